@@ -17,12 +17,14 @@ import (
 func InitializeApplication() (*Application, error) {
 	initUploadUsecase := di.InitUploadUsecaseProvider()
 	uploadUsecase := di.UploadUsecaseProvider()
+	uploadCommand := di.UploadCommandProvider(initUploadUsecase, uploadUsecase)
 	deleteUsecase := di.DeleteFileUsecaseProvider()
-	uploadCommand := di.UploadCommandProvider(initUploadUsecase, uploadUsecase, deleteUsecase)
 	deleteCommand := di.DeleteCommandProvider(deleteUsecase)
 	listUsecase := di.ListUsecaseProvider()
 	listCommand := di.ListCommandProvider(listUsecase)
-	command := di.RootCommandProvider(uploadCommand, deleteCommand, listCommand)
+	downloadUsecase := di.DownloadUsecaseProvider()
+	downloadCommand := di.DownloadCommandProvider(downloadUsecase)
+	command := di.RootCommandProvider(uploadCommand, deleteCommand, listCommand, downloadCommand)
 	application := &Application{
 		RootCmd: command,
 	}
@@ -15,6 +15,7 @@ import (
 type UploadCommand *cobra.Command
 type DeleteCommand *cobra.Command
 type ListCommand *cobra.Command
+type DownloadCommand *cobra.Command
 
 // LoggerProvider provides the application logger
 func LoggerProvider() *slog.Logger {
@@ -42,9 +43,13 @@ func ListUsecaseProvider() usecase.ListUsecase {
 	return usecase.NewListUsecase(infrastructure.NewFileServerV1HttpClient())
 }
 
+func DownloadUsecaseProvider() usecase.DownloadUsecase {
+	return usecase.NewDownloadUsecase(infrastructure.NewFileServerV1HttpClient())
+}
+
 // UploadCommandProvider provides the upload command
-func UploadCommandProvider(initUploadUsecase usecase.InitUploadUsecase, uploadUsecase usecase.UploadUsecase, deleteFileUsecase usecase.DeleteUsecase) UploadCommand {
-	return UploadCommand(command.NewUploadCommandHandler(initUploadUsecase, uploadUsecase, deleteFileUsecase).Execute())
+func UploadCommandProvider(initUploadUsecase usecase.InitUploadUsecase, uploadUsecase usecase.UploadUsecase) UploadCommand {
+	return UploadCommand(command.NewUploadCommandHandler(initUploadUsecase, uploadUsecase).Execute())
 }
 
 // DeleteCommandProvider provides the delete command
@@ -57,19 +62,25 @@ func ListCommandProvider(listUsecase usecase.ListUsecase) ListCommand {
 	return ListCommand(command.NewListCommandHandler(listUsecase).Execute())
 }
 
+// DownloadCommandProvider provides the download command
+func DownloadCommandProvider(downloadUsecase usecase.DownloadUsecase) DownloadCommand {
+	return DownloadCommand(command.NewDownloadCommandHandler(downloadUsecase).Execute())
+}
+
 // RootCommandProvider provides the root command with all subcommands
-func RootCommandProvider(uploadCmd UploadCommand, deleteCmd DeleteCommand, listCmd ListCommand) *cobra.Command {
+func RootCommandProvider(uploadCmd UploadCommand, deleteCmd DeleteCommand, listCmd ListCommand, downloadCmd DownloadCommand) *cobra.Command {
 	// Create root command
 	rootCmd := &cobra.Command{
 		Use:   "fs-store",
 		Short: "Command-line interface for the file storage server",
-		Long:  `Command-line interface for interacting with the file storage server to upload, delete, and list files.`,
+		Long:  `Command-line interface for interacting with the file storage server to upload, download, delete, and list files.`,
 	}
 
 	// Add commands
 	rootCmd.AddCommand((*cobra.Command)(uploadCmd))
 	rootCmd.AddCommand((*cobra.Command)(deleteCmd))
 	rootCmd.AddCommand((*cobra.Command)(listCmd))
+	rootCmd.AddCommand((*cobra.Command)(downloadCmd))
 
 	return rootCmd
 }
@@ -82,9 +93,11 @@ var CLIProvider = wire.NewSet(
 	UploadUsecaseProvider,
 	DeleteFileUsecaseProvider,
 	ListUsecaseProvider,
+	DownloadUsecaseProvider,
 	// Command providers
 	UploadCommandProvider,
 	DeleteCommandProvider,
 	ListCommandProvider,
+	DownloadCommandProvider,
 	RootCommandProvider,
 )
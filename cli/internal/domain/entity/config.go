@@ -1,17 +1,26 @@
 package entity
 
 const (
-	DefaultServerURL          = "http://localhost:38080"
-	DefaultChunkSize          = 1024 * 256 // 256 KiB
-	DefaultRetries            = 3
-	DefaultMaxConcurrency     = 10
-	DefaultCompressionEnabled = false
+	DefaultServerURL              = "http://localhost:38080"
+	DefaultChunkSize              = 1024 * 256 // 256 KiB
+	DefaultRetries                = 3
+	DefaultMaxConcurrency         = 10
+	DefaultCompression            = "none"
+	DefaultCompressionLevel       = 0
+	DefaultChecksumAlgorithm      = "sha256"
+	DefaultSingleRequestThreshold = 1024 * 1024 // 1 MiB
+	DefaultChunking               = "fixed"
+	DefaultCDCMinSize             = 1024 * 64   // 64 KiB
+	DefaultCDCAvgSize             = 1024 * 256  // 256 KiB
+	DefaultCDCMaxSize             = 1024 * 1024 // 1 MiB
 )
 
 type ContextKey string
 
 const (
-	ConcurrencyKey        ContextKey = "concurrency"
-	RetriesKey            ContextKey = "retries"
-	CompressionEnabledKey ContextKey = "compressionEnabled"
+	ConcurrencyKey      ContextKey = "concurrency"
+	RetriesKey          ContextKey = "retries"
+	CompressionKey      ContextKey = "compression"
+	CompressionLevelKey ContextKey = "compressionLevel"
+	StorageCodecKey     ContextKey = "storageCodec"
 )
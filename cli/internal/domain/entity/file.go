@@ -9,25 +9,58 @@ const (
 	FileStatusInProgress  FileStatus = "IN_PROGRESS"
 	FileStatusFailed      FileStatus = "FAILED"
 	FileStatusUploaded    FileStatus = "UPLOADED"
+	FileStatusVerifying   FileStatus = "VERIFYING"
+	FileStatusCorrupt     FileStatus = "CORRUPT"
+	FileStatusDamaged     FileStatus = "DAMAGED"
 )
 
 // FileStatsResp represents the response body of the file stats request
 type FileStatsResp struct {
-	ID                  uint64        `json:"id"`
-	Name                string        `json:"name"`
-	Size                uint64        `json:"size"`
-	Checksum            string        `json:"checksum"`
-	Status              FileStatus    `json:"status"`
-	TotalChunks         uint          `json:"total_chunks"`
-	UploadedChunks      uint          `json:"uploaded_chunks"`
-	CreatedAt           time.Time     `json:"created_at"`
-	UpdatedAt           time.Time     `json:"updated_at"`
-	UploadTimeoutSecond time.Duration `json:"upload_timeout_second"`
+	ID                uint64     `json:"id"`
+	Name              string     `json:"name"`
+	Size              uint64     `json:"size"`
+	Checksum          string     `json:"checksum"`
+	ChecksumAlgorithm string     `json:"checksum_algorithm"` // empty on the servers which only support sha256
+	Status            FileStatus `json:"status"`
+	TotalChunks       uint       `json:"total_chunks"`
+	UploadedChunks    uint       `json:"uploaded_chunks"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// FileManifestResp represents the response body of the manifest request, which lists the chunks of a file
+type FileManifestResp struct {
+	FileID            uint64              `json:"file_id"`
+	Name              string              `json:"name"`
+	Size              uint64              `json:"size"`
+	Checksum          string              `json:"checksum"`
+	ChecksumAlgorithm string              `json:"checksum_algorithm"`
+	ChunkSize         uint64              `json:"chunk_size"`
+	Status            FileStatus          `json:"status"`
+	TotalChunks       uint                `json:"total_chunks"`
+	Chunks            []FileManifestChunk `json:"chunks"`
+}
+
+// FileManifestChunk represents a chunk in the manifest
+type FileManifestChunk struct {
+	ChunkNumber uint64     `json:"chunk_number"`
+	Offset      uint64     `json:"offset"`
+	Size        uint64     `json:"size"`
+	Checksum    string     `json:"checksum"` // SHA-256 of the chunk content, which is empty until the chunk is uploaded
+	Status      FileStatus `json:"status"`
 }
 
 type MissingChunkInfo struct {
 	MaxChunkSize uint64   `json:"max_size"`
 	ChunkNumbers []uint64 `json:"chunk_numbers"`
+	Offsets      []uint64 `json:"offsets"` // bytes of each chunk in ChunkNumbers the server already received
+}
+
+// UploadLimitsResp represents the response body of the upload limits request. A zero limit means no limit.
+type UploadLimitsResp struct {
+	MaxFileSize   uint64 `json:"max_file_size"`
+	MaxChunkSize  uint64 `json:"max_chunk_size"`
+	MaxChunkCount uint64 `json:"max_chunk_count"`
 }
 
 // ListFilesResp represents the response body of the list files request
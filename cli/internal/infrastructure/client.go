@@ -2,21 +2,38 @@ package infrastructure
 
 import (
 	"bytes"
-	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"os/user"
 	"time"
 
 	"github.com/tomoya.tokunaga/cli/internal/domain/entity"
+	"github.com/tomoya.tokunaga/cli/internal/util"
+)
+
+// uploadOwnerHeader is the header which identifies who starts an upload, so that only the same user on the same host
+// can resume it
+const uploadOwnerHeader = "X-Upload-Owner"
+
+// the headers carrying the checksum of the whole content uploaded in a single request, and the codec the server stores
+// it with
+const (
+	fileChecksumHeader          = "X-File-Checksum"
+	fileChecksumAlgorithmHeader = "X-File-Checksum-Algorithm"
+	fileStorageCodecHeader      = "X-File-Storage-Codec"
 )
 
 // FileServerV1HttpClient is a client for communicating with the file server
 type FileServerV1HttpClient struct {
 	baseURL    string
 	httpClient *http.Client
+	owner      string
 }
 
 // NewFileServerV1HttpClient creates a new client for the remote file server
@@ -26,7 +43,18 @@ func NewFileServerV1HttpClient() *FileServerV1HttpClient {
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
+		owner: uploadOwner(),
+	}
+}
+
+// uploadOwner returns "<user>@<host>" of the current process. The parts which can't be determined are left empty
+func uploadOwner() string {
+	var userName string
+	if u, err := user.Current(); err == nil {
+		userName = u.Username
 	}
+	hostName, _ := os.Hostname()
+	return fmt.Sprintf("%s@%s", userName, hostName)
 }
 
 // createRequest creates an HTTP request with common settings
@@ -45,21 +73,33 @@ func (c *FileServerV1HttpClient) createRequest(ctx context.Context, method, urlP
 
 // UploadInitRequest represents the request body for initializing an upload
 type UploadInitRequest struct {
-	TotalSize   int64  `json:"total_size"`
-	TotalChunks int64  `json:"total_chunks"`
-	ChunkSize   int64  `json:"chunk_size"`
-	Checksum    string `json:"checksum"`
-	IsReUpload  bool   `json:"is_reupload"`
+	TotalSize         int64    `json:"total_size"`
+	TotalChunks       int64    `json:"total_chunks"`
+	ChunkSize         int64    `json:"chunk_size"`
+	ChunkSizes        []int64  `json:"chunk_sizes,omitempty"`     // sizes of the content-defined chunks in order, each up to the chunk size
+	ChunkChecksums    []string `json:"chunk_checksums,omitempty"` // SHA-256 of the chunks in order, by which the chunks of the base file are reused
+	BaseFileID        uint64   `json:"base_file_id,omitempty"`    // file on the server whose chunks of the same content are reused
+	Checksum          string   `json:"checksum"`
+	ChecksumAlgorithm string   `json:"checksum_algorithm"`
+	IsReUpload        bool     `json:"is_reupload"`
+	OpenEnded         bool     `json:"open_ended"`              // the size and the checksum are given on the commit
+	StorageCodec      string   `json:"storage_codec,omitempty"` // the server chooses its default codec when empty
 }
 
-// UploadInitResponse represents the response from initializing an upload
+// UploadInitResponse represents the response from initializing an upload. The chunks are uploaded with the session
+// token until the session expires
 type UploadInitResponse struct {
-	UploadID         uint64                   `json:"upload_id"`
+	SessionToken     string                   `json:"session_token"`
+	ExpiresAt        time.Time                `json:"expires_at"`
 	MissingChunkInfo *entity.MissingChunkInfo `json:"missing_chunk_info"`
 }
 
-// InitUpload initializes a file upload on the server
+// InitUpload initializes a file upload on the server. The storage codec in the context is asked for unless the request
+// has one
 func (c *FileServerV1HttpClient) InitUpload(ctx context.Context, fileName string, request UploadInitRequest) (*UploadInitResponse, error) {
+	if request.StorageCodec == "" {
+		request.StorageCodec, _ = ctx.Value(entity.StorageCodecKey).(string)
+	}
 	requestBody, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
@@ -75,6 +115,7 @@ func (c *FileServerV1HttpClient) InitUpload(ctx context.Context, fileName string
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(uploadOwnerHeader, c.owner)
 
 	// Send request
 	resp, err := c.httpClient.Do(req)
@@ -107,37 +148,20 @@ func (c *FileServerV1HttpClient) InitUpload(ctx context.Context, fileName string
 	return &response, nil
 }
 
-// UploadChunk uploads a chunk to the server
-func (c *FileServerV1HttpClient) UploadChunk(ctx context.Context, uploadID uint64, chunkID int, data []byte) error {
-	// Retrieve flag values from context
-	compressionEnabled := ctx.Value(entity.CompressionEnabledKey).(bool)
-
-	var requestBody io.Reader
-	var contentEncoding string
-
-	if compressionEnabled {
-		// Compress data with gzip
-		var buf bytes.Buffer
-		gzipWriter := gzip.NewWriter(&buf)
-
-		if _, err := gzipWriter.Write(data); err != nil {
-			return fmt.Errorf("failed to write data to gzip writer: %w", err)
-		}
-
-		// Close the gzip writer to flush any pending data
-		if err := gzipWriter.Close(); err != nil {
-			return fmt.Errorf("failed to close gzip writer: %w", err)
-		}
+// UploadChunk uploads a chunk to the server. When offset is greater than 0, only the content of the chunk from the
+// offset is sent to continue the content the server already received
+func (c *FileServerV1HttpClient) UploadChunk(ctx context.Context, sessionToken string, chunkID int, data []byte, offset int64) error {
+	if offset < 0 || (offset > 0 && offset >= int64(len(data))) {
+		return fmt.Errorf("offset %d is out of the chunk of %d bytes", offset, len(data))
+	}
 
-		requestBody = &buf
-		contentEncoding = "gzip"
-	} else {
-		// Use uncompressed data
-		requestBody = bytes.NewReader(data)
+	requestBody, contentEncoding, err := compressRequestBody(ctx, data[offset:])
+	if err != nil {
+		return err
 	}
 
 	// Create URL using helper
-	endpointPath := fmt.Sprintf("/files/upload/%d/%d", uploadID, chunkID)
+	endpointPath := fmt.Sprintf("/files/upload/%s/%d", sessionToken, chunkID)
 
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -149,8 +173,14 @@ func (c *FileServerV1HttpClient) UploadChunk(ctx context.Context, uploadID uint6
 		return err
 	}
 
-	// Set headers for application/octet-stream
+	// Set headers for application/octet-stream. The checksum is of the whole uncompressed data, so that the server can
+	// detect the chunk corrupted in transit or by the compression
+	checksum := sha256.Sum256(data)
 	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-Chunk-SHA256", hex.EncodeToString(checksum[:]))
+	if offset > 0 {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(data)-1, len(data)))
+	}
 	if contentEncoding != "" {
 		req.Header.Set("Content-Encoding", contentEncoding)
 	}
@@ -180,6 +210,195 @@ func (c *FileServerV1HttpClient) UploadChunk(ctx context.Context, uploadID uint6
 	return nil
 }
 
+// uploadCommitRequest represents the request body for committing an open-ended upload
+type uploadCommitRequest struct {
+	TotalSize int64  `json:"total_size"`
+	Checksum  string `json:"checksum"`
+}
+
+// CommitUpload commits an open-ended upload with the total size and the checksum of the content once all of its
+// chunks are uploaded. The server verifies the content and binds it to the name before the response
+func (c *FileServerV1HttpClient) CommitUpload(ctx context.Context, sessionToken string, totalSize int64, checksum string) error {
+	requestBody, err := json.Marshal(uploadCommitRequest{TotalSize: totalSize, Checksum: checksum})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	endpointPath := fmt.Sprintf("/files/upload/commit/%s", sessionToken)
+
+	// the whole content is read to be verified, so the request is only bound by the client's timeout
+	req, err := c.createRequest(ctx, "POST", endpointPath, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Println("Failed to close response body")
+		}
+	}()
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// AbortUpload aborts an upload session on the server. The server stops the chunks being uploaded before deleting the
+// content uploaded so far, and the file previously uploaded with the name is left as it is
+func (c *FileServerV1HttpClient) AbortUpload(ctx context.Context, sessionToken string) error {
+	endpointPath := fmt.Sprintf("/files/upload/%s", sessionToken)
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Create request
+	req, err := c.createRequest(ctx, "DELETE", endpointPath, nil)
+	if err != nil {
+		return err
+	}
+
+	// Send request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Println("Failed to close response body")
+		}
+	}()
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// PutFile uploads the whole content of a file to the server in a single request, replacing the file with the same name
+// if any. The server verifies the content against the checksum
+func (c *FileServerV1HttpClient) PutFile(ctx context.Context, fileName string, data []byte, checksum string, checksumAlgorithm string) error {
+	requestBody, contentEncoding, err := compressRequestBody(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	endpointPath := fmt.Sprintf("/files/%s", fileName)
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Create request
+	req, err := c.createRequest(ctx, "PUT", endpointPath, requestBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set(fileChecksumHeader, checksum)
+	req.Header.Set(fileChecksumAlgorithmHeader, checksumAlgorithm)
+	if storageCodec, _ := ctx.Value(entity.StorageCodecKey).(string); storageCodec != "" {
+		req.Header.Set(fileStorageCodecHeader, storageCodec)
+	}
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+
+	// Send request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Println("Failed to close response body")
+		}
+	}()
+
+	// Check response status
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// compressRequestBody compresses the data with the compression algorithm and level in the context, and returns it
+// along with its Content-Encoding, which is empty when the data isn't compressed
+func compressRequestBody(ctx context.Context, data []byte) (io.Reader, string, error) {
+	// Retrieve flag values from context
+	algorithm, _ := ctx.Value(entity.CompressionKey).(string)
+	level, _ := ctx.Value(entity.CompressionLevelKey).(int)
+	if algorithm == "" || algorithm == util.CompressionNone {
+		return bytes.NewReader(data), "", nil
+	}
+
+	compressed, err := util.Compress(data, algorithm, level)
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(compressed), algorithm, nil
+}
+
+// DownloadChunk downloads length bytes of a file starting at offset from the server
+func (c *FileServerV1HttpClient) DownloadChunk(ctx context.Context, fileName string, offset int64, length int64) ([]byte, error) {
+	endpointPath := fmt.Sprintf("/files/%s/content", fileName)
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// Create request
+	req, err := c.createRequest(ctx, "GET", endpointPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the server sends a range unencoded, because Content-Range refers to the bytes of the content
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+
+	// Send request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Println("Failed to close response body")
+		}
+	}()
+
+	// Check response status
+	if resp.StatusCode != http.StatusPartialContent {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if int64(len(body)) != length {
+		return nil, fmt.Errorf("server returned %d bytes, but %d bytes were requested", len(body), length)
+	}
+
+	return body, nil
+}
+
 // DeleteFile deletes a file from the server
 func (c *FileServerV1HttpClient) DeleteFile(ctx context.Context, fileName string) error {
 	endpointPath := fmt.Sprintf("/files/%s", fileName)
@@ -302,3 +521,93 @@ func (c *FileServerV1HttpClient) GetFileStats(ctx context.Context, fileName stri
 
 	return &file, nil
 }
+
+// GetFileManifest gets the list of the chunks of a file with their checksums. It returns nil when the file doesn't exist
+func (c *FileServerV1HttpClient) GetFileManifest(ctx context.Context, fileName string) (*entity.FileManifestResp, error) {
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := c.createRequest(ctx, "GET", fmt.Sprintf("/files/%s/manifest", fileName), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Println("Failed to close response body")
+		}
+	}()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check response status
+	var manifest entity.FileManifestResp
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	// Parse response
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse response body: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+func (c *FileServerV1HttpClient) GetUploadLimits(ctx context.Context) (*entity.UploadLimitsResp, error) {
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	// Create request to /files/upload/limits endpoint
+	req, err := c.createRequest(ctx, "GET", "/files/upload/limits", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Send request
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Println("Failed to close response body")
+		}
+	}()
+
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	// Check response status. the servers which don't enforce the limits don't have the endpoint
+	var limits entity.UploadLimitsResp
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	// Parse response
+	if err := json.Unmarshal(body, &limits); err != nil {
+		return nil, fmt.Errorf("failed to parse response body: %w", err)
+	}
+
+	return &limits, nil
+}
@@ -11,8 +11,20 @@ type FileServerHttpClient interface {
 	// InitUpload initializes a file upload on the server
 	InitUpload(ctx context.Context, fileName string, request UploadInitRequest) (*UploadInitResponse, error)
 
-	// UploadChunk uploads a chunk to the server
-	UploadChunk(ctx context.Context, uploadID uint64, chunkID int, data []byte) error
+	// UploadChunk uploads a chunk of the upload session to the server, sending its content from offset
+	UploadChunk(ctx context.Context, sessionToken string, chunkID int, data []byte, offset int64) error
+
+	// CommitUpload commits an open-ended upload with the total size and the checksum of the content
+	CommitUpload(ctx context.Context, sessionToken string, totalSize int64, checksum string) error
+
+	// AbortUpload aborts an upload session on the server, which deletes the content uploaded so far
+	AbortUpload(ctx context.Context, sessionToken string) error
+
+	// PutFile uploads the whole content of a file to the server in a single request
+	PutFile(ctx context.Context, fileName string, data []byte, checksum string, checksumAlgorithm string) error
+
+	// DownloadChunk downloads length bytes of a file starting at offset from the server
+	DownloadChunk(ctx context.Context, fileName string, offset int64, length int64) ([]byte, error)
 
 	// DeleteFile deletes a file from the server
 	DeleteFile(ctx context.Context, fileName string) error
@@ -22,4 +34,10 @@ type FileServerHttpClient interface {
 
 	// GetFileStats gets the stats of a file on the server
 	GetFileStats(ctx context.Context, fileName string) (*entity.FileStatsResp, error)
+
+	// GetFileManifest gets the list of the chunks of a file on the server
+	GetFileManifest(ctx context.Context, fileName string) (*entity.FileManifestResp, error)
+
+	// GetUploadLimits gets the limits the server enforces on uploads
+	GetUploadLimits(ctx context.Context) (*entity.UploadLimitsResp, error)
 }
@@ -0,0 +1,129 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/schollz/progressbar/v3"
+	"github.com/spf13/cobra"
+
+	"github.com/tomoya.tokunaga/cli/internal/domain/entity"
+	"github.com/tomoya.tokunaga/cli/internal/usecase"
+)
+
+type DownloadCommandHandler struct {
+	downloadUsecase usecase.DownloadUsecase
+}
+
+func NewDownloadCommandHandler(
+	downloadUsecase usecase.DownloadUsecase,
+) *DownloadCommandHandler {
+	return &DownloadCommandHandler{
+		downloadUsecase: downloadUsecase,
+	}
+}
+
+// Execute creates a command to download a file from the file server
+func (h *DownloadCommandHandler) Execute() *cobra.Command {
+	var (
+		concurrency int
+		retries     int
+		chunkSize   int64
+		outputPath  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "download-file [file name]",
+		Short: "Download a file from the file server",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rawFileName := args[0]
+
+			// Use just the filename part if a path was provided
+			fileName := filepath.Base(rawFileName)
+			if fileName == "" || fileName == "." || fileName == "/" {
+				return fmt.Errorf("[ERROR] Invalid file name: %s. Please provide a valid file name", rawFileName)
+			}
+			if chunkSize <= 0 {
+				return fmt.Errorf("[ERROR] Invalid chunk size: %d. Please provide a positive chunk size", chunkSize)
+			}
+			if concurrency < 1 {
+				return fmt.Errorf("[ERROR] Invalid concurrency: %d. Please provide a positive concurrency", concurrency)
+			}
+
+			filePath := outputPath
+			if filePath == "" {
+				filePath = fileName
+			}
+
+			ctx := cmd.Context()
+
+			// Add command parameters to context
+			ctx = context.WithValue(ctx, entity.ConcurrencyKey, concurrency)
+			ctx = context.WithValue(ctx, entity.RetriesKey, retries)
+
+			// Precheck
+			precheckOutput, err := h.downloadUsecase.ExecutePrecheck(ctx, &usecase.DownloadPrecheckUsecaseInput{
+				TargetFileName: fileName,
+				FilePath:       filePath,
+				ChunkSize:      chunkSize,
+			})
+			if err != nil {
+				cmd.PrintErrf("[ERROR] Failed to initialize download pre-check: %v\n", err)
+				return nil
+			}
+			if precheckOutput.IsDownloaded {
+				cmd.Printf("'%s' already downloaded to '%s'.\n", fileName, filePath)
+				cmd.Println("Exiting...")
+				return nil
+			}
+			if len(precheckOutput.CompletedChunkNumberMap) > 0 {
+				cmd.Printf("Resuming the previous download of '%s'...\n", fileName)
+			}
+
+			// Setup progress bar to write to cmd's error stream
+			bar := progressbar.NewOptions64(
+				precheckOutput.FileSize,
+				progressbar.OptionSetDescription("Downloading in progress..."),
+				progressbar.OptionSetWriter(cmd.ErrOrStderr()),
+				progressbar.OptionShowBytes(true),
+				progressbar.OptionSetWidth(30),
+				progressbar.OptionThrottle(100),
+				progressbar.OptionShowCount(),
+				progressbar.OptionFullWidth(),
+				progressbar.OptionSetRenderBlankState(true),
+				progressbar.OptionClearOnFinish(),
+			)
+			_ = bar.Add64(precheckOutput.DownloadedSize)
+
+			// Execute download
+			err = h.downloadUsecase.Execute(ctx, &usecase.DownloadUsecaseInput{
+				TargetFileName:          fileName,
+				FilePath:                filePath,
+				Checksum:                precheckOutput.Checksum,
+				ChecksumAlgorithm:       precheckOutput.ChecksumAlgorithm,
+				FileSize:                precheckOutput.FileSize,
+				ChunkSize:               precheckOutput.ChunkSize,
+				CompletedChunkNumberMap: precheckOutput.CompletedChunkNumberMap,
+				ProgressCb:              func(size int64) { _ = bar.Add64(size) },
+			})
+			if err != nil {
+				_ = bar.Clear()
+				cmd.PrintErrf("[ERROR] Download failed for file '%s': %v\n", fileName, err)
+				return nil
+			}
+
+			cmd.Println("Successfully downloaded!")
+			return nil
+		},
+	}
+
+	// Define flags
+	cmd.Flags().IntVarP(&retries, "retries", "r", entity.DefaultRetries, "Number of retries for failed chunk downloads")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", entity.DefaultMaxConcurrency, "Maximum number of concurrent operations")
+	cmd.Flags().Int64VarP(&chunkSize, "chunk-size", "s", entity.DefaultChunkSize, "Size in bytes of each ranged request")
+	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "Specify the local path to save the file (defaults to the file name)")
+
+	return cmd
+}
@@ -0,0 +1,169 @@
+package command_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/tomoya.tokunaga/cli/internal/domain/entity"
+	"github.com/tomoya.tokunaga/cli/internal/interface/command"
+	"github.com/tomoya.tokunaga/cli/internal/mock"
+	"github.com/tomoya.tokunaga/cli/internal/usecase"
+)
+
+func TestDownloadCommandHandler_Execute(t *testing.T) {
+	ctx := context.Background()
+	defaultChecksum := "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+	tests := []struct {
+		name          string
+		args          []string
+		flags         map[string]string
+		mockSetup     func(m *mock.MockDownloadUsecase)
+		expectedOut   []string
+		expectedError error
+	}{
+		{
+			name: "Success: Basic download",
+			args: []string{"test.txt"},
+			mockSetup: func(m *mock.MockDownloadUsecase) {
+				m.EXPECT().ExecutePrecheck(gomock.Any(), &usecase.DownloadPrecheckUsecaseInput{
+					TargetFileName: "test.txt",
+					FilePath:       "test.txt",
+					ChunkSize:      entity.DefaultChunkSize,
+				}).Return(&usecase.DownloadPrecheckUsecaseOutput{
+					Checksum:                defaultChecksum,
+					FileSize:                11,
+					ChunkSize:               entity.DefaultChunkSize,
+					CompletedChunkNumberMap: map[uint64]struct{}{},
+				}, nil)
+				m.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, in *usecase.DownloadUsecaseInput) error {
+						assert.Equal(t, "test.txt", in.FilePath)
+						assert.Equal(t, defaultChecksum, in.Checksum)
+						assert.Equal(t, int64(11), in.FileSize)
+						assert.Equal(t, entity.DefaultMaxConcurrency, ctx.Value(entity.ConcurrencyKey))
+						in.ProgressCb(11)
+						return nil
+					})
+			},
+			expectedOut: []string{"Successfully downloaded!"},
+		},
+		{
+			name:  "Success: Resume download to the output path",
+			args:  []string{"test.txt"},
+			flags: map[string]string{"output": "/tmp/out.txt", "concurrency": "3"},
+			mockSetup: func(m *mock.MockDownloadUsecase) {
+				m.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).Return(&usecase.DownloadPrecheckUsecaseOutput{
+					Checksum:                defaultChecksum,
+					FileSize:                11,
+					ChunkSize:               4,
+					DownloadedSize:          4,
+					CompletedChunkNumberMap: map[uint64]struct{}{0: {}},
+				}, nil)
+				m.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, in *usecase.DownloadUsecaseInput) error {
+						assert.Equal(t, "/tmp/out.txt", in.FilePath)
+						assert.Equal(t, int64(4), in.ChunkSize)
+						assert.Equal(t, map[uint64]struct{}{0: {}}, in.CompletedChunkNumberMap)
+						assert.Equal(t, 3, ctx.Value(entity.ConcurrencyKey))
+						return nil
+					})
+			},
+			expectedOut: []string{"Resuming the previous download of 'test.txt'...", "Successfully downloaded!"},
+		},
+		{
+			name: "Success: Already downloaded",
+			args: []string{"test.txt"},
+			mockSetup: func(m *mock.MockDownloadUsecase) {
+				m.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).Return(&usecase.DownloadPrecheckUsecaseOutput{
+					IsDownloaded: true,
+				}, nil)
+			},
+			expectedOut: []string{"'test.txt' already downloaded to 'test.txt'.", "Exiting..."},
+		},
+		{
+			name:          "Error: Invalid file name",
+			args:          []string{"/"},
+			mockSetup:     func(m *mock.MockDownloadUsecase) {},
+			expectedError: errors.New("[ERROR] Invalid file name: /. Please provide a valid file name"),
+		},
+		{
+			name:          "Error: Invalid chunk size",
+			args:          []string{"test.txt"},
+			flags:         map[string]string{"chunk-size": "0"},
+			mockSetup:     func(m *mock.MockDownloadUsecase) {},
+			expectedError: errors.New("[ERROR] Invalid chunk size: 0. Please provide a positive chunk size"),
+		},
+		{
+			name:          "Error: Invalid concurrency",
+			args:          []string{"test.txt"},
+			flags:         map[string]string{"concurrency": "0"},
+			mockSetup:     func(m *mock.MockDownloadUsecase) {},
+			expectedError: errors.New("[ERROR] Invalid concurrency: 0. Please provide a positive concurrency"),
+		},
+		{
+			name: "Error: Precheck fails",
+			args: []string{"test.txt"},
+			mockSetup: func(m *mock.MockDownloadUsecase) {
+				m.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).Return(nil, errors.New("not found"))
+			},
+			expectedOut: []string{"[ERROR] Failed to initialize download pre-check: not found"},
+		},
+		{
+			name: "Error: Download fails",
+			args: []string{"test.txt"},
+			mockSetup: func(m *mock.MockDownloadUsecase) {
+				m.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).Return(&usecase.DownloadPrecheckUsecaseOutput{
+					Checksum:  defaultChecksum,
+					FileSize:  11,
+					ChunkSize: entity.DefaultChunkSize,
+				}, nil)
+				m.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(errors.New("checksum mismatch"))
+			},
+			expectedOut: []string{"[ERROR] Download failed for file 'test.txt': checksum mismatch"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDownloadUsecase := mock.NewMockDownloadUsecase(ctrl)
+			tc.mockSetup(mockDownloadUsecase)
+
+			handler := command.NewDownloadCommandHandler(mockDownloadUsecase)
+			cmd := handler.Execute()
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			cmd.SetArgs(tc.args)
+
+			for key, val := range tc.flags {
+				_ = cmd.Flags().Set(key, val)
+			}
+
+			err := cmd.ExecuteContext(ctx)
+
+			outputStr := out.String()
+			for _, expectedSubstr := range tc.expectedOut {
+				assert.Contains(t, outputStr, expectedSubstr)
+			}
+
+			if tc.expectedError != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
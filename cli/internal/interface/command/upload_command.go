@@ -4,55 +4,99 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 
 	"github.com/tomoya.tokunaga/cli/internal/domain/entity"
 	"github.com/tomoya.tokunaga/cli/internal/usecase"
+	"github.com/tomoya.tokunaga/cli/internal/util"
 )
 
+// stdinFilePath is the file path given to upload the content read from stdin
+const stdinFilePath = "-"
+
 type UploadCommandHandler struct {
 	initUploadUsecase usecase.InitUploadUsecase
 	uploadUsecase     usecase.UploadUsecase
-	deleteUsecase     usecase.DeleteUsecase
 }
 
 func NewUploadCommandHandler(
 	initUploadUsecase usecase.InitUploadUsecase,
 	uploadUsecase usecase.UploadUsecase,
-	deleteUsecase usecase.DeleteUsecase,
 ) *UploadCommandHandler {
 	return &UploadCommandHandler{
 		initUploadUsecase: initUploadUsecase,
 		uploadUsecase:     uploadUsecase,
-		deleteUsecase:     deleteUsecase,
 	}
 }
 
 func (h *UploadCommandHandler) Execute() *cobra.Command {
 	var (
-		concurrency        int
-		retries            int
-		chunkSize          int64
-		fileName           string
-		compressionEnabled bool
+		concurrency            int
+		retries                int
+		chunkSize              int64
+		fileName               string
+		compression            string
+		compressionLevel       int
+		checksumAlgorithm      string
+		singleRequestThreshold int64
+		storageCodec           string
+		chunking               string
+		cdcParams              util.CDCParams
 	)
 
 	cmd := &cobra.Command{
 		Use:   "upload-file [file path]",
-		Short: "Upload a file to the file server",
+		Short: "Upload a file to the file server (\"-\" to read the content from stdin)",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			filePath := args[0]
 			ctx := cmd.Context()
 
+			if concurrency < 1 {
+				return fmt.Errorf("[ERROR] Invalid concurrency: %d. Please provide a positive concurrency", concurrency)
+			}
+			if err := util.ValidateCompression(compression, compressionLevel); err != nil {
+				return fmt.Errorf("[ERROR] Invalid compression: %w", err)
+			}
+			if storageCodec != "" {
+				if err := util.ValidateCompression(storageCodec, 0); err != nil {
+					return fmt.Errorf("[ERROR] Invalid storage codec: %w", err)
+				}
+			}
+			var cdc *util.CDCParams
+			switch chunking {
+			case util.ChunkingFixed:
+			case util.ChunkingCDC:
+				if err := cdcParams.Validate(); err != nil {
+					return fmt.Errorf("[ERROR] Invalid chunking: %w", err)
+				}
+				cdc = &cdcParams
+			default:
+				return fmt.Errorf("[ERROR] Invalid chunking: unsupported chunking %q (supported: %v)", chunking, util.ChunkingMethods())
+			}
+
 			// Add command parameters to context
 			ctx = context.WithValue(ctx, entity.ConcurrencyKey, concurrency)
 			ctx = context.WithValue(ctx, entity.RetriesKey, retries)
-			ctx = context.WithValue(ctx, entity.CompressionEnabledKey, compressionEnabled)
+			ctx = context.WithValue(ctx, entity.CompressionKey, compression)
+			ctx = context.WithValue(ctx, entity.CompressionLevelKey, compressionLevel)
+			ctx = context.WithValue(ctx, entity.StorageCodecKey, storageCodec)
+
+			if filePath == stdinFilePath {
+				if fileName == "" {
+					cmd.PrintErrln("[ERROR] --file-name is required to upload the content from stdin")
+					return nil
+				}
+				return h.uploadFromStdin(ctx, cmd, fileName, chunkSize, checksumAlgorithm)
+			}
 
 			targetFileName := fileName
 			if targetFileName == "" {
@@ -61,52 +105,71 @@ func (h *UploadCommandHandler) Execute() *cobra.Command {
 
 			// Precheck
 			postPrecheckAction, precheckOutput, err := h.initUploadUsecase.ExecutePrecheck(ctx, &usecase.InitUploadPrecheckUsecaseInput{
-				FilePath:       filePath,
-				TargetFileName: targetFileName,
+				FilePath:          filePath,
+				TargetFileName:    targetFileName,
+				ChecksumAlgorithm: checksumAlgorithm,
 			})
 			if err != nil {
 				cmd.PrintErrf("[ERROR] Failed to initialize upload pre-check: %v\n", err)
 				return nil
 			}
 
-			isReUpload := postPrecheckAction == usecase.ProceedWithReUpload
 			uploadInitOutput := &usecase.UploadUsecaseOutput{}
+			isDelta := false
+
+			// a small file is sent in a single request, which saves the round trips of the upload session
+			isSingleRequest := precheckOutput != nil && precheckOutput.FileSize <= singleRequestThreshold
 
-			// Handle precheck results
+			// Handle precheck results. The file being uploaded has no name on the server until all of its chunks are
+			// uploaded, so the precheck can't tell if there's an upload session to resume. Every upload asks the server
+			// to resume the session of the same content this user started, if any.
 			switch postPrecheckAction {
 			case usecase.Exits:
 				cmd.Printf("'%s' already uploaded to the file server.\n", targetFileName)
 				cmd.Println("Exiting...")
 				return nil
-			case usecase.ProceedWithInit, usecase.ProceedWithReUpload:
+			case usecase.ProceedWithInit:
+				if isSingleRequest {
+					return h.uploadInSingleRequest(ctx, cmd, filePath, targetFileName, precheckOutput)
+				}
 				uploadInitOutput, err = h.initUploadUsecase.Execute(ctx, &usecase.InitUploadUsecaseInput{
-					FilePath:         filePath,
-					TargetFileName:   targetFileName,
-					OriginalChecksum: precheckOutput.Checksum,
-					ChunkSize:        chunkSize,
-					IsReUpload:       isReUpload,
+					FilePath:          filePath,
+					TargetFileName:    targetFileName,
+					OriginalChecksum:  precheckOutput.Checksum,
+					ChecksumAlgorithm: precheckOutput.ChecksumAlgorithm,
+					ChunkSize:         chunkSize,
+					CDC:               cdc,
+					IsReUpload:        true,
 				})
 				if err != nil {
 					// Return error here because it's a fundamental failure of init
 					return fmt.Errorf("[ERROR] failed to initialize upload: %w", err)
 				}
 			case usecase.SuggestExistingEntryDeletion:
-				forceDeleted, err := h.handleFileConflict(cmd, ctx, targetFileName)
+				replaces, err := h.handleFileConflict(cmd)
 				if err != nil {
 					cmd.PrintErrf("[ERROR] Failed to handle file conflict: %v\n", err)
 					return nil
 				}
-				if !forceDeleted {
+				if !replaces {
 					cmd.Println("Cancelling the upload...")
 					return nil
 				}
-				// Re-init after deletion
+				// the conflicting file stays readable until the upload completes and replaces it. Its chunks of the
+				// same content are reused, so only the changed chunks are sent
+				if isSingleRequest {
+					return h.uploadInSingleRequest(ctx, cmd, filePath, targetFileName, precheckOutput)
+				}
+				isDelta = true
 				uploadInitOutput, err = h.initUploadUsecase.Execute(ctx, &usecase.InitUploadUsecaseInput{
-					FilePath:         filePath,
-					TargetFileName:   targetFileName,
-					OriginalChecksum: precheckOutput.Checksum,
-					ChunkSize:        chunkSize,
-					IsReUpload:       false, // It's a fresh upload after deletion
+					FilePath:          filePath,
+					TargetFileName:    targetFileName,
+					OriginalChecksum:  precheckOutput.Checksum,
+					ChecksumAlgorithm: precheckOutput.ChecksumAlgorithm,
+					ChunkSize:         chunkSize,
+					CDC:               cdc,
+					IsReUpload:        true,
+					Delta:             true,
 				})
 				if err != nil {
 					cmd.PrintErrf("[ERROR] Failed to initialize upload after conflict resolution: %v\n", err)
@@ -118,9 +181,22 @@ func (h *UploadCommandHandler) Execute() *cobra.Command {
 			}
 
 			// Calculate total size for progress bar
+			isReUpload := uploadInitOutput.IsResumed
 			var uploadChunkSizeTotal int64
 			if isReUpload && uploadInitOutput.MissingChunkNumberMap != nil {
 				uploadChunkSizeTotal = int64(uploadInitOutput.UploadChunkSize * uint64(len(uploadInitOutput.MissingChunkNumberMap)))
+				if uploadInitOutput.ChunkSizes != nil {
+					uploadChunkSizeTotal = 0
+					for chunkNumber := range uploadInitOutput.MissingChunkNumberMap {
+						if chunkNumber < uint64(len(uploadInitOutput.ChunkSizes)) {
+							uploadChunkSizeTotal += uploadInitOutput.ChunkSizes[chunkNumber]
+						}
+					}
+				}
+				// the content the server already received isn't sent again
+				for _, offset := range uploadInitOutput.MissingChunkOffsetMap {
+					uploadChunkSizeTotal -= offset
+				}
 			} else {
 				uploadChunkSizeTotal = precheckOutput.FileSize
 			}
@@ -140,27 +216,35 @@ func (h *UploadCommandHandler) Execute() *cobra.Command {
 			)
 
 			// Execute upload
-			err = h.uploadUsecase.Execute(ctx, &usecase.UploadUsecaseInput{
-				UploadID:              uploadInitOutput.UploadID,
-				FilePath:              filePath,
-				ChunkSize:             chunkSize,
-				IsReUpload:            isReUpload,
-				MissingChunkNumberMap: uploadInitOutput.MissingChunkNumberMap,
-				ProgressCb:            func(size int64) { _ = bar.Add64(size) },
+			interrupted, err := runInterruptible(ctx, func(ctx context.Context) error {
+				return h.uploadUsecase.Execute(ctx, &usecase.UploadUsecaseInput{
+					SessionToken:          uploadInitOutput.SessionToken,
+					FilePath:              filePath,
+					ChunkSize:             int64(uploadInitOutput.ChunkSize),
+					ChunkSizes:            uploadInitOutput.ChunkSizes,
+					IsReUpload:            isReUpload,
+					MissingChunkNumberMap: uploadInitOutput.MissingChunkNumberMap,
+					MissingChunkOffsetMap: uploadInitOutput.MissingChunkOffsetMap,
+					ProgressCb:            func(size int64) { _ = bar.Add64(size) },
+				})
 			})
 			if err != nil {
 				_ = bar.Clear()
-				deleteErr := h.deleteUsecase.Execute(ctx, targetFileName)
-				if deleteErr != nil {
-					cmd.PrintErrf("[ERROR] Failed to delete the partially uploaded file entry: %v\n", deleteErr)
-					cmd.PrintErrf("[ERROR] Upload failed for file '%s': %v\n", targetFileName, err)
+				if interrupted {
+					h.abortUpload(ctx, cmd, targetFileName, uploadInitOutput.SessionToken)
 					return nil
 				}
+				// the chunks uploaded so far are kept in the session until it expires, and the file previously uploaded
+				// with the name (if any) is left as it is
 				cmd.PrintErrf("[ERROR] Upload failed for file '%s': %v\n", targetFileName, err)
+				cmd.PrintErrf("Run the same command again before %s to resume the upload.\n", uploadInitOutput.ExpiresAt.Local().Format(time.DateTime))
 				return nil
 			}
 
 			cmd.Println("Successfully uploaded!")
+			if isDelta {
+				cmd.Printf("Reused %d chunks on the file server: %d of %d bytes were not sent.\n", uploadInitOutput.ReusedChunks, uploadInitOutput.ReusedSize, precheckOutput.FileSize)
+			}
 			return nil
 		},
 	}
@@ -170,15 +254,131 @@ func (h *UploadCommandHandler) Execute() *cobra.Command {
 	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", entity.DefaultMaxConcurrency, "Maximum number of concurrent operations")
 	cmd.Flags().Int64VarP(&chunkSize, "chunk-size", "s", entity.DefaultChunkSize, "Chunk size in bytes")
 	cmd.Flags().StringVarP(&fileName, "file-name", "n", "", "Specify the file name to be used on the server")
-	cmd.Flags().BoolVarP(&compressionEnabled, "compression", "z", entity.DefaultCompressionEnabled, "Enable gzip compression for the file (data will be decompressed on the file server)")
+	cmd.Flags().StringVarP(&compression, "compression", "z", entity.DefaultCompression, fmt.Sprintf("Compression of the content sent to the file server, which decompresses it (%s)", strings.Join(util.CompressionAlgorithms(), ", ")))
+	cmd.Flags().IntVar(&compressionLevel, "compression-level", entity.DefaultCompressionLevel, "Compression level (1-9 for gzip, 1-22 for zstd, 0 for the default of the compression)")
+	cmd.Flags().StringVar(&checksumAlgorithm, "checksum-algorithm", entity.DefaultChecksumAlgorithm, fmt.Sprintf("Checksum algorithm to verify the whole file (%s)", strings.Join(util.ChecksumAlgorithms(), ", ")))
+	cmd.Flags().StringVar(&storageCodec, "storage-codec", "", fmt.Sprintf("Compression the file server stores the file with (%s). The server's default when unset", strings.Join(util.CompressionAlgorithms(), ", ")))
+	cmd.Flags().StringVar(&chunking, "chunking", entity.DefaultChunking, fmt.Sprintf("How the file is split into chunks (%s). cdc cuts the chunks by the content, so the chunks of the unchanged parts stay the same when the file is modified", strings.Join(util.ChunkingMethods(), ", ")))
+	cmd.Flags().Int64Var(&cdcParams.MinSize, "cdc-min-size", entity.DefaultCDCMinSize, "Minimum chunk size in bytes of --chunking=cdc")
+	cmd.Flags().Int64Var(&cdcParams.AvgSize, "cdc-avg-size", entity.DefaultCDCAvgSize, "Average chunk size in bytes of --chunking=cdc")
+	cmd.Flags().Int64Var(&cdcParams.MaxSize, "cdc-max-size", entity.DefaultCDCMaxSize, "Maximum chunk size in bytes of --chunking=cdc")
+	cmd.Flags().Int64Var(&singleRequestThreshold, "single-request-threshold", entity.DefaultSingleRequestThreshold, "Files up to this size in bytes are uploaded in a single request instead of in chunks (0 to always upload in chunks)")
 
 	return cmd
 }
 
-// handleFileConflict now accepts *cobra.Command to use its I/O streams
-func (h *UploadCommandHandler) handleFileConflict(cmd *cobra.Command, ctx context.Context, targetFileName string) (bool, error) {
+// uploadFromStdin uploads the content read from stdin to an open-ended upload session, whose size and checksum are
+// given once all of the content is read. The content can't be compared with the file on the server beforehand, so the
+// file with the name is replaced once the upload completes without confirmation
+func (h *UploadCommandHandler) uploadFromStdin(
+	ctx context.Context,
+	cmd *cobra.Command,
+	targetFileName string,
+	chunkSize int64,
+	checksumAlgorithm string,
+) error {
+	uploadInitOutput, err := h.initUploadUsecase.ExecuteOpenEnded(ctx, &usecase.OpenEndedInitUploadUsecaseInput{
+		TargetFileName:    targetFileName,
+		ChecksumAlgorithm: checksumAlgorithm,
+		ChunkSize:         chunkSize,
+	})
+	if err != nil {
+		return fmt.Errorf("[ERROR] failed to initialize upload: %w", err)
+	}
+
+	// the size of the content is unknown until all of it is read
+	bar := progressbar.NewOptions64(
+		-1,
+		progressbar.OptionSetDescription("Uploading in progress..."),
+		progressbar.OptionSetWriter(cmd.ErrOrStderr()),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionSetWidth(30),
+		progressbar.OptionThrottle(100),
+		progressbar.OptionShowCount(),
+		progressbar.OptionFullWidth(),
+		progressbar.OptionSetRenderBlankState(true),
+		progressbar.OptionClearOnFinish(),
+	)
+
+	interrupted, err := runInterruptible(ctx, func(ctx context.Context) error {
+		return h.uploadUsecase.ExecuteStream(ctx, &usecase.StreamUploadUsecaseInput{
+			SessionToken:      uploadInitOutput.SessionToken,
+			Reader:            cmd.InOrStdin(),
+			ChunkSize:         int64(uploadInitOutput.ChunkSize),
+			ChecksumAlgorithm: checksumAlgorithm,
+			ProgressCb:        func(size int64) { _ = bar.Add64(size) },
+		})
+	})
+	// the spinner is rendered by its own goroutine until the bar is finished, so the bar is finished (and cleared)
+	// before anything else is printed
+	_ = bar.Finish()
+	if err != nil {
+		if interrupted {
+			h.abortUpload(ctx, cmd, targetFileName, uploadInitOutput.SessionToken)
+			return nil
+		}
+		// the content read so far is gone, so the upload can't be resumed. the session is discarded once it expires
+		cmd.PrintErrf("[ERROR] Upload failed for file '%s': %v\n", targetFileName, err)
+		return nil
+	}
+
+	cmd.Println("Successfully uploaded!")
+	return nil
+}
+
+// uploadInSingleRequest uploads the whole file in a single request. The file with the name is replaced only once the
+// request succeeds, so nothing is left to resume when it fails
+func (h *UploadCommandHandler) uploadInSingleRequest(
+	ctx context.Context,
+	cmd *cobra.Command,
+	filePath string,
+	targetFileName string,
+	precheckOutput *usecase.InitUploadPrecheckUsecaseOutput,
+) error {
+	err := h.uploadUsecase.ExecuteSingleRequest(ctx, &usecase.SingleRequestUploadUsecaseInput{
+		FilePath:          filePath,
+		TargetFileName:    targetFileName,
+		OriginalChecksum:  precheckOutput.Checksum,
+		ChecksumAlgorithm: precheckOutput.ChecksumAlgorithm,
+	})
+	if err != nil {
+		cmd.PrintErrf("[ERROR] Upload failed for file '%s': %v\n", targetFileName, err)
+		return nil
+	}
+
+	cmd.Println("Successfully uploaded!")
+	return nil
+}
+
+// runInterruptible runs the upload with the context canceled when the user interrupts it (e.g. Ctrl-C), and reports
+// whether it's interrupted. The interrupt is caught only during the upload, so another one while the upload is being
+// aborted exits the CLI as usual
+func runInterruptible(ctx context.Context, upload func(ctx context.Context) error) (bool, error) {
+	uploadCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := upload(uploadCtx)
+	return uploadCtx.Err() != nil, err
+}
+
+// abortUpload aborts the interrupted upload on the file server, which stops the chunks still being uploaded and
+// deletes the content uploaded so far. The file previously uploaded with the name is left as it is
+func (h *UploadCommandHandler) abortUpload(ctx context.Context, cmd *cobra.Command, targetFileName string, sessionToken string) {
+	cmd.PrintErrf("Upload of '%s' is interrupted. Aborting the upload...\n", targetFileName)
+
+	// the context of the upload is canceled by the interrupt
+	if err := h.uploadUsecase.ExecuteAbort(context.WithoutCancel(ctx), sessionToken); err != nil {
+		cmd.PrintErrf("[ERROR] Failed to abort upload of '%s': %v\n", targetFileName, err)
+		return
+	}
+	cmd.PrintErrln("The upload is aborted, and the content uploaded so far is deleted from the file server.")
+}
+
+// handleFileConflict asks the user whether the conflicting file is replaced by the uploaded one. The conflicting file is
+// replaced only when the upload completes, so nothing is deleted here
+func (h *UploadCommandHandler) handleFileConflict(cmd *cobra.Command) (bool, error) {
 	cmd.PrintErrln("[ERROR] A confilcting file (a file with the same name but different contents) is found on the file server")
-	cmd.PrintErrf("* Do you want to delete the conflicting file and proceed with the upload? (y/n): ")
+	cmd.PrintErrf("* Do you want to replace the conflicting file once the upload completes? (y/n): ")
 
 	// Use bufio.Reader to read from cmd's input stream
 	reader := bufio.NewReader(cmd.InOrStdin())
@@ -188,14 +388,5 @@ func (h *UploadCommandHandler) handleFileConflict(cmd *cobra.Command, ctx contex
 	}
 	input = strings.TrimSpace(input)
 
-	if strings.EqualFold(input, "y") {
-		cmd.Printf("Attempting to delete the conflicting file \"%s\" on the file server...\n", targetFileName)
-		if delErr := h.deleteUsecase.Execute(ctx, targetFileName); delErr != nil {
-			cmd.PrintErrf("[ERROR] failed to delete the conflicting file \"%s\": %v\n", targetFileName, delErr)
-			return false, fmt.Errorf("failed to delete conflicting file")
-		}
-		cmd.Println("Successfully deleted the conflicting file.")
-		return true, nil
-	}
-	return false, nil
+	return strings.EqualFold(input, "y"), nil
 }
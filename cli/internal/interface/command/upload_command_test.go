@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -15,6 +16,7 @@ import (
 	"github.com/tomoya.tokunaga/cli/internal/interface/command"
 	"github.com/tomoya.tokunaga/cli/internal/mock"
 	"github.com/tomoya.tokunaga/cli/internal/usecase"
+	"github.com/tomoya.tokunaga/cli/internal/util"
 )
 
 // Helper function to create a temporary file for testing uploads
@@ -37,7 +39,7 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 	ctx := context.Background()
 	defaultChunkSize := entity.DefaultChunkSize
 	defaultChecksum := "d41d8cd98f00b204e9800998ecf8427e"
-	defaultUploadID := uint64(1)
+	defaultSessionToken := "token-1"
 
 	tests := []struct {
 		name          string
@@ -45,7 +47,8 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 		flags         map[string]string
 		fileContent   string
 		userInput     string
-		mockSetup     func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, deleteMock *mock.MockDeleteUsecase, filePath string, targetFileName string, checksum string, fileSize int64)
+		mockSetup     func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64)
+		interrupted   bool // the user interrupts the upload (e.g. Ctrl-C)
 		expectedOut   []string
 		expectedError error
 	}{
@@ -53,7 +56,7 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 			name:        "Success: Basic upload (ProceedWithInit)",
 			args:        []string{"placeholder"},
 			fileContent: "hello world",
-			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, deleteMock *mock.MockDeleteUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
 				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(_ context.Context, in *usecase.InitUploadPrecheckUsecaseInput) (usecase.PostPrecheckAction, *usecase.InitUploadPrecheckUsecaseOutput, error) {
 						assert.Equal(t, filePath, in.FilePath)
@@ -66,12 +69,12 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 						assert.Equal(t, targetFileName, in.TargetFileName)
 						assert.Equal(t, checksum, in.OriginalChecksum)
 						assert.Equal(t, int64(defaultChunkSize), in.ChunkSize)
-						assert.False(t, in.IsReUpload)
-						return &usecase.UploadUsecaseOutput{UploadID: defaultUploadID, UploadChunkSize: uint64(defaultChunkSize)}, nil
+						assert.True(t, in.IsReUpload)
+						return &usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: uint64(defaultChunkSize), UploadChunkSize: uint64(defaultChunkSize)}, nil
 					})
 				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(_ context.Context, in *usecase.UploadUsecaseInput) error {
-						assert.Equal(t, defaultUploadID, in.UploadID)
+						assert.Equal(t, defaultSessionToken, in.SessionToken)
 						assert.Equal(t, filePath, in.FilePath)
 						assert.Equal(t, int64(defaultChunkSize), in.ChunkSize)
 						assert.False(t, in.IsReUpload)
@@ -85,18 +88,18 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 			expectedOut: []string{"Successfully uploaded!"},
 		},
 		{
-			name:        "Success: Re-upload (ProceedWithReUpload)",
+			name:        "Success: Resumed session uploads only the missing chunks",
 			args:        []string{"placeholder"},
 			fileContent: "hello again",
-			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, deleteMock *mock.MockDeleteUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
 				missingMap := map[uint64]struct{}{1: {}, 3: {}}
 				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
-					Return(usecase.ProceedWithReUpload, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
+					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
 				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(_ context.Context, in *usecase.InitUploadUsecaseInput) (*usecase.UploadUsecaseOutput, error) {
 						assert.True(t, in.IsReUpload)
 						assert.Equal(t, int64(defaultChunkSize), in.ChunkSize)
-						return &usecase.UploadUsecaseOutput{UploadID: defaultUploadID, UploadChunkSize: uint64(defaultChunkSize), MissingChunkNumberMap: missingMap}, nil
+						return &usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: uint64(defaultChunkSize), UploadChunkSize: uint64(defaultChunkSize), IsResumed: true, MissingChunkNumberMap: missingMap}, nil
 					})
 				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(_ context.Context, in *usecase.UploadUsecaseInput) error {
@@ -111,12 +114,37 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 			},
 			expectedOut: []string{"Successfully uploaded!"},
 		},
+		{
+			name:        "Success: Upload session resumed (ProceedWithInit)",
+			args:        []string{"placeholder"},
+			fileContent: "hello from the last attempt",
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+				missingMap := map[uint64]struct{}{0: {}}
+				offsetMap := map[uint64]int64{0: 4}
+				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
+					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
+				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *usecase.InitUploadUsecaseInput) (*usecase.UploadUsecaseOutput, error) {
+						assert.True(t, in.IsReUpload)
+						return &usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: uint64(defaultChunkSize), UploadChunkSize: uint64(defaultChunkSize), IsResumed: true, MissingChunkNumberMap: missingMap, MissingChunkOffsetMap: offsetMap}, nil
+					})
+				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *usecase.UploadUsecaseInput) error {
+						assert.Equal(t, defaultSessionToken, in.SessionToken)
+						assert.True(t, in.IsReUpload)
+						assert.Equal(t, missingMap, in.MissingChunkNumberMap)
+						assert.Equal(t, offsetMap, in.MissingChunkOffsetMap)
+						return nil
+					})
+			},
+			expectedOut: []string{"Successfully uploaded!"},
+		},
 		{
 			name:        "Success: File already exists (Exits)",
 			args:        []string{"placeholder"},
 			fileContent: "identical content",
 			flags:       map[string]string{"file-name": "existing.txt"},
-			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, deleteMock *mock.MockDeleteUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
 				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(_ context.Context, in *usecase.InitUploadPrecheckUsecaseInput) (usecase.PostPrecheckAction, *usecase.InitUploadPrecheckUsecaseOutput, error) {
 						assert.Equal(t, "existing.txt", in.TargetFileName)
@@ -126,27 +154,28 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 			expectedOut: []string{"already uploaded", "Exiting..."},
 		},
 		{
-			name:        "Success: Conflict resolved by user deletion (SuggestExistingEntryDeletion -> y)",
+			name:        "Success: Conflict resolved by user replacement (SuggestExistingEntryDeletion -> y)",
 			args:        []string{"placeholder"},
 			fileContent: "new content for conflicting file",
 			flags:       map[string]string{"file-name": "conflict.txt"},
 			userInput:   "y\n",
-			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, deleteMock *mock.MockDeleteUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
 				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
 					Return(usecase.SuggestExistingEntryDeletion, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
-				deleteMock.EXPECT().Execute(gomock.Any(), targetFileName).Return(nil)
 				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(_ context.Context, in *usecase.InitUploadUsecaseInput) (*usecase.UploadUsecaseOutput, error) {
 						assert.Equal(t, filePath, in.FilePath)
 						assert.Equal(t, targetFileName, in.TargetFileName)
 						assert.Equal(t, checksum, in.OriginalChecksum)
 						assert.Equal(t, int64(defaultChunkSize), in.ChunkSize)
-						assert.False(t, in.IsReUpload)
-						return &usecase.UploadUsecaseOutput{UploadID: defaultUploadID, UploadChunkSize: uint64(defaultChunkSize)}, nil
+						assert.True(t, in.IsReUpload)
+						// the chunks of the conflicting file are reused
+						assert.True(t, in.Delta)
+						return &usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: uint64(defaultChunkSize), UploadChunkSize: uint64(defaultChunkSize)}, nil
 					})
 				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(_ context.Context, in *usecase.UploadUsecaseInput) error {
-						assert.Equal(t, defaultUploadID, in.UploadID)
+						assert.Equal(t, defaultSessionToken, in.SessionToken)
 						assert.Equal(t, filePath, in.FilePath)
 						assert.Equal(t, int64(defaultChunkSize), in.ChunkSize)
 						assert.False(t, in.IsReUpload)
@@ -159,9 +188,42 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 			},
 			expectedOut: []string{
 				"conflicting file",
-				"delete the conflicting file",
-				"Successfully deleted the conflicting file",
+				"replace the conflicting file",
 				"Successfully uploaded!",
+				"Reused 0 chunks on the file server: 0 of 32 bytes were not sent.",
+			},
+		},
+		{
+			name:        "Success: Conflict replaced by sending only changed chunks (SuggestExistingEntryDeletion -> y)",
+			args:        []string{"placeholder"},
+			fileContent: "new content for conflicting file",
+			flags:       map[string]string{"file-name": "conflict.txt", "chunk-size": "8"},
+			userInput:   "y\n",
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
+					Return(usecase.SuggestExistingEntryDeletion, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
+				// only the third chunk differs from the conflicting file
+				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					Return(&usecase.UploadUsecaseOutput{
+						SessionToken:          defaultSessionToken,
+						IsResumed:             true,
+						ChunkSize:             8,
+						UploadChunkSize:       8,
+						MissingChunkNumberMap: map[uint64]struct{}{2: {}},
+						MissingChunkOffsetMap: map[uint64]int64{},
+						ReusedChunks:          3,
+						ReusedSize:            24,
+					}, nil)
+				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *usecase.UploadUsecaseInput) error {
+						assert.True(t, in.IsReUpload)
+						assert.Equal(t, map[uint64]struct{}{2: {}}, in.MissingChunkNumberMap)
+						return nil
+					})
+			},
+			expectedOut: []string{
+				"Successfully uploaded!",
+				"Reused 3 chunks on the file server: 24 of 32 bytes were not sent.",
 			},
 		},
 		{
@@ -170,21 +232,204 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 			fileContent: "different content",
 			flags:       map[string]string{"file-name": "conflict_cancel.txt"},
 			userInput:   "n\n",
-			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, deleteMock *mock.MockDeleteUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
 				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
 					Return(usecase.SuggestExistingEntryDeletion, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
 			},
 			expectedOut: []string{
 				"conflicting file",
-				"delete the conflicting file",
+				"replace the conflicting file",
 				"Cancelling the upload...",
 			},
 		},
+		{
+			name:        "Success: Chunks compressed in zstd at the level",
+			args:        []string{"placeholder"},
+			fileContent: "hello world",
+			flags:       map[string]string{"compression": "zstd", "compression-level": "19"},
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
+					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
+				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					Return(&usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: uint64(defaultChunkSize), UploadChunkSize: uint64(defaultChunkSize)}, nil)
+				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, _ *usecase.UploadUsecaseInput) error {
+						assert.Equal(t, "zstd", ctx.Value(entity.CompressionKey))
+						assert.Equal(t, 19, ctx.Value(entity.CompressionLevelKey))
+						return nil
+					})
+			},
+			expectedOut: []string{"Successfully uploaded!"},
+		},
+		{
+			name:        "Success: -z takes the compression as its value",
+			args:        []string{"placeholder", "-z", "zstd"},
+			fileContent: "hello world",
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
+					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
+				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					Return(&usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: uint64(defaultChunkSize), UploadChunkSize: uint64(defaultChunkSize)}, nil)
+				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, _ *usecase.UploadUsecaseInput) error {
+						assert.Equal(t, "zstd", ctx.Value(entity.CompressionKey))
+						assert.Equal(t, 0, ctx.Value(entity.CompressionLevelKey))
+						return nil
+					})
+			},
+			expectedOut: []string{"Successfully uploaded!"},
+		},
+		{
+			name:        "Error: Compression level out of range",
+			args:        []string{"placeholder"},
+			fileContent: "hello world",
+			flags:       map[string]string{"compression": "gzip", "compression-level": "19"},
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			},
+			expectedError: errors.New("[ERROR] Invalid compression: compression level of gzip needs to be between 1 and 9"),
+		},
+		{
+			name:        "Success: Storage codec asked for on init",
+			args:        []string{"placeholder"},
+			fileContent: "hello world",
+			flags:       map[string]string{"storage-codec": "zstd"},
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
+					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
+				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, _ *usecase.InitUploadUsecaseInput) (*usecase.UploadUsecaseOutput, error) {
+						assert.Equal(t, "zstd", ctx.Value(entity.StorageCodecKey))
+						return &usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: uint64(defaultChunkSize), UploadChunkSize: uint64(defaultChunkSize)}, nil
+					})
+				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedOut: []string{"Successfully uploaded!"},
+		},
+		{
+			name:        "Success: Content-defined chunks asked for on init",
+			args:        []string{"placeholder"},
+			fileContent: "hello world",
+			flags:       map[string]string{"chunking": "cdc", "cdc-min-size": "2", "cdc-avg-size": "4", "cdc-max-size": "8"},
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
+					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
+				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *usecase.InitUploadUsecaseInput) (*usecase.UploadUsecaseOutput, error) {
+						assert.Equal(t, &util.CDCParams{MinSize: 2, AvgSize: 4, MaxSize: 8}, in.CDC)
+						return &usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: 8, ChunkSizes: []int64{5, 6}, UploadChunkSize: 8}, nil
+					})
+				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *usecase.UploadUsecaseInput) error {
+						// the file is read by the sizes of the content-defined chunks
+						assert.Equal(t, []int64{5, 6}, in.ChunkSizes)
+						return nil
+					})
+			},
+			expectedOut: []string{"Successfully uploaded!"},
+		},
+		{
+			name:        "Error: Content-defined chunk sizes out of order",
+			args:        []string{"placeholder"},
+			fileContent: "hello world",
+			flags:       map[string]string{"chunking": "cdc", "cdc-min-size": "8", "cdc-avg-size": "4", "cdc-max-size": "16"},
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			},
+			expectedError: errors.New("[ERROR] Invalid chunking: chunk sizes need to be minimum <= average <= maximum, but got 8, 4 and 16"),
+		},
+		{
+			name:        "Error: Unsupported chunking",
+			args:        []string{"placeholder"},
+			fileContent: "hello world",
+			flags:       map[string]string{"chunking": "rabin"},
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			},
+			expectedError: errors.New(`[ERROR] Invalid chunking: unsupported chunking "rabin" (supported: [cdc fixed])`),
+		},
+		{
+			name:        "Error: Negative concurrency",
+			args:        []string{"placeholder"},
+			fileContent: "hello world",
+			flags:       map[string]string{"concurrency": "-1"},
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			},
+			expectedError: errors.New("[ERROR] Invalid concurrency: -1. Please provide a positive concurrency"),
+		},
+		{
+			name:        "Error: Unsupported storage codec",
+			args:        []string{"placeholder"},
+			fileContent: "hello world",
+			flags:       map[string]string{"storage-codec": "lz4"},
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			},
+			expectedError: errors.New(`[ERROR] Invalid storage codec: unsupported compression "lz4" (supported: [gzip none zstd])`),
+		},
+		{
+			name:        "Success: Small file uploaded in a single request",
+			args:        []string{"placeholder"},
+			fileContent: "small config",
+			flags:       map[string]string{"single-request-threshold": "1024"},
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
+					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, ChecksumAlgorithm: "sha256", FileSize: fileSize}, nil)
+				uploadMock.EXPECT().ExecuteSingleRequest(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *usecase.SingleRequestUploadUsecaseInput) error {
+						assert.Equal(t, filePath, in.FilePath)
+						assert.Equal(t, targetFileName, in.TargetFileName)
+						assert.Equal(t, checksum, in.OriginalChecksum)
+						assert.Equal(t, "sha256", in.ChecksumAlgorithm)
+						return nil
+					})
+			},
+			expectedOut: []string{"Successfully uploaded!"},
+		},
+		{
+			name:        "Success: Conflict replaced in a single request (SuggestExistingEntryDeletion -> y)",
+			args:        []string{"placeholder"},
+			fileContent: "small conflicting config",
+			flags:       map[string]string{"file-name": "conflict_small.txt", "single-request-threshold": "1024"},
+			userInput:   "y\n",
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
+					Return(usecase.SuggestExistingEntryDeletion, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
+				uploadMock.EXPECT().ExecuteSingleRequest(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *usecase.SingleRequestUploadUsecaseInput) error {
+						assert.Equal(t, "conflict_small.txt", in.TargetFileName)
+						return nil
+					})
+			},
+			expectedOut: []string{"replace the conflicting file", "Successfully uploaded!"},
+		},
+		{
+			name:        "Success: File above the threshold uploaded in chunks",
+			args:        []string{"placeholder"},
+			fileContent: "content larger than the threshold",
+			flags:       map[string]string{"single-request-threshold": "8"},
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
+					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
+				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					Return(&usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: uint64(defaultChunkSize), UploadChunkSize: uint64(defaultChunkSize)}, nil)
+				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			expectedOut: []string{"Successfully uploaded!"},
+		},
+		{
+			name:        "Error: Single request upload fails",
+			args:        []string{"placeholder"},
+			fileContent: "small config",
+			flags:       map[string]string{"file-name": "small_fail.txt", "single-request-threshold": "1024"},
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
+					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
+				uploadMock.EXPECT().ExecuteSingleRequest(gomock.Any(), gomock.Any()).Return(errors.New("server returned status 400"))
+			},
+			expectedOut: []string{"[ERROR] Upload failed for file 'small_fail.txt': server returned status 400"},
+		},
 		{
 			name:        "Error: Precheck fails",
 			args:        []string{"placeholder"},
 			fileContent: "precheck fail content",
-			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, deleteMock *mock.MockDeleteUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
 				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
 					Return(usecase.PostPrecheckAction(0), nil, errors.New("precheck failed"))
 			},
@@ -194,7 +439,7 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 			name:        "Error: Init fails",
 			args:        []string{"placeholder"},
 			fileContent: "init fail content",
-			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, deleteMock *mock.MockDeleteUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
 				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
 					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
 				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
@@ -207,17 +452,17 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 			expectedOut:   []string{},
 		},
 		{
-			name:        "Error: Upload fails, delete succeeds",
+			name:        "Error: Upload fails",
 			args:        []string{"placeholder"},
 			fileContent: "upload fail content",
 			flags:       map[string]string{"file-name": "upload_fail.txt"},
-			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, deleteMock *mock.MockDeleteUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
 				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
 					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
 				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(_ context.Context, in *usecase.InitUploadUsecaseInput) (*usecase.UploadUsecaseOutput, error) {
 						assert.Equal(t, int64(defaultChunkSize), in.ChunkSize)
-						return &usecase.UploadUsecaseOutput{UploadID: defaultUploadID, UploadChunkSize: uint64(defaultChunkSize)}, nil
+						return &usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: uint64(defaultChunkSize), UploadChunkSize: uint64(defaultChunkSize)}, nil
 					})
 				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(_ context.Context, in *usecase.UploadUsecaseInput) error {
@@ -227,56 +472,56 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 						}
 						return errors.New("chunk upload failed")
 					})
-				deleteMock.EXPECT().Execute(gomock.Any(), targetFileName).Return(nil)
 			},
 			expectedOut: []string{
 				"[ERROR] Upload failed for file 'upload_fail.txt': chunk upload failed",
+				"to resume the upload",
 			},
 		},
 		{
-			name:        "Error: Upload fails, delete fails",
+			name:        "Interrupted: Upload is aborted on the file server",
 			args:        []string{"placeholder"},
-			fileContent: "upload fail delete fail content",
-			flags:       map[string]string{"file-name": "double_fail.txt"},
-			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, deleteMock *mock.MockDeleteUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			fileContent: "hello world",
+			flags:       map[string]string{"file-name": "interrupted.txt"},
+			interrupted: true,
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
 				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
 					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
 				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
-					DoAndReturn(func(_ context.Context, in *usecase.InitUploadUsecaseInput) (*usecase.UploadUsecaseOutput, error) {
-						assert.Equal(t, int64(defaultChunkSize), in.ChunkSize)
-						return &usecase.UploadUsecaseOutput{UploadID: defaultUploadID, UploadChunkSize: uint64(defaultChunkSize)}, nil
-					})
+					Return(&usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: uint64(defaultChunkSize), UploadChunkSize: uint64(defaultChunkSize)}, nil)
 				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
-					DoAndReturn(func(_ context.Context, in *usecase.UploadUsecaseInput) error {
-						assert.Equal(t, int64(defaultChunkSize), in.ChunkSize)
-						if in.ProgressCb != nil {
-							in.ProgressCb(fileSize / 2)
-						}
-						return errors.New("chunk upload failed again")
+					DoAndReturn(func(ctx context.Context, _ *usecase.UploadUsecaseInput) error {
+						return ctx.Err()
+					})
+				uploadMock.EXPECT().ExecuteAbort(gomock.Any(), defaultSessionToken).
+					DoAndReturn(func(ctx context.Context, _ string) error {
+						// the upload is aborted even though its context is canceled
+						assert.NoError(t, ctx.Err())
+						return nil
 					})
-				deleteMock.EXPECT().Execute(gomock.Any(), targetFileName).Return(errors.New("delete also failed"))
 			},
 			expectedOut: []string{
-				"[ERROR] Failed to delete the partially uploaded file entry: delete also failed",
-				"[ERROR] Upload failed for file 'double_fail.txt': chunk upload failed again",
+				"Upload of 'interrupted.txt' is interrupted. Aborting the upload...",
+				"The upload is aborted, and the content uploaded so far is deleted from the file server.",
 			},
 		},
 		{
-			name:        "Error: Conflict resolution fails (delete error)",
+			name:        "Interrupted: Abort fails",
 			args:        []string{"placeholder"},
-			fileContent: "conflict delete fail content",
-			flags:       map[string]string{"file-name": "conflict_del_fail.txt"},
-			userInput:   "y\n",
-			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, deleteMock *mock.MockDeleteUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
+			fileContent: "hello world",
+			flags:       map[string]string{"file-name": "interrupted.txt"},
+			interrupted: true,
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase, filePath string, targetFileName string, checksum string, fileSize int64) {
 				initMock.EXPECT().ExecutePrecheck(gomock.Any(), gomock.Any()).
-					Return(usecase.SuggestExistingEntryDeletion, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
-				deleteMock.EXPECT().Execute(gomock.Any(), targetFileName).Return(errors.New("delete conflict failed"))
+					Return(usecase.ProceedWithInit, &usecase.InitUploadPrecheckUsecaseOutput{Checksum: checksum, FileSize: fileSize}, nil)
+				initMock.EXPECT().Execute(gomock.Any(), gomock.Any()).
+					Return(&usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: uint64(defaultChunkSize), UploadChunkSize: uint64(defaultChunkSize)}, nil)
+				uploadMock.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(context.Canceled)
+				uploadMock.EXPECT().ExecuteAbort(gomock.Any(), defaultSessionToken).Return(errors.New("server returned status 409"))
 			},
 			expectedOut: []string{
-				"conflicting file",
-				"Attempting to delete the conflicting file",
-				"[ERROR] failed to delete the conflicting file \"conflict_del_fail.txt\": delete conflict failed",
-				"[ERROR] Failed to handle file conflict: failed to delete conflicting file",
+				"Upload of 'interrupted.txt' is interrupted. Aborting the upload...",
+				"[ERROR] Failed to abort upload of 'interrupted.txt': server returned status 409",
 			},
 		},
 	}
@@ -290,7 +535,6 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 
 			mockInitUsecase := mock.NewMockInitUploadUsecase(ctrl)
 			mockUploadUsecase := mock.NewMockUploadUsecase(ctrl)
-			mockDeleteUsecase := mock.NewMockDeleteUsecase(ctrl)
 
 			tempFilePath := createTempFile(t, tc.fileContent)
 			fileBaseName := filepath.Base(tempFilePath)
@@ -302,9 +546,9 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 				targetFileName = nameFlag
 			}
 
-			tc.mockSetup(mockInitUsecase, mockUploadUsecase, mockDeleteUsecase, tempFilePath, targetFileName, checksum, fileSize)
+			tc.mockSetup(mockInitUsecase, mockUploadUsecase, tempFilePath, targetFileName, checksum, fileSize)
 
-			handler := command.NewUploadCommandHandler(mockInitUsecase, mockUploadUsecase, mockDeleteUsecase)
+			handler := command.NewUploadCommandHandler(mockInitUsecase, mockUploadUsecase)
 			cmd := handler.Execute()
 
 			var in bytes.Buffer
@@ -324,11 +568,20 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 			}
 			cmd.SetArgs(finalArgs)
 
+			// the chunked upload is tested with small files, so the single-request upload is disabled unless the case
+			// sets the threshold
+			cmd.Flags().Set("single-request-threshold", "0")
 			for key, val := range tc.flags {
 				cmd.Flags().Set(key, val)
 			}
 
-			err := cmd.ExecuteContext(ctx)
+			caseCtx := ctx
+			if tc.interrupted {
+				interruptedCtx, cancel := context.WithCancel(ctx)
+				cancel()
+				caseCtx = interruptedCtx
+			}
+			err := cmd.ExecuteContext(caseCtx)
 
 			outputStr := out.String()
 			for _, expectedSubstr := range tc.expectedOut {
@@ -344,3 +597,119 @@ func TestUploadCommandHandler_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadCommandHandler_ExecuteStdin(t *testing.T) {
+	ctx := context.Background()
+	defaultSessionToken := "token-1"
+
+	tests := []struct {
+		name        string
+		flags       map[string]string
+		stdin       string
+		mockSetup   func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase)
+		interrupted bool // the user interrupts the upload (e.g. Ctrl-C)
+		expectedOut []string
+	}{
+		{
+			name:  "Success: Upload from stdin",
+			flags: map[string]string{"file-name": "backup.tar", "chunk-size": "4", "checksum-algorithm": "xxhash64"},
+			stdin: "hello world",
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase) {
+				initMock.EXPECT().ExecuteOpenEnded(gomock.Any(), &usecase.OpenEndedInitUploadUsecaseInput{
+					TargetFileName: "backup.tar", ChecksumAlgorithm: "xxhash64", ChunkSize: 4,
+				}).Return(&usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: 4, UploadChunkSize: 4}, nil)
+				uploadMock.EXPECT().ExecuteStream(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, in *usecase.StreamUploadUsecaseInput) error {
+						assert.Equal(t, defaultSessionToken, in.SessionToken)
+						assert.Equal(t, int64(4), in.ChunkSize)
+						assert.Equal(t, "xxhash64", in.ChecksumAlgorithm)
+						content, err := io.ReadAll(in.Reader)
+						assert.NoError(t, err)
+						assert.Equal(t, "hello world", string(content))
+						in.ProgressCb(int64(len(content)))
+						return nil
+					})
+			},
+			expectedOut: []string{"Successfully uploaded!"},
+		},
+		{
+			name:      "Error: No file name given",
+			stdin:     "hello world",
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase) {},
+			expectedOut: []string{
+				"[ERROR] --file-name is required to upload the content from stdin",
+			},
+		},
+		{
+			name:  "Error: Upload fails",
+			flags: map[string]string{"file-name": "backup.tar"},
+			stdin: "hello world",
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase) {
+				initMock.EXPECT().ExecuteOpenEnded(gomock.Any(), gomock.Any()).
+					Return(&usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: 4, UploadChunkSize: 4}, nil)
+				uploadMock.EXPECT().ExecuteStream(gomock.Any(), gomock.Any()).Return(errors.New("failed to commit upload"))
+			},
+			expectedOut: []string{
+				"[ERROR] Upload failed for file 'backup.tar': failed to commit upload",
+			},
+		},
+		{
+			name:        "Interrupted: Upload is aborted on the file server",
+			flags:       map[string]string{"file-name": "backup.tar"},
+			stdin:       "hello world",
+			interrupted: true,
+			mockSetup: func(initMock *mock.MockInitUploadUsecase, uploadMock *mock.MockUploadUsecase) {
+				initMock.EXPECT().ExecuteOpenEnded(gomock.Any(), gomock.Any()).
+					Return(&usecase.UploadUsecaseOutput{SessionToken: defaultSessionToken, ChunkSize: 4, UploadChunkSize: 4}, nil)
+				uploadMock.EXPECT().ExecuteStream(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, _ *usecase.StreamUploadUsecaseInput) error {
+						return ctx.Err()
+					})
+				uploadMock.EXPECT().ExecuteAbort(gomock.Any(), defaultSessionToken).Return(nil)
+			},
+			expectedOut: []string{
+				"Upload of 'backup.tar' is interrupted. Aborting the upload...",
+				"The upload is aborted, and the content uploaded so far is deleted from the file server.",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockInitUsecase := mock.NewMockInitUploadUsecase(ctrl)
+			mockUploadUsecase := mock.NewMockUploadUsecase(ctrl)
+			tc.mockSetup(mockInitUsecase, mockUploadUsecase)
+
+			handler := command.NewUploadCommandHandler(mockInitUsecase, mockUploadUsecase)
+			cmd := handler.Execute()
+			cmd.SetIn(bytes.NewBufferString(tc.stdin))
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetErr(&out)
+			cmd.SetArgs([]string{"-"})
+			for key, val := range tc.flags {
+				cmd.Flags().Set(key, val)
+			}
+
+			caseCtx := ctx
+			if tc.interrupted {
+				interruptedCtx, cancel := context.WithCancel(ctx)
+				cancel()
+				caseCtx = interruptedCtx
+			}
+			err := cmd.ExecuteContext(caseCtx)
+			assert.NoError(t, err)
+
+			outputStr := out.String()
+			for _, expectedSubstr := range tc.expectedOut {
+				assert.Contains(t, outputStr, expectedSubstr, "Output should contain substring")
+			}
+		})
+	}
+}
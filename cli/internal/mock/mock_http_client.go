@@ -42,6 +42,34 @@ func (m *MockFileServerHttpClient) EXPECT() *MockFileServerHttpClientMockRecorde
 	return m.recorder
 }
 
+// AbortUpload mocks base method.
+func (m *MockFileServerHttpClient) AbortUpload(ctx context.Context, sessionToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AbortUpload", ctx, sessionToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AbortUpload indicates an expected call of AbortUpload.
+func (mr *MockFileServerHttpClientMockRecorder) AbortUpload(ctx, sessionToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbortUpload", reflect.TypeOf((*MockFileServerHttpClient)(nil).AbortUpload), ctx, sessionToken)
+}
+
+// CommitUpload mocks base method.
+func (m *MockFileServerHttpClient) CommitUpload(ctx context.Context, sessionToken string, totalSize int64, checksum string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitUpload", ctx, sessionToken, totalSize, checksum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitUpload indicates an expected call of CommitUpload.
+func (mr *MockFileServerHttpClientMockRecorder) CommitUpload(ctx, sessionToken, totalSize, checksum any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitUpload", reflect.TypeOf((*MockFileServerHttpClient)(nil).CommitUpload), ctx, sessionToken, totalSize, checksum)
+}
+
 // DeleteFile mocks base method.
 func (m *MockFileServerHttpClient) DeleteFile(ctx context.Context, fileName string) error {
 	m.ctrl.T.Helper()
@@ -56,6 +84,36 @@ func (mr *MockFileServerHttpClientMockRecorder) DeleteFile(ctx, fileName any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockFileServerHttpClient)(nil).DeleteFile), ctx, fileName)
 }
 
+// DownloadChunk mocks base method.
+func (m *MockFileServerHttpClient) DownloadChunk(ctx context.Context, fileName string, offset, length int64) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadChunk", ctx, fileName, offset, length)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadChunk indicates an expected call of DownloadChunk.
+func (mr *MockFileServerHttpClientMockRecorder) DownloadChunk(ctx, fileName, offset, length any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadChunk", reflect.TypeOf((*MockFileServerHttpClient)(nil).DownloadChunk), ctx, fileName, offset, length)
+}
+
+// GetFileManifest mocks base method.
+func (m *MockFileServerHttpClient) GetFileManifest(ctx context.Context, fileName string) (*entity.FileManifestResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileManifest", ctx, fileName)
+	ret0, _ := ret[0].(*entity.FileManifestResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileManifest indicates an expected call of GetFileManifest.
+func (mr *MockFileServerHttpClientMockRecorder) GetFileManifest(ctx, fileName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileManifest", reflect.TypeOf((*MockFileServerHttpClient)(nil).GetFileManifest), ctx, fileName)
+}
+
 // GetFileStats mocks base method.
 func (m *MockFileServerHttpClient) GetFileStats(ctx context.Context, fileName string) (*entity.FileStatsResp, error) {
 	m.ctrl.T.Helper()
@@ -71,6 +129,21 @@ func (mr *MockFileServerHttpClientMockRecorder) GetFileStats(ctx, fileName any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileStats", reflect.TypeOf((*MockFileServerHttpClient)(nil).GetFileStats), ctx, fileName)
 }
 
+// GetUploadLimits mocks base method.
+func (m *MockFileServerHttpClient) GetUploadLimits(ctx context.Context) (*entity.UploadLimitsResp, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUploadLimits", ctx)
+	ret0, _ := ret[0].(*entity.UploadLimitsResp)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUploadLimits indicates an expected call of GetUploadLimits.
+func (mr *MockFileServerHttpClientMockRecorder) GetUploadLimits(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUploadLimits", reflect.TypeOf((*MockFileServerHttpClient)(nil).GetUploadLimits), ctx)
+}
+
 // InitUpload mocks base method.
 func (m *MockFileServerHttpClient) InitUpload(ctx context.Context, fileName string, request infrastructure.UploadInitRequest) (*infrastructure.UploadInitResponse, error) {
 	m.ctrl.T.Helper()
@@ -101,16 +174,30 @@ func (mr *MockFileServerHttpClientMockRecorder) ListFiles(ctx any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFiles", reflect.TypeOf((*MockFileServerHttpClient)(nil).ListFiles), ctx)
 }
 
+// PutFile mocks base method.
+func (m *MockFileServerHttpClient) PutFile(ctx context.Context, fileName string, data []byte, checksum, checksumAlgorithm string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutFile", ctx, fileName, data, checksum, checksumAlgorithm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutFile indicates an expected call of PutFile.
+func (mr *MockFileServerHttpClientMockRecorder) PutFile(ctx, fileName, data, checksum, checksumAlgorithm any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutFile", reflect.TypeOf((*MockFileServerHttpClient)(nil).PutFile), ctx, fileName, data, checksum, checksumAlgorithm)
+}
+
 // UploadChunk mocks base method.
-func (m *MockFileServerHttpClient) UploadChunk(ctx context.Context, uploadID uint64, chunkID int, data []byte) error {
+func (m *MockFileServerHttpClient) UploadChunk(ctx context.Context, sessionToken string, chunkID int, data []byte, offset int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UploadChunk", ctx, uploadID, chunkID, data)
+	ret := m.ctrl.Call(m, "UploadChunk", ctx, sessionToken, chunkID, data, offset)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UploadChunk indicates an expected call of UploadChunk.
-func (mr *MockFileServerHttpClientMockRecorder) UploadChunk(ctx, uploadID, chunkID, data any) *gomock.Call {
+func (mr *MockFileServerHttpClientMockRecorder) UploadChunk(ctx, sessionToken, chunkID, data, offset any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadChunk", reflect.TypeOf((*MockFileServerHttpClient)(nil).UploadChunk), ctx, uploadID, chunkID, data)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadChunk", reflect.TypeOf((*MockFileServerHttpClient)(nil).UploadChunk), ctx, sessionToken, chunkID, data, offset)
 }
@@ -96,6 +96,21 @@ func (mr *MockInitUploadUsecaseMockRecorder) Execute(ctx, input any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockInitUploadUsecase)(nil).Execute), ctx, input)
 }
 
+// ExecuteOpenEnded mocks base method.
+func (m *MockInitUploadUsecase) ExecuteOpenEnded(ctx context.Context, input *usecase.OpenEndedInitUploadUsecaseInput) (*usecase.UploadUsecaseOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteOpenEnded", ctx, input)
+	ret0, _ := ret[0].(*usecase.UploadUsecaseOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteOpenEnded indicates an expected call of ExecuteOpenEnded.
+func (mr *MockInitUploadUsecaseMockRecorder) ExecuteOpenEnded(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteOpenEnded", reflect.TypeOf((*MockInitUploadUsecase)(nil).ExecuteOpenEnded), ctx, input)
+}
+
 // ExecutePrecheck mocks base method.
 func (m *MockInitUploadUsecase) ExecutePrecheck(ctx context.Context, input *usecase.InitUploadPrecheckUsecaseInput) (usecase.PostPrecheckAction, *usecase.InitUploadPrecheckUsecaseOutput, error) {
 	m.ctrl.T.Helper()
@@ -150,6 +165,101 @@ func (mr *MockUploadUsecaseMockRecorder) Execute(ctx, input any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockUploadUsecase)(nil).Execute), ctx, input)
 }
 
+// ExecuteAbort mocks base method.
+func (m *MockUploadUsecase) ExecuteAbort(ctx context.Context, sessionToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteAbort", ctx, sessionToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecuteAbort indicates an expected call of ExecuteAbort.
+func (mr *MockUploadUsecaseMockRecorder) ExecuteAbort(ctx, sessionToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteAbort", reflect.TypeOf((*MockUploadUsecase)(nil).ExecuteAbort), ctx, sessionToken)
+}
+
+// ExecuteSingleRequest mocks base method.
+func (m *MockUploadUsecase) ExecuteSingleRequest(ctx context.Context, input *usecase.SingleRequestUploadUsecaseInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteSingleRequest", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecuteSingleRequest indicates an expected call of ExecuteSingleRequest.
+func (mr *MockUploadUsecaseMockRecorder) ExecuteSingleRequest(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteSingleRequest", reflect.TypeOf((*MockUploadUsecase)(nil).ExecuteSingleRequest), ctx, input)
+}
+
+// ExecuteStream mocks base method.
+func (m *MockUploadUsecase) ExecuteStream(ctx context.Context, input *usecase.StreamUploadUsecaseInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteStream", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecuteStream indicates an expected call of ExecuteStream.
+func (mr *MockUploadUsecaseMockRecorder) ExecuteStream(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteStream", reflect.TypeOf((*MockUploadUsecase)(nil).ExecuteStream), ctx, input)
+}
+
+// MockDownloadUsecase is a mock of DownloadUsecase interface.
+type MockDownloadUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockDownloadUsecaseMockRecorder
+	isgomock struct{}
+}
+
+// MockDownloadUsecaseMockRecorder is the mock recorder for MockDownloadUsecase.
+type MockDownloadUsecaseMockRecorder struct {
+	mock *MockDownloadUsecase
+}
+
+// NewMockDownloadUsecase creates a new mock instance.
+func NewMockDownloadUsecase(ctrl *gomock.Controller) *MockDownloadUsecase {
+	mock := &MockDownloadUsecase{ctrl: ctrl}
+	mock.recorder = &MockDownloadUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDownloadUsecase) EXPECT() *MockDownloadUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Execute mocks base method.
+func (m *MockDownloadUsecase) Execute(ctx context.Context, input *usecase.DownloadUsecaseInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Execute", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Execute indicates an expected call of Execute.
+func (mr *MockDownloadUsecaseMockRecorder) Execute(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockDownloadUsecase)(nil).Execute), ctx, input)
+}
+
+// ExecutePrecheck mocks base method.
+func (m *MockDownloadUsecase) ExecutePrecheck(ctx context.Context, input *usecase.DownloadPrecheckUsecaseInput) (*usecase.DownloadPrecheckUsecaseOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecutePrecheck", ctx, input)
+	ret0, _ := ret[0].(*usecase.DownloadPrecheckUsecaseOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecutePrecheck indicates an expected call of ExecutePrecheck.
+func (mr *MockDownloadUsecaseMockRecorder) ExecutePrecheck(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecutePrecheck", reflect.TypeOf((*MockDownloadUsecase)(nil).ExecutePrecheck), ctx, input)
+}
+
 // MockDeleteUsecase is a mock of DeleteUsecase interface.
 type MockDeleteUsecase struct {
 	ctrl     *gomock.Controller
@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tomoya.tokunaga/cli/internal/domain/entity"
+	"github.com/tomoya.tokunaga/cli/internal/infrastructure"
+	"github.com/tomoya.tokunaga/cli/internal/util"
+)
+
+// While downloading, the content is written to the partial file and the numbers of the chunks already written are
+// appended to the download log, so that an interrupted download can be resumed from where it stopped
+const (
+	partialFileSuffix = ".part"
+	downloadLogSuffix = ".part.log"
+)
+
+// DownloadUsecase handles file download operations
+type downloadUsecase struct {
+	fileServerHttpClient infrastructure.FileServerHttpClient
+}
+
+// NewDownloadUsecase creates a new download usecase
+func NewDownloadUsecase(fileClient infrastructure.FileServerHttpClient) *downloadUsecase {
+	return &downloadUsecase{
+		fileServerHttpClient: fileClient,
+	}
+}
+
+// ExecutePrecheck checks the file on the server and the local files left by a previous download attempt
+func (s *downloadUsecase) ExecutePrecheck(ctx context.Context, input *DownloadPrecheckUsecaseInput) (*DownloadPrecheckUsecaseOutput, error) {
+	fileStats, err := s.fileServerHttpClient.GetFileStats(ctx, input.TargetFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file stats for '%s': %w", input.TargetFileName, err)
+	}
+	if fileStats == nil {
+		return nil, fmt.Errorf("'%s' not found on the file server", input.TargetFileName)
+	}
+	if fileStats.Status != entity.FileStatusUploaded {
+		return nil, fmt.Errorf("'%s' is in %s status and cannot be downloaded", input.TargetFileName, fileStats.Status)
+	}
+
+	// the servers which only support sha256 don't report the checksum algorithm
+	checksumAlgorithm := fileStats.ChecksumAlgorithm
+	if checksumAlgorithm == "" {
+		checksumAlgorithm = entity.DefaultChecksumAlgorithm
+	}
+
+	output := &DownloadPrecheckUsecaseOutput{
+		Checksum:                fileStats.Checksum,
+		ChecksumAlgorithm:       checksumAlgorithm,
+		FileSize:                int64(fileStats.Size),
+		ChunkSize:               input.ChunkSize,
+		CompletedChunkNumberMap: make(map[uint64]struct{}),
+	}
+
+	// checks the local file doesn't exist, or it's the same as the file on the server
+	if _, err := os.Stat(input.FilePath); err == nil {
+		checksum, err := util.CalculateChecksum(input.FilePath, checksumAlgorithm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate file checksum: %w", err)
+		}
+		if checksum != fileStats.Checksum {
+			return nil, fmt.Errorf("local file '%s' already exists with different content", input.FilePath)
+		}
+		output.IsDownloaded = true
+		return output, nil
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	// resumes the previous download attempt only when it was for the same content
+	partialFilePath := input.FilePath + partialFileSuffix
+	downloadLogPath := input.FilePath + downloadLogSuffix
+	dlLog, err := readDownloadLog(downloadLogPath)
+	if err != nil {
+		return nil, err
+	}
+	_, partialFileErr := os.Stat(partialFilePath)
+	if dlLog != nil && partialFileErr == nil && dlLog.checksum == fileStats.Checksum && dlLog.fileSize == output.FileSize {
+		output.ChunkSize = dlLog.chunkSize
+		output.CompletedChunkNumberMap = dlLog.completedChunkNumberMap
+		for chunkNumber := range dlLog.completedChunkNumberMap {
+			_, length := chunkRange(chunkNumber, output.ChunkSize, output.FileSize)
+			output.DownloadedSize += length
+		}
+		return output, nil
+	}
+
+	// otherwise, the leftovers of the previous attempt are discarded
+	for _, path := range []string{partialFilePath, downloadLogPath} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove '%s': %w", path, err)
+		}
+	}
+
+	return output, nil
+}
+
+// Execute downloads the chunks of a file in parallel, and verifies the checksum of the downloaded content
+func (s *downloadUsecase) Execute(ctx context.Context, input *DownloadUsecaseInput) error {
+	partialFilePath := input.FilePath + partialFileSuffix
+	downloadLogPath := input.FilePath + downloadLogSuffix
+
+	if err := s.downloadChunks(ctx, input, partialFilePath, downloadLogPath); err != nil {
+		return err
+	}
+
+	checksum, err := util.CalculateChecksum(partialFilePath, input.ChecksumAlgorithm)
+	if err != nil {
+		return fmt.Errorf("failed to calculate file checksum: %w", err)
+	}
+	if checksum != input.Checksum {
+		// the downloaded content is broken, so the next attempt starts over
+		_ = os.Remove(partialFilePath)
+		_ = os.Remove(downloadLogPath)
+		return fmt.Errorf("checksum mismatch for '%s': expected %s, but got %s", input.TargetFileName, input.Checksum, checksum)
+	}
+
+	if err := os.Rename(partialFilePath, input.FilePath); err != nil {
+		return fmt.Errorf("failed to move the downloaded file to '%s': %w", input.FilePath, err)
+	}
+	if err := os.Remove(downloadLogPath); err != nil {
+		return fmt.Errorf("failed to remove '%s': %w", downloadLogPath, err)
+	}
+
+	return nil
+}
+
+// downloadChunks downloads the chunks which are not completed yet and writes them to the partial file at their offsets
+func (s *downloadUsecase) downloadChunks(ctx context.Context, input *DownloadUsecaseInput, partialFilePath string, downloadLogPath string) error {
+	file, err := os.OpenFile(partialFilePath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Println("Failed to close file")
+		}
+	}()
+
+	// Preallocates the file so that the chunks can be written at their offsets in any order
+	if err := file.Truncate(input.FileSize); err != nil {
+		return fmt.Errorf("failed to allocate file: %w", err)
+	}
+
+	logFile, err := openDownloadLog(downloadLogPath, input)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := logFile.Close(); err != nil {
+			fmt.Println("Failed to close file")
+		}
+	}()
+
+	// Retrieve flag values from context
+	concurrency := ctx.Value(entity.ConcurrencyKey).(int)
+	retries := ctx.Value(entity.RetriesKey).(int)
+
+	chunkNumbersChan := make(chan uint64, concurrency)
+	errorChan := make(chan error, concurrency)
+
+	var wg sync.WaitGroup
+	var logMutex sync.Mutex
+
+	for range make([]struct{}, concurrency) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunkNumber := range chunkNumbersChan {
+				offset, length := chunkRange(chunkNumber, input.ChunkSize, input.FileSize)
+
+				var data []byte
+				var downloadErr error
+				for retry := 0; retry <= retries; retry++ {
+					data, downloadErr = s.fileServerHttpClient.DownloadChunk(ctx, input.TargetFileName, offset, length)
+					if downloadErr == nil {
+						break
+					}
+
+					if retry < retries {
+						time.Sleep(time.Second * time.Duration(retry+1))
+					}
+				}
+
+				if downloadErr != nil {
+					errorChan <- fmt.Errorf("failed to download chunk %d after %d retries: %w",
+						chunkNumber, retries, downloadErr)
+					return
+				}
+
+				if _, err := file.WriteAt(data, offset); err != nil {
+					errorChan <- fmt.Errorf("failed to write chunk %d: %w", chunkNumber, err)
+					return
+				}
+
+				// records the chunk as completed only after it's written to the file
+				logMutex.Lock()
+				_, err := fmt.Fprintf(logFile, "%d\n", chunkNumber)
+				logMutex.Unlock()
+				if err != nil {
+					errorChan <- fmt.Errorf("failed to record chunk %d: %w", chunkNumber, err)
+					return
+				}
+
+				if input.ProgressCb != nil {
+					input.ProgressCb(int64(len(data)))
+				}
+			}
+		}()
+	}
+
+	numChunks := uint64((input.FileSize + input.ChunkSize - 1) / input.ChunkSize)
+	var sendErr error
+	for chunkNumber := uint64(0); chunkNumber < numChunks && sendErr == nil; chunkNumber++ {
+		// skips the chunks downloaded by the previous attempt
+		if _, completed := input.CompletedChunkNumberMap[chunkNumber]; completed {
+			continue
+		}
+
+		select {
+		case chunkNumbersChan <- chunkNumber:
+			// Chunk enqueued successfully
+		case sendErr = <-errorChan:
+			// A worker encountered an error
+		}
+	}
+
+	// Waits for the workers so that none of them writes to the files after they are closed
+	close(chunkNumbersChan)
+	wg.Wait()
+	close(errorChan)
+
+	if sendErr != nil {
+		return sendErr
+	}
+	if err, ok := <-errorChan; ok {
+		return err
+	}
+
+	return file.Sync()
+}
+
+// chunkRange returns the offset and the length of the chunk in the file
+func chunkRange(chunkNumber uint64, chunkSize int64, fileSize int64) (int64, int64) {
+	offset := int64(chunkNumber) * chunkSize
+	return offset, min(chunkSize, fileSize-offset)
+}
+
+// downloadLog represents the content of the download log
+type downloadLog struct {
+	checksum                string
+	fileSize                int64
+	chunkSize               int64
+	completedChunkNumberMap map[uint64]struct{}
+}
+
+// readDownloadLog reads the download log whose first line is "<checksum> <file size> <chunk size>" followed by the
+// numbers of the completed chunks, one per line. It returns nil when the log doesn't exist or is broken.
+func readDownloadLog(path string) (*downloadLog, error) {
+	logFile, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open download log: %w", err)
+	}
+	defer func() {
+		if err := logFile.Close(); err != nil {
+			fmt.Println("Failed to close file")
+		}
+	}()
+
+	scanner := bufio.NewScanner(logFile)
+	if !scanner.Scan() {
+		return nil, nil
+	}
+	header := strings.Fields(scanner.Text())
+	if len(header) != 3 {
+		return nil, nil
+	}
+	fileSize, err := strconv.ParseInt(header[1], 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	chunkSize, err := strconv.ParseInt(header[2], 10, 64)
+	if err != nil || chunkSize <= 0 {
+		return nil, nil
+	}
+
+	dlLog := &downloadLog{
+		checksum:                header[0],
+		fileSize:                fileSize,
+		chunkSize:               chunkSize,
+		completedChunkNumberMap: make(map[uint64]struct{}),
+	}
+	for scanner.Scan() {
+		// a line being written when the previous attempt was interrupted can be broken, so it's just skipped
+		chunkNumber, err := strconv.ParseUint(strings.TrimSpace(scanner.Text()), 10, 64)
+		if err != nil {
+			continue
+		}
+		dlLog.completedChunkNumberMap[chunkNumber] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read download log: %w", err)
+	}
+
+	return dlLog, nil
+}
+
+// openDownloadLog opens the download log to append completed chunks, and writes its header when it's new
+func openDownloadLog(path string, input *DownloadUsecaseInput) (*os.File, error) {
+	logFile, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open download log: %w", err)
+	}
+
+	info, err := logFile.Stat()
+	if err != nil {
+		_ = logFile.Close()
+		return nil, fmt.Errorf("failed to get download log info: %w", err)
+	}
+	if info.Size() == 0 {
+		if _, err := fmt.Fprintf(logFile, "%s %d %d\n", input.Checksum, input.FileSize, input.ChunkSize); err != nil {
+			_ = logFile.Close()
+			return nil, fmt.Errorf("failed to write download log: %w", err)
+		}
+	}
+
+	return logFile, nil
+}
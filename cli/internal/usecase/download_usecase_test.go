@@ -0,0 +1,301 @@
+package usecase_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tomoya.tokunaga/cli/internal/domain/entity"
+	"github.com/tomoya.tokunaga/cli/internal/mock"
+	"github.com/tomoya.tokunaga/cli/internal/usecase"
+	"go.uber.org/mock/gomock"
+)
+
+const downloadContent = "hello world"
+
+func downloadChecksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloadUsecase_ExecutePrecheck(t *testing.T) {
+	uploadedStats := &entity.FileStatsResp{
+		Name:     "test.txt",
+		Size:     uint64(len(downloadContent)),
+		Checksum: downloadChecksum(downloadContent),
+		Status:   entity.FileStatusUploaded,
+	}
+
+	tests := []struct {
+		name              string
+		setupFiles        func(filePath string)
+		mockSetup         func(mockClient *mock.MockFileServerHttpClient)
+		expectedOutput    *usecase.DownloadPrecheckUsecaseOutput
+		expectedErrString string
+	}{
+		{
+			name: "When the file is not found on the server, should return error",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetFileStats(gomock.Any(), "test.txt").Return(nil, nil)
+			},
+			expectedErrString: "'test.txt' not found on the file server",
+		},
+		{
+			name: "When the file is not uploaded yet, should return error",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetFileStats(gomock.Any(), "test.txt").Return(&entity.FileStatsResp{
+					Name:   "test.txt",
+					Status: entity.FileStatusInProgress,
+				}, nil)
+			},
+			expectedErrString: "cannot be downloaded",
+		},
+		{
+			name: "When getting the file stats fails, should return error",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetFileStats(gomock.Any(), "test.txt").Return(nil, errors.New("connection refused"))
+			},
+			expectedErrString: "failed to get file stats",
+		},
+		{
+			name: "When nothing is downloaded yet, should start a new download",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetFileStats(gomock.Any(), "test.txt").Return(uploadedStats, nil)
+			},
+			expectedOutput: &usecase.DownloadPrecheckUsecaseOutput{
+				Checksum:                uploadedStats.Checksum,
+				ChecksumAlgorithm:       "sha256",
+				FileSize:                int64(len(downloadContent)),
+				ChunkSize:               4,
+				CompletedChunkNumberMap: map[uint64]struct{}{},
+			},
+		},
+		{
+			name: "When the same file is already downloaded, should report it",
+			setupFiles: func(filePath string) {
+				_ = os.WriteFile(filePath, []byte(downloadContent), 0644)
+			},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetFileStats(gomock.Any(), "test.txt").Return(uploadedStats, nil)
+			},
+			expectedOutput: &usecase.DownloadPrecheckUsecaseOutput{
+				Checksum:                uploadedStats.Checksum,
+				ChecksumAlgorithm:       "sha256",
+				FileSize:                int64(len(downloadContent)),
+				ChunkSize:               4,
+				IsDownloaded:            true,
+				CompletedChunkNumberMap: map[uint64]struct{}{},
+			},
+		},
+		{
+			name: "When the same file is already downloaded and its checksum is of other algorithm, should report it",
+			setupFiles: func(filePath string) {
+				_ = os.WriteFile(filePath, []byte(downloadContent), 0644)
+			},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetFileStats(gomock.Any(), "test.txt").Return(&entity.FileStatsResp{
+					Name:              "test.txt",
+					Size:              uint64(len(downloadContent)),
+					Checksum:          "c99465aa", // CRC32C of "hello world"
+					ChecksumAlgorithm: "crc32c",
+					Status:            entity.FileStatusUploaded,
+				}, nil)
+			},
+			expectedOutput: &usecase.DownloadPrecheckUsecaseOutput{
+				Checksum:                "c99465aa",
+				ChecksumAlgorithm:       "crc32c",
+				FileSize:                int64(len(downloadContent)),
+				ChunkSize:               4,
+				IsDownloaded:            true,
+				CompletedChunkNumberMap: map[uint64]struct{}{},
+			},
+		},
+		{
+			name: "When a different local file exists, should return error",
+			setupFiles: func(filePath string) {
+				_ = os.WriteFile(filePath, []byte("other content"), 0644)
+			},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetFileStats(gomock.Any(), "test.txt").Return(uploadedStats, nil)
+			},
+			expectedErrString: "already exists with different content",
+		},
+		{
+			name: "When a previous download of the same file was interrupted, should resume it with its chunk size",
+			setupFiles: func(filePath string) {
+				_ = os.WriteFile(filePath+".part", make([]byte, len(downloadContent)), 0644)
+				_ = os.WriteFile(filePath+".part.log", []byte(uploadedStats.Checksum+" 11 3\n0\n3\n2"), 0644)
+			},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetFileStats(gomock.Any(), "test.txt").Return(uploadedStats, nil)
+			},
+			expectedOutput: &usecase.DownloadPrecheckUsecaseOutput{
+				Checksum:                uploadedStats.Checksum,
+				ChecksumAlgorithm:       "sha256",
+				FileSize:                int64(len(downloadContent)),
+				ChunkSize:               3,
+				DownloadedSize:          3 + 2 + 3,
+				CompletedChunkNumberMap: map[uint64]struct{}{0: {}, 2: {}, 3: {}},
+			},
+		},
+		{
+			name: "When a previous download was for different content, should start over",
+			setupFiles: func(filePath string) {
+				_ = os.WriteFile(filePath+".part", make([]byte, len(downloadContent)), 0644)
+				_ = os.WriteFile(filePath+".part.log", []byte("outdated 11 3\n0\n"), 0644)
+			},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetFileStats(gomock.Any(), "test.txt").Return(uploadedStats, nil)
+			},
+			expectedOutput: &usecase.DownloadPrecheckUsecaseOutput{
+				Checksum:                uploadedStats.Checksum,
+				ChecksumAlgorithm:       "sha256",
+				FileSize:                int64(len(downloadContent)),
+				ChunkSize:               4,
+				CompletedChunkNumberMap: map[uint64]struct{}{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filePath := filepath.Join(t.TempDir(), "test.txt")
+			if tt.setupFiles != nil {
+				tt.setupFiles(filePath)
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockFileServerHttpClient(ctrl)
+			tt.mockSetup(mockClient)
+
+			downloadUsecase := usecase.NewDownloadUsecase(mockClient)
+			output, err := downloadUsecase.ExecutePrecheck(context.Background(), &usecase.DownloadPrecheckUsecaseInput{
+				TargetFileName: "test.txt",
+				FilePath:       filePath,
+				ChunkSize:      4,
+			})
+
+			if tt.expectedErrString != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrString)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOutput, output)
+		})
+	}
+}
+
+func TestDownloadUsecase_Execute(t *testing.T) {
+	tests := []struct {
+		name                    string
+		checksum                string
+		completedChunkNumberMap map[uint64]struct{}
+		mockSetup               func(mockClient *mock.MockFileServerHttpClient)
+		expectedErrString       string
+	}{
+		{
+			name:     "When all chunks are downloaded, should assemble the file",
+			checksum: downloadChecksum(downloadContent),
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().
+					DownloadChunk(gomock.Any(), "test.txt", gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, offset int64, length int64) ([]byte, error) {
+						return []byte(downloadContent[offset : offset+length]), nil
+					}).
+					Times(3)
+			},
+		},
+		{
+			name:                    "When resuming, should download only the chunks not completed yet",
+			checksum:                downloadChecksum(downloadContent),
+			completedChunkNumberMap: map[uint64]struct{}{0: {}, 2: {}},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().
+					DownloadChunk(gomock.Any(), "test.txt", int64(4), int64(4)).
+					Return([]byte(downloadContent[4:8]), nil)
+			},
+		},
+		{
+			name:     "When the downloaded content is broken, should return error",
+			checksum: downloadChecksum("something else"),
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().
+					DownloadChunk(gomock.Any(), "test.txt", gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, offset int64, length int64) ([]byte, error) {
+						return []byte(downloadContent[offset : offset+length]), nil
+					}).
+					Times(3)
+			},
+			expectedErrString: "checksum mismatch",
+		},
+		{
+			name:     "When download chunk fails with retry exhausted, should return error",
+			checksum: downloadChecksum(downloadContent),
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().
+					DownloadChunk(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("chunk download error")).
+					AnyTimes()
+			},
+			expectedErrString: "failed to download chunk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.WithValue(context.Background(), entity.ConcurrencyKey, 2)
+			ctx = context.WithValue(ctx, entity.RetriesKey, 0)
+
+			filePath := filepath.Join(t.TempDir(), "test.txt")
+			if tt.completedChunkNumberMap != nil {
+				// the chunks completed by the previous attempt are already in the partial file
+				partial := []byte(downloadContent)
+				copy(partial[4:8], make([]byte, 4))
+				_ = os.WriteFile(filePath+".part", partial, 0644)
+			}
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockFileServerHttpClient(ctrl)
+			tt.mockSetup(mockClient)
+
+			downloadUsecase := usecase.NewDownloadUsecase(mockClient)
+			err := downloadUsecase.Execute(ctx, &usecase.DownloadUsecaseInput{
+				TargetFileName:          "test.txt",
+				FilePath:                filePath,
+				Checksum:                tt.checksum,
+				ChecksumAlgorithm:       "sha256",
+				FileSize:                int64(len(downloadContent)),
+				ChunkSize:               4,
+				CompletedChunkNumberMap: tt.completedChunkNumberMap,
+			})
+
+			if tt.expectedErrString != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrString)
+				_, statErr := os.Stat(filePath)
+				assert.True(t, os.IsNotExist(statErr))
+				return
+			}
+			assert.NoError(t, err)
+			content, err := os.ReadFile(filePath)
+			assert.NoError(t, err)
+			assert.Equal(t, downloadContent, string(content))
+			_, statErr := os.Stat(filePath + ".part.log")
+			assert.True(t, os.IsNotExist(statErr))
+		})
+	}
+}
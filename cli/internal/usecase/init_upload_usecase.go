@@ -3,7 +3,6 @@ package usecase
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/tomoya.tokunaga/cli/internal/domain/entity"
 	"github.com/tomoya.tokunaga/cli/internal/infrastructure"
@@ -29,14 +28,9 @@ func (s *initUploadUsecase) ExecutePrecheck(ctx context.Context, input *InitUplo
 		return ReturnError, nil, err
 	}
 
-	checksum, err := util.CalculateChecksum(input.FilePath)
-	if err != nil {
-		return ReturnError, nil, fmt.Errorf("failed to calculate file checksum: %w", err)
-	}
-
-	output = &InitUploadPrecheckUsecaseOutput{
-		Checksum: checksum,
-		FileSize: fileSize,
+	algorithm := input.ChecksumAlgorithm
+	if algorithm == "" {
+		algorithm = entity.DefaultChecksumAlgorithm
 	}
 
 	// checks the existence of the file on the server using targetFileName
@@ -45,39 +39,51 @@ func (s *initUploadUsecase) ExecutePrecheck(ctx context.Context, input *InitUplo
 		return ReturnError, nil, fmt.Errorf("failed to get file stats for '%s': %w", input.TargetFileName, err)
 	}
 	if fileStats == nil {
+		output, err = calculatePrecheckOutput(input.FilePath, algorithm, fileSize)
+		if err != nil {
+			return ReturnError, nil, err
+		}
 		return ProceedWithInit, output, nil
 	}
 
-	isSameFile := fileStats.Checksum == checksum && fileStats.Size == uint64(fileSize)
+	// the content can only be compared with the checksum calculated by the same algorithm as the file on the server
+	serverAlgorithm := fileStats.ChecksumAlgorithm
+	if serverAlgorithm == "" {
+		serverAlgorithm = entity.DefaultChecksumAlgorithm
+	}
+	output, err = calculatePrecheckOutput(input.FilePath, serverAlgorithm, fileSize)
+	if err != nil {
+		return ReturnError, nil, err
+	}
+
+	isSameFile := fileStats.Checksum == output.Checksum && fileStats.Size == uint64(fileSize)
 	if !isSameFile {
 		// the file on the server has different content, so suggest to delete the existing entry and to retry the upload
+		// with the requested algorithm
+		if serverAlgorithm != algorithm {
+			output, err = calculatePrecheckOutput(input.FilePath, algorithm, fileSize)
+			if err != nil {
+				return ReturnError, nil, err
+			}
+		}
 		return SuggestExistingEntryDeletion, output, nil
 	}
+	// the stats are served only for an uploaded file, or one whose content the scrubber found broken afterwards
 	switch fileStats.Status {
 	case entity.FileStatusUploaded:
 		// the file on the server has same content, so do nothing and exit
 		return Exits, nil, nil
-	case entity.FileStatusInitialized:
-		// the file upload was initialized, but not completed, so proceed with the upload
-		return ProceedWithInit, output, nil
-	case entity.FileStatusFailed:
-		// the file upload failed previously (e.g. database/storage error, network disconnection, etc), so proceed with
-		// re-uploading missing chunks
-		return ProceedWithReUpload, output, nil
-	case entity.FileStatusInProgress:
-		// the file upload is being processed by other client or something went wrong on the server side (e.g. server crash,
-		// database down, etc) and the file data is orphaned. determine if the file is orphaned and proceed with re-uploading
-		// missing chunks if the last updated time is older than the server's upload timeout.
-		isOrphaned := fileStats.UpdatedAt.Before(time.Now().Add(-fileStats.UploadTimeoutSecond))
-		if isOrphaned {
-			return ProceedWithReUpload, output, nil
-		}
-		return Exits, nil, fmt.Errorf("remote file server processing '%s' with the same content currently or faces some problems (database crash, server error, etc). try again later, or delete the existing entry and retry the upload", input.TargetFileName)
+	case entity.FileStatusCorrupt:
+		// the content stored on the server doesn't match the checksum, and it can't tell which chunks are broken, so
+		// suggest to delete the existing entry and to retry the upload
+		return SuggestExistingEntryDeletion, output, nil
+	case entity.FileStatusDamaged:
+		// the scrubber found some chunks stored on the server broken after the upload completed, and the file server
+		// doesn't accept those chunks again, so suggest to delete the existing entry and to retry the upload
+		return SuggestExistingEntryDeletion, output, nil
 	}
 
-	// when the file is one of deleted status (DELETE_INITIALIZED, DELETE_IN_PROGRESS, DELETE_FAILED), suggest to
-	// delete the existing entry and to retry the upload
-	return SuggestExistingEntryDeletion, nil, nil
+	return ReturnError, nil, fmt.Errorf("file '%s' on the server is in unexpected status %s", input.TargetFileName, fileStats.Status)
 }
 
 // Execute initializes a file upload on the server
@@ -88,7 +94,7 @@ func (s *initUploadUsecase) Execute(ctx context.Context, input *InitUploadUsecas
 	}
 
 	// checks the file content is the same as the original checksum in precheck
-	checksum, err := util.CalculateChecksum(input.FilePath)
+	checksum, err := util.CalculateChecksum(input.FilePath, input.ChecksumAlgorithm)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate file checksum: %w", err)
 	}
@@ -96,14 +102,71 @@ func (s *initUploadUsecase) Execute(ctx context.Context, input *InitUploadUsecas
 		return nil, fmt.Errorf("file content has changed for local file '%s' since precheck", input.FilePath)
 	}
 
-	// Calculate numChunks as int64
-	numChunks := (fileSize + input.ChunkSize - 1) / input.ChunkSize
+	limits, err := s.fileServerHttpClient.GetUploadLimits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload limits: %w", err)
+	}
+
+	// the chunks of the file on the server are compared with the local ones by their checksums
+	var manifest *entity.FileManifestResp
+	requestedChunkSize := input.ChunkSize
+	if input.Delta {
+		manifest, err = s.fileServerHttpClient.GetFileManifest(ctx, input.TargetFileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get manifest of '%s': %w", input.TargetFileName, err)
+		}
+		// the fixed chunks line up with the ones on the server only when they're of the same size
+		if manifest != nil && input.CDC == nil && manifest.ChunkSize > 0 {
+			requestedChunkSize = int64(manifest.ChunkSize)
+		}
+	}
+
+	var chunkSize, numChunks int64
+	var chunkSizes []int64
+	if input.CDC != nil {
+		// the chunks are cut by the content, so the same parameters split the same content the same way on a re-upload
+		chunkSizes, err = util.CalculateChunkSizes(input.FilePath, *input.CDC)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split file into chunks: %w", err)
+		}
+		if err := checkChunkSizes(fileSize, input.CDC.MaxSize, chunkSizes, limits); err != nil {
+			return nil, err
+		}
+		chunkSize = input.CDC.MaxSize
+		numChunks = int64(len(chunkSizes))
+	} else {
+		chunkSize, err = chooseChunkSize(fileSize, requestedChunkSize, limits)
+		if err != nil {
+			return nil, err
+		}
+		numChunks = (fileSize + chunkSize - 1) / chunkSize
+	}
+
+	var layout []int64
+	var chunkChecksums []string
+	var baseFileID uint64
+	if manifest != nil {
+		layout = chunkSizes
+		if layout == nil {
+			layout = fixedChunkSizes(fileSize, chunkSize)
+		}
+		chunkChecksums, err = util.CalculateChunkChecksums(input.FilePath, layout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate chunk checksums: %w", err)
+		}
+		baseFileID = manifest.FileID
+	}
+
 	reqBody := infrastructure.UploadInitRequest{
-		TotalSize:   fileSize,
-		TotalChunks: numChunks,
-		ChunkSize:   input.ChunkSize,
-		Checksum:    checksum,
-		IsReUpload:  input.IsReUpload,
+		TotalSize:         fileSize,
+		TotalChunks:       numChunks,
+		ChunkSize:         chunkSize,
+		ChunkSizes:        chunkSizes,
+		ChunkChecksums:    chunkChecksums,
+		BaseFileID:        baseFileID,
+		Checksum:          checksum,
+		ChecksumAlgorithm: input.ChecksumAlgorithm,
+		IsReUpload:        input.IsReUpload,
 	}
 	res, err := s.fileServerHttpClient.InitUpload(ctx, input.TargetFileName, reqBody)
 	if err != nil {
@@ -111,18 +174,147 @@ func (s *initUploadUsecase) Execute(ctx context.Context, input *InitUploadUsecas
 	}
 
 	// populate values required to re-upload failed chunks from previously upload attempts
-	uploadChunkSize := input.ChunkSize
+	uploadChunkSize := chunkSize
 	missingChunkNumberMap := make(map[uint64]struct{})
+	missingChunkOffsetMap := make(map[uint64]int64)
+	var reusedChunks int
+	var reusedSize int64
 	if res.MissingChunkInfo != nil {
 		uploadChunkSize = int64(res.MissingChunkInfo.MaxChunkSize)
-		for _, chunkNumber := range res.MissingChunkInfo.ChunkNumbers {
+		for i, chunkNumber := range res.MissingChunkInfo.ChunkNumbers {
 			missingChunkNumberMap[chunkNumber] = struct{}{}
+			// the server which doesn't keep the content received before the upload was cut off reports no offsets
+			if i < len(res.MissingChunkInfo.Offsets) && res.MissingChunkInfo.Offsets[i] > 0 {
+				missingChunkOffsetMap[chunkNumber] = int64(res.MissingChunkInfo.Offsets[i])
+			}
+		}
+		// the chunks the server doesn't ask for are the ones reusing the chunks of the same content on the server
+		if manifest != nil {
+			reusedSize = fileSize
+			for chunkNumber := range missingChunkNumberMap {
+				if chunkNumber < uint64(len(layout)) {
+					reusedSize -= layout[chunkNumber]
+				}
+			}
+			reusedChunks = len(layout) - len(missingChunkNumberMap)
 		}
 	}
 
 	return &UploadUsecaseOutput{
-		UploadID:              res.UploadID,
+		SessionToken:          res.SessionToken,
+		ExpiresAt:             res.ExpiresAt,
+		IsResumed:             res.MissingChunkInfo != nil,
+		ChunkSize:             uint64(chunkSize),
+		ChunkSizes:            chunkSizes,
 		UploadChunkSize:       uint64(uploadChunkSize),
 		MissingChunkNumberMap: missingChunkNumberMap,
+		MissingChunkOffsetMap: missingChunkOffsetMap,
+		ReusedChunks:          reusedChunks,
+		ReusedSize:            reusedSize,
+	}, nil
+}
+
+// ExecuteOpenEnded initializes an open-ended upload on the server, whose size and checksum are given on the commit
+// once all of its content is read. It's never resumed, as the content can't be read again
+func (s *initUploadUsecase) ExecuteOpenEnded(ctx context.Context, input *OpenEndedInitUploadUsecaseInput) (*UploadUsecaseOutput, error) {
+	limits, err := s.fileServerHttpClient.GetUploadLimits(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload limits: %w", err)
+	}
+	// the size isn't known yet, so the chunk size is only lowered to the maximum chunk size
+	chunkSize, err := chooseChunkSize(0, input.ChunkSize, limits)
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody := infrastructure.UploadInitRequest{
+		ChunkSize:         chunkSize,
+		ChecksumAlgorithm: input.ChecksumAlgorithm,
+		OpenEnded:         true,
+	}
+	res, err := s.fileServerHttpClient.InitUpload(ctx, input.TargetFileName, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize upload for '%s': %w", input.TargetFileName, err)
+	}
+
+	return &UploadUsecaseOutput{
+		SessionToken:    res.SessionToken,
+		ExpiresAt:       res.ExpiresAt,
+		ChunkSize:       uint64(chunkSize),
+		UploadChunkSize: uint64(chunkSize),
+	}, nil
+}
+
+// chooseChunkSize returns the chunk size closest to the requested one which splits the file within the server's
+// limits. The chunk size is raised when the file would be split into too many chunks, and lowered when it exceeds the
+// maximum chunk size. The result only depends on the inputs, so a re-upload splits the file the same way as the
+// original upload.
+func chooseChunkSize(fileSize int64, chunkSize int64, limits *entity.UploadLimitsResp) (int64, error) {
+	if chunkSize <= 0 {
+		return 0, fmt.Errorf("chunk size must be greater than 0")
+	}
+	if limits == nil {
+		return chunkSize, nil
+	}
+
+	if limits.MaxFileSize > 0 && uint64(fileSize) > limits.MaxFileSize {
+		return 0, fmt.Errorf("file size %d exceeds the server's maximum %d", fileSize, limits.MaxFileSize)
+	}
+	if limits.MaxChunkCount > 0 {
+		minChunkSize := int64((uint64(fileSize) + limits.MaxChunkCount - 1) / limits.MaxChunkCount)
+		chunkSize = max(chunkSize, minChunkSize)
+	}
+	if limits.MaxChunkSize > 0 {
+		chunkSize = min(chunkSize, int64(limits.MaxChunkSize))
+	}
+
+	numChunks := (fileSize + chunkSize - 1) / chunkSize
+	if limits.MaxChunkCount > 0 && uint64(numChunks) > limits.MaxChunkCount {
+		return 0, fmt.Errorf("file size %d can't be split into %d chunks of %d bytes at most", fileSize, limits.MaxChunkCount, chunkSize)
+	}
+
+	return chunkSize, nil
+}
+
+// fixedChunkSizes returns the sizes of the chunks of the chunk size the file is split into, where the last one has the
+// rest of the file
+func fixedChunkSizes(fileSize int64, chunkSize int64) []int64 {
+	sizes := make([]int64, 0, (fileSize+chunkSize-1)/chunkSize)
+	for offset := int64(0); offset < fileSize; offset += chunkSize {
+		sizes = append(sizes, min(chunkSize, fileSize-offset))
+	}
+	return sizes
+}
+
+// checkChunkSizes checks that the content-defined chunks of the file are within the server's limits. Unlike the fixed
+// chunk size, the chunks can't be adjusted to the limits, so the chunking parameters need to be changed instead
+func checkChunkSizes(fileSize int64, maxChunkSize int64, chunkSizes []int64, limits *entity.UploadLimitsResp) error {
+	if limits == nil {
+		return nil
+	}
+
+	if limits.MaxFileSize > 0 && uint64(fileSize) > limits.MaxFileSize {
+		return fmt.Errorf("file size %d exceeds the server's maximum %d", fileSize, limits.MaxFileSize)
+	}
+	if limits.MaxChunkSize > 0 && uint64(maxChunkSize) > limits.MaxChunkSize {
+		return fmt.Errorf("maximum chunk size %d exceeds the server's maximum %d", maxChunkSize, limits.MaxChunkSize)
+	}
+	if limits.MaxChunkCount > 0 && uint64(len(chunkSizes)) > limits.MaxChunkCount {
+		return fmt.Errorf("file is split into %d chunks, which exceeds the server's maximum %d. try larger chunk sizes", len(chunkSizes), limits.MaxChunkCount)
+	}
+
+	return nil
+}
+
+// calculatePrecheckOutput calculates the checksum of the local file with the given algorithm
+func calculatePrecheckOutput(filePath string, algorithm string, fileSize int64) (*InitUploadPrecheckUsecaseOutput, error) {
+	checksum, err := util.CalculateChecksum(filePath, algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate file checksum: %w", err)
+	}
+	return &InitUploadPrecheckUsecaseOutput{
+		Checksum:          checksum,
+		ChecksumAlgorithm: algorithm,
+		FileSize:          fileSize,
 	}, nil
 }
@@ -2,6 +2,8 @@ package usecase_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"os"
 	"path/filepath"
@@ -28,17 +30,22 @@ func createTempFile(t *testing.T, content string) string {
 	return filePath
 }
 
+// Helper function to calculate the SHA-256 checksum of a chunk
+func chunkChecksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
 func TestExecutePrecheck(t *testing.T) {
 	ctx := context.Background()
 	testContent := "hello world"
 	testFilePath := createTempFile(t, testContent)
-	checksum, err := util.CalculateChecksum(testFilePath)
+	checksum, err := util.CalculateChecksum(testFilePath, "sha256")
+	require.NoError(t, err)
+	xxhashChecksum, err := util.CalculateChecksum(testFilePath, "xxhash64")
 	require.NoError(t, err)
 	fileSize := int64(len(testContent))
 
-	now := time.Now()
-	serverUploadTimeoutDuration := 1 * time.Hour
-
 	tests := []struct {
 		name                 string
 		mockSetup            func(mockClient *mock.MockFileServerHttpClient)
@@ -59,8 +66,9 @@ func TestExecutePrecheck(t *testing.T) {
 			},
 			wantAction: usecase.ProceedWithInit,
 			wantOutput: &usecase.InitUploadPrecheckUsecaseOutput{
-				Checksum: checksum,
-				FileSize: fileSize,
+				Checksum:          checksum,
+				ChecksumAlgorithm: "sha256",
+				FileSize:          fileSize,
 			},
 			wantErr: false,
 		},
@@ -82,127 +90,168 @@ func TestExecutePrecheck(t *testing.T) {
 			wantErr:    false,
 		},
 		{
-			name: "Success: Same file exists, status Initialized",
+			name: "Suggest Deletion: Same file exists, status Corrupt",
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
 				mockClient.EXPECT().GetFileStats(ctx, "target.txt").Return(&entity.FileStatsResp{
-					Status:   entity.FileStatusInitialized,
 					Checksum: checksum,
 					Size:     uint64(fileSize),
+					Status:   entity.FileStatusCorrupt,
 				}, nil)
 			},
 			input: &usecase.InitUploadPrecheckUsecaseInput{
 				FilePath:       testFilePath,
 				TargetFileName: "target.txt",
 			},
-			wantAction: usecase.ProceedWithInit,
+			wantAction: usecase.SuggestExistingEntryDeletion,
 			wantOutput: &usecase.InitUploadPrecheckUsecaseOutput{
-				Checksum: checksum,
-				FileSize: fileSize,
+				Checksum:          checksum,
+				ChecksumAlgorithm: "sha256",
+				FileSize:          fileSize,
 			},
 			wantErr: false,
 		},
 		{
-			name: "Success: Same file exists, status Failed",
+			name: "Suggest Deletion: Same file exists, status Damaged",
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
 				mockClient.EXPECT().GetFileStats(ctx, "target.txt").Return(&entity.FileStatsResp{
-					Status:   entity.FileStatusFailed,
 					Checksum: checksum,
 					Size:     uint64(fileSize),
+					Status:   entity.FileStatusDamaged,
 				}, nil)
 			},
 			input: &usecase.InitUploadPrecheckUsecaseInput{
 				FilePath:       testFilePath,
 				TargetFileName: "target.txt",
 			},
-			wantAction: usecase.ProceedWithReUpload,
+			wantAction: usecase.SuggestExistingEntryDeletion,
 			wantOutput: &usecase.InitUploadPrecheckUsecaseOutput{
-				Checksum: checksum,
-				FileSize: fileSize,
+				Checksum:          checksum,
+				ChecksumAlgorithm: "sha256",
+				FileSize:          fileSize,
 			},
 			wantErr: false,
 		},
 		{
-			name: "Success: Same file exists, status InProgress, orphaned",
+			name: "Suggest Deletion: Different file exists (checksum mismatch)",
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
 				mockClient.EXPECT().GetFileStats(ctx, "target.txt").Return(&entity.FileStatsResp{
-					Status:              entity.FileStatusInProgress,
-					Checksum:            checksum,
-					Size:                uint64(fileSize),
-					UpdatedAt:           now.Add(-serverUploadTimeoutDuration).Add(-time.Second),
-					UploadTimeoutSecond: serverUploadTimeoutDuration,
+					Status:   entity.FileStatusUploaded,
+					Checksum: "different-checksum",
+					Size:     uint64(fileSize),
 				}, nil)
 			},
 			input: &usecase.InitUploadPrecheckUsecaseInput{
 				FilePath:       testFilePath,
 				TargetFileName: "target.txt",
 			},
-			wantAction: usecase.ProceedWithReUpload,
+			wantAction: usecase.SuggestExistingEntryDeletion,
 			wantOutput: &usecase.InitUploadPrecheckUsecaseOutput{
-				Checksum: checksum,
-				FileSize: fileSize,
+				Checksum:          checksum,
+				ChecksumAlgorithm: "sha256",
+				FileSize:          fileSize,
 			},
 			wantErr: false,
 		},
 		{
-			name: "Error: Same file exists, status InProgress, not orphaned",
+			name: "Suggest Deletion: Different file exists (size mismatch)",
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
 				mockClient.EXPECT().GetFileStats(ctx, "target.txt").Return(&entity.FileStatsResp{
-					Checksum:            checksum,
-					Size:                uint64(fileSize),
-					Status:              entity.FileStatusInProgress,
-					UpdatedAt:           time.Now(),
-					UploadTimeoutSecond: 3600 * time.Second, // Expecting error
+					Status:   entity.FileStatusUploaded,
+					Checksum: checksum,
+					Size:     uint64(fileSize + 1),
 				}, nil)
 			},
 			input: &usecase.InitUploadPrecheckUsecaseInput{
 				FilePath:       testFilePath,
 				TargetFileName: "target.txt",
 			},
-			wantAction:           usecase.Exits,
-			wantOutput:           nil,
-			wantErr:              true,
-			expectedErrSubstring: "remote file server processing 'target.txt'",
+			wantAction: usecase.SuggestExistingEntryDeletion,
+			wantOutput: &usecase.InitUploadPrecheckUsecaseOutput{
+				Checksum:          checksum,
+				ChecksumAlgorithm: "sha256",
+				FileSize:          fileSize,
+			},
+			wantErr: false,
 		},
 		{
-			name: "Suggest Deletion: Different file exists (checksum mismatch)",
+			name: "Success: File does not exist on server, other algorithm requested",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetFileStats(ctx, "target.txt").Return(nil, nil)
+			},
+			input: &usecase.InitUploadPrecheckUsecaseInput{
+				FilePath:          testFilePath,
+				TargetFileName:    "target.txt",
+				ChecksumAlgorithm: "xxhash64",
+			},
+			wantAction: usecase.ProceedWithInit,
+			wantOutput: &usecase.InitUploadPrecheckUsecaseOutput{
+				Checksum:          xxhashChecksum,
+				ChecksumAlgorithm: "xxhash64",
+				FileSize:          fileSize,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Suggest Deletion: Same file exists with other algorithm, status Damaged",
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
 				mockClient.EXPECT().GetFileStats(ctx, "target.txt").Return(&entity.FileStatsResp{
-					Status:   entity.FileStatusUploaded,
-					Checksum: "different-checksum",
-					Size:     uint64(fileSize),
+					Status:            entity.FileStatusDamaged,
+					Checksum:          xxhashChecksum,
+					ChecksumAlgorithm: "xxhash64",
+					Size:              uint64(fileSize),
 				}, nil)
 			},
 			input: &usecase.InitUploadPrecheckUsecaseInput{
-				FilePath:       testFilePath,
-				TargetFileName: "target.txt",
+				FilePath:          testFilePath,
+				TargetFileName:    "target.txt",
+				ChecksumAlgorithm: "sha256",
 			},
 			wantAction: usecase.SuggestExistingEntryDeletion,
 			wantOutput: &usecase.InitUploadPrecheckUsecaseOutput{
-				Checksum: checksum,
-				FileSize: fileSize,
+				Checksum:          xxhashChecksum,
+				ChecksumAlgorithm: "xxhash64",
+				FileSize:          fileSize,
 			},
 			wantErr: false,
 		},
 		{
-			name: "Suggest Deletion: Different file exists (size mismatch)",
+			name: "Suggest Deletion: Different file exists with other algorithm",
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
 				mockClient.EXPECT().GetFileStats(ctx, "target.txt").Return(&entity.FileStatsResp{
-					Status:   entity.FileStatusUploaded,
-					Checksum: checksum,
-					Size:     uint64(fileSize + 1),
+					Status:            entity.FileStatusUploaded,
+					Checksum:          "different-checksum",
+					ChecksumAlgorithm: "crc32c",
+					Size:              uint64(fileSize),
 				}, nil)
 			},
 			input: &usecase.InitUploadPrecheckUsecaseInput{
-				FilePath:       testFilePath,
-				TargetFileName: "target.txt",
+				FilePath:          testFilePath,
+				TargetFileName:    "target.txt",
+				ChecksumAlgorithm: "xxhash64",
 			},
 			wantAction: usecase.SuggestExistingEntryDeletion,
 			wantOutput: &usecase.InitUploadPrecheckUsecaseOutput{
-				Checksum: checksum,
-				FileSize: fileSize,
+				Checksum:          xxhashChecksum,
+				ChecksumAlgorithm: "xxhash64",
+				FileSize:          fileSize,
 			},
 			wantErr: false,
 		},
+		{
+			name: "Error: Unsupported checksum algorithm",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetFileStats(ctx, "target.txt").Return(nil, nil)
+			},
+			input: &usecase.InitUploadPrecheckUsecaseInput{
+				FilePath:          testFilePath,
+				TargetFileName:    "target.txt",
+				ChecksumAlgorithm: "md5",
+			},
+			wantAction:           usecase.ReturnError,
+			wantOutput:           nil,
+			wantErr:              true,
+			expectedErrSubstring: "unsupported checksum algorithm \"md5\"",
+		},
 		{
 			name: "Error: Local file does not exist",
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
@@ -269,6 +318,7 @@ func TestExecutePrecheck(t *testing.T) {
 func TestExecute(t *testing.T) {
 	ctx := context.Background()
 	baseChunkSize := int64(5)
+	expiresAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name                  string
@@ -284,30 +334,37 @@ func TestExecute(t *testing.T) {
 			name:        "Success: Initialize new upload",
 			fileContent: "hello world again",
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(nil, nil)
 				reqBody := infrastructure.UploadInitRequest{
-					TotalSize:   fileSize,
-					TotalChunks: numChunks,
-					ChunkSize:   chunkSize,
-					Checksum:    checksum,
-					IsReUpload:  false,
+					TotalSize:         fileSize,
+					TotalChunks:       numChunks,
+					ChunkSize:         chunkSize,
+					Checksum:          checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
 				}
 				mockClient.EXPECT().InitUpload(ctx, "target.txt", reqBody).Return(&infrastructure.UploadInitResponse{
-					UploadID: 123,
+					SessionToken: "token-123",
+					ExpiresAt:    expiresAt,
 				}, nil)
 			},
 			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
 				return &usecase.InitUploadUsecaseInput{
-					FilePath:         filePath,
-					TargetFileName:   "target.txt",
-					ChunkSize:        chunkSize,
-					OriginalChecksum: checksum,
-					IsReUpload:       false,
+					FilePath:          filePath,
+					TargetFileName:    "target.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
 				}
 			},
 			wantOutput: &usecase.UploadUsecaseOutput{
-				UploadID:              123,
+				SessionToken:          "token-123",
+				ExpiresAt:             expiresAt,
+				ChunkSize:             uint64(baseChunkSize),
 				UploadChunkSize:       uint64(baseChunkSize),
 				MissingChunkNumberMap: map[uint64]struct{}{},
+				MissingChunkOffsetMap: map[uint64]int64{},
 			},
 			wantErr: false,
 		},
@@ -315,37 +372,153 @@ func TestExecute(t *testing.T) {
 			name:        "Success: Initialize re-upload with missing chunks",
 			fileContent: "another file content for re-upload",
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(nil, nil)
 				reqBody := infrastructure.UploadInitRequest{
-					TotalSize:   fileSize,
-					TotalChunks: numChunks,
-					ChunkSize:   chunkSize,
-					Checksum:    checksum,
-					IsReUpload:  true,
+					TotalSize:         fileSize,
+					TotalChunks:       numChunks,
+					ChunkSize:         chunkSize,
+					Checksum:          checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        true,
 				}
 				mockClient.EXPECT().InitUpload(ctx, "target-reup.txt", reqBody).Return(&infrastructure.UploadInitResponse{
-					UploadID: 456,
+					SessionToken: "token-456",
 					MissingChunkInfo: &entity.MissingChunkInfo{
 						MaxChunkSize: 10,
 						ChunkNumbers: []uint64{1, 3},
+						Offsets:      []uint64{4, 0},
 					},
 				}, nil)
 			},
 			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
 				return &usecase.InitUploadUsecaseInput{
-					FilePath:         filePath,
-					TargetFileName:   "target-reup.txt",
-					ChunkSize:        chunkSize,
-					OriginalChecksum: checksum,
-					IsReUpload:       true,
+					FilePath:          filePath,
+					TargetFileName:    "target-reup.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        true,
 				}
 			},
 			wantOutput: &usecase.UploadUsecaseOutput{
-				UploadID:              456,
+				SessionToken:          "token-456",
+				IsResumed:             true,
+				ChunkSize:             uint64(baseChunkSize),
 				UploadChunkSize:       10,
 				MissingChunkNumberMap: map[uint64]struct{}{1: {}, 3: {}},
+				MissingChunkOffsetMap: map[uint64]int64{1: 4},
+			},
+			wantErr: false,
+		},
+		{
+			name:        "Success: Delta re-upload reuses chunks of the conflicting file",
+			fileContent: "hello world again",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(nil, nil)
+				// the chunk size of the conflicting file is taken, so that the unchanged chunks line up
+				mockClient.EXPECT().GetFileManifest(ctx, "target-delta.txt").Return(&entity.FileManifestResp{FileID: 9, ChunkSize: 6}, nil)
+				reqBody := infrastructure.UploadInitRequest{
+					TotalSize:         fileSize,
+					TotalChunks:       3,
+					ChunkSize:         6,
+					Checksum:          checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        true,
+					ChunkChecksums:    []string{chunkChecksum("hello "), chunkChecksum("world "), chunkChecksum("again")},
+					BaseFileID:        9,
+				}
+				mockClient.EXPECT().InitUpload(ctx, "target-delta.txt", reqBody).Return(&infrastructure.UploadInitResponse{
+					SessionToken: "token-789",
+					MissingChunkInfo: &entity.MissingChunkInfo{
+						MaxChunkSize: 6,
+						ChunkNumbers: []uint64{1},
+						Offsets:      []uint64{0},
+					},
+				}, nil)
+			},
+			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
+				return &usecase.InitUploadUsecaseInput{
+					FilePath:          filePath,
+					TargetFileName:    "target-delta.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        true,
+					Delta:             true,
+				}
+			},
+			wantOutput: &usecase.UploadUsecaseOutput{
+				SessionToken:          "token-789",
+				IsResumed:             true,
+				ChunkSize:             6,
+				UploadChunkSize:       6,
+				MissingChunkNumberMap: map[uint64]struct{}{1: {}},
+				MissingChunkOffsetMap: map[uint64]int64{},
+				ReusedChunks:          2,
+				ReusedSize:            11,
 			},
 			wantErr: false,
 		},
+		{
+			name:        "Success: Delta re-upload without manifest sends every chunk",
+			fileContent: "hello world again",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(nil, nil)
+				mockClient.EXPECT().GetFileManifest(ctx, "target-delta.txt").Return(nil, nil)
+				reqBody := infrastructure.UploadInitRequest{
+					TotalSize:         fileSize,
+					TotalChunks:       numChunks,
+					ChunkSize:         chunkSize,
+					Checksum:          checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        true,
+				}
+				mockClient.EXPECT().InitUpload(ctx, "target-delta.txt", reqBody).Return(&infrastructure.UploadInitResponse{
+					SessionToken: "token-789",
+				}, nil)
+			},
+			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
+				return &usecase.InitUploadUsecaseInput{
+					FilePath:          filePath,
+					TargetFileName:    "target-delta.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        true,
+					Delta:             true,
+				}
+			},
+			wantOutput: &usecase.UploadUsecaseOutput{
+				SessionToken:          "token-789",
+				ChunkSize:             uint64(baseChunkSize),
+				UploadChunkSize:       uint64(baseChunkSize),
+				MissingChunkNumberMap: map[uint64]struct{}{},
+				MissingChunkOffsetMap: map[uint64]int64{},
+			},
+			wantErr: false,
+		},
+		{
+			name:        "Error: Delta re-upload fails to get manifest",
+			fileContent: "hello world again",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(nil, nil)
+				mockClient.EXPECT().GetFileManifest(ctx, "target-delta.txt").Return(nil, errors.New("connection refused"))
+			},
+			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
+				return &usecase.InitUploadUsecaseInput{
+					FilePath:          filePath,
+					TargetFileName:    "target-delta.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        true,
+					Delta:             true,
+				}
+			},
+			wantOutput:           nil,
+			wantErr:              true,
+			expectedErrSubstring: "failed to get manifest of 'target-delta.txt'",
+		},
 		{
 			name:        "Error: Local file does not exist",
 			fileContent: "dummy content",
@@ -354,11 +527,12 @@ func TestExecute(t *testing.T) {
 			},
 			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
 				return &usecase.InitUploadUsecaseInput{
-					FilePath:         "nonexistent/file.txt",
-					TargetFileName:   "target.txt",
-					ChunkSize:        chunkSize,
-					OriginalChecksum: checksum,
-					IsReUpload:       false,
+					FilePath:          "nonexistent/file.txt",
+					TargetFileName:    "target.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
 				}
 			},
 			wantOutput:           nil,
@@ -374,37 +548,233 @@ func TestExecute(t *testing.T) {
 			},
 			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
 				return &usecase.InitUploadUsecaseInput{
-					FilePath:         filePath,
-					TargetFileName:   "target.txt",
-					ChunkSize:        chunkSize,
-					OriginalChecksum: "different-checksum-from-precheck",
-					IsReUpload:       false,
+					FilePath:          filePath,
+					TargetFileName:    "target.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  "different-checksum-from-precheck",
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
 				}
 			},
 			wantOutput:           nil,
 			wantErr:              true,
 			expectedErrSubstring: "file content has changed",
 		},
+		{
+			name:        "Success: Raise chunk size to fit in max chunk count",
+			fileContent: "hello world again",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(&entity.UploadLimitsResp{MaxFileSize: 1024, MaxChunkSize: 16, MaxChunkCount: 2}, nil)
+				reqBody := infrastructure.UploadInitRequest{
+					TotalSize:         fileSize,
+					TotalChunks:       2,
+					ChunkSize:         9,
+					Checksum:          checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
+				}
+				mockClient.EXPECT().InitUpload(ctx, "target.txt", reqBody).Return(&infrastructure.UploadInitResponse{
+					SessionToken: "token-123",
+				}, nil)
+			},
+			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
+				return &usecase.InitUploadUsecaseInput{
+					FilePath:          filePath,
+					TargetFileName:    "target.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
+				}
+			},
+			wantOutput: &usecase.UploadUsecaseOutput{
+				SessionToken:          "token-123",
+				ChunkSize:             9,
+				UploadChunkSize:       9,
+				MissingChunkNumberMap: map[uint64]struct{}{},
+				MissingChunkOffsetMap: map[uint64]int64{},
+			},
+			wantErr: false,
+		},
+		{
+			name:        "Success: Lower chunk size to max chunk size",
+			fileContent: "hello world again",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(&entity.UploadLimitsResp{MaxChunkSize: 3}, nil)
+				reqBody := infrastructure.UploadInitRequest{
+					TotalSize:         fileSize,
+					TotalChunks:       6,
+					ChunkSize:         3,
+					Checksum:          checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
+				}
+				mockClient.EXPECT().InitUpload(ctx, "target.txt", reqBody).Return(&infrastructure.UploadInitResponse{
+					SessionToken: "token-123",
+				}, nil)
+			},
+			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
+				return &usecase.InitUploadUsecaseInput{
+					FilePath:          filePath,
+					TargetFileName:    "target.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
+				}
+			},
+			wantOutput: &usecase.UploadUsecaseOutput{
+				SessionToken:          "token-123",
+				ChunkSize:             3,
+				UploadChunkSize:       3,
+				MissingChunkNumberMap: map[uint64]struct{}{},
+				MissingChunkOffsetMap: map[uint64]int64{},
+			},
+			wantErr: false,
+		},
+		{
+			name:        "Success: Split file into content-defined chunks",
+			fileContent: "hello world again",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(&entity.UploadLimitsResp{MaxChunkSize: 4, MaxChunkCount: 5}, nil)
+				reqBody := infrastructure.UploadInitRequest{
+					TotalSize:         fileSize,
+					TotalChunks:       5,
+					ChunkSize:         4,
+					ChunkSizes:        []int64{4, 4, 4, 4, 1},
+					Checksum:          checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
+				}
+				mockClient.EXPECT().InitUpload(ctx, "target.txt", reqBody).Return(&infrastructure.UploadInitResponse{
+					SessionToken: "token-123",
+				}, nil)
+			},
+			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
+				return &usecase.InitUploadUsecaseInput{
+					FilePath:          filePath,
+					TargetFileName:    "target.txt",
+					ChunkSize:         chunkSize,
+					CDC:               &util.CDCParams{MinSize: 4, AvgSize: 4, MaxSize: 4},
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
+				}
+			},
+			wantOutput: &usecase.UploadUsecaseOutput{
+				SessionToken:          "token-123",
+				ChunkSize:             4,
+				ChunkSizes:            []int64{4, 4, 4, 4, 1},
+				UploadChunkSize:       4,
+				MissingChunkNumberMap: map[uint64]struct{}{},
+				MissingChunkOffsetMap: map[uint64]int64{},
+			},
+			wantErr: false,
+		},
+		{
+			name:        "Error: Content-defined chunks exceed max chunk count",
+			fileContent: "hello world again",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(&entity.UploadLimitsResp{MaxChunkSize: 4, MaxChunkCount: 4}, nil)
+			},
+			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
+				return &usecase.InitUploadUsecaseInput{
+					FilePath:          filePath,
+					TargetFileName:    "target.txt",
+					ChunkSize:         chunkSize,
+					CDC:               &util.CDCParams{MinSize: 4, AvgSize: 4, MaxSize: 4},
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
+				}
+			},
+			wantOutput:           nil,
+			wantErr:              true,
+			expectedErrSubstring: "file is split into 5 chunks, which exceeds the server's maximum 4",
+		},
+		{
+			name:        "Error: File exceeds max file size",
+			fileContent: "content for file size limit test",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(&entity.UploadLimitsResp{MaxFileSize: 10}, nil)
+			},
+			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
+				return &usecase.InitUploadUsecaseInput{
+					FilePath:          filePath,
+					TargetFileName:    "target.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
+				}
+			},
+			wantOutput:           nil,
+			wantErr:              true,
+			expectedErrSubstring: "exceeds the server's maximum 10",
+		},
+		{
+			name:        "Error: File can't fit in max chunk count",
+			fileContent: "content for chunk count limit test",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(&entity.UploadLimitsResp{MaxChunkSize: 4, MaxChunkCount: 2}, nil)
+			},
+			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
+				return &usecase.InitUploadUsecaseInput{
+					FilePath:          filePath,
+					TargetFileName:    "target.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
+				}
+			},
+			wantOutput:           nil,
+			wantErr:              true,
+			expectedErrSubstring: "can't be split into 2 chunks",
+		},
+		{
+			name:        "Error: GetUploadLimits fails on server",
+			fileContent: "content for limits error test",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(nil, errors.New("server limits error"))
+			},
+			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
+				return &usecase.InitUploadUsecaseInput{
+					FilePath:          filePath,
+					TargetFileName:    "target.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
+				}
+			},
+			wantOutput:           nil,
+			wantErr:              true,
+			expectedErrSubstring: "failed to get upload limits: server limits error",
+		},
 		{
 			name:        "Error: InitUpload fails on server",
 			fileContent: "content for server error test",
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient, fileSize int64, numChunks int64, checksum string, chunkSize int64) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(nil, nil)
 				reqBody := infrastructure.UploadInitRequest{
-					TotalSize:   fileSize,
-					TotalChunks: numChunks,
-					ChunkSize:   chunkSize,
-					Checksum:    checksum,
-					IsReUpload:  false,
+					TotalSize:         fileSize,
+					TotalChunks:       numChunks,
+					ChunkSize:         chunkSize,
+					Checksum:          checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
 				}
 				mockClient.EXPECT().InitUpload(ctx, "target-server-err.txt", reqBody).Return(nil, errors.New("server init error"))
 			},
 			inputArgs: func(filePath string, checksum string, chunkSize int64) *usecase.InitUploadUsecaseInput {
 				return &usecase.InitUploadUsecaseInput{
-					FilePath:         filePath,
-					TargetFileName:   "target-server-err.txt",
-					ChunkSize:        chunkSize,
-					OriginalChecksum: checksum,
-					IsReUpload:       false,
+					FilePath:          filePath,
+					TargetFileName:    "target-server-err.txt",
+					ChunkSize:         chunkSize,
+					OriginalChecksum:  checksum,
+					ChecksumAlgorithm: "sha256",
+					IsReUpload:        false,
 				}
 			},
 			wantOutput:           nil,
@@ -421,7 +791,7 @@ func TestExecute(t *testing.T) {
 			defer ctrl.Finish()
 
 			testFilePath := createTempFile(t, tt.fileContent)
-			checksum, err := util.CalculateChecksum(testFilePath)
+			checksum, err := util.CalculateChecksum(testFilePath, "sha256")
 			require.NoError(t, err)
 			fileSize := int64(len(tt.fileContent))
 			chunkSize := baseChunkSize
@@ -459,3 +829,88 @@ func TestExecute(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteOpenEnded(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name                 string
+		input                *usecase.OpenEndedInitUploadUsecaseInput
+		mockSetup            func(mockClient *mock.MockFileServerHttpClient)
+		wantOutput           *usecase.UploadUsecaseOutput
+		wantErr              bool
+		expectedErrSubstring string
+	}{
+		{
+			name:  "Success: Initialize open-ended upload",
+			input: &usecase.OpenEndedInitUploadUsecaseInput{TargetFileName: "stream.tar", ChecksumAlgorithm: "sha256", ChunkSize: 1024},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(nil, nil)
+				mockClient.EXPECT().InitUpload(ctx, "stream.tar", infrastructure.UploadInitRequest{
+					ChunkSize:         1024,
+					ChecksumAlgorithm: "sha256",
+					OpenEnded:         true,
+				}).Return(&infrastructure.UploadInitResponse{SessionToken: "token-123"}, nil)
+			},
+			wantOutput: &usecase.UploadUsecaseOutput{SessionToken: "token-123", ChunkSize: 1024, UploadChunkSize: 1024},
+			wantErr:    false,
+		},
+		{
+			name:  "Success: Lower chunk size to max chunk size",
+			input: &usecase.OpenEndedInitUploadUsecaseInput{TargetFileName: "stream.tar", ChecksumAlgorithm: "xxhash64", ChunkSize: 1024},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(&entity.UploadLimitsResp{MaxChunkSize: 512, MaxChunkCount: 2}, nil)
+				mockClient.EXPECT().InitUpload(ctx, "stream.tar", infrastructure.UploadInitRequest{
+					ChunkSize:         512,
+					ChecksumAlgorithm: "xxhash64",
+					OpenEnded:         true,
+				}).Return(&infrastructure.UploadInitResponse{SessionToken: "token-123"}, nil)
+			},
+			wantOutput: &usecase.UploadUsecaseOutput{SessionToken: "token-123", ChunkSize: 512, UploadChunkSize: 512},
+			wantErr:    false,
+		},
+		{
+			name:  "Error: GetUploadLimits fails on server",
+			input: &usecase.OpenEndedInitUploadUsecaseInput{TargetFileName: "stream.tar", ChecksumAlgorithm: "sha256", ChunkSize: 1024},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(nil, errors.New("server limits error"))
+			},
+			wantErr:              true,
+			expectedErrSubstring: "failed to get upload limits: server limits error",
+		},
+		{
+			name:  "Error: InitUpload fails on server",
+			input: &usecase.OpenEndedInitUploadUsecaseInput{TargetFileName: "stream.tar", ChecksumAlgorithm: "sha256", ChunkSize: 1024},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().GetUploadLimits(ctx).Return(nil, nil)
+				mockClient.EXPECT().InitUpload(ctx, "stream.tar", gomock.Any()).Return(nil, errors.New("server init error"))
+			},
+			wantErr:              true,
+			expectedErrSubstring: "failed to initialize upload for 'stream.tar': server init error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockFileServerHttpClient(ctrl)
+			tt.mockSetup(mockClient)
+
+			uc := usecase.NewInitUploadUsecase(mockClient)
+			output, err := uc.ExecuteOpenEnded(ctx, tt.input)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrSubstring)
+				assert.Nil(t, output)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantOutput, output)
+			}
+		})
+	}
+}
@@ -2,8 +2,11 @@ package usecase
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/tomoya.tokunaga/cli/internal/domain/entity"
+	"github.com/tomoya.tokunaga/cli/internal/util"
 )
 
 type ListUsecase interface {
@@ -13,10 +16,19 @@ type ListUsecase interface {
 type InitUploadUsecase interface {
 	ExecutePrecheck(ctx context.Context, input *InitUploadPrecheckUsecaseInput) (action PostPrecheckAction, output *InitUploadPrecheckUsecaseOutput, err error)
 	Execute(ctx context.Context, input *InitUploadUsecaseInput) (*UploadUsecaseOutput, error)
+	ExecuteOpenEnded(ctx context.Context, input *OpenEndedInitUploadUsecaseInput) (*UploadUsecaseOutput, error)
 }
 
 type UploadUsecase interface {
 	Execute(ctx context.Context, input *UploadUsecaseInput) error
+	ExecuteSingleRequest(ctx context.Context, input *SingleRequestUploadUsecaseInput) error
+	ExecuteStream(ctx context.Context, input *StreamUploadUsecaseInput) error
+	ExecuteAbort(ctx context.Context, sessionToken string) error
+}
+
+type DownloadUsecase interface {
+	ExecutePrecheck(ctx context.Context, input *DownloadPrecheckUsecaseInput) (*DownloadPrecheckUsecaseOutput, error)
+	Execute(ctx context.Context, input *DownloadUsecaseInput) error
 }
 
 type DeleteUsecase interface {
@@ -28,41 +40,107 @@ type PostPrecheckAction int
 
 const (
 	ProceedWithInit PostPrecheckAction = iota
-	ProceedWithReUpload
 	SuggestExistingEntryDeletion
 	Exits
 	ReturnError
 )
 
 type InitUploadPrecheckUsecaseInput struct {
-	FilePath       string
-	TargetFileName string
+	FilePath          string
+	TargetFileName    string
+	ChecksumAlgorithm string
 }
 
 type InitUploadPrecheckUsecaseOutput struct {
-	Checksum string
-	FileSize int64
+	Checksum          string
+	ChecksumAlgorithm string
+	FileSize          int64
 }
 
 type InitUploadUsecaseInput struct {
-	FilePath         string
-	TargetFileName   string
-	OriginalChecksum string
-	ChunkSize        int64
-	IsReUpload       bool
+	FilePath          string
+	TargetFileName    string
+	OriginalChecksum  string
+	ChecksumAlgorithm string
+	ChunkSize         int64
+	CDC               *util.CDCParams // splits the file into content-defined chunks instead of the chunks of ChunkSize when set
+	IsReUpload        bool            // resumes the upload session of the same content started by this user if any
+	Delta             bool            // reuses the chunks of the file with the name on the server which have the same content, so that only the changed chunks are sent
+}
+
+// OpenEndedInitUploadUsecaseInput is the input to initialize an upload of the content whose size isn't known upfront
+type OpenEndedInitUploadUsecaseInput struct {
+	TargetFileName    string
+	ChecksumAlgorithm string
+	ChunkSize         int64
 }
 
 type UploadUsecaseOutput struct {
-	UploadID              uint64
+	SessionToken          string
+	ExpiresAt             time.Time // the chunks need to be uploaded by then to resume the upload after it fails
+	IsResumed             bool      // only the chunks in MissingChunkNumberMap need to be uploaded
+	ChunkSize             uint64    // the size the local file is split by, chosen within the server's upload limits
+	ChunkSizes            []int64   // sizes of the content-defined chunks in order, which is nil for the chunks of ChunkSize
 	UploadChunkSize       uint64
 	MissingChunkNumberMap map[uint64]struct{}
+	MissingChunkOffsetMap map[uint64]int64 // bytes of each missing chunk the server already received
+	ReusedChunks          int              // chunks reusing the ones of the same content on the server, which aren't sent
+	ReusedSize            int64            // bytes of the reused chunks
 }
 
 type UploadUsecaseInput struct {
-	UploadID              uint64
+	SessionToken          string
 	FilePath              string
 	ChunkSize             int64
+	ChunkSizes            []int64 // the file is read by these sizes instead of ChunkSize when set
 	IsReUpload            bool
 	MissingChunkNumberMap map[uint64]struct{}
+	MissingChunkOffsetMap map[uint64]int64
 	ProgressCb            func(size int64)
 }
+
+// SingleRequestUploadUsecaseInput is the input to upload a small file in a single request without an upload session
+type SingleRequestUploadUsecaseInput struct {
+	FilePath          string
+	TargetFileName    string
+	OriginalChecksum  string // the server rejects the content unless it still has the checksum in precheck
+	ChecksumAlgorithm string
+	ProgressCb        func(size int64)
+}
+
+// StreamUploadUsecaseInput is the input to upload the content read from a stream (e.g. stdin) to an open-ended upload
+// session. The checksum is calculated while the content is read
+type StreamUploadUsecaseInput struct {
+	SessionToken      string
+	Reader            io.Reader
+	ChunkSize         int64
+	ChecksumAlgorithm string
+	ProgressCb        func(size int64)
+}
+
+type DownloadPrecheckUsecaseInput struct {
+	TargetFileName string
+	FilePath       string
+	ChunkSize      int64
+}
+
+type DownloadPrecheckUsecaseOutput struct {
+	Checksum                string
+	ChecksumAlgorithm       string
+	FileSize                int64
+	ChunkSize               int64
+	IsDownloaded            bool
+	DownloadedSize          int64
+	CompletedChunkNumberMap map[uint64]struct{}
+}
+
+type DownloadUsecaseInput struct {
+	TargetFileName          string
+	FilePath                string
+	Checksum                string
+	ChecksumAlgorithm       string
+	FileSize                int64
+	ChunkSize               int64
+	CompletedChunkNumberMap map[uint64]struct{}
+	ProgressCb              func(size int64)
+}
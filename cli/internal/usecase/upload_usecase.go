@@ -3,6 +3,7 @@ package usecase
 import (
 	"bufio"
 	"context"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/tomoya.tokunaga/cli/internal/domain/entity"
 	"github.com/tomoya.tokunaga/cli/internal/infrastructure"
+	"github.com/tomoya.tokunaga/cli/internal/util"
 )
 
 // UploadUsecase handles file upload operations
@@ -27,8 +29,9 @@ func NewUploadUsecase(fileClient infrastructure.FileServerHttpClient) *uploadUse
 
 // Create a buffered channel for chunks
 type chunk struct {
-	id   int
-	data []byte
+	id     int
+	data   []byte
+	offset int64 // bytes of the chunk the server already received
 }
 
 // UploadFile uploads a file to the server
@@ -43,51 +46,25 @@ func (s *uploadUsecase) Execute(ctx context.Context, input *UploadUsecaseInput)
 		}
 	}()
 
-	// Retrieve flag values from context
-	concurrency := ctx.Value(entity.ConcurrencyKey).(int)
-	retries := ctx.Value(entity.RetriesKey).(int)
-
-	chunksChan := make(chan chunk, concurrency)
-	errorChan := make(chan error, concurrency)
-
-	var wg sync.WaitGroup
-
-	for range make([]struct{}, concurrency) {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for c := range chunksChan {
-				var uploadErr error
-				for retry := 0; retry <= retries; retry++ {
-					uploadErr = s.fileServerHttpClient.UploadChunk(ctx, input.UploadID, c.id, c.data)
-					if uploadErr == nil {
-						break
-					}
-
-					if retry < retries {
-						time.Sleep(time.Second * time.Duration(retry+1))
-					}
-				}
-
-				if uploadErr != nil {
-					errorChan <- fmt.Errorf("failed to upload chunk %d after %d retries: %w",
-						c.id, retries, uploadErr)
-					return
-				}
-
-				if input.ProgressCb != nil {
-					input.ProgressCb(int64(len(c.data)))
-				}
-			}
-		}()
-	}
+	chunksChan, errorChan := s.startUploadWorkers(ctx, input.SessionToken, input.ProgressCb)
 
 	reader := bufio.NewReaderSize(file, int(input.ChunkSize))
 	chunkID := 0
 
 	for {
-		buffer := make([]byte, input.ChunkSize)
-		bytesRead, err := reader.Read(buffer)
+		// the content-defined chunks are read by their sizes, which add up to the file size
+		bufferSize := input.ChunkSize
+		if input.ChunkSizes != nil {
+			if chunkID >= len(input.ChunkSizes) {
+				break
+			}
+			bufferSize = input.ChunkSizes[chunkID]
+		}
+		buffer := make([]byte, bufferSize)
+		bytesRead, err := io.ReadFull(reader, buffer)
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
 
 		if err != nil && err != io.EOF {
 			close(chunksChan)
@@ -98,15 +75,21 @@ func (s *uploadUsecase) Execute(ctx context.Context, input *UploadUsecaseInput)
 			// Determine if this chunk should be sent. For re-uploading, only send chunks which failed to be uploaded
 			// are subject to re-upload.
 			shouldSend := !input.IsReUpload
+			var offset int64
 			if input.IsReUpload {
 				if _, exists := input.MissingChunkNumberMap[uint64(chunkID)]; exists {
 					shouldSend = true
 				}
+				// continue the content the server already received unless all of it was received, in which case
+				// the chunk is sent again as there's nothing to continue
+				if o := input.MissingChunkOffsetMap[uint64(chunkID)]; o < int64(bytesRead) {
+					offset = o
+				}
 			}
 
 			if shouldSend {
 				select {
-				case chunksChan <- chunk{id: chunkID, data: buffer[:bytesRead]}:
+				case chunksChan <- chunk{id: chunkID, data: buffer[:bytesRead], offset: offset}:
 					// Chunk enqueued successfully
 				case err := <-errorChan:
 					// A worker encountered an error
@@ -125,14 +108,167 @@ func (s *uploadUsecase) Execute(ctx context.Context, input *UploadUsecaseInput)
 
 	close(chunksChan)
 
+	if err, ok := <-errorChan; ok {
+		return err
+	}
+
+	return nil
+}
+
+// ExecuteStream uploads the content read from a stream to an open-ended upload session, and commits the upload with
+// the size and the checksum of the content once all of it is read. The content can't be read again, so a chunk is
+// sent again only while it's kept in memory and the upload can't be resumed once it fails
+func (s *uploadUsecase) ExecuteStream(ctx context.Context, input *StreamUploadUsecaseInput) error {
+	hash, err := util.NewHash(input.ChecksumAlgorithm)
+	if err != nil {
+		return err
+	}
+	if input.ChunkSize <= 0 {
+		return fmt.Errorf("chunk size must be greater than 0")
+	}
+
+	chunksChan, errorChan := s.startUploadWorkers(ctx, input.SessionToken, input.ProgressCb)
+
+	// the chunks are filled up as the stream (e.g. a pipe) may return fewer bytes than requested at a time
+	var totalSize int64
+	chunkID := 0
+	for {
+		buffer := make([]byte, input.ChunkSize)
+		bytesRead, err := io.ReadFull(input.Reader, buffer)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			close(chunksChan)
+			return fmt.Errorf("error reading stream: %w", err)
+		}
+
+		if bytesRead > 0 {
+			_, _ = hash.Write(buffer[:bytesRead])
+			totalSize += int64(bytesRead)
+
+			select {
+			case chunksChan <- chunk{id: chunkID, data: buffer[:bytesRead]}:
+				// Chunk enqueued successfully
+			case err := <-errorChan:
+				// A worker encountered an error
+				close(chunksChan)
+				return err
+			}
+			chunkID++
+		}
+
+		if err != nil {
+			break
+		}
+	}
+
+	close(chunksChan)
+
+	if err, ok := <-errorChan; ok {
+		return err
+	}
+	if totalSize == 0 {
+		return fmt.Errorf("stream is empty")
+	}
+
+	if err := s.fileServerHttpClient.CommitUpload(ctx, input.SessionToken, totalSize, hex.EncodeToString(hash.Sum(nil))); err != nil {
+		return fmt.Errorf("failed to commit upload: %w", err)
+	}
+
+	return nil
+}
+
+// startUploadWorkers starts the workers uploading the chunks sent to the returned channel, each retried up to the
+// retries in the context. The first error of the workers is sent to the returned error channel, which is closed once
+// the chunk channel is closed and all the workers are done
+func (s *uploadUsecase) startUploadWorkers(ctx context.Context, sessionToken string, progressCb func(size int64)) (chan<- chunk, <-chan error) {
+	// Retrieve flag values from context
+	concurrency := ctx.Value(entity.ConcurrencyKey).(int)
+	retries := ctx.Value(entity.RetriesKey).(int)
+
+	chunksChan := make(chan chunk, concurrency)
+	errorChan := make(chan error, concurrency)
+
+	var wg sync.WaitGroup
+
+	for range make([]struct{}, concurrency) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range chunksChan {
+				var uploadErr error
+				for retry := 0; retry <= retries; retry++ {
+					uploadErr = s.fileServerHttpClient.UploadChunk(ctx, sessionToken, c.id, c.data, c.offset)
+					if uploadErr == nil {
+						break
+					}
+					// the server may have discarded the received content (e.g. checksum mismatch), so retry with
+					// the whole chunk
+					c.offset = 0
+
+					if retry < retries {
+						time.Sleep(time.Second * time.Duration(retry+1))
+					}
+				}
+
+				if uploadErr != nil {
+					errorChan <- fmt.Errorf("failed to upload chunk %d after %d retries: %w",
+						c.id, retries, uploadErr)
+					return
+				}
+
+				if progressCb != nil {
+					progressCb(int64(len(c.data)) - c.offset)
+				}
+			}
+		}()
+	}
+
 	// Start a goroutine to close the error channel once all workers are done
 	go func() {
 		wg.Wait()
 		close(errorChan)
 	}()
 
-	if err, ok := <-errorChan; ok {
-		return err
+	return chunksChan, errorChan
+}
+
+// ExecuteSingleRequest uploads the whole content of a small file in a single request. Unlike the chunked upload, the
+// content can't be resumed once the request fails, so the whole content is sent again on each retry
+func (s *uploadUsecase) ExecuteSingleRequest(ctx context.Context, input *SingleRequestUploadUsecaseInput) error {
+	data, err := os.ReadFile(input.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Retrieve flag values from context
+	retries := ctx.Value(entity.RetriesKey).(int)
+
+	var uploadErr error
+	for retry := 0; retry <= retries; retry++ {
+		uploadErr = s.fileServerHttpClient.PutFile(ctx, input.TargetFileName, data, input.OriginalChecksum, input.ChecksumAlgorithm)
+		if uploadErr == nil {
+			break
+		}
+
+		if retry < retries {
+			time.Sleep(time.Second * time.Duration(retry+1))
+		}
+	}
+	if uploadErr != nil {
+		return fmt.Errorf("failed to upload '%s' after %d retries: %w", input.TargetFileName, retries, uploadErr)
+	}
+
+	if input.ProgressCb != nil {
+		input.ProgressCb(int64(len(data)))
+	}
+
+	return nil
+}
+
+// ExecuteAbort aborts the upload session on the server, which stops the chunks still being uploaded and deletes the
+// content uploaded so far
+func (s *uploadUsecase) ExecuteAbort(ctx context.Context, sessionToken string) error {
+	if err := s.fileServerHttpClient.AbortUpload(ctx, sessionToken); err != nil {
+		return fmt.Errorf("failed to abort upload: %w", err)
 	}
 
 	return nil
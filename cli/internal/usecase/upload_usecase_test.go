@@ -3,7 +3,9 @@ package usecase_test
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tomoya.tokunaga/cli/internal/domain/entity"
@@ -23,7 +25,7 @@ func TestUploadUsecase_Execute(t *testing.T) {
 		{
 			name: "When upload chunks succeed, should return no error",
 			input: &usecase.UploadUsecaseInput{
-				UploadID:              123,
+				SessionToken:          "token-123",
 				FilePath:              "testdata/test.txt",
 				ChunkSize:             1024,
 				IsReUpload:            false,
@@ -31,7 +33,7 @@ func TestUploadUsecase_Execute(t *testing.T) {
 			},
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
 				mockClient.EXPECT().
-					UploadChunk(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					UploadChunk(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).
 					AnyTimes()
 			},
@@ -40,7 +42,7 @@ func TestUploadUsecase_Execute(t *testing.T) {
 		{
 			name: "When upload chunk fails with retry exhausted, should return error",
 			input: &usecase.UploadUsecaseInput{
-				UploadID:              123,
+				SessionToken:          "token-123",
 				FilePath:              "testdata/test.txt",
 				ChunkSize:             1024,
 				IsReUpload:            false,
@@ -48,7 +50,7 @@ func TestUploadUsecase_Execute(t *testing.T) {
 			},
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
 				mockClient.EXPECT().
-					UploadChunk(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					UploadChunk(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(errors.New("chunk upload error")).
 					AnyTimes()
 			},
@@ -58,10 +60,10 @@ func TestUploadUsecase_Execute(t *testing.T) {
 		{
 			name: "When is re-upload, should only upload missing chunks",
 			input: &usecase.UploadUsecaseInput{
-				UploadID:   123,
-				FilePath:   "testdata/test.txt",
-				ChunkSize:  1024,
-				IsReUpload: true,
+				SessionToken: "token-123",
+				FilePath:     "testdata/test.txt",
+				ChunkSize:    1024,
+				IsReUpload:   true,
 				MissingChunkNumberMap: map[uint64]struct{}{
 					1: {},
 					3: {},
@@ -69,12 +71,60 @@ func TestUploadUsecase_Execute(t *testing.T) {
 			},
 			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
 				mockClient.EXPECT().
-					UploadChunk(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					UploadChunk(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).
 					AnyTimes()
 			},
 			expectedErr: false,
 		},
+		{
+			name: "When is re-upload with received content, should continue the chunk from its offset and retry the whole chunk",
+			input: &usecase.UploadUsecaseInput{
+				SessionToken:          "token-123",
+				FilePath:              "testdata/test.txt",
+				ChunkSize:             64,
+				IsReUpload:            true,
+				MissingChunkNumberMap: map[uint64]struct{}{1: {}, 2: {}},
+				MissingChunkOffsetMap: map[uint64]int64{1: 10, 2: 58}, // chunk 2 is the last 58 bytes, all of which were received
+			},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				gomock.InOrder(
+					mockClient.EXPECT().
+						UploadChunk(gomock.Any(), "token-123", 1, gomock.Len(64), int64(10)).
+						Return(errors.New("chunk upload error")),
+					mockClient.EXPECT().
+						UploadChunk(gomock.Any(), "token-123", 1, gomock.Len(64), int64(0)).
+						Return(nil),
+				)
+				mockClient.EXPECT().
+					UploadChunk(gomock.Any(), "token-123", 2, gomock.Len(58), int64(0)).
+					Return(nil)
+			},
+			expectedErr: false,
+		},
+		{
+			name: "When chunk sizes are given, should read each chunk by its size",
+			input: &usecase.UploadUsecaseInput{
+				SessionToken:          "token-123",
+				FilePath:              "testdata/test.txt",
+				ChunkSize:             100,
+				ChunkSizes:            []int64{100, 30, 56},
+				IsReUpload:            false,
+				MissingChunkNumberMap: make(map[uint64]struct{}),
+			},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().
+					UploadChunk(gomock.Any(), "token-123", 0, gomock.Len(100), int64(0)).
+					Return(nil)
+				mockClient.EXPECT().
+					UploadChunk(gomock.Any(), "token-123", 1, gomock.Len(30), int64(0)).
+					Return(nil)
+				mockClient.EXPECT().
+					UploadChunk(gomock.Any(), "token-123", 2, gomock.Len(56), int64(0)).
+					Return(nil)
+			},
+			expectedErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -104,3 +154,258 @@ func TestUploadUsecase_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestUploadUsecase_ExecuteSingleRequest(t *testing.T) {
+	tests := []struct {
+		name              string
+		input             *usecase.SingleRequestUploadUsecaseInput
+		mockSetup         func(mockClient *mock.MockFileServerHttpClient)
+		expectedErr       bool
+		expectedErrString string
+	}{
+		{
+			name: "When the request succeeds, should send the whole file with the checksum",
+			input: &usecase.SingleRequestUploadUsecaseInput{
+				FilePath:          "testdata/test.txt",
+				TargetFileName:    "test.txt",
+				OriginalChecksum:  "checksum-123",
+				ChecksumAlgorithm: "sha256",
+			},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().
+					PutFile(gomock.Any(), "test.txt", gomock.Any(), "checksum-123", "sha256").
+					DoAndReturn(func(_ context.Context, _ string, data []byte, _ string, _ string) error {
+						assert.Len(t, data, 186)
+						return nil
+					})
+			},
+			expectedErr: false,
+		},
+		{
+			name: "When the request fails once, should send the whole file again",
+			input: &usecase.SingleRequestUploadUsecaseInput{
+				FilePath:          "testdata/test.txt",
+				TargetFileName:    "test.txt",
+				OriginalChecksum:  "checksum-123",
+				ChecksumAlgorithm: "sha256",
+			},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				gomock.InOrder(
+					mockClient.EXPECT().PutFile(gomock.Any(), "test.txt", gomock.Any(), "checksum-123", "sha256").Return(errors.New("network error")),
+					mockClient.EXPECT().PutFile(gomock.Any(), "test.txt", gomock.Any(), "checksum-123", "sha256").Return(nil),
+				)
+			},
+			expectedErr: false,
+		},
+		{
+			name: "When the request fails with retry exhausted, should return error",
+			input: &usecase.SingleRequestUploadUsecaseInput{
+				FilePath:          "testdata/test.txt",
+				TargetFileName:    "test.txt",
+				OriginalChecksum:  "checksum-123",
+				ChecksumAlgorithm: "sha256",
+			},
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().
+					PutFile(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(errors.New("server returned status 400")).
+					Times(2)
+			},
+			expectedErr:       true,
+			expectedErrString: "failed to upload 'test.txt' after 1 retries",
+		},
+		{
+			name: "When the file doesn't exist, should return error without sending it",
+			input: &usecase.SingleRequestUploadUsecaseInput{
+				FilePath:       "testdata/missing.txt",
+				TargetFileName: "missing.txt",
+			},
+			mockSetup:         func(mockClient *mock.MockFileServerHttpClient) {},
+			expectedErr:       true,
+			expectedErrString: "failed to read file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.WithValue(context.Background(), entity.RetriesKey, 1)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockFileServerHttpClient(ctrl)
+			tt.mockSetup(mockClient)
+
+			usecase := usecase.NewUploadUsecase(mockClient)
+			err := usecase.ExecuteSingleRequest(ctx, tt.input)
+
+			if tt.expectedErr {
+				assert.Error(t, err)
+				if tt.expectedErrString != "" {
+					assert.Contains(t, err.Error(), tt.expectedErrString)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUploadUsecase_ExecuteStream(t *testing.T) {
+	// "hello world" is read in chunks of 4 bytes
+	helloWorldChecksum := "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+	tests := []struct {
+		name              string
+		content           string
+		checksumAlgorithm string
+		mockSetup         func(mockClient *mock.MockFileServerHttpClient)
+		expectedErr       bool
+		expectedErrString string
+	}{
+		{
+			name:              "When all chunks are uploaded, should commit with the size and the checksum",
+			content:           "hello world",
+			checksumAlgorithm: "sha256",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				for chunkID, data := range []string{"hell", "o wo", "rld"} {
+					mockClient.EXPECT().UploadChunk(gomock.Any(), "token-123", chunkID, []byte(data), int64(0)).Return(nil)
+				}
+				mockClient.EXPECT().CommitUpload(gomock.Any(), "token-123", int64(11), helloWorldChecksum).Return(nil)
+			},
+			expectedErr: false,
+		},
+		{
+			name:              "When a chunk fails once, should send the chunk again",
+			content:           "hell",
+			checksumAlgorithm: "sha256",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				gomock.InOrder(
+					mockClient.EXPECT().UploadChunk(gomock.Any(), "token-123", 0, []byte("hell"), int64(0)).Return(errors.New("network error")),
+					mockClient.EXPECT().UploadChunk(gomock.Any(), "token-123", 0, []byte("hell"), int64(0)).Return(nil),
+				)
+				mockClient.EXPECT().CommitUpload(gomock.Any(), "token-123", int64(4), gomock.Any()).Return(nil)
+			},
+			expectedErr: false,
+		},
+		{
+			name:              "When a chunk fails with retry exhausted, should return error without commit",
+			content:           "hello world",
+			checksumAlgorithm: "sha256",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().
+					UploadChunk(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(errors.New("chunk upload error")).
+					AnyTimes()
+			},
+			expectedErr:       true,
+			expectedErrString: "failed to upload chunk",
+		},
+		{
+			name:              "When the commit fails, should return error",
+			content:           "hell",
+			checksumAlgorithm: "sha256",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().UploadChunk(gomock.Any(), "token-123", 0, []byte("hell"), int64(0)).Return(nil)
+				mockClient.EXPECT().CommitUpload(gomock.Any(), "token-123", int64(4), gomock.Any()).Return(errors.New("server returned status 422"))
+			},
+			expectedErr:       true,
+			expectedErrString: "failed to commit upload: server returned status 422",
+		},
+		{
+			name:              "When the stream is empty, should return error without commit",
+			content:           "",
+			checksumAlgorithm: "sha256",
+			mockSetup:         func(mockClient *mock.MockFileServerHttpClient) {},
+			expectedErr:       true,
+			expectedErrString: "stream is empty",
+		},
+		{
+			name:              "When the checksum algorithm is unsupported, should return error without reading the stream",
+			content:           "hello world",
+			checksumAlgorithm: "md4",
+			mockSetup:         func(mockClient *mock.MockFileServerHttpClient) {},
+			expectedErr:       true,
+			expectedErrString: `unsupported checksum algorithm "md4"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.WithValue(context.Background(), entity.ConcurrencyKey, 2)
+			ctx = context.WithValue(ctx, entity.RetriesKey, 1)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockFileServerHttpClient(ctrl)
+			tt.mockSetup(mockClient)
+
+			uc := usecase.NewUploadUsecase(mockClient)
+			// the stream returns a byte at a time as a pipe may, which the chunks are filled up from
+			err := uc.ExecuteStream(ctx, &usecase.StreamUploadUsecaseInput{
+				SessionToken:      "token-123",
+				Reader:            iotest.OneByteReader(strings.NewReader(tt.content)),
+				ChunkSize:         4,
+				ChecksumAlgorithm: tt.checksumAlgorithm,
+			})
+
+			if tt.expectedErr {
+				assert.Error(t, err)
+				if tt.expectedErrString != "" {
+					assert.Contains(t, err.Error(), tt.expectedErrString)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUploadUsecase_ExecuteAbort(t *testing.T) {
+	tests := []struct {
+		name        string
+		mockSetup   func(mockClient *mock.MockFileServerHttpClient)
+		expectedErr error
+	}{
+		{
+			name: "When the server aborts the upload, should return no error",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().AbortUpload(gomock.Any(), "token-123").Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "When the upload is already committed, should return error",
+			mockSetup: func(mockClient *mock.MockFileServerHttpClient) {
+				mockClient.EXPECT().AbortUpload(gomock.Any(), "token-123").Return(errors.New("server returned status 409"))
+			},
+			expectedErr: errors.New("failed to abort upload: server returned status 409"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockClient := mock.NewMockFileServerHttpClient(ctrl)
+			tt.mockSetup(mockClient)
+
+			usecase := usecase.NewUploadUsecase(mockClient)
+			err := usecase.ExecuteAbort(context.Background(), "token-123")
+
+			if tt.expectedErr != nil {
+				assert.EqualError(t, err, tt.expectedErr.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -1,15 +1,56 @@
 package util
 
 import (
+	"bufio"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/hex"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
 	"os"
+	"slices"
+
+	"github.com/cespare/xxhash/v2"
 )
 
-// CalculateChecksum computes the SHA256 checksum of a file in a streaming fashion
-func CalculateChecksum(filePath string) (string, error) {
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// checksumAlgorithms maps the checksum algorithms supported by the file server to their implementations
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"sha256":   sha256.New,
+	"sha512":   sha512.New,
+	"crc32c":   func() hash.Hash { return crc32.New(crc32cTable) },
+	"xxhash64": func() hash.Hash { return xxhash.New() },
+}
+
+// NewHash returns a new hash of the given checksum algorithm
+func NewHash(algorithm string) (hash.Hash, error) {
+	newHash, ok := checksumAlgorithms[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported checksum algorithm %q (supported: %v)", algorithm, ChecksumAlgorithms())
+	}
+	return newHash(), nil
+}
+
+// ChecksumAlgorithms returns the supported checksum algorithms in alphabetical order
+func ChecksumAlgorithms() []string {
+	algorithms := make([]string, 0, len(checksumAlgorithms))
+	for algorithm := range checksumAlgorithms {
+		algorithms = append(algorithms, algorithm)
+	}
+	slices.Sort(algorithms)
+	return algorithms
+}
+
+// CalculateChecksum computes the checksum of a file with the given algorithm in a streaming fashion
+func CalculateChecksum(filePath string, algorithm string) (string, error) {
+	hash, err := NewHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open file for checksum calculation: %w", err)
@@ -20,8 +61,6 @@ func CalculateChecksum(filePath string) (string, error) {
 		}
 	}()
 
-	hash := sha256.New()
-
 	// Reads the file content and writes it to the hash in streaming fashion
 	if _, err := io.Copy(hash, file); err != nil {
 		return "", fmt.Errorf("error calculating checksum: %w", err)
@@ -29,3 +68,33 @@ func CalculateChecksum(filePath string) (string, error) {
 
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
+
+// CalculateChunkChecksums computes the SHA-256 checksums of the chunks of the given sizes the file is split into in
+// order, which are compared with the checksums of the chunks on the server
+func CalculateChunkChecksums(filePath string, chunkSizes []int64) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file for checksum calculation: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Println("Failed to close file")
+		}
+	}()
+
+	reader := bufio.NewReaderSize(file, 1024*1024)
+	checksums := make([]string, len(chunkSizes))
+	for i, size := range chunkSizes {
+		hash := sha256.New()
+		n, err := io.CopyN(hash, reader, size)
+		if err == io.EOF {
+			return nil, fmt.Errorf("chunk %d needs %d bytes, but only %d bytes are left in the file", i, size, n)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error calculating checksum of chunk %d: %w", i, err)
+		}
+		checksums[i] = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return checksums, nil
+}
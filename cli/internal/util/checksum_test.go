@@ -3,6 +3,8 @@ package util
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -36,29 +38,62 @@ func TestCalculateChecksum(t *testing.T) {
 	tests := []struct {
 		name        string
 		filePath    string
+		algorithm   string
 		expected    string
 		expectError bool
 	}{
 		{
 			name:        "Empty file",
+			algorithm:   "sha256",
 			filePath:    filePaths["empty.txt"],
 			expected:    "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
 			expectError: false,
 		},
 		{
 			name:        "Simple file",
+			algorithm:   "sha256",
 			filePath:    filePaths["simple.txt"],
 			expected:    "315f5bdb76d078c43b8ac0064e4a0164612b1fce77c869345bfc94c75894edd3",
 			expectError: false,
 		},
 		{
 			name:        "Multiline file",
+			algorithm:   "sha256",
 			filePath:    filePaths["multiline.txt"],
 			expected:    "391ba54caa9e9da3dd31dca1eff275e706979e76c1f60c91401f0624734f52b0",
 			expectError: false,
 		},
+		{
+			name:        "Simple file (sha512)",
+			algorithm:   "sha512",
+			filePath:    filePaths["simple.txt"],
+			expected:    "c1527cd893c124773d811911970c8fe6e857d6df5dc9226bd8a160614c0cd963a4ddea2b94bb7d36021ef9d865d5cea294a82dd49a0bb269f51f6e7a57f79421",
+			expectError: false,
+		},
+		{
+			name:        "Simple file (crc32c)",
+			algorithm:   "crc32c",
+			filePath:    filePaths["simple.txt"],
+			expected:    "c8a106e5",
+			expectError: false,
+		},
+		{
+			name:        "Simple file (xxhash64)",
+			algorithm:   "xxhash64",
+			filePath:    filePaths["simple.txt"],
+			expected:    "f58336a78b6f9476",
+			expectError: false,
+		},
+		{
+			name:        "Unsupported algorithm",
+			algorithm:   "md5",
+			filePath:    filePaths["simple.txt"],
+			expected:    "",
+			expectError: true,
+		},
 		{
 			name:        "Non-existent file",
+			algorithm:   "sha256",
 			filePath:    filepath.Join(tempDir, "does-not-exist.txt"),
 			expected:    "",
 			expectError: true,
@@ -69,7 +104,7 @@ func TestCalculateChecksum(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			checksum, err := CalculateChecksum(tt.filePath)
+			checksum, err := CalculateChecksum(tt.filePath, tt.algorithm)
 
 			if tt.expectError && err == nil {
 				t.Errorf("Expected error but got none")
@@ -84,3 +119,60 @@ func TestCalculateChecksum(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateChunkChecksums(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "chunks.txt")
+	if err := os.WriteFile(filePath, []byte("Hello, world!"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		chunkSizes    []int64
+		expected      []string
+		expectedError string
+	}{
+		{
+			name:       "Chunks cover the file",
+			chunkSizes: []int64{5, 8},
+			expected: []string{
+				// "Hello"
+				"185f8db32271fe25f561a6fc938b2e264306ec304eda518007d1764826381969",
+				// ", world!"
+				"346af43a8ee6e382afdad5135d017a2714ad3f019eef6ec506d437b471e3d7dc",
+			},
+		},
+		{
+			name:       "No chunks",
+			chunkSizes: []int64{},
+			expected:   []string{},
+		},
+		{
+			name:          "Chunks exceed the file",
+			chunkSizes:    []int64{5, 10},
+			expectedError: "chunk 1 needs 10 bytes, but only 8 bytes are left in the file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			checksums, err := CalculateChunkChecksums(filePath, tt.chunkSizes)
+
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Errorf("Expected error containing %q but got: %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if !reflect.DeepEqual(checksums, tt.expected) {
+				t.Errorf("Expected checksums %v but got %v", tt.expected, checksums)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"slices"
+)
+
+// The methods splitting a file into the chunks sent to the file server
+const (
+	ChunkingFixed = "fixed" // every chunk except the last one has the chunk size
+	ChunkingCDC   = "cdc"   // the chunks are cut where the content decides (FastCDC), so they have variable sizes
+)
+
+// ChunkingMethods returns the supported chunking methods in alphabetical order
+func ChunkingMethods() []string {
+	methods := []string{ChunkingFixed, ChunkingCDC}
+	slices.Sort(methods)
+	return methods
+}
+
+// gearTable maps each byte to the random value the rolling hash of FastCDC adds for it. The values are generated by
+// SplitMix64 with a fixed seed, since the same content needs to be cut at the same positions by every run
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+	state := uint64(0x2545f4914f6cdd1d)
+	for i := range table {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// CDCParams are the sizes of the content-defined chunks. A chunk is cut where the rolling hash of the last 64 bytes
+// matches a mask, which is harder to match before the average size and easier after it (normalized chunking), so the
+// chunk sizes gather around the average. A chunk is never smaller than the minimum, except the last one, nor larger
+// than the maximum
+type CDCParams struct {
+	MinSize int64
+	AvgSize int64
+	MaxSize int64
+}
+
+// Validate checks that the sizes are positive and in the order of the minimum, the average and the maximum
+func (p CDCParams) Validate() error {
+	if p.MinSize <= 0 {
+		return fmt.Errorf("minimum chunk size must be greater than 0")
+	}
+	if p.MinSize > p.AvgSize || p.AvgSize > p.MaxSize {
+		return fmt.Errorf("chunk sizes need to be minimum <= average <= maximum, but got %d, %d and %d", p.MinSize, p.AvgSize, p.MaxSize)
+	}
+	return nil
+}
+
+// ChunkSizes returns the sizes of the content-defined chunks the content is split into in order
+func (p CDCParams) ChunkSizes(r io.Reader) ([]int64, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	// the mask before the average has one more bit than the one after it, where the bits are the high ones of the
+	// hash, which are affected by the most bytes
+	avgBits := bits.Len64(uint64(p.AvgSize)) - 1
+	maskSmall := ^uint64(0) << (63 - avgBits)
+	maskLarge := ^uint64(0) << (65 - max(avgBits, 2))
+
+	reader := bufio.NewReaderSize(r, 1024*1024)
+	sizes := []int64{}
+	var size int64
+	var hash uint64
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading content: %w", err)
+		}
+		size++
+
+		// the bytes up to the minimum size aren't hashed, as no chunk is cut there
+		if size < p.MaxSize && size <= p.MinSize {
+			continue
+		}
+		hash = (hash << 1) + gearTable[b]
+		mask := maskLarge
+		if size < p.AvgSize {
+			mask = maskSmall
+		}
+		if hash&mask == 0 || size >= p.MaxSize {
+			sizes = append(sizes, size)
+			size, hash = 0, 0
+		}
+	}
+	if size > 0 {
+		sizes = append(sizes, size)
+	}
+
+	return sizes, nil
+}
+
+// CalculateChunkSizes returns the sizes of the content-defined chunks the file is split into in order
+func CalculateChunkSizes(filePath string, params CDCParams) ([]int64, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Println("Failed to close file")
+		}
+	}()
+
+	return params.ChunkSizes(file)
+}
@@ -0,0 +1,124 @@
+package util
+
+import (
+	"bytes"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCDCParams_ChunkSizes(t *testing.T) {
+	params := CDCParams{MinSize: 1024, AvgSize: 4096, MaxSize: 16384}
+	content := make([]byte, 1024*1024)
+	rand.New(rand.NewSource(1)).Read(content)
+
+	sizes, err := params.ChunkSizes(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("ChunkSizes returned error: %v", err)
+	}
+
+	var total int64
+	for i, size := range sizes {
+		if size > params.MaxSize || (size < params.MinSize && i < len(sizes)-1) {
+			t.Errorf("Chunk %d has %d bytes, which is out of %d-%d bytes", i, size, params.MinSize, params.MaxSize)
+		}
+		total += size
+	}
+	if total != int64(len(content)) {
+		t.Fatalf("Chunks make up %d bytes, expected %d bytes", total, len(content))
+	}
+	// the sizes gather around the average
+	if avg := total / int64(len(sizes)); avg < params.MinSize*2 || avg > params.MaxSize/2 {
+		t.Errorf("Average chunk size is %d bytes, which is far from %d bytes", avg, params.AvgSize)
+	}
+
+	// the same content is split at the same positions
+	again, err := params.ChunkSizes(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("ChunkSizes returned error: %v", err)
+	}
+	if !slices.Equal(sizes, again) {
+		t.Errorf("Expected the same chunk sizes for the same content")
+	}
+
+	// a byte inserted at the start only changes the chunks around it, and the rest are cut at the same positions
+	modified, err := params.ChunkSizes(bytes.NewReader(append([]byte{0x42}, content...)))
+	if err != nil {
+		t.Fatalf("ChunkSizes returned error: %v", err)
+	}
+	original := chunkContents(content, sizes)
+	shared := 0
+	for chunk := range chunkContents(append([]byte{0x42}, content...), modified) {
+		if _, ok := original[chunk]; ok {
+			shared++
+		}
+	}
+	if shared < len(sizes)-3 {
+		t.Errorf("Expected all but a few of %d chunks to be unchanged, but %d are", len(sizes), shared)
+	}
+}
+
+func TestCDCParams_ChunkSizes_SmallContent(t *testing.T) {
+	params := CDCParams{MinSize: 1024, AvgSize: 4096, MaxSize: 16384}
+
+	sizes, err := params.ChunkSizes(strings.NewReader("hello world"))
+	if err != nil {
+		t.Fatalf("ChunkSizes returned error: %v", err)
+	}
+	if !slices.Equal(sizes, []int64{11}) {
+		t.Errorf("Expected a single chunk of 11 bytes, got %v", sizes)
+	}
+
+	// the chunks of the same minimum and maximum sizes are cut at the fixed size
+	sizes, err = CDCParams{MinSize: 4, AvgSize: 4, MaxSize: 4}.ChunkSizes(strings.NewReader("hello world"))
+	if err != nil {
+		t.Fatalf("ChunkSizes returned error: %v", err)
+	}
+	if !slices.Equal(sizes, []int64{4, 4, 3}) {
+		t.Errorf("Expected chunks of 4, 4 and 3 bytes, got %v", sizes)
+	}
+
+	sizes, err = params.ChunkSizes(strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("ChunkSizes returned error: %v", err)
+	}
+	if len(sizes) != 0 {
+		t.Errorf("Expected no chunks for empty content, got %v", sizes)
+	}
+}
+
+func TestCDCParams_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      CDCParams
+		expectError bool
+	}{
+		{name: "Valid sizes", params: CDCParams{MinSize: 1, AvgSize: 2, MaxSize: 3}},
+		{name: "Same sizes", params: CDCParams{MinSize: 2, AvgSize: 2, MaxSize: 2}},
+		{name: "Zero minimum", params: CDCParams{MinSize: 0, AvgSize: 2, MaxSize: 3}, expectError: true},
+		{name: "Average below minimum", params: CDCParams{MinSize: 3, AvgSize: 2, MaxSize: 4}, expectError: true},
+		{name: "Maximum below average", params: CDCParams{MinSize: 1, AvgSize: 4, MaxSize: 3}, expectError: true},
+	}
+
+	for _, tc := range tests {
+		err := tc.params.Validate()
+		if tc.expectError && err == nil {
+			t.Errorf("%s: expected an error, got nil", tc.name)
+		}
+		if !tc.expectError && err != nil {
+			t.Errorf("%s: expected no error, got %v", tc.name, err)
+		}
+	}
+}
+
+// chunkContents returns the set of the chunks the content is split into by the sizes
+func chunkContents(content []byte, sizes []int64) map[string]struct{} {
+	chunks := make(map[string]struct{}, len(sizes))
+	var offset int64
+	for _, size := range sizes {
+		chunks[string(content[offset:offset+size])] = struct{}{}
+		offset += size
+	}
+	return chunks
+}
@@ -0,0 +1,92 @@
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// The compression algorithms of the content sent to the file server, named after their Content-Encoding tokens
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// compressionLevels maps the compression algorithms to the range of their levels. Level 0 lets each algorithm choose
+// its default level
+var compressionLevels = map[string][2]int{
+	CompressionNone: {0, 0},
+	CompressionGzip: {gzip.BestSpeed, gzip.BestCompression},
+	CompressionZstd: {1, 22},
+}
+
+// CompressionAlgorithms returns the supported compression algorithms in alphabetical order
+func CompressionAlgorithms() []string {
+	algorithms := make([]string, 0, len(compressionLevels))
+	for algorithm := range compressionLevels {
+		algorithms = append(algorithms, algorithm)
+	}
+	slices.Sort(algorithms)
+	return algorithms
+}
+
+// ValidateCompression checks that the compression algorithm is supported and the level is in its range
+func ValidateCompression(algorithm string, level int) error {
+	levels, ok := compressionLevels[algorithm]
+	if !ok {
+		return fmt.Errorf("unsupported compression %q (supported: %v)", algorithm, CompressionAlgorithms())
+	}
+	if level != 0 && (level < levels[0] || level > levels[1]) {
+		return fmt.Errorf("compression level of %s needs to be between %d and %d, or 0 for its default", algorithm, levels[0], levels[1])
+	}
+	return nil
+}
+
+// Compress compresses the data with the algorithm at the level. The data is returned as it is for CompressionNone
+func Compress(data []byte, algorithm string, level int) ([]byte, error) {
+	if err := ValidateCompression(algorithm, level); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch algorithm {
+	case CompressionNone:
+		return data, nil
+	case CompressionGzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		gzipWriter, err := gzip.NewWriterLevel(&buf, level)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip writer: %w", err)
+		}
+		w = gzipWriter
+	case CompressionZstd:
+		zstdLevel := zstd.SpeedDefault
+		if level != 0 {
+			zstdLevel = zstd.EncoderLevelFromZstd(level)
+		}
+		zstdWriter, err := zstd.NewWriter(&buf, zstd.WithEncoderLevel(zstdLevel), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+		}
+		w = zstdWriter
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write data to %s writer: %w", algorithm, err)
+	}
+
+	// Close the writer to flush any pending data
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close %s writer: %w", algorithm, err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,92 @@
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestCompress(t *testing.T) {
+	data := []byte(strings.Repeat("hello world ", 1000))
+
+	for _, algorithm := range CompressionAlgorithms() {
+		for _, level := range []int{0, 1, 9} {
+			if algorithm == CompressionNone && level != 0 {
+				continue
+			}
+			t.Run(fmt.Sprintf("%s level %d", algorithm, level), func(t *testing.T) {
+				compressed, err := Compress(data, algorithm, level)
+				if err != nil {
+					t.Fatalf("Compress returned error: %v", err)
+				}
+				if algorithm != CompressionNone && len(compressed) >= len(data) {
+					t.Errorf("Compressed data is %d bytes, which isn't smaller than %d bytes", len(compressed), len(data))
+				}
+
+				decompressed, err := decompress(compressed, algorithm)
+				if err != nil {
+					t.Fatalf("Failed to decompress data: %v", err)
+				}
+				if !bytes.Equal(data, decompressed) {
+					t.Errorf("Decompressed data doesn't match the original data")
+				}
+			})
+		}
+	}
+}
+
+// decompress decompresses the data compressed with the algorithm
+func decompress(data []byte, algorithm string) ([]byte, error) {
+	switch algorithm {
+	case CompressionGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(reader)
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer decoder.Close()
+		return io.ReadAll(decoder)
+	}
+	return data, nil
+}
+
+func TestValidateCompression(t *testing.T) {
+	tests := []struct {
+		algorithm   string
+		level       int
+		expectedErr string
+	}{
+		{algorithm: "none", level: 0},
+		{algorithm: "gzip", level: 9},
+		{algorithm: "zstd", level: 22},
+		{algorithm: "zstd", level: 0},
+		{algorithm: "gzip", level: 10, expectedErr: "compression level of gzip needs to be between 1 and 9"},
+		{algorithm: "none", level: 1, expectedErr: "compression level of none needs to be between 0 and 0"},
+		{algorithm: "br", level: 0, expectedErr: `unsupported compression "br"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s level %d", tt.algorithm, tt.level), func(t *testing.T) {
+			err := ValidateCompression(tt.algorithm, tt.level)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -3,11 +3,15 @@ USE fs_store;
 -- Create files table
 CREATE TABLE IF NOT EXISTS `files` (
   `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
-  `name` VARCHAR(255) NOT NULL,
+  `name` VARCHAR(255) NULL, -- NULL until the upload session of the file is committed
   `size` BIGINT UNSIGNED NOT NULL DEFAULT 0,
   `checksum` VARCHAR(255) NOT NULL,
-  `chunk_size` BIGINT UNSIGNED NOT NULL DEFAULT 0,
-  `status` ENUM('INITIALIZED', 'IN_PROGRESS', 'FAILED', 'UPLOADED') NOT NULL DEFAULT 'INITIALIZED',
+  `checksum_algorithm` VARCHAR(32) NOT NULL DEFAULT 'sha256',
+  `merkle_root` VARCHAR(64) NOT NULL DEFAULT '',
+  `chunk_size` BIGINT UNSIGNED NOT NULL DEFAULT 0, -- the largest chunk size when the chunks have variable sizes
+  `variable_chunks` BOOLEAN NOT NULL DEFAULT FALSE, -- the offsets and the sizes of the chunks are recorded in file_chunks
+  `storage_codec` VARCHAR(16) NOT NULL DEFAULT 'none', -- compression of the stored chunks: none, gzip or zstd
+  `status` ENUM('INITIALIZED', 'IN_PROGRESS', 'FAILED', 'UPLOADED', 'VERIFYING', 'CORRUPT', 'DAMAGED') NOT NULL DEFAULT 'INITIALIZED',
   `total_chunks` INT UNSIGNED NOT NULL DEFAULT 0,
   `uploaded_chunks` INT UNSIGNED DEFAULT 0,
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -18,17 +22,57 @@ CREATE TABLE IF NOT EXISTS `files` (
   CHECK (`total_chunks` >= `uploaded_chunks`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
+-- Create blobs table, which keeps the content shared by the chunks of the same content
+CREATE TABLE IF NOT EXISTS `blobs` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `checksum` VARCHAR(64) NOT NULL, -- SHA-256 of the uncompressed content
+  `storage_codec` VARCHAR(16) NOT NULL DEFAULT 'none',
+  `size` BIGINT UNSIGNED NOT NULL DEFAULT 0, -- size of the uncompressed content
+  `file_path` VARCHAR(1024) NOT NULL,
+  `ref_count` BIGINT UNSIGNED NOT NULL DEFAULT 0, -- the number of chunks referring to the blob
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_blobs_checksum_codec` (`checksum`, `storage_codec`),
+  INDEX `idx_blobs_ref_count` (`ref_count`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
 -- Create file_chunks table
 CREATE TABLE IF NOT EXISTS `file_chunks` (
   `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
   `parent_id` BIGINT UNSIGNED NOT NULL,
-  `status` ENUM('INITIALIZED', 'IN_PROGRESS', 'FAILED', 'UPLOADED') NOT NULL DEFAULT 'INITIALIZED',
+  `status` ENUM('INITIALIZED', 'IN_PROGRESS', 'FAILED', 'UPLOADED', 'DAMAGED') NOT NULL DEFAULT 'INITIALIZED',
   `chunk_number` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  `offset` BIGINT UNSIGNED NOT NULL DEFAULT 0, -- recorded only when the chunks of the file have variable sizes
   `size` BIGINT UNSIGNED NOT NULL DEFAULT 0,
+  `checksum` VARCHAR(128) NOT NULL DEFAULT '',
   `file_path` VARCHAR(1024) NOT NULL,
+  `blob_id` BIGINT UNSIGNED NULL, -- NULL until the chunk is uploaded, or when the chunk is stored on its own
   `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
   `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
   PRIMARY KEY (`id`),
   INDEX `idx_file_chunks_parent_status` (`parent_id`, `status`),
-  CONSTRAINT `fk_file_chunks_parent` FOREIGN KEY (`parent_id`) REFERENCES `files` (`id`) ON DELETE CASCADE
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci; 
\ No newline at end of file
+  INDEX `idx_file_chunks_blob_id` (`blob_id`),
+  CONSTRAINT `fk_file_chunks_parent` FOREIGN KEY (`parent_id`) REFERENCES `files` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_file_chunks_blob` FOREIGN KEY (`blob_id`) REFERENCES `blobs` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci; 
+
+-- Create upload_sessions table
+CREATE TABLE IF NOT EXISTS `upload_sessions` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `token` VARCHAR(64) NOT NULL,
+  `file_id` BIGINT UNSIGNED NOT NULL,
+  `target_name` VARCHAR(255) NOT NULL,
+  `owner` VARCHAR(255) NOT NULL DEFAULT '',
+  `open_ended` BOOLEAN NOT NULL DEFAULT FALSE,
+  `multipart` BOOLEAN NOT NULL DEFAULT FALSE,
+  `expires_at` DATETIME NOT NULL,
+  `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_upload_sessions_token` (`token`),
+  UNIQUE KEY `idx_upload_sessions_file_id` (`file_id`),
+  INDEX `idx_upload_sessions_target_name` (`target_name`),
+  INDEX `idx_upload_sessions_expires_at` (`expires_at`),
+  CONSTRAINT `fk_upload_sessions_file` FOREIGN KEY (`file_id`) REFERENCES `files` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
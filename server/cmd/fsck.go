@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"golang.org/x/exp/slog"
+)
+
+// Exit codes of the fsck subcommand
+const (
+	fsckExitOK           = 0
+	fsckExitError        = 1
+	fsckExitInconsistent = 2
+)
+
+// FsckCommand holds the components needed for the fsck subcommand
+type FsckCommand struct {
+	Logger          *slog.Logger
+	FileFsckUseCase usecase.FileFsckUseCase
+}
+
+// Run checks the consistency between the database and the storage, and writes the report to the writer in JSON. It
+// returns the exit code, which is fsckExitInconsistent when inconsistencies are found and not repaired.
+func (c *FsckCommand) Run(ctx context.Context, args []string, writer io.Writer) int {
+	flags := flag.NewFlagSet("fsck", flag.ContinueOnError)
+	repair := flags.Bool("repair", false, "repair the inconsistencies so that the affected files can be re-uploaded")
+	if err := flags.Parse(args); err != nil {
+		return fsckExitError
+	}
+
+	report, err := c.FileFsckUseCase.Execute(ctx, *repair)
+	if err != nil {
+		c.Logger.Error("fsck failed", "error", err.Error(), "code", err.ErrorCode())
+		return fsckExitError
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		c.Logger.Error("failed to write the fsck report", "error", err)
+		return fsckExitError
+	}
+
+	if !report.IsConsistent() && !*repair {
+		fmt.Fprintln(flags.Output(), "inconsistencies are found. run with --repair to repair them")
+		return fsckExitInconsistent
+	}
+	return fsckExitOK
+}
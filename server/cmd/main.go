@@ -1,11 +1,21 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 )
 
 func main() {
+	// Run the consistency check between the database and the storage instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "fsck" {
+		cmd, err := InitializeFsckCommand()
+		if err != nil {
+			log.Fatalf("Failed to initialize fsck: %v", err)
+		}
+		os.Exit(cmd.Run(context.Background(), os.Args[2:], os.Stdout))
+	}
+
 	// Initialize the application with dependency injection
 	app, err := InitializeApplication()
 	if err != nil {
@@ -18,6 +18,7 @@ import (
 	"github.com/tomoya.tokunaga/server/internal/di"
 	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	"github.com/tomoya.tokunaga/server/internal/interface/api/router"
+	"github.com/tomoya.tokunaga/server/internal/interface/scheduler"
 	"golang.org/x/exp/slog"
 )
 
@@ -27,6 +28,12 @@ type Application struct {
 	Logger *slog.Logger
 	Router *router.Router
 	Server *http.Server
+	// Scrubber re-verifies the stored chunks in the background
+	ScrubScheduler *scheduler.ScrubScheduler
+	// Sweeper deletes the expired upload sessions in the background
+	SessionSweepScheduler *scheduler.SessionSweepScheduler
+	// Garbage collector deletes the unreferenced blobs in the background
+	BlobGCScheduler *scheduler.BlobGCScheduler
 }
 
 // HTTPServerProvider provides the configured HTTP server
@@ -52,6 +59,13 @@ func (app *Application) Run() error {
 		}
 	}()
 
+	// Start the scrubber, the session sweeper and the garbage collector, which stop on the shutdown
+	scrubCtx, cancelScrub := context.WithCancel(context.Background())
+	defer cancelScrub()
+	go app.ScrubScheduler.Run(scrubCtx)
+	go app.SessionSweepScheduler.Run(scrubCtx)
+	go app.BlobGCScheduler.Run(scrubCtx)
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -81,3 +95,12 @@ func InitializeApplication() (*Application, error) {
 	)
 	return nil, nil
 }
+
+// InitializeFsckCommand initializes the components for the fsck subcommand
+func InitializeFsckCommand() (*FsckCommand, error) {
+	wire.Build(
+		di.FsckProvider,
+		wire.Struct(new(FsckCommand), "*"),
+	)
+	return nil, nil
+}
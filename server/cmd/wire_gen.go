@@ -12,6 +12,7 @@ import (
 	"github.com/tomoya.tokunaga/server/internal/di"
 	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	"github.com/tomoya.tokunaga/server/internal/interface/api/router"
+	"github.com/tomoya.tokunaga/server/internal/interface/scheduler"
 	"golang.org/x/exp/slog"
 	"net/http"
 	"os"
@@ -31,24 +32,57 @@ func InitializeApplication() (*Application, error) {
 		return nil, err
 	}
 	fileRepository := di.FileRepositoryProvider(db)
+	uploadSessionRepository := di.UploadSessionRepositoryProvider(db)
 	fileStorageRepository := di.StorageRepositoryProvider(config, logger)
-	fileUploadUseCase := di.FileUploadUseCaseProvider(fileRepository, fileStorageRepository, config)
+	fileUploadUseCase := di.FileUploadUseCaseProvider(fileRepository, uploadSessionRepository, fileStorageRepository, config)
 	fileUploadHandler := di.FileUploadHandlerProvider(fileUploadUseCase, config, logger)
-	fileGetUseCase := di.FileGetUseCaseProvider(fileRepository)
+	fileGetUseCase := di.FileGetUseCaseProvider(fileRepository, fileStorageRepository)
 	fileGetHandler := di.FileGetHandlerProvider(fileGetUseCase, config, logger)
 	fileDeleteUseCase := di.FileDeleteUseCaseProvider(fileRepository, fileStorageRepository, config)
 	fileDeleteHandler := di.FileDeleteHandlerProvider(fileDeleteUseCase, logger)
-	router := di.RouterProvider(fileUploadHandler, fileGetHandler, fileDeleteHandler)
+	fileScrubUseCase := di.FileScrubUseCaseProvider(fileRepository, fileStorageRepository, config)
+	fileScrubHandler := di.FileScrubHandlerProvider(fileScrubUseCase, logger)
+	fileFsckUseCase := di.FileFsckUseCaseProvider(fileRepository, fileStorageRepository, config)
+	fileFsckHandler := di.FileFsckHandlerProvider(fileFsckUseCase, logger)
+	fileTusHandler := di.FileTusHandlerProvider(fileUploadUseCase, config, logger)
+	fileS3Handler := di.FileS3HandlerProvider(fileUploadUseCase, fileGetUseCase, fileDeleteUseCase, config, logger)
+	router := di.RouterProvider(fileUploadHandler, fileGetHandler, fileDeleteHandler, fileScrubHandler, fileFsckHandler, fileTusHandler, fileS3Handler)
 	server := HTTPServerProvider(config, router)
+	scrubScheduler := di.ScrubSchedulerProvider(fileScrubUseCase, config, logger)
+	sessionSweepScheduler := di.SessionSweepSchedulerProvider(fileUploadUseCase, config, logger)
+	blobRepository := di.BlobRepositoryProvider(db)
+	fileGCUseCase := di.FileGCUseCaseProvider(blobRepository, fileStorageRepository, config)
+	blobGCScheduler := di.BlobGCSchedulerProvider(fileGCUseCase, config, logger)
 	application := &Application{
-		Config: config,
-		Logger: logger,
-		Router: router,
-		Server: server,
+		Config:                config,
+		Logger:                logger,
+		Router:                router,
+		Server:                server,
+		ScrubScheduler:        scrubScheduler,
+		SessionSweepScheduler: sessionSweepScheduler,
+		BlobGCScheduler:       blobGCScheduler,
 	}
 	return application, nil
 }
 
+// InitializeFsckCommand initializes the components for the fsck subcommand
+func InitializeFsckCommand() (*FsckCommand, error) {
+	config := di.ConfigProvider()
+	logger := di.LoggerProvider()
+	db, err := di.DBProvider(config, logger)
+	if err != nil {
+		return nil, err
+	}
+	fileRepository := di.FileRepositoryProvider(db)
+	fileStorageRepository := di.StorageRepositoryProvider(config, logger)
+	fileFsckUseCase := di.FileFsckUseCaseProvider(fileRepository, fileStorageRepository, config)
+	fsckCommand := &FsckCommand{
+		Logger:          logger,
+		FileFsckUseCase: fileFsckUseCase,
+	}
+	return fsckCommand, nil
+}
+
 // wire.go:
 
 // Application holds all the components needed for application
@@ -57,6 +91,12 @@ type Application struct {
 	Logger *slog.Logger
 	Router *router.Router
 	Server *http.Server
+	// Scrubber re-verifies the stored chunks in the background
+	ScrubScheduler *scheduler.ScrubScheduler
+	// Sweeper deletes the expired upload sessions in the background
+	SessionSweepScheduler *scheduler.SessionSweepScheduler
+	// Garbage collector deletes the unreferenced blobs in the background
+	BlobGCScheduler *scheduler.BlobGCScheduler
 }
 
 // HTTPServerProvider provides the configured HTTP server
@@ -82,6 +122,12 @@ func (app *Application) Run() error {
 		}
 	}()
 
+	scrubCtx, cancelScrub := context.WithCancel(context.Background())
+	defer cancelScrub()
+	go app.ScrubScheduler.Run(scrubCtx)
+	go app.SessionSweepScheduler.Run(scrubCtx)
+	go app.BlobGCScheduler.Run(scrubCtx)
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
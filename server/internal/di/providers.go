@@ -13,6 +13,7 @@ import (
 	"github.com/tomoya.tokunaga/server/internal/interface/api/router"
 	db_repo "github.com/tomoya.tokunaga/server/internal/interface/repository/database"
 	fs_repo "github.com/tomoya.tokunaga/server/internal/interface/repository/storage"
+	"github.com/tomoya.tokunaga/server/internal/interface/scheduler"
 	"github.com/tomoya.tokunaga/server/internal/usecase"
 )
 
@@ -42,6 +43,14 @@ func FileRepositoryProvider(db *gorm.DB) db_repo.FileRepository {
 	return db_repo.NewFileRepository(db)
 }
 
+func UploadSessionRepositoryProvider(db *gorm.DB) db_repo.UploadSessionRepository {
+	return db_repo.NewUploadSessionRepository(db)
+}
+
+func BlobRepositoryProvider(db *gorm.DB) db_repo.BlobRepository {
+	return db_repo.NewBlobRepository(db)
+}
+
 func StorageRepositoryProvider(config *entity.Config, logger *slog.Logger) fs_repo.FileStorageRepository {
 	return fs_repo.NewStorageRepository(config, logger)
 }
@@ -49,18 +58,35 @@ func StorageRepositoryProvider(config *entity.Config, logger *slog.Logger) fs_re
 // ----------------------------------------------------------------
 // Usecase Providers
 // ----------------------------------------------------------------
-func FileGetUseCaseProvider(fileRepo db_repo.FileRepository) usecase.FileGetUseCase {
-	return usecase.NewFileGetUseCase(fileRepo)
+func FileGetUseCaseProvider(fileRepo db_repo.FileRepository, storageRepo fs_repo.FileStorageRepository) usecase.FileGetUseCase {
+	return usecase.NewFileGetUseCase(fileRepo, storageRepo)
 }
 
-func FileUploadUseCaseProvider(fileRepo db_repo.FileRepository, storageRepo fs_repo.FileStorageRepository, config *entity.Config) usecase.FileUploadUseCase {
-	return usecase.NewFileUploadUseCase(fileRepo, storageRepo, config.BaseStorageDir)
+func FileUploadUseCaseProvider(
+	fileRepo db_repo.FileRepository,
+	sessionRepo db_repo.UploadSessionRepository,
+	storageRepo fs_repo.FileStorageRepository,
+	config *entity.Config,
+) usecase.FileUploadUseCase {
+	return usecase.NewFileUploadUseCase(fileRepo, sessionRepo, storageRepo, config.BaseStorageDir, config.UploadSessionTTL, config.StorageCodec)
 }
 
 func FileDeleteUseCaseProvider(fileRepo db_repo.FileRepository, storageRepo fs_repo.FileStorageRepository, config *entity.Config) usecase.FileDeleteUseCase {
 	return usecase.NewFileDeleteUseCase(config, fileRepo, storageRepo)
 }
 
+func FileScrubUseCaseProvider(fileRepo db_repo.FileRepository, storageRepo fs_repo.FileStorageRepository, config *entity.Config) usecase.FileScrubUseCase {
+	return usecase.NewFileScrubUseCase(config, fileRepo, storageRepo)
+}
+
+func FileFsckUseCaseProvider(fileRepo db_repo.FileRepository, storageRepo fs_repo.FileStorageRepository, config *entity.Config) usecase.FileFsckUseCase {
+	return usecase.NewFileFsckUseCase(config, fileRepo, storageRepo)
+}
+
+func FileGCUseCaseProvider(blobRepo db_repo.BlobRepository, storageRepo fs_repo.FileStorageRepository, config *entity.Config) usecase.FileGCUseCase {
+	return usecase.NewFileGCUseCase(config, blobRepo, storageRepo)
+}
+
 // ----------------------------------------------------------------
 // Handler Providers
 // ----------------------------------------------------------------
@@ -76,13 +102,48 @@ func FileDeleteHandlerProvider(fileDeleteUseCase usecase.FileDeleteUseCase, logg
 	return handler.NewFileDeleteHandler(fileDeleteUseCase, logger)
 }
 
+func FileScrubHandlerProvider(fileScrubUseCase usecase.FileScrubUseCase, logger *slog.Logger) *handler.FileScrubHandler {
+	return handler.NewFileScrubHandler(fileScrubUseCase, logger)
+}
+
+func FileFsckHandlerProvider(fileFsckUseCase usecase.FileFsckUseCase, logger *slog.Logger) *handler.FileFsckHandler {
+	return handler.NewFileFsckHandler(fileFsckUseCase, logger)
+}
+
+func FileTusHandlerProvider(fileUploadUseCase usecase.FileUploadUseCase, config *entity.Config, logger *slog.Logger) *handler.FileTusHandler {
+	return handler.NewFileTusHandler(fileUploadUseCase, config, logger)
+}
+
+func FileS3HandlerProvider(fileUploadUseCase usecase.FileUploadUseCase, fileGetUseCase usecase.FileGetUseCase, fileDeleteUseCase usecase.FileDeleteUseCase, config *entity.Config, logger *slog.Logger) *handler.FileS3Handler {
+	return handler.NewFileS3Handler(fileUploadUseCase, fileGetUseCase, fileDeleteUseCase, config, logger)
+}
+
+// ----------------------------------------------------------------
+// Scheduler Providers
+// ----------------------------------------------------------------
+func ScrubSchedulerProvider(fileScrubUseCase usecase.FileScrubUseCase, config *entity.Config, logger *slog.Logger) *scheduler.ScrubScheduler {
+	return scheduler.NewScrubScheduler(fileScrubUseCase, config, logger)
+}
+
+func SessionSweepSchedulerProvider(fileUploadUseCase usecase.FileUploadUseCase, config *entity.Config, logger *slog.Logger) *scheduler.SessionSweepScheduler {
+	return scheduler.NewSessionSweepScheduler(fileUploadUseCase, config, logger)
+}
+
+func BlobGCSchedulerProvider(fileGCUseCase usecase.FileGCUseCase, config *entity.Config, logger *slog.Logger) *scheduler.BlobGCScheduler {
+	return scheduler.NewBlobGCScheduler(fileGCUseCase, config, logger)
+}
+
 // RouterProvider provides the router implementation
 func RouterProvider(
 	fileUploadHandler *handler.FileUploadHandler,
 	fileGetHandler *handler.FileGetHandler,
 	fileDeleteHandler *handler.FileDeleteHandler,
+	fileScrubHandler *handler.FileScrubHandler,
+	fileFsckHandler *handler.FileFsckHandler,
+	fileTusHandler *handler.FileTusHandler,
+	fileS3Handler *handler.FileS3Handler,
 ) *router.Router {
-	r := router.NewRouter(fileUploadHandler, fileGetHandler, fileDeleteHandler)
+	r := router.NewRouter(fileUploadHandler, fileGetHandler, fileDeleteHandler, fileScrubHandler, fileFsckHandler, fileTusHandler, fileS3Handler)
 	r.SetupRoutes()
 	return r
 }
@@ -94,15 +155,40 @@ var ServerProvider = wire.NewSet(
 	DBProvider,
 	// Repository
 	FileRepositoryProvider,
+	UploadSessionRepositoryProvider,
+	BlobRepositoryProvider,
 	StorageRepositoryProvider,
 	// Usecase
 	FileGetUseCaseProvider,
 	FileUploadUseCaseProvider,
 	FileDeleteUseCaseProvider,
+	FileScrubUseCaseProvider,
+	FileFsckUseCaseProvider,
+	FileGCUseCaseProvider,
 	// Handler
 	FileUploadHandlerProvider,
 	FileGetHandlerProvider,
 	FileDeleteHandlerProvider,
+	FileScrubHandlerProvider,
+	FileFsckHandlerProvider,
+	FileTusHandlerProvider,
+	FileS3HandlerProvider,
+	// Scheduler
+	ScrubSchedulerProvider,
+	SessionSweepSchedulerProvider,
+	BlobGCSchedulerProvider,
 	// Router
 	RouterProvider,
 )
+
+// FsckProvider provides sets for the fsck subcommand, which only needs the database and the storage
+var FsckProvider = wire.NewSet(
+	ConfigProvider,
+	LoggerProvider,
+	DBProvider,
+	// Repository
+	FileRepositoryProvider,
+	StorageRepositoryProvider,
+	// Usecase
+	FileFsckUseCaseProvider,
+)
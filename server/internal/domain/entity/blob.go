@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// Blob represents the stored content shared by the chunks of the same content. A blob is identified by the checksum
+// of its content and the codec it is stored in, and is deleted once no chunk refers to it anymore
+type Blob struct {
+	ID           uint64       `json:"id"`
+	Checksum     string       `json:"checksum"` // SHA-256 of the uncompressed content
+	StorageCodec StorageCodec `json:"storage_codec"`
+	Size         uint64       `json:"size"` // size of the uncompressed content
+	FilePath     string       `json:"file_path"`
+	RefCount     uint64       `json:"ref_count"` // the number of chunks referring to the blob
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// BlobGCRun represents the result of a single garbage collection of the blobs which no chunk refers to
+type BlobGCRun struct {
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	RecountedBlobs int64     `json:"recounted_blobs"` // blobs whose reference count drifted and was corrected
+	DeletedBlobs   uint64    `json:"deleted_blobs"`   // unreferenced blobs deleted along with their files
+	DeletedStrays  uint64    `json:"deleted_strays"`  // files in the object area which no blob refers to
+	ReclaimedBytes uint64    `json:"reclaimed_bytes"` // uncompressed size of the deleted blobs
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,6 +14,23 @@ const (
 	DefaultUploadTimeoutSecond = 5
 	DefaultWorkerPoolSize      = 5
 	DefaultStreamBufferSize    = 1024 * 1024 // 1MB
+	DefaultScrubIntervalMinute = 24 * 60     // once a day
+	DefaultStorageDurability   = DurabilityFull
+	DefaultStorageCodec        = StorageCodecNone
+	DefaultMaxFileSize         = 1024 * 1024 * 1024 * 1024 // 1TiB
+	DefaultMaxChunkSize        = 64 * 1024 * 1024          // 64MiB
+	DefaultMaxChunkCount       = 100000
+	DefaultTusChunkSize        = 8 * 1024 * 1024 // 8MiB
+	DefaultS3Bucket            = "fs-store"
+	DefaultS3Region            = "us-east-1"
+
+	DefaultResponseCompressionLevel = 0 // the default level of each encoding
+
+	DefaultUploadSessionTTLMinute     = 24 * 60 // a day since the last upload to the session
+	DefaultSessionSweepIntervalMinute = 60
+
+	DefaultBlobGCIntervalMinute = 60
+	DefaultBlobGCGraceMinute    = 60
 
 	DefaultDBHost               = "localhost"
 	DefaultDBPort               = 3306
@@ -25,6 +43,15 @@ const (
 	DefaultDBConnMaxLifetimeMin = 60
 )
 
+// Durability represents how far a written chunk is flushed to the disk before the write is acknowledged
+type Durability string
+
+const (
+	DurabilityNone Durability = "none" // leaves the flush to the OS, so acknowledged chunks can be lost on power loss
+	DurabilityFile Durability = "file" // fsyncs the chunk file before renaming it into place
+	DurabilityFull Durability = "full" // also fsyncs the directory, so that the rename itself survives a crash
+)
+
 // Config represents the application configuration loaded from environment variables
 type Config struct {
 	// Server config
@@ -34,6 +61,29 @@ type Config struct {
 	BaseStorageDir      string
 	StreamBufferSize    int
 	UploadTimeoutSecond time.Duration
+	StorageDurability   Durability
+	// compression of the chunks of the files which don't choose theirs, which is recorded per file so that changing it
+	// doesn't affect the files already stored
+	StorageCodec StorageCodec
+
+	// Upload limits config. A zero limit means no limit
+	MaxFileSize   uint64
+	MaxChunkSize  uint64
+	MaxChunkCount uint64
+
+	// tus config. The size of the chunks which the files uploaded through the tus protocol are stored in
+	TusChunkSize uint64
+
+	// S3 config. The S3-compatible API serves the files as the objects of a single bucket, and accepts the requests
+	// signed with one of the credentials (access key ID to secret access key). No request is accepted without them
+	S3Bucket      string
+	S3Region      string
+	S3Credentials map[string]string
+
+	// Upload session config. A session expires when nothing is uploaded to it for the TTL, and the expired sessions
+	// are deleted along with their uncommitted files by the sweeper, which is disabled when the interval is zero
+	UploadSessionTTL     time.Duration
+	SessionSweepInterval time.Duration
 
 	// Database config
 	DBHost            string
@@ -48,6 +98,19 @@ type Config struct {
 
 	// Worker pool config
 	WorkerPoolSize int
+
+	// Compression config. The level of the gzip (1-9) or zstd (1-22) encoding of the response bodies, where 0 lets
+	// each encoding choose its default. The response bodies are encoded only when the client accepts it
+	ResponseCompressionLevel int
+
+	// Scrub config. The scrubber is disabled when the interval is zero
+	ScrubInterval time.Duration
+
+	// Blob GC config. The garbage collector deletes the blobs which no chunk refers to, and the files in the object
+	// area which no blob refers to once they are older than the grace period, which leaves the files of the chunks
+	// being stored alone. The garbage collector is disabled when the interval is zero
+	BlobGCInterval    time.Duration
+	BlobGCGracePeriod time.Duration
 }
 
 // NewConfig loads configuration from environment variables
@@ -60,6 +123,25 @@ func NewConfig() *Config {
 		BaseStorageDir:      GetEnv("BASE_STORAGE_DIR", DefaultBaseStorageDir),
 		StreamBufferSize:    GetEnvInt("STREAM_BUFFER_SIZE", DefaultStreamBufferSize),
 		UploadTimeoutSecond: time.Duration(GetEnvInt("UPLOAD_TIMEOUT_SECOND", DefaultUploadTimeoutSecond)) * time.Second,
+		StorageDurability:   GetEnvDurability("STORAGE_DURABILITY", DefaultStorageDurability),
+		StorageCodec:        GetEnvStorageCodec("STORAGE_CODEC", DefaultStorageCodec),
+
+		// Upload limits config
+		MaxFileSize:   GetEnvUint64("MAX_FILE_SIZE", DefaultMaxFileSize),
+		MaxChunkSize:  GetEnvUint64("MAX_CHUNK_SIZE", DefaultMaxChunkSize),
+		MaxChunkCount: GetEnvUint64("MAX_CHUNK_COUNT", DefaultMaxChunkCount),
+
+		// tus config
+		TusChunkSize: GetEnvUint64("TUS_CHUNK_SIZE", DefaultTusChunkSize),
+
+		// S3 config
+		S3Bucket:      GetEnv("S3_BUCKET", DefaultS3Bucket),
+		S3Region:      GetEnv("S3_REGION", DefaultS3Region),
+		S3Credentials: GetEnvCredentials("S3_CREDENTIALS"),
+
+		// Upload session config
+		UploadSessionTTL:     time.Duration(GetEnvInt("UPLOAD_SESSION_TTL_MINUTE", DefaultUploadSessionTTLMinute)) * time.Minute,
+		SessionSweepInterval: time.Duration(GetEnvInt("SESSION_SWEEP_INTERVAL_MINUTE", DefaultSessionSweepIntervalMinute)) * time.Minute,
 
 		// Database config
 		DBHost:            GetEnv("DB_HOST", DefaultDBHost),
@@ -74,6 +156,25 @@ func NewConfig() *Config {
 
 		// Worker pool config
 		WorkerPoolSize: GetEnvInt("WORKER_POOL_SIZE", DefaultWorkerPoolSize),
+
+		// Compression config
+		ResponseCompressionLevel: GetEnvInt("RESPONSE_COMPRESSION_LEVEL", DefaultResponseCompressionLevel),
+
+		// Scrub config
+		ScrubInterval: time.Duration(GetEnvInt("SCRUB_INTERVAL_MINUTE", DefaultScrubIntervalMinute)) * time.Minute,
+
+		// Blob GC config
+		BlobGCInterval:    time.Duration(GetEnvInt("BLOB_GC_INTERVAL_MINUTE", DefaultBlobGCIntervalMinute)) * time.Minute,
+		BlobGCGracePeriod: time.Duration(GetEnvInt("BLOB_GC_GRACE_MINUTE", DefaultBlobGCGraceMinute)) * time.Minute,
+	}
+}
+
+// UploadLimits returns the limits of a file upload
+func (c *Config) UploadLimits() UploadLimits {
+	return UploadLimits{
+		MaxFileSize:   c.MaxFileSize,
+		MaxChunkSize:  c.MaxChunkSize,
+		MaxChunkCount: c.MaxChunkCount,
 	}
 }
 
@@ -117,3 +218,54 @@ func GetEnvUint64(key string, defaultValue uint64) uint64 {
 
 	return uint64Value
 }
+
+// GetEnvDurability gets an environment variable as a durability level or returns a default value
+func GetEnvDurability(key string, defaultValue Durability) Durability {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	switch durability := Durability(value); durability {
+	case DurabilityNone, DurabilityFile, DurabilityFull:
+		return durability
+	default:
+		log.Printf("invalid value for %s: %s, using default: %s", key, value, defaultValue)
+		return defaultValue
+	}
+}
+
+// GetEnvStorageCodec gets an environment variable as a storage codec or returns a default value
+func GetEnvStorageCodec(key string, defaultValue StorageCodec) StorageCodec {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	if codec := StorageCodec(value); codec.IsValid() {
+		return codec
+	}
+	log.Printf("invalid value for %s: %s, using default: %s", key, value, defaultValue)
+	return defaultValue
+}
+
+// GetEnvCredentials gets an environment variable as comma-separated credentials (e.g. "key1:secret1,key2:secret2")
+// which map an access key ID to its secret access key. Malformed credentials are skipped
+func GetEnvCredentials(key string) map[string]string {
+	credentials := make(map[string]string)
+	value := os.Getenv(key)
+	if value == "" {
+		return credentials
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		accessKeyID, secretAccessKey, found := strings.Cut(strings.TrimSpace(pair), ":")
+		if !found || accessKeyID == "" || secretAccessKey == "" {
+			log.Printf("invalid credential for %s, skipping it", key)
+			continue
+		}
+		credentials[accessKeyID] = secretAccessKey
+	}
+
+	return credentials
+}
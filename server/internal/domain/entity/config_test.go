@@ -3,6 +3,7 @@ package entity
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestGetEnv(t *testing.T) {
@@ -112,12 +113,122 @@ func TestGetEnvUint64(t *testing.T) {
 	}
 }
 
+func TestGetEnvDurability(t *testing.T) {
+	// Test default value when env var not set
+	err := os.Unsetenv("TEST_ENV_DURABILITY")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	value := GetEnvDurability("TEST_ENV_DURABILITY", DurabilityFull)
+	if value != DurabilityFull {
+		t.Errorf("expected default value %s, got %s", DurabilityFull, value)
+	}
+
+	// Test env var value when set
+	err = os.Setenv("TEST_ENV_DURABILITY", "none")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	defer func() {
+		err := os.Unsetenv("TEST_ENV_DURABILITY")
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	}()
+
+	value = GetEnvDurability("TEST_ENV_DURABILITY", DurabilityFull)
+	if value != DurabilityNone {
+		t.Errorf("expected custom value %s, got %s", DurabilityNone, value)
+	}
+
+	// Test invalid durability value
+	err = os.Setenv("TEST_ENV_DURABILITY", "invalid")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	value = GetEnvDurability("TEST_ENV_DURABILITY", DurabilityFull)
+	if value != DurabilityFull {
+		t.Errorf("expected default value %s for invalid input, got %s", DurabilityFull, value)
+	}
+}
+
+func TestGetEnvStorageCodec(t *testing.T) {
+	// Test default value when env var not set
+	err := os.Unsetenv("TEST_ENV_STORAGE_CODEC")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	value := GetEnvStorageCodec("TEST_ENV_STORAGE_CODEC", StorageCodecNone)
+	if value != StorageCodecNone {
+		t.Errorf("expected default value %s, got %s", StorageCodecNone, value)
+	}
+
+	// Test env var value when set
+	err = os.Setenv("TEST_ENV_STORAGE_CODEC", "zstd")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	defer func() {
+		err := os.Unsetenv("TEST_ENV_STORAGE_CODEC")
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	}()
+
+	value = GetEnvStorageCodec("TEST_ENV_STORAGE_CODEC", StorageCodecNone)
+	if value != StorageCodecZstd {
+		t.Errorf("expected custom value %s, got %s", StorageCodecZstd, value)
+	}
+
+	// Test invalid codec value
+	err = os.Setenv("TEST_ENV_STORAGE_CODEC", "brotli")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	value = GetEnvStorageCodec("TEST_ENV_STORAGE_CODEC", StorageCodecNone)
+	if value != StorageCodecNone {
+		t.Errorf("expected default value %s for invalid input, got %s", StorageCodecNone, value)
+	}
+}
+
+func TestGetEnvCredentials(t *testing.T) {
+	// Test no credentials when env var not set
+	err := os.Unsetenv("TEST_ENV_CREDENTIALS")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	value := GetEnvCredentials("TEST_ENV_CREDENTIALS")
+	if len(value) != 0 {
+		t.Errorf("expected no credentials, got %v", value)
+	}
+
+	// Test env var value when set, where the malformed credentials are skipped
+	err = os.Setenv("TEST_ENV_CREDENTIALS", "key1:secret1, key2:sec:ret2,key3,:secret4,key5:")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	defer func() {
+		err := os.Unsetenv("TEST_ENV_CREDENTIALS")
+		if err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	}()
+
+	value = GetEnvCredentials("TEST_ENV_CREDENTIALS")
+	if len(value) != 2 || value["key1"] != "secret1" || value["key2"] != "sec:ret2" {
+		t.Errorf("expected credentials of key1 and key2, got %v", value)
+	}
+}
+
 func TestNewConfig(t *testing.T) {
 	// Clear environment variables to test defaults
 	envVars := []string{
 		"PORT", "BASE_STORAGE_DIR", "UPLOAD_SIZE_LIMIT", "UPLOAD_TIMEOUT",
 		"DB_HOST", "DB_PORT", "DB_USER", "DB_PASSWORD", "DB_NAME",
-		"DB_CONN_TIMEOUT", "WORKER_POOL_SIZE",
+		"DB_CONN_TIMEOUT", "WORKER_POOL_SIZE", "SCRUB_INTERVAL_MINUTE", "STORAGE_DURABILITY",
+		"MAX_FILE_SIZE", "MAX_CHUNK_SIZE", "MAX_CHUNK_COUNT", "TUS_CHUNK_SIZE",
+		"S3_BUCKET", "S3_REGION", "S3_CREDENTIALS", "UPLOAD_SESSION_TTL_MINUTE", "SESSION_SWEEP_INTERVAL_MINUTE",
+		"BLOB_GC_INTERVAL_MINUTE", "BLOB_GC_GRACE_MINUTE",
 	}
 
 	for _, env := range envVars {
@@ -141,6 +252,42 @@ func TestNewConfig(t *testing.T) {
 		t.Errorf("expected default worker pool size %d, got %d", DefaultWorkerPoolSize, config.WorkerPoolSize)
 	}
 
+	if config.ScrubInterval != DefaultScrubIntervalMinute*time.Minute {
+		t.Errorf("expected default scrub interval %d minutes, got %v", DefaultScrubIntervalMinute, config.ScrubInterval)
+	}
+
+	if config.StorageDurability != DefaultStorageDurability {
+		t.Errorf("expected default storage durability %s, got %s", DefaultStorageDurability, config.StorageDurability)
+	}
+
+	if limits := config.UploadLimits(); limits != (UploadLimits{MaxFileSize: DefaultMaxFileSize, MaxChunkSize: DefaultMaxChunkSize, MaxChunkCount: DefaultMaxChunkCount}) {
+		t.Errorf("expected default upload limits, got %+v", limits)
+	}
+
+	if config.TusChunkSize != DefaultTusChunkSize {
+		t.Errorf("expected default tus chunk size %d, got %d", DefaultTusChunkSize, config.TusChunkSize)
+	}
+
+	if config.S3Bucket != DefaultS3Bucket || config.S3Region != DefaultS3Region || len(config.S3Credentials) != 0 {
+		t.Errorf("expected default S3 config, got %s, %s, %v", config.S3Bucket, config.S3Region, config.S3Credentials)
+	}
+
+	if config.UploadSessionTTL != DefaultUploadSessionTTLMinute*time.Minute {
+		t.Errorf("expected default upload session TTL %d minutes, got %v", DefaultUploadSessionTTLMinute, config.UploadSessionTTL)
+	}
+
+	if config.SessionSweepInterval != DefaultSessionSweepIntervalMinute*time.Minute {
+		t.Errorf("expected default session sweep interval %d minutes, got %v", DefaultSessionSweepIntervalMinute, config.SessionSweepInterval)
+	}
+
+	if config.BlobGCInterval != DefaultBlobGCIntervalMinute*time.Minute {
+		t.Errorf("expected default blob GC interval %d minutes, got %v", DefaultBlobGCIntervalMinute, config.BlobGCInterval)
+	}
+
+	if config.BlobGCGracePeriod != DefaultBlobGCGraceMinute*time.Minute {
+		t.Errorf("expected default blob GC grace period %d minutes, got %v", DefaultBlobGCGraceMinute, config.BlobGCGracePeriod)
+	}
+
 	// Test with custom values
 	err := os.Setenv("PORT", "9090")
 	if err != nil {
@@ -186,6 +333,58 @@ func TestNewConfig(t *testing.T) {
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
 	}
+	err = os.Setenv("SCRUB_INTERVAL_MINUTE", "0")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("STORAGE_DURABILITY", "file")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("MAX_FILE_SIZE", "0")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("MAX_CHUNK_SIZE", "1048576")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("MAX_CHUNK_COUNT", "1000")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("TUS_CHUNK_SIZE", "65536")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("S3_BUCKET", "test-bucket")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("S3_REGION", "ap-northeast-1")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("S3_CREDENTIALS", "testkey:testsecret")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("UPLOAD_SESSION_TTL_MINUTE", "30")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("SESSION_SWEEP_INTERVAL_MINUTE", "0")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("BLOB_GC_INTERVAL_MINUTE", "0")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+	err = os.Setenv("BLOB_GC_GRACE_MINUTE", "15")
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
 
 	// Cleanup after test
 	defer func() {
@@ -234,4 +433,40 @@ func TestNewConfig(t *testing.T) {
 	if config.WorkerPoolSize != 10 {
 		t.Errorf("expected custom worker pool size 10, got %d", config.WorkerPoolSize)
 	}
+
+	if config.ScrubInterval != 0 {
+		t.Errorf("expected disabled scrub interval 0, got %v", config.ScrubInterval)
+	}
+
+	if config.StorageDurability != DurabilityFile {
+		t.Errorf("expected custom storage durability %s, got %s", DurabilityFile, config.StorageDurability)
+	}
+
+	if limits := config.UploadLimits(); limits != (UploadLimits{MaxFileSize: 0, MaxChunkSize: 1048576, MaxChunkCount: 1000}) {
+		t.Errorf("expected custom upload limits, got %+v", limits)
+	}
+
+	if config.TusChunkSize != 65536 {
+		t.Errorf("expected custom tus chunk size 65536, got %d", config.TusChunkSize)
+	}
+
+	if config.S3Bucket != "test-bucket" || config.S3Region != "ap-northeast-1" || config.S3Credentials["testkey"] != "testsecret" {
+		t.Errorf("expected custom S3 config, got %s, %s, %v", config.S3Bucket, config.S3Region, config.S3Credentials)
+	}
+
+	if config.UploadSessionTTL != 30*time.Minute {
+		t.Errorf("expected custom upload session TTL 30m, got %v", config.UploadSessionTTL)
+	}
+
+	if config.SessionSweepInterval != 0 {
+		t.Errorf("expected disabled session sweep interval 0, got %v", config.SessionSweepInterval)
+	}
+
+	if config.BlobGCInterval != 0 {
+		t.Errorf("expected disabled blob GC interval 0, got %v", config.BlobGCInterval)
+	}
+
+	if config.BlobGCGracePeriod != 15*time.Minute {
+		t.Errorf("expected custom blob GC grace period 15m, got %v", config.BlobGCGracePeriod)
+	}
 }
@@ -0,0 +1,34 @@
+package error
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type ChecksumMismatchError struct {
+	err    error
+	errMsg string
+}
+
+func NewChecksumMismatchError(err error, errMsg string) *ChecksumMismatchError {
+	return &ChecksumMismatchError{
+		err:    err,
+		errMsg: errMsg,
+	}
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s: %v", e.errMsg, e.err)
+}
+
+func (e *ChecksumMismatchError) ErrorObject() error {
+	return fmt.Errorf("%s: %w", e.errMsg, e.err)
+}
+
+func (e *ChecksumMismatchError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+func (e *ChecksumMismatchError) ErrorCode() string {
+	return "CHECKSUM_MISMATCH"
+}
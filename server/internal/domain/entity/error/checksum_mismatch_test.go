@@ -0,0 +1,66 @@
+package error_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+)
+
+func TestChecksumMismatchError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		err            error
+		errMsg         string
+		expectedError  string
+		expectedCode   string
+		expectedStatus int
+	}{
+		{
+			name:           "Chunk checksum mismatch",
+			err:            errors.New("expected abc, but got def"),
+			errMsg:         "chunk 3 of test.txt is corrupted",
+			expectedError:  "chunk 3 of test.txt is corrupted: expected abc, but got def",
+			expectedCode:   "CHECKSUM_MISMATCH",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Nil error",
+			err:            nil,
+			errMsg:         "Checksum mismatch",
+			expectedError:  "Checksum mismatch: <nil>",
+			expectedCode:   "CHECKSUM_MISMATCH",
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			checksumErr := e.NewChecksumMismatchError(tc.err, tc.errMsg)
+
+			// Test Error() method
+			assert.Equal(t, tc.expectedError, checksumErr.Error())
+
+			// Test ErrorObject() method
+			errorObj := checksumErr.ErrorObject()
+			assert.NotNil(t, errorObj)
+
+			// For nil error case, ErrorObject() formats differently than Error()
+			if tc.err == nil {
+				assert.Contains(t, errorObj.Error(), tc.errMsg)
+			} else {
+				assert.Equal(t, tc.expectedError, errorObj.Error())
+			}
+
+			// Test StatusCode() method
+			assert.Equal(t, tc.expectedStatus, checksumErr.StatusCode())
+
+			// Test ErrorCode() method
+			assert.Equal(t, tc.expectedCode, checksumErr.ErrorCode())
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package error
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type ConflictError struct {
+	err    error
+	errMsg string
+}
+
+func NewConflictError(err error, errMsg string) *ConflictError {
+	return &ConflictError{
+		err:    err,
+		errMsg: errMsg,
+	}
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: %v", e.errMsg, e.err)
+}
+
+func (e *ConflictError) ErrorObject() error {
+	return fmt.Errorf("%s: %w", e.errMsg, e.err)
+}
+
+func (e *ConflictError) StatusCode() int {
+	return http.StatusConflict
+}
+
+func (e *ConflictError) ErrorCode() string {
+	return "CONFLICT"
+}
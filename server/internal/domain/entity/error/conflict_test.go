@@ -0,0 +1,66 @@
+package error_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+)
+
+func TestConflictError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		err            error
+		errMsg         string
+		expectedError  string
+		expectedCode   string
+		expectedStatus int
+	}{
+		{
+			name:           "Offset mismatch",
+			err:            errors.New("upload offset is 1024, but got 512"),
+			errMsg:         "invalid offset",
+			expectedError:  "invalid offset: upload offset is 1024, but got 512",
+			expectedCode:   "CONFLICT",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Nil error",
+			err:            nil,
+			errMsg:         "Conflict",
+			expectedError:  "Conflict: <nil>",
+			expectedCode:   "CONFLICT",
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conflictErr := e.NewConflictError(tc.err, tc.errMsg)
+
+			// Test Error() method
+			assert.Equal(t, tc.expectedError, conflictErr.Error())
+
+			// Test ErrorObject() method
+			errorObj := conflictErr.ErrorObject()
+			assert.NotNil(t, errorObj)
+
+			// For nil error case, ErrorObject() formats differently than Error()
+			if tc.err == nil {
+				assert.Contains(t, errorObj.Error(), tc.errMsg)
+			} else {
+				assert.Equal(t, tc.expectedError, errorObj.Error())
+			}
+
+			// Test StatusCode() method
+			assert.Equal(t, tc.expectedStatus, conflictErr.StatusCode())
+
+			// Test ErrorCode() method
+			assert.Equal(t, tc.expectedCode, conflictErr.ErrorCode())
+		})
+	}
+}
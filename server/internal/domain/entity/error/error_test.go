@@ -84,6 +84,8 @@ func TestCustomErrorInterface(t *testing.T) {
 	fsErr := e.NewFileStorageError(errors.New("test"), "storage")
 	invalidErr := e.NewInvalidInputError(errors.New("test"), "invalid")
 	notFoundErr := e.NewNotFoundError(errors.New("test"), "notfound")
+	rangeErr := e.NewRangeNotSatisfiableError(errors.New("test"), "range")
+	checksumErr := e.NewChecksumMismatchError(errors.New("test"), "checksum")
 
 	var customErrors = []e.CustomError{
 		mockErr,
@@ -92,6 +94,8 @@ func TestCustomErrorInterface(t *testing.T) {
 		fsErr,
 		invalidErr,
 		notFoundErr,
+		rangeErr,
+		checksumErr,
 	}
 
 	for i, err := range customErrors {
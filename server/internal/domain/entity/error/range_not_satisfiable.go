@@ -0,0 +1,34 @@
+package error
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type RangeNotSatisfiableError struct {
+	err    error
+	errMsg string
+}
+
+func NewRangeNotSatisfiableError(err error, errMsg string) *RangeNotSatisfiableError {
+	return &RangeNotSatisfiableError{
+		err:    err,
+		errMsg: errMsg,
+	}
+}
+
+func (e *RangeNotSatisfiableError) Error() string {
+	return fmt.Sprintf("%s: %v", e.errMsg, e.err)
+}
+
+func (e *RangeNotSatisfiableError) ErrorObject() error {
+	return fmt.Errorf("%s: %w", e.errMsg, e.err)
+}
+
+func (e *RangeNotSatisfiableError) StatusCode() int {
+	return http.StatusRequestedRangeNotSatisfiable
+}
+
+func (e *RangeNotSatisfiableError) ErrorCode() string {
+	return "RANGE_NOT_SATISFIABLE"
+}
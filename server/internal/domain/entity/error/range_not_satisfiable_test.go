@@ -0,0 +1,74 @@
+package error_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+)
+
+func TestRangeNotSatisfiableError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		err            error
+		errMsg         string
+		expectedError  string
+		expectedCode   string
+		expectedStatus int
+	}{
+		{
+			name:           "Range beyond content",
+			err:            errors.New("invalid range: failed to overlap"),
+			errMsg:         "bytes=2048-",
+			expectedError:  "bytes=2048-: invalid range: failed to overlap",
+			expectedCode:   "RANGE_NOT_SATISFIABLE",
+			expectedStatus: http.StatusRequestedRangeNotSatisfiable,
+		},
+		{
+			name:           "Malformed range",
+			err:            errors.New("invalid range"),
+			errMsg:         "bytes=abc",
+			expectedError:  "bytes=abc: invalid range",
+			expectedCode:   "RANGE_NOT_SATISFIABLE",
+			expectedStatus: http.StatusRequestedRangeNotSatisfiable,
+		},
+		{
+			name:           "Nil error",
+			err:            nil,
+			errMsg:         "Range not satisfiable",
+			expectedError:  "Range not satisfiable: <nil>",
+			expectedCode:   "RANGE_NOT_SATISFIABLE",
+			expectedStatus: http.StatusRequestedRangeNotSatisfiable,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rangeErr := e.NewRangeNotSatisfiableError(tc.err, tc.errMsg)
+
+			// Test Error() method
+			assert.Equal(t, tc.expectedError, rangeErr.Error())
+
+			// Test ErrorObject() method
+			errorObj := rangeErr.ErrorObject()
+			assert.NotNil(t, errorObj)
+
+			// For nil error case, ErrorObject() formats differently than Error()
+			if tc.err == nil {
+				assert.Contains(t, errorObj.Error(), tc.errMsg)
+			} else {
+				assert.Equal(t, tc.expectedError, errorObj.Error())
+			}
+
+			// Test StatusCode() method
+			assert.Equal(t, tc.expectedStatus, rangeErr.StatusCode())
+
+			// Test ErrorCode() method
+			assert.Equal(t, tc.expectedCode, rangeErr.ErrorCode())
+		})
+	}
+}
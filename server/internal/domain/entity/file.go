@@ -9,32 +9,89 @@ const (
 	FileStatusInProgress  FileStatus = "IN_PROGRESS"
 	FileStatusFailed      FileStatus = "FAILED"
 	FileStatusUploaded    FileStatus = "UPLOADED"
+	// all chunks are uploaded and the checksum of the stored content is being verified
+	FileStatusVerifying FileStatus = "VERIFYING"
+	// the checksum of the stored content doesn't match the one declared at the initialization
+	FileStatusCorrupt FileStatus = "CORRUPT"
+	// the stored content of an uploaded file or chunk is found missing or modified by the scrubber
+	FileStatusDamaged FileStatus = "DAMAGED"
 )
 
+// StorageCodec represents the compression the chunks of a file are stored in. The content read from the chunks is
+// always the uncompressed one, so the sizes and the checksums of the files and the chunks are of the uncompressed content
+type StorageCodec string
+
+const (
+	StorageCodecNone StorageCodec = "none"
+	StorageCodecGzip StorageCodec = "gzip"
+	StorageCodecZstd StorageCodec = "zstd"
+)
+
+// IsValid reports whether the codec is a supported one
+func (c StorageCodec) IsValid() bool {
+	switch c {
+	case StorageCodecNone, StorageCodecGzip, StorageCodecZstd:
+		return true
+	}
+	return false
+}
+
 // File represents a file in the file system
 type File struct {
-	ID             uint64     `json:"id"`
-	Name           string     `json:"name"`
-	Size           uint64     `json:"size"`
-	Checksum       string     `json:"checksum"`
-	ChunkSize      uint64     `json:"chunk_size"`
-	Status         FileStatus `json:"status"`
-	TotalChunks    uint       `json:"total_chunks"`
-	UploadedChunks uint       `json:"uploaded_chunks"`
-	CreatedAt      time.Time  `json:"created_at"`
-	UpdatedAt      time.Time  `json:"updated_at"`
-	FileChunks     []FileChunk
+	ID                uint64       `json:"id"`
+	Name              string       `json:"name"`
+	Size              uint64       `json:"size"`
+	Checksum          string       `json:"checksum"`
+	ChecksumAlgorithm string       `json:"checksum_algorithm"` // hash algorithm of the checksum, e.g. "sha256" or "xxhash64"
+	MerkleRoot        string       `json:"merkle_root"`        // root of the Merkle tree over the chunk checksums, which is set once the file is uploaded
+	ChunkSize         uint64       `json:"chunk_size"`         // size of every chunk but the last one, or the largest chunk size when the chunks have variable sizes
+	VariableChunks    bool         `json:"variable_chunks"`    // the chunks have their own sizes (e.g. content-defined chunks), which are recorded with their offsets
+	StorageCodec      StorageCodec `json:"storage_codec"`      // compression of the stored chunks, which is chosen when the file is created
+	Status            FileStatus   `json:"status"`
+	TotalChunks       uint         `json:"total_chunks"`
+	UploadedChunks    uint         `json:"uploaded_chunks"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+	FileChunks        []FileChunk
 }
 
 // NewFile creates a new File entity
-func NewFile(name string, size uint64, checksum string, totalChunks uint, chunkSize uint64) *File {
+func NewFile(name string, size uint64, checksum string, checksumAlgorithm string, totalChunks uint, chunkSize uint64) *File {
 	return &File{
-		Name:           name,
-		Size:           size,
-		Checksum:       checksum,
-		ChunkSize:      chunkSize,
-		Status:         FileStatusInitialized,
-		TotalChunks:    totalChunks,
-		UploadedChunks: 0,
+		Name:              name,
+		Size:              size,
+		Checksum:          checksum,
+		ChecksumAlgorithm: checksumAlgorithm,
+		ChunkSize:         chunkSize,
+		Status:            FileStatusInitialized,
+		TotalChunks:       totalChunks,
+		UploadedChunks:    0,
+	}
+}
+
+// SetChunkSizes lays out the chunks of the file by their sizes in order, each of which starts where the previous one
+// ends. The chunks are the ones the records of the file are created from
+func (f *File) SetChunkSizes(chunkSizes []uint64) {
+	f.VariableChunks = true
+	f.TotalChunks = uint(len(chunkSizes))
+	f.FileChunks = make([]FileChunk, len(chunkSizes))
+	var offset uint64
+	for i, size := range chunkSizes {
+		f.FileChunks[i] = FileChunk{ChunkNumber: uint64(i), Offset: offset, Size: size}
+		offset += size
+	}
+}
+
+// ChunkRange returns the byte offset and the size of the chunk in the file. A chunk of a file with variable chunk sizes
+// has the recorded ones. Otherwise, every chunk except the last one has the fixed chunk size, and the last one has the
+// rest of the file
+func (f *File) ChunkRange(chunk *FileChunk) (uint64, uint64) {
+	if f.VariableChunks {
+		return chunk.Offset, chunk.Size
+	}
+	offset := chunk.ChunkNumber * f.ChunkSize
+	if offset >= f.Size {
+		return offset, 0
 	}
+	return offset, min(f.ChunkSize, f.Size-offset)
 }
@@ -10,8 +10,12 @@ type FileChunk struct {
 	ParentID    uint64     `json:"parent_id"`
 	Status      FileStatus `json:"status"`
 	ChunkNumber uint64     `json:"chunk_number"`
+	Offset      uint64     `json:"offset"` // byte offset of the chunk in the file, which is recorded when the chunks have variable sizes
 	Size        uint64     `json:"size"`
+	Checksum    string     `json:"checksum"` // SHA-256 of the chunk content, which is set once the chunk is uploaded
 	FilePath    string     `json:"file_path"`
+	BlobID      uint64     `json:"blob_id"`      // blob storing the chunk content once uploaded, or 0 if the chunk is stored on its own
+	PartialSize uint64     `json:"partial_size"` // bytes received so far of the chunk being uploaded, which is read from the storage
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
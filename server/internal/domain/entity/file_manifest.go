@@ -0,0 +1,24 @@
+package entity
+
+// FileManifest describes how a file is split into chunks, so that the chunks can be fetched and verified one by one
+type FileManifest struct {
+	FileID            uint64              `json:"file_id"`
+	Name              string              `json:"name"`
+	Size              uint64              `json:"size"`
+	Checksum          string              `json:"checksum"`
+	ChecksumAlgorithm string              `json:"checksum_algorithm"` // the checksums of the chunks are always SHA-256
+	MerkleRoot        string              `json:"merkle_root"`        // root of the Merkle tree over the checksums of the chunks
+	ChunkSize         uint64              `json:"chunk_size"`
+	Status            FileStatus          `json:"status"`
+	TotalChunks       uint                `json:"total_chunks"`
+	Chunks            []FileManifestChunk `json:"chunks"`
+}
+
+// FileManifestChunk represents a chunk in the manifest
+type FileManifestChunk struct {
+	ChunkNumber uint64     `json:"chunk_number"`
+	Offset      uint64     `json:"offset"`
+	Size        uint64     `json:"size"`
+	Checksum    string     `json:"checksum"` // SHA-256 of the chunk content, which is empty until the chunk is uploaded
+	Status      FileStatus `json:"status"`
+}
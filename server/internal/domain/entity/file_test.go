@@ -8,10 +8,11 @@ func TestNewFile(t *testing.T) {
 	name := "test-file.txt"
 	size := uint64(1024)
 	checksum := "abc123"
+	checksumAlgorithm := "xxhash64"
 	totalChunks := uint(10)
 	chunkSize := uint64(102)
 
-	file := NewFile(name, size, checksum, totalChunks, chunkSize)
+	file := NewFile(name, size, checksum, checksumAlgorithm, totalChunks, chunkSize)
 
 	if file.Name != name {
 		t.Errorf("Expected Name to be %s, got %s", name, file.Name)
@@ -22,6 +23,9 @@ func TestNewFile(t *testing.T) {
 	if file.Checksum != checksum {
 		t.Errorf("Expected Checksum to be %s, got %s", checksum, file.Checksum)
 	}
+	if file.ChecksumAlgorithm != checksumAlgorithm {
+		t.Errorf("Expected ChecksumAlgorithm to be %s, got %s", checksumAlgorithm, file.ChecksumAlgorithm)
+	}
 	if file.TotalChunks != totalChunks {
 		t.Errorf("Expected TotalChunks to be %d, got %d", totalChunks, file.TotalChunks)
 	}
@@ -53,3 +57,56 @@ func TestFileStatusConstants(t *testing.T) {
 		}
 	}
 }
+
+func TestFile_ChunkRange(t *testing.T) {
+	file := &File{Size: 10, ChunkSize: 4}
+
+	tests := []struct {
+		chunkNumber    uint64
+		expectedOffset uint64
+		expectedSize   uint64
+	}{
+		{chunkNumber: 0, expectedOffset: 0, expectedSize: 4},
+		{chunkNumber: 1, expectedOffset: 4, expectedSize: 4},
+		{chunkNumber: 2, expectedOffset: 8, expectedSize: 2},
+		{chunkNumber: 3, expectedOffset: 12, expectedSize: 0},
+	}
+
+	for _, tc := range tests {
+		offset, size := file.ChunkRange(&FileChunk{ChunkNumber: tc.chunkNumber})
+		if offset != tc.expectedOffset {
+			t.Errorf("Expected offset of chunk %d to be %d, got %d", tc.chunkNumber, tc.expectedOffset, offset)
+		}
+		if size != tc.expectedSize {
+			t.Errorf("Expected size of chunk %d to be %d, got %d", tc.chunkNumber, tc.expectedSize, size)
+		}
+	}
+}
+
+func TestFile_ChunkRange_VariableChunks(t *testing.T) {
+	file := &File{Size: 10, ChunkSize: 6}
+	file.SetChunkSizes([]uint64{3, 6, 1})
+
+	if !file.VariableChunks || file.TotalChunks != 3 || len(file.FileChunks) != 3 {
+		t.Fatalf("Expected 3 variable chunks, got %d chunks of %d records (variable: %t)", file.TotalChunks, len(file.FileChunks), file.VariableChunks)
+	}
+
+	tests := []struct {
+		expectedOffset uint64
+		expectedSize   uint64
+	}{
+		{expectedOffset: 0, expectedSize: 3},
+		{expectedOffset: 3, expectedSize: 6},
+		{expectedOffset: 9, expectedSize: 1},
+	}
+
+	for i, tc := range tests {
+		offset, size := file.ChunkRange(&file.FileChunks[i])
+		if offset != tc.expectedOffset {
+			t.Errorf("Expected offset of chunk %d to be %d, got %d", i, tc.expectedOffset, offset)
+		}
+		if size != tc.expectedSize {
+			t.Errorf("Expected size of chunk %d to be %d, got %d", i, tc.expectedSize, size)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// FsckReport represents the inconsistencies between the database and the storage found by the consistency checker
+type FsckReport struct {
+	CheckedAt         time.Time   `json:"checked_at"`
+	IsRepaired        bool        `json:"is_repaired"`        // whether the inconsistencies below are repaired
+	MissingChunks     []FsckChunk `json:"missing_chunks"`     // stored chunks whose files are missing or found damaged
+	InterruptedChunks []FsckChunk `json:"interrupted_chunks"` // chunks left IN_PROGRESS by the stuck files
+	StuckFiles        []FsckFile  `json:"stuck_files"`        // files left IN_PROGRESS or VERIFYING after the upload timeout
+	OrphanedPaths     []string    `json:"orphaned_paths"`     // files and directories in the storage which no record refers to
+}
+
+// FsckFile represents a file whose upload is stuck
+type FsckFile struct {
+	FileID    uint64     `json:"file_id"`
+	Name      string     `json:"name"`
+	Status    FileStatus `json:"status"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// FsckChunk represents a chunk whose record doesn't match the storage
+type FsckChunk struct {
+	FileID      uint64     `json:"file_id"`
+	FileName    string     `json:"file_name"`
+	ChunkNumber uint64     `json:"chunk_number"`
+	Status      FileStatus `json:"status"`
+	FilePath    string     `json:"file_path"`
+}
+
+// IsConsistent reports whether no inconsistency is found
+func (r *FsckReport) IsConsistent() bool {
+	return len(r.MissingChunks) == 0 && len(r.InterruptedChunks) == 0 && len(r.StuckFiles) == 0 && len(r.OrphanedPaths) == 0
+}
@@ -0,0 +1,10 @@
+package entity
+
+// MerkleProof is the inclusion proof of a chunk in the Merkle tree of its file. The leaves of the tree are the SHA-256
+// checksums of the chunks in order, and the tree is built as described in RFC 6962.
+type MerkleProof struct {
+	Root      string   `json:"root"`
+	LeafIndex uint64   `json:"leaf_index"` // the chunk number
+	TreeSize  uint64   `json:"tree_size"`  // the total number of the chunks
+	Hashes    []string `json:"hashes"`     // the sibling hashes from the leaf up to the root
+}
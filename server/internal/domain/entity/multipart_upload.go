@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// MultipartUpload represents an upload of a file in parts, whose size is unknown until all the parts are uploaded.
+// The parts are kept aside and become the chunks of the file once the upload is completed
+type MultipartUpload struct {
+	ID        string                    `json:"id"`
+	FileName  string                    `json:"file_name"`
+	Parts     map[uint64]*MultipartPart `json:"parts"` // part number to the uploaded part
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+// MultipartPart represents an uploaded part of a multipart upload
+type MultipartPart struct {
+	PartNumber uint64 `json:"part_number"`
+	Size       uint64 `json:"size"`
+	Checksum   string `json:"checksum"` // SHA-256 of the part content
+	FilePath   string `json:"file_path"`
+}
+
+// NewMultipartUpload creates a new MultipartUpload entity
+func NewMultipartUpload(id string, fileName string) *MultipartUpload {
+	return &MultipartUpload{
+		ID:        id,
+		FileName:  fileName,
+		Parts:     make(map[uint64]*MultipartPart),
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// ScrubReport represents the damage found by the scrubber, which re-verifies the stored chunks of the uploaded files
+type ScrubReport struct {
+	LastRun      *ScrubRun          `json:"last_run"` // nil until a scrub finishes after the server starts
+	DamagedFiles []ScrubDamagedFile `json:"damaged_files"`
+}
+
+// ScrubRun represents the result of a single scrub over all the uploaded files
+type ScrubRun struct {
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+	ScannedFiles  uint64    `json:"scanned_files"`
+	ScannedChunks uint64    `json:"scanned_chunks"`
+	DamagedFiles  uint64    `json:"damaged_files"` // the number of files newly found damaged in this run
+}
+
+// ScrubDamagedFile represents a file whose stored chunks are found missing or modified by the scrubber
+type ScrubDamagedFile struct {
+	FileID       uint64   `json:"file_id"`
+	Name         string   `json:"name"`
+	ChunkNumbers []uint64 `json:"chunk_numbers"`
+}
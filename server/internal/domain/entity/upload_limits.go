@@ -0,0 +1,94 @@
+package entity
+
+import "fmt"
+
+// UploadLimits represents the limits of a file upload enforced by the server. A zero limit means no limit.
+type UploadLimits struct {
+	MaxFileSize   uint64 `json:"max_file_size"`
+	MaxChunkSize  uint64 `json:"max_chunk_size"`
+	MaxChunkCount uint64 `json:"max_chunk_count"`
+}
+
+// Validate checks that the layout of a file upload is within the limits and that its chunks cover the file exactly
+func (l UploadLimits) Validate(totalSize uint64, chunkSize uint64, totalChunks uint64) error {
+	if err := l.validateSizes(totalSize, chunkSize, totalChunks); err != nil {
+		return err
+	}
+
+	// every chunk except the last one has the chunk size, so the chunk count is determined by the sizes
+	expectedChunks := totalSize / chunkSize
+	if totalSize%chunkSize != 0 {
+		expectedChunks++
+	}
+	if totalChunks != expectedChunks {
+		return fmt.Errorf("%d bytes split by %d bytes needs %d chunks, but got %d", totalSize, chunkSize, expectedChunks, totalChunks)
+	}
+
+	return nil
+}
+
+// ValidateChunkSizes checks that the layout of a file upload split into chunks of variable sizes is within the limits,
+// and that its chunks cover the file exactly. The chunk size is the largest size the chunks can have
+func (l UploadLimits) ValidateChunkSizes(totalSize uint64, chunkSize uint64, chunkSizes []uint64) error {
+	if err := l.validateSizes(totalSize, chunkSize, uint64(len(chunkSizes))); err != nil {
+		return err
+	}
+
+	var sum uint64
+	for i, size := range chunkSizes {
+		if size == 0 || size > chunkSize {
+			return fmt.Errorf("size %d of chunk %d is out of 1-%d bytes", size, i, chunkSize)
+		}
+		sum += size
+	}
+	if sum != totalSize {
+		return fmt.Errorf("%d chunks make up %d bytes, but the file has %d bytes", len(chunkSizes), sum, totalSize)
+	}
+
+	return nil
+}
+
+// validateSizes checks that the file size, the chunk size and the chunk count of a file upload are within the limits
+func (l UploadLimits) validateSizes(totalSize uint64, chunkSize uint64, totalChunks uint64) error {
+	if chunkSize == 0 {
+		return fmt.Errorf("chunk size must be greater than 0")
+	}
+	if l.MaxFileSize > 0 && totalSize > l.MaxFileSize {
+		return fmt.Errorf("file size %d exceeds the maximum %d", totalSize, l.MaxFileSize)
+	}
+	if l.MaxChunkSize > 0 && chunkSize > l.MaxChunkSize {
+		return fmt.Errorf("chunk size %d exceeds the maximum %d", chunkSize, l.MaxChunkSize)
+	}
+	if l.MaxChunkCount > 0 && totalChunks > l.MaxChunkCount {
+		return fmt.Errorf("chunk count %d exceeds the maximum %d", totalChunks, l.MaxChunkCount)
+	}
+
+	return nil
+}
+
+// ChunkSizeFor returns the chunk size closest to the preferred one which splits a file of the total size within the
+// limits. It's raised when the file would be split into too many chunks, and lowered to the maximum chunk size. The
+// layout can still be out of the limits, which Validate reports.
+func (l UploadLimits) ChunkSizeFor(totalSize uint64, preferredChunkSize uint64) uint64 {
+	chunkSize := max(preferredChunkSize, 1)
+	if l.MaxChunkCount > 0 {
+		chunkSize = max(chunkSize, (totalSize+l.MaxChunkCount-1)/l.MaxChunkCount)
+	}
+	if l.MaxChunkSize > 0 {
+		chunkSize = min(chunkSize, l.MaxChunkSize)
+	}
+	return chunkSize
+}
+
+// MaxSingleChunkFileSize returns the maximum size of a file stored in a single chunk, which is the smaller of the
+// maximum file size and the maximum chunk size. Zero means no limit.
+func (l UploadLimits) MaxSingleChunkFileSize() uint64 {
+	switch {
+	case l.MaxFileSize == 0:
+		return l.MaxChunkSize
+	case l.MaxChunkSize == 0:
+		return l.MaxFileSize
+	default:
+		return min(l.MaxFileSize, l.MaxChunkSize)
+	}
+}
@@ -0,0 +1,120 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUploadLimits_Validate(t *testing.T) {
+	limits := UploadLimits{MaxFileSize: 100, MaxChunkSize: 10, MaxChunkCount: 12}
+
+	tests := []struct {
+		name          string
+		limits        UploadLimits
+		totalSize     uint64
+		chunkSize     uint64
+		totalChunks   uint64
+		expectedError string
+	}{
+		{name: "Exact chunks", limits: limits, totalSize: 100, chunkSize: 10, totalChunks: 10},
+		{name: "Short last chunk", limits: limits, totalSize: 95, chunkSize: 10, totalChunks: 10},
+		{name: "No limits", totalSize: 1 << 40, chunkSize: 1, totalChunks: 1 << 40},
+		{name: "Zero chunk size", limits: limits, totalSize: 100, chunkSize: 0, totalChunks: 10, expectedError: "chunk size must be greater than 0"},
+		{name: "Too large file", limits: limits, totalSize: 101, chunkSize: 10, totalChunks: 11, expectedError: "file size 101 exceeds the maximum 100"},
+		{name: "Too large chunk", limits: limits, totalSize: 100, chunkSize: 11, totalChunks: 10, expectedError: "chunk size 11 exceeds the maximum 10"},
+		{name: "Too many chunks", limits: limits, totalSize: 13, chunkSize: 1, totalChunks: 13, expectedError: "chunk count 13 exceeds the maximum 12"},
+		{name: "Too few chunks", limits: limits, totalSize: 95, chunkSize: 10, totalChunks: 9, expectedError: "needs 10 chunks, but got 9"},
+		{name: "Too many chunks for the size", limits: limits, totalSize: 95, chunkSize: 10, totalChunks: 11, expectedError: "needs 10 chunks, but got 11"},
+	}
+
+	for _, tc := range tests {
+		err := tc.limits.Validate(tc.totalSize, tc.chunkSize, tc.totalChunks)
+		if tc.expectedError == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+			t.Errorf("%s: expected error containing %q, got %v", tc.name, tc.expectedError, err)
+		}
+	}
+}
+
+func TestUploadLimits_ValidateChunkSizes(t *testing.T) {
+	limits := UploadLimits{MaxFileSize: 100, MaxChunkSize: 10, MaxChunkCount: 4}
+
+	tests := []struct {
+		name          string
+		totalSize     uint64
+		chunkSize     uint64
+		chunkSizes    []uint64
+		expectedError string
+	}{
+		{name: "Variable chunks", totalSize: 20, chunkSize: 10, chunkSizes: []uint64{3, 10, 7}},
+		{name: "Zero chunk size", totalSize: 20, chunkSize: 0, chunkSizes: []uint64{10, 10}, expectedError: "chunk size must be greater than 0"},
+		{name: "Too large chunk size", totalSize: 20, chunkSize: 11, chunkSizes: []uint64{10, 10}, expectedError: "chunk size 11 exceeds the maximum 10"},
+		{name: "Too many chunks", totalSize: 5, chunkSize: 10, chunkSizes: []uint64{1, 1, 1, 1, 1}, expectedError: "chunk count 5 exceeds the maximum 4"},
+		{name: "Empty chunk", totalSize: 10, chunkSize: 10, chunkSizes: []uint64{10, 0}, expectedError: "size 0 of chunk 1 is out of 1-10 bytes"},
+		{name: "Chunk larger than chunk size", totalSize: 10, chunkSize: 8, chunkSizes: []uint64{2, 8, 9}, expectedError: "size 9 of chunk 2 is out of 1-8 bytes"},
+		{name: "Chunks short of file", totalSize: 20, chunkSize: 10, chunkSizes: []uint64{10, 9}, expectedError: "2 chunks make up 19 bytes, but the file has 20 bytes"},
+	}
+
+	for _, tc := range tests {
+		err := limits.ValidateChunkSizes(tc.totalSize, tc.chunkSize, tc.chunkSizes)
+		if tc.expectedError == "" {
+			if err != nil {
+				t.Errorf("%s: expected no error, got %v", tc.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+			t.Errorf("%s: expected error containing %q, got %v", tc.name, tc.expectedError, err)
+		}
+	}
+}
+
+func TestUploadLimits_ChunkSizeFor(t *testing.T) {
+	limits := UploadLimits{MaxFileSize: 100, MaxChunkSize: 10, MaxChunkCount: 12}
+
+	tests := []struct {
+		name               string
+		limits             UploadLimits
+		totalSize          uint64
+		preferredChunkSize uint64
+		expected           uint64
+	}{
+		{name: "Preferred size fits", limits: limits, totalSize: 50, preferredChunkSize: 5, expected: 5},
+		{name: "Raised to fit chunk count", limits: limits, totalSize: 100, preferredChunkSize: 5, expected: 9},
+		{name: "Lowered to max chunk size", limits: limits, totalSize: 50, preferredChunkSize: 20, expected: 10},
+		{name: "Capped even when too many chunks", limits: limits, totalSize: 200, preferredChunkSize: 5, expected: 10},
+		{name: "Zero preferred size", totalSize: 50, preferredChunkSize: 0, expected: 1},
+		{name: "No limits", totalSize: 1 << 40, preferredChunkSize: 4096, expected: 4096},
+	}
+
+	for _, tc := range tests {
+		if actual := tc.limits.ChunkSizeFor(tc.totalSize, tc.preferredChunkSize); actual != tc.expected {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestUploadLimits_MaxSingleChunkFileSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   UploadLimits
+		expected uint64
+	}{
+		{name: "Chunk size is smaller", limits: UploadLimits{MaxFileSize: 100, MaxChunkSize: 10}, expected: 10},
+		{name: "File size is smaller", limits: UploadLimits{MaxFileSize: 5, MaxChunkSize: 10}, expected: 5},
+		{name: "No max file size", limits: UploadLimits{MaxChunkSize: 10}, expected: 10},
+		{name: "No max chunk size", limits: UploadLimits{MaxFileSize: 100}, expected: 100},
+		{name: "No limits", expected: 0},
+	}
+
+	for _, tc := range tests {
+		if actual := tc.limits.MaxSingleChunkFileSize(); actual != tc.expected {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.expected, actual)
+		}
+	}
+}
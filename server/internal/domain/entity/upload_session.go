@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// UploadSession represents an upload of a file identified by an opaque token rather than by the file name. The file
+// being uploaded has no name until the session is committed, where it's bound to the target name atomically, so the
+// file previously bound to the name stays readable during the upload and the uploads of the same name don't collide
+type UploadSession struct {
+	ID         uint64    `json:"id"`
+	Token      string    `json:"token"`
+	FileID     uint64    `json:"file_id"`
+	TargetName string    `json:"target_name"`
+	Owner      string    `json:"owner"`      // who started the upload, which is the only one who can resume it
+	OpenEnded  bool      `json:"open_ended"` // the size and the checksum of the file are given when it's committed
+	Multipart  bool      `json:"multipart"`  // the file records the parts of a multipart upload as its chunks
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	File       *File     `json:"file"`
+}
+
+// NewUploadSession creates a new UploadSession entity
+func NewUploadSession(token string, targetName string, owner string, expiresAt time.Time) *UploadSession {
+	return &UploadSession{
+		Token:      token,
+		TargetName: targetName,
+		Owner:      owner,
+		ExpiresAt:  expiresAt,
+	}
+}
+
+// IsExpired reports whether the session is expired at the time
+func (s *UploadSession) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// IsCommitted reports whether the file of the session is bound to the target name
+func (s *UploadSession) IsCommitted() bool {
+	return s.File != nil && s.File.Status == FileStatusUploaded
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewUploadSession(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	session := NewUploadSession("token", "test-file.txt", "alice@host", expiresAt)
+
+	if session.Token != "token" {
+		t.Errorf("Expected Token to be token, got %s", session.Token)
+	}
+	if session.TargetName != "test-file.txt" {
+		t.Errorf("Expected TargetName to be test-file.txt, got %s", session.TargetName)
+	}
+	if session.Owner != "alice@host" {
+		t.Errorf("Expected Owner to be alice@host, got %s", session.Owner)
+	}
+	if !session.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("Expected ExpiresAt to be %v, got %v", expiresAt, session.ExpiresAt)
+	}
+	if session.File != nil {
+		t.Errorf("Expected File to be nil, got %+v", session.File)
+	}
+}
+
+func TestUploadSession_IsExpired(t *testing.T) {
+	expiresAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	session := NewUploadSession("token", "test-file.txt", "", expiresAt)
+
+	testCases := []struct {
+		now      time.Time
+		expected bool
+	}{
+		{now: expiresAt.Add(-time.Second), expected: false},
+		{now: expiresAt, expected: true},
+		{now: expiresAt.Add(time.Second), expected: true},
+	}
+
+	for _, tc := range testCases {
+		if actual := session.IsExpired(tc.now); actual != tc.expected {
+			t.Errorf("IsExpired(%v): expected %v, got %v", tc.now, tc.expected, actual)
+		}
+	}
+}
+
+func TestUploadSession_IsCommitted(t *testing.T) {
+	testCases := []struct {
+		file     *File
+		expected bool
+	}{
+		{file: nil, expected: false},
+		{file: &File{Status: FileStatusInProgress}, expected: false},
+		{file: &File{Status: FileStatusVerifying}, expected: false},
+		{file: &File{Status: FileStatusUploaded}, expected: true},
+	}
+
+	for _, tc := range testCases {
+		session := &UploadSession{File: tc.file}
+		if actual := session.IsCommitted(); actual != tc.expected {
+			t.Errorf("IsCommitted() with %+v: expected %v, got %v", tc.file, tc.expected, actual)
+		}
+	}
+}
@@ -33,7 +33,7 @@ func NewDB(config *entity.Config, logger *slog.Logger) (*gorm.DB, error) {
 	sqlDB.SetConnMaxLifetime(config.DBConnMaxLifetime)
 
 	// Auto-migrate the database based on the models
-	if err := db.AutoMigrate(&database.FileModel{}, &database.FileChunkModel{}); err != nil {
+	if err := db.AutoMigrate(&database.FileModel{}, &database.BlobModel{}, &database.FileChunkModel{}, &database.UploadSessionModel{}); err != nil {
 		logger.Error("failed to auto-migrate database", "error", err)
 		return nil, err
 	}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+var (
+	errInvalidRange = errors.New("invalid range")
+	errNoOverlap    = errors.New("invalid range: failed to overlap")
+)
+
+// byteRange represents a single range of the "Range: bytes=..." request header
+type byteRange struct {
+	start  uint64
+	length uint64
+}
+
+// contentRange returns the value of the Content-Range header for the range
+func (r byteRange) contentRange(size uint64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// mimeHeader returns the header of the part of a multipart/byteranges response for the range
+func (r byteRange) mimeHeader(contentType string, size uint64) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		"Content-Range": {r.contentRange(size)},
+		"Content-Type":  {contentType},
+	}
+}
+
+// parseRange parses a Range header value (e.g. "bytes=0-99,200-,-50") against a content of the given size.
+// Ranges which start beyond the content size are dropped, and errNoOverlap is returned if no range is left.
+func parseRange(header string, size uint64) ([]byteRange, error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, errInvalidRange
+	}
+
+	var ranges []byteRange
+	noOverlap := false
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		startStr, endStr, found := strings.Cut(spec, "-")
+		if !found {
+			return nil, errInvalidRange
+		}
+		startStr, endStr = strings.TrimSpace(startStr), strings.TrimSpace(endStr)
+
+		var r byteRange
+		if startStr == "" {
+			// suffix range (e.g. "-50") which means the last N bytes
+			if endStr == "" {
+				return nil, errInvalidRange
+			}
+			suffix, err := strconv.ParseUint(endStr, 10, 64)
+			if err != nil {
+				return nil, errInvalidRange
+			}
+			if suffix == 0 {
+				noOverlap = true
+				continue
+			}
+			suffix = min(suffix, size)
+			r.start = size - suffix
+			r.length = suffix
+		} else {
+			start, err := strconv.ParseUint(startStr, 10, 64)
+			if err != nil {
+				return nil, errInvalidRange
+			}
+			if start >= size {
+				noOverlap = true
+				continue
+			}
+			r.start = start
+			if endStr == "" {
+				// open-ended range (e.g. "200-") which means until the end of the content
+				r.length = size - start
+			} else {
+				end, err := strconv.ParseUint(endStr, 10, 64)
+				if err != nil || start > end {
+					return nil, errInvalidRange
+				}
+				end = min(end, size-1)
+				r.length = end - start + 1
+			}
+		}
+		ranges = append(ranges, r)
+	}
+
+	if noOverlap && len(ranges) == 0 {
+		return nil, errNoOverlap
+	}
+	if len(ranges) == 0 {
+		return nil, errInvalidRange
+	}
+
+	return ranges, nil
+}
+
+// countingWriter counts the number of bytes written to it
+type countingWriter uint64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// multipartRangesSize returns the length of the multipart/byteranges body for the ranges. The boundary is not
+// relevant because every boundary generated by the multipart writer has the same length.
+func multipartRangesSize(ranges []byteRange, contentType string, size uint64) uint64 {
+	var w countingWriter
+	mw := multipart.NewWriter(&w)
+
+	var total uint64
+	for _, r := range ranges {
+		_, _ = mw.CreatePart(r.mimeHeader(contentType, size))
+		total += r.length
+	}
+	_ = mw.Close()
+
+	return total + uint64(w)
+}
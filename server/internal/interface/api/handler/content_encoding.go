@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tomoya.tokunaga/server/internal/util/compression"
+)
+
+// newRequestBodyReader returns a reader decoding the request body in its Content-Encoding. The body is read as it is
+// without the header
+func newRequestBodyReader(ctx *gin.Context) (io.ReadCloser, error) {
+	encoding, err := compression.Parse(ctx.GetHeader("Content-Encoding"))
+	if err != nil {
+		return nil, err
+	}
+	return compression.NewReader(encoding, ctx.Request.Body)
+}
+
+// newResponseBodyWriter returns a writer encoding the response body in the encoding negotiated by the Accept-Encoding
+// header of the request, and sets the Content-Encoding header of the response accordingly. The length of the encoded
+// body isn't known upfront, so the Content-Length header needs to be left unset when the body is encoded. Close
+// needs to be called to flush the body
+func newResponseBodyWriter(ctx *gin.Context, level int) (io.WriteCloser, compression.Encoding, error) {
+	ctx.Header("Vary", "Accept-Encoding")
+	encoding := compression.Negotiate(ctx.GetHeader("Accept-Encoding"))
+	w, err := compression.NewWriter(encoding, ctx.Writer, level)
+	if err != nil {
+		return nil, "", err
+	}
+	if encoding != compression.Identity {
+		ctx.Header("Content-Encoding", string(encoding))
+	}
+	return w, encoding, nil
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"golang.org/x/exp/slog"
+)
+
+type FileFsckHandler struct {
+	fileFsckUseCase usecase.FileFsckUseCase
+	logger          *slog.Logger
+}
+
+func NewFileFsckHandler(fileFsckUseCase usecase.FileFsckUseCase, logger *slog.Logger) *FileFsckHandler {
+	return &FileFsckHandler{
+		fileFsckUseCase: fileFsckUseCase,
+		logger:          logger,
+	}
+}
+
+// Execute handles the consistency check between the database and the storage without changing anything. The repair
+// deletes files and rewrites records, so it's left to the fsck subcommand run by the operator of the file server
+func (h *FileFsckHandler) Execute(ctx *gin.Context) {
+	report, err := h.fileFsckUseCase.Execute(ctx.Request.Context(), false)
+	if err != nil {
+		sendErrorResponse(ctx, h.logger, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
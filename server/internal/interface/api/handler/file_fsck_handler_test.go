@@ -0,0 +1,104 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/interface/api/handler"
+	"github.com/tomoya.tokunaga/server/internal/mock"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/exp/slog"
+)
+
+func TestFileFsckHandler_Execute(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	testReport := &entity.FsckReport{
+		CheckedAt: now,
+		MissingChunks: []entity.FsckChunk{
+			{FileID: 1, FileName: "uploaded.txt", ChunkNumber: 1, Status: entity.FileStatusUploaded, FilePath: "/uploads/uploaded.txt/1"},
+		},
+		InterruptedChunks: []entity.FsckChunk{},
+		StuckFiles: []entity.FsckFile{
+			{FileID: 2, Name: "stuck.txt", Status: entity.FileStatusInProgress, UpdatedAt: now.Add(-time.Hour)},
+		},
+		OrphanedPaths: []string{"/uploads/deleted.txt"},
+	}
+	mockUsecaseError := e.NewMockCustomError(errors.New("usecase error"), "Usecase error", http.StatusInternalServerError)
+
+	testCases := []struct {
+		name             string
+		method           string
+		path             string
+		setupMock        func(mockUseCase *mock.MockFileFsckUseCase)
+		expectedStatus   int
+		expectedBody     *entity.FsckReport
+		expectedErrorMsg string
+	}{
+		{
+			name:   "Success - Check",
+			method: http.MethodGet,
+			path:   "/admin/fsck",
+			setupMock: func(mockUseCase *mock.MockFileFsckUseCase) {
+				mockUseCase.EXPECT().Execute(gomock.Any(), false).Return(testReport, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testReport,
+		},
+		{
+			name:   "Error - Usecase Internal Error",
+			method: http.MethodGet,
+			path:   "/admin/fsck",
+			setupMock: func(mockUseCase *mock.MockFileFsckUseCase) {
+				mockUseCase.EXPECT().Execute(gomock.Any(), false).Return(nil, mockUsecaseError)
+			},
+			expectedStatus:   mockUsecaseError.StatusCode(),
+			expectedErrorMsg: mockUsecaseError.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUseCase := mock.NewMockFileFsckUseCase(ctrl)
+			tc.setupMock(mockUseCase)
+
+			h := handler.NewFileFsckHandler(mockUseCase, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin/fsck", h.Execute)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(context.Background(), tc.method, tc.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code, "Status code mismatch")
+
+			if tc.expectedBody != nil {
+				var actualBody entity.FsckReport
+				err := json.Unmarshal(w.Body.Bytes(), &actualBody)
+				require.NoError(t, err, "Failed to unmarshal success response: %s", w.Body.String())
+				assert.Equal(t, *tc.expectedBody, actualBody)
+			} else {
+				var errorResponse handler.ErrorResponse
+				err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
+				require.NoError(t, err, "Failed to unmarshal error response: %s", w.Body.String())
+				assert.Contains(t, errorResponse.Error, tc.expectedErrorMsg, "Error message mismatch")
+			}
+		})
+	}
+}
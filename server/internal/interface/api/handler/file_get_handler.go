@@ -2,16 +2,29 @@ package handler
 
 import (
 	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
-	"time"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
 	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"github.com/tomoya.tokunaga/server/internal/util/compression"
 	"golang.org/x/exp/slog"
 )
 
+// Headers of a chunk response which carry the inclusion proof of the chunk in the Merkle tree of its file
+const (
+	MerkleRootHeader      = "X-Merkle-Root"
+	MerkleLeafIndexHeader = "X-Merkle-Leaf-Index"
+	MerkleTreeSizeHeader  = "X-Merkle-Tree-Size"
+	MerkleProofHeader     = "X-Merkle-Proof" // comma-separated sibling hashes from the leaf up to the root
+)
+
 type FileGetHandler struct {
 	fileGetUseCase usecase.FileGetUseCase
 	config         *entity.Config
@@ -24,7 +37,6 @@ type FileGetResponse struct {
 
 type FileGetStatsResponse struct {
 	*entity.File
-	UploadTimeoutSecond time.Duration `json:"upload_timeout_second"` // used to identify an orphaned file in the cli
 }
 
 func NewFileGetHandler(fileGetUseCase usecase.FileGetUseCase, config *entity.Config, logger *slog.Logger) *FileGetHandler {
@@ -63,7 +75,211 @@ func (h *FileGetHandler) ExecuteGetStats(ctx *gin.Context) {
 	}
 
 	ctx.JSON(http.StatusOK, FileGetStatsResponse{
-		File:                fileStats,
-		UploadTimeoutSecond: h.config.UploadTimeoutSecond,
+		File: fileStats,
 	})
 }
+
+// ExecuteGetContent streams the content of an uploaded file by reassembling its chunks on the fly. A "Range" header
+// is answered with "206 Partial Content" (multipart/byteranges for multiple ranges)
+func (h *FileGetHandler) ExecuteGetContent(ctx *gin.Context) {
+	fileName := ctx.Param("file_name")
+	if fileName == "" {
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(errors.New("file_name parameter is required"), ""))
+		return
+	}
+
+	reqCtx := ctx.Request.Context()
+	file, err := h.fileGetUseCase.ExecuteGetContent(reqCtx, fileName)
+	if err != nil {
+		sendErrorResponse(ctx, h.logger, err)
+		return
+	}
+
+	const contentType = "application/octet-stream"
+	ctx.Header("Accept-Ranges", "bytes")
+
+	var ranges []byteRange
+	if rangeHeader := ctx.GetHeader("Range"); rangeHeader != "" {
+		parsed, parseErr := parseRange(rangeHeader, file.Size)
+		if parseErr != nil {
+			ctx.Header("Content-Range", fmt.Sprintf("bytes */%d", file.Size))
+			sendErrorResponse(ctx, h.logger, e.NewRangeNotSatisfiableError(parseErr, rangeHeader))
+			return
+		}
+
+		// The ranges are ignored when they are larger than the content in total, which protects the server from
+		// requests repeating the same range over and over
+		var totalLength uint64
+		for _, r := range parsed {
+			totalLength += r.length
+		}
+		if totalLength <= file.Size {
+			ranges = parsed
+		}
+	}
+
+	switch len(ranges) {
+	case 0:
+		// Without a range, the whole content is sent
+		ctx.Header("Content-Type", contentType)
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+		h.streamEncodedContent(ctx, file)
+		return
+	case 1:
+		// A single range is sent as it is. Content-Range refers to the bytes of the representation, so the range is
+		// never encoded, or the offsets wouldn't match the bytes sent and a client resuming the download would
+		// assemble a corrupt file
+		r := ranges[0]
+		ctx.Header("Content-Type", contentType)
+		ctx.Header("Content-Range", r.contentRange(file.Size))
+		ctx.Header("Content-Length", strconv.FormatUint(r.length, 10))
+		ctx.Status(http.StatusPartialContent)
+		h.streamContent(ctx, file, ctx.Writer, r.start, r.length)
+		return
+	}
+
+	// Multiple ranges are sent as parts of a multipart/byteranges body
+	mw := multipart.NewWriter(ctx.Writer)
+	ctx.Header("Content-Type", "multipart/byteranges; boundary="+mw.Boundary())
+	ctx.Header("Content-Length", strconv.FormatUint(multipartRangesSize(ranges, contentType, file.Size), 10))
+	ctx.Status(http.StatusPartialContent)
+	for _, r := range ranges {
+		part, err := mw.CreatePart(r.mimeHeader(contentType, file.Size))
+		if err != nil {
+			h.logger.Error("failed to create multipart range", "file_name", fileName, "error", err)
+			return
+		}
+		if !h.streamContent(ctx, file, part, r.start, r.length) {
+			return
+		}
+	}
+	if err := mw.Close(); err != nil {
+		h.logger.Error("failed to close multipart writer", "file_name", fileName, "error", err)
+	}
+}
+
+// streamEncodedContent sends the whole file content, encoded in the encoding the client accepts if any. The length of
+// the encoded content isn't known upfront, so it's sent without Content-Length
+func (h *FileGetHandler) streamEncodedContent(ctx *gin.Context, file *entity.File) {
+	body, encoding, encErr := newResponseBodyWriter(ctx, h.config.ResponseCompressionLevel)
+	if encErr != nil {
+		sendErrorResponse(ctx, h.logger, e.NewContextError(encErr, "failed to encode response body"))
+		return
+	}
+	if encoding == compression.Identity {
+		ctx.Header("Content-Length", strconv.FormatUint(file.Size, 10))
+	}
+	ctx.Status(http.StatusOK)
+	if !h.streamContent(ctx, file, body, 0, file.Size) {
+		return
+	}
+	if err := body.Close(); err != nil {
+		h.logger.Error("failed to flush encoded file content", "file_name", file.Name, "error", err)
+	}
+}
+
+// streamContent writes the range of the file content to the writer and reports whether it succeeded
+func (h *FileGetHandler) streamContent(ctx *gin.Context, file *entity.File, writer io.Writer, offset uint64, length uint64) bool {
+	err := h.fileGetUseCase.ExecuteStreamContent(ctx.Request.Context(), file, writer, offset, length)
+	if err == nil {
+		return true
+	}
+
+	h.handleStreamError(ctx, file.Name, err)
+	return false
+}
+
+// handleStreamError reports the error which occurred while streaming the content of a file
+func (h *FileGetHandler) handleStreamError(ctx *gin.Context, fileName string, err e.CustomError) {
+	if !ctx.Writer.Written() {
+		// nothing has been sent yet, so the error can still be reported to the client
+		ctx.Writer.Header().Del("Content-Length")
+		ctx.Writer.Header().Del("Content-Range")
+		ctx.Writer.Header().Del("Content-Disposition")
+		ctx.Writer.Header().Del("Content-Encoding")
+		sendErrorResponse(ctx, h.logger, err)
+		return
+	}
+
+	// the response is partially sent and can't be replaced by an error response. the client detects the
+	// failure by the content length mismatch once the connection is closed
+	h.logger.Error("failed to stream file content",
+		"file_name", fileName,
+		"error", err.Error(),
+		"code", err.ErrorCode(),
+	)
+}
+
+// ExecuteGetManifest returns the chunk list of a file
+func (h *FileGetHandler) ExecuteGetManifest(ctx *gin.Context) {
+	fileName := ctx.Param("file_name")
+	if fileName == "" {
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(errors.New("file_name parameter is required"), ""))
+		return
+	}
+
+	manifest, err := h.fileGetUseCase.ExecuteGetManifest(ctx.Request.Context(), fileName)
+	if err != nil {
+		sendErrorResponse(ctx, h.logger, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, manifest)
+}
+
+// ExecuteGetChunk streams the raw content of a single uploaded chunk
+func (h *FileGetHandler) ExecuteGetChunk(ctx *gin.Context) {
+	// gin requires the wildcards at the same position to have the same name, so the file ID comes as "file_name"
+	fileIDStr := ctx.Param("file_name")
+	chunkNumberStr := ctx.Param("chunk_number")
+
+	fileID, parseErr := strconv.ParseUint(fileIDStr, 10, 64)
+	if parseErr != nil {
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(parseErr, fmt.Sprintf("invalid file ID: %s", fileIDStr)))
+		return
+	}
+
+	chunkNumber, parseErr := strconv.ParseUint(chunkNumberStr, 10, 64)
+	if parseErr != nil {
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(parseErr, fmt.Sprintf("invalid chunk number: %s", chunkNumberStr)))
+		return
+	}
+
+	reqCtx := ctx.Request.Context()
+	file, chunk, err := h.fileGetUseCase.ExecuteGetChunk(reqCtx, fileID, chunkNumber)
+	if err != nil {
+		sendErrorResponse(ctx, h.logger, err)
+		return
+	}
+
+	// the inclusion proof lets the client verify the chunk against the Merkle root of the file without the other chunks
+	proof, err := h.fileGetUseCase.ExecuteGetChunkProof(reqCtx, file, chunk)
+	if err != nil {
+		sendErrorResponse(ctx, h.logger, err)
+		return
+	}
+	if proof != nil {
+		ctx.Header(MerkleRootHeader, proof.Root)
+		ctx.Header(MerkleLeafIndexHeader, strconv.FormatUint(proof.LeafIndex, 10))
+		ctx.Header(MerkleTreeSizeHeader, strconv.FormatUint(proof.TreeSize, 10))
+		ctx.Header(MerkleProofHeader, strings.Join(proof.Hashes, ","))
+	}
+
+	body, encoding, encErr := newResponseBodyWriter(ctx, h.config.ResponseCompressionLevel)
+	if encErr != nil {
+		sendErrorResponse(ctx, h.logger, e.NewContextError(encErr, "failed to encode response body"))
+		return
+	}
+	ctx.Header("Content-Type", "application/octet-stream")
+	if encoding == compression.Identity {
+		ctx.Header("Content-Length", strconv.FormatUint(chunk.Size, 10))
+	}
+	ctx.Status(http.StatusOK)
+	if err := h.fileGetUseCase.ExecuteStreamChunk(reqCtx, file, chunk, body); err != nil {
+		h.handleStreamError(ctx, file.Name, err)
+		return
+	}
+	if err := body.Close(); err != nil {
+		h.logger.Error("failed to flush encoded chunk content", "file_name", file.Name, "error", err)
+	}
+}
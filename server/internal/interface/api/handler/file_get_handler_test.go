@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -18,6 +21,7 @@ import (
 	"github.com/tomoya.tokunaga/server/internal/interface/api/handler"
 	"github.com/tomoya.tokunaga/server/internal/mock"
 	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"github.com/tomoya.tokunaga/server/internal/util/compression"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/exp/slog"
 )
@@ -275,3 +279,485 @@ func TestFileGetHandler_ExecuteGetStats(t *testing.T) {
 		})
 	}
 }
+
+func TestFileGetHandler_ExecuteGetContent(t *testing.T) {
+	testFileName := "testfile.txt"
+	testContent := "hello world"
+	testFile := &entity.File{
+		ID:          1,
+		Name:        testFileName,
+		Size:        uint64(len(testContent)),
+		Status:      entity.FileStatusUploaded,
+		ChunkSize:   6,
+		TotalChunks: 2,
+	}
+
+	mockNotFoundError := e.NewNotFoundError(errors.New("file not found"), "")
+	mockStorageError := e.NewFileStorageError(errors.New("storage error"), "failed to open file")
+
+	// streamRange writes the requested range of the test content
+	streamRange := func(_ context.Context, _ *entity.File, w io.Writer, offset uint64, length uint64) e.CustomError {
+		_, _ = w.Write([]byte(testContent[offset : offset+length]))
+		return nil
+	}
+
+	testCases := []struct {
+		name                 string
+		rangeHeader          string
+		acceptEncoding       string
+		setupMock            func(mockUseCase *mock.MockFileGetUseCase)
+		expectedStatus       int
+		expectedBody         string
+		expectedContentRange string
+		expectedEncoding     string
+		expectedParts        []string
+		expectError          bool
+		expectedErrorMsg     string
+	}{
+		{
+			name: "Success - Whole content",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+				mockUseCase.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(0), testFile.Size).DoAndReturn(streamRange)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testContent,
+			expectError:    false,
+		},
+		{
+			name:        "Success - Single range",
+			rangeHeader: "bytes=3-7",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+				mockUseCase.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(3), uint64(5)).DoAndReturn(streamRange)
+			},
+			expectedStatus:       http.StatusPartialContent,
+			expectedBody:         "lo wo",
+			expectedContentRange: "bytes 3-7/11",
+			expectError:          false,
+		},
+		{
+			name:        "Success - Suffix range",
+			rangeHeader: "bytes=-5",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+				mockUseCase.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(6), uint64(5)).DoAndReturn(streamRange)
+			},
+			expectedStatus:       http.StatusPartialContent,
+			expectedBody:         "world",
+			expectedContentRange: "bytes 6-10/11",
+			expectError:          false,
+		},
+		{
+			name:        "Success - Open-ended range beyond the end is truncated",
+			rangeHeader: "bytes=6-100",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+				mockUseCase.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(6), uint64(5)).DoAndReturn(streamRange)
+			},
+			expectedStatus:       http.StatusPartialContent,
+			expectedBody:         "world",
+			expectedContentRange: "bytes 6-10/11",
+			expectError:          false,
+		},
+		{
+			name:           "Success - Whole content encoded in zstd",
+			acceptEncoding: "gzip, zstd",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+				mockUseCase.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(0), testFile.Size).DoAndReturn(streamRange)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedBody:     testContent,
+			expectedEncoding: "zstd",
+			expectError:      false,
+		},
+		{
+			name:           "Success - Single range isn't encoded even if gzip is accepted",
+			rangeHeader:    "bytes=3-7",
+			acceptEncoding: "gzip",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+				mockUseCase.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(3), uint64(5)).DoAndReturn(streamRange)
+			},
+			expectedStatus:       http.StatusPartialContent,
+			expectedBody:         "lo wo",
+			expectedContentRange: "bytes 3-7/11",
+			expectError:          false,
+		},
+		{
+			name:        "Success - Multiple ranges",
+			rangeHeader: "bytes=0-1, 6-",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+				gomock.InOrder(
+					mockUseCase.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(0), uint64(2)).DoAndReturn(streamRange),
+					mockUseCase.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(6), uint64(5)).DoAndReturn(streamRange),
+				)
+			},
+			expectedStatus: http.StatusPartialContent,
+			expectedParts:  []string{"he", "world"},
+			expectError:    false,
+		},
+		{
+			name:        "Success - Ranges larger than content are ignored",
+			rangeHeader: "bytes=0-,0-",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+				mockUseCase.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(0), testFile.Size).DoAndReturn(streamRange)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testContent,
+			expectError:    false,
+		},
+		{
+			name:        "Error - Range not satisfiable",
+			rangeHeader: "bytes=11-",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+			},
+			expectedStatus:       http.StatusRequestedRangeNotSatisfiable,
+			expectedContentRange: "bytes */11",
+			expectError:          true,
+			expectedErrorMsg:     "failed to overlap",
+		},
+		{
+			name:        "Error - Malformed range",
+			rangeHeader: "bytes=5-3",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+			},
+			expectedStatus:       http.StatusRequestedRangeNotSatisfiable,
+			expectedContentRange: "bytes */11",
+			expectError:          true,
+			expectedErrorMsg:     "invalid range",
+		},
+		{
+			name: "Error - File not found",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(nil, mockNotFoundError)
+			},
+			expectedStatus:   http.StatusNotFound,
+			expectError:      true,
+			expectedErrorMsg: mockNotFoundError.Error(),
+		},
+		{
+			name: "Error - Storage error before any byte is sent",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+				mockUseCase.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(0), testFile.Size).Return(mockStorageError)
+			},
+			expectedStatus:   http.StatusInternalServerError,
+			expectError:      true,
+			expectedErrorMsg: mockStorageError.Error(),
+		},
+		{
+			name:           "Error - Storage error before any encoded byte is sent",
+			acceptEncoding: "zstd",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetContent(gomock.Any(), testFileName).Return(testFile, nil)
+				mockUseCase.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(0), testFile.Size).Return(mockStorageError)
+			},
+			expectedStatus:   http.StatusInternalServerError,
+			expectError:      true,
+			expectedErrorMsg: mockStorageError.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Setting up mock api server and use cases
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUseCase := mock.NewMockFileGetUseCase(ctrl)
+			if tc.setupMock != nil {
+				tc.setupMock(mockUseCase)
+			}
+
+			h, router := setupTestFileGetHandler(t, mockUseCase)
+			router.GET("/files/:file_name/content", h.ExecuteGetContent)
+
+			// Executing the request
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/files/"+testFileName+"/content", nil)
+			if tc.rangeHeader != "" {
+				req.Header.Set("Range", tc.rangeHeader)
+			}
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+			router.ServeHTTP(w, req)
+
+			// Checking the response
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedContentRange, w.Header().Get("Content-Range"))
+			assert.Equal(t, tc.expectedEncoding, w.Header().Get("Content-Encoding"))
+
+			if tc.expectError {
+				var errorResponse map[string]any
+				err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
+
+				// checks error response has an expected structure
+				require.NoError(t, err, "Failed to unmarshal error response: %s", w.Body.String())
+				assert.Empty(t, w.Header().Get("Content-Disposition"))
+
+				// checks error message
+				errorMsg, ok := errorResponse["error"].(string)
+				require.True(t, ok, "Error message is not a string")
+				assert.Contains(t, errorMsg, tc.expectedErrorMsg)
+				return
+			}
+
+			assert.Equal(t, "bytes", w.Header().Get("Accept-Ranges"))
+			if tc.expectedEncoding != "" {
+				// the length of the encoded content isn't known upfront
+				assert.Empty(t, w.Header().Get("Content-Length"))
+				reader, err := compression.NewReader(compression.Encoding(tc.expectedEncoding), w.Body)
+				require.NoError(t, err)
+				body, err := io.ReadAll(reader)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedBody, string(body))
+				return
+			}
+			assert.Equal(t, fmt.Sprintf("%d", w.Body.Len()), w.Header().Get("Content-Length"))
+			if tc.expectedParts == nil {
+				assert.Equal(t, "application/octet-stream", w.Header().Get("Content-Type"))
+				assert.Equal(t, tc.expectedBody, w.Body.String())
+				return
+			}
+
+			// checks each part of the multipart/byteranges body
+			mediaType, params, err := mime.ParseMediaType(w.Header().Get("Content-Type"))
+			require.NoError(t, err)
+			assert.Equal(t, "multipart/byteranges", mediaType)
+
+			reader := multipart.NewReader(w.Body, params["boundary"])
+			for _, expectedPart := range tc.expectedParts {
+				part, err := reader.NextPart()
+				require.NoError(t, err)
+				body, err := io.ReadAll(part)
+				require.NoError(t, err)
+				assert.Equal(t, expectedPart, string(body))
+				assert.NotEmpty(t, part.Header.Get("Content-Range"))
+			}
+			_, err = reader.NextPart()
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestFileGetHandler_ExecuteGetManifest(t *testing.T) {
+	testFileName := "testfile.txt"
+	testManifest := &entity.FileManifest{
+		FileID:      1,
+		Name:        testFileName,
+		Size:        10,
+		ChunkSize:   6,
+		TotalChunks: 2,
+		Status:      entity.FileStatusUploaded,
+		Chunks: []entity.FileManifestChunk{
+			{ChunkNumber: 0, Offset: 0, Size: 6, Checksum: "hash0", Status: entity.FileStatusUploaded},
+			{ChunkNumber: 1, Offset: 6, Size: 4, Checksum: "hash1", Status: entity.FileStatusUploaded},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		setupMock        func(mockUseCase *mock.MockFileGetUseCase)
+		expectedStatus   int
+		expectedManifest *entity.FileManifest
+		expectedErrorMsg string
+	}{
+		{
+			name: "Success",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetManifest(gomock.Any(), testFileName).Return(testManifest, nil)
+			},
+			expectedStatus:   http.StatusOK,
+			expectedManifest: testManifest,
+		},
+		{
+			name: "Error - File not found",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetManifest(gomock.Any(), testFileName).Return(nil, e.NewNotFoundError(errors.New("file not found"), ""))
+			},
+			expectedStatus:   http.StatusNotFound,
+			expectedErrorMsg: "file not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUseCase := mock.NewMockFileGetUseCase(ctrl)
+			tc.setupMock(mockUseCase)
+
+			h, router := setupTestFileGetHandler(t, mockUseCase)
+			router.GET("/files/:file_name/manifest", h.ExecuteGetManifest)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/files/"+testFileName+"/manifest", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedManifest != nil {
+				var manifest entity.FileManifest
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &manifest))
+				assert.Equal(t, *tc.expectedManifest, manifest)
+				return
+			}
+
+			var errorResponse map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+			assert.Contains(t, errorResponse["error"], tc.expectedErrorMsg)
+		})
+	}
+}
+
+func TestFileGetHandler_ExecuteGetChunk(t *testing.T) {
+	testFile := &entity.File{ID: 1, Name: "testfile.txt", Size: 10, ChunkSize: 6, TotalChunks: 2, Status: entity.FileStatusUploaded}
+	testChunk := &entity.FileChunk{ID: 11, ParentID: 1, ChunkNumber: 1, Size: 4, FilePath: "/test/testfile.txt/1", Status: entity.FileStatusUploaded}
+	testProof := &entity.MerkleProof{
+		Root:      "1611ac016713113b29ef4acfa37566046c257ee45139b112939f43a4c20ca8a2",
+		LeafIndex: 1,
+		TreeSize:  2,
+		Hashes:    []string{"0e5aa1d9486fc844f74907edb32f9a119333d0780cd414d6cda1190994c8031e"},
+	}
+	streamChunk := func(_ context.Context, _ *entity.File, _ *entity.FileChunk, w io.Writer) e.CustomError {
+		_, _ = w.Write([]byte("orld"))
+		return nil
+	}
+
+	testCases := []struct {
+		name             string
+		path             string
+		setupMock        func(mockUseCase *mock.MockFileGetUseCase)
+		expectedStatus   int
+		expectedBody     string
+		expectedHeaders  map[string]string
+		expectedErrorMsg string
+	}{
+		{
+			name: "Success",
+			path: "/files/1/chunks/1",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetChunk(gomock.Any(), uint64(1), uint64(1)).Return(testFile, testChunk, nil)
+				mockUseCase.EXPECT().ExecuteGetChunkProof(gomock.Any(), testFile, testChunk).Return(testProof, nil)
+				mockUseCase.EXPECT().ExecuteStreamChunk(gomock.Any(), testFile, testChunk, gomock.Any()).DoAndReturn(streamChunk)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "orld",
+			expectedHeaders: map[string]string{
+				handler.MerkleRootHeader:      testProof.Root,
+				handler.MerkleLeafIndexHeader: "1",
+				handler.MerkleTreeSizeHeader:  "2",
+				handler.MerkleProofHeader:     testProof.Hashes[0],
+			},
+		},
+		{
+			name: "Success - Without Merkle root",
+			path: "/files/1/chunks/1",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetChunk(gomock.Any(), uint64(1), uint64(1)).Return(testFile, testChunk, nil)
+				mockUseCase.EXPECT().ExecuteGetChunkProof(gomock.Any(), testFile, testChunk).Return(nil, nil)
+				mockUseCase.EXPECT().ExecuteStreamChunk(gomock.Any(), testFile, testChunk, gomock.Any()).DoAndReturn(streamChunk)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "orld",
+			expectedHeaders: map[string]string{
+				handler.MerkleRootHeader:  "",
+				handler.MerkleProofHeader: "",
+			},
+		},
+		{
+			name: "Error - Inconsistent Merkle tree",
+			path: "/files/1/chunks/1",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetChunk(gomock.Any(), uint64(1), uint64(1)).Return(testFile, testChunk, nil)
+				mockUseCase.EXPECT().ExecuteGetChunkProof(gomock.Any(), testFile, testChunk).
+					Return(nil, e.NewDatabaseError(errors.New("merkle root mismatch"), "chunk records of testfile.txt are inconsistent"))
+			},
+			expectedStatus:   http.StatusInternalServerError,
+			expectedErrorMsg: "inconsistent",
+		},
+		{
+			name:             "Error - Invalid file ID",
+			path:             "/files/abc/chunks/1",
+			setupMock:        func(mockUseCase *mock.MockFileGetUseCase) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "invalid file ID: abc",
+		},
+		{
+			name:             "Error - Invalid chunk number",
+			path:             "/files/1/chunks/-1",
+			setupMock:        func(mockUseCase *mock.MockFileGetUseCase) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "invalid chunk number: -1",
+		},
+		{
+			name: "Error - Chunk not uploaded",
+			path: "/files/1/chunks/0",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetChunk(gomock.Any(), uint64(1), uint64(0)).
+					Return(nil, nil, e.NewNotFoundError(errors.New("chunk 0 of testfile.txt is in IN_PROGRESS status and cannot be read"), ""))
+			},
+			expectedStatus:   http.StatusNotFound,
+			expectedErrorMsg: "cannot be read",
+		},
+		{
+			name: "Error - Storage error before streaming",
+			path: "/files/1/chunks/1",
+			setupMock: func(mockUseCase *mock.MockFileGetUseCase) {
+				mockUseCase.EXPECT().ExecuteGetChunk(gomock.Any(), uint64(1), uint64(1)).Return(testFile, testChunk, nil)
+				mockUseCase.EXPECT().ExecuteGetChunkProof(gomock.Any(), testFile, testChunk).Return(nil, nil)
+				mockUseCase.EXPECT().ExecuteStreamChunk(gomock.Any(), testFile, testChunk, gomock.Any()).
+					Return(e.NewFileStorageError(errors.New("storage error"), "failed to open file"))
+			},
+			expectedStatus:   http.StatusInternalServerError,
+			expectedErrorMsg: "storage error",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUseCase := mock.NewMockFileGetUseCase(ctrl)
+			tc.setupMock(mockUseCase)
+
+			h, router := setupTestFileGetHandler(t, mockUseCase)
+			router.GET("/files/:file_name/chunks/:chunk_number", h.ExecuteGetChunk)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, tc.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedErrorMsg == "" {
+				assert.Equal(t, tc.expectedBody, w.Body.String())
+				assert.Equal(t, fmt.Sprintf("%d", len(tc.expectedBody)), w.Header().Get("Content-Length"))
+				for key, value := range tc.expectedHeaders {
+					assert.Equal(t, value, w.Header().Get(key), key)
+				}
+				return
+			}
+
+			var errorResponse map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse), w.Body.String())
+			assert.Contains(t, errorResponse["error"], tc.expectedErrorMsg)
+		})
+	}
+}
@@ -0,0 +1,722 @@
+package handler
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"github.com/tomoya.tokunaga/server/internal/util/checksum"
+	"github.com/tomoya.tokunaga/server/internal/util/sigv4"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	S3Namespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+	// S3TimeFormat is the format of the timestamps in the S3 responses
+	S3TimeFormat = "2006-01-02T15:04:05.000Z"
+
+	s3MaxKeys       = 1000
+	s3MaxPartNumber = 10000
+	// the body of CompleteMultipartUpload lists up to 10000 parts, each of which takes about 100 bytes
+	s3MaxCompleteBodySize = 4 * 1024 * 1024
+	// the key of the payload hash verified by the signature in the gin context
+	s3PayloadHashKey = "s3_payload_hash"
+	// the key of the access key ID which signs the request in the gin context, which owns the uploads of the request
+	s3AccessKeyIDKey = "s3_access_key_id"
+	// the key of the reader decoding the aws-chunked body in the gin context
+	s3ChunkedReaderKey = "s3_chunked_reader"
+)
+
+// FileS3Handler serves the files as the objects of a single bucket through a subset of the S3 API, so that the S3
+// clients (e.g. the AWS SDKs and CLI) can read and write them
+type FileS3Handler struct {
+	fileUploadUseCase usecase.FileUploadUseCase
+	fileGetUseCase    usecase.FileGetUseCase
+	fileDeleteUseCase usecase.FileDeleteUseCase
+	verifier          *sigv4.Verifier
+	config            *entity.Config
+	logger            *slog.Logger
+}
+
+func NewFileS3Handler(
+	fileUploadUseCase usecase.FileUploadUseCase,
+	fileGetUseCase usecase.FileGetUseCase,
+	fileDeleteUseCase usecase.FileDeleteUseCase,
+	config *entity.Config,
+	logger *slog.Logger,
+) *FileS3Handler {
+	return &FileS3Handler{
+		fileUploadUseCase: fileUploadUseCase,
+		fileGetUseCase:    fileGetUseCase,
+		fileDeleteUseCase: fileDeleteUseCase,
+		verifier:          sigv4.NewVerifier(config.S3Credentials, config.S3Region, "s3"),
+		config:            config,
+		logger:            logger,
+	}
+}
+
+// S3ErrorResponse represents the structure of the error response of the S3 API
+type S3ErrorResponse struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
+
+type ListBucketResult struct {
+	XMLName               xml.Name         `xml:"ListBucketResult"`
+	Xmlns                 string           `xml:"xmlns,attr"`
+	Name                  string           `xml:"Name"`
+	Prefix                string           `xml:"Prefix"`
+	Delimiter             string           `xml:"Delimiter,omitempty"`
+	MaxKeys               int              `xml:"MaxKeys"`
+	KeyCount              int              `xml:"KeyCount"`
+	IsTruncated           bool             `xml:"IsTruncated"`
+	EncodingType          string           `xml:"EncodingType,omitempty"`
+	ContinuationToken     string           `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string           `xml:"NextContinuationToken,omitempty"`
+	StartAfter            string           `xml:"StartAfter,omitempty"`
+	Contents              []S3Object       `xml:"Contents"`
+	CommonPrefixes        []S3CommonPrefix `xml:"CommonPrefixes"`
+}
+
+type S3Object struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         uint64 `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type S3CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type InitiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type CompleteMultipartUploadRequest struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []CompletedPart `xml:"Part"`
+}
+
+type CompletedPart struct {
+	PartNumber uint64 `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type CompleteMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+// Authenticate verifies the signature of the request and the bucket, and keeps the signed payload hash for the
+// handlers which read the body. An aws-chunked body is replaced with its decoded content, which is verified as it's
+// read, so the handlers read it as an unsigned payload of the decoded size.
+func (h *FileS3Handler) Authenticate(ctx *gin.Context) {
+	result, err := h.verifier.Verify(ctx.Request)
+	if err != nil {
+		statusCode, code := s3AuthErrorCode(err)
+		h.sendS3Error(ctx, statusCode, code, err.Error())
+		ctx.Abort()
+		return
+	}
+	payloadHash := result.PayloadHash
+	switch {
+	case payloadHash == sigv4.StreamingSignedPayload || payloadHash == sigv4.StreamingUnsignedPayloadTrailer:
+		reader, err := result.NewChunkedReader(ctx.Request)
+		if err != nil {
+			statusCode, code := s3AuthErrorCode(err)
+			h.sendS3Error(ctx, statusCode, code, err.Error())
+			ctx.Abort()
+			return
+		}
+		ctx.Request.Body = io.NopCloser(reader)
+		ctx.Request.ContentLength = reader.DecodedSize()
+		ctx.Set(s3ChunkedReaderKey, reader)
+		payloadHash = sigv4.UnsignedPayload
+	case strings.HasPrefix(payloadHash, sigv4.StreamingPayloadPrefix):
+		h.sendS3Error(ctx, http.StatusNotImplemented, "NotImplemented", fmt.Sprintf("payload %s is not supported", payloadHash))
+		ctx.Abort()
+		return
+	case payloadHash != sigv4.UnsignedPayload:
+		if decoded, err := hex.DecodeString(result.PayloadHash); err != nil || len(decoded) != sha256.Size {
+			h.sendS3Error(ctx, http.StatusBadRequest, "InvalidArgument", fmt.Sprintf("invalid %s %q", sigv4.ContentSHA256Header, result.PayloadHash))
+			ctx.Abort()
+			return
+		}
+	}
+	if bucket := ctx.Param("bucket"); bucket != h.config.S3Bucket {
+		h.sendS3Error(ctx, http.StatusNotFound, "NoSuchBucket", fmt.Sprintf("bucket %s does not exist", bucket))
+		ctx.Abort()
+		return
+	}
+
+	ctx.Set(s3PayloadHashKey, payloadHash)
+	ctx.Set(s3AccessKeyIDKey, result.AccessKeyID)
+}
+
+// ExecuteHeadBucket tells the client that the bucket exists, which is checked by Authenticate
+func (h *FileS3Handler) ExecuteHeadBucket(ctx *gin.Context) {
+	ctx.Status(http.StatusOK)
+}
+
+// ExecuteListObjects lists the uploaded files in the order of their names (ListObjectsV2). The continuation token is
+// the last key or common prefix of the previous page
+func (h *FileS3Handler) ExecuteListObjects(ctx *gin.Context) {
+	if ctx.Query("list-type") != "2" {
+		h.sendS3Error(ctx, http.StatusNotImplemented, "NotImplemented", "only ListObjectsV2 is supported")
+		return
+	}
+
+	result := ListBucketResult{
+		Xmlns:             S3Namespace,
+		Name:              h.config.S3Bucket,
+		Prefix:            ctx.Query("prefix"),
+		Delimiter:         ctx.Query("delimiter"),
+		MaxKeys:           s3MaxKeys,
+		ContinuationToken: ctx.Query("continuation-token"),
+		StartAfter:        ctx.Query("start-after"),
+		EncodingType:      ctx.Query("encoding-type"),
+		Contents:          []S3Object{},
+		CommonPrefixes:    []S3CommonPrefix{},
+	}
+	if maxKeys := ctx.Query("max-keys"); maxKeys != "" {
+		value, err := strconv.Atoi(maxKeys)
+		if err != nil || value < 0 {
+			h.sendS3Error(ctx, http.StatusBadRequest, "InvalidArgument", fmt.Sprintf("invalid max-keys %q", maxKeys))
+			return
+		}
+		result.MaxKeys = min(value, s3MaxKeys)
+	}
+	if result.EncodingType != "" && result.EncodingType != "url" {
+		h.sendS3Error(ctx, http.StatusBadRequest, "InvalidArgument", fmt.Sprintf("invalid encoding-type %q", result.EncodingType))
+		return
+	}
+	marker := result.StartAfter
+	if result.ContinuationToken != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(result.ContinuationToken)
+		if err != nil {
+			h.sendS3Error(ctx, http.StatusBadRequest, "InvalidArgument", "the continuation token provided is incorrect")
+			return
+		}
+		marker = string(decoded)
+	}
+
+	files, err := h.fileGetUseCase.ExecuteGetUploadedFiles(ctx.Request.Context())
+	if err != nil {
+		h.sendUseCaseError(ctx, err, "NoSuchKey")
+		return
+	}
+
+	var lastEntry string
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name, result.Prefix) || file.Name <= marker {
+			continue
+		}
+		// the keys sharing the part up to the delimiter after the prefix are rolled up into a common prefix
+		commonPrefix := ""
+		if result.Delimiter != "" {
+			if i := strings.Index(file.Name[len(result.Prefix):], result.Delimiter); i >= 0 {
+				commonPrefix = file.Name[:len(result.Prefix)+i+len(result.Delimiter)]
+				if commonPrefix == lastEntry || strings.HasPrefix(marker, commonPrefix) {
+					continue
+				}
+			}
+		}
+		if result.KeyCount == result.MaxKeys {
+			result.IsTruncated = true
+			if lastEntry != "" {
+				result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(lastEntry))
+			}
+			break
+		}
+
+		result.KeyCount++
+		if commonPrefix != "" {
+			lastEntry = commonPrefix
+			result.CommonPrefixes = append(result.CommonPrefixes, S3CommonPrefix{Prefix: h.encodeKey(result.EncodingType, commonPrefix)})
+			continue
+		}
+		lastEntry = file.Name
+		result.Contents = append(result.Contents, S3Object{
+			Key:          h.encodeKey(result.EncodingType, file.Name),
+			LastModified: file.UpdatedAt.UTC().Format(S3TimeFormat),
+			ETag:         s3ETag(file.Checksum),
+			Size:         file.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+	if result.EncodingType != "" {
+		result.Prefix = h.encodeKey(result.EncodingType, result.Prefix)
+		result.Delimiter = h.encodeKey(result.EncodingType, result.Delimiter)
+		result.StartAfter = h.encodeKey(result.EncodingType, result.StartAfter)
+	}
+
+	ctx.XML(http.StatusOK, result)
+}
+
+// ExecuteGet reads an object (GetObject), or lists the objects when the key is empty
+func (h *FileS3Handler) ExecuteGet(ctx *gin.Context) {
+	key := s3Key(ctx)
+	if key == "" {
+		h.ExecuteListObjects(ctx)
+		return
+	}
+	h.getObject(ctx, key, true)
+}
+
+// ExecuteHead returns the metadata of an object (HeadObject), or tells the bucket exists when the key is empty
+func (h *FileS3Handler) ExecuteHead(ctx *gin.Context) {
+	key := s3Key(ctx)
+	if key == "" {
+		h.ExecuteHeadBucket(ctx)
+		return
+	}
+	h.getObject(ctx, key, false)
+}
+
+// ExecutePut uploads a part of a multipart upload (UploadPart) when the upload ID is given, and an object (PutObject)
+// otherwise
+func (h *FileS3Handler) ExecutePut(ctx *gin.Context) {
+	key := s3Key(ctx)
+	if !isValidFileName(key) {
+		h.sendS3Error(ctx, http.StatusBadRequest, "InvalidArgument", fmt.Sprintf("key %q needs to be a file name without slashes", key))
+		return
+	}
+	if ctx.GetHeader("X-Amz-Copy-Source") != "" {
+		h.sendS3Error(ctx, http.StatusNotImplemented, "NotImplemented", "copying objects is not supported")
+		return
+	}
+	if ctx.Request.ContentLength < 0 {
+		h.sendS3Error(ctx, http.StatusLengthRequired, "MissingContentLength", "Content-Length header is required")
+		return
+	}
+	if ctx.Request.ContentLength == 0 {
+		h.sendS3Error(ctx, http.StatusBadRequest, "InvalidArgument", "empty objects are not supported")
+		return
+	}
+
+	if uploadID := ctx.Query("uploadId"); uploadID != "" {
+		h.uploadPart(ctx, key, uploadID)
+		return
+	}
+	h.putObject(ctx, key)
+}
+
+// ExecutePost creates a multipart upload (CreateMultipartUpload) or completes it (CompleteMultipartUpload)
+func (h *FileS3Handler) ExecutePost(ctx *gin.Context) {
+	key := s3Key(ctx)
+	if !isValidFileName(key) {
+		h.sendS3Error(ctx, http.StatusBadRequest, "InvalidArgument", fmt.Sprintf("key %q needs to be a file name without slashes", key))
+		return
+	}
+
+	if _, ok := ctx.GetQuery("uploads"); ok {
+		h.createMultipartUpload(ctx, key)
+		return
+	}
+	if uploadID := ctx.Query("uploadId"); uploadID != "" {
+		h.completeMultipartUpload(ctx, key, uploadID)
+		return
+	}
+	h.sendS3Error(ctx, http.StatusNotImplemented, "NotImplemented", "only the multipart upload operations are supported by POST")
+}
+
+// ExecuteDelete aborts a multipart upload (AbortMultipartUpload) when the upload ID is given, and deletes an object
+// (DeleteObject) otherwise. Deleting an object which doesn't exist succeeds as S3 does
+func (h *FileS3Handler) ExecuteDelete(ctx *gin.Context) {
+	key := s3Key(ctx)
+	reqCtx := ctx.Request.Context()
+
+	if uploadID := ctx.Query("uploadId"); uploadID != "" {
+		if err := h.fileUploadUseCase.ExecuteAbortMultipart(reqCtx, uploadID); err != nil {
+			h.sendUseCaseError(ctx, err, "NoSuchUpload")
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+
+	if err := h.deleteObject(reqCtx, key); err != nil {
+		h.sendUseCaseError(ctx, err, "NoSuchKey")
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getObject sends the metadata of an object and its content if told to. Only a single range is served partially, as
+// S3 does
+func (h *FileS3Handler) getObject(ctx *gin.Context, key string, withContent bool) {
+	reqCtx := ctx.Request.Context()
+	file, err := h.fileGetUseCase.ExecuteGetContent(reqCtx, key)
+	if err != nil {
+		h.sendUseCaseError(ctx, err, "NoSuchKey")
+		return
+	}
+
+	ctx.Header("ETag", s3ETag(file.Checksum))
+	ctx.Header("Last-Modified", file.UpdatedAt.UTC().Format(http.TimeFormat))
+	ctx.Header("Accept-Ranges", "bytes")
+	ctx.Header("Content-Type", "application/octet-stream")
+
+	statusCode := http.StatusOK
+	start, length := uint64(0), file.Size
+	if rangeHeader := ctx.GetHeader("Range"); rangeHeader != "" {
+		ranges, parseErr := parseRange(rangeHeader, file.Size)
+		if errors.Is(parseErr, errNoOverlap) {
+			ctx.Header("Content-Range", fmt.Sprintf("bytes */%d", file.Size))
+			h.sendS3Error(ctx, http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "the requested range is not satisfiable")
+			return
+		}
+		// a malformed range or multiple ranges are ignored
+		if parseErr == nil && len(ranges) == 1 {
+			statusCode = http.StatusPartialContent
+			start, length = ranges[0].start, ranges[0].length
+			ctx.Header("Content-Range", ranges[0].contentRange(file.Size))
+		}
+	}
+	ctx.Header("Content-Length", strconv.FormatUint(length, 10))
+	ctx.Status(statusCode)
+	if !withContent {
+		return
+	}
+
+	if err := h.fileGetUseCase.ExecuteStreamContent(reqCtx, file, ctx.Writer, start, length); err != nil {
+		if !ctx.Writer.Written() {
+			ctx.Writer.Header().Del("Content-Length")
+			ctx.Writer.Header().Del("Content-Range")
+			h.sendUseCaseError(ctx, err, "NoSuchKey")
+			return
+		}
+		// the client detects the failure by the content length mismatch once the connection is closed
+		h.logger.Error("failed to stream object content", "key", key, "error", err.Error(), "code", err.ErrorCode())
+	}
+}
+
+// putObject stores the body as the content of the object, replacing the existing one. The content is appended to a
+// new file as the tus uploads are, so the chunk size is chosen in the same way. The existing object stays readable
+// until the new one is committed
+func (h *FileS3Handler) putObject(ctx *gin.Context, key string) {
+	reqCtx := ctx.Request.Context()
+	totalSize := uint64(ctx.Request.ContentLength)
+	limits := h.config.UploadLimits()
+	chunkSize := limits.ChunkSizeFor(totalSize, h.config.TusChunkSize)
+	totalChunks := (totalSize + chunkSize - 1) / chunkSize
+	if err := limits.Validate(totalSize, chunkSize, totalChunks); err != nil {
+		h.sendS3Error(ctx, http.StatusBadRequest, "EntityTooLarge", err.Error())
+		return
+	}
+	checksumHash, expectedChecksum, parseErr := h.bodyChecksum(ctx)
+	if parseErr != nil {
+		h.sendS3Error(ctx, http.StatusBadRequest, "InvalidDigest", parseErr.Error())
+		return
+	}
+	// the signed SHA-256 is also verified against the stored content
+	declaredChecksum := ""
+	if payloadHash := ctx.GetString(s3PayloadHashKey); payloadHash != sigv4.UnsignedPayload {
+		declaredChecksum = strings.ToLower(payloadHash)
+	}
+
+	session, _, err := h.fileUploadUseCase.ExecuteInit(reqCtx, usecase.FileUploadUseCaseExecuteInitInput{
+		FileName:          key,
+		Checksum:          declaredChecksum,
+		ChecksumAlgorithm: string(checksum.DefaultAlgorithm),
+		TotalSize:         totalSize,
+		TotalChunks:       uint(totalChunks),
+		ChunkSize:         chunkSize,
+		Owner:             ctx.GetString(s3AccessKeyIDKey),
+	})
+	if err != nil {
+		h.sendUseCaseError(ctx, err, "NoSuchKey")
+		return
+	}
+	offset, err := h.fileUploadUseCase.ExecuteAppend(reqCtx, usecase.FileUploadUseCaseExecuteAppendInput{
+		SessionToken: session.Token,
+		Offset:       0,
+		Reader:       ctx.Request.Body,
+		ChecksumHash: checksumHash,
+		Checksum:     expectedChecksum,
+	})
+	if err == nil && offset != totalSize {
+		err = e.NewInvalidInputError(fmt.Errorf("expected %d bytes, but got %d", totalSize, offset), "incomplete body")
+	}
+	if err != nil {
+		// an object is either fully stored or not at all
+		if abortErr := h.fileUploadUseCase.ExecuteAbort(context.WithoutCancel(reqCtx), session.Token); abortErr != nil {
+			h.logger.Error("failed to abort incomplete object upload", "key", key, "error", abortErr.Error())
+		}
+		h.sendUseCaseError(ctx, err, "NoSuchKey")
+		return
+	}
+
+	stats, err := h.fileGetUseCase.ExecuteGetStats(reqCtx, key)
+	if err != nil {
+		h.sendUseCaseError(ctx, err, "NoSuchKey")
+		return
+	}
+	if stats != nil {
+		ctx.Header("ETag", s3ETag(stats.Checksum))
+	}
+	ctx.Status(http.StatusOK)
+}
+
+// uploadPart stores the body as a part of a multipart upload. A part becomes a chunk of the file, so it's limited by
+// the maximum chunk size and count
+func (h *FileS3Handler) uploadPart(ctx *gin.Context, key string, uploadID string) {
+	partNumber, err := strconv.ParseUint(ctx.Query("partNumber"), 10, 64)
+	if err != nil || partNumber == 0 || partNumber > s3MaxPartNumber {
+		h.sendS3Error(ctx, http.StatusBadRequest, "InvalidArgument", fmt.Sprintf("part number needs to be an integer between 1 and %d", s3MaxPartNumber))
+		return
+	}
+	limits := h.config.UploadLimits()
+	if limits.MaxChunkCount > 0 && partNumber > limits.MaxChunkCount {
+		h.sendS3Error(ctx, http.StatusBadRequest, "InvalidArgument", fmt.Sprintf("part number %d exceeds the maximum %d", partNumber, limits.MaxChunkCount))
+		return
+	}
+	if limits.MaxChunkSize > 0 && uint64(ctx.Request.ContentLength) > limits.MaxChunkSize {
+		h.sendS3Error(ctx, http.StatusBadRequest, "EntityTooLarge", fmt.Sprintf("part size %d exceeds the maximum %d", ctx.Request.ContentLength, limits.MaxChunkSize))
+		return
+	}
+	partChecksum := ""
+	if payloadHash := ctx.GetString(s3PayloadHashKey); payloadHash != sigv4.UnsignedPayload {
+		partChecksum = payloadHash
+	}
+
+	part, ucErr := h.fileUploadUseCase.ExecuteUploadPart(ctx.Request.Context(), usecase.FileUploadUseCaseExecuteUploadPartInput{
+		FileName:   key,
+		UploadID:   uploadID,
+		PartNumber: partNumber,
+		Reader:     ctx.Request.Body,
+		Checksum:   partChecksum,
+	})
+	if ucErr != nil {
+		h.sendUseCaseError(ctx, ucErr, "NoSuchUpload")
+		return
+	}
+
+	ctx.Header("ETag", s3ETag(part.Checksum))
+	ctx.Status(http.StatusOK)
+}
+
+// createMultipartUpload starts a multipart upload of the object
+func (h *FileS3Handler) createMultipartUpload(ctx *gin.Context, key string) {
+	upload, err := h.fileUploadUseCase.ExecuteCreateMultipart(ctx.Request.Context(), key)
+	if err != nil {
+		h.sendUseCaseError(ctx, err, "NoSuchUpload")
+		return
+	}
+
+	ctx.XML(http.StatusOK, InitiateMultipartUploadResult{
+		Xmlns:    S3Namespace,
+		Bucket:   h.config.S3Bucket,
+		Key:      key,
+		UploadID: upload.ID,
+	})
+}
+
+// completeMultipartUpload assembles the listed parts into the object, replacing the existing one once the assembled
+// object is verified
+func (h *FileS3Handler) completeMultipartUpload(ctx *gin.Context, key string, uploadID string) {
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, s3MaxCompleteBodySize+1))
+	if h.sendChunkedBodyError(ctx) {
+		return
+	}
+	if err != nil {
+		h.sendS3Error(ctx, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+	if len(body) > s3MaxCompleteBodySize {
+		h.sendS3Error(ctx, http.StatusBadRequest, "MalformedXML", "the list of the parts is too large")
+		return
+	}
+	if payloadHash := ctx.GetString(s3PayloadHashKey); payloadHash != sigv4.UnsignedPayload {
+		if actual := sha256.Sum256(body); !strings.EqualFold(hex.EncodeToString(actual[:]), payloadHash) {
+			h.sendS3Error(ctx, http.StatusBadRequest, "XAmzContentSHA256Mismatch", "the body doesn't match the signed payload hash")
+			return
+		}
+	}
+	var req CompleteMultipartUploadRequest
+	if err := xml.Unmarshal(body, &req); err != nil {
+		h.sendS3Error(ctx, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+
+	input := usecase.FileUploadUseCaseExecuteCompleteMultipartInput{
+		FileName: key,
+		UploadID: uploadID,
+		Parts:    make([]entity.MultipartPart, len(req.Parts)),
+		Limits:   h.config.UploadLimits(),
+		Owner:    ctx.GetString(s3AccessKeyIDKey),
+	}
+	for i, part := range req.Parts {
+		input.Parts[i] = entity.MultipartPart{PartNumber: part.PartNumber, Checksum: strings.Trim(part.ETag, `"`)}
+	}
+
+	file, ucErr := h.fileUploadUseCase.ExecuteCompleteMultipart(ctx.Request.Context(), input)
+	if ucErr != nil {
+		if ucErr.ErrorCode() == "INVALID_INPUT" {
+			h.sendS3Error(ctx, http.StatusBadRequest, "InvalidPart", ucErr.Error())
+			return
+		}
+		h.sendUseCaseError(ctx, ucErr, "NoSuchUpload")
+		return
+	}
+
+	scheme := "http"
+	if ctx.Request.TLS != nil {
+		scheme = "https"
+	}
+	ctx.XML(http.StatusOK, CompleteMultipartUploadResult{
+		Xmlns:    S3Namespace,
+		Location: fmt.Sprintf("%s://%s%s", scheme, ctx.Request.Host, ctx.Request.URL.EscapedPath()),
+		Bucket:   h.config.S3Bucket,
+		Key:      key,
+		ETag:     s3ETag(file.Checksum),
+	})
+}
+
+// deleteObject deletes the object if it exists
+func (h *FileS3Handler) deleteObject(ctx context.Context, key string) e.CustomError {
+	if err := h.fileDeleteUseCase.Execute(ctx, key); err != nil && err.ErrorCode() != "NOT_FOUND" {
+		return err
+	}
+	return nil
+}
+
+// bodyChecksum returns the hash and the expected checksum of the body, which is the signed SHA-256 or the MD5 of the
+// Content-MD5 header. Nothing is returned when neither of them is given
+func (h *FileS3Handler) bodyChecksum(ctx *gin.Context) (hash.Hash, []byte, error) {
+	if payloadHash := ctx.GetString(s3PayloadHashKey); payloadHash != sigv4.UnsignedPayload {
+		decoded, err := hex.DecodeString(payloadHash)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sha256.New(), decoded, nil
+	}
+	if contentMD5 := ctx.GetHeader("Content-MD5"); contentMD5 != "" {
+		decoded, err := base64.StdEncoding.DecodeString(contentMD5)
+		if err != nil || len(decoded) != md5.Size {
+			return nil, nil, fmt.Errorf("invalid Content-MD5 %q", contentMD5)
+		}
+		return md5.New(), decoded, nil
+	}
+	return nil, nil, nil
+}
+
+// encodeKey encodes the key for the encoding type of the listing
+func (h *FileS3Handler) encodeKey(encodingType string, key string) string {
+	if encodingType == "url" {
+		return url.QueryEscape(key)
+	}
+	return key
+}
+
+// sendUseCaseError sends the error of a use case with the S3 error code for it. Which resource is not found depends
+// on the operation, so it's given by the caller
+func (h *FileS3Handler) sendUseCaseError(ctx *gin.Context, err e.CustomError, notFoundCode string) {
+	// the use case fails to read an aws-chunked body which turns out to be malformed or forged, which is reported instead
+	if h.sendChunkedBodyError(ctx) {
+		return
+	}
+	switch err.ErrorCode() {
+	case "NOT_FOUND":
+		h.sendS3Error(ctx, http.StatusNotFound, notFoundCode, err.Error())
+	case "INVALID_INPUT":
+		h.sendS3Error(ctx, http.StatusBadRequest, "InvalidArgument", err.Error())
+	case "CHECKSUM_MISMATCH":
+		h.sendS3Error(ctx, http.StatusBadRequest, "BadDigest", err.Error())
+	case "CONFLICT":
+		h.sendS3Error(ctx, http.StatusConflict, "OperationAborted", err.Error())
+	default:
+		h.sendS3Error(ctx, http.StatusInternalServerError, "InternalError", err.Error())
+	}
+}
+
+// sendChunkedBodyError sends the error of the aws-chunked body of the request when the body turned out to be malformed
+// or forged while it was read, and reports whether it's sent. A body cut off by the client is left to the caller
+func (h *FileS3Handler) sendChunkedBodyError(ctx *gin.Context) bool {
+	reader, ok := ctx.Value(s3ChunkedReaderKey).(*sigv4.ChunkedReader)
+	if !ok {
+		return false
+	}
+	err := reader.Err()
+	if !errors.Is(err, sigv4.ErrMalformedChunkedBody) && !errors.Is(err, sigv4.ErrTrailerChecksumMismatch) &&
+		!errors.Is(err, sigv4.ErrSignatureDoesNotMatch) {
+		return false
+	}
+
+	statusCode, code := s3AuthErrorCode(err)
+	h.sendS3Error(ctx, statusCode, code, err.Error())
+	return true
+}
+
+// sendS3Error sends an error response of the S3 API
+func (h *FileS3Handler) sendS3Error(ctx *gin.Context, statusCode int, code string, message string) {
+	h.logger.Error("error",
+		"error", message,
+		"code", code,
+		"status_code", statusCode,
+	)
+
+	ctx.XML(statusCode, S3ErrorResponse{
+		Code:     code,
+		Message:  message,
+		Resource: ctx.Request.URL.Path,
+	})
+}
+
+// s3AuthErrorCode returns the status code and the S3 error code for an error of the signature verification, including
+// the verification of an aws-chunked body
+func s3AuthErrorCode(err error) (int, string) {
+	switch {
+	case errors.Is(err, sigv4.ErrMalformedChunkedBody):
+		return http.StatusBadRequest, "InvalidRequest"
+	case errors.Is(err, sigv4.ErrTrailerChecksumMismatch):
+		return http.StatusBadRequest, "BadDigest"
+	case errors.Is(err, sigv4.ErrMalformedAuthorization):
+		return http.StatusBadRequest, "AuthorizationHeaderMalformed"
+	case errors.Is(err, sigv4.ErrInvalidAccessKeyID):
+		return http.StatusForbidden, "InvalidAccessKeyId"
+	case errors.Is(err, sigv4.ErrSignatureDoesNotMatch):
+		return http.StatusForbidden, "SignatureDoesNotMatch"
+	case errors.Is(err, sigv4.ErrRequestTimeTooSkewed):
+		return http.StatusForbidden, "RequestTimeTooSkewed"
+	default:
+		return http.StatusForbidden, "AccessDenied"
+	}
+}
+
+// s3Key returns the key of the object in the URL
+func s3Key(ctx *gin.Context) string {
+	return strings.TrimPrefix(ctx.Param("key"), "/")
+}
+
+// s3ETag returns the entity tag of an object or a part, which is its checksum
+func s3ETag(checksum string) string {
+	return `"` + checksum + `"`
+}
@@ -0,0 +1,857 @@
+package handler_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/interface/api/handler"
+	"github.com/tomoya.tokunaga/server/internal/mock"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"github.com/tomoya.tokunaga/server/internal/util/sigv4"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	testS3AccessKeyID     = "test-access-key"
+	testS3SecretAccessKey = "test-secret-key"
+	testS3Bucket          = "test-bucket"
+	testS3Region          = "us-east-1"
+)
+
+// setupTestFileS3Handler is a helper function to set up the S3 routes with the mocked use cases
+func setupTestFileS3Handler(
+	t *testing.T,
+	uploadUseCase usecase.FileUploadUseCase,
+	getUseCase usecase.FileGetUseCase,
+	deleteUseCase usecase.FileDeleteUseCase,
+) *gin.Engine {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	config := &entity.Config{
+		MaxFileSize:   1024 * 1024,
+		MaxChunkSize:  4096,
+		MaxChunkCount: 8,
+		TusChunkSize:  1024,
+		S3Bucket:      testS3Bucket,
+		S3Region:      testS3Region,
+		S3Credentials: map[string]string{testS3AccessKeyID: testS3SecretAccessKey},
+	}
+	h := handler.NewFileS3Handler(uploadUseCase, getUseCase, deleteUseCase, config, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	s3 := router.Group("/s3", h.Authenticate)
+	s3.HEAD("/:bucket", h.ExecuteHeadBucket)
+	s3.GET("/:bucket", h.ExecuteListObjects)
+	s3.HEAD("/:bucket/*key", h.ExecuteHead)
+	s3.GET("/:bucket/*key", h.ExecuteGet)
+	s3.PUT("/:bucket/*key", h.ExecutePut)
+	s3.POST("/:bucket/*key", h.ExecutePost)
+	s3.DELETE("/:bucket/*key", h.ExecuteDelete)
+
+	return router
+}
+
+// newSignedS3Request returns a request signed with the test credential, whose payload hash is the SHA-256 of the body
+func newSignedS3Request(method string, target string, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	hash := sha256.Sum256([]byte(body))
+	sigv4.Sign(req, testS3AccessKeyID, testS3SecretAccessKey, testS3Region, "s3", hex.EncodeToString(hash[:]), time.Now())
+	return req
+}
+
+// newChunkedS3Request returns a PUT request signed with the test credential, whose body is the content encoded in the
+// aws-chunked encoding with the CRC32 checksum in the trailer as the AWS SDKs send it over HTTPS
+func newChunkedS3Request(target string, content string, crc32Checksum string) *http.Request {
+	body := fmt.Sprintf("%x\r\n%s\r\n0\r\nx-amz-checksum-crc32:%s\r\n\r\n", len(content), content, crc32Checksum)
+	req := httptest.NewRequest(http.MethodPut, target, strings.NewReader(body))
+	req.Header.Set("Content-Encoding", "aws-chunked")
+	req.Header.Set(sigv4.DecodedContentLengthHeader, strconv.Itoa(len(content)))
+	req.Header.Set(sigv4.TrailerHeader, "x-amz-checksum-crc32")
+	sigv4.Sign(req, testS3AccessKeyID, testS3SecretAccessKey, testS3Region, "s3", sigv4.StreamingUnsignedPayloadTrailer, time.Now())
+	return req
+}
+
+func sha256Hex(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:])
+}
+
+func TestFileS3Handler_Authenticate(t *testing.T) {
+	testCases := []struct {
+		name           string
+		request        func() *http.Request
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:           "Success - Head bucket",
+			request:        func() *http.Request { return newSignedS3Request(http.MethodHead, "/s3/"+testS3Bucket, "") },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Error - Not signed",
+			request: func() *http.Request {
+				return httptest.NewRequest(http.MethodGet, "/s3/"+testS3Bucket+"?list-type=2", nil)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "AccessDenied",
+		},
+		{
+			name: "Error - Unknown access key ID",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/s3/"+testS3Bucket+"?list-type=2", nil)
+				sigv4.Sign(req, "unknown", testS3SecretAccessKey, testS3Region, "s3", sigv4.UnsignedPayload, time.Now())
+				return req
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "InvalidAccessKeyId",
+		},
+		{
+			name: "Error - Wrong secret",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/s3/"+testS3Bucket+"?list-type=2", nil)
+				sigv4.Sign(req, testS3AccessKeyID, "wrong", testS3Region, "s3", sigv4.UnsignedPayload, time.Now())
+				return req
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "SignatureDoesNotMatch",
+		},
+		{
+			name: "Error - Too skewed",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/s3/"+testS3Bucket+"?list-type=2", nil)
+				sigv4.Sign(req, testS3AccessKeyID, testS3SecretAccessKey, testS3Region, "s3", sigv4.UnsignedPayload, time.Now().Add(-time.Hour))
+				return req
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "RequestTimeTooSkewed",
+		},
+		{
+			name: "Error - Unsupported streaming payload",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPut, "/s3/"+testS3Bucket+"/testfile.txt", strings.NewReader("hello"))
+				sigv4.Sign(req, testS3AccessKeyID, testS3SecretAccessKey, testS3Region, "s3", "STREAMING-AWS4-ECDSA-P256-SHA256-PAYLOAD", time.Now())
+				return req
+			},
+			expectedStatus: http.StatusNotImplemented,
+			expectedCode:   "NotImplemented",
+		},
+		{
+			name: "Error - Streaming payload without decoded length",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPut, "/s3/"+testS3Bucket+"/testfile.txt", strings.NewReader("5\r\nhello\r\n0\r\n\r\n"))
+				sigv4.Sign(req, testS3AccessKeyID, testS3SecretAccessKey, testS3Region, "s3", sigv4.StreamingUnsignedPayloadTrailer, time.Now())
+				return req
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "InvalidRequest",
+		},
+		{
+			name:           "Error - Unknown bucket",
+			request:        func() *http.Request { return newSignedS3Request(http.MethodHead, "/s3/other-bucket", "") },
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "NoSuchBucket",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			router := setupTestFileS3Handler(t, mock.NewMockFileUploadUseCase(mockCtrl), mock.NewMockFileGetUseCase(mockCtrl), mock.NewMockFileDeleteUseCase(mockCtrl))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tc.request())
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedCode != "" {
+				assert.Contains(t, w.Body.String(), "<Code>"+tc.expectedCode+"</Code>")
+			}
+		})
+	}
+}
+
+func TestFileS3Handler_ExecutePut(t *testing.T) {
+	testContent := "hello world"
+	testFile := &entity.File{ID: 1, Name: "testfile.txt", Size: uint64(len(testContent)), Checksum: sha256Hex(testContent)}
+	testSession := &entity.UploadSession{Token: "token", TargetName: "testfile.txt", File: testFile}
+	notFoundError := e.NewNotFoundError(errors.New("not found"), "file not found")
+	mockUsecaseError := e.NewMockCustomError(errors.New("usecase append error"), "Usecase append error", http.StatusInternalServerError)
+
+	testCases := []struct {
+		name           string
+		request        func() *http.Request
+		setupMock      func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase)
+		expectedStatus int
+		expectedCode   string
+		expectedETag   string
+	}{
+		{
+			name: "Success - Put object",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPut, "/s3/"+testS3Bucket+"/testfile.txt", testContent)
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase) {
+				upload.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: "testfile.txt", Checksum: sha256Hex(testContent), ChecksumAlgorithm: "sha256",
+					TotalSize: 11, TotalChunks: 1, ChunkSize: 1024, Owner: testS3AccessKeyID,
+				}).Return(testSession, nil, nil)
+				upload.EXPECT().ExecuteAppend(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, input usecase.FileUploadUseCaseExecuteAppendInput) (uint64, e.CustomError) {
+						assert.Equal(t, testSession.Token, input.SessionToken)
+						body, _ := io.ReadAll(input.Reader)
+						assert.Equal(t, testContent, string(body))
+						assert.Equal(t, sha256Hex(testContent), hex.EncodeToString(input.Checksum))
+						return uint64(len(body)), nil
+					})
+				get.EXPECT().ExecuteGetStats(gomock.Any(), "testfile.txt").Return(testFile, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"` + testFile.Checksum + `"`,
+		},
+		{
+			name: "Success - Put object with aws-chunked body",
+			request: func() *http.Request {
+				return newChunkedS3Request("/s3/"+testS3Bucket+"/testfile.txt", testContent, "DUoRhQ==")
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase) {
+				// the content is verified by the checksum in the trailer instead of the payload hash
+				upload.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: "testfile.txt", ChecksumAlgorithm: "sha256",
+					TotalSize: 11, TotalChunks: 1, ChunkSize: 1024, Owner: testS3AccessKeyID,
+				}).Return(testSession, nil, nil)
+				upload.EXPECT().ExecuteAppend(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, input usecase.FileUploadUseCaseExecuteAppendInput) (uint64, e.CustomError) {
+						body, err := io.ReadAll(input.Reader)
+						assert.NoError(t, err)
+						assert.Equal(t, testContent, string(body))
+						assert.Nil(t, input.Checksum)
+						return uint64(len(body)), nil
+					})
+				get.EXPECT().ExecuteGetStats(gomock.Any(), "testfile.txt").Return(testFile, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"` + testFile.Checksum + `"`,
+		},
+		{
+			name: "Error - Trailer checksum mismatch and the upload is aborted",
+			request: func() *http.Request {
+				return newChunkedS3Request("/s3/"+testS3Bucket+"/testfile.txt", testContent, "AAAAAA==")
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase) {
+				upload.EXPECT().ExecuteInit(gomock.Any(), gomock.Any()).Return(testSession, nil, nil)
+				upload.EXPECT().ExecuteAppend(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, input usecase.FileUploadUseCaseExecuteAppendInput) (uint64, e.CustomError) {
+						_, err := io.ReadAll(input.Reader)
+						return 0, e.NewInvalidInputError(err, "failed to read content")
+					})
+				upload.EXPECT().ExecuteAbort(gomock.Any(), testSession.Token).Return(nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "BadDigest",
+		},
+		{
+			name: "Error - Append fails and the upload is aborted",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPut, "/s3/"+testS3Bucket+"/testfile.txt", testContent)
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase) {
+				upload.EXPECT().ExecuteInit(gomock.Any(), gomock.Any()).Return(testSession, nil, nil)
+				upload.EXPECT().ExecuteAppend(gomock.Any(), gomock.Any()).Return(uint64(0), mockUsecaseError)
+				upload.EXPECT().ExecuteAbort(gomock.Any(), testSession.Token).Return(nil)
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "InternalError",
+		},
+		{
+			name: "Error - Key with slash",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPut, "/s3/"+testS3Bucket+"/dir/testfile.txt", testContent)
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase) {
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "InvalidArgument",
+		},
+		{
+			name: "Error - Empty object",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPut, "/s3/"+testS3Bucket+"/testfile.txt", "")
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase) {
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "InvalidArgument",
+		},
+		{
+			name: "Error - Copy object",
+			request: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPut, "/s3/"+testS3Bucket+"/testfile.txt", strings.NewReader(testContent))
+				req.Header.Set("X-Amz-Copy-Source", "/"+testS3Bucket+"/other.txt")
+				sigv4.Sign(req, testS3AccessKeyID, testS3SecretAccessKey, testS3Region, "s3", sha256Hex(testContent), time.Now())
+				return req
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase) {
+			},
+			expectedStatus: http.StatusNotImplemented,
+			expectedCode:   "NotImplemented",
+		},
+		{
+			name: "Success - Upload part",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPut, "/s3/"+testS3Bucket+"/testfile.txt?partNumber=2&uploadId=abc", "world")
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase) {
+				upload.EXPECT().ExecuteUploadPart(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(_ any, input usecase.FileUploadUseCaseExecuteUploadPartInput) (*entity.MultipartPart, e.CustomError) {
+						assert.Equal(t, "testfile.txt", input.FileName)
+						assert.Equal(t, "abc", input.UploadID)
+						assert.Equal(t, uint64(2), input.PartNumber)
+						assert.Equal(t, sha256Hex("world"), input.Checksum)
+						return &entity.MultipartPart{PartNumber: 2, Size: 5, Checksum: sha256Hex("world")}, nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"` + sha256Hex("world") + `"`,
+		},
+		{
+			name: "Error - Upload part of unknown upload",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPut, "/s3/"+testS3Bucket+"/testfile.txt?partNumber=1&uploadId=abc", "world")
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase) {
+				upload.EXPECT().ExecuteUploadPart(gomock.Any(), gomock.Any()).Return(nil, notFoundError)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "NoSuchUpload",
+		},
+		{
+			name: "Error - Part number beyond chunk count",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPut, "/s3/"+testS3Bucket+"/testfile.txt?partNumber=9&uploadId=abc", "world")
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase) {
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "InvalidArgument",
+		},
+		{
+			name: "Error - Part too large",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPut, "/s3/"+testS3Bucket+"/testfile.txt?partNumber=1&uploadId=abc", strings.Repeat("a", 4097))
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, get *mock.MockFileGetUseCase, del *mock.MockFileDeleteUseCase) {
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "EntityTooLarge",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			upload, get, del := mock.NewMockFileUploadUseCase(mockCtrl), mock.NewMockFileGetUseCase(mockCtrl), mock.NewMockFileDeleteUseCase(mockCtrl)
+			tc.setupMock(upload, get, del)
+			router := setupTestFileS3Handler(t, upload, get, del)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tc.request())
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedCode != "" {
+				assert.Contains(t, w.Body.String(), "<Code>"+tc.expectedCode+"</Code>")
+			}
+			if tc.expectedETag != "" {
+				assert.Equal(t, tc.expectedETag, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestFileS3Handler_ExecutePost(t *testing.T) {
+	completeBody := `<CompleteMultipartUpload>` +
+		`<Part><PartNumber>1</PartNumber><ETag>"` + sha256Hex("hello ") + `"</ETag></Part>` +
+		`<Part><PartNumber>2</PartNumber><ETag>"` + sha256Hex("world") + `"</ETag></Part>` +
+		`</CompleteMultipartUpload>`
+	expectedParts := []entity.MultipartPart{
+		{PartNumber: 1, Checksum: sha256Hex("hello ")},
+		{PartNumber: 2, Checksum: sha256Hex("world")},
+	}
+	testFile := &entity.File{ID: 1, Name: "testfile.txt", Size: 11, Checksum: sha256Hex("hello world")}
+
+	testCases := []struct {
+		name           string
+		request        func() *http.Request
+		setupMock      func(upload *mock.MockFileUploadUseCase, del *mock.MockFileDeleteUseCase)
+		expectedStatus int
+		expectedCode   string
+		expectedBody   string
+	}{
+		{
+			name: "Success - Create multipart upload",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPost, "/s3/"+testS3Bucket+"/testfile.txt?uploads", "")
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, del *mock.MockFileDeleteUseCase) {
+				upload.EXPECT().ExecuteCreateMultipart(gomock.Any(), "testfile.txt").Return(entity.NewMultipartUpload("abc", "testfile.txt"), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "<UploadId>abc</UploadId>",
+		},
+		{
+			name: "Success - Complete multipart upload replacing the existing object",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPost, "/s3/"+testS3Bucket+"/testfile.txt?uploadId=abc", completeBody)
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, del *mock.MockFileDeleteUseCase) {
+				upload.EXPECT().ExecuteCompleteMultipart(gomock.Any(), usecase.FileUploadUseCaseExecuteCompleteMultipartInput{
+					FileName: "testfile.txt",
+					UploadID: "abc",
+					Parts:    expectedParts,
+					Limits:   entity.UploadLimits{MaxFileSize: 1024 * 1024, MaxChunkSize: 4096, MaxChunkCount: 8},
+					Owner:    testS3AccessKeyID,
+				}).Return(testFile, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "<ETag>&#34;" + testFile.Checksum + "&#34;</ETag>",
+		},
+		{
+			name: "Error - Invalid part",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPost, "/s3/"+testS3Bucket+"/testfile.txt?uploadId=abc", completeBody)
+			},
+			setupMock: func(upload *mock.MockFileUploadUseCase, del *mock.MockFileDeleteUseCase) {
+				upload.EXPECT().ExecuteCompleteMultipart(gomock.Any(), gomock.Any()).
+					Return(nil, e.NewInvalidInputError(errors.New("part 2 is not uploaded"), "invalid parts"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "InvalidPart",
+		},
+		{
+			name: "Error - Malformed XML",
+			request: func() *http.Request {
+				return newSignedS3Request(http.MethodPost, "/s3/"+testS3Bucket+"/testfile.txt?uploadId=abc", "<CompleteMultipartUpload>")
+			},
+			setupMock:      func(upload *mock.MockFileUploadUseCase, del *mock.MockFileDeleteUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "MalformedXML",
+		},
+		{
+			name: "Error - Body not matching the payload hash",
+			request: func() *http.Request {
+				req := newSignedS3Request(http.MethodPost, "/s3/"+testS3Bucket+"/testfile.txt?uploadId=abc", completeBody)
+				req.Body = io.NopCloser(strings.NewReader(strings.Replace(completeBody, "<PartNumber>2", "<PartNumber>3", 1)))
+				return req
+			},
+			setupMock:      func(upload *mock.MockFileUploadUseCase, del *mock.MockFileDeleteUseCase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "XAmzContentSHA256Mismatch",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			upload, del := mock.NewMockFileUploadUseCase(mockCtrl), mock.NewMockFileDeleteUseCase(mockCtrl)
+			tc.setupMock(upload, del)
+			router := setupTestFileS3Handler(t, upload, mock.NewMockFileGetUseCase(mockCtrl), del)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tc.request())
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedCode != "" {
+				assert.Contains(t, w.Body.String(), "<Code>"+tc.expectedCode+"</Code>")
+			}
+			if tc.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tc.expectedBody)
+			}
+		})
+	}
+}
+
+func TestFileS3Handler_ExecuteGet(t *testing.T) {
+	testContent := "hello world"
+	testFile := &entity.File{
+		ID:        1,
+		Name:      "testfile.txt",
+		Size:      uint64(len(testContent)),
+		Checksum:  sha256Hex(testContent),
+		UpdatedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	streamRange := func(_ any, _ *entity.File, writer io.Writer, offset uint64, length uint64) e.CustomError {
+		_, _ = writer.Write([]byte(testContent[offset : offset+length]))
+		return nil
+	}
+
+	testCases := []struct {
+		name                 string
+		method               string
+		rangeHeader          string
+		setupMock            func(get *mock.MockFileGetUseCase)
+		expectedStatus       int
+		expectedBody         string
+		expectedContentRange string
+		expectedCode         string
+	}{
+		{
+			name:   "Success - Get object",
+			method: http.MethodGet,
+			setupMock: func(get *mock.MockFileGetUseCase) {
+				get.EXPECT().ExecuteGetContent(gomock.Any(), "testfile.txt").Return(testFile, nil)
+				get.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(0), testFile.Size).DoAndReturn(streamRange)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testContent,
+		},
+		{
+			name:        "Success - Get range of object",
+			method:      http.MethodGet,
+			rangeHeader: "bytes=6-",
+			setupMock: func(get *mock.MockFileGetUseCase) {
+				get.EXPECT().ExecuteGetContent(gomock.Any(), "testfile.txt").Return(testFile, nil)
+				get.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(6), uint64(5)).DoAndReturn(streamRange)
+			},
+			expectedStatus:       http.StatusPartialContent,
+			expectedBody:         "world",
+			expectedContentRange: "bytes 6-10/11",
+		},
+		{
+			name:        "Success - Multiple ranges return whole object",
+			method:      http.MethodGet,
+			rangeHeader: "bytes=0-1,6-7",
+			setupMock: func(get *mock.MockFileGetUseCase) {
+				get.EXPECT().ExecuteGetContent(gomock.Any(), "testfile.txt").Return(testFile, nil)
+				get.EXPECT().ExecuteStreamContent(gomock.Any(), testFile, gomock.Any(), uint64(0), testFile.Size).DoAndReturn(streamRange)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testContent,
+		},
+		{
+			name:        "Error - Range not satisfiable",
+			method:      http.MethodGet,
+			rangeHeader: "bytes=100-",
+			setupMock: func(get *mock.MockFileGetUseCase) {
+				get.EXPECT().ExecuteGetContent(gomock.Any(), "testfile.txt").Return(testFile, nil)
+			},
+			expectedStatus:       http.StatusRequestedRangeNotSatisfiable,
+			expectedContentRange: "bytes */11",
+			expectedCode:         "InvalidRange",
+		},
+		{
+			name:   "Success - Head object",
+			method: http.MethodHead,
+			setupMock: func(get *mock.MockFileGetUseCase) {
+				get.EXPECT().ExecuteGetContent(gomock.Any(), "testfile.txt").Return(testFile, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Error - No such key",
+			method: http.MethodGet,
+			setupMock: func(get *mock.MockFileGetUseCase) {
+				get.EXPECT().ExecuteGetContent(gomock.Any(), "testfile.txt").Return(nil, e.NewNotFoundError(errors.New("not found"), "file not found"))
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "NoSuchKey",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			get := mock.NewMockFileGetUseCase(mockCtrl)
+			tc.setupMock(get)
+			router := setupTestFileS3Handler(t, mock.NewMockFileUploadUseCase(mockCtrl), get, mock.NewMockFileDeleteUseCase(mockCtrl))
+			req := httptest.NewRequest(tc.method, "/s3/"+testS3Bucket+"/testfile.txt", nil)
+			if tc.rangeHeader != "" {
+				req.Header.Set("Range", tc.rangeHeader)
+			}
+			sigv4.Sign(req, testS3AccessKeyID, testS3SecretAccessKey, testS3Region, "s3", sigv4.UnsignedPayload, time.Now())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, tc.expectedContentRange, w.Header().Get("Content-Range"))
+			if tc.expectedCode != "" {
+				assert.Contains(t, w.Body.String(), "<Code>"+tc.expectedCode+"</Code>")
+				return
+			}
+			assert.Equal(t, tc.expectedBody, w.Body.String())
+			assert.Equal(t, `"`+testFile.Checksum+`"`, w.Header().Get("ETag"))
+			assert.Equal(t, "Thu, 02 Jan 2025 03:04:05 GMT", w.Header().Get("Last-Modified"))
+		})
+	}
+}
+
+func TestFileS3Handler_ExecuteListObjects(t *testing.T) {
+	files := []*entity.File{
+		{Name: "a.txt", Size: 1, Checksum: "c1"},
+		{Name: "b-1.txt", Size: 2, Checksum: "c2"},
+		{Name: "b-2.txt", Size: 3, Checksum: "c3"},
+		{Name: "c.txt", Size: 4, Checksum: "c4"},
+	}
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedBody   []string
+		unexpectedBody []string
+	}{
+		{
+			name:           "Success - List all objects",
+			query:          "list-type=2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"<Key>a.txt</Key>", "<Key>b-1.txt</Key>", "<Key>b-2.txt</Key>", "<Key>c.txt</Key>", "<KeyCount>4</KeyCount>", "<IsTruncated>false</IsTruncated>"},
+		},
+		{
+			name:           "Success - List with prefix",
+			query:          "list-type=2&prefix=b-",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"<Key>b-1.txt</Key>", "<Key>b-2.txt</Key>", "<KeyCount>2</KeyCount>"},
+			unexpectedBody: []string{"<Key>a.txt</Key>", "<Key>c.txt</Key>"},
+		},
+		{
+			name:           "Success - List with delimiter",
+			query:          "list-type=2&delimiter=-",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"<Key>a.txt</Key>", "<CommonPrefixes><Prefix>b-</Prefix></CommonPrefixes>", "<Key>c.txt</Key>", "<KeyCount>3</KeyCount>"},
+			unexpectedBody: []string{"<Key>b-1.txt</Key>"},
+		},
+		{
+			name:           "Success - List first page",
+			query:          "list-type=2&max-keys=2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"<Key>a.txt</Key>", "<Key>b-1.txt</Key>", "<IsTruncated>true</IsTruncated>", "<NextContinuationToken>Yi0xLnR4dA</NextContinuationToken>"},
+			unexpectedBody: []string{"<Key>b-2.txt</Key>"},
+		},
+		{
+			name:           "Success - List next page",
+			query:          "list-type=2&max-keys=2&continuation-token=Yi0xLnR4dA",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"<Key>b-2.txt</Key>", "<Key>c.txt</Key>", "<IsTruncated>false</IsTruncated>"},
+			unexpectedBody: []string{"<Key>b-1.txt</Key>"},
+		},
+		{
+			name:           "Success - List after common prefix",
+			query:          "list-type=2&delimiter=-&start-after=b-",
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{"<Key>c.txt</Key>", "<KeyCount>1</KeyCount>"},
+			unexpectedBody: []string{"<Prefix>b-</Prefix></CommonPrefixes>"},
+		},
+		{
+			name:           "Error - ListObjects V1",
+			query:          "",
+			expectedStatus: http.StatusNotImplemented,
+			expectedBody:   []string{"<Code>NotImplemented</Code>"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			get := mock.NewMockFileGetUseCase(mockCtrl)
+			if tc.expectedStatus == http.StatusOK {
+				get.EXPECT().ExecuteGetUploadedFiles(gomock.Any()).Return(files, nil)
+			}
+			router := setupTestFileS3Handler(t, mock.NewMockFileUploadUseCase(mockCtrl), get, mock.NewMockFileDeleteUseCase(mockCtrl))
+			req := httptest.NewRequest(http.MethodGet, "/s3/"+testS3Bucket+"?"+tc.query, nil)
+			sigv4.Sign(req, testS3AccessKeyID, testS3SecretAccessKey, testS3Region, "s3", sigv4.UnsignedPayload, time.Now())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			for _, expected := range tc.expectedBody {
+				assert.Contains(t, w.Body.String(), expected)
+			}
+			for _, unexpected := range tc.unexpectedBody {
+				assert.NotContains(t, w.Body.String(), unexpected)
+			}
+		})
+	}
+}
+
+func TestFileS3Handler_ExecuteDelete(t *testing.T) {
+	notFoundError := e.NewNotFoundError(errors.New("not found"), "not found")
+
+	testCases := []struct {
+		name           string
+		target         string
+		setupMock      func(upload *mock.MockFileUploadUseCase, del *mock.MockFileDeleteUseCase)
+		expectedStatus int
+		expectedCode   string
+	}{
+		{
+			name:   "Success - Delete object",
+			target: "/s3/" + testS3Bucket + "/testfile.txt",
+			setupMock: func(upload *mock.MockFileUploadUseCase, del *mock.MockFileDeleteUseCase) {
+				del.EXPECT().Execute(gomock.Any(), "testfile.txt").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Success - Delete missing object",
+			target: "/s3/" + testS3Bucket + "/testfile.txt",
+			setupMock: func(upload *mock.MockFileUploadUseCase, del *mock.MockFileDeleteUseCase) {
+				del.EXPECT().Execute(gomock.Any(), "testfile.txt").Return(notFoundError)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Success - Abort multipart upload",
+			target: "/s3/" + testS3Bucket + "/testfile.txt?uploadId=abc",
+			setupMock: func(upload *mock.MockFileUploadUseCase, del *mock.MockFileDeleteUseCase) {
+				upload.EXPECT().ExecuteAbortMultipart(gomock.Any(), "abc").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:   "Error - Abort unknown multipart upload",
+			target: "/s3/" + testS3Bucket + "/testfile.txt?uploadId=abc",
+			setupMock: func(upload *mock.MockFileUploadUseCase, del *mock.MockFileDeleteUseCase) {
+				upload.EXPECT().ExecuteAbortMultipart(gomock.Any(), "abc").Return(notFoundError)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "NoSuchUpload",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			upload, del := mock.NewMockFileUploadUseCase(mockCtrl), mock.NewMockFileDeleteUseCase(mockCtrl)
+			tc.setupMock(upload, del)
+			router := setupTestFileS3Handler(t, upload, mock.NewMockFileGetUseCase(mockCtrl), del)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, newSignedS3Request(http.MethodDelete, tc.target, ""))
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedCode != "" {
+				assert.Contains(t, w.Body.String(), "<Code>"+tc.expectedCode+"</Code>")
+			}
+		})
+	}
+}
+
+func TestFileS3Handler_AWSSDK(t *testing.T) {
+	testContent := "hello world"
+	testFile := &entity.File{ID: 1, Name: "testfile.txt", Size: uint64(len(testContent)), Checksum: sha256Hex(testContent)}
+	testSession := &entity.UploadSession{Token: "token", TargetName: "testfile.txt", File: testFile}
+
+	// the AWS SDK streams the body in the aws-chunked encoding with the checksum in the trailer over HTTPS, and signs
+	// the SHA-256 of the body over HTTP
+	testCases := []struct {
+		name                  string
+		useTLS                bool
+		expectedPayloadHashes []string
+	}{
+		{
+			name:                  "Success - HTTPS",
+			useTLS:                true,
+			expectedPayloadHashes: []string{sigv4.StreamingUnsignedPayloadTrailer, sigv4.StreamingUnsignedPayloadTrailer},
+		},
+		{
+			name:                  "Success - HTTP",
+			useTLS:                false,
+			expectedPayloadHashes: []string{sha256Hex(testContent), sha256Hex("world")},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			upload, get, del := mock.NewMockFileUploadUseCase(mockCtrl), mock.NewMockFileGetUseCase(mockCtrl), mock.NewMockFileDeleteUseCase(mockCtrl)
+			upload.EXPECT().ExecuteInit(gomock.Any(), gomock.Any()).Return(testSession, nil, nil)
+			upload.EXPECT().ExecuteAppend(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ any, input usecase.FileUploadUseCaseExecuteAppendInput) (uint64, e.CustomError) {
+					body, err := io.ReadAll(input.Reader)
+					assert.NoError(t, err)
+					assert.Equal(t, testContent, string(body))
+					return uint64(len(body)), nil
+				})
+			get.EXPECT().ExecuteGetStats(gomock.Any(), "testfile.txt").Return(testFile, nil)
+			upload.EXPECT().ExecuteUploadPart(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ any, input usecase.FileUploadUseCaseExecuteUploadPartInput) (*entity.MultipartPart, e.CustomError) {
+					body, err := io.ReadAll(input.Reader)
+					assert.NoError(t, err)
+					assert.Equal(t, "world", string(body))
+					return &entity.MultipartPart{PartNumber: 2, Size: 5, Checksum: sha256Hex("world")}, nil
+				})
+
+			router := setupTestFileS3Handler(t, upload, get, del)
+			payloadHashes := []string{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				payloadHashes = append(payloadHashes, r.Header.Get(sigv4.ContentSHA256Header))
+				router.ServeHTTP(w, r)
+			})
+			server := httptest.NewUnstartedServer(handler)
+			if tc.useTLS {
+				server.StartTLS()
+			} else {
+				server.Start()
+			}
+			defer server.Close()
+
+			// the checksum is calculated as the default configuration of the SDK does
+			client := s3.New(s3.Options{
+				BaseEndpoint:               aws.String(server.URL + "/s3"),
+				Region:                     testS3Region,
+				RequestChecksumCalculation: aws.RequestChecksumCalculationWhenSupported,
+				Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+					return aws.Credentials{AccessKeyID: testS3AccessKeyID, SecretAccessKey: testS3SecretAccessKey}, nil
+				}),
+				UsePathStyle: true,
+				HTTPClient:   server.Client(),
+			})
+			putOutput, err := client.PutObject(context.Background(), &s3.PutObjectInput{
+				Bucket: aws.String(testS3Bucket),
+				Key:    aws.String("testfile.txt"),
+				Body:   strings.NewReader(testContent),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, `"`+testFile.Checksum+`"`, aws.ToString(putOutput.ETag))
+
+			partOutput, err := client.UploadPart(context.Background(), &s3.UploadPartInput{
+				Bucket:     aws.String(testS3Bucket),
+				Key:        aws.String("testfile.txt"),
+				UploadId:   aws.String("abc"),
+				PartNumber: aws.Int32(2),
+				Body:       strings.NewReader("world"),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, `"`+sha256Hex("world")+`"`, aws.ToString(partOutput.ETag))
+
+			assert.Equal(t, tc.expectedPayloadHashes, payloadHashes)
+		})
+	}
+}
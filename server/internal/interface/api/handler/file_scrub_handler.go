@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"golang.org/x/exp/slog"
+)
+
+type FileScrubHandler struct {
+	fileScrubUseCase usecase.FileScrubUseCase
+	logger           *slog.Logger
+}
+
+func NewFileScrubHandler(fileScrubUseCase usecase.FileScrubUseCase, logger *slog.Logger) *FileScrubHandler {
+	return &FileScrubHandler{
+		fileScrubUseCase: fileScrubUseCase,
+		logger:           logger,
+	}
+}
+
+// ExecuteGetReport handles the retrieval of the files found damaged by the scrubber
+func (h *FileScrubHandler) ExecuteGetReport(ctx *gin.Context) {
+	report, err := h.fileScrubUseCase.ExecuteGetReport(ctx.Request.Context())
+	if err != nil {
+		sendErrorResponse(ctx, h.logger, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
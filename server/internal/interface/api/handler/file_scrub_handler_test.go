@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/interface/api/handler"
+	"github.com/tomoya.tokunaga/server/internal/mock"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/exp/slog"
+)
+
+func TestFileScrubHandler_ExecuteGetReport(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	testReport := &entity.ScrubReport{
+		LastRun: &entity.ScrubRun{
+			StartedAt:     now.Add(-time.Minute),
+			FinishedAt:    now,
+			ScannedFiles:  3,
+			ScannedChunks: 10,
+			DamagedFiles:  1,
+		},
+		DamagedFiles: []entity.ScrubDamagedFile{
+			{FileID: 1, Name: "damaged.txt", ChunkNumbers: []uint64{0, 2}},
+		},
+	}
+	mockUsecaseError := e.NewMockCustomError(errors.New("usecase error"), "Usecase error", http.StatusInternalServerError)
+
+	testCases := []struct {
+		name             string
+		setupMock        func(mockUseCase *mock.MockFileScrubUseCase)
+		expectedStatus   int
+		expectedBody     *entity.ScrubReport
+		expectedErrorMsg string
+	}{
+		{
+			name: "Success",
+			setupMock: func(mockUseCase *mock.MockFileScrubUseCase) {
+				mockUseCase.EXPECT().ExecuteGetReport(gomock.Any()).Return(testReport, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   testReport,
+		},
+		{
+			name: "Error - Usecase Internal Error",
+			setupMock: func(mockUseCase *mock.MockFileScrubUseCase) {
+				mockUseCase.EXPECT().ExecuteGetReport(gomock.Any()).Return(nil, mockUsecaseError)
+			},
+			expectedStatus:   mockUsecaseError.StatusCode(),
+			expectedErrorMsg: mockUsecaseError.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUseCase := mock.NewMockFileScrubUseCase(ctrl)
+			tc.setupMock(mockUseCase)
+
+			h := handler.NewFileScrubHandler(mockUseCase, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/admin/scrub", h.ExecuteGetReport)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/admin/scrub", nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code, "Status code mismatch")
+
+			if tc.expectedBody != nil {
+				var actualBody entity.ScrubReport
+				err := json.Unmarshal(w.Body.Bytes(), &actualBody)
+				require.NoError(t, err, "Failed to unmarshal success response: %s", w.Body.String())
+				assert.Equal(t, *tc.expectedBody, actualBody)
+			} else {
+				var errorResponse handler.ErrorResponse
+				err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
+				require.NoError(t, err, "Failed to unmarshal error response: %s", w.Body.String())
+				assert.Contains(t, errorResponse.Error, tc.expectedErrorMsg, "Error message mismatch")
+			}
+		})
+	}
+}
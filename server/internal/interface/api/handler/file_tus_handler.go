@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"github.com/tomoya.tokunaga/server/internal/util/checksum"
+	"golang.org/x/exp/slog"
+)
+
+// Headers and values of the tus resumable upload protocol 1.0.0 (https://tus.io/protocols/resumable-upload)
+const (
+	TusResumableHeader         = "Tus-Resumable"
+	TusVersionHeader           = "Tus-Version"
+	TusExtensionHeader         = "Tus-Extension"
+	TusMaxSizeHeader           = "Tus-Max-Size"
+	TusChecksumAlgorithmHeader = "Tus-Checksum-Algorithm"
+	UploadOffsetHeader         = "Upload-Offset"
+	UploadLengthHeader         = "Upload-Length"
+	UploadDeferLengthHeader    = "Upload-Defer-Length"
+	UploadMetadataHeader       = "Upload-Metadata"
+	UploadChecksumHeader       = "Upload-Checksum"
+
+	TusVersion     = "1.0.0"
+	TusExtensions  = "creation,termination,checksum"
+	TusContentType = "application/offset+octet-stream"
+
+	// StatusChecksumMismatch is the status code which the checksum extension responds with when the checksum of the
+	// content doesn't match the declared one
+	StatusChecksumMismatch = 460
+)
+
+// tusChecksumAlgorithms are the algorithms of the checksum extension, which is named differently from the ones of
+// the whole-file checksum
+var tusChecksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// FileTusHandler serves the tus resumable upload protocol, so that the existing tus clients (e.g. browser uploaders and
+// mobile SDKs) can upload files into the same storage as the CLI. The uploads are stored in the chunks of a fixed size
+// like the ones of the CLI, and a request can start and end anywhere in the file.
+type FileTusHandler struct {
+	fileUploadUseCase usecase.FileUploadUseCase
+	config            *entity.Config
+	logger            *slog.Logger
+}
+
+func NewFileTusHandler(fileUploadUseCase usecase.FileUploadUseCase, config *entity.Config, logger *slog.Logger) *FileTusHandler {
+	return &FileTusHandler{
+		fileUploadUseCase: fileUploadUseCase,
+		config:            config,
+		logger:            logger,
+	}
+}
+
+// CheckTusResumable rejects the requests of the unsupported protocol versions, and adds the headers shared by all the
+// responses. The CORS headers let the browser uploaders on other origins read the tus headers.
+func (h *FileTusHandler) CheckTusResumable(ctx *gin.Context) {
+	ctx.Header(TusResumableHeader, TusVersion)
+	ctx.Header("Access-Control-Allow-Origin", "*")
+	ctx.Header("Access-Control-Expose-Headers", strings.Join([]string{
+		"Location", TusResumableHeader, TusVersionHeader, TusExtensionHeader, TusMaxSizeHeader,
+		TusChecksumAlgorithmHeader, UploadOffsetHeader, UploadLengthHeader, UploadMetadataHeader,
+	}, ", "))
+
+	// OPTIONS requests are sent without the header to discover the versions
+	if ctx.Request.Method == http.MethodOptions {
+		return
+	}
+	if version := ctx.GetHeader(TusResumableHeader); version != TusVersion {
+		ctx.Header(TusVersionHeader, TusVersion)
+		h.sendErrorResponse(ctx, http.StatusPreconditionFailed, e.NewInvalidInputError(
+			fmt.Errorf("tus version %q is not supported", version), "",
+		))
+		ctx.Abort()
+	}
+}
+
+// ExecuteOptions describes the protocol versions, the extensions and the limits supported by the server
+func (h *FileTusHandler) ExecuteOptions(ctx *gin.Context) {
+	ctx.Header(TusVersionHeader, TusVersion)
+	ctx.Header(TusExtensionHeader, TusExtensions)
+	if h.config.MaxFileSize > 0 {
+		ctx.Header(TusMaxSizeHeader, strconv.FormatUint(h.config.MaxFileSize, 10))
+	}
+	algorithms := make([]string, 0, len(tusChecksumAlgorithms))
+	for algorithm := range tusChecksumAlgorithms {
+		algorithms = append(algorithms, algorithm)
+	}
+	slices.Sort(algorithms)
+	ctx.Header(TusChecksumAlgorithmHeader, strings.Join(algorithms, ","))
+
+	// preflight requests of the browser uploaders
+	ctx.Header("Access-Control-Allow-Methods", "POST, HEAD, PATCH, DELETE, OPTIONS")
+	ctx.Header("Access-Control-Allow-Headers", strings.Join([]string{
+		TusResumableHeader, UploadLengthHeader, UploadMetadataHeader, UploadOffsetHeader, UploadChecksumHeader,
+		"Content-Type", "X-Requested-With",
+	}, ", "))
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ExecuteCreate creates an upload (creation extension). The file name is taken from the "filename" (or "name")
+// metadata, and the whole-file checksum can be declared by the "checksum" and "checksum_algorithm" metadata. Without
+// the checksum, the checksum of the file is calculated once all of it is uploaded.
+func (h *FileTusHandler) ExecuteCreate(ctx *gin.Context) {
+	if ctx.GetHeader(UploadDeferLengthHeader) != "" {
+		h.sendErrorResponse(ctx, http.StatusBadRequest, e.NewInvalidInputError(errors.New("deferred upload length is not supported"), ""))
+		return
+	}
+	totalSize, err := strconv.ParseUint(ctx.GetHeader(UploadLengthHeader), 10, 64)
+	if err != nil {
+		h.sendErrorResponse(ctx, http.StatusBadRequest, e.NewInvalidInputError(err, "invalid Upload-Length"))
+		return
+	}
+	if totalSize == 0 {
+		h.sendErrorResponse(ctx, http.StatusBadRequest, e.NewInvalidInputError(errors.New("empty files are not supported"), "invalid Upload-Length"))
+		return
+	}
+	limits := h.config.UploadLimits()
+	if limits.MaxFileSize > 0 && totalSize > limits.MaxFileSize {
+		h.sendErrorResponse(ctx, http.StatusRequestEntityTooLarge, e.NewInvalidInputError(
+			fmt.Errorf("file size %d exceeds the maximum %d", totalSize, limits.MaxFileSize), "invalid Upload-Length",
+		))
+		return
+	}
+
+	metadata, err := parseUploadMetadata(ctx.GetHeader(UploadMetadataHeader))
+	if err != nil {
+		h.sendErrorResponse(ctx, http.StatusBadRequest, e.NewInvalidInputError(err, "invalid Upload-Metadata"))
+		return
+	}
+	fileName := metadata["filename"]
+	if fileName == "" {
+		fileName = metadata["name"]
+	}
+	if !isValidFileName(fileName) {
+		h.sendErrorResponse(ctx, http.StatusBadRequest, e.NewInvalidInputError(fmt.Errorf("invalid file name %q", fileName), "invalid Upload-Metadata"))
+		return
+	}
+	checksumAlgorithm := metadata["checksum_algorithm"]
+	if checksumAlgorithm == "" {
+		checksumAlgorithm = string(checksum.DefaultAlgorithm)
+	}
+
+	chunkSize := limits.ChunkSizeFor(totalSize, h.config.TusChunkSize)
+	totalChunks := (totalSize + chunkSize - 1) / chunkSize
+	if err := limits.Validate(totalSize, chunkSize, totalChunks); err != nil {
+		h.sendErrorResponse(ctx, http.StatusRequestEntityTooLarge, e.NewInvalidInputError(err, "invalid upload layout"))
+		return
+	}
+
+	session, _, ucErr := h.fileUploadUseCase.ExecuteInit(ctx.Request.Context(), usecase.FileUploadUseCaseExecuteInitInput{
+		FileName:          fileName,
+		Checksum:          metadata["checksum"],
+		ChecksumAlgorithm: checksumAlgorithm,
+		TotalSize:         totalSize,
+		TotalChunks:       uint(totalChunks),
+		ChunkSize:         chunkSize,
+		Owner:             ctx.GetHeader(UploadOwnerHeader),
+	})
+	if ucErr != nil {
+		h.sendErrorResponse(ctx, tusStatusCode(ucErr), ucErr)
+		return
+	}
+
+	// the upload URL is of the upload session, so the file with the name stays readable until the upload is completed
+	ctx.Header("Location", fmt.Sprintf("%s/%s", strings.TrimSuffix(ctx.Request.URL.Path, "/"), session.Token))
+	ctx.Status(http.StatusCreated)
+}
+
+// ExecuteHead returns the offset of an upload, which the client resumes the upload from
+func (h *FileTusHandler) ExecuteHead(ctx *gin.Context) {
+	session, offset, ucErr := h.fileUploadUseCase.ExecuteGetOffset(ctx.Request.Context(), ctx.Param("session_token"))
+	if ucErr != nil {
+		h.sendErrorResponse(ctx, tusStatusCode(ucErr), ucErr)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header(UploadOffsetHeader, strconv.FormatUint(offset, 10))
+	ctx.Header(UploadLengthHeader, strconv.FormatUint(session.File.Size, 10))
+	ctx.Header(UploadMetadataHeader, "filename "+base64.StdEncoding.EncodeToString([]byte(session.TargetName)))
+	ctx.Status(http.StatusOK)
+}
+
+// ExecutePatch appends the content of the request to an upload at its offset. When the checksum of the content is
+// declared (checksum extension), the content is discarded unless it matches.
+func (h *FileTusHandler) ExecutePatch(ctx *gin.Context) {
+	if contentType := ctx.GetHeader("Content-Type"); contentType != TusContentType {
+		h.sendErrorResponse(ctx, http.StatusUnsupportedMediaType, e.NewInvalidInputError(fmt.Errorf("content type needs to be %s, but got %q", TusContentType, contentType), ""))
+		return
+	}
+	offset, err := strconv.ParseUint(ctx.GetHeader(UploadOffsetHeader), 10, 64)
+	if err != nil {
+		h.sendErrorResponse(ctx, http.StatusBadRequest, e.NewInvalidInputError(err, "invalid Upload-Offset"))
+		return
+	}
+	checksumHash, declaredChecksum, err := parseUploadChecksum(ctx.GetHeader(UploadChecksumHeader))
+	if err != nil {
+		h.sendErrorResponse(ctx, http.StatusBadRequest, e.NewInvalidInputError(err, "invalid Upload-Checksum"))
+		return
+	}
+
+	newOffset, ucErr := h.fileUploadUseCase.ExecuteAppend(ctx.Request.Context(), usecase.FileUploadUseCaseExecuteAppendInput{
+		SessionToken: ctx.Param("session_token"),
+		Offset:       offset,
+		Reader:       ctx.Request.Body,
+		ChecksumHash: checksumHash,
+		Checksum:     declaredChecksum,
+	})
+	if ucErr != nil {
+		h.sendErrorResponse(ctx, tusStatusCode(ucErr), ucErr)
+		return
+	}
+
+	ctx.Header(UploadOffsetHeader, strconv.FormatUint(newOffset, 10))
+	ctx.Status(http.StatusNoContent)
+}
+
+// ExecuteDelete terminates an upload and deletes its content (termination extension). The file previously uploaded
+// with the name is left as it is, and a completed upload can't be terminated
+func (h *FileTusHandler) ExecuteDelete(ctx *gin.Context) {
+	if ucErr := h.fileUploadUseCase.ExecuteAbort(ctx.Request.Context(), ctx.Param("session_token")); ucErr != nil {
+		h.sendErrorResponse(ctx, tusStatusCode(ucErr), ucErr)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// sendErrorResponse sends the error with the status code of the protocol, which can differ from the one of the error
+func (h *FileTusHandler) sendErrorResponse(ctx *gin.Context, statusCode int, err e.CustomError) {
+	h.logger.Error("error",
+		"error", err.Error(),
+		"code", err.ErrorCode(),
+		"status_code", statusCode,
+	)
+
+	ctx.JSON(statusCode, ErrorResponse{
+		Error:      err.Error(),
+		ErrorCode:  err.ErrorCode(),
+		StatusCode: statusCode,
+	})
+}
+
+// tusStatusCode returns the status code of the protocol for the error
+func tusStatusCode(err e.CustomError) int {
+	if err.ErrorCode() == "CHECKSUM_MISMATCH" {
+		return StatusChecksumMismatch
+	}
+	return err.StatusCode()
+}
+
+// isValidFileName reports whether the name can be the name of a file. The name is a part of the storage path, so unlike
+// the one in the URL of the other routes, it needs to be checked for slashes
+func isValidFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// parseUploadMetadata parses the Upload-Metadata header, which is comma-separated pairs of a key and a base64 encoded
+// value separated by a space. The value can be omitted.
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encodedValue, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, fmt.Errorf("empty key in %q", header)
+		}
+		value, err := base64.StdEncoding.DecodeString(encodedValue)
+		if err != nil {
+			return nil, fmt.Errorf("value of %s needs to be base64 encoded: %w", key, err)
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// parseUploadChecksum parses the Upload-Checksum header, which is the algorithm and the base64 encoded checksum
+// separated by a space. It returns nothing when the header is empty.
+func parseUploadChecksum(header string) (hash.Hash, []byte, error) {
+	if header == "" {
+		return nil, nil, nil
+	}
+	algorithm, encodedChecksum, found := strings.Cut(header, " ")
+	if !found {
+		return nil, nil, fmt.Errorf("algorithm and checksum need to be separated by a space: %s", header)
+	}
+	newHash, ok := tusChecksumAlgorithms[algorithm]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported checksum algorithm %q", algorithm)
+	}
+	declaredChecksum, err := base64.StdEncoding.DecodeString(encodedChecksum)
+	if err != nil {
+		return nil, nil, fmt.Errorf("checksum needs to be base64 encoded: %w", err)
+	}
+	return newHash(), declaredChecksum, nil
+}
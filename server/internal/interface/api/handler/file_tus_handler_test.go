@@ -0,0 +1,436 @@
+package handler_test
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/interface/api/handler"
+	"github.com/tomoya.tokunaga/server/internal/mock"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/exp/slog"
+)
+
+// setupTestFileTusHandler is a helper function to set up the tus routes with the mocked use cases
+func setupTestFileTusHandler(t *testing.T, uploadUseCase usecase.FileUploadUseCase) *gin.Engine {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	config := &entity.Config{MaxFileSize: 1024 * 1024, MaxChunkSize: 4096, MaxChunkCount: 8, TusChunkSize: 1024}
+	h := handler.NewFileTusHandler(uploadUseCase, config, logger)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	tus := router.Group("/api/v1/tus", h.CheckTusResumable)
+	tus.OPTIONS("/files", h.ExecuteOptions)
+	tus.POST("/files", h.ExecuteCreate)
+	tus.HEAD("/files/:session_token", h.ExecuteHead)
+	tus.PATCH("/files/:session_token", h.ExecutePatch)
+	tus.DELETE("/files/:session_token", h.ExecuteDelete)
+
+	return router
+}
+
+func TestFileTusHandler_ExecuteOptions(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	router := setupTestFileTusHandler(t, mock.NewMockFileUploadUseCase(mockCtrl))
+
+	// the versions are discovered without the Tus-Resumable header
+	req, _ := http.NewRequest(http.MethodOptions, "/api/v1/tus/files", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, handler.TusVersion, w.Header().Get(handler.TusResumableHeader))
+	assert.Equal(t, handler.TusVersion, w.Header().Get(handler.TusVersionHeader))
+	assert.Equal(t, "creation,termination,checksum", w.Header().Get(handler.TusExtensionHeader))
+	assert.Equal(t, "1048576", w.Header().Get(handler.TusMaxSizeHeader))
+	assert.Equal(t, "md5,sha1,sha256", w.Header().Get(handler.TusChecksumAlgorithmHeader))
+}
+
+func TestFileTusHandler_ExecuteCreate(t *testing.T) {
+	fileNameMetadata := "filename " + base64.StdEncoding.EncodeToString([]byte("testfile.txt"))
+	mockUsecaseError := e.NewMockCustomError(errors.New("usecase init error"), "Usecase init error", http.StatusInternalServerError)
+
+	testCases := []struct {
+		name             string
+		headers          map[string]string
+		setupMock        func(mockUseCase *mock.MockFileUploadUseCase)
+		expectedStatus   int
+		expectedLocation string
+		expectedErrorMsg string
+	}{
+		{
+			name:    "Success - Create upload",
+			headers: map[string]string{handler.UploadLengthHeader: "3000", handler.UploadMetadataHeader: fileNameMetadata},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: "testfile.txt", ChecksumAlgorithm: "sha256", TotalSize: 3000, TotalChunks: 3, ChunkSize: 1024,
+				}).Return(&entity.UploadSession{Token: "token7", File: &entity.File{ID: 7}}, nil, nil)
+			},
+			expectedStatus:   http.StatusCreated,
+			expectedLocation: "/api/v1/tus/files/token7",
+		},
+		{
+			name: "Success - Create upload with checksum and chunk size raised to fit chunk count",
+			headers: map[string]string{
+				handler.UploadLengthHeader: "10000",
+				handler.UploadMetadataHeader: fmt.Sprintf("name %s,checksum %s,checksum_algorithm %s,is_confidential",
+					base64.StdEncoding.EncodeToString([]byte("testfile.txt")),
+					base64.StdEncoding.EncodeToString([]byte("abc")),
+					base64.StdEncoding.EncodeToString([]byte("xxhash64")),
+				),
+			},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: "testfile.txt", Checksum: "abc", ChecksumAlgorithm: "xxhash64", TotalSize: 10000, TotalChunks: 8, ChunkSize: 1250,
+				}).Return(&entity.UploadSession{Token: "token8", File: &entity.File{ID: 8}}, nil, nil)
+			},
+			expectedStatus:   http.StatusCreated,
+			expectedLocation: "/api/v1/tus/files/token8",
+		},
+		{
+			name:             "Error - Missing Tus-Resumable",
+			headers:          map[string]string{handler.TusResumableHeader: "", handler.UploadLengthHeader: "3000", handler.UploadMetadataHeader: fileNameMetadata},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusPreconditionFailed,
+			expectedErrorMsg: "is not supported",
+		},
+		{
+			name:             "Error - Missing Upload-Length",
+			headers:          map[string]string{handler.UploadMetadataHeader: fileNameMetadata},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "invalid Upload-Length",
+		},
+		{
+			name:             "Error - Deferred Upload-Length",
+			headers:          map[string]string{handler.UploadDeferLengthHeader: "1", handler.UploadMetadataHeader: fileNameMetadata},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "deferred upload length is not supported",
+		},
+		{
+			name:             "Error - Empty file",
+			headers:          map[string]string{handler.UploadLengthHeader: "0", handler.UploadMetadataHeader: fileNameMetadata},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "empty files are not supported",
+		},
+		{
+			name:             "Error - File too large",
+			headers:          map[string]string{handler.UploadLengthHeader: "2097152", handler.UploadMetadataHeader: fileNameMetadata},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusRequestEntityTooLarge,
+			expectedErrorMsg: "file size 2097152 exceeds the maximum 1048576",
+		},
+		{
+			name:             "Error - File needs too many chunks",
+			headers:          map[string]string{handler.UploadLengthHeader: "40000", handler.UploadMetadataHeader: fileNameMetadata},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusRequestEntityTooLarge,
+			expectedErrorMsg: "chunk count 10 exceeds the maximum 8",
+		},
+		{
+			name:             "Error - Missing file name",
+			headers:          map[string]string{handler.UploadLengthHeader: "3000"},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "invalid file name",
+		},
+		{
+			name:             "Error - File name with path separator",
+			headers:          map[string]string{handler.UploadLengthHeader: "3000", handler.UploadMetadataHeader: "filename " + base64.StdEncoding.EncodeToString([]byte("../etc/passwd"))},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "invalid file name",
+		},
+		{
+			name:             "Error - Metadata not base64 encoded",
+			headers:          map[string]string{handler.UploadLengthHeader: "3000", handler.UploadMetadataHeader: "filename testfile.txt"},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "value of filename needs to be base64 encoded",
+		},
+		{
+			name:    "Success - Create upload of owner",
+			headers: map[string]string{handler.UploadLengthHeader: "3000", handler.UploadMetadataHeader: fileNameMetadata, handler.UploadOwnerHeader: "alice@host"},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: "testfile.txt", ChecksumAlgorithm: "sha256", TotalSize: 3000, TotalChunks: 3, ChunkSize: 1024, Owner: "alice@host",
+				}).Return(&entity.UploadSession{Token: "token9", File: &entity.File{ID: 9}}, nil, nil)
+			},
+			expectedStatus:   http.StatusCreated,
+			expectedLocation: "/api/v1/tus/files/token9",
+		},
+		{
+			name:    "Error - Usecase returns error",
+			headers: map[string]string{handler.UploadLengthHeader: "3000", handler.UploadMetadataHeader: fileNameMetadata},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), gomock.Any()).Return(nil, nil, mockUsecaseError)
+			},
+			expectedStatus:   http.StatusInternalServerError,
+			expectedErrorMsg: mockUsecaseError.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockUseCase := mock.NewMockFileUploadUseCase(mockCtrl)
+			tc.setupMock(mockUseCase)
+			router := setupTestFileTusHandler(t, mockUseCase)
+
+			req, _ := http.NewRequest(http.MethodPost, "/api/v1/tus/files", nil)
+			req.Header.Set(handler.TusResumableHeader, handler.TusVersion)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			assert.Equal(t, handler.TusVersion, w.Header().Get(handler.TusResumableHeader))
+			if tc.expectedErrorMsg == "" {
+				assert.Equal(t, tc.expectedLocation, w.Header().Get("Location"))
+			} else {
+				assert.Contains(t, w.Body.String(), tc.expectedErrorMsg)
+			}
+		})
+	}
+}
+
+func TestFileTusHandler_ExecuteHead(t *testing.T) {
+	testSession := &entity.UploadSession{Token: "token7", TargetName: "testfile.txt", File: &entity.File{ID: 7, Name: "testfile.txt", Size: 3000}}
+	notFoundError := e.NewNotFoundError(errors.New("upload session not found or expired"), "")
+
+	testCases := []struct {
+		name           string
+		tokenParam     string
+		setupMock      func(mockUseCase *mock.MockFileUploadUseCase)
+		expectedStatus int
+		expectedOffset string
+	}{
+		{
+			name:       "Success",
+			tokenParam: "token7",
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteGetOffset(gomock.Any(), "token7").Return(testSession, uint64(1500), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedOffset: "1500",
+		},
+		{
+			name:       "Error - Not Found",
+			tokenParam: "unknown",
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteGetOffset(gomock.Any(), "unknown").Return(nil, uint64(0), notFoundError)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockUseCase := mock.NewMockFileUploadUseCase(mockCtrl)
+			tc.setupMock(mockUseCase)
+			router := setupTestFileTusHandler(t, mockUseCase)
+
+			req, _ := http.NewRequest(http.MethodHead, "/api/v1/tus/files/"+tc.tokenParam, nil)
+			req.Header.Set(handler.TusResumableHeader, handler.TusVersion)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedOffset != "" {
+				assert.Equal(t, tc.expectedOffset, w.Header().Get(handler.UploadOffsetHeader))
+				assert.Equal(t, "3000", w.Header().Get(handler.UploadLengthHeader))
+				assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+				assert.Equal(t, "filename "+base64.StdEncoding.EncodeToString([]byte("testfile.txt")), w.Header().Get(handler.UploadMetadataHeader))
+			}
+		})
+	}
+}
+
+func TestFileTusHandler_ExecutePatch(t *testing.T) {
+	content := "hello world"
+	sha1Sum := sha1.Sum([]byte(content))
+	checksumHeader := "sha1 " + base64.StdEncoding.EncodeToString(sha1Sum[:])
+
+	testCases := []struct {
+		name             string
+		headers          map[string]string
+		setupMock        func(mockUseCase *mock.MockFileUploadUseCase)
+		expectedStatus   int
+		expectedOffset   string
+		expectedErrorMsg string
+	}{
+		{
+			name:    "Success",
+			headers: map[string]string{handler.UploadOffsetHeader: "1000"},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteAppend(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ any, input usecase.FileUploadUseCaseExecuteAppendInput) (uint64, e.CustomError) {
+						assert.Equal(t, "token7", input.SessionToken)
+						assert.Equal(t, uint64(1000), input.Offset)
+						assert.Nil(t, input.ChecksumHash)
+						body, _ := io.ReadAll(input.Reader)
+						assert.Equal(t, content, string(body))
+						return 1011, nil
+					})
+			},
+			expectedStatus: http.StatusNoContent,
+			expectedOffset: "1011",
+		},
+		{
+			name:    "Success - With checksum",
+			headers: map[string]string{handler.UploadOffsetHeader: "1000", handler.UploadChecksumHeader: checksumHeader},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteAppend(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ any, input usecase.FileUploadUseCaseExecuteAppendInput) (uint64, e.CustomError) {
+						assert.NotNil(t, input.ChecksumHash)
+						assert.Equal(t, sha1Sum[:], input.Checksum)
+						return 1011, nil
+					})
+			},
+			expectedStatus: http.StatusNoContent,
+			expectedOffset: "1011",
+		},
+		{
+			name:             "Error - Invalid content type",
+			headers:          map[string]string{handler.UploadOffsetHeader: "1000", "Content-Type": "application/octet-stream"},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusUnsupportedMediaType,
+			expectedErrorMsg: "content type needs to be application/offset+octet-stream",
+		},
+		{
+			name:             "Error - Missing Upload-Offset",
+			headers:          map[string]string{},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "invalid Upload-Offset",
+		},
+		{
+			name:             "Error - Unsupported checksum algorithm",
+			headers:          map[string]string{handler.UploadOffsetHeader: "1000", handler.UploadChecksumHeader: "crc32 AAAAAA=="},
+			setupMock:        func(mockUseCase *mock.MockFileUploadUseCase) {},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "unsupported checksum algorithm",
+		},
+		{
+			name:    "Error - Checksum mismatch",
+			headers: map[string]string{handler.UploadOffsetHeader: "1000", handler.UploadChecksumHeader: checksumHeader},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteAppend(gomock.Any(), gomock.Any()).
+					Return(uint64(0), e.NewChecksumMismatchError(errors.New("expected abc, but got def"), "content appended to testfile.txt is corrupted"))
+			},
+			expectedStatus:   handler.StatusChecksumMismatch,
+			expectedErrorMsg: "content appended to testfile.txt is corrupted",
+		},
+		{
+			name:    "Error - Offset mismatch",
+			headers: map[string]string{handler.UploadOffsetHeader: "0"},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteAppend(gomock.Any(), gomock.Any()).
+					Return(uint64(0), e.NewConflictError(errors.New("offset of testfile.txt is 1000, but got 0"), "invalid offset"))
+			},
+			expectedStatus:   http.StatusConflict,
+			expectedErrorMsg: "offset of testfile.txt is 1000, but got 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockUseCase := mock.NewMockFileUploadUseCase(mockCtrl)
+			tc.setupMock(mockUseCase)
+			router := setupTestFileTusHandler(t, mockUseCase)
+
+			req, _ := http.NewRequest(http.MethodPatch, "/api/v1/tus/files/token7", strings.NewReader(content))
+			req.Header.Set(handler.TusResumableHeader, handler.TusVersion)
+			req.Header.Set("Content-Type", handler.TusContentType)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedErrorMsg == "" {
+				assert.Equal(t, tc.expectedOffset, w.Header().Get(handler.UploadOffsetHeader))
+			} else {
+				assert.Contains(t, w.Body.String(), tc.expectedErrorMsg)
+			}
+		})
+	}
+}
+
+func TestFileTusHandler_ExecuteDelete(t *testing.T) {
+	notFoundError := e.NewNotFoundError(errors.New("upload session not found or expired"), "")
+	conflictError := e.NewConflictError(errors.New("upload of testfile.txt is already committed"), "")
+
+	testCases := []struct {
+		name           string
+		setupMock      func(mockUseCase *mock.MockFileUploadUseCase)
+		expectedStatus int
+	}{
+		{
+			name: "Success",
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteAbort(gomock.Any(), "token7").Return(nil)
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "Error - Not Found",
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteAbort(gomock.Any(), "token7").Return(notFoundError)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Error - Upload already committed",
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteAbort(gomock.Any(), "token7").Return(conflictError)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockUseCase := mock.NewMockFileUploadUseCase(mockCtrl)
+			tc.setupMock(mockUseCase)
+			router := setupTestFileTusHandler(t, mockUseCase)
+
+			req, _ := http.NewRequest(http.MethodDelete, "/api/v1/tus/files/token7", nil)
+			req.Header.Set(handler.TusResumableHeader, handler.TusVersion)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+		})
+	}
+}
@@ -2,21 +2,44 @@ package handler
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"strconv"
-
-	"compress/gzip"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
 	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"github.com/tomoya.tokunaga/server/internal/util/checksum"
 	"golang.org/x/exp/slog"
 )
 
+// UploadOwnerHeader is the header which identifies who starts an upload (e.g. "user@host"), so that only the same
+// owner resumes the upload session
+const UploadOwnerHeader = "X-Upload-Owner"
+
+const (
+	// FileChecksumHeader is the header which declares the checksum of the whole content uploaded by a single request
+	// in hex, which is verified before the file is stored
+	FileChecksumHeader = "X-File-Checksum"
+	// FileChecksumAlgorithmHeader is the header which declares the algorithm of the checksum, sha256 by default
+	FileChecksumAlgorithmHeader = "X-File-Checksum-Algorithm"
+	// FileStorageCodecHeader is the header which chooses the compression the file is stored in (none, gzip or zstd),
+	// the server's default when it's omitted
+	FileStorageCodecHeader = "X-File-Storage-Codec"
+	// PutFileFormField is the name of the form field which carries the content of a multipart/form-data upload
+	PutFileFormField = "file"
+)
+
 type FileUploadHandler struct {
 	fileUploadUseCase usecase.FileUploadUseCase
 	config            *entity.Config
@@ -31,28 +54,52 @@ func NewFileUploadHandler(fileUploadUseCase usecase.FileUploadUseCase, config *e
 	}
 }
 
+// InitUploadRequest is the request body of the upload initialization. The total size, the total chunks and the checksum
+// of an open-ended upload (e.g. a stream read from stdin) are unknown until its commit
 type InitUploadRequest struct {
-	Checksum    string `json:"checksum" binding:"required"`
-	TotalSize   uint64 `json:"total_size" binding:"required"`
-	TotalChunks uint   `json:"total_chunks" binding:"required"`
-	ChunkSize   uint64 `json:"chunk_size" binding:"required"`
-	IsReUpload  bool   `json:"is_reupload"`
+	Checksum          string   `json:"checksum" binding:"required_unless=OpenEnded true"`
+	ChecksumAlgorithm string   `json:"checksum_algorithm"` // defaults to sha256 for the clients which don't declare it
+	TotalSize         uint64   `json:"total_size" binding:"required_unless=OpenEnded true"`
+	TotalChunks       uint     `json:"total_chunks" binding:"required_unless=OpenEnded true"`
+	ChunkSize         uint64   `json:"chunk_size" binding:"required"`
+	ChunkSizes        []uint64 `json:"chunk_sizes"`     // sizes of the chunks in order when they vary, each up to the chunk size
+	ChunkChecksums    []string `json:"chunk_checksums"` // SHA-256 of the chunks in order in hex, by which the chunks of the base file are reused
+	BaseFileID        uint64   `json:"base_file_id"`    // file bound to the name whose chunks of the same content are reused
+	IsReUpload        bool     `json:"is_reupload"`
+	OpenEnded         bool     `json:"open_ended"`
+	StorageCodec      string   `json:"storage_codec"` // compression the chunks are stored in (none, gzip or zstd), which is the server's default when omitted
+}
+
+// CommitUploadRequest is the request body of the commit of an open-ended upload
+type CommitUploadRequest struct {
+	TotalSize uint64 `json:"total_size" binding:"required"`
+	Checksum  string `json:"checksum"` // verified against the uploaded content when it's given
 }
 
 type MissingChunkInfo struct {
 	MaxChunkSize uint64   `json:"max_size"`
 	ChunkNumbers []uint64 `json:"chunk_numbers"`
+	Offsets      []uint64 `json:"offsets"` // bytes of each chunk in ChunkNumbers already stored, from which its upload continues
 }
 
 type InitUploadResponse struct {
-	UploadID         uint64           `json:"upload_id"`
-	MissingChunkInfo MissingChunkInfo `json:"missing_chunk_info"`
+	SessionToken     string            `json:"session_token"`
+	ExpiresAt        time.Time         `json:"expires_at"`
+	MissingChunkInfo *MissingChunkInfo `json:"missing_chunk_info,omitempty"` // the only chunks to be sent when the session is resumed or reuses chunks
 }
 
 type UploadResponse struct {
 	Status string `json:"status"`
 }
 
+type PutFileResponse struct {
+	Name              string `json:"name"`
+	Size              uint64 `json:"size"`
+	Checksum          string `json:"checksum"`
+	ChecksumAlgorithm string `json:"checksum_algorithm"`
+	MerkleRoot        string `json:"merkle_root"`
+}
+
 // InitUpload handles file upload initialization
 func (h *FileUploadHandler) ExecuteInit(ctx *gin.Context) {
 	// Get the file name from the URL
@@ -74,15 +121,42 @@ func (h *FileUploadHandler) ExecuteInit(ctx *gin.Context) {
 		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, "invalid request body"))
 		return
 	}
+	if req.ChecksumAlgorithm == "" {
+		req.ChecksumAlgorithm = string(checksum.DefaultAlgorithm)
+	}
+	if req.OpenEnded {
+		// the layout of an open-ended upload is validated on its commit
+		req.TotalSize, req.TotalChunks, req.Checksum, req.ChunkSizes, req.ChunkChecksums = 0, 0, "", nil, nil
+	}
+	if len(req.ChunkSizes) == 0 {
+		req.ChunkSizes = nil
+	}
+	if len(req.ChunkChecksums) == 0 {
+		req.ChunkChecksums = nil
+	}
+	for i, chunkChecksum := range req.ChunkChecksums {
+		req.ChunkChecksums[i] = strings.ToLower(chunkChecksum)
+	}
+	if err := validateUploadLayout(h.config.UploadLimits(), req); err != nil {
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, "invalid upload layout"))
+		return
+	}
 
 	// Initialize the upload
-	fileRecord, missingChunks, err := h.fileUploadUseCase.ExecuteInit(ctx.Request.Context(), usecase.FileUploadUseCaseExecuteInitInput{
-		FileName:    fileName,
-		Checksum:    req.Checksum,
-		TotalSize:   req.TotalSize,
-		TotalChunks: req.TotalChunks,
-		ChunkSize:   req.ChunkSize,
-		IsReUpload:  req.IsReUpload,
+	session, missingChunks, err := h.fileUploadUseCase.ExecuteInit(ctx.Request.Context(), usecase.FileUploadUseCaseExecuteInitInput{
+		FileName:          fileName,
+		Checksum:          req.Checksum,
+		ChecksumAlgorithm: req.ChecksumAlgorithm,
+		TotalSize:         req.TotalSize,
+		TotalChunks:       req.TotalChunks,
+		ChunkSize:         req.ChunkSize,
+		ChunkSizes:        req.ChunkSizes,
+		ChunkChecksums:    req.ChunkChecksums,
+		BaseFileID:        req.BaseFileID,
+		Owner:             ctx.GetHeader(UploadOwnerHeader),
+		IsReUpload:        req.IsReUpload && !req.OpenEnded,
+		OpenEnded:         req.OpenEnded,
+		StorageCodec:      entity.StorageCodec(req.StorageCodec),
 	})
 	if err != nil {
 		sendErrorResponse(ctx, h.logger, err)
@@ -91,68 +165,88 @@ func (h *FileUploadHandler) ExecuteInit(ctx *gin.Context) {
 
 	// Form the response and send it back
 	res := InitUploadResponse{
-		UploadID: fileRecord.ID,
+		SessionToken: session.Token,
+		ExpiresAt:    session.ExpiresAt,
 	}
 
-	if len(missingChunks) > 0 {
-		missingChunkInfo := MissingChunkInfo{
-			MaxChunkSize: fileRecord.ChunkSize,
+	if missingChunks != nil {
+		res.MissingChunkInfo = &MissingChunkInfo{
+			MaxChunkSize: session.File.ChunkSize,
 			ChunkNumbers: make([]uint64, len(missingChunks)),
+			Offsets:      make([]uint64, len(missingChunks)),
 		}
 		for i, chunk := range missingChunks {
-			missingChunkInfo.ChunkNumbers[i] = uint64(chunk.ChunkNumber)
+			res.MissingChunkInfo.ChunkNumbers[i] = uint64(chunk.ChunkNumber)
+			res.MissingChunkInfo.Offsets[i] = chunk.PartialSize
 		}
-		res.MissingChunkInfo = missingChunkInfo
 	}
 
 	ctx.JSON(http.StatusOK, res)
 }
 
+// ExecuteGetLimits returns the upload limits, so that the client can choose a valid chunk size before the upload
+func (h *FileUploadHandler) ExecuteGetLimits(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.config.UploadLimits())
+}
+
 // UploadChunk handles file chunk upload
 func (h *FileUploadHandler) Execute(ctx *gin.Context) {
-	fileIDStr := ctx.Param("file_id")
+	sessionToken := ctx.Param("session_token")
 	chunkNumberStr := ctx.Param("chunk_number")
 
-	fileID, err := strconv.ParseUint(fileIDStr, 10, 64)
+	chunkNumber, err := strconv.ParseUint(chunkNumberStr, 10, 64)
 	if err != nil {
-		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, fmt.Sprintf("invalid file ID: %s", fileIDStr)))
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, fmt.Sprintf("invalid chunk ID: %s", chunkNumberStr)))
+		return
+	}
+	// the chunks of an open-ended upload are created as they're uploaded, so their number is bounded here
+	if maxChunkCount := h.config.UploadLimits().MaxChunkCount; maxChunkCount > 0 && chunkNumber >= maxChunkCount {
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(
+			fmt.Errorf("chunk %d exceeds the maximum chunk count %d", chunkNumber, maxChunkCount),
+			"invalid chunk ID",
+		))
 		return
 	}
 
-	chunkNumber, err := strconv.ParseUint(chunkNumberStr, 10, 64)
+	declaredChecksum, err := chunkChecksum(ctx.Request.Header)
 	if err != nil {
-		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, fmt.Sprintf("invalid chunk ID: %s", chunkNumberStr)))
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, "invalid chunk checksum"))
 		return
 	}
 
-	// Setup the reader from request body
-	var reader io.Reader = ctx.Request.Body
-
-	// Check if content is gzip encoded
-	if ctx.GetHeader("Content-Encoding") == "gzip" {
-		gzipReader, err := gzip.NewReader(reader)
+	var offset uint64
+	if contentRange := ctx.GetHeader("Content-Range"); contentRange != "" {
+		offset, err = parseChunkContentRange(contentRange)
 		if err != nil {
-			sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, "failed to create gzip reader"))
+			sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, "invalid Content-Range"))
 			return
 		}
-		defer func() {
-			err := gzipReader.Close()
-			if err != nil {
-				sendErrorResponse(ctx, h.logger, e.NewContextError(err, "failed to close gzip reader"))
-			}
-		}()
-		reader = gzipReader
 	}
 
+	// Setup the reader decoding the request body in its Content-Encoding (gzip or zstd)
+	reader, err := newRequestBodyReader(ctx)
+	if err != nil {
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, "invalid Content-Encoding"))
+		return
+	}
+	defer func() {
+		err := reader.Close()
+		if err != nil {
+			sendErrorResponse(ctx, h.logger, e.NewContextError(err, "failed to close request body reader"))
+		}
+	}()
+
 	// Get the request context for client disconnect detection
 	reqCtx := ctx.Request.Context()
 	errChan := make(chan e.CustomError, 1)
 	// Run the upload process in a goroutine
 	go func() {
 		ucErr := h.fileUploadUseCase.Execute(reqCtx, usecase.FileUploadUseCaseExecuteInput{
-			FileID:      fileID,
-			ChunkNumber: chunkNumber,
-			Reader:      reader,
+			SessionToken: sessionToken,
+			ChunkNumber:  chunkNumber,
+			Offset:       offset,
+			Reader:       reader,
+			Checksum:     declaredChecksum,
 		})
 		errChan <- ucErr
 	}()
@@ -160,7 +254,10 @@ func (h *FileUploadHandler) Execute(ctx *gin.Context) {
 	// Wait for either the upload to complete or the context to be cancelled
 	select {
 	case <-reqCtx.Done():
-		if failRecovErr := h.fileUploadUseCase.ExecuteFailRecovery(context.Background(), fileID, chunkNumber); failRecovErr != nil {
+		// the content received so far is kept, so the upload needs to stop writing before the chunk is marked as
+		// failed and its size is reported to the re-upload
+		<-errChan
+		if failRecovErr := h.fileUploadUseCase.ExecuteFailRecovery(context.Background(), sessionToken, chunkNumber); failRecovErr != nil {
 			sendErrorResponse(ctx, h.logger, failRecovErr)
 			return
 		}
@@ -172,10 +269,206 @@ func (h *FileUploadHandler) Execute(ctx *gin.Context) {
 			return
 		}
 
-		if failRecovErr := h.fileUploadUseCase.ExecuteFailRecovery(ctx, fileID, chunkNumber); failRecovErr != nil {
+		if failRecovErr := h.fileUploadUseCase.ExecuteFailRecovery(ctx, sessionToken, chunkNumber); failRecovErr != nil {
 			sendErrorResponse(ctx, h.logger, failRecovErr)
 			return
 		}
 		sendErrorResponse(ctx, h.logger, ucErr)
 	}
 }
+
+// ExecuteCommit completes an open-ended upload with its total size and optionally its checksum once all of its chunks
+// are uploaded. The file is verified and bound to the name before the response
+func (h *FileUploadHandler) ExecuteCommit(ctx *gin.Context) {
+	sessionToken := ctx.Param("session_token")
+
+	var req CommitUploadRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, "invalid request body"))
+		return
+	}
+
+	_, ucErr := h.fileUploadUseCase.ExecuteCommit(ctx.Request.Context(), usecase.FileUploadUseCaseExecuteCommitInput{
+		SessionToken: sessionToken,
+		TotalSize:    req.TotalSize,
+		Checksum:     strings.ToLower(req.Checksum),
+		Limits:       h.config.UploadLimits(),
+	})
+	if ucErr != nil {
+		sendErrorResponse(ctx, h.logger, ucErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, UploadResponse{Status: "OK"})
+}
+
+// ExecuteAbort aborts an upload and deletes the content uploaded so far. The chunks being uploaded are canceled before
+// the response, and the file previously uploaded with the name is left as it is. The content is deleted even if the
+// client leaves in the meantime (e.g. the CLI interrupted by the user)
+func (h *FileUploadHandler) ExecuteAbort(ctx *gin.Context) {
+	if ucErr := h.fileUploadUseCase.ExecuteAbort(context.WithoutCancel(ctx.Request.Context()), ctx.Param("session_token")); ucErr != nil {
+		sendErrorResponse(ctx, h.logger, ucErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, UploadResponse{Status: "OK"})
+}
+
+// ExecutePut uploads a whole file by a single request, either as the raw body or as the "file" field of a
+// multipart/form-data body. The file is stored in a single chunk, so it can be up to the maximum chunk size
+func (h *FileUploadHandler) ExecutePut(ctx *gin.Context) {
+	fileName := ctx.Param("file_name")
+	if !isValidFileName(fileName) {
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(errors.New("invalid file name"), fileName))
+		return
+	}
+
+	algorithm := ctx.GetHeader(FileChecksumAlgorithmHeader)
+	if algorithm == "" {
+		algorithm = string(checksum.DefaultAlgorithm)
+	}
+	var declaredChecksum string
+	if value := ctx.GetHeader(FileChecksumHeader); value != "" {
+		decoded, err := hex.DecodeString(value)
+		if err != nil {
+			sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, fmt.Sprintf("%s needs to be hex encoded", FileChecksumHeader)))
+			return
+		}
+		declaredChecksum = hex.EncodeToString(decoded)
+	}
+
+	// a raw body declaring a larger size is rejected before it's read
+	maxSize := h.config.UploadLimits().MaxSingleChunkFileSize()
+	contentEncoding := ctx.GetHeader("Content-Encoding")
+	mediaType, params, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	isRaw := contentEncoding == "" && mediaType != "multipart/form-data"
+	if maxSize > 0 && isRaw && ctx.Request.ContentLength > int64(maxSize) {
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(
+			fmt.Errorf("content of %s exceeds %d bytes, which needs to be uploaded in chunks", fileName, maxSize),
+			"invalid content",
+		))
+		return
+	}
+
+	bodyReader, err := newRequestBodyReader(ctx)
+	if err != nil {
+		sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, "invalid Content-Encoding"))
+		return
+	}
+	defer func() { _ = bodyReader.Close() }()
+	var reader io.Reader = bodyReader
+	if mediaType == "multipart/form-data" {
+		part, err := formFilePart(multipart.NewReader(reader, params["boundary"]))
+		if err != nil {
+			sendErrorResponse(ctx, h.logger, e.NewInvalidInputError(err, "invalid multipart/form-data body"))
+			return
+		}
+		reader = part
+	}
+
+	file, ucErr := h.fileUploadUseCase.ExecutePut(ctx.Request.Context(), usecase.FileUploadUseCaseExecutePutInput{
+		FileName:          fileName,
+		Reader:            reader,
+		Checksum:          declaredChecksum,
+		ChecksumAlgorithm: algorithm,
+		MaxSize:           maxSize,
+		StorageCodec:      entity.StorageCodec(ctx.GetHeader(FileStorageCodecHeader)),
+	})
+	if ucErr != nil {
+		sendErrorResponse(ctx, h.logger, ucErr)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, PutFileResponse{
+		Name:              file.Name,
+		Size:              file.Size,
+		Checksum:          file.Checksum,
+		ChecksumAlgorithm: file.ChecksumAlgorithm,
+		MerkleRoot:        file.MerkleRoot,
+	})
+}
+
+// validateUploadLayout checks that the layout of the upload initialization is within the limits. The chunks of the
+// given sizes need to make up the total size, while the other chunks have the chunk size except the last one
+func validateUploadLayout(limits entity.UploadLimits, req InitUploadRequest) error {
+	if req.ChunkSizes == nil {
+		return limits.Validate(req.TotalSize, req.ChunkSize, uint64(req.TotalChunks))
+	}
+	if uint(len(req.ChunkSizes)) != req.TotalChunks {
+		return fmt.Errorf("%d chunk sizes are given for %d chunks", len(req.ChunkSizes), req.TotalChunks)
+	}
+	return limits.ValidateChunkSizes(req.TotalSize, req.ChunkSize, req.ChunkSizes)
+}
+
+// formFilePart returns the part of the multipart/form-data body which carries the file content. The parts before it
+// are skipped
+func formFilePart(reader *multipart.Reader) (*multipart.Part, error) {
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%q field is required", PutFileFormField)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == PutFileFormField {
+			return part, nil
+		}
+	}
+}
+
+// parseChunkContentRange parses the Content-Range header (e.g. "bytes 100-199/200") of a chunk upload which continues
+// the content of the chunk received so far, and returns the offset in the chunk the content starts at. The content
+// needs to run to the end of the chunk, whose size is the complete length.
+func parseChunkContentRange(header string) (uint64, error) {
+	const prefix = "bytes "
+	if !strings.HasPrefix(header, prefix) {
+		return 0, fmt.Errorf("Content-Range needs to be in bytes: %s", header)
+	}
+	byteRange, sizeStr, found := strings.Cut(header[len(prefix):], "/")
+	if !found {
+		return 0, fmt.Errorf("Content-Range needs the chunk size: %s", header)
+	}
+	startStr, endStr, found := strings.Cut(byteRange, "-")
+	if !found {
+		return 0, fmt.Errorf("Content-Range needs a byte range: %s", header)
+	}
+	start, startErr := strconv.ParseUint(startStr, 10, 64)
+	end, endErr := strconv.ParseUint(endStr, 10, 64)
+	size, sizeErr := strconv.ParseUint(sizeStr, 10, 64)
+	if startErr != nil || endErr != nil || sizeErr != nil {
+		return 0, fmt.Errorf("Content-Range needs to be \"bytes <start>-<end>/<size>\": %s", header)
+	}
+	if start > end || end+1 != size {
+		return 0, fmt.Errorf("Content-Range needs to cover the rest of the chunk: %s", header)
+	}
+
+	return start, nil
+}
+
+// chunkChecksum returns the SHA-256 checksum of the chunk content in hex declared by either the "X-Chunk-SHA256"
+// header (hex) or the "Digest" header (e.g. "sha-256=<base64>"). It returns an empty string when none is declared.
+// The checksum is of the content before the Content-Encoding is applied.
+func chunkChecksum(header http.Header) (string, error) {
+	if value := header.Get("X-Chunk-SHA256"); value != "" {
+		decoded, err := hex.DecodeString(value)
+		if err != nil || len(decoded) != sha256.Size {
+			return "", fmt.Errorf("X-Chunk-SHA256 needs to be a hex encoded SHA-256: %s", value)
+		}
+		return hex.EncodeToString(decoded), nil
+	}
+
+	for _, digest := range strings.Split(header.Get("Digest"), ",") {
+		algorithm, value, found := strings.Cut(strings.TrimSpace(digest), "=")
+		if !found || !strings.EqualFold(algorithm, "sha-256") {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(decoded) != sha256.Size {
+			return "", fmt.Errorf("Digest needs to be a base64 encoded SHA-256: %s", value)
+		}
+		return hex.EncodeToString(decoded), nil
+	}
+
+	return "", nil
+}
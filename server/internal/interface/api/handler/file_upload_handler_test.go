@@ -7,10 +7,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -20,6 +22,7 @@ import (
 	"github.com/tomoya.tokunaga/server/internal/interface/api/handler"
 	"github.com/tomoya.tokunaga/server/internal/mock"
 	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"github.com/tomoya.tokunaga/server/internal/util/compression"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/exp/slog"
 )
@@ -28,7 +31,7 @@ import (
 func setupTestFileUploadHandler(t *testing.T, useCase usecase.FileUploadUseCase) (*handler.FileUploadHandler, *gin.Engine) {
 	t.Helper()
 	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
-	config := &entity.Config{}
+	config := &entity.Config{MaxFileSize: 1024 * 1024, MaxChunkSize: 4096, MaxChunkCount: 8}
 	h := handler.NewFileUploadHandler(useCase, config, logger)
 
 	gin.SetMode(gin.TestMode)
@@ -37,16 +40,36 @@ func setupTestFileUploadHandler(t *testing.T, useCase usecase.FileUploadUseCase)
 	return h, router
 }
 
+// encodeContent returns the content encoded in the encoding
+func encodeContent(t *testing.T, encoding compression.Encoding, content string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := compression.NewWriter(encoding, &buf, compression.DefaultLevel)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.String()
+}
+
 func TestFileUploadHandler_ExecuteInit(t *testing.T) {
 	testFileName := "testfile.txt"
-	mockFileRecord := &entity.File{ID: 1, Name: testFileName, ChunkSize: 1024}
-	mockMissingChunks := []*entity.FileChunk{{ChunkNumber: 1}, {ChunkNumber: 3}}
+	mockSession := &entity.UploadSession{
+		ID:         1,
+		Token:      "session-token",
+		FileID:     1,
+		TargetName: testFileName,
+		ExpiresAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		File:       &entity.File{ID: 1, Name: testFileName, ChunkSize: 1024},
+	}
+	mockMissingChunks := []*entity.FileChunk{{ChunkNumber: 1, PartialSize: 512}, {ChunkNumber: 3}}
 	mockUsecaseError := e.NewMockCustomError(errors.New("usecase init error"), "Usecase init error", http.StatusInternalServerError)
 
 	testCases := []struct {
 		name             string
 		fileNameParam    string
 		requestBody      handler.InitUploadRequest
+		headers          map[string]string
 		setupMock        func(mockUseCase *mock.MockFileUploadUseCase)
 		expectedStatus   int
 		expectedBody     *handler.InitUploadResponse
@@ -64,15 +87,16 @@ func TestFileUploadHandler_ExecuteInit(t *testing.T) {
 				IsReUpload:  false,
 			},
 			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
-				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), gomock.Any()).Return(mockFileRecord, nil, nil)
+				// the checksum algorithm defaults to sha256 when it's not declared
+				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: testFileName, Checksum: "abc", ChecksumAlgorithm: "sha256", TotalSize: 2048, TotalChunks: 2, ChunkSize: 1024,
+				}).Return(mockSession, nil, nil)
 			},
 			expectedStatus: http.StatusOK,
+			// a new session has no missing chunk info, so that all the chunks are sent
 			expectedBody: &handler.InitUploadResponse{
-				UploadID: mockFileRecord.ID,
-				MissingChunkInfo: handler.MissingChunkInfo{
-					MaxChunkSize: 0,          // Expect zero when no missing chunks
-					ChunkNumbers: []uint64{}, // Expect empty slice
-				},
+				SessionToken: mockSession.Token,
+				ExpiresAt:    mockSession.ExpiresAt,
 			},
 			expectError: false,
 		},
@@ -80,21 +104,29 @@ func TestFileUploadHandler_ExecuteInit(t *testing.T) {
 			name:          "Success - With missing chunks",
 			fileNameParam: testFileName,
 			requestBody: handler.InitUploadRequest{
-				Checksum:    "def",
-				TotalSize:   3072,
-				TotalChunks: 3,
-				ChunkSize:   1024,
-				IsReUpload:  true,
+				Checksum:          "def",
+				ChecksumAlgorithm: "xxhash64",
+				TotalSize:         3072,
+				TotalChunks:       3,
+				ChunkSize:         1024,
+				IsReUpload:        true,
 			},
+			// the session of the owner is resumed
+			headers: map[string]string{handler.UploadOwnerHeader: "alice@host"},
 			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
-				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), gomock.Any()).Return(mockFileRecord, mockMissingChunks, nil)
+				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: testFileName, Checksum: "def", ChecksumAlgorithm: "xxhash64", TotalSize: 3072, TotalChunks: 3, ChunkSize: 1024,
+					Owner: "alice@host", IsReUpload: true,
+				}).Return(mockSession, mockMissingChunks, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectedBody: &handler.InitUploadResponse{
-				UploadID: mockFileRecord.ID,
-				MissingChunkInfo: handler.MissingChunkInfo{
-					MaxChunkSize: mockFileRecord.ChunkSize,
+				SessionToken: mockSession.Token,
+				ExpiresAt:    mockSession.ExpiresAt,
+				MissingChunkInfo: &handler.MissingChunkInfo{
+					MaxChunkSize: mockSession.File.ChunkSize,
 					ChunkNumbers: []uint64{1, 3}, // Corresponding to mockMissingChunks
+					Offsets:      []uint64{512, 0},
 				},
 			},
 			expectError: false,
@@ -126,6 +158,212 @@ func TestFileUploadHandler_ExecuteInit(t *testing.T) {
 			expectError:      true,
 			expectedErrorMsg: "invalid request body", // Gin binding error message might vary slightly
 		},
+		{
+			name:          "Error - Inconsistent chunk count",
+			fileNameParam: testFileName,
+			requestBody: handler.InitUploadRequest{
+				Checksum:    "jkl",
+				TotalSize:   2049,
+				TotalChunks: 2,
+				ChunkSize:   1024,
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectError:      true,
+			expectedErrorMsg: "2049 bytes split by 1024 bytes needs 3 chunks, but got 2",
+		},
+		{
+			name:          "Error - File size exceeds the limit",
+			fileNameParam: testFileName,
+			requestBody: handler.InitUploadRequest{
+				Checksum:    "jkl",
+				TotalSize:   1024*1024 + 1,
+				TotalChunks: 257,
+				ChunkSize:   4096,
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectError:      true,
+			expectedErrorMsg: "file size 1048577 exceeds the maximum 1048576",
+		},
+		{
+			name:          "Error - Chunk size exceeds the limit",
+			fileNameParam: testFileName,
+			requestBody: handler.InitUploadRequest{
+				Checksum:    "jkl",
+				TotalSize:   8192,
+				TotalChunks: 1,
+				ChunkSize:   8192,
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectError:      true,
+			expectedErrorMsg: "chunk size 8192 exceeds the maximum 4096",
+		},
+		{
+			name:          "Error - Chunk count exceeds the limit",
+			fileNameParam: testFileName,
+			requestBody: handler.InitUploadRequest{
+				Checksum:    "jkl",
+				TotalSize:   9,
+				TotalChunks: 9,
+				ChunkSize:   1,
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectError:      true,
+			expectedErrorMsg: "chunk count 9 exceeds the maximum 8",
+		},
+		{
+			name:          "Success - Variable chunk sizes",
+			fileNameParam: testFileName,
+			requestBody: handler.InitUploadRequest{
+				Checksum:    "mno",
+				TotalSize:   3000,
+				TotalChunks: 3,
+				ChunkSize:   4096,
+				ChunkSizes:  []uint64{1200, 4, 1796},
+			},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: testFileName, Checksum: "mno", ChecksumAlgorithm: "sha256", TotalSize: 3000, TotalChunks: 3, ChunkSize: 4096,
+					ChunkSizes: []uint64{1200, 4, 1796},
+				}).Return(mockSession, nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: &handler.InitUploadResponse{
+				SessionToken: mockSession.Token,
+				ExpiresAt:    mockSession.ExpiresAt,
+			},
+			expectError: false,
+		},
+		{
+			name:          "Success - Reuse chunks of base file",
+			fileNameParam: testFileName,
+			requestBody: handler.InitUploadRequest{
+				Checksum:       "pqr",
+				TotalSize:      3072,
+				TotalChunks:    3,
+				ChunkSize:      1024,
+				ChunkChecksums: []string{"AAAA", "bbbb", "CCCC"},
+				BaseFileID:     7,
+			},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				// the chunk checksums are compared in lowercase hex
+				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: testFileName, Checksum: "pqr", ChecksumAlgorithm: "sha256", TotalSize: 3072, TotalChunks: 3, ChunkSize: 1024,
+					ChunkChecksums: []string{"aaaa", "bbbb", "cccc"}, BaseFileID: 7,
+				}).Return(mockSession, []*entity.FileChunk{{ChunkNumber: 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: &handler.InitUploadResponse{
+				SessionToken: mockSession.Token,
+				ExpiresAt:    mockSession.ExpiresAt,
+				MissingChunkInfo: &handler.MissingChunkInfo{
+					MaxChunkSize: mockSession.File.ChunkSize,
+					ChunkNumbers: []uint64{1},
+					Offsets:      []uint64{0},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name:          "Success - All chunks reused from base file",
+			fileNameParam: testFileName,
+			requestBody: handler.InitUploadRequest{
+				Checksum:       "pqr",
+				TotalSize:      1024,
+				TotalChunks:    1,
+				ChunkSize:      1024,
+				ChunkChecksums: []string{"aaaa"},
+				BaseFileID:     7,
+			},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: testFileName, Checksum: "pqr", ChecksumAlgorithm: "sha256", TotalSize: 1024, TotalChunks: 1, ChunkSize: 1024,
+					ChunkChecksums: []string{"aaaa"}, BaseFileID: 7,
+				}).Return(mockSession, []*entity.FileChunk{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			// the missing chunk info without chunks tells that nothing needs to be sent
+			expectedBody: &handler.InitUploadResponse{
+				SessionToken: mockSession.Token,
+				ExpiresAt:    mockSession.ExpiresAt,
+				MissingChunkInfo: &handler.MissingChunkInfo{
+					MaxChunkSize: mockSession.File.ChunkSize,
+					ChunkNumbers: []uint64{},
+					Offsets:      []uint64{},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name:          "Error - Variable chunk sizes don't make up the file",
+			fileNameParam: testFileName,
+			requestBody: handler.InitUploadRequest{
+				Checksum:    "mno",
+				TotalSize:   3000,
+				TotalChunks: 2,
+				ChunkSize:   4096,
+				ChunkSizes:  []uint64{1200, 1796},
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectError:      true,
+			expectedErrorMsg: "2 chunks make up 2996 bytes, but the file has 3000 bytes",
+		},
+		{
+			name:          "Error - Variable chunk sizes mismatch the chunk count",
+			fileNameParam: testFileName,
+			requestBody: handler.InitUploadRequest{
+				Checksum:    "mno",
+				TotalSize:   3000,
+				TotalChunks: 3,
+				ChunkSize:   4096,
+				ChunkSizes:  []uint64{1200, 1800},
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectError:      true,
+			expectedErrorMsg: "2 chunk sizes are given for 3 chunks",
+		},
+		{
+			name:          "Success - Open-ended",
+			fileNameParam: testFileName,
+			// the size, the chunk count and the checksum are given on the commit
+			requestBody: handler.InitUploadRequest{
+				ChunkSize:  1024,
+				OpenEnded:  true,
+				IsReUpload: true,
+			},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: testFileName, ChecksumAlgorithm: "sha256", ChunkSize: 1024, OpenEnded: true,
+				}).Return(mockSession, nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: &handler.InitUploadResponse{
+				SessionToken: mockSession.Token,
+				ExpiresAt:    mockSession.ExpiresAt,
+			},
+			expectError: false,
+		},
+		{
+			name:          "Success - Storage codec",
+			fileNameParam: testFileName,
+			requestBody: handler.InitUploadRequest{
+				Checksum:     "jkl",
+				TotalSize:    1024,
+				TotalChunks:  1,
+				ChunkSize:    1024,
+				StorageCodec: "zstd",
+			},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteInit(gomock.Any(), usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: testFileName, Checksum: "jkl", ChecksumAlgorithm: "sha256", TotalSize: 1024, TotalChunks: 1, ChunkSize: 1024, StorageCodec: entity.StorageCodecZstd,
+				}).Return(mockSession, nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: &handler.InitUploadResponse{
+				SessionToken: mockSession.Token,
+				ExpiresAt:    mockSession.ExpiresAt,
+			},
+			expectError: false,
+		},
 		{
 			name:          "Error - Usecase error",
 			fileNameParam: testFileName,
@@ -179,6 +417,9 @@ func TestFileUploadHandler_ExecuteInit(t *testing.T) {
 
 			req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, url, reqBodyReader)
 			req.Header.Set("Content-Type", "application/json")
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
 			router.ServeHTTP(w, req)
 
 			assert.Equal(t, tc.expectedStatus, w.Code, "Status code mismatch")
@@ -189,13 +430,6 @@ func TestFileUploadHandler_ExecuteInit(t *testing.T) {
 				err := json.Unmarshal(w.Body.Bytes(), &actualBody)
 				require.NoError(t, err, "Failed to unmarshal success response: %s", w.Body.String())
 
-				// Special handling for missing chunk info slices (nil vs empty)
-				if tc.expectedBody.MissingChunkInfo.ChunkNumbers == nil {
-					tc.expectedBody.MissingChunkInfo.ChunkNumbers = []uint64{}
-				}
-				if actualBody.MissingChunkInfo.ChunkNumbers == nil {
-					actualBody.MissingChunkInfo.ChunkNumbers = []uint64{}
-				}
 				assert.Equal(t, *tc.expectedBody, actualBody)
 			} else {
 				var errorResponse map[string]any
@@ -212,17 +446,35 @@ func TestFileUploadHandler_ExecuteInit(t *testing.T) {
 	}
 }
 
+func TestFileUploadHandler_ExecuteGetLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h, router := setupTestFileUploadHandler(t, mock.NewMockFileUploadUseCase(ctrl))
+	router.GET("/files/upload/limits", h.ExecuteGetLimits)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, "/files/upload/limits", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var limits entity.UploadLimits
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &limits))
+	assert.Equal(t, entity.UploadLimits{MaxFileSize: 1024 * 1024, MaxChunkSize: 4096, MaxChunkCount: 8}, limits)
+}
+
 func TestFileUploadHandler_Execute(t *testing.T) {
-	mockFileID := uint64(1)
+	mockSessionToken := "session-token"
 	mockChunkNumber := uint64(1)
 	mockUsecaseError := e.NewMockCustomError(errors.New("usecase execute error"), "Usecase execute error", http.StatusInternalServerError)
 	mockFailRecoveryError := e.NewMockCustomError(errors.New("fail recovery error"), "Fail recovery error", http.StatusInternalServerError)
 
 	testCases := []struct {
 		name              string
-		fileIDParam       string
+		sessionTokenParam string
 		chunkNumberParam  string
 		fileContent       string
+		headers           map[string]string
 		setupMock         func(mockUseCase *mock.MockFileUploadUseCase)
 		expectedStatus    int
 		expectedBody      *handler.UploadResponse
@@ -231,10 +483,10 @@ func TestFileUploadHandler_Execute(t *testing.T) {
 		failRecoveryError bool // Flag to simulate error during fail recovery
 	}{
 		{
-			name:             "Success",
-			fileIDParam:      strconv.FormatUint(mockFileID, 10),
-			chunkNumberParam: strconv.FormatUint(mockChunkNumber, 10),
-			fileContent:      "this is chunk 1 data",
+			name:              "Success",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  strconv.FormatUint(mockChunkNumber, 10),
+			fileContent:       "this is chunk 1 data",
 			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
 				mockUseCase.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(nil)
 			},
@@ -243,33 +495,143 @@ func TestFileUploadHandler_Execute(t *testing.T) {
 			expectError:    false,
 		},
 		{
-			name:             "Error - Invalid file ID",
-			fileIDParam:      "invalid-id",
-			chunkNumberParam: strconv.FormatUint(mockChunkNumber, 10),
-			fileContent:      "some data",
-			setupMock:        nil,
-			expectedStatus:   http.StatusBadRequest,
-			expectError:      true,
-			expectedErrorMsg: "invalid file ID: invalid-id",
+			name:              "Success - With X-Chunk-SHA256 header",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  strconv.FormatUint(mockChunkNumber, 10),
+			fileContent:       "hello world",
+			headers:           map[string]string{"X-Chunk-SHA256": "B94D27B9934D3E08A52E52D7DA7DABFAC484EFE37A5380EE9088F7ACE2EFCDE9"},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, input usecase.FileUploadUseCaseExecuteInput) e.CustomError {
+					assert.Equal(t, "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", input.Checksum)
+					return nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   &handler.UploadResponse{Status: "OK"},
+			expectError:    false,
 		},
 		{
-			name:             "Error - Invalid chunk number",
-			fileIDParam:      strconv.FormatUint(mockFileID, 10),
-			chunkNumberParam: "invalid-chunk",
-			fileContent:      "some data",
-			setupMock:        nil,
-			expectedStatus:   http.StatusBadRequest,
-			expectError:      true,
-			expectedErrorMsg: "invalid chunk ID: invalid-chunk",
+			name:              "Success - With Digest header",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  strconv.FormatUint(mockChunkNumber, 10),
+			fileContent:       "hello world",
+			headers:           map[string]string{"Digest": "md5=XrY7u+Ae7tCTyyK7j1rNww==, sha-256=uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, input usecase.FileUploadUseCaseExecuteInput) e.CustomError {
+					assert.Equal(t, "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", input.Checksum)
+					return nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   &handler.UploadResponse{Status: "OK"},
+			expectError:    false,
+		},
+		{
+			name:              "Success - zstd encoded body",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  strconv.FormatUint(mockChunkNumber, 10),
+			fileContent:       encodeContent(t, compression.Zstd, "hello world"),
+			headers:           map[string]string{"Content-Encoding": "zstd"},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, input usecase.FileUploadUseCaseExecuteInput) e.CustomError {
+					content, err := io.ReadAll(input.Reader)
+					assert.NoError(t, err)
+					assert.Equal(t, "hello world", string(content))
+					return nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   &handler.UploadResponse{Status: "OK"},
+			expectError:    false,
+		},
+		{
+			name:              "Error - Unsupported Content-Encoding",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  strconv.FormatUint(mockChunkNumber, 10),
+			fileContent:       "hello world",
+			headers:           map[string]string{"Content-Encoding": "br"},
+			setupMock:         nil,
+			expectedStatus:    http.StatusBadRequest,
+			expectError:       true,
+			expectedErrorMsg:  `unsupported content encoding "br"`,
+		},
+		{
+			name:              "Success - With Content-Range header",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  strconv.FormatUint(mockChunkNumber, 10),
+			fileContent:       "world",
+			headers:           map[string]string{"Content-Range": "bytes 6-10/11"},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().Execute(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, input usecase.FileUploadUseCaseExecuteInput) e.CustomError {
+					assert.Equal(t, uint64(6), input.Offset)
+					return nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   &handler.UploadResponse{Status: "OK"},
+			expectError:    false,
+		},
+		{
+			name:              "Error - Content-Range not covering the rest of the chunk",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  strconv.FormatUint(mockChunkNumber, 10),
+			fileContent:       "wor",
+			headers:           map[string]string{"Content-Range": "bytes 6-8/11"},
+			setupMock:         nil,
+			expectedStatus:    http.StatusBadRequest,
+			expectError:       true,
+			expectedErrorMsg:  "invalid Content-Range",
+		},
+		{
+			name:              "Error - Malformed Content-Range header",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  strconv.FormatUint(mockChunkNumber, 10),
+			fileContent:       "world",
+			headers:           map[string]string{"Content-Range": "bytes 6-/11"},
+			setupMock:         nil,
+			expectedStatus:    http.StatusBadRequest,
+			expectError:       true,
+			expectedErrorMsg:  "invalid Content-Range",
+		},
+		{
+			name:              "Error - Invalid X-Chunk-SHA256 header",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  strconv.FormatUint(mockChunkNumber, 10),
+			fileContent:       "hello world",
+			headers:           map[string]string{"X-Chunk-SHA256": "not-a-hash"},
+			setupMock:         nil,
+			expectedStatus:    http.StatusBadRequest,
+			expectError:       true,
+			expectedErrorMsg:  "invalid chunk checksum",
 		},
 		{
-			name:             "Error - Usecase execute error (with fail recovery)",
-			fileIDParam:      strconv.FormatUint(mockFileID, 10),
-			chunkNumberParam: strconv.FormatUint(mockChunkNumber, 10),
-			fileContent:      "some data",
+			name:              "Error - Invalid chunk number",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  "invalid-chunk",
+			fileContent:       "some data",
+			setupMock:         nil,
+			expectedStatus:    http.StatusBadRequest,
+			expectError:       true,
+			expectedErrorMsg:  "invalid chunk ID: invalid-chunk",
+		},
+		{
+			name:              "Error - Chunk number exceeds the chunk count limit",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  "8",
+			fileContent:       "some data",
+			setupMock:         nil,
+			expectedStatus:    http.StatusBadRequest,
+			expectError:       true,
+			expectedErrorMsg:  "chunk 8 exceeds the maximum chunk count 8",
+		},
+		{
+			name:              "Error - Usecase execute error (with fail recovery)",
+			sessionTokenParam: mockSessionToken,
+			chunkNumberParam:  strconv.FormatUint(mockChunkNumber, 10),
+			fileContent:       "some data",
 			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
 				mockUseCase.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(mockUsecaseError)
-				mockUseCase.EXPECT().ExecuteFailRecovery(gomock.Any(), mockFileID, mockChunkNumber).Return(nil) // Successful recovery
+				mockUseCase.EXPECT().ExecuteFailRecovery(gomock.Any(), mockSessionToken, mockChunkNumber).Return(nil) // Successful recovery
 			},
 			expectedStatus:   mockUsecaseError.StatusCode(),
 			expectError:      true,
@@ -277,13 +639,13 @@ func TestFileUploadHandler_Execute(t *testing.T) {
 		},
 		{
 			name:              "Error - Usecase execute error (with fail recovery error)",
-			fileIDParam:       strconv.FormatUint(mockFileID, 10),
+			sessionTokenParam: mockSessionToken,
 			chunkNumberParam:  strconv.FormatUint(mockChunkNumber, 10),
 			fileContent:       "some data",
 			failRecoveryError: true,
 			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
 				mockUseCase.EXPECT().Execute(gomock.Any(), gomock.Any()).Return(mockUsecaseError)
-				mockUseCase.EXPECT().ExecuteFailRecovery(gomock.Any(), mockFileID, mockChunkNumber).Return(mockFailRecoveryError) // Error during recovery
+				mockUseCase.EXPECT().ExecuteFailRecovery(gomock.Any(), mockSessionToken, mockChunkNumber).Return(mockFailRecoveryError) // Error during recovery
 			},
 			expectedStatus:   mockFailRecoveryError.StatusCode(), // Expect recovery error status
 			expectError:      true,
@@ -307,10 +669,10 @@ func TestFileUploadHandler_Execute(t *testing.T) {
 			}
 
 			h, router := setupTestFileUploadHandler(t, mockUseCase)
-			router.POST("/files/upload/:file_id/:chunk_number", h.Execute)
+			router.POST("/files/upload/:session_token/:chunk_number", h.Execute)
 
 			w := httptest.NewRecorder()
-			url := fmt.Sprintf("/files/upload/%s/%s", tc.fileIDParam, tc.chunkNumberParam)
+			url := fmt.Sprintf("/files/upload/%s/%s", tc.sessionTokenParam, tc.chunkNumberParam)
 
 			// Create request body with application/octet-stream
 			body := bytes.NewBufferString(tc.fileContent)
@@ -321,6 +683,9 @@ func TestFileUploadHandler_Execute(t *testing.T) {
 
 			req, _ := http.NewRequestWithContext(reqCtx, http.MethodPost, url, body)
 			req.Header.Set("Content-Type", "application/octet-stream")
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
 
 			router.ServeHTTP(w, req)
 
@@ -348,3 +713,349 @@ func TestFileUploadHandler_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestFileUploadHandler_ExecuteCommit(t *testing.T) {
+	mockSessionToken := "session-token"
+	mockUsecaseError := e.NewMockCustomError(errors.New("usecase commit error"), "Usecase commit error", http.StatusBadRequest)
+
+	testCases := []struct {
+		name             string
+		requestBody      string
+		setupMock        func(mockUseCase *mock.MockFileUploadUseCase)
+		expectedStatus   int
+		expectedErrorMsg string
+	}{
+		{
+			name:        "Success",
+			requestBody: `{"total_size": 2048, "checksum": "ABC"}`,
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteCommit(gomock.Any(), usecase.FileUploadUseCaseExecuteCommitInput{
+					SessionToken: mockSessionToken,
+					TotalSize:    2048,
+					Checksum:     "abc",
+					Limits:       entity.UploadLimits{MaxFileSize: 1024 * 1024, MaxChunkSize: 4096, MaxChunkCount: 8},
+				}).Return(&entity.File{ID: 1}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:             "Error - Missing total size",
+			requestBody:      `{"checksum": "abc"}`,
+			setupMock:        nil,
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "invalid request body",
+		},
+		{
+			name:        "Error - Usecase error",
+			requestBody: `{"total_size": 2048}`,
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteCommit(gomock.Any(), gomock.Any()).Return(nil, mockUsecaseError)
+			},
+			expectedStatus:   mockUsecaseError.StatusCode(),
+			expectedErrorMsg: mockUsecaseError.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUseCase := mock.NewMockFileUploadUseCase(ctrl)
+			if tc.setupMock != nil {
+				tc.setupMock(mockUseCase)
+			}
+
+			h, router := setupTestFileUploadHandler(t, mockUseCase)
+			router.POST("/files/upload/commit/:session_token", h.ExecuteCommit)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodPost, "/files/upload/commit/"+mockSessionToken, bytes.NewBufferString(tc.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code, "Status code mismatch")
+			if tc.expectedErrorMsg == "" {
+				var actualBody handler.UploadResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actualBody))
+				assert.Equal(t, handler.UploadResponse{Status: "OK"}, actualBody)
+			} else {
+				var errorResponse map[string]any
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+				errorMsg, ok := errorResponse["error"].(string)
+				require.True(t, ok, "Error message is not a string")
+				assert.Contains(t, errorMsg, tc.expectedErrorMsg)
+			}
+		})
+	}
+}
+
+func TestFileUploadHandler_ExecuteAbort(t *testing.T) {
+	mockSessionToken := "session-token"
+	mockUsecaseError := e.NewMockCustomError(errors.New("upload of test.txt is already committed"), "", http.StatusConflict)
+
+	testCases := []struct {
+		name             string
+		setupMock        func(mockUseCase *mock.MockFileUploadUseCase)
+		expectedStatus   int
+		expectedErrorMsg string
+	}{
+		{
+			name: "Success",
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteAbort(gomock.Any(), mockSessionToken).
+					DoAndReturn(func(ctx context.Context, _ string) e.CustomError {
+						// the content is deleted even if the client leaves
+						assert.Nil(t, ctx.Done())
+						return nil
+					})
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Error - Usecase error",
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecuteAbort(gomock.Any(), mockSessionToken).Return(mockUsecaseError)
+			},
+			expectedStatus:   http.StatusConflict,
+			expectedErrorMsg: mockUsecaseError.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUseCase := mock.NewMockFileUploadUseCase(ctrl)
+			tc.setupMock(mockUseCase)
+
+			h, router := setupTestFileUploadHandler(t, mockUseCase)
+			router.DELETE("/files/upload/:session_token", h.ExecuteAbort)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodDelete, "/files/upload/"+mockSessionToken, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code, "Status code mismatch")
+			if tc.expectedErrorMsg == "" {
+				var actualBody handler.UploadResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actualBody))
+				assert.Equal(t, handler.UploadResponse{Status: "OK"}, actualBody)
+			} else {
+				var errorResponse map[string]any
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+				errorMsg, ok := errorResponse["error"].(string)
+				require.True(t, ok, "Error message is not a string")
+				assert.Contains(t, errorMsg, tc.expectedErrorMsg)
+			}
+		})
+	}
+}
+
+func TestFileUploadHandler_ExecutePut(t *testing.T) {
+	testFileName := "config.yaml"
+	mockFile := &entity.File{
+		ID:                1,
+		Name:              testFileName,
+		Size:              11,
+		Checksum:          "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+		ChecksumAlgorithm: "sha256",
+		MerkleRoot:        "merkle-root",
+		Status:            entity.FileStatusUploaded,
+	}
+	mockUsecaseError := e.NewChecksumMismatchError(errors.New("checksum mismatch"), "config.yaml is corrupt")
+
+	multipartBody := func(fieldName string, content string) (string, *bytes.Buffer) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		_ = writer.WriteField("comment", "skipped")
+		part, _ := writer.CreateFormFile(fieldName, testFileName)
+		_, _ = part.Write([]byte(content))
+		_ = writer.Close()
+		return writer.FormDataContentType(), body
+	}
+
+	testCases := []struct {
+		name             string
+		fileNameParam    string
+		contentType      string
+		body             func() (string, *bytes.Buffer)
+		headers          map[string]string
+		setupMock        func(mockUseCase *mock.MockFileUploadUseCase)
+		expectedStatus   int
+		expectedBody     *handler.PutFileResponse
+		expectedErrorMsg string
+	}{
+		{
+			name:          "Success - Raw body",
+			fileNameParam: testFileName,
+			body: func() (string, *bytes.Buffer) {
+				return "application/octet-stream", bytes.NewBufferString("hello world")
+			},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecutePut(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, input usecase.FileUploadUseCaseExecutePutInput) (*entity.File, e.CustomError) {
+					content, _ := io.ReadAll(input.Reader)
+					assert.Equal(t, testFileName, input.FileName)
+					assert.Equal(t, "hello world", string(content))
+					assert.Equal(t, "", input.Checksum)
+					assert.Equal(t, "sha256", input.ChecksumAlgorithm)
+					assert.Equal(t, uint64(4096), input.MaxSize)
+					return mockFile, nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: &handler.PutFileResponse{
+				Name:              testFileName,
+				Size:              11,
+				Checksum:          mockFile.Checksum,
+				ChecksumAlgorithm: "sha256",
+				MerkleRoot:        "merkle-root",
+			},
+		},
+		{
+			name:          "Success - Multipart form body with checksum headers",
+			fileNameParam: testFileName,
+			body: func() (string, *bytes.Buffer) {
+				return multipartBody("file", "hello world")
+			},
+			headers: map[string]string{
+				handler.FileChecksumHeader:          "2F5D3C8D2E1B4A6C",
+				handler.FileChecksumAlgorithmHeader: "xxhash64",
+			},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecutePut(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, input usecase.FileUploadUseCaseExecutePutInput) (*entity.File, e.CustomError) {
+					content, _ := io.ReadAll(input.Reader)
+					assert.Equal(t, "hello world", string(content))
+					assert.Equal(t, "2f5d3c8d2e1b4a6c", input.Checksum)
+					assert.Equal(t, "xxhash64", input.ChecksumAlgorithm)
+					return mockFile, nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: &handler.PutFileResponse{
+				Name:              testFileName,
+				Size:              11,
+				Checksum:          mockFile.Checksum,
+				ChecksumAlgorithm: "sha256",
+				MerkleRoot:        "merkle-root",
+			},
+		},
+		{
+			name:          "Success - Storage codec header",
+			fileNameParam: testFileName,
+			body: func() (string, *bytes.Buffer) {
+				return "application/octet-stream", bytes.NewBufferString("hello world")
+			},
+			headers: map[string]string{
+				handler.FileStorageCodecHeader: "zstd",
+			},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecutePut(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, input usecase.FileUploadUseCaseExecutePutInput) (*entity.File, e.CustomError) {
+					assert.Equal(t, entity.StorageCodecZstd, input.StorageCodec)
+					return mockFile, nil
+				})
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: &handler.PutFileResponse{
+				Name:              testFileName,
+				Size:              11,
+				Checksum:          mockFile.Checksum,
+				ChecksumAlgorithm: "sha256",
+				MerkleRoot:        "merkle-root",
+			},
+		},
+		{
+			name:          "Error - Multipart form body without file field",
+			fileNameParam: testFileName,
+			body: func() (string, *bytes.Buffer) {
+				return multipartBody("attachment", "hello world")
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "invalid multipart/form-data body",
+		},
+		{
+			name:          "Error - Invalid checksum header",
+			fileNameParam: testFileName,
+			body: func() (string, *bytes.Buffer) {
+				return "application/octet-stream", bytes.NewBufferString("hello world")
+			},
+			headers:          map[string]string{handler.FileChecksumHeader: "not-a-hash"},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "X-File-Checksum needs to be hex encoded",
+		},
+		{
+			name:          "Error - Raw body larger than max chunk size",
+			fileNameParam: testFileName,
+			body: func() (string, *bytes.Buffer) {
+				return "application/octet-stream", bytes.NewBuffer(make([]byte, 4097))
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "invalid content",
+		},
+		{
+			name:          "Error - Reserved file name",
+			fileNameParam: "..",
+			body: func() (string, *bytes.Buffer) {
+				return "application/octet-stream", bytes.NewBufferString("hello world")
+			},
+			expectedStatus:   http.StatusBadRequest,
+			expectedErrorMsg: "invalid file name",
+		},
+		{
+			name:          "Error - Usecase error",
+			fileNameParam: testFileName,
+			body: func() (string, *bytes.Buffer) {
+				return "application/octet-stream", bytes.NewBufferString("hello world")
+			},
+			setupMock: func(mockUseCase *mock.MockFileUploadUseCase) {
+				mockUseCase.EXPECT().ExecutePut(gomock.Any(), gomock.Any()).Return(nil, mockUsecaseError)
+			},
+			expectedStatus:   mockUsecaseError.StatusCode(),
+			expectedErrorMsg: mockUsecaseError.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUseCase := mock.NewMockFileUploadUseCase(ctrl)
+			if tc.setupMock != nil {
+				tc.setupMock(mockUseCase)
+			}
+
+			h, router := setupTestFileUploadHandler(t, mockUseCase)
+			router.PUT("/files/:file_name", h.ExecutePut)
+
+			contentType, body := tc.body()
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodPut, "/files/"+tc.fileNameParam, body)
+			req.Header.Set("Content-Type", contentType)
+			for key, value := range tc.headers {
+				req.Header.Set(key, value)
+			}
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.expectedStatus, w.Code)
+			if tc.expectedBody != nil {
+				var actualBody handler.PutFileResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &actualBody))
+				assert.Equal(t, *tc.expectedBody, actualBody)
+				return
+			}
+			var errorResponse map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+			assert.Contains(t, errorResponse["error"], tc.expectedErrorMsg)
+		})
+	}
+}
@@ -15,6 +15,10 @@ type Router struct {
 	fileUploadHandler *handler.FileUploadHandler
 	fileGetHandler    *handler.FileGetHandler
 	fileDeleteHandler *handler.FileDeleteHandler
+	fileScrubHandler  *handler.FileScrubHandler
+	fileFsckHandler   *handler.FileFsckHandler
+	fileTusHandler    *handler.FileTusHandler
+	fileS3Handler     *handler.FileS3Handler
 }
 
 // NewRouter creates a new Router instance
@@ -22,12 +26,20 @@ func NewRouter(
 	fileUploadHandler *handler.FileUploadHandler,
 	fileGetHandler *handler.FileGetHandler,
 	fileDeleteHandler *handler.FileDeleteHandler,
+	fileScrubHandler *handler.FileScrubHandler,
+	fileFsckHandler *handler.FileFsckHandler,
+	fileTusHandler *handler.FileTusHandler,
+	fileS3Handler *handler.FileS3Handler,
 ) *Router {
 	return &Router{
 		engine:            gin.Default(),
 		fileUploadHandler: fileUploadHandler,
 		fileGetHandler:    fileGetHandler,
 		fileDeleteHandler: fileDeleteHandler,
+		fileScrubHandler:  fileScrubHandler,
+		fileFsckHandler:   fileFsckHandler,
+		fileTusHandler:    fileTusHandler,
+		fileS3Handler:     fileS3Handler,
 	}
 }
 
@@ -43,11 +55,43 @@ func (r *Router) SetupRoutes() {
 	api := r.engine.Group("/api")
 
 	v1 := api.Group("/v1")
+	v1.GET("/files/upload/limits", r.fileUploadHandler.ExecuteGetLimits)
 	v1.POST("/files/upload/init/:file_name", r.fileUploadHandler.ExecuteInit)
-	v1.POST("/files/upload/:file_id/:chunk_number", r.fileUploadHandler.Execute)
+	v1.POST("/files/upload/commit/:session_token", r.fileUploadHandler.ExecuteCommit)
+	v1.POST("/files/upload/:session_token/:chunk_number", r.fileUploadHandler.Execute)
+	v1.DELETE("/files/upload/:session_token", r.fileUploadHandler.ExecuteAbort)
+	v1.PUT("/files/:file_name", r.fileUploadHandler.ExecutePut)
 	v1.DELETE("/files/:file_name", r.fileDeleteHandler.Execute)
 	v1.GET("/files", r.fileGetHandler.Execute)
 	v1.GET("/files/:file_name", r.fileGetHandler.ExecuteGetStats)
+	v1.GET("/files/:file_name/content", r.fileGetHandler.ExecuteGetContent)
+	v1.GET("/files/:file_name/manifest", r.fileGetHandler.ExecuteGetManifest)
+	// the wildcard is named "file_name" to share the prefix with the routes above, but it takes a file ID
+	v1.GET("/files/:file_name/chunks/:chunk_number", r.fileGetHandler.ExecuteGetChunk)
+
+	// tus routes for the clients of the tus resumable upload protocol, which upload into the same storage
+	tus := v1.Group("/tus", r.fileTusHandler.CheckTusResumable)
+	tus.OPTIONS("/files", r.fileTusHandler.ExecuteOptions)
+	tus.POST("/files", r.fileTusHandler.ExecuteCreate)
+	tus.OPTIONS("/files/:session_token", r.fileTusHandler.ExecuteOptions)
+	tus.HEAD("/files/:session_token", r.fileTusHandler.ExecuteHead)
+	tus.PATCH("/files/:session_token", r.fileTusHandler.ExecutePatch)
+	tus.DELETE("/files/:session_token", r.fileTusHandler.ExecuteDelete)
+
+	// S3 routes for the S3 clients, which see the files as the objects of a single bucket (path-style addressing)
+	s3 := r.engine.Group("/s3", r.fileS3Handler.Authenticate)
+	s3.HEAD("/:bucket", r.fileS3Handler.ExecuteHeadBucket)
+	s3.GET("/:bucket", r.fileS3Handler.ExecuteListObjects)
+	s3.HEAD("/:bucket/*key", r.fileS3Handler.ExecuteHead)
+	s3.GET("/:bucket/*key", r.fileS3Handler.ExecuteGet)
+	s3.PUT("/:bucket/*key", r.fileS3Handler.ExecutePut)
+	s3.POST("/:bucket/*key", r.fileS3Handler.ExecutePost)
+	s3.DELETE("/:bucket/*key", r.fileS3Handler.ExecuteDelete)
+
+	// Admin routes for the operators of the server
+	admin := v1.Group("/admin")
+	admin.GET("/scrub", r.fileScrubHandler.ExecuteGetReport)
+	admin.GET("/fsck", r.fileFsckHandler.Execute)
 
 	// Debug routes for profiling
 	debug := r.engine.Group("/debug")
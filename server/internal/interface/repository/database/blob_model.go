@@ -0,0 +1,38 @@
+package database
+
+import (
+	"time"
+
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+)
+
+// BlobModel represents the blobs table in the database
+type BlobModel struct {
+	ID           uint64 `gorm:"primaryKey;autoIncrement"`
+	Checksum     string `gorm:"size:64;not null;uniqueIndex:idx_blobs_checksum_codec"`
+	StorageCodec string `gorm:"size:16;not null;default:'none';uniqueIndex:idx_blobs_checksum_codec"`
+	Size         uint64 `gorm:"not null;default:0"`
+	FilePath     string `gorm:"size:1024;not null"`
+	RefCount     uint64 `gorm:"not null;default:0;index"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// TableName returns the table name for the blob model
+func (BlobModel) TableName() string {
+	return "blobs"
+}
+
+// ToEntity converts a BlobModel to a Blob entity
+func (m *BlobModel) ToEntity() *entity.Blob {
+	return &entity.Blob{
+		ID:           m.ID,
+		Checksum:     m.Checksum,
+		StorageCodec: entity.StorageCodec(m.StorageCodec),
+		Size:         m.Size,
+		FilePath:     m.FilePath,
+		RefCount:     m.RefCount,
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+)
+
+// chunkRefCountQuery counts the chunks referring to the blob of the outer query
+const chunkRefCountQuery = "(SELECT COUNT(*) FROM file_chunks WHERE file_chunks.blob_id = blobs.id)"
+
+// blobRepository implements the repository.BlobRepository interface
+type blobRepository struct {
+	db *gorm.DB
+}
+
+// NewBlobRepository creates a new MySQL blob repository
+func NewBlobRepository(db *gorm.DB) BlobRepository {
+	return &blobRepository{db: db}
+}
+
+// RecountBlobRefs sets the reference count of each blob to the number of the chunks referring to it, and returns the
+// number of the blobs whose count is corrected
+func (r *blobRepository) RecountBlobRefs(ctx context.Context) (int64, e.CustomError) {
+	result := r.db.WithContext(ctx).Model(&BlobModel{}).
+		Where("ref_count <> "+chunkRefCountQuery).
+		Update("ref_count", gorm.Expr(chunkRefCountQuery))
+	if result.Error != nil {
+		return 0, e.NewDatabaseError(result.Error, "RecountBlobRefs: failed to recount blob references")
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteUnreferencedBlobs deletes the blobs which no chunk refers to within a transaction, and returns them so that
+// their files can be deleted
+func (r *blobRepository) DeleteUnreferencedBlobs(ctx context.Context) ([]*entity.Blob, e.CustomError) {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, e.NewDatabaseError(tx.Error, "DeleteUnreferencedBlobs: failed to begin transaction")
+	}
+
+	models, err := deleteUnreferencedBlobs(tx, nil)
+	if err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "DeleteUnreferencedBlobs: failed to delete unreferenced blobs")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "DeleteUnreferencedBlobs: failed to commit transaction")
+	}
+
+	return toBlobEntities(models), nil
+}
+
+// GetBlobPaths lists the file paths of all the blobs
+func (r *blobRepository) GetBlobPaths(ctx context.Context) ([]string, e.CustomError) {
+	var filePaths []string
+	if err := r.db.WithContext(ctx).Model(&BlobModel{}).Pluck("file_path", &filePaths).Error; err != nil {
+		return nil, e.NewDatabaseError(err, "GetBlobPaths: failed to list blob paths")
+	}
+
+	return filePaths, nil
+}
+
+// acquireBlob takes a reference to the blob of the content within the transaction, and returns the blob. The blob is
+// created from the file at the path unless the blob of the same content in the same codec exists, in which case the
+// file at the path is left to the caller
+func acquireBlob(tx *gorm.DB, checksum string, codec entity.StorageCodec, size uint64, filePath string) (*BlobModel, error) {
+	model := BlobModel{
+		Checksum:     checksum,
+		StorageCodec: string(codec),
+		Size:         size,
+		FilePath:     filePath,
+		RefCount:     1,
+	}
+	// the unique key of the content serializes the concurrent acquisitions of the same blob
+	if err := tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{"ref_count": gorm.Expr("ref_count + 1")}),
+	}).Create(&model).Error; err != nil {
+		return nil, err
+	}
+
+	var blobModel BlobModel
+	if err := tx.Where("checksum = ? AND storage_codec = ?", checksum, string(codec)).First(&blobModel).Error; err != nil {
+		return nil, err
+	}
+
+	return &blobModel, nil
+}
+
+// takeBlobs adds a reference to each of the existing blobs within the transaction, where a blob appearing more than
+// once gains as many references, and returns the IDs of the blobs taken. A blob deleted in the meantime isn't taken
+func takeBlobs(tx *gorm.DB, blobIDs []uint64) (map[uint64]struct{}, error) {
+	refs := make(map[uint64]uint64)
+	for _, blobID := range blobIDs {
+		refs[blobID]++
+	}
+	ids := make([]uint64, 0, len(refs))
+	for blobID := range refs {
+		ids = append(ids, blobID)
+	}
+	// the blobs are locked in the order of their IDs to avoid deadlocks with the concurrent releases
+	slices.Sort(ids)
+
+	takenBlobIDs := make(map[uint64]struct{}, len(ids))
+	for _, blobID := range ids {
+		result := tx.Model(&BlobModel{}).Where("id = ?", blobID).
+			Update("ref_count", gorm.Expr("ref_count + ?", refs[blobID]))
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			takenBlobIDs[blobID] = struct{}{}
+		}
+	}
+
+	return takenBlobIDs, nil
+}
+
+// releaseBlobs drops a reference to each of the blobs within the transaction, where a blob appearing more than once
+// loses as many references, and deletes the blobs left unreferenced. The deleted blobs are returned
+func releaseBlobs(tx *gorm.DB, blobIDs []uint64) ([]BlobModel, error) {
+	refs := make(map[uint64]uint64)
+	for _, blobID := range blobIDs {
+		refs[blobID]++
+	}
+	ids := make([]uint64, 0, len(refs))
+	for blobID := range refs {
+		ids = append(ids, blobID)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	// the blobs are locked in the order of their IDs to avoid deadlocks with the concurrent releases
+	slices.Sort(ids)
+
+	for _, blobID := range ids {
+		// a drifted count doesn't go below zero, and is corrected by the garbage collector
+		if err := tx.Model(&BlobModel{}).Where("id = ?", blobID).
+			Update("ref_count", gorm.Expr("ref_count - LEAST(ref_count, ?)", refs[blobID])).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return deleteUnreferencedBlobs(tx, ids)
+}
+
+// deleteUnreferencedBlobs deletes the blobs of the IDs, or all the blobs when no ID is given, whose reference count is
+// zero and which no chunk refers to within the transaction, and returns them
+func deleteUnreferencedBlobs(tx *gorm.DB, ids []uint64) ([]BlobModel, error) {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("ref_count = 0 AND NOT EXISTS (SELECT 1 FROM file_chunks WHERE file_chunks.blob_id = blobs.id)")
+	if ids != nil {
+		query = query.Where("id IN ?", ids)
+	}
+
+	var models []BlobModel
+	if err := query.Order("id ASC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, nil
+	}
+
+	deletedIDs := make([]uint64, len(models))
+	for i, model := range models {
+		deletedIDs[i] = model.ID
+	}
+	if err := tx.Delete(&BlobModel{}, deletedIDs).Error; err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// deleteFile deletes the record of the file within the transaction, which also deletes its chunks by the cascade, and
+// releases the blobs of the chunks. The blobs left unreferenced are deleted and returned
+func deleteFile(tx *gorm.DB, fileID uint64) ([]BlobModel, error) {
+	// the chunks are locked so that the blobs they refer to don't change until they're deleted
+	var blobIDs []uint64
+	if err := tx.Model(&FileChunkModel{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("parent_id = ? AND blob_id IS NOT NULL", fileID).
+		Pluck("blob_id", &blobIDs).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Delete(&FileModel{}, fileID).Error; err != nil {
+		return nil, err
+	}
+
+	return releaseBlobs(tx, blobIDs)
+}
+
+// toBlobEntities converts BlobModels to Blob entities
+func toBlobEntities(models []BlobModel) []*entity.Blob {
+	blobs := make([]*entity.Blob, len(models))
+	for i, model := range models {
+		blobs[i] = model.ToEntity()
+	}
+	return blobs
+}
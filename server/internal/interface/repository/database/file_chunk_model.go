@@ -8,12 +8,15 @@ import (
 
 // FileChunkModel represents the file_chunks table in the database
 type FileChunkModel struct {
-	ID          uint64 `gorm:"primaryKey;autoIncrement"`
-	ParentID    uint64 `gorm:"index;not null"`
-	Status      string `gorm:"type:enum('INITIALIZED','IN_PROGRESS','FAILED','UPLOADED');default:'INITIALIZED';not null"`
-	ChunkNumber uint64 `gorm:"not null;default:0"`
-	Size        uint64 `gorm:"not null;default:0"`
-	FilePath    string `gorm:"size:1024;not null"`
+	ID          uint64  `gorm:"primaryKey;autoIncrement"`
+	ParentID    uint64  `gorm:"index;not null"`
+	Status      string  `gorm:"type:enum('INITIALIZED','IN_PROGRESS','FAILED','UPLOADED','DAMAGED');default:'INITIALIZED';not null"`
+	ChunkNumber uint64  `gorm:"not null;default:0"`
+	Offset      uint64  `gorm:"not null;default:0"` // recorded only when the chunks of the file have variable sizes
+	Size        uint64  `gorm:"not null;default:0"`
+	Checksum    string  `gorm:"size:128;not null;default:''"`
+	FilePath    string  `gorm:"size:1024;not null"`
+	BlobID      *uint64 `gorm:"index"` // NULL until the chunk is uploaded, or when the chunk is stored on its own
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
@@ -25,13 +28,21 @@ func (FileChunkModel) TableName() string {
 
 // ToEntity converts a FileChunkModel to a FileChunk entity
 func (m *FileChunkModel) ToEntity() *entity.FileChunk {
+	var blobID uint64
+	if m.BlobID != nil {
+		blobID = *m.BlobID
+	}
+
 	return &entity.FileChunk{
 		ID:          m.ID,
 		ParentID:    m.ParentID,
 		Status:      entity.FileStatus(m.Status),
 		ChunkNumber: m.ChunkNumber,
+		Offset:      m.Offset,
 		Size:        m.Size,
+		Checksum:    m.Checksum,
 		FilePath:    m.FilePath,
+		BlobID:      blobID,
 		CreatedAt:   m.CreatedAt,
 		UpdatedAt:   m.UpdatedAt,
 	}
@@ -43,8 +54,14 @@ func (m *FileChunkModel) FromEntity(e *entity.FileChunk) {
 	m.ParentID = e.ParentID
 	m.Status = string(e.Status)
 	m.ChunkNumber = e.ChunkNumber
+	m.Offset = e.Offset
 	m.Size = e.Size
+	m.Checksum = e.Checksum
 	m.FilePath = e.FilePath
+	m.BlobID = nil
+	if e.BlobID != 0 {
+		m.BlobID = &e.BlobID
+	}
 	m.CreatedAt = e.CreatedAt
 	m.UpdatedAt = e.UpdatedAt
 }
@@ -8,17 +8,21 @@ import (
 
 // FileModel represents the files table in the database
 type FileModel struct {
-	ID             uint64 `gorm:"primaryKey;autoIncrement"`
-	Name           string `gorm:"uniqueIndex;size:255;not null"`
-	Size           uint64 `gorm:"not null;default:0"`
-	Checksum       string `gorm:"size:512;not null"`
-	ChunkSize      uint64 `gorm:"not null;default:0"`
-	Status         string `gorm:"type:enum('INITIALIZED','IN_PROGRESS','FAILED','UPLOADED');default:'INITIALIZED';index;not null"`
-	TotalChunks    uint   `gorm:"not null;default:0"`
-	UploadedChunks uint   `gorm:"not null;default:0"`
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	FileChunks     []FileChunkModel `gorm:"foreignKey:ParentID"`
+	ID                uint64  `gorm:"primaryKey;autoIncrement"`
+	Name              *string `gorm:"uniqueIndex;size:255"` // NULL until the upload session of the file is committed
+	Size              uint64  `gorm:"not null;default:0"`
+	Checksum          string  `gorm:"size:512;not null"`
+	ChecksumAlgorithm string  `gorm:"size:32;not null;default:'sha256'"`
+	MerkleRoot        string  `gorm:"size:64;not null;default:''"`
+	ChunkSize         uint64  `gorm:"not null;default:0"`
+	VariableChunks    bool    `gorm:"not null;default:false"`
+	StorageCodec      string  `gorm:"size:16;not null;default:'none'"`
+	Status            string  `gorm:"type:enum('INITIALIZED','IN_PROGRESS','FAILED','UPLOADED','VERIFYING','CORRUPT','DAMAGED');default:'INITIALIZED';index;not null"`
+	TotalChunks       uint    `gorm:"not null;default:0"`
+	UploadedChunks    uint    `gorm:"not null;default:0"`
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	FileChunks        []FileChunkModel `gorm:"foreignKey:ParentID"`
 }
 
 // TableName returns the table name for the file model
@@ -33,28 +37,44 @@ func (m *FileModel) ToEntity() *entity.File {
 		fileChunks[i] = *chunk.ToEntity()
 	}
 
+	var name string
+	if m.Name != nil {
+		name = *m.Name
+	}
+
 	return &entity.File{
-		ID:             m.ID,
-		Name:           m.Name,
-		Size:           m.Size,
-		Checksum:       m.Checksum,
-		ChunkSize:      m.ChunkSize,
-		Status:         entity.FileStatus(m.Status),
-		TotalChunks:    m.TotalChunks,
-		UploadedChunks: m.UploadedChunks,
-		CreatedAt:      m.CreatedAt,
-		UpdatedAt:      m.UpdatedAt,
-		FileChunks:     fileChunks,
+		ID:                m.ID,
+		Name:              name,
+		Size:              m.Size,
+		Checksum:          m.Checksum,
+		ChecksumAlgorithm: m.ChecksumAlgorithm,
+		MerkleRoot:        m.MerkleRoot,
+		ChunkSize:         m.ChunkSize,
+		VariableChunks:    m.VariableChunks,
+		StorageCodec:      entity.StorageCodec(m.StorageCodec),
+		Status:            entity.FileStatus(m.Status),
+		TotalChunks:       m.TotalChunks,
+		UploadedChunks:    m.UploadedChunks,
+		CreatedAt:         m.CreatedAt,
+		UpdatedAt:         m.UpdatedAt,
+		FileChunks:        fileChunks,
 	}
 }
 
 // FromEntity converts a File entity to a FileModel
 func (m *FileModel) FromEntity(e *entity.File) {
 	m.ID = e.ID
-	m.Name = e.Name
+	m.Name = nil
+	if e.Name != "" {
+		m.Name = &e.Name
+	}
 	m.Size = e.Size
 	m.Checksum = e.Checksum
+	m.ChecksumAlgorithm = e.ChecksumAlgorithm
+	m.MerkleRoot = e.MerkleRoot
 	m.ChunkSize = e.ChunkSize
+	m.VariableChunks = e.VariableChunks
+	m.StorageCodec = string(e.StorageCodec)
 	m.Status = string(e.Status)
 	m.TotalChunks = e.TotalChunks
 	m.UploadedChunks = e.UploadedChunks
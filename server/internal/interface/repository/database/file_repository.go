@@ -4,9 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"path/filepath"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
@@ -35,12 +35,26 @@ func (r *fileRepository) GetFileByName(ctx context.Context, name string) (*entit
 	return model.ToEntity(), nil
 }
 
-// GetChunksByFileID retrieves all file chunks associated with a given file ID.
+// GetFileByID retrieves a file by its ID
+func (r *fileRepository) GetFileByID(ctx context.Context, id uint64) (*entity.File, e.CustomError) {
+	var model FileModel
+	if err := r.db.WithContext(ctx).First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, e.NewDatabaseError(err, "GetFileByID: failed to get file by ID")
+	}
+
+	return model.ToEntity(), nil
+}
+
+// GetChunksByFileID retrieves all file chunks associated with a given file ID ordered by chunk number.
 func (r *fileRepository) GetChunksByFileID(ctx context.Context, fileID uint64) ([]*entity.FileChunk, e.CustomError) {
 	var chunkModels []FileChunkModel
 
 	err := r.db.WithContext(ctx).
 		Where("parent_id = ?", fileID).
+		Order("chunk_number ASC").
 		Find(&chunkModels).Error
 
 	if err != nil {
@@ -127,6 +141,45 @@ func (r *fileRepository) GetFileAndChunk(ctx context.Context, fileID uint64, chu
 	return fileEntity, chunkEntity, nil
 }
 
+// GetFiles retrieves all files ordered by ID.
+func (r *fileRepository) GetFiles(ctx context.Context) ([]*entity.File, e.CustomError) {
+	var fileModels []FileModel
+
+	err := r.db.WithContext(ctx).
+		Order("id ASC").
+		Find(&fileModels).Error
+	if err != nil {
+		return nil, e.NewDatabaseError(err, "GetFiles: failed to get files")
+	}
+
+	files := make([]*entity.File, len(fileModels))
+	for i, model := range fileModels {
+		files[i] = model.ToEntity()
+	}
+
+	return files, nil
+}
+
+// GetFilesByStatus retrieves all files in the given status ordered by ID.
+func (r *fileRepository) GetFilesByStatus(ctx context.Context, status entity.FileStatus) ([]*entity.File, e.CustomError) {
+	var fileModels []FileModel
+
+	err := r.db.WithContext(ctx).
+		Where("status = ?", string(status)).
+		Order("id ASC").
+		Find(&fileModels).Error
+	if err != nil {
+		return nil, e.NewDatabaseError(err, "GetFilesByStatus: failed to get files by status")
+	}
+
+	files := make([]*entity.File, len(fileModels))
+	for i, model := range fileModels {
+		files[i] = model.ToEntity()
+	}
+
+	return files, nil
+}
+
 // GetFileNames lists all completed files
 func (r *fileRepository) GetFileNames(ctx context.Context) ([]string, e.CustomError) {
 	var fileNames []string
@@ -142,61 +195,127 @@ func (r *fileRepository) GetFileNames(ctx context.Context) ([]string, e.CustomEr
 	return fileNames, nil
 }
 
-// CreateFileWithChunks creates a file and its corresponding chunk records within a transaction.
-func (r *fileRepository) CreateFileWithChunks(ctx context.Context, file *entity.File, baseDir string) (*entity.File, e.CustomError) {
+// ReplaceFile creates a file bound to its name along with its chunks within a transaction. The uploaded chunks take
+// the blobs of their content, which are created from the files at the paths of the chunks unless they exist, and
+// their paths are updated to the ones of the blobs. The record of the file previously bound to the name is deleted in
+// the same transaction, and returned with its chunks along with the blobs left unreferenced, so that their content can
+// be deleted. Nil is returned when no file is bound to the name.
+func (r *fileRepository) ReplaceFile(ctx context.Context, file *entity.File) (*entity.File, []*entity.Blob, e.CustomError) {
 	tx := r.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
-		return nil, e.NewDatabaseError(tx.Error, "CreateFileWithChunks: failed to begin transaction")
+		return nil, nil, e.NewDatabaseError(tx.Error, "ReplaceFile: failed to begin transaction")
+	}
+
+	previousModel, releasedBlobs, err := deleteFileBoundToName(tx, file.Name, 0)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, e.NewDatabaseError(err, "ReplaceFile: failed to delete file bound to name")
 	}
 
-	// Create FileModel
 	var fileModel FileModel
 	fileModel.FromEntity(file)
 	if err := tx.Create(&fileModel).Error; err != nil {
 		tx.Rollback()
-		return nil, e.NewDatabaseError(err, "CreateFileWithChunks: failed to create file record")
+		return nil, nil, e.NewDatabaseError(err, "ReplaceFile: failed to create file record")
 	}
 
-	// Create FileChunkModels
-	fileChunks := make([]FileChunkModel, file.TotalChunks)
-	for i := uint(0); i < file.TotalChunks; i++ {
-		fileChunks[i] = FileChunkModel{
-			ParentID:    fileModel.ID,
-			ChunkNumber: uint64(i),
-			Status:      string(entity.FileStatusInitialized),
-			FilePath:    filepath.Join(baseDir, file.Name, fmt.Sprintf("%d", i)),
+	fileChunks := make([]FileChunkModel, len(file.FileChunks))
+	for i := range file.FileChunks {
+		fileChunks[i].FromEntity(&file.FileChunks[i])
+		fileChunks[i].ParentID = fileModel.ID
+		if fileChunks[i].Status != string(entity.FileStatusUploaded) {
+			continue
+		}
+		blobModel, err := acquireBlob(tx, fileChunks[i].Checksum, file.StorageCodec, fileChunks[i].Size, fileChunks[i].FilePath)
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, e.NewDatabaseError(err, "ReplaceFile: failed to acquire blob of file chunk")
 		}
+		fileChunks[i].BlobID = &blobModel.ID
+		fileChunks[i].FilePath = blobModel.FilePath
 	}
-
-	// Use CreateInBatches to handle potentially large numbers of chunks
-	if err := tx.CreateInBatches(&fileChunks, 1000).Error; err != nil {
-		tx.Rollback()
-		return nil, e.NewDatabaseError(err, "CreateFileWithChunks: failed to create file chunks")
+	if len(fileChunks) > 0 {
+		if err := tx.CreateInBatches(&fileChunks, 1000).Error; err != nil {
+			tx.Rollback()
+			return nil, nil, e.NewDatabaseError(err, "ReplaceFile: failed to create file chunks")
+		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
-		// Rollback might have already happened implicitly, but doesn't hurt.
 		tx.Rollback()
-		return nil, e.NewDatabaseError(err, "CreateFileWithChunks: failed to commit transaction")
+		return nil, nil, e.NewDatabaseError(err, "ReplaceFile: failed to commit transaction")
+	}
+
+	file.ID = fileModel.ID
+	file.CreatedAt = fileModel.CreatedAt
+	file.UpdatedAt = fileModel.UpdatedAt
+	for i := range fileChunks {
+		file.FileChunks[i] = *fileChunks[i].ToEntity()
+	}
+
+	if previousModel == nil {
+		return nil, toBlobEntities(releasedBlobs), nil
 	}
+	return previousModel.ToEntity(), toBlobEntities(releasedBlobs), nil
+}
 
-	createdFile := fileModel.ToEntity()
-	if createdFile == nil {
-		// Handle the case where conversion fails, although it shouldn't if creation succeeded
-		return nil, e.NewDatabaseError(errors.New("failed to convert created file model to entity"), "CreateFileWithChunks: database conversion error")
+// CreateChunkIfNotExists creates the record of a chunk of a file in INITIALIZED status unless the file already has
+// the chunk of the same number. The chunks of an open-ended upload are created as they're uploaded
+func (r *fileRepository) CreateChunkIfNotExists(ctx context.Context, fileID uint64, chunkNumber uint64, filePath string) e.CustomError {
+	var chunkModel FileChunkModel
+	err := r.db.WithContext(ctx).
+		Where("parent_id = ? AND chunk_number = ?", fileID, chunkNumber).
+		Attrs(FileChunkModel{
+			ParentID:    fileID,
+			ChunkNumber: chunkNumber,
+			Status:      string(entity.FileStatusInitialized),
+			FilePath:    filePath,
+		}).
+		FirstOrCreate(&chunkModel).Error
+	if err != nil {
+		return e.NewDatabaseError(err, "CreateChunkIfNotExists: failed to create file chunk")
 	}
 
-	return createdFile, nil
+	return nil
 }
 
-// DeleteFileByID deletes a file by its ID. This also triggers the cascade delete of all its chunks.
-func (r *fileRepository) DeleteFileByID(ctx context.Context, fileID uint64) e.CustomError {
-	if err := r.db.WithContext(ctx).Delete(&FileModel{}, fileID).Error; err != nil {
-		return e.NewDatabaseError(err, "DeleteFileByID: failed to delete file")
+// UpdateFileLayout records the size, the number of chunks and the checksum of a file, which are given once all the
+// content of an open-ended upload is uploaded
+func (r *fileRepository) UpdateFileLayout(ctx context.Context, id uint64, size uint64, totalChunks uint, checksum string) e.CustomError {
+	if err := r.db.WithContext(ctx).Model(&FileModel{}).Where("id = ?", id).Updates(map[string]any{
+		"size":         size,
+		"total_chunks": totalChunks,
+		"checksum":     checksum,
+	}).Error; err != nil {
+		return e.NewDatabaseError(err, "UpdateFileLayout: failed to update file layout")
 	}
+
 	return nil
 }
 
+// DeleteFileByID deletes a file by its ID within a transaction. This also triggers the cascade delete of all its
+// chunks, and releases the blobs they refer to. The blobs left unreferenced are deleted and returned, so that their
+// files can be deleted.
+func (r *fileRepository) DeleteFileByID(ctx context.Context, fileID uint64) ([]*entity.Blob, e.CustomError) {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, e.NewDatabaseError(tx.Error, "DeleteFileByID: failed to begin transaction")
+	}
+
+	releasedBlobs, err := deleteFile(tx, fileID)
+	if err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "DeleteFileByID: failed to delete file")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "DeleteFileByID: failed to commit transaction")
+	}
+
+	return toBlobEntities(releasedBlobs), nil
+}
+
 // UpdateFileStatus updates the status of a file
 func (r *fileRepository) UpdateFileStatus(ctx context.Context, id uint64, status entity.FileStatus) e.CustomError {
 	if err := r.db.WithContext(ctx).Model(&FileModel{}).Where("id = ?", id).Update("status", string(status)).Error; err != nil {
@@ -206,6 +325,25 @@ func (r *fileRepository) UpdateFileStatus(ctx context.Context, id uint64, status
 	return nil
 }
 
+// CompareAndUpdateFileStatus updates the status of a file only when its current status is one of the given statuses,
+// and reports whether the file is updated. The condition is evaluated in the same statement as the update, so only one
+// of the concurrent callers can make the transition.
+func (r *fileRepository) CompareAndUpdateFileStatus(ctx context.Context, id uint64, currentStatuses []entity.FileStatus, status entity.FileStatus) (bool, e.CustomError) {
+	currentStatusStrs := make([]string, len(currentStatuses))
+	for i, currentStatus := range currentStatuses {
+		currentStatusStrs[i] = string(currentStatus)
+	}
+
+	result := r.db.WithContext(ctx).Model(&FileModel{}).
+		Where("id = ? AND status IN ?", id, currentStatusStrs).
+		Update("status", string(status))
+	if result.Error != nil {
+		return false, e.NewDatabaseError(result.Error, "CompareAndUpdateFileStatus: failed to update file status")
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
 // UpdateChunksStatus updates the status of multiple file chunks identified by their IDs within a transaction.
 func (r *fileRepository) UpdateChunksStatus(ctx context.Context, chunkIDs []uint64, status entity.FileStatus) e.CustomError {
 	if len(chunkIDs) == 0 {
@@ -231,6 +369,63 @@ func (r *fileRepository) UpdateChunksStatus(ctx context.Context, chunkIDs []uint
 	return nil
 }
 
+// CompleteChunk marks a chunk as UPLOADED and records the size and the checksum of its content within a transaction.
+// The chunk takes the blob of its content in the codec, which is created from the file at the path of the chunk unless
+// it exists, and the path of the chunk is updated to the one of the blob. The blob the chunk referred to before is
+// released, and returned when it's deleted as no chunk refers to it anymore, so that its file can be deleted.
+func (r *fileRepository) CompleteChunk(ctx context.Context, chunk *entity.FileChunk, codec entity.StorageCodec) ([]*entity.Blob, e.CustomError) {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, e.NewDatabaseError(tx.Error, "CompleteChunk: failed to begin transaction")
+	}
+
+	// the chunk is locked before the blobs, in the same order as the deletion of its file
+	var chunkModel FileChunkModel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&chunkModel, chunk.ID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, e.NewNotFoundError(err, fmt.Sprintf("CompleteChunk: file chunk with ID %d not found", chunk.ID))
+		}
+		return nil, e.NewDatabaseError(err, "CompleteChunk: failed to get file chunk")
+	}
+
+	blobModel, err := acquireBlob(tx, chunk.Checksum, codec, chunk.Size, chunk.FilePath)
+	if err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "CompleteChunk: failed to acquire blob of file chunk")
+	}
+
+	if err := tx.Model(&FileChunkModel{}).Where("id = ?", chunk.ID).Updates(map[string]any{
+		"status":    string(entity.FileStatusUploaded),
+		"size":      chunk.Size,
+		"checksum":  chunk.Checksum,
+		"file_path": blobModel.FilePath,
+		"blob_id":   blobModel.ID,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "CompleteChunk: failed to update file chunk")
+	}
+
+	var releasedBlobs []BlobModel
+	if chunkModel.BlobID != nil {
+		if releasedBlobs, err = releaseBlobs(tx, []uint64{*chunkModel.BlobID}); err != nil {
+			tx.Rollback()
+			return nil, e.NewDatabaseError(err, "CompleteChunk: failed to release previous blob of file chunk")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "CompleteChunk: failed to commit transaction")
+	}
+
+	chunk.Status = entity.FileStatusUploaded
+	chunk.FilePath = blobModel.FilePath
+	chunk.BlobID = blobModel.ID
+
+	return toBlobEntities(releasedBlobs), nil
+}
+
 // UpdateFileAndChunkStatus updates the status of a specific file and a specific chunk within a transaction.
 func (r *fileRepository) UpdateFileAndChunkStatus(ctx context.Context, fileID uint64, chunkIDs []uint64, status entity.FileStatus) e.CustomError {
 	tx := r.db.WithContext(ctx).Begin()
@@ -312,3 +507,30 @@ func (r *fileRepository) CountChunksByStatus(ctx context.Context, fileID uint64,
 
 	return count, int64(fileModel.TotalChunks), nil
 }
+
+// deleteFileBoundToName deletes the record of the file bound to the name within the transaction unless it's the file
+// of the given ID, and returns it with its chunks along with the blobs left unreferenced by the deletion. The row of the
+// name is locked, so that the concurrent bindings of the name are serialized. Nil is returned when no other file is
+// bound to the name.
+func deleteFileBoundToName(tx *gorm.DB, name string, fileID uint64) (*FileModel, []BlobModel, error) {
+	var model FileModel
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("FileChunks").
+		Where("name = ?", name).
+		First(&model).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if model.ID == fileID {
+		return nil, nil, nil
+	}
+	releasedBlobs, err := deleteFile(tx, model.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &model, releasedBlobs, nil
+}
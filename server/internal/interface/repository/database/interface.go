@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
@@ -12,7 +13,10 @@ type FileRepository interface {
 	// GetFileByName retrieves a file by its name
 	GetFileByName(ctx context.Context, name string) (*entity.File, e.CustomError)
 
-	// GetChunksByFileID retrieves all file chunks associated with a given file ID.
+	// GetFileByID retrieves a file by its ID
+	GetFileByID(ctx context.Context, id uint64) (*entity.File, e.CustomError)
+
+	// GetChunksByFileID retrieves all file chunks associated with a given file ID ordered by chunk number.
 	GetChunksByFileID(ctx context.Context, fileID uint64) ([]*entity.FileChunk, e.CustomError)
 
 	// GetChunksByStatus retrieves chunks by their status
@@ -21,21 +25,46 @@ type FileRepository interface {
 	// GetFileAndChunk retrieves a file and a specific chunk by file ID and chunk number.
 	GetFileAndChunk(ctx context.Context, fileID uint64, chunkNumber uint64) (*entity.File, *entity.FileChunk, e.CustomError)
 
+	// GetFiles retrieves all files ordered by ID.
+	GetFiles(ctx context.Context) ([]*entity.File, e.CustomError)
+
+	// GetFilesByStatus retrieves all files in the given status ordered by ID.
+	GetFilesByStatus(ctx context.Context, status entity.FileStatus) ([]*entity.File, e.CustomError)
+
 	// GetFileNames lists all completed files
 	GetFileNames(ctx context.Context) ([]string, e.CustomError)
 
-	// CreateFileWithChunks creates a file and its corresponding chunk records within a transaction.
-	CreateFileWithChunks(ctx context.Context, file *entity.File, baseDir string) (*entity.File, e.CustomError)
+	// ReplaceFile creates a file bound to its name along with its chunks, which take the blobs of their content,
+	// within a transaction, and returns the file previously bound to the name with its chunks, whose record is deleted
+	// in the transaction, along with the blobs left unreferenced by the deletion.
+	ReplaceFile(ctx context.Context, file *entity.File) (*entity.File, []*entity.Blob, e.CustomError)
+
+	// CreateChunkIfNotExists creates the record of a chunk of a file in INITIALIZED status unless it exists.
+	CreateChunkIfNotExists(ctx context.Context, fileID uint64, chunkNumber uint64, filePath string) e.CustomError
+
+	// UpdateFileLayout records the size, the number of chunks and the checksum of a file.
+	UpdateFileLayout(ctx context.Context, id uint64, size uint64, totalChunks uint, checksum string) e.CustomError
 
-	// DeleteFileByID deletes a file by its ID.
-	DeleteFileByID(ctx context.Context, fileID uint64) e.CustomError
+	// DeleteFileByID deletes a file by its ID along with its chunks, and returns the blobs left unreferenced by the
+	// deletion.
+	DeleteFileByID(ctx context.Context, fileID uint64) ([]*entity.Blob, e.CustomError)
 
 	// UpdateFileStatus updates the status of a file
 	UpdateFileStatus(ctx context.Context, id uint64, status entity.FileStatus) e.CustomError
 
+	// CompareAndUpdateFileStatus updates the status of a file only when its current status is one of the given
+	// statuses, and reports whether the file is updated.
+	CompareAndUpdateFileStatus(ctx context.Context, id uint64, currentStatuses []entity.FileStatus, status entity.FileStatus) (bool, e.CustomError)
+
 	// UpdateChunksStatus updates the status of multiple chunks identified by their IDs.
 	UpdateChunksStatus(ctx context.Context, chunkIDs []uint64, status entity.FileStatus) e.CustomError
 
+	// CompleteChunk marks a chunk as UPLOADED and records the size and the checksum of its content, and refers the
+	// chunk to the blob of its content in the codec, which is created from the file at the path of the chunk unless it
+	// exists. The path of the chunk is updated to the one of the blob. The blob the chunk referred to before is
+	// returned when it's left unreferenced.
+	CompleteChunk(ctx context.Context, chunk *entity.FileChunk, codec entity.StorageCodec) ([]*entity.Blob, e.CustomError)
+
 	// UpdateFileAndChunkStatus updates the status of a specific file and a list of chunks within a transaction.
 	UpdateFileAndChunkStatus(ctx context.Context, fileID uint64, chunkIDs []uint64, status entity.FileStatus) e.CustomError
 
@@ -46,3 +75,50 @@ type FileRepository interface {
 	// and returns the total number of chunks for that file.
 	CountChunksByStatus(ctx context.Context, fileID uint64, status entity.FileStatus) (int64, int64, e.CustomError)
 }
+
+// UploadSessionRepository defines the interface for upload session data operations
+type UploadSessionRepository interface {
+	// CreateSession creates an upload session along with its unnamed file and the chunk records of the file, which are
+	// stored under the directory, within a transaction. The chunks of the file referring to blobs take them and are
+	// created as UPLOADED, while the ones whose blobs are gone are reset to be uploaded.
+	CreateSession(ctx context.Context, session *entity.UploadSession, file *entity.File, dirPath string) (*entity.UploadSession, e.CustomError)
+
+	// GetSessionByToken retrieves an upload session and its file by the token
+	GetSessionByToken(ctx context.Context, token string) (*entity.UploadSession, e.CustomError)
+
+	// GetSessionsByTargetName retrieves the upload sessions of the target name and their files ordered by ID.
+	GetSessionsByTargetName(ctx context.Context, targetName string) ([]*entity.UploadSession, e.CustomError)
+
+	// GetExpiredSessions retrieves the upload sessions expired at the time and their files ordered by ID.
+	GetExpiredSessions(ctx context.Context, now time.Time) ([]*entity.UploadSession, e.CustomError)
+
+	// ExtendSession updates the expiry of an upload session
+	ExtendSession(ctx context.Context, id uint64, expiresAt time.Time) e.CustomError
+
+	// DeleteSession deletes an upload session, leaving its file as it is
+	DeleteSession(ctx context.Context, id uint64) e.CustomError
+
+	// SavePart records a part of a multipart upload as an UPLOADED chunk of the file of the session numbered by the
+	// part number within a transaction, replacing the part with the same number, which is returned. The part is
+	// recorded only while the file is INITIALIZED, i.e. the upload isn't being completed or aborted.
+	SavePart(ctx context.Context, session *entity.UploadSession, part *entity.MultipartPart) (*entity.MultipartPart, e.CustomError)
+
+	// CommitSession binds the file of an upload session to the target name as UPLOADED within a transaction, and
+	// returns the file previously bound to the name with its chunks, whose record is deleted in the transaction, along
+	// with the blobs left unreferenced by the deletion.
+	CommitSession(ctx context.Context, session *entity.UploadSession) (*entity.File, []*entity.Blob, e.CustomError)
+}
+
+// BlobRepository defines the interface for the blob data operations of the garbage collector. The reference counts of
+// the blobs are maintained along with the chunks referring to them by the other repositories
+type BlobRepository interface {
+	// RecountBlobRefs corrects the reference count of each blob to the number of the chunks referring to it, and
+	// returns the number of the blobs corrected.
+	RecountBlobRefs(ctx context.Context) (int64, e.CustomError)
+
+	// DeleteUnreferencedBlobs deletes the blobs which no chunk refers to, and returns them.
+	DeleteUnreferencedBlobs(ctx context.Context) ([]*entity.Blob, e.CustomError)
+
+	// GetBlobPaths lists the file paths of all the blobs.
+	GetBlobPaths(ctx context.Context) ([]string, e.CustomError)
+}
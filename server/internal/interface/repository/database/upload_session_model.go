@@ -0,0 +1,66 @@
+package database
+
+import (
+	"time"
+
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+)
+
+// UploadSessionModel represents the upload_sessions table in the database
+type UploadSessionModel struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement"`
+	Token      string    `gorm:"uniqueIndex;size:64;not null"`
+	FileID     uint64    `gorm:"uniqueIndex;not null"`
+	TargetName string    `gorm:"index;size:255;not null"`
+	Owner      string    `gorm:"size:255;not null;default:''"`
+	OpenEnded  bool      `gorm:"not null;default:false"`
+	Multipart  bool      `gorm:"not null;default:false"`
+	ExpiresAt  time.Time `gorm:"index;not null"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	File       *FileModel `gorm:"foreignKey:FileID;constraint:OnDelete:CASCADE"`
+}
+
+// TableName returns the table name for the upload session model
+func (UploadSessionModel) TableName() string {
+	return "upload_sessions"
+}
+
+// ToEntity converts an UploadSessionModel to an UploadSession entity. The file of the session is named after the
+// target name until it's bound to it
+func (m *UploadSessionModel) ToEntity() *entity.UploadSession {
+	session := &entity.UploadSession{
+		ID:         m.ID,
+		Token:      m.Token,
+		FileID:     m.FileID,
+		TargetName: m.TargetName,
+		Owner:      m.Owner,
+		OpenEnded:  m.OpenEnded,
+		Multipart:  m.Multipart,
+		ExpiresAt:  m.ExpiresAt,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+	if m.File != nil {
+		session.File = m.File.ToEntity()
+		if session.File.Name == "" {
+			session.File.Name = m.TargetName
+		}
+	}
+
+	return session
+}
+
+// FromEntity converts an UploadSession entity to an UploadSessionModel
+func (m *UploadSessionModel) FromEntity(e *entity.UploadSession) {
+	m.ID = e.ID
+	m.Token = e.Token
+	m.FileID = e.FileID
+	m.TargetName = e.TargetName
+	m.Owner = e.Owner
+	m.OpenEnded = e.OpenEnded
+	m.Multipart = e.Multipart
+	m.ExpiresAt = e.ExpiresAt
+	m.CreatedAt = e.CreatedAt
+	m.UpdatedAt = e.UpdatedAt
+}
@@ -0,0 +1,285 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+)
+
+// uploadSessionRepository implements the repository.UploadSessionRepository interface
+type uploadSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewUploadSessionRepository creates a new MySQL upload session repository
+func NewUploadSessionRepository(db *gorm.DB) UploadSessionRepository {
+	return &uploadSessionRepository{db: db}
+}
+
+// CreateSession creates an upload session along with its file and the chunk records of the file within a
+// transaction. The file has no name until the session is committed, and its chunks are stored under the directory.
+// The file of an open-ended session has no chunk records, which are created as its chunks are uploaded, and neither
+// does the file of a multipart upload, whose parts are recorded as they're uploaded. The chunks reusing the blobs of
+// another file take the blobs, and the ones whose blobs are gone are reset in the file to be uploaded.
+func (r *uploadSessionRepository) CreateSession(ctx context.Context, session *entity.UploadSession, file *entity.File, dirPath string) (*entity.UploadSession, e.CustomError) {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, e.NewDatabaseError(tx.Error, "CreateSession: failed to begin transaction")
+	}
+
+	var fileModel FileModel
+	fileModel.FromEntity(file)
+	fileModel.Name = nil
+	if err := tx.Create(&fileModel).Error; err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "CreateSession: failed to create file record")
+	}
+
+	// the chunks of variable sizes are created with their offsets and sizes, which the uploaded content is checked by
+	fileChunks := make([]FileChunkModel, file.TotalChunks)
+	for i := uint(0); i < file.TotalChunks; i++ {
+		fileChunks[i] = FileChunkModel{
+			ParentID:    fileModel.ID,
+			ChunkNumber: uint64(i),
+			Status:      string(entity.FileStatusInitialized),
+			FilePath:    filepath.Join(dirPath, fmt.Sprintf("%d", i)),
+		}
+		if file.VariableChunks {
+			fileChunks[i].Offset = file.FileChunks[i].Offset
+			fileChunks[i].Size = file.FileChunks[i].Size
+		}
+	}
+
+	// the chunks reusing the blobs of another file are created as UPLOADED, and take the blobs. A chunk whose blob was
+	// deleted in the meantime is left to be uploaded
+	var reusedBlobIDs []uint64
+	for i := range file.FileChunks {
+		if file.FileChunks[i].BlobID != 0 {
+			reusedBlobIDs = append(reusedBlobIDs, file.FileChunks[i].BlobID)
+		}
+	}
+	if len(reusedBlobIDs) > 0 {
+		takenBlobIDs, err := takeBlobs(tx, reusedBlobIDs)
+		if err != nil {
+			tx.Rollback()
+			return nil, e.NewDatabaseError(err, "CreateSession: failed to take blobs of reused file chunks")
+		}
+		for i := range file.FileChunks {
+			chunk := &file.FileChunks[i]
+			if chunk.BlobID == 0 {
+				continue
+			}
+			if _, taken := takenBlobIDs[chunk.BlobID]; !taken {
+				chunk.Status = entity.FileStatusInitialized
+				chunk.BlobID = 0
+				continue
+			}
+			fileChunks[i].Status = string(entity.FileStatusUploaded)
+			fileChunks[i].Size = chunk.Size
+			fileChunks[i].Checksum = chunk.Checksum
+			fileChunks[i].FilePath = chunk.FilePath
+			fileChunks[i].BlobID = &chunk.BlobID
+		}
+	}
+
+	// Use CreateInBatches to handle potentially large numbers of chunks
+	if len(fileChunks) > 0 {
+		if err := tx.CreateInBatches(&fileChunks, 1000).Error; err != nil {
+			tx.Rollback()
+			return nil, e.NewDatabaseError(err, "CreateSession: failed to create file chunks")
+		}
+	}
+
+	var sessionModel UploadSessionModel
+	sessionModel.FromEntity(session)
+	sessionModel.FileID = fileModel.ID
+	if err := tx.Create(&sessionModel).Error; err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "CreateSession: failed to create upload session")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "CreateSession: failed to commit transaction")
+	}
+
+	sessionModel.File = &fileModel
+	return sessionModel.ToEntity(), nil
+}
+
+// GetSessionByToken retrieves an upload session and its file by the token
+func (r *uploadSessionRepository) GetSessionByToken(ctx context.Context, token string) (*entity.UploadSession, e.CustomError) {
+	var model UploadSessionModel
+	if err := r.db.WithContext(ctx).Preload("File").Where("token = ?", token).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, e.NewDatabaseError(err, "GetSessionByToken: failed to get upload session by token")
+	}
+
+	return model.ToEntity(), nil
+}
+
+// GetSessionsByTargetName retrieves the upload sessions of the target name and their files ordered by ID
+func (r *uploadSessionRepository) GetSessionsByTargetName(ctx context.Context, targetName string) ([]*entity.UploadSession, e.CustomError) {
+	var models []UploadSessionModel
+	err := r.db.WithContext(ctx).
+		Preload("File").
+		Where("target_name = ?", targetName).
+		Order("id ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, e.NewDatabaseError(err, "GetSessionsByTargetName: failed to get upload sessions by target name")
+	}
+
+	return toSessionEntities(models), nil
+}
+
+// GetExpiredSessions retrieves the upload sessions expired at the time and their files ordered by ID
+func (r *uploadSessionRepository) GetExpiredSessions(ctx context.Context, now time.Time) ([]*entity.UploadSession, e.CustomError) {
+	var models []UploadSessionModel
+	err := r.db.WithContext(ctx).
+		Preload("File").
+		Where("expires_at <= ?", now).
+		Order("id ASC").
+		Find(&models).Error
+	if err != nil {
+		return nil, e.NewDatabaseError(err, "GetExpiredSessions: failed to get expired upload sessions")
+	}
+
+	return toSessionEntities(models), nil
+}
+
+// ExtendSession updates the expiry of an upload session
+func (r *uploadSessionRepository) ExtendSession(ctx context.Context, id uint64, expiresAt time.Time) e.CustomError {
+	if err := r.db.WithContext(ctx).Model(&UploadSessionModel{}).Where("id = ?", id).Update("expires_at", expiresAt).Error; err != nil {
+		return e.NewDatabaseError(err, "ExtendSession: failed to update upload session expiry")
+	}
+
+	return nil
+}
+
+// DeleteSession deletes an upload session, leaving its file as it is
+func (r *uploadSessionRepository) DeleteSession(ctx context.Context, id uint64) e.CustomError {
+	if err := r.db.WithContext(ctx).Delete(&UploadSessionModel{}, id).Error; err != nil {
+		return e.NewDatabaseError(err, "DeleteSession: failed to delete upload session")
+	}
+
+	return nil
+}
+
+// SavePart records a part of a multipart upload as an UPLOADED chunk of the file of the session numbered by the part
+// number within a transaction. The chunk of the part with the same number is updated to the new part, and the part it
+// recorded is returned, so that its content can be deleted. The file is locked while the part is recorded, and the part
+// is rejected unless the file is INITIALIZED, so that no part is replaced once the upload is being completed or
+// aborted.
+func (r *uploadSessionRepository) SavePart(ctx context.Context, session *entity.UploadSession, part *entity.MultipartPart) (*entity.MultipartPart, e.CustomError) {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, e.NewDatabaseError(tx.Error, "SavePart: failed to begin transaction")
+	}
+
+	var fileModel FileModel
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&fileModel, session.FileID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, e.NewNotFoundError(err, fmt.Sprintf("SavePart: file with ID %d not found", session.FileID))
+		}
+		return nil, e.NewDatabaseError(err, "SavePart: failed to get file")
+	}
+	if fileModel.Status != string(entity.FileStatusInitialized) {
+		tx.Rollback()
+		return nil, e.NewNotFoundError(
+			fmt.Errorf("file with ID %d is in %s status", session.FileID, fileModel.Status),
+			"SavePart: multipart upload is being completed or aborted",
+		)
+	}
+
+	var chunkModel FileChunkModel
+	err := tx.Where("parent_id = ? AND chunk_number = ?", session.FileID, part.PartNumber).First(&chunkModel).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "SavePart: failed to get file chunk of part")
+	}
+
+	var replacedPart *entity.MultipartPart
+	if err == nil {
+		replacedPart = &entity.MultipartPart{
+			PartNumber: chunkModel.ChunkNumber,
+			Size:       chunkModel.Size,
+			Checksum:   chunkModel.Checksum,
+			FilePath:   chunkModel.FilePath,
+		}
+	}
+	chunkModel.ParentID = session.FileID
+	chunkModel.ChunkNumber = part.PartNumber
+	chunkModel.Status = string(entity.FileStatusUploaded)
+	chunkModel.Size = part.Size
+	chunkModel.Checksum = part.Checksum
+	chunkModel.FilePath = part.FilePath
+	if err := tx.Save(&chunkModel).Error; err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "SavePart: failed to save file chunk of part")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, e.NewDatabaseError(err, "SavePart: failed to commit transaction")
+	}
+
+	return replacedPart, nil
+}
+
+// CommitSession binds the file of an upload session to the target name as UPLOADED with its checksum and Merkle root
+// within a transaction. The record of the file previously bound to the name is deleted in the same transaction, and
+// returned with its chunks along with the blobs left unreferenced, so that their content can be deleted. Nil is
+// returned when no file is bound to the name.
+func (r *uploadSessionRepository) CommitSession(ctx context.Context, session *entity.UploadSession) (*entity.File, []*entity.Blob, e.CustomError) {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, nil, e.NewDatabaseError(tx.Error, "CommitSession: failed to begin transaction")
+	}
+
+	previousModel, releasedBlobs, err := deleteFileBoundToName(tx, session.TargetName, session.FileID)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, e.NewDatabaseError(err, "CommitSession: failed to delete file bound to target name")
+	}
+
+	if err := tx.Model(&FileModel{}).Where("id = ?", session.FileID).Updates(map[string]any{
+		"name":        session.TargetName,
+		"status":      string(entity.FileStatusUploaded),
+		"checksum":    session.File.Checksum,
+		"merkle_root": session.File.MerkleRoot,
+	}).Error; err != nil {
+		tx.Rollback()
+		return nil, nil, e.NewDatabaseError(err, "CommitSession: failed to bind file to target name")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		tx.Rollback()
+		return nil, nil, e.NewDatabaseError(err, "CommitSession: failed to commit transaction")
+	}
+
+	if previousModel == nil {
+		return nil, toBlobEntities(releasedBlobs), nil
+	}
+	return previousModel.ToEntity(), toBlobEntities(releasedBlobs), nil
+}
+
+// toSessionEntities converts UploadSessionModels to UploadSession entities
+func toSessionEntities(models []UploadSessionModel) []*entity.UploadSession {
+	sessions := make([]*entity.UploadSession, len(models))
+	for i, model := range models {
+		sessions[i] = model.ToEntity()
+	}
+	return sessions
+}
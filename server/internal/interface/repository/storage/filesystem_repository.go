@@ -2,24 +2,61 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"golang.org/x/exp/slog"
 
 	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/util/compression"
 )
 
+// TempFileSuffix is a part of the names of the temporary files which chunks are written to before they're complete.
+// The temporary files left by a crash can be found by it.
+const TempFileSuffix = ".tmp-"
+
+// PartialChunkSuffix is appended to the path of a chunk to name the file which keeps the content of the chunk received
+// so far, when the chunk is uploaded in pieces.
+const PartialChunkSuffix = ".partial"
+
+// PartialChunkPath returns the path of the file which keeps the content of the chunk received so far
+func PartialChunkPath(filePath string) string {
+	return filePath + PartialChunkSuffix
+}
+
+// MultipartDirName is the name of the directory under the base storage directory which keeps the parts of the
+// multipart uploads until they're assembled into a file, so no file can have it as its name.
+const MultipartDirName = ".multipart"
+
+// UploadsDirName is the name of the directory under the base storage directory which keeps the chunks of the files
+// uploaded in upload sessions, in a directory per session. The files are named only when their sessions are committed,
+// so the directories are named after the session tokens rather than the file names. The files uploaded by a single
+// request have a directory of a random name there too
+const UploadsDirName = ".uploads"
+
+// ObjectsDirName is the name of the directory under the base storage directory which keeps the blobs, the content of
+// the chunks shared by the chunks of the same content. The blob files are spread over subdirectories named after the
+// first byte of their random names
+const ObjectsDirName = ".objects"
+
 // storageRepository implements the repository.StorageRepository interface
 type storageRepository struct {
 	config *entity.Config
 	logger *slog.Logger
 	// stores the available space in bytes to tell if a new file upload can fit
-	// in the storage (using syscall can be expensive, so in-memory is used)
+	// in the storage (using syscall can be expensive, so in-memory is used). It's
+	// updated by the bytes written to and deleted from the disk, so the chunks
+	// stored compressed consume only their compressed size
 	availableSpace uint64
 	spaceMutex     sync.RWMutex
 }
@@ -59,18 +96,307 @@ func (r *storageRepository) CreateDirectory(ctx context.Context, dirPath string)
 	return nil
 }
 
-// WriteChunk writes the file chunk to the storage
-func (r *storageRepository) WriteChunk(ctx context.Context, reader io.Reader, filePath string) e.CustomError {
+// WriteChunk writes the file chunk to the storage in the codec and returns the number of bytes written and the SHA-256
+// checksum of the written content, both of which are of the content before it's compressed. The chunk is written to a
+// temporary file in the same directory and renamed into place once it's complete, so that the chunk file either fully
+// exists or not at all even if the server crashes or the request is canceled. When the expected checksum is given and
+// it doesn't match, the chunk is not stored.
+func (r *storageRepository) WriteChunk(ctx context.Context, reader io.Reader, filePath string, codec entity.StorageCodec, checksum string) (uint64, string, e.CustomError) {
+	encoding, codecErr := codecEncoding(codec)
+	if codecErr != nil {
+		return 0, "", codecErr
+	}
+
 	// Ensure the directory exists
 	dirPath := filepath.Dir(filePath)
 	if err := os.MkdirAll(dirPath, 0755); err != nil {
-		return e.NewFileStorageError(err, "failed to create directory")
+		return 0, "", e.NewFileStorageError(err, "failed to create directory")
+	}
+
+	// Create the temporary file, which is hidden and never matches the name of any chunk
+	tempFile, err := os.CreateTemp(dirPath, "."+filepath.Base(filePath)+TempFileSuffix+"*")
+	if err != nil {
+		return 0, "", e.NewFileStorageError(err, "failed to create temporary file")
+	}
+	isRenamed := false
+	defer func() {
+		if isRenamed {
+			return
+		}
+		// the temporary file may already be closed, so the error is ignored
+		_ = tempFile.Close()
+		if err := os.Remove(tempFile.Name()); err != nil && !os.IsNotExist(err) {
+			r.logger.Error("Failed to remove temporary file", "path", tempFile.Name(), "error", err)
+		}
+	}()
+
+	encoder, err := compression.NewWriter(encoding, tempFile, compression.DefaultLevel)
+	if err != nil {
+		return 0, "", e.NewFileStorageError(err, "failed to create encoder")
+	}
+	written, writtenChecksum, writeErr := r.copyChunk(ctx, reader, encoder)
+	if writeErr != nil {
+		return 0, "", writeErr
+	}
+	// closing the encoder flushes the compressed content, but leaves the file open
+	if err := encoder.Close(); err != nil {
+		return 0, "", e.NewFileStorageError(err, "failed to compress chunk")
+	}
+	if checksum != "" && !strings.EqualFold(checksum, writtenChecksum) {
+		// the chunk is corrupted in transit, so it's not left in the storage
+		return 0, "", e.NewChecksumMismatchError(
+			fmt.Errorf("expected %s, but got %s", checksum, writtenChecksum),
+			fmt.Sprintf("chunk %s is corrupted", filePath),
+		)
+	}
+
+	if r.config.StorageDurability != entity.DurabilityNone {
+		if err := tempFile.Sync(); err != nil {
+			return 0, "", e.NewFileStorageError(err, "failed to sync file")
+		}
+	}
+	info, err := tempFile.Stat()
+	if err != nil {
+		return 0, "", e.NewFileStorageError(err, "failed to get file info")
+	}
+	if err := tempFile.Close(); err != nil {
+		return 0, "", e.NewFileStorageError(err, "failed to close file")
+	}
+	replacedSize := storedSize(filePath)
+	if err := os.Rename(tempFile.Name(), filePath); err != nil {
+		return 0, "", e.NewFileStorageError(err, "failed to rename file")
+	}
+	isRenamed = true
+	r.UpdateAvailableSpace(replacedSize - info.Size())
+
+	if r.config.StorageDurability == entity.DurabilityFull {
+		if err := syncDirectory(dirPath); err != nil {
+			return 0, "", e.NewFileStorageError(err, "failed to sync directory")
+		}
+	}
+
+	return written, writtenChecksum, nil
+}
+
+// copyChunk reads the chunk and writes it to the file while hashing it, and returns the number of bytes written and
+// the SHA-256 checksum of the written content. On an error, the number of bytes written before it is returned.
+func (r *storageRepository) copyChunk(ctx context.Context, reader io.Reader, file io.Writer) (uint64, string, e.CustomError) {
+	hash := sha256.New()
+	var written uint64
+	buffer := make([]byte, r.config.StreamBufferSize)
+	for done := false; !done; {
+		select {
+		case <-ctx.Done():
+			return written, "", e.NewContextError(ctx.Err(), "context canceled")
+		default:
+			// Read a chunk from the reader
+			n, err := reader.Read(buffer)
+			if n > 0 {
+				// Write the chunk to the file
+				if _, err := file.Write(buffer[:n]); err != nil {
+					return written, "", e.NewFileStorageError(err, "failed to write to file")
+				}
+			}
+			if err != nil && err != io.EOF {
+				// the bytes read along with the error are written above, so that they're not lost
+				return written + uint64(n), "", e.NewFileStorageError(err, "failed to read from reader")
+			}
+			if n == 0 {
+				done = true
+				break
+			}
+			hash.Write(buffer[:n])
+			written += uint64(n)
+		}
 	}
 
-	// Create the file
-	file, err := os.Create(filePath)
+	return written, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// AppendPartialChunk writes the content from the reader to the partial chunk of the chunk at the given offset, and
+// returns the size of the partial chunk after the write. The content after the offset is discarded first, so that an
+// empty reader truncates the partial chunk to the offset. The bytes received before an error are kept, and the
+// returned size includes them.
+func (r *storageRepository) AppendPartialChunk(ctx context.Context, reader io.Reader, filePath string, offset uint64) (uint64, e.CustomError) {
+	// Ensure the directory exists
+	dirPath := filepath.Dir(filePath)
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return 0, e.NewFileStorageError(err, "failed to create directory")
+	}
+
+	partialFile, err := os.OpenFile(PartialChunkPath(filePath), os.O_RDWR|os.O_CREATE, 0644)
 	if err != nil {
-		return e.NewFileStorageError(err, "failed to create file")
+		return 0, e.NewFileStorageError(err, "failed to open partial chunk")
+	}
+	defer func() {
+		// the partial chunk may already be closed, so the error is ignored
+		_ = partialFile.Close()
+	}()
+
+	info, err := partialFile.Stat()
+	if err != nil {
+		return 0, e.NewFileStorageError(err, "failed to get partial chunk info")
+	}
+	if uint64(info.Size()) < offset {
+		return uint64(info.Size()), e.NewFileStorageError(
+			fmt.Errorf("partial chunk has %d bytes, but the write starts at %d", info.Size(), offset),
+			fmt.Sprintf("partial chunk of %s is shorter than expected", filePath),
+		)
+	}
+	if err := partialFile.Truncate(int64(offset)); err != nil {
+		return offset, e.NewFileStorageError(err, "failed to truncate partial chunk")
+	}
+	if _, err := partialFile.Seek(int64(offset), io.SeekStart); err != nil {
+		return offset, e.NewFileStorageError(err, "failed to seek partial chunk")
+	}
+
+	written, _, copyErr := r.copyChunk(ctx, reader, partialFile)
+	r.UpdateAvailableSpace(info.Size() - int64(offset+written))
+	if r.config.StorageDurability != entity.DurabilityNone {
+		if err := partialFile.Sync(); err != nil {
+			return offset, e.NewFileStorageError(err, "failed to sync partial chunk")
+		}
+	}
+	if err := partialFile.Close(); err != nil {
+		return offset, e.NewFileStorageError(err, "failed to close partial chunk")
+	}
+
+	return offset + written, copyErr
+}
+
+// GetPartialChunkSize returns the size of the partial chunk of the chunk, which is 0 when there's none
+func (r *storageRepository) GetPartialChunkSize(ctx context.Context, filePath string) (uint64, e.CustomError) {
+	info, err := os.Stat(PartialChunkPath(filePath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, e.NewFileStorageError(err, "failed to get partial chunk info")
+	}
+
+	return uint64(info.Size()), nil
+}
+
+// CommitPartialChunk renames the partial chunk into the place of the chunk, and returns the size and the SHA-256
+// checksum of its content. The partial chunk keeps the content as it's received, so it's compressed into the place of
+// the chunk instead when the codec compresses it. When the expected checksum is given and it doesn't match, the partial
+// chunk is deleted.
+func (r *storageRepository) CommitPartialChunk(ctx context.Context, filePath string, codec entity.StorageCodec, checksum string) (uint64, string, e.CustomError) {
+	encoding, codecErr := codecEncoding(codec)
+	if codecErr != nil {
+		return 0, "", codecErr
+	}
+	partialPath := PartialChunkPath(filePath)
+	partialFile, err := os.Open(partialPath)
+	if err != nil {
+		return 0, "", e.NewFileStorageError(err, "failed to open partial chunk")
+	}
+	if encoding != compression.Identity {
+		return r.compressPartialChunk(ctx, partialFile, filePath, codec, checksum)
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, partialFile)
+	_ = partialFile.Close()
+	if err != nil {
+		return 0, "", e.NewFileStorageError(err, "failed to read partial chunk")
+	}
+	writtenChecksum := hex.EncodeToString(hash.Sum(nil))
+	if checksum != "" && !strings.EqualFold(checksum, writtenChecksum) {
+		if err := os.Remove(partialPath); err != nil {
+			r.logger.Error("Failed to remove partial chunk", "path", partialPath, "error", err)
+		}
+		return 0, "", e.NewChecksumMismatchError(
+			fmt.Errorf("expected %s, but got %s", checksum, writtenChecksum),
+			fmt.Sprintf("chunk %s is corrupted", filePath),
+		)
+	}
+
+	// the content is already synced on every append, so only the rename needs to be made durable
+	replacedSize := storedSize(filePath)
+	if err := os.Rename(partialPath, filePath); err != nil {
+		return 0, "", e.NewFileStorageError(err, "failed to rename partial chunk")
+	}
+	r.UpdateAvailableSpace(replacedSize)
+	if r.config.StorageDurability == entity.DurabilityFull {
+		if err := syncDirectory(filepath.Dir(filePath)); err != nil {
+			return 0, "", e.NewFileStorageError(err, "failed to sync directory")
+		}
+	}
+
+	return uint64(size), writtenChecksum, nil
+}
+
+// compressPartialChunk writes the content of the partial chunk into the place of the chunk in the codec, and deletes
+// the partial chunk once it's written or found corrupted
+func (r *storageRepository) compressPartialChunk(ctx context.Context, partialFile *os.File, filePath string, codec entity.StorageCodec, checksum string) (uint64, string, e.CustomError) {
+	partialSize := storedSize(partialFile.Name())
+	size, writtenChecksum, err := r.WriteChunk(ctx, partialFile, filePath, codec, checksum)
+	_ = partialFile.Close()
+	if err != nil && err.ErrorCode() != "CHECKSUM_MISMATCH" {
+		// the partial chunk is kept, so that the commit can be tried again
+		return 0, "", err
+	}
+
+	if removeErr := os.Remove(partialFile.Name()); removeErr != nil {
+		r.logger.Error("Failed to remove partial chunk", "path", partialFile.Name(), "error", removeErr)
+	} else {
+		r.UpdateAvailableSpace(partialSize)
+	}
+	if err != nil {
+		return 0, "", err
+	}
+
+	return size, writtenChecksum, nil
+}
+
+// codecEncoding returns the encoding the chunks of the codec are stored in. The chunks of the files recorded without
+// a codec are stored as they are
+func codecEncoding(codec entity.StorageCodec) (compression.Encoding, e.CustomError) {
+	switch codec {
+	case "", entity.StorageCodecNone:
+		return compression.Identity, nil
+	case entity.StorageCodecGzip:
+		return compression.Gzip, nil
+	case entity.StorageCodecZstd:
+		return compression.Zstd, nil
+	}
+	return "", e.NewFileStorageError(fmt.Errorf("unsupported storage codec %q", codec), "")
+}
+
+// storedSize returns the size of the file on the disk, which is 0 when there's none
+func storedSize(filePath string) int64 {
+	info, err := os.Stat(filePath)
+	if err != nil || info.IsDir() {
+		return 0
+	}
+	return info.Size()
+}
+
+// syncDirectory flushes the directory entries, so that the files created or renamed in it are not lost on a crash
+func syncDirectory(dirPath string) error {
+	dir, err := os.Open(dirPath)
+	if err != nil {
+		return err
+	}
+	if err := dir.Sync(); err != nil {
+		_ = dir.Close()
+		return err
+	}
+
+	return dir.Close()
+}
+
+// ReadChunk reads length bytes of the file chunk stored in the codec starting at offset from the storage and writes them
+// to the writer. The offset and the length are of the content before it's compressed
+func (r *storageRepository) ReadChunk(ctx context.Context, writer io.Writer, filePath string, codec entity.StorageCodec, offset uint64, length uint64) e.CustomError {
+	encoding, codecErr := codecEncoding(codec)
+	if codecErr != nil {
+		return codecErr
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return e.NewFileStorageError(err, "failed to open file")
 	}
 	defer func() {
 		if err := file.Close(); err != nil {
@@ -78,39 +404,98 @@ func (r *storageRepository) WriteChunk(ctx context.Context, reader io.Reader, fi
 		}
 	}()
 
-	// Read the chunk and write it to the file
+	// Move to the position where reading starts. The compressed content can't be seeked, so the content before the
+	// position is decompressed and skipped
+	var reader io.Reader = file
+	if encoding != compression.Identity {
+		decoder, err := compression.NewReader(encoding, file)
+		if err != nil {
+			return e.NewFileStorageError(err, "failed to decompress file")
+		}
+		defer func() {
+			_ = decoder.Close()
+		}()
+		reader = decoder
+		if skipped, err := io.CopyN(io.Discard, decoder, int64(offset)); err != nil {
+			if err == io.EOF {
+				return e.NewFileStorageError(io.ErrUnexpectedEOF, fmt.Sprintf("%d bytes missing in %s", offset-uint64(skipped)+length, filePath))
+			}
+			return e.NewFileStorageError(err, "failed to decompress file")
+		}
+	} else if offset > 0 {
+		if _, err := file.Seek(int64(offset), io.SeekStart); err != nil {
+			return e.NewFileStorageError(err, "failed to seek file")
+		}
+	}
+
+	// Read the chunk and write it to the writer
 	buffer := make([]byte, r.config.StreamBufferSize)
-	for {
+	remaining := length
+	for remaining > 0 {
 		select {
 		case <-ctx.Done():
 			return e.NewContextError(ctx.Err(), "context canceled")
 		default:
-			// Read a chunk from the reader
-			n, err := reader.Read(buffer)
+			// Read a piece of the chunk from the file, but not beyond the requested length
+			readSize := min(uint64(len(buffer)), remaining)
+			n, err := reader.Read(buffer[:readSize])
 			if err != nil && err != io.EOF {
-				return e.NewFileStorageError(err, "failed to read from reader")
+				return e.NewFileStorageError(err, "failed to read from file")
 			}
 			if n == 0 {
-				return nil
+				// the chunk is shorter than expected
+				return e.NewFileStorageError(io.ErrUnexpectedEOF, fmt.Sprintf("%d bytes missing in %s", remaining, filePath))
 			}
 
-			// Write the chunk to the file
-			if _, err := file.Write(buffer[:n]); err != nil {
-				return e.NewFileStorageError(err, "failed to write to file")
+			// Write the piece to the writer
+			if _, err := writer.Write(buffer[:n]); err != nil {
+				return e.NewFileStorageError(err, "failed to write to writer")
 			}
+			remaining -= uint64(n)
+		}
+	}
+
+	return nil
+}
+
+// MoveFile moves a file to another path in the storage, replacing the file at the path if any. The modification time
+// of the file is set to now, so that the file just moved into the object area isn't taken for a stale one there
+func (r *storageRepository) MoveFile(ctx context.Context, srcPath string, dstPath string) e.CustomError {
+	dirPath := filepath.Dir(dstPath)
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return e.NewFileStorageError(err, "failed to create directory")
+	}
+	now := time.Now()
+	if err := os.Chtimes(srcPath, now, now); err != nil {
+		return e.NewFileStorageError(err, "failed to touch file")
+	}
+	replacedSize := storedSize(dstPath)
+	if err := os.Rename(srcPath, dstPath); err != nil {
+		return e.NewFileStorageError(err, "failed to move file")
+	}
+	r.UpdateAvailableSpace(replacedSize)
+	if r.config.StorageDurability == entity.DurabilityFull {
+		if err := syncDirectory(dirPath); err != nil {
+			return e.NewFileStorageError(err, "failed to sync directory")
 		}
 	}
+
+	return nil
 }
 
 // DeleteFile deletes a file from the storage
 func (r *storageRepository) DeleteFile(ctx context.Context, filePath string) e.CustomError {
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	info, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
 		return nil
 	}
 
 	if err := os.Remove(filePath); err != nil {
 		return e.NewFileStorageError(err, "failed to delete file")
 	}
+	if info != nil && !info.IsDir() {
+		r.UpdateAvailableSpace(info.Size())
+	}
 
 	return nil
 }
@@ -121,9 +506,18 @@ func (r *storageRepository) DeleteDirectory(ctx context.Context, dirPath string)
 		return nil
 	}
 
+	// the space is freed by the files deleted, so they're summed up before the deletion
+	var size int64
+	_ = filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			size += storedSize(path)
+		}
+		return nil
+	})
 	if err := os.RemoveAll(dirPath); err != nil {
 		return e.NewFileStorageError(err, "failed to delete directory")
 	}
+	r.UpdateAvailableSpace(size)
 
 	return nil
 }
@@ -147,6 +541,59 @@ func (r *storageRepository) DeleteDirectory(ctx context.Context, dirPath string)
 // 	return nil
 // }
 
+// ListFiles returns the paths of all the files under the directory, including the ones in its subdirectories
+func (r *storageRepository) ListFiles(ctx context.Context, dirPath string) ([]string, e.CustomError) {
+	filePaths := []string{}
+	err := filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dirPath && os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !entry.IsDir() {
+			filePaths = append(filePaths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, e.NewContextError(ctx.Err(), "context canceled")
+		}
+		return nil, e.NewFileStorageError(err, "failed to list files")
+	}
+
+	return filePaths, nil
+}
+
+// ListFilesModifiedBefore returns the paths of the files under the directory, including the ones in its
+// subdirectories, which are last modified before the time. It returns nothing when the directory doesn't exist.
+func (r *storageRepository) ListFilesModifiedBefore(ctx context.Context, dirPath string, before time.Time) ([]string, e.CustomError) {
+	filePaths, err := r.ListFiles(ctx, dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	staleFilePaths := []string{}
+	for _, filePath := range filePaths {
+		info, statErr := os.Stat(filePath)
+		if os.IsNotExist(statErr) {
+			continue
+		}
+		if statErr != nil {
+			return nil, e.NewFileStorageError(statErr, "failed to get file info")
+		}
+		if info.ModTime().Before(before) {
+			staleFilePaths = append(staleFilePaths, filePath)
+		}
+	}
+
+	return staleFilePaths, nil
+}
+
 // FileExists checks if a file exists at the given path
 func (r *storageRepository) FileExists(ctx context.Context, filePath string) (bool, e.CustomError) {
 	_, err := os.Stat(filePath)
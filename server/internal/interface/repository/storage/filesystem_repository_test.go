@@ -2,10 +2,16 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"golang.org/x/exp/slog"
 
@@ -19,21 +25,32 @@ func TestStorageRepository_WriteChunk(t *testing.T) {
 	repo := NewStorageRepository(config, slog.Default())
 	tempDir := t.TempDir()
 
+	// SHA-256 of "hello world"
+	helloWorldChecksum := "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
 	tests := []struct {
-		name        string
-		content     string
-		setup       func(t *testing.T) (filePath string, cleanup func())
-		wantErr     bool
-		checkResult func(t *testing.T, filePath string)
+		name         string
+		ctx          context.Context
+		reader       io.Reader // overrides the content when set
+		content      string
+		checksum     string
+		setup        func(t *testing.T) (filePath string, cleanup func())
+		wantErrCode  string
+		wantChecksum string
+		checkResult  func(t *testing.T, filePath string)
 	}{
 		{
-			name:    "Success - Write new chunk",
-			content: "hello world",
+			name:     "Success - Write new chunk",
+			content:  "hello world",
+			checksum: helloWorldChecksum,
 			setup: func(t *testing.T) (string, func()) {
 				filePath := filepath.Join(tempDir, "subdir", "chunk_0")
 				return filePath, func() {}
 			},
-			wantErr: false,
+			wantChecksum: helloWorldChecksum,
 			checkResult: func(t *testing.T, filePath string) {
 				data, err := os.ReadFile(filePath)
 				assert.NoError(t, err)
@@ -53,13 +70,72 @@ func TestStorageRepository_WriteChunk(t *testing.T) {
 				assert.NoError(t, err)
 				return filePath, func() {}
 			},
-			wantErr: false,
+			wantChecksum: "fe32608c9ef5b6cf7e3f946480253ff76f24f4ec0678f3d0f07f9844cbff9601",
 			checkResult: func(t *testing.T, filePath string) {
 				data, err := os.ReadFile(filePath)
 				assert.NoError(t, err)
 				assert.Equal(t, "new content", string(data))
 			},
 		},
+		{
+			name:     "Error - Checksum mismatch",
+			content:  "hello w0rld",
+			checksum: helloWorldChecksum,
+			setup: func(t *testing.T) (string, func()) {
+				filePath := filepath.Join(tempDir, "chunk_corrupted")
+				return filePath, func() {}
+			},
+			wantErrCode: "CHECKSUM_MISMATCH",
+			checkResult: func(t *testing.T, filePath string) {
+				_, err := os.Stat(filePath)
+				assert.True(t, os.IsNotExist(err), "Corrupted chunk should be removed")
+			},
+		},
+		{
+			name:     "Error - Checksum mismatch keeps existing chunk",
+			content:  "hello w0rld",
+			checksum: helloWorldChecksum,
+			setup: func(t *testing.T) (string, func()) {
+				filePath := filepath.Join(tempDir, "chunk_kept")
+				err := os.WriteFile(filePath, []byte("hello world"), 0644)
+				assert.NoError(t, err)
+				return filePath, func() {}
+			},
+			wantErrCode: "CHECKSUM_MISMATCH",
+			checkResult: func(t *testing.T, filePath string) {
+				data, err := os.ReadFile(filePath)
+				assert.NoError(t, err)
+				assert.Equal(t, "hello world", string(data), "Existing chunk should not be touched")
+			},
+		},
+		{
+			name:    "Error - Reader fails midway",
+			reader:  io.MultiReader(strings.NewReader("hello"), iotest.ErrReader(errors.New("connection reset"))),
+			content: "hello",
+			setup: func(t *testing.T) (string, func()) {
+				filePath := filepath.Join(tempDir, "chunk_interrupted")
+				return filePath, func() {}
+			},
+			wantErrCode: "FILE_STORAGE",
+			checkResult: func(t *testing.T, filePath string) {
+				_, err := os.Stat(filePath)
+				assert.True(t, os.IsNotExist(err), "Truncated chunk should not be stored")
+			},
+		},
+		{
+			name:    "Error - Context canceled",
+			ctx:     canceledCtx,
+			content: "hello world",
+			setup: func(t *testing.T) (string, func()) {
+				filePath := filepath.Join(tempDir, "chunk_canceled")
+				return filePath, func() {}
+			},
+			wantErrCode: "CONTEXT",
+			checkResult: func(t *testing.T, filePath string) {
+				_, err := os.Stat(filePath)
+				assert.True(t, os.IsNotExist(err), "Canceled chunk should not be stored")
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -67,18 +143,299 @@ func TestStorageRepository_WriteChunk(t *testing.T) {
 			filePath, cleanup := tt.setup(t)
 			defer cleanup()
 
-			reader := strings.NewReader(tt.content)
-			gotErr := repo.WriteChunk(ctx, reader, filePath)
+			testCtx := ctx
+			if tt.ctx != nil {
+				testCtx = tt.ctx
+			}
+			var reader io.Reader = strings.NewReader(tt.content)
+			if tt.reader != nil {
+				reader = tt.reader
+			}
+			gotSize, gotChecksum, gotErr := repo.WriteChunk(testCtx, reader, filePath, entity.StorageCodecNone, tt.checksum)
 
-			if tt.wantErr {
+			if tt.wantErrCode != "" {
 				assert.Error(t, gotErr)
+				assert.Equal(t, tt.wantErrCode, gotErr.ErrorCode())
 			} else {
 				assert.NoError(t, gotErr)
+				assert.Equal(t, uint64(len(tt.content)), gotSize)
+				assert.Equal(t, tt.wantChecksum, gotChecksum)
 			}
 
 			if tt.checkResult != nil {
 				tt.checkResult(t, filePath)
 			}
+
+			// the temporary file is never left behind
+			tempFiles, err := filepath.Glob(filepath.Join(filepath.Dir(filePath), "*"+TempFileSuffix+"*"))
+			assert.NoError(t, err)
+			assert.Empty(t, tempFiles)
+		})
+	}
+}
+
+func TestStorageRepository_WriteChunk_Durability(t *testing.T) {
+	ctx := context.Background()
+
+	for _, durability := range []entity.Durability{entity.DurabilityNone, entity.DurabilityFile, entity.DurabilityFull} {
+		t.Run(string(durability), func(t *testing.T) {
+			config := entity.NewConfig()
+			config.StorageDurability = durability
+			repo := NewStorageRepository(config, slog.Default())
+			filePath := filepath.Join(t.TempDir(), "chunk_0")
+
+			gotSize, _, gotErr := repo.WriteChunk(ctx, strings.NewReader("hello world"), filePath, entity.StorageCodecNone, "")
+
+			assert.NoError(t, gotErr)
+			assert.Equal(t, uint64(11), gotSize)
+			data, err := os.ReadFile(filePath)
+			assert.NoError(t, err)
+			assert.Equal(t, "hello world", string(data))
+		})
+	}
+}
+
+func TestStorageRepository_StorageCodec(t *testing.T) {
+	ctx := context.Background()
+	config := entity.NewConfig()
+	repo := NewStorageRepository(config, slog.Default())
+	content := strings.Repeat("2024-01-01T00:00:00Z INFO request served\n", 100)
+	contentChecksum := fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+
+	for _, codec := range []entity.StorageCodec{entity.StorageCodecNone, entity.StorageCodecGzip, entity.StorageCodecZstd} {
+		t.Run(string(codec), func(t *testing.T) {
+			tempDir := t.TempDir()
+
+			// the chunk written in the codec is stored compressed, but its size and checksum are of the content
+			writtenPath := filepath.Join(tempDir, "written")
+			gotSize, gotChecksum, gotErr := repo.WriteChunk(ctx, strings.NewReader(content), writtenPath, codec, contentChecksum)
+			assert.NoError(t, gotErr)
+			assert.Equal(t, uint64(len(content)), gotSize)
+			assert.Equal(t, contentChecksum, gotChecksum)
+			info, err := os.Stat(writtenPath)
+			assert.NoError(t, err)
+			if codec == entity.StorageCodecNone {
+				assert.Equal(t, int64(len(content)), info.Size())
+			} else {
+				assert.Less(t, info.Size(), int64(len(content))/10)
+			}
+
+			// the partial chunk keeps the content as it is until it's committed in the codec
+			committedPath := filepath.Join(tempDir, "committed")
+			_, gotErr = repo.AppendPartialChunk(ctx, strings.NewReader(content), committedPath, 0)
+			assert.NoError(t, gotErr)
+			gotSize, gotChecksum, gotErr = repo.CommitPartialChunk(ctx, committedPath, codec, contentChecksum)
+			assert.NoError(t, gotErr)
+			assert.Equal(t, uint64(len(content)), gotSize)
+			assert.Equal(t, contentChecksum, gotChecksum)
+			_, err = os.Stat(PartialChunkPath(committedPath))
+			assert.True(t, os.IsNotExist(err), "Partial chunk should not be left")
+
+			for _, filePath := range []string{writtenPath, committedPath} {
+				var buf strings.Builder
+				assert.NoError(t, repo.ReadChunk(ctx, &buf, filePath, codec, 0, uint64(len(content))))
+				assert.Equal(t, content, buf.String())
+
+				// the offset and the length are of the content, not of the compressed one
+				buf.Reset()
+				assert.NoError(t, repo.ReadChunk(ctx, &buf, filePath, codec, 45, 10))
+				assert.Equal(t, content[45:55], buf.String())
+
+				gotErr = repo.ReadChunk(ctx, io.Discard, filePath, codec, uint64(len(content))-1, 2)
+				assert.Error(t, gotErr)
+				assert.Equal(t, "FILE_STORAGE", gotErr.ErrorCode())
+			}
+
+			// the corrupted partial chunk is deleted without being stored
+			corruptPath := filepath.Join(tempDir, "corrupt")
+			assert.NoError(t, os.WriteFile(PartialChunkPath(corruptPath), []byte("corrupted"), 0644))
+			_, _, gotErr = repo.CommitPartialChunk(ctx, corruptPath, codec, contentChecksum)
+			assert.Error(t, gotErr)
+			assert.Equal(t, "CHECKSUM_MISMATCH", gotErr.ErrorCode())
+			_, err = os.Stat(corruptPath)
+			assert.True(t, os.IsNotExist(err), "Corrupted chunk should not be stored")
+			_, err = os.Stat(PartialChunkPath(corruptPath))
+			assert.True(t, os.IsNotExist(err), "Partial chunk should not be left")
+		})
+	}
+
+	t.Run("Error - Unsupported codec", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "chunk_0")
+		_, _, gotErr := repo.WriteChunk(ctx, strings.NewReader(content), filePath, "brotli", "")
+		assert.Error(t, gotErr)
+		assert.Equal(t, "FILE_STORAGE", gotErr.ErrorCode())
+		_, err := os.Stat(filePath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Error - Chunk stored in another codec", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "chunk_0")
+		_, _, gotErr := repo.WriteChunk(ctx, strings.NewReader(content), filePath, entity.StorageCodecNone, "")
+		assert.NoError(t, gotErr)
+		gotErr = repo.ReadChunk(ctx, io.Discard, filePath, entity.StorageCodecZstd, 0, uint64(len(content)))
+		assert.Error(t, gotErr)
+		assert.Equal(t, "FILE_STORAGE", gotErr.ErrorCode())
+	})
+}
+
+func TestStorageRepository_AppendPartialChunk(t *testing.T) {
+	ctx := context.Background()
+	config := entity.NewConfig()
+	repo := NewStorageRepository(config, slog.Default())
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name        string
+		reader      io.Reader
+		offset      uint64
+		existing    string // content of the partial chunk before the append, which is absent when empty
+		wantSize    uint64
+		wantErrCode string
+		wantContent string
+	}{
+		{
+			name:        "Success - Create partial chunk",
+			reader:      strings.NewReader("hello"),
+			offset:      0,
+			wantSize:    5,
+			wantContent: "hello",
+		},
+		{
+			name:        "Success - Append to partial chunk",
+			reader:      strings.NewReader(" world"),
+			offset:      5,
+			existing:    "hello",
+			wantSize:    11,
+			wantContent: "hello world",
+		},
+		{
+			name:        "Success - Overwrite content after offset",
+			reader:      strings.NewReader(" world"),
+			offset:      5,
+			existing:    "hello there",
+			wantSize:    11,
+			wantContent: "hello world",
+		},
+		{
+			name:        "Success - Truncate with empty reader",
+			reader:      strings.NewReader(""),
+			offset:      2,
+			existing:    "hello",
+			wantSize:    2,
+			wantContent: "he",
+		},
+		{
+			name:        "Error - Reader fails midway keeps received bytes",
+			reader:      io.MultiReader(strings.NewReader(" wor"), iotest.ErrReader(errors.New("connection reset"))),
+			offset:      5,
+			existing:    "hello",
+			wantSize:    9,
+			wantErrCode: "FILE_STORAGE",
+			wantContent: "hello wor",
+		},
+		{
+			name:        "Error - Offset beyond partial chunk",
+			reader:      strings.NewReader("world"),
+			offset:      8,
+			existing:    "hello",
+			wantSize:    5,
+			wantErrCode: "FILE_STORAGE",
+			wantContent: "hello",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tempDir, "subdir", fmt.Sprintf("chunk_%d", i))
+			if tt.existing != "" {
+				assert.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+				assert.NoError(t, os.WriteFile(PartialChunkPath(filePath), []byte(tt.existing), 0644))
+			}
+
+			gotSize, gotErr := repo.AppendPartialChunk(ctx, tt.reader, filePath, tt.offset)
+
+			if tt.wantErrCode != "" {
+				assert.Error(t, gotErr)
+				assert.Equal(t, tt.wantErrCode, gotErr.ErrorCode())
+			} else {
+				assert.NoError(t, gotErr)
+			}
+			assert.Equal(t, tt.wantSize, gotSize)
+			data, err := os.ReadFile(PartialChunkPath(filePath))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantContent, string(data))
+
+			size, sizeErr := repo.GetPartialChunkSize(ctx, filePath)
+			assert.Nil(t, sizeErr)
+			assert.Equal(t, uint64(len(tt.wantContent)), size)
+
+			_, err = os.Stat(filePath)
+			assert.True(t, os.IsNotExist(err), "Chunk should not be stored until it's committed")
+		})
+	}
+}
+
+func TestStorageRepository_CommitPartialChunk(t *testing.T) {
+	ctx := context.Background()
+	config := entity.NewConfig()
+	repo := NewStorageRepository(config, slog.Default())
+	tempDir := t.TempDir()
+
+	// SHA-256 of "hello world"
+	helloWorldChecksum := "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+	tests := []struct {
+		name        string
+		partial     string // content of the partial chunk, which is absent when empty
+		checksum    string
+		wantErrCode string
+		wantChunk   bool
+	}{
+		{
+			name:      "Success - Commit partial chunk",
+			partial:   "hello world",
+			wantChunk: true,
+		},
+		{
+			name:      "Success - Commit partial chunk with checksum",
+			partial:   "hello world",
+			checksum:  helloWorldChecksum,
+			wantChunk: true,
+		},
+		{
+			name:        "Error - Checksum mismatch",
+			partial:     "hello w0rld",
+			checksum:    helloWorldChecksum,
+			wantErrCode: "CHECKSUM_MISMATCH",
+		},
+		{
+			name:        "Error - No partial chunk",
+			wantErrCode: "FILE_STORAGE",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(tempDir, fmt.Sprintf("chunk_%d", i))
+			if tt.partial != "" {
+				assert.NoError(t, os.WriteFile(PartialChunkPath(filePath), []byte(tt.partial), 0644))
+			}
+
+			gotSize, gotChecksum, gotErr := repo.CommitPartialChunk(ctx, filePath, entity.StorageCodecNone, tt.checksum)
+
+			if tt.wantErrCode != "" {
+				assert.Error(t, gotErr)
+				assert.Equal(t, tt.wantErrCode, gotErr.ErrorCode())
+			} else {
+				assert.NoError(t, gotErr)
+				assert.Equal(t, uint64(11), gotSize)
+				assert.Equal(t, helloWorldChecksum, gotChecksum)
+			}
+
+			_, err := os.Stat(filePath)
+			assert.Equal(t, tt.wantChunk, err == nil)
+			_, err = os.Stat(PartialChunkPath(filePath))
+			assert.True(t, os.IsNotExist(err), "Partial chunk should not be left")
 		})
 	}
 }
@@ -460,6 +817,46 @@ func TestStorageRepository_DeleteFile(t *testing.T) {
 	}
 }
 
+func TestStorageRepository_MoveFile(t *testing.T) {
+	ctx := context.Background()
+	config := entity.NewConfig()
+	repo := NewStorageRepository(config, slog.Default())
+	tempDir := t.TempDir()
+
+	// Success - Move to a new directory
+	srcPath := filepath.Join(tempDir, "src", "1")
+	dstPath := filepath.Join(tempDir, "dst", "0")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(srcPath), 0755))
+	assert.NoError(t, os.WriteFile(srcPath, []byte("part 1"), 0644))
+	assert.Nil(t, repo.MoveFile(ctx, srcPath, dstPath))
+	content, err := os.ReadFile(dstPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "part 1", string(content))
+	_, err = os.Stat(srcPath)
+	assert.True(t, os.IsNotExist(err), "Source file should not exist after the move")
+
+	// Success - Replace an existing file
+	assert.NoError(t, os.WriteFile(srcPath, []byte("part 2"), 0644))
+	assert.Nil(t, repo.MoveFile(ctx, srcPath, dstPath))
+	content, err = os.ReadFile(dstPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "part 2", string(content))
+
+	// Success - The moved file is modified at the move, so that it isn't taken for a stale file
+	staleTime := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.WriteFile(srcPath, []byte("part 3"), 0644))
+	assert.NoError(t, os.Chtimes(srcPath, staleTime, staleTime))
+	assert.Nil(t, repo.MoveFile(ctx, srcPath, dstPath))
+	info, err := os.Stat(dstPath)
+	assert.NoError(t, err)
+	assert.True(t, info.ModTime().After(staleTime))
+
+	// Failure - Source file doesn't exist
+	customErr := repo.MoveFile(ctx, srcPath, dstPath)
+	assert.NotNil(t, customErr)
+	assert.Equal(t, "FILE_STORAGE", customErr.ErrorCode())
+}
+
 func TestStorageRepository_GetAvailableSpace(t *testing.T) {
 	ctx := context.Background()
 	config := entity.NewConfig()
@@ -509,6 +906,47 @@ func TestStorageRepository_UpdateAvailableSpace(t *testing.T) {
 	assert.Zero(t, finalSpace, "Space should be zero after large reduction")
 }
 
+func TestStorageRepository_AvailableSpace_StoredSize(t *testing.T) {
+	ctx := context.Background()
+	config := entity.NewConfig()
+	tempDir := t.TempDir()
+	repo := NewStorageRepository(config, slog.Default())
+	content := strings.Repeat("hello world\n", 1000)
+	storedSpace := func(initialSpace uint64) int64 {
+		return int64(initialSpace) - int64(repo.GetAvailableSpace(ctx, tempDir))
+	}
+
+	// the chunk consumes its compressed size, not the size of its content
+	initialSpace := repo.GetAvailableSpace(ctx, tempDir)
+	filePath := filepath.Join(tempDir, "file", "chunk_0")
+	_, _, err := repo.WriteChunk(ctx, strings.NewReader(content), filePath, entity.StorageCodecZstd, "")
+	assert.NoError(t, err)
+	info, statErr := os.Stat(filePath)
+	assert.NoError(t, statErr)
+	assert.Equal(t, info.Size(), storedSpace(initialSpace))
+	assert.Less(t, info.Size(), int64(len(content)))
+
+	// the partial chunk consumes the content received so far, which is replaced by the compressed chunk on the commit
+	partialPath := filepath.Join(tempDir, "file", "chunk_1")
+	_, err = repo.AppendPartialChunk(ctx, strings.NewReader(content), partialPath, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, info.Size()+int64(len(content)), storedSpace(initialSpace))
+	_, err = repo.AppendPartialChunk(ctx, strings.NewReader(""), partialPath, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, info.Size()+6, storedSpace(initialSpace))
+	_, _, err = repo.CommitPartialChunk(ctx, partialPath, entity.StorageCodecGzip, "")
+	assert.NoError(t, err)
+	committedInfo, statErr := os.Stat(partialPath)
+	assert.NoError(t, statErr)
+	assert.Equal(t, info.Size()+committedInfo.Size(), storedSpace(initialSpace))
+
+	// the deleted chunks free the space they consumed
+	assert.NoError(t, repo.DeleteFile(ctx, filePath))
+	assert.Equal(t, committedInfo.Size(), storedSpace(initialSpace))
+	assert.NoError(t, repo.DeleteDirectory(ctx, filepath.Join(tempDir, "file")))
+	assert.Equal(t, int64(0), storedSpace(initialSpace))
+}
+
 func TestStorageRepository_InitWithNonExistentDirectory(t *testing.T) {
 	tempBase := t.TempDir()
 	nonExistentDir := filepath.Join(tempBase, "storage", "uploads")
@@ -537,3 +975,186 @@ func TestStorageRepository_InitWithNonExistentDirectory(t *testing.T) {
 	available := repo.GetAvailableSpace(context.Background(), nonExistentDir)
 	assert.NotZero(t, available, "Available space should not be zero")
 }
+
+func TestStorageRepository_ReadChunk(t *testing.T) {
+	ctx := context.Background()
+	config := entity.NewConfig()
+	config.StreamBufferSize = 4 // forces the chunk to be read in several pieces
+	repo := NewStorageRepository(config, slog.Default())
+	tempDir := t.TempDir()
+
+	chunkPath := filepath.Join(tempDir, "chunk_to_read")
+	err := os.WriteFile(chunkPath, []byte("hello world"), 0644)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		filePath    string
+		offset      uint64
+		length      uint64
+		wantErr     bool
+		wantContent string
+	}{
+		{
+			name:        "Success - Read whole chunk",
+			filePath:    chunkPath,
+			offset:      0,
+			length:      11,
+			wantErr:     false,
+			wantContent: "hello world",
+		},
+		{
+			name:        "Success - Read part of chunk",
+			filePath:    chunkPath,
+			offset:      6,
+			length:      3,
+			wantErr:     false,
+			wantContent: "wor",
+		},
+		{
+			name:        "Success - Read nothing",
+			filePath:    chunkPath,
+			offset:      3,
+			length:      0,
+			wantErr:     false,
+			wantContent: "",
+		},
+		{
+			name:        "Failure - Chunk shorter than requested",
+			filePath:    chunkPath,
+			offset:      6,
+			length:      10,
+			wantErr:     true,
+			wantContent: "world",
+		},
+		{
+			name:        "Failure - Chunk does not exist",
+			filePath:    filepath.Join(tempDir, "non_existent_chunk"),
+			offset:      0,
+			length:      1,
+			wantErr:     true,
+			wantContent: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf strings.Builder
+			gotErr := repo.ReadChunk(ctx, &buf, tt.filePath, entity.StorageCodecNone, tt.offset, tt.length)
+
+			if tt.wantErr {
+				assert.Error(t, gotErr)
+			} else {
+				assert.NoError(t, gotErr)
+			}
+			assert.Equal(t, tt.wantContent, buf.String())
+		})
+	}
+}
+
+func TestStorageRepository_ListFiles(t *testing.T) {
+	ctx := context.Background()
+	config := entity.NewConfig()
+	repo := NewStorageRepository(config, slog.Default())
+
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T) string
+		ctx       func() context.Context
+		wantFiles func(dirPath string) []string
+		wantErr   string
+	}{
+		{
+			name: "Success - Lists files in subdirectories",
+			setup: func(t *testing.T) string {
+				dirPath := t.TempDir()
+				assert.NoError(t, os.MkdirAll(filepath.Join(dirPath, "a.txt"), 0755))
+				assert.NoError(t, os.MkdirAll(filepath.Join(dirPath, "empty.txt"), 0755))
+				assert.NoError(t, os.WriteFile(filepath.Join(dirPath, "a.txt", "0"), []byte("hello"), 0644))
+				assert.NoError(t, os.WriteFile(filepath.Join(dirPath, "a.txt", "1"), []byte("world"), 0644))
+				assert.NoError(t, os.WriteFile(filepath.Join(dirPath, "stray"), []byte("stray"), 0644))
+				return dirPath
+			},
+			wantFiles: func(dirPath string) []string {
+				return []string{
+					filepath.Join(dirPath, "a.txt", "0"),
+					filepath.Join(dirPath, "a.txt", "1"),
+					filepath.Join(dirPath, "stray"),
+				}
+			},
+		},
+		{
+			name: "Success - Directory does not exist",
+			setup: func(t *testing.T) string {
+				return filepath.Join(t.TempDir(), "non_existent_dir")
+			},
+			wantFiles: func(string) []string { return []string{} },
+		},
+		{
+			name: "Error - Context canceled",
+			setup: func(t *testing.T) string {
+				dirPath := t.TempDir()
+				assert.NoError(t, os.WriteFile(filepath.Join(dirPath, "0"), []byte("hello"), 0644))
+				return dirPath
+			},
+			ctx: func() context.Context {
+				canceledCtx, cancel := context.WithCancel(ctx)
+				cancel()
+				return canceledCtx
+			},
+			wantErr: "CONTEXT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dirPath := tt.setup(t)
+			execCtx := ctx
+			if tt.ctx != nil {
+				execCtx = tt.ctx()
+			}
+
+			gotFiles, gotErr := repo.ListFiles(execCtx, dirPath)
+
+			if tt.wantErr != "" {
+				assert.Error(t, gotErr)
+				assert.Equal(t, tt.wantErr, gotErr.ErrorCode())
+				return
+			}
+			assert.NoError(t, gotErr)
+			assert.ElementsMatch(t, tt.wantFiles(dirPath), gotFiles)
+		})
+	}
+}
+
+func TestStorageRepository_ListFilesModifiedBefore(t *testing.T) {
+	ctx := context.Background()
+	config := entity.NewConfig()
+	repo := NewStorageRepository(config, slog.Default())
+
+	// Success - Lists the files modified before the time in subdirectories
+	dirPath := t.TempDir()
+	staleTime := time.Now().Add(-time.Hour)
+	stalePath := filepath.Join(dirPath, "ab", "ab01")
+	freshPath := filepath.Join(dirPath, "cd", "cd01")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(stalePath), 0755))
+	assert.NoError(t, os.MkdirAll(filepath.Dir(freshPath), 0755))
+	assert.NoError(t, os.WriteFile(stalePath, []byte("stale"), 0644))
+	assert.NoError(t, os.WriteFile(freshPath, []byte("fresh"), 0644))
+	assert.NoError(t, os.Chtimes(stalePath, staleTime, staleTime))
+	filePaths, err := repo.ListFilesModifiedBefore(ctx, dirPath, time.Now().Add(-time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{stalePath}, filePaths)
+
+	// Success - Directory does not exist
+	filePaths, err = repo.ListFilesModifiedBefore(ctx, filepath.Join(dirPath, "non_existent_dir"), time.Now())
+	assert.Nil(t, err)
+	assert.Empty(t, filePaths)
+
+	// Error - Context canceled
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = repo.ListFilesModifiedBefore(canceledCtx, dirPath, time.Now())
+	assert.NotNil(t, err)
+	assert.Equal(t, "CONTEXT", err.ErrorCode())
+}
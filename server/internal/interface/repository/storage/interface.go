@@ -3,7 +3,9 @@ package storage
 import (
 	"context"
 	"io"
+	"time"
 
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
 )
 
@@ -12,8 +14,32 @@ type FileStorageRepository interface {
 	// CreateDirectory creates a directory at the given path
 	CreateDirectory(ctx context.Context, dirPath string) e.CustomError
 
-	// WriteChunk writes the file chunk to the storage
-	WriteChunk(ctx context.Context, reader io.Reader, filePath string) e.CustomError
+	// WriteChunk writes the file chunk to the storage in the codec and returns the number of bytes written and the
+	// SHA-256 checksum of the written content, both of which are of the content before it's compressed. The chunk file
+	// either fully exists or not at all, even if the write is interrupted. When the expected checksum is given and it
+	// doesn't match, the chunk is not stored.
+	WriteChunk(ctx context.Context, reader io.Reader, filePath string, codec entity.StorageCodec, checksum string) (uint64, string, e.CustomError)
+
+	// AppendPartialChunk writes the content to the partial chunk of the chunk at the given offset, and returns the size
+	// of the partial chunk after the write. The content after the offset is discarded first. The bytes received before
+	// an error are kept, and the returned size includes them.
+	AppendPartialChunk(ctx context.Context, reader io.Reader, filePath string, offset uint64) (uint64, e.CustomError)
+
+	// GetPartialChunkSize returns the size of the partial chunk of the chunk, which is 0 when there's none
+	GetPartialChunkSize(ctx context.Context, filePath string) (uint64, e.CustomError)
+
+	// CommitPartialChunk moves the partial chunk into the place of the chunk in the codec, and returns the size and the
+	// SHA-256 checksum of its content. When the expected checksum is given and it doesn't match, the partial chunk is
+	// deleted.
+	CommitPartialChunk(ctx context.Context, filePath string, codec entity.StorageCodec, checksum string) (uint64, string, e.CustomError)
+
+	// ReadChunk reads length bytes of the file chunk stored in the codec starting at offset from the storage and writes
+	// them to the writer. The offset and the length are of the content before it's compressed
+	ReadChunk(ctx context.Context, writer io.Writer, filePath string, codec entity.StorageCodec, offset uint64, length uint64) e.CustomError
+
+	// MoveFile moves a file to another path in the storage, replacing the file at the path if any. The moved file is
+	// stamped as modified now.
+	MoveFile(ctx context.Context, srcPath string, dstPath string) e.CustomError
 
 	// DeleteFile deletes a file chunk from the storage
 	DeleteFile(ctx context.Context, filePath string) e.CustomError
@@ -21,10 +47,19 @@ type FileStorageRepository interface {
 	// DeleteDirectory deletes a directory at the given path
 	DeleteDirectory(ctx context.Context, dirPath string) e.CustomError
 
+	// ListFiles returns the paths of all the files under the directory, including the ones in its subdirectories. It
+	// returns nothing when the directory doesn't exist.
+	ListFiles(ctx context.Context, dirPath string) ([]string, e.CustomError)
+
+	// ListFilesModifiedBefore returns the paths of the files under the directory, including the ones in its
+	// subdirectories, which are last modified before the time. It returns nothing when the directory doesn't exist.
+	ListFilesModifiedBefore(ctx context.Context, dirPath string, before time.Time) ([]string, e.CustomError)
+
 	// FileExists checks if a file exists at the given path
 	FileExists(ctx context.Context, filePath string) (bool, e.CustomError)
 
-	// GetAvailableSpace returns the available space in bytes at the given path
+	// GetAvailableSpace returns the available space in bytes at the given path. The space consumed by the chunks is
+	// their size on the disk, which is their compressed size when they're stored compressed
 	GetAvailableSpace(ctx context.Context, dirPath string) uint64
 
 	// UpdateAvailableSpace updates the available space amount
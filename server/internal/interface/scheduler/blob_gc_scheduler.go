@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"golang.org/x/exp/slog"
+)
+
+// BlobGCScheduler runs the garbage collection of the blobs periodically in the background
+type BlobGCScheduler struct {
+	fileGCUseCase usecase.FileGCUseCase
+	interval      time.Duration
+	logger        *slog.Logger
+}
+
+func NewBlobGCScheduler(fileGCUseCase usecase.FileGCUseCase, config *entity.Config, logger *slog.Logger) *BlobGCScheduler {
+	return &BlobGCScheduler{
+		fileGCUseCase: fileGCUseCase,
+		interval:      config.BlobGCInterval,
+		logger:        logger,
+	}
+}
+
+// Run runs the garbage collection every interval until the context is canceled. It returns immediately when the
+// interval is zero.
+func (s *BlobGCScheduler) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		s.logger.Info("blob garbage collector is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			run, err := s.fileGCUseCase.Execute(ctx)
+			if err != nil {
+				s.logger.Error("blob garbage collection failed", "error", err.Error(), "code", err.ErrorCode())
+				continue
+			}
+			s.logger.Info("blob garbage collection finished",
+				"recounted_blobs", run.RecountedBlobs,
+				"deleted_blobs", run.DeletedBlobs,
+				"deleted_strays", run.DeletedStrays,
+				"duration", run.FinishedAt.Sub(run.StartedAt).String(),
+			)
+			if run.RecountedBlobs > 0 {
+				s.logger.Warn("blob reference counts drifted", "recounted_blobs", run.RecountedBlobs)
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"golang.org/x/exp/slog"
+)
+
+// ScrubScheduler runs the scrub periodically in the background
+type ScrubScheduler struct {
+	fileScrubUseCase usecase.FileScrubUseCase
+	interval         time.Duration
+	logger           *slog.Logger
+}
+
+func NewScrubScheduler(fileScrubUseCase usecase.FileScrubUseCase, config *entity.Config, logger *slog.Logger) *ScrubScheduler {
+	return &ScrubScheduler{
+		fileScrubUseCase: fileScrubUseCase,
+		interval:         config.ScrubInterval,
+		logger:           logger,
+	}
+}
+
+// Run runs the scrub every interval until the context is canceled. It returns immediately when the interval is zero.
+func (s *ScrubScheduler) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		s.logger.Info("scrubber is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.logger.Info("starting scrub")
+			run, err := s.fileScrubUseCase.Execute(ctx)
+			if err != nil {
+				s.logger.Error("scrub failed", "error", err.Error(), "code", err.ErrorCode())
+				continue
+			}
+			s.logger.Info("scrub finished",
+				"scanned_files", run.ScannedFiles,
+				"scanned_chunks", run.ScannedChunks,
+				"damaged_files", run.DamagedFiles,
+				"duration", run.FinishedAt.Sub(run.StartedAt).String(),
+			)
+			if run.DamagedFiles > 0 {
+				s.logger.Warn("damaged files found by scrub", "damaged_files", run.DamagedFiles)
+			}
+		}
+	}
+}
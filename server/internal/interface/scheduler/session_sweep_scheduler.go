@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"golang.org/x/exp/slog"
+)
+
+// SessionSweepScheduler deletes the expired upload sessions periodically in the background, so that the files of the
+// abandoned uploads don't stay in the storage
+type SessionSweepScheduler struct {
+	fileUploadUseCase usecase.FileUploadUseCase
+	interval          time.Duration
+	logger            *slog.Logger
+}
+
+func NewSessionSweepScheduler(fileUploadUseCase usecase.FileUploadUseCase, config *entity.Config, logger *slog.Logger) *SessionSweepScheduler {
+	return &SessionSweepScheduler{
+		fileUploadUseCase: fileUploadUseCase,
+		interval:          config.SessionSweepInterval,
+		logger:            logger,
+	}
+}
+
+// Run sweeps the expired sessions every interval until the context is canceled. It returns immediately when the
+// interval is zero.
+func (s *SessionSweepScheduler) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		s.logger.Info("session sweeper is disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			discarded, err := s.fileUploadUseCase.ExecuteSweepSessions(ctx)
+			if err != nil {
+				s.logger.Error("session sweep failed", "error", err.Error(), "code", err.ErrorCode())
+				continue
+			}
+			if discarded > 0 {
+				s.logger.Info("expired upload sessions swept", "discarded_uploads", discarded)
+			}
+		}
+	}
+}
@@ -12,6 +12,7 @@ package mock
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	entity "github.com/tomoya.tokunaga/server/internal/domain/entity"
 	error "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
@@ -42,6 +43,36 @@ func (m *MockFileRepository) EXPECT() *MockFileRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CompareAndUpdateFileStatus mocks base method.
+func (m *MockFileRepository) CompareAndUpdateFileStatus(ctx context.Context, id uint64, currentStatuses []entity.FileStatus, status entity.FileStatus) (bool, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareAndUpdateFileStatus", ctx, id, currentStatuses, status)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// CompareAndUpdateFileStatus indicates an expected call of CompareAndUpdateFileStatus.
+func (mr *MockFileRepositoryMockRecorder) CompareAndUpdateFileStatus(ctx, id, currentStatuses, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareAndUpdateFileStatus", reflect.TypeOf((*MockFileRepository)(nil).CompareAndUpdateFileStatus), ctx, id, currentStatuses, status)
+}
+
+// CompleteChunk mocks base method.
+func (m *MockFileRepository) CompleteChunk(ctx context.Context, chunk *entity.FileChunk, codec entity.StorageCodec) ([]*entity.Blob, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteChunk", ctx, chunk, codec)
+	ret0, _ := ret[0].([]*entity.Blob)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// CompleteChunk indicates an expected call of CompleteChunk.
+func (mr *MockFileRepositoryMockRecorder) CompleteChunk(ctx, chunk, codec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteChunk", reflect.TypeOf((*MockFileRepository)(nil).CompleteChunk), ctx, chunk, codec)
+}
+
 // CountChunksByStatus mocks base method.
 func (m *MockFileRepository) CountChunksByStatus(ctx context.Context, fileID uint64, status entity.FileStatus) (int64, int64, error.CustomError) {
 	m.ctrl.T.Helper()
@@ -58,27 +89,27 @@ func (mr *MockFileRepositoryMockRecorder) CountChunksByStatus(ctx, fileID, statu
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountChunksByStatus", reflect.TypeOf((*MockFileRepository)(nil).CountChunksByStatus), ctx, fileID, status)
 }
 
-// CreateFileWithChunks mocks base method.
-func (m *MockFileRepository) CreateFileWithChunks(ctx context.Context, file *entity.File, baseDir string) (*entity.File, error.CustomError) {
+// CreateChunkIfNotExists mocks base method.
+func (m *MockFileRepository) CreateChunkIfNotExists(ctx context.Context, fileID, chunkNumber uint64, filePath string) error.CustomError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateFileWithChunks", ctx, file, baseDir)
-	ret0, _ := ret[0].(*entity.File)
-	ret1, _ := ret[1].(error.CustomError)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "CreateChunkIfNotExists", ctx, fileID, chunkNumber, filePath)
+	ret0, _ := ret[0].(error.CustomError)
+	return ret0
 }
 
-// CreateFileWithChunks indicates an expected call of CreateFileWithChunks.
-func (mr *MockFileRepositoryMockRecorder) CreateFileWithChunks(ctx, file, baseDir any) *gomock.Call {
+// CreateChunkIfNotExists indicates an expected call of CreateChunkIfNotExists.
+func (mr *MockFileRepositoryMockRecorder) CreateChunkIfNotExists(ctx, fileID, chunkNumber, filePath any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFileWithChunks", reflect.TypeOf((*MockFileRepository)(nil).CreateFileWithChunks), ctx, file, baseDir)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChunkIfNotExists", reflect.TypeOf((*MockFileRepository)(nil).CreateChunkIfNotExists), ctx, fileID, chunkNumber, filePath)
 }
 
 // DeleteFileByID mocks base method.
-func (m *MockFileRepository) DeleteFileByID(ctx context.Context, fileID uint64) error.CustomError {
+func (m *MockFileRepository) DeleteFileByID(ctx context.Context, fileID uint64) ([]*entity.Blob, error.CustomError) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "DeleteFileByID", ctx, fileID)
-	ret0, _ := ret[0].(error.CustomError)
-	return ret0
+	ret0, _ := ret[0].([]*entity.Blob)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
 }
 
 // DeleteFileByID indicates an expected call of DeleteFileByID.
@@ -133,6 +164,21 @@ func (mr *MockFileRepositoryMockRecorder) GetFileAndChunk(ctx, fileID, chunkNumb
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileAndChunk", reflect.TypeOf((*MockFileRepository)(nil).GetFileAndChunk), ctx, fileID, chunkNumber)
 }
 
+// GetFileByID mocks base method.
+func (m *MockFileRepository) GetFileByID(ctx context.Context, id uint64) (*entity.File, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileByID", ctx, id)
+	ret0, _ := ret[0].(*entity.File)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// GetFileByID indicates an expected call of GetFileByID.
+func (mr *MockFileRepositoryMockRecorder) GetFileByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileByID", reflect.TypeOf((*MockFileRepository)(nil).GetFileByID), ctx, id)
+}
+
 // GetFileByName mocks base method.
 func (m *MockFileRepository) GetFileByName(ctx context.Context, name string) (*entity.File, error.CustomError) {
 	m.ctrl.T.Helper()
@@ -163,6 +209,36 @@ func (mr *MockFileRepositoryMockRecorder) GetFileNames(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileNames", reflect.TypeOf((*MockFileRepository)(nil).GetFileNames), ctx)
 }
 
+// GetFiles mocks base method.
+func (m *MockFileRepository) GetFiles(ctx context.Context) ([]*entity.File, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFiles", ctx)
+	ret0, _ := ret[0].([]*entity.File)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// GetFiles indicates an expected call of GetFiles.
+func (mr *MockFileRepositoryMockRecorder) GetFiles(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFiles", reflect.TypeOf((*MockFileRepository)(nil).GetFiles), ctx)
+}
+
+// GetFilesByStatus mocks base method.
+func (m *MockFileRepository) GetFilesByStatus(ctx context.Context, status entity.FileStatus) ([]*entity.File, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilesByStatus", ctx, status)
+	ret0, _ := ret[0].([]*entity.File)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// GetFilesByStatus indicates an expected call of GetFilesByStatus.
+func (mr *MockFileRepositoryMockRecorder) GetFilesByStatus(ctx, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilesByStatus", reflect.TypeOf((*MockFileRepository)(nil).GetFilesByStatus), ctx, status)
+}
+
 // IncrementUploadedChunks mocks base method.
 func (m *MockFileRepository) IncrementUploadedChunks(ctx context.Context, id uint64) (uint, uint, error.CustomError) {
 	m.ctrl.T.Helper()
@@ -179,6 +255,22 @@ func (mr *MockFileRepositoryMockRecorder) IncrementUploadedChunks(ctx, id any) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementUploadedChunks", reflect.TypeOf((*MockFileRepository)(nil).IncrementUploadedChunks), ctx, id)
 }
 
+// ReplaceFile mocks base method.
+func (m *MockFileRepository) ReplaceFile(ctx context.Context, file *entity.File) (*entity.File, []*entity.Blob, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceFile", ctx, file)
+	ret0, _ := ret[0].(*entity.File)
+	ret1, _ := ret[1].([]*entity.Blob)
+	ret2, _ := ret[2].(error.CustomError)
+	return ret0, ret1, ret2
+}
+
+// ReplaceFile indicates an expected call of ReplaceFile.
+func (mr *MockFileRepositoryMockRecorder) ReplaceFile(ctx, file any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceFile", reflect.TypeOf((*MockFileRepository)(nil).ReplaceFile), ctx, file)
+}
+
 // UpdateChunksStatus mocks base method.
 func (m *MockFileRepository) UpdateChunksStatus(ctx context.Context, chunkIDs []uint64, status entity.FileStatus) error.CustomError {
 	m.ctrl.T.Helper()
@@ -207,6 +299,20 @@ func (mr *MockFileRepositoryMockRecorder) UpdateFileAndChunkStatus(ctx, fileID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFileAndChunkStatus", reflect.TypeOf((*MockFileRepository)(nil).UpdateFileAndChunkStatus), ctx, fileID, chunkIDs, status)
 }
 
+// UpdateFileLayout mocks base method.
+func (m *MockFileRepository) UpdateFileLayout(ctx context.Context, id, size uint64, totalChunks uint, checksum string) error.CustomError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFileLayout", ctx, id, size, totalChunks, checksum)
+	ret0, _ := ret[0].(error.CustomError)
+	return ret0
+}
+
+// UpdateFileLayout indicates an expected call of UpdateFileLayout.
+func (mr *MockFileRepositoryMockRecorder) UpdateFileLayout(ctx, id, size, totalChunks, checksum any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFileLayout", reflect.TypeOf((*MockFileRepository)(nil).UpdateFileLayout), ctx, id, size, totalChunks, checksum)
+}
+
 // UpdateFileStatus mocks base method.
 func (m *MockFileRepository) UpdateFileStatus(ctx context.Context, id uint64, status entity.FileStatus) error.CustomError {
 	m.ctrl.T.Helper()
@@ -220,3 +326,215 @@ func (mr *MockFileRepositoryMockRecorder) UpdateFileStatus(ctx, id, status any)
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFileStatus", reflect.TypeOf((*MockFileRepository)(nil).UpdateFileStatus), ctx, id, status)
 }
+
+// MockUploadSessionRepository is a mock of UploadSessionRepository interface.
+type MockUploadSessionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUploadSessionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockUploadSessionRepositoryMockRecorder is the mock recorder for MockUploadSessionRepository.
+type MockUploadSessionRepositoryMockRecorder struct {
+	mock *MockUploadSessionRepository
+}
+
+// NewMockUploadSessionRepository creates a new mock instance.
+func NewMockUploadSessionRepository(ctrl *gomock.Controller) *MockUploadSessionRepository {
+	mock := &MockUploadSessionRepository{ctrl: ctrl}
+	mock.recorder = &MockUploadSessionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUploadSessionRepository) EXPECT() *MockUploadSessionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CommitSession mocks base method.
+func (m *MockUploadSessionRepository) CommitSession(ctx context.Context, session *entity.UploadSession) (*entity.File, []*entity.Blob, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitSession", ctx, session)
+	ret0, _ := ret[0].(*entity.File)
+	ret1, _ := ret[1].([]*entity.Blob)
+	ret2, _ := ret[2].(error.CustomError)
+	return ret0, ret1, ret2
+}
+
+// CommitSession indicates an expected call of CommitSession.
+func (mr *MockUploadSessionRepositoryMockRecorder) CommitSession(ctx, session any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitSession", reflect.TypeOf((*MockUploadSessionRepository)(nil).CommitSession), ctx, session)
+}
+
+// CreateSession mocks base method.
+func (m *MockUploadSessionRepository) CreateSession(ctx context.Context, session *entity.UploadSession, file *entity.File, dirPath string) (*entity.UploadSession, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", ctx, session, file, dirPath)
+	ret0, _ := ret[0].(*entity.UploadSession)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockUploadSessionRepositoryMockRecorder) CreateSession(ctx, session, file, dirPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockUploadSessionRepository)(nil).CreateSession), ctx, session, file, dirPath)
+}
+
+// DeleteSession mocks base method.
+func (m *MockUploadSessionRepository) DeleteSession(ctx context.Context, id uint64) error.CustomError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSession", ctx, id)
+	ret0, _ := ret[0].(error.CustomError)
+	return ret0
+}
+
+// DeleteSession indicates an expected call of DeleteSession.
+func (mr *MockUploadSessionRepositoryMockRecorder) DeleteSession(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockUploadSessionRepository)(nil).DeleteSession), ctx, id)
+}
+
+// ExtendSession mocks base method.
+func (m *MockUploadSessionRepository) ExtendSession(ctx context.Context, id uint64, expiresAt time.Time) error.CustomError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendSession", ctx, id, expiresAt)
+	ret0, _ := ret[0].(error.CustomError)
+	return ret0
+}
+
+// ExtendSession indicates an expected call of ExtendSession.
+func (mr *MockUploadSessionRepositoryMockRecorder) ExtendSession(ctx, id, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendSession", reflect.TypeOf((*MockUploadSessionRepository)(nil).ExtendSession), ctx, id, expiresAt)
+}
+
+// GetExpiredSessions mocks base method.
+func (m *MockUploadSessionRepository) GetExpiredSessions(ctx context.Context, now time.Time) ([]*entity.UploadSession, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredSessions", ctx, now)
+	ret0, _ := ret[0].([]*entity.UploadSession)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// GetExpiredSessions indicates an expected call of GetExpiredSessions.
+func (mr *MockUploadSessionRepositoryMockRecorder) GetExpiredSessions(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredSessions", reflect.TypeOf((*MockUploadSessionRepository)(nil).GetExpiredSessions), ctx, now)
+}
+
+// GetSessionByToken mocks base method.
+func (m *MockUploadSessionRepository) GetSessionByToken(ctx context.Context, token string) (*entity.UploadSession, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionByToken", ctx, token)
+	ret0, _ := ret[0].(*entity.UploadSession)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// GetSessionByToken indicates an expected call of GetSessionByToken.
+func (mr *MockUploadSessionRepositoryMockRecorder) GetSessionByToken(ctx, token any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByToken", reflect.TypeOf((*MockUploadSessionRepository)(nil).GetSessionByToken), ctx, token)
+}
+
+// GetSessionsByTargetName mocks base method.
+func (m *MockUploadSessionRepository) GetSessionsByTargetName(ctx context.Context, targetName string) ([]*entity.UploadSession, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionsByTargetName", ctx, targetName)
+	ret0, _ := ret[0].([]*entity.UploadSession)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// GetSessionsByTargetName indicates an expected call of GetSessionsByTargetName.
+func (mr *MockUploadSessionRepositoryMockRecorder) GetSessionsByTargetName(ctx, targetName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionsByTargetName", reflect.TypeOf((*MockUploadSessionRepository)(nil).GetSessionsByTargetName), ctx, targetName)
+}
+
+// SavePart mocks base method.
+func (m *MockUploadSessionRepository) SavePart(ctx context.Context, session *entity.UploadSession, part *entity.MultipartPart) (*entity.MultipartPart, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SavePart", ctx, session, part)
+	ret0, _ := ret[0].(*entity.MultipartPart)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// SavePart indicates an expected call of SavePart.
+func (mr *MockUploadSessionRepositoryMockRecorder) SavePart(ctx, session, part any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SavePart", reflect.TypeOf((*MockUploadSessionRepository)(nil).SavePart), ctx, session, part)
+}
+
+// MockBlobRepository is a mock of BlobRepository interface.
+type MockBlobRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockBlobRepositoryMockRecorder is the mock recorder for MockBlobRepository.
+type MockBlobRepositoryMockRecorder struct {
+	mock *MockBlobRepository
+}
+
+// NewMockBlobRepository creates a new mock instance.
+func NewMockBlobRepository(ctrl *gomock.Controller) *MockBlobRepository {
+	mock := &MockBlobRepository{ctrl: ctrl}
+	mock.recorder = &MockBlobRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobRepository) EXPECT() *MockBlobRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteUnreferencedBlobs mocks base method.
+func (m *MockBlobRepository) DeleteUnreferencedBlobs(ctx context.Context) ([]*entity.Blob, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUnreferencedBlobs", ctx)
+	ret0, _ := ret[0].([]*entity.Blob)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// DeleteUnreferencedBlobs indicates an expected call of DeleteUnreferencedBlobs.
+func (mr *MockBlobRepositoryMockRecorder) DeleteUnreferencedBlobs(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUnreferencedBlobs", reflect.TypeOf((*MockBlobRepository)(nil).DeleteUnreferencedBlobs), ctx)
+}
+
+// GetBlobPaths mocks base method.
+func (m *MockBlobRepository) GetBlobPaths(ctx context.Context) ([]string, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlobPaths", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// GetBlobPaths indicates an expected call of GetBlobPaths.
+func (mr *MockBlobRepositoryMockRecorder) GetBlobPaths(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlobPaths", reflect.TypeOf((*MockBlobRepository)(nil).GetBlobPaths), ctx)
+}
+
+// RecountBlobRefs mocks base method.
+func (m *MockBlobRepository) RecountBlobRefs(ctx context.Context) (int64, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecountBlobRefs", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// RecountBlobRefs indicates an expected call of RecountBlobRefs.
+func (mr *MockBlobRepositoryMockRecorder) RecountBlobRefs(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecountBlobRefs", reflect.TypeOf((*MockBlobRepository)(nil).RecountBlobRefs), ctx)
+}
@@ -13,7 +13,9 @@ import (
 	context "context"
 	io "io"
 	reflect "reflect"
+	time "time"
 
+	entity "github.com/tomoya.tokunaga/server/internal/domain/entity"
 	error "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -42,6 +44,37 @@ func (m *MockFileStorageRepository) EXPECT() *MockFileStorageRepositoryMockRecor
 	return m.recorder
 }
 
+// AppendPartialChunk mocks base method.
+func (m *MockFileStorageRepository) AppendPartialChunk(ctx context.Context, reader io.Reader, filePath string, offset uint64) (uint64, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendPartialChunk", ctx, reader, filePath, offset)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// AppendPartialChunk indicates an expected call of AppendPartialChunk.
+func (mr *MockFileStorageRepositoryMockRecorder) AppendPartialChunk(ctx, reader, filePath, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendPartialChunk", reflect.TypeOf((*MockFileStorageRepository)(nil).AppendPartialChunk), ctx, reader, filePath, offset)
+}
+
+// CommitPartialChunk mocks base method.
+func (m *MockFileStorageRepository) CommitPartialChunk(ctx context.Context, filePath string, codec entity.StorageCodec, checksum string) (uint64, string, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitPartialChunk", ctx, filePath, codec, checksum)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error.CustomError)
+	return ret0, ret1, ret2
+}
+
+// CommitPartialChunk indicates an expected call of CommitPartialChunk.
+func (mr *MockFileStorageRepositoryMockRecorder) CommitPartialChunk(ctx, filePath, codec, checksum any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitPartialChunk", reflect.TypeOf((*MockFileStorageRepository)(nil).CommitPartialChunk), ctx, filePath, codec, checksum)
+}
+
 // CreateDirectory mocks base method.
 func (m *MockFileStorageRepository) CreateDirectory(ctx context.Context, dirPath string) error.CustomError {
 	m.ctrl.T.Helper()
@@ -113,6 +146,79 @@ func (mr *MockFileStorageRepositoryMockRecorder) GetAvailableSpace(ctx, dirPath
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAvailableSpace", reflect.TypeOf((*MockFileStorageRepository)(nil).GetAvailableSpace), ctx, dirPath)
 }
 
+// GetPartialChunkSize mocks base method.
+func (m *MockFileStorageRepository) GetPartialChunkSize(ctx context.Context, filePath string) (uint64, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPartialChunkSize", ctx, filePath)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// GetPartialChunkSize indicates an expected call of GetPartialChunkSize.
+func (mr *MockFileStorageRepositoryMockRecorder) GetPartialChunkSize(ctx, filePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPartialChunkSize", reflect.TypeOf((*MockFileStorageRepository)(nil).GetPartialChunkSize), ctx, filePath)
+}
+
+// ListFiles mocks base method.
+func (m *MockFileStorageRepository) ListFiles(ctx context.Context, dirPath string) ([]string, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFiles", ctx, dirPath)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ListFiles indicates an expected call of ListFiles.
+func (mr *MockFileStorageRepositoryMockRecorder) ListFiles(ctx, dirPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFiles", reflect.TypeOf((*MockFileStorageRepository)(nil).ListFiles), ctx, dirPath)
+}
+
+// ListFilesModifiedBefore mocks base method.
+func (m *MockFileStorageRepository) ListFilesModifiedBefore(ctx context.Context, dirPath string, before time.Time) ([]string, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFilesModifiedBefore", ctx, dirPath, before)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ListFilesModifiedBefore indicates an expected call of ListFilesModifiedBefore.
+func (mr *MockFileStorageRepositoryMockRecorder) ListFilesModifiedBefore(ctx, dirPath, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFilesModifiedBefore", reflect.TypeOf((*MockFileStorageRepository)(nil).ListFilesModifiedBefore), ctx, dirPath, before)
+}
+
+// MoveFile mocks base method.
+func (m *MockFileStorageRepository) MoveFile(ctx context.Context, srcPath, dstPath string) error.CustomError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveFile", ctx, srcPath, dstPath)
+	ret0, _ := ret[0].(error.CustomError)
+	return ret0
+}
+
+// MoveFile indicates an expected call of MoveFile.
+func (mr *MockFileStorageRepositoryMockRecorder) MoveFile(ctx, srcPath, dstPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveFile", reflect.TypeOf((*MockFileStorageRepository)(nil).MoveFile), ctx, srcPath, dstPath)
+}
+
+// ReadChunk mocks base method.
+func (m *MockFileStorageRepository) ReadChunk(ctx context.Context, writer io.Writer, filePath string, codec entity.StorageCodec, offset, length uint64) error.CustomError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadChunk", ctx, writer, filePath, codec, offset, length)
+	ret0, _ := ret[0].(error.CustomError)
+	return ret0
+}
+
+// ReadChunk indicates an expected call of ReadChunk.
+func (mr *MockFileStorageRepositoryMockRecorder) ReadChunk(ctx, writer, filePath, codec, offset, length any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadChunk", reflect.TypeOf((*MockFileStorageRepository)(nil).ReadChunk), ctx, writer, filePath, codec, offset, length)
+}
+
 // UpdateAvailableSpace mocks base method.
 func (m *MockFileStorageRepository) UpdateAvailableSpace(sizeChange int64) {
 	m.ctrl.T.Helper()
@@ -126,15 +232,17 @@ func (mr *MockFileStorageRepositoryMockRecorder) UpdateAvailableSpace(sizeChange
 }
 
 // WriteChunk mocks base method.
-func (m *MockFileStorageRepository) WriteChunk(ctx context.Context, reader io.Reader, filePath string) error.CustomError {
+func (m *MockFileStorageRepository) WriteChunk(ctx context.Context, reader io.Reader, filePath string, codec entity.StorageCodec, checksum string) (uint64, string, error.CustomError) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WriteChunk", ctx, reader, filePath)
-	ret0, _ := ret[0].(error.CustomError)
-	return ret0
+	ret := m.ctrl.Call(m, "WriteChunk", ctx, reader, filePath, codec, checksum)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error.CustomError)
+	return ret0, ret1, ret2
 }
 
 // WriteChunk indicates an expected call of WriteChunk.
-func (mr *MockFileStorageRepositoryMockRecorder) WriteChunk(ctx, reader, filePath any) *gomock.Call {
+func (mr *MockFileStorageRepositoryMockRecorder) WriteChunk(ctx, reader, filePath, codec, checksum any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteChunk", reflect.TypeOf((*MockFileStorageRepository)(nil).WriteChunk), ctx, reader, filePath)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteChunk", reflect.TypeOf((*MockFileStorageRepository)(nil).WriteChunk), ctx, reader, filePath, codec, checksum)
 }
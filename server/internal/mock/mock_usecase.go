@@ -11,6 +11,7 @@ package mock
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	entity "github.com/tomoya.tokunaga/server/internal/domain/entity"
@@ -58,6 +59,67 @@ func (mr *MockFileGetUseCaseMockRecorder) Execute(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockFileGetUseCase)(nil).Execute), ctx)
 }
 
+// ExecuteGetChunk mocks base method.
+func (m *MockFileGetUseCase) ExecuteGetChunk(ctx context.Context, fileID, chunkNumber uint64) (*entity.File, *entity.FileChunk, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteGetChunk", ctx, fileID, chunkNumber)
+	ret0, _ := ret[0].(*entity.File)
+	ret1, _ := ret[1].(*entity.FileChunk)
+	ret2, _ := ret[2].(error.CustomError)
+	return ret0, ret1, ret2
+}
+
+// ExecuteGetChunk indicates an expected call of ExecuteGetChunk.
+func (mr *MockFileGetUseCaseMockRecorder) ExecuteGetChunk(ctx, fileID, chunkNumber any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteGetChunk", reflect.TypeOf((*MockFileGetUseCase)(nil).ExecuteGetChunk), ctx, fileID, chunkNumber)
+}
+
+// ExecuteGetChunkProof mocks base method.
+func (m *MockFileGetUseCase) ExecuteGetChunkProof(ctx context.Context, file *entity.File, chunk *entity.FileChunk) (*entity.MerkleProof, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteGetChunkProof", ctx, file, chunk)
+	ret0, _ := ret[0].(*entity.MerkleProof)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecuteGetChunkProof indicates an expected call of ExecuteGetChunkProof.
+func (mr *MockFileGetUseCaseMockRecorder) ExecuteGetChunkProof(ctx, file, chunk any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteGetChunkProof", reflect.TypeOf((*MockFileGetUseCase)(nil).ExecuteGetChunkProof), ctx, file, chunk)
+}
+
+// ExecuteGetContent mocks base method.
+func (m *MockFileGetUseCase) ExecuteGetContent(ctx context.Context, fileName string) (*entity.File, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteGetContent", ctx, fileName)
+	ret0, _ := ret[0].(*entity.File)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecuteGetContent indicates an expected call of ExecuteGetContent.
+func (mr *MockFileGetUseCaseMockRecorder) ExecuteGetContent(ctx, fileName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteGetContent", reflect.TypeOf((*MockFileGetUseCase)(nil).ExecuteGetContent), ctx, fileName)
+}
+
+// ExecuteGetManifest mocks base method.
+func (m *MockFileGetUseCase) ExecuteGetManifest(ctx context.Context, fileName string) (*entity.FileManifest, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteGetManifest", ctx, fileName)
+	ret0, _ := ret[0].(*entity.FileManifest)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecuteGetManifest indicates an expected call of ExecuteGetManifest.
+func (mr *MockFileGetUseCaseMockRecorder) ExecuteGetManifest(ctx, fileName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteGetManifest", reflect.TypeOf((*MockFileGetUseCase)(nil).ExecuteGetManifest), ctx, fileName)
+}
+
 // ExecuteGetStats mocks base method.
 func (m *MockFileGetUseCase) ExecuteGetStats(ctx context.Context, fileName string) (*entity.File, error.CustomError) {
 	m.ctrl.T.Helper()
@@ -73,6 +135,49 @@ func (mr *MockFileGetUseCaseMockRecorder) ExecuteGetStats(ctx, fileName any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteGetStats", reflect.TypeOf((*MockFileGetUseCase)(nil).ExecuteGetStats), ctx, fileName)
 }
 
+// ExecuteGetUploadedFiles mocks base method.
+func (m *MockFileGetUseCase) ExecuteGetUploadedFiles(ctx context.Context) ([]*entity.File, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteGetUploadedFiles", ctx)
+	ret0, _ := ret[0].([]*entity.File)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecuteGetUploadedFiles indicates an expected call of ExecuteGetUploadedFiles.
+func (mr *MockFileGetUseCaseMockRecorder) ExecuteGetUploadedFiles(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteGetUploadedFiles", reflect.TypeOf((*MockFileGetUseCase)(nil).ExecuteGetUploadedFiles), ctx)
+}
+
+// ExecuteStreamChunk mocks base method.
+func (m *MockFileGetUseCase) ExecuteStreamChunk(ctx context.Context, file *entity.File, chunk *entity.FileChunk, writer io.Writer) error.CustomError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteStreamChunk", ctx, file, chunk, writer)
+	ret0, _ := ret[0].(error.CustomError)
+	return ret0
+}
+
+// ExecuteStreamChunk indicates an expected call of ExecuteStreamChunk.
+func (mr *MockFileGetUseCaseMockRecorder) ExecuteStreamChunk(ctx, file, chunk, writer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteStreamChunk", reflect.TypeOf((*MockFileGetUseCase)(nil).ExecuteStreamChunk), ctx, file, chunk, writer)
+}
+
+// ExecuteStreamContent mocks base method.
+func (m *MockFileGetUseCase) ExecuteStreamContent(ctx context.Context, file *entity.File, writer io.Writer, offset, length uint64) error.CustomError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteStreamContent", ctx, file, writer, offset, length)
+	ret0, _ := ret[0].(error.CustomError)
+	return ret0
+}
+
+// ExecuteStreamContent indicates an expected call of ExecuteStreamContent.
+func (mr *MockFileGetUseCaseMockRecorder) ExecuteStreamContent(ctx, file, writer, offset, length any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteStreamContent", reflect.TypeOf((*MockFileGetUseCase)(nil).ExecuteStreamContent), ctx, file, writer, offset, length)
+}
+
 // MockFileUploadUseCase is a mock of FileUploadUseCase interface.
 type MockFileUploadUseCase struct {
 	ctrl     *gomock.Controller
@@ -111,25 +216,129 @@ func (mr *MockFileUploadUseCaseMockRecorder) Execute(ctx, input any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockFileUploadUseCase)(nil).Execute), ctx, input)
 }
 
+// ExecuteAbort mocks base method.
+func (m *MockFileUploadUseCase) ExecuteAbort(ctx context.Context, sessionToken string) error.CustomError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteAbort", ctx, sessionToken)
+	ret0, _ := ret[0].(error.CustomError)
+	return ret0
+}
+
+// ExecuteAbort indicates an expected call of ExecuteAbort.
+func (mr *MockFileUploadUseCaseMockRecorder) ExecuteAbort(ctx, sessionToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteAbort", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteAbort), ctx, sessionToken)
+}
+
+// ExecuteAbortMultipart mocks base method.
+func (m *MockFileUploadUseCase) ExecuteAbortMultipart(ctx context.Context, uploadID string) error.CustomError {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteAbortMultipart", ctx, uploadID)
+	ret0, _ := ret[0].(error.CustomError)
+	return ret0
+}
+
+// ExecuteAbortMultipart indicates an expected call of ExecuteAbortMultipart.
+func (mr *MockFileUploadUseCaseMockRecorder) ExecuteAbortMultipart(ctx, uploadID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteAbortMultipart", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteAbortMultipart), ctx, uploadID)
+}
+
+// ExecuteAppend mocks base method.
+func (m *MockFileUploadUseCase) ExecuteAppend(ctx context.Context, input usecase.FileUploadUseCaseExecuteAppendInput) (uint64, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteAppend", ctx, input)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecuteAppend indicates an expected call of ExecuteAppend.
+func (mr *MockFileUploadUseCaseMockRecorder) ExecuteAppend(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteAppend", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteAppend), ctx, input)
+}
+
+// ExecuteCommit mocks base method.
+func (m *MockFileUploadUseCase) ExecuteCommit(ctx context.Context, input usecase.FileUploadUseCaseExecuteCommitInput) (*entity.File, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteCommit", ctx, input)
+	ret0, _ := ret[0].(*entity.File)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecuteCommit indicates an expected call of ExecuteCommit.
+func (mr *MockFileUploadUseCaseMockRecorder) ExecuteCommit(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteCommit", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteCommit), ctx, input)
+}
+
+// ExecuteCompleteMultipart mocks base method.
+func (m *MockFileUploadUseCase) ExecuteCompleteMultipart(ctx context.Context, input usecase.FileUploadUseCaseExecuteCompleteMultipartInput) (*entity.File, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteCompleteMultipart", ctx, input)
+	ret0, _ := ret[0].(*entity.File)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecuteCompleteMultipart indicates an expected call of ExecuteCompleteMultipart.
+func (mr *MockFileUploadUseCaseMockRecorder) ExecuteCompleteMultipart(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteCompleteMultipart", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteCompleteMultipart), ctx, input)
+}
+
+// ExecuteCreateMultipart mocks base method.
+func (m *MockFileUploadUseCase) ExecuteCreateMultipart(ctx context.Context, fileName string) (*entity.MultipartUpload, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteCreateMultipart", ctx, fileName)
+	ret0, _ := ret[0].(*entity.MultipartUpload)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecuteCreateMultipart indicates an expected call of ExecuteCreateMultipart.
+func (mr *MockFileUploadUseCaseMockRecorder) ExecuteCreateMultipart(ctx, fileName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteCreateMultipart", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteCreateMultipart), ctx, fileName)
+}
+
 // ExecuteFailRecovery mocks base method.
-func (m *MockFileUploadUseCase) ExecuteFailRecovery(ctx context.Context, fileID, chunkID uint64) error.CustomError {
+func (m *MockFileUploadUseCase) ExecuteFailRecovery(ctx context.Context, sessionToken string, chunkNumber uint64) error.CustomError {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExecuteFailRecovery", ctx, fileID, chunkID)
+	ret := m.ctrl.Call(m, "ExecuteFailRecovery", ctx, sessionToken, chunkNumber)
 	ret0, _ := ret[0].(error.CustomError)
 	return ret0
 }
 
 // ExecuteFailRecovery indicates an expected call of ExecuteFailRecovery.
-func (mr *MockFileUploadUseCaseMockRecorder) ExecuteFailRecovery(ctx, fileID, chunkID any) *gomock.Call {
+func (mr *MockFileUploadUseCaseMockRecorder) ExecuteFailRecovery(ctx, sessionToken, chunkNumber any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteFailRecovery", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteFailRecovery), ctx, fileID, chunkID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteFailRecovery", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteFailRecovery), ctx, sessionToken, chunkNumber)
+}
+
+// ExecuteGetOffset mocks base method.
+func (m *MockFileUploadUseCase) ExecuteGetOffset(ctx context.Context, sessionToken string) (*entity.UploadSession, uint64, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteGetOffset", ctx, sessionToken)
+	ret0, _ := ret[0].(*entity.UploadSession)
+	ret1, _ := ret[1].(uint64)
+	ret2, _ := ret[2].(error.CustomError)
+	return ret0, ret1, ret2
+}
+
+// ExecuteGetOffset indicates an expected call of ExecuteGetOffset.
+func (mr *MockFileUploadUseCaseMockRecorder) ExecuteGetOffset(ctx, sessionToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteGetOffset", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteGetOffset), ctx, sessionToken)
 }
 
 // ExecuteInit mocks base method.
-func (m *MockFileUploadUseCase) ExecuteInit(ctx context.Context, input usecase.FileUploadUseCaseExecuteInitInput) (*entity.File, []*entity.FileChunk, error.CustomError) {
+func (m *MockFileUploadUseCase) ExecuteInit(ctx context.Context, input usecase.FileUploadUseCaseExecuteInitInput) (*entity.UploadSession, []*entity.FileChunk, error.CustomError) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "ExecuteInit", ctx, input)
-	ret0, _ := ret[0].(*entity.File)
+	ret0, _ := ret[0].(*entity.UploadSession)
 	ret1, _ := ret[1].([]*entity.FileChunk)
 	ret2, _ := ret[2].(error.CustomError)
 	return ret0, ret1, ret2
@@ -141,6 +350,51 @@ func (mr *MockFileUploadUseCaseMockRecorder) ExecuteInit(ctx, input any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteInit", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteInit), ctx, input)
 }
 
+// ExecutePut mocks base method.
+func (m *MockFileUploadUseCase) ExecutePut(ctx context.Context, input usecase.FileUploadUseCaseExecutePutInput) (*entity.File, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecutePut", ctx, input)
+	ret0, _ := ret[0].(*entity.File)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecutePut indicates an expected call of ExecutePut.
+func (mr *MockFileUploadUseCaseMockRecorder) ExecutePut(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecutePut", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecutePut), ctx, input)
+}
+
+// ExecuteSweepSessions mocks base method.
+func (m *MockFileUploadUseCase) ExecuteSweepSessions(ctx context.Context) (int, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteSweepSessions", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecuteSweepSessions indicates an expected call of ExecuteSweepSessions.
+func (mr *MockFileUploadUseCaseMockRecorder) ExecuteSweepSessions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteSweepSessions", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteSweepSessions), ctx)
+}
+
+// ExecuteUploadPart mocks base method.
+func (m *MockFileUploadUseCase) ExecuteUploadPart(ctx context.Context, input usecase.FileUploadUseCaseExecuteUploadPartInput) (*entity.MultipartPart, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteUploadPart", ctx, input)
+	ret0, _ := ret[0].(*entity.MultipartPart)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecuteUploadPart indicates an expected call of ExecuteUploadPart.
+func (mr *MockFileUploadUseCaseMockRecorder) ExecuteUploadPart(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteUploadPart", reflect.TypeOf((*MockFileUploadUseCase)(nil).ExecuteUploadPart), ctx, input)
+}
+
 // MockFileDeleteUseCase is a mock of FileDeleteUseCase interface.
 type MockFileDeleteUseCase struct {
 	ctrl     *gomock.Controller
@@ -178,3 +432,135 @@ func (mr *MockFileDeleteUseCaseMockRecorder) Execute(ctx, fileName any) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockFileDeleteUseCase)(nil).Execute), ctx, fileName)
 }
+
+// MockFileScrubUseCase is a mock of FileScrubUseCase interface.
+type MockFileScrubUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileScrubUseCaseMockRecorder
+	isgomock struct{}
+}
+
+// MockFileScrubUseCaseMockRecorder is the mock recorder for MockFileScrubUseCase.
+type MockFileScrubUseCaseMockRecorder struct {
+	mock *MockFileScrubUseCase
+}
+
+// NewMockFileScrubUseCase creates a new mock instance.
+func NewMockFileScrubUseCase(ctrl *gomock.Controller) *MockFileScrubUseCase {
+	mock := &MockFileScrubUseCase{ctrl: ctrl}
+	mock.recorder = &MockFileScrubUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFileScrubUseCase) EXPECT() *MockFileScrubUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Execute mocks base method.
+func (m *MockFileScrubUseCase) Execute(ctx context.Context) (*entity.ScrubRun, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Execute", ctx)
+	ret0, _ := ret[0].(*entity.ScrubRun)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// Execute indicates an expected call of Execute.
+func (mr *MockFileScrubUseCaseMockRecorder) Execute(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockFileScrubUseCase)(nil).Execute), ctx)
+}
+
+// ExecuteGetReport mocks base method.
+func (m *MockFileScrubUseCase) ExecuteGetReport(ctx context.Context) (*entity.ScrubReport, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecuteGetReport", ctx)
+	ret0, _ := ret[0].(*entity.ScrubReport)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// ExecuteGetReport indicates an expected call of ExecuteGetReport.
+func (mr *MockFileScrubUseCaseMockRecorder) ExecuteGetReport(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteGetReport", reflect.TypeOf((*MockFileScrubUseCase)(nil).ExecuteGetReport), ctx)
+}
+
+// MockFileFsckUseCase is a mock of FileFsckUseCase interface.
+type MockFileFsckUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileFsckUseCaseMockRecorder
+	isgomock struct{}
+}
+
+// MockFileFsckUseCaseMockRecorder is the mock recorder for MockFileFsckUseCase.
+type MockFileFsckUseCaseMockRecorder struct {
+	mock *MockFileFsckUseCase
+}
+
+// NewMockFileFsckUseCase creates a new mock instance.
+func NewMockFileFsckUseCase(ctrl *gomock.Controller) *MockFileFsckUseCase {
+	mock := &MockFileFsckUseCase{ctrl: ctrl}
+	mock.recorder = &MockFileFsckUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFileFsckUseCase) EXPECT() *MockFileFsckUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Execute mocks base method.
+func (m *MockFileFsckUseCase) Execute(ctx context.Context, repair bool) (*entity.FsckReport, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Execute", ctx, repair)
+	ret0, _ := ret[0].(*entity.FsckReport)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// Execute indicates an expected call of Execute.
+func (mr *MockFileFsckUseCaseMockRecorder) Execute(ctx, repair any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockFileFsckUseCase)(nil).Execute), ctx, repair)
+}
+
+// MockFileGCUseCase is a mock of FileGCUseCase interface.
+type MockFileGCUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockFileGCUseCaseMockRecorder
+	isgomock struct{}
+}
+
+// MockFileGCUseCaseMockRecorder is the mock recorder for MockFileGCUseCase.
+type MockFileGCUseCaseMockRecorder struct {
+	mock *MockFileGCUseCase
+}
+
+// NewMockFileGCUseCase creates a new mock instance.
+func NewMockFileGCUseCase(ctrl *gomock.Controller) *MockFileGCUseCase {
+	mock := &MockFileGCUseCase{ctrl: ctrl}
+	mock.recorder = &MockFileGCUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFileGCUseCase) EXPECT() *MockFileGCUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Execute mocks base method.
+func (m *MockFileGCUseCase) Execute(ctx context.Context) (*entity.BlobGCRun, error.CustomError) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Execute", ctx)
+	ret0, _ := ret[0].(*entity.BlobGCRun)
+	ret1, _ := ret[1].(error.CustomError)
+	return ret0, ret1
+}
+
+// Execute indicates an expected call of Execute.
+func (mr *MockFileGCUseCaseMockRecorder) Execute(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockFileGCUseCase)(nil).Execute), ctx)
+}
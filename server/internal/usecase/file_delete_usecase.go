@@ -37,17 +37,32 @@ func (uc *fileDeleteUseCase) Execute(ctx context.Context, fileName string) e.Cus
 		return e.NewNotFoundError(fmt.Errorf("%s not found", fileName), "")
 	}
 
-	// Delete all file chunks from storage
+	// The chunks stored on their own are deleted along with the file, while the ones stored in the blobs are deleted
+	// only when the blobs are no longer shared with the other files
 	chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
 	if err != nil {
 		return err
 	}
 
-	// Use worker wp for parallel chunk deletion
+	// Delete the file and its chunks (cascade delete), which releases the blobs of the chunks. The content is deleted
+	// after the records, so that no file refers to the content being deleted
+	releasedBlobs, err := uc.fileRepo.DeleteFileByID(ctx, file.ID)
+	if err != nil {
+		return err
+	}
+	aloneChunks := chunksStoredAlone(chunks)
+
+	// Use worker wp for parallel deletion. The space of the content is freed by the storage as it's deleted
 	wp := concurrency.NewWorkerPool(uc.config.WorkerPoolSize)
 	wp.Start(ctx)
 
-	for _, chunk := range chunks {
+	for _, blob := range releasedBlobs {
+		wp.Submit(func() error {
+			_ = uc.storageRepo.DeleteFile(ctx, blob.FilePath)
+			return nil
+		})
+	}
+	for _, chunk := range aloneChunks {
 		wp.Submit(func() error {
 			_ = uc.storageRepo.DeleteFile(ctx, chunk.FilePath)
 			return nil
@@ -55,19 +70,47 @@ func (uc *fileDeleteUseCase) Execute(ctx context.Context, fileName string) e.Cus
 	}
 	wp.Wait()
 
-	// Delete the file directory
-	fileDirPath := filepath.Join(uc.config.BaseStorageDir, fileName)
-	if err := uc.storageRepo.DeleteDirectory(ctx, fileDirPath); err != nil {
-		return err
+	// Delete the file directory, which is named after the file or the upload session of the file
+	return deleteChunkDirectories(ctx, uc.storageRepo, aloneChunks)
+}
+
+// chunksStoredAlone returns the chunks stored in the files of their own rather than in the blobs, which are the chunks
+// not uploaded yet and the ones uploaded before the blobs are introduced
+func chunksStoredAlone(chunks []*entity.FileChunk) []*entity.FileChunk {
+	aloneChunks := make([]*entity.FileChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.BlobID == 0 {
+			aloneChunks = append(aloneChunks, chunk)
+		}
 	}
+	return aloneChunks
+}
 
-	// Delete the file and its chunks (cascade delete)
-	if err := uc.fileRepo.DeleteFileByID(ctx, file.ID); err != nil {
-		return err
+// deleteChunkDirectories deletes the directories the chunks stored on their own are in, which also deletes their
+// partial chunks
+func deleteChunkDirectories(ctx context.Context, storageRepo storage.FileStorageRepository, chunks []*entity.FileChunk) e.CustomError {
+	deletedDirPaths := make(map[string]struct{})
+	for _, chunk := range chunks {
+		dirPath := filepath.Dir(chunk.FilePath)
+		if _, ok := deletedDirPaths[dirPath]; ok {
+			continue
+		}
+		if err := storageRepo.DeleteDirectory(ctx, dirPath); err != nil {
+			return err
+		}
+		deletedDirPaths[dirPath] = struct{}{}
 	}
 
-	// Update the available space
-	uc.storageRepo.UpdateAvailableSpace(int64(file.Size))
+	return nil
+}
+
+// deleteBlobFiles deletes the files of the blobs which are deleted as no chunk refers to them anymore
+func deleteBlobFiles(ctx context.Context, storageRepo storage.FileStorageRepository, blobs []*entity.Blob) e.CustomError {
+	for _, blob := range blobs {
+		if err := storageRepo.DeleteFile(ctx, blob.FilePath); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
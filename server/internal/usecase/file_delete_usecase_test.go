@@ -41,6 +41,12 @@ func TestFileDeleteUseCase_Execute(t *testing.T) {
 		{ID: 1, ParentID: testFileID, ChunkNumber: 1, FilePath: "/test/storage/test_file.txt/chunk_1", Status: entity.FileStatusUploaded},
 		{ID: 2, ParentID: testFileID, ChunkNumber: 2, FilePath: "/test/storage/test_file.txt/chunk_2", Status: entity.FileStatusUploaded},
 	}
+	// chunk 1 shares its blob with another file, so only the blob of chunk 2 is released
+	blobChunks := []*entity.FileChunk{
+		{ID: 1, ParentID: testFileID, ChunkNumber: 1, FilePath: "/test/storage/.objects/0b/0b1d", BlobID: 1, Status: entity.FileStatusUploaded},
+		{ID: 2, ParentID: testFileID, ChunkNumber: 2, FilePath: "/test/storage/.objects/1b/1b1d", BlobID: 2, Status: entity.FileStatusUploaded},
+	}
+	releasedBlob := &entity.Blob{ID: 2, FilePath: "/test/storage/.objects/1b/1b1d"}
 	testFile := &entity.File{
 		ID:        testFileID,
 		Name:      testFileName,
@@ -65,13 +71,23 @@ func TestFileDeleteUseCase_Execute(t *testing.T) {
 			setupMocks: func() {
 				mockFileRepo.EXPECT().GetFileByName(ctx, testFileName).Return(testFile, nil)
 				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(testChunks, nil)
+				mockFileRepo.EXPECT().DeleteFileByID(ctx, testFileID).Return(nil, nil)
 				// Concurrent deletion, order doesn't matter, called for each chunk
 				mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), testChunks[0].FilePath).Return(nil).Times(1)
 				mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), testChunks[1].FilePath).Return(nil).Times(1)
 				mockStorageRepo.EXPECT().DeleteDirectory(ctx, testFileDirPath).Return(nil)
-				mockFileRepo.EXPECT().DeleteFileByID(ctx, testFileID).Return(nil)
-				// Expect the call to update available space
-				mockStorageRepo.EXPECT().UpdateAvailableSpace(int64(testFileSize))
+			},
+			expectedErr: nil,
+		},
+		{
+			name:     "Success - Chunks Stored In Blobs",
+			fileName: testFileName,
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetFileByName(ctx, testFileName).Return(testFile, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(blobChunks, nil)
+				mockFileRepo.EXPECT().DeleteFileByID(ctx, testFileID).Return([]*entity.Blob{releasedBlob}, nil)
+				// only the released blob is deleted, and the shared one is kept
+				mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), releasedBlob.FilePath).Return(nil).Times(1)
 			},
 			expectedErr: nil,
 		},
@@ -106,6 +122,7 @@ func TestFileDeleteUseCase_Execute(t *testing.T) {
 			setupMocks: func() {
 				mockFileRepo.EXPECT().GetFileByName(ctx, testFileName).Return(testFile, nil)
 				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(testChunks, nil)
+				mockFileRepo.EXPECT().DeleteFileByID(ctx, testFileID).Return(nil, nil)
 				mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), gomock.Any()).Return(nil).AnyTimes() // Assume chunk deletion succeeds or is ignored
 				mockStorageRepo.EXPECT().DeleteDirectory(ctx, testFileDirPath).Return(genericError)
 			},
@@ -117,9 +134,8 @@ func TestFileDeleteUseCase_Execute(t *testing.T) {
 			setupMocks: func() {
 				mockFileRepo.EXPECT().GetFileByName(ctx, testFileName).Return(testFile, nil)
 				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(testChunks, nil)
-				mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
-				mockStorageRepo.EXPECT().DeleteDirectory(ctx, testFileDirPath).Return(nil)
-				mockFileRepo.EXPECT().DeleteFileByID(ctx, testFileID).Return(genericError)
+				// the content is kept while the file refers to it
+				mockFileRepo.EXPECT().DeleteFileByID(ctx, testFileID).Return(nil, genericError)
 			},
 			expectedErr: genericError,
 		},
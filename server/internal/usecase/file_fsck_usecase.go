@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/interface/repository/database"
+	"github.com/tomoya.tokunaga/server/internal/interface/repository/storage"
+)
+
+type fileFsckUseCase struct {
+	config      *entity.Config
+	fileRepo    database.FileRepository
+	storageRepo storage.FileStorageRepository
+
+	fsckMutex sync.Mutex // allows only one check at a time
+}
+
+func NewFileFsckUseCase(config *entity.Config, fileRepo database.FileRepository, storageRepo storage.FileStorageRepository) FileFsckUseCase {
+	return &fileFsckUseCase{
+		config:      config,
+		fileRepo:    fileRepo,
+		storageRepo: storageRepo,
+	}
+}
+
+// Execute cross-checks the file and chunk records against the storage and reports the inconsistencies. When repair is
+// true, it also resolves them, so that the affected files can be completed by re-uploading them:
+//   - the missing chunks and the interrupted chunks are set to FAILED along with their files
+//   - the stuck files are set to FAILED
+//   - the orphaned files and directories are deleted
+func (uc *fileFsckUseCase) Execute(ctx context.Context, repair bool) (*entity.FsckReport, e.CustomError) {
+	if !uc.fsckMutex.TryLock() {
+		return nil, e.NewConflictError(fmt.Errorf("fsck is already running"), "")
+	}
+	defer uc.fsckMutex.Unlock()
+
+	report := &entity.FsckReport{
+		CheckedAt:         time.Now(),
+		IsRepaired:        repair,
+		MissingChunks:     []entity.FsckChunk{},
+		InterruptedChunks: []entity.FsckChunk{},
+		StuckFiles:        []entity.FsckFile{},
+		OrphanedPaths:     []string{},
+	}
+
+	files, err := uc.fileRepo.GetFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	referencedPaths := make(map[string]struct{})
+	// the directories of the files being uploaded may have temporary files in them, which are not orphaned
+	activeDirPaths := make(map[string]struct{})
+	knownDirPaths := make(map[string]struct{})
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return nil, e.NewContextError(ctx.Err(), "fsck canceled")
+		}
+
+		isStale := file.UpdatedAt.Before(report.CheckedAt.Add(-uc.config.UploadTimeoutSecond))
+		chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, chunk := range chunks {
+			referencedPaths[chunk.FilePath] = struct{}{}
+			// the chunks are stored in the directory named after the file or the upload session of the file
+			fileDirPath := filepath.Dir(chunk.FilePath)
+			knownDirPaths[fileDirPath] = struct{}{}
+			if !isStale && isUploadingStatus(file.Status) {
+				activeDirPaths[fileDirPath] = struct{}{}
+			}
+		}
+
+		isStuck := isStale && (file.Status == entity.FileStatusInProgress || file.Status == entity.FileStatusVerifying)
+		missingChunks, interruptedChunks, err := uc.checkChunks(ctx, file, chunks, isStuck)
+		if err != nil {
+			return nil, err
+		}
+		for _, chunk := range missingChunks {
+			report.MissingChunks = append(report.MissingChunks, newFsckChunk(file, chunk))
+		}
+		for _, chunk := range interruptedChunks {
+			report.InterruptedChunks = append(report.InterruptedChunks, newFsckChunk(file, chunk))
+		}
+		if isStuck {
+			report.StuckFiles = append(report.StuckFiles, entity.FsckFile{
+				FileID:    file.ID,
+				Name:      file.Name,
+				Status:    file.Status,
+				UpdatedAt: file.UpdatedAt,
+			})
+		}
+
+		if repair {
+			if err := uc.repairFile(ctx, file, chunks, append(missingChunks, interruptedChunks...), isStuck); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	orphanedPaths, err := uc.findOrphanedPaths(ctx, referencedPaths, activeDirPaths, knownDirPaths)
+	if err != nil {
+		return nil, err
+	}
+	report.OrphanedPaths = orphanedPaths
+
+	if repair {
+		for _, path := range orphanedPaths {
+			// a path is either a file or a directory, and deleting the directory removes the files in it
+			if err := uc.storageRepo.DeleteDirectory(ctx, path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// checkChunks returns the chunks of the file which are recorded as stored but whose content is missing or found
+// damaged by the scrubber, and the chunks left IN_PROGRESS when the upload of the file is stuck
+func (uc *fileFsckUseCase) checkChunks(ctx context.Context, file *entity.File, chunks []*entity.FileChunk, isStuck bool) ([]*entity.FileChunk, []*entity.FileChunk, e.CustomError) {
+	missingChunks := []*entity.FileChunk{}
+	interruptedChunks := []*entity.FileChunk{}
+	for _, chunk := range chunks {
+		switch chunk.Status {
+		case entity.FileStatusUploaded:
+			exists, err := uc.storageRepo.FileExists(ctx, chunk.FilePath)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !exists {
+				missingChunks = append(missingChunks, chunk)
+			}
+		case entity.FileStatusDamaged:
+			missingChunks = append(missingChunks, chunk)
+		case entity.FileStatusInProgress:
+			if isStuck {
+				interruptedChunks = append(interruptedChunks, chunk)
+			}
+		}
+	}
+
+	return missingChunks, interruptedChunks, nil
+}
+
+// repairFile sets the broken chunks and the file to FAILED, so that the client re-uploads only those chunks through
+// the re-upload of the file. The re-upload initializes only the chunks which are not UPLOADED, and the verification
+// runs when the last of them is uploaded, so the remaining chunks of a stuck file are set to FAILED as well, and the
+// last chunk is uploaded again when all the chunks of a stuck file are already UPLOADED.
+func (uc *fileFsckUseCase) repairFile(ctx context.Context, file *entity.File, chunks []*entity.FileChunk, brokenChunks []*entity.FileChunk, isStuck bool) e.CustomError {
+	if isStuck {
+		for _, chunk := range chunks {
+			if chunk.Status == entity.FileStatusInitialized || chunk.Status == entity.FileStatusFailed {
+				brokenChunks = append(brokenChunks, chunk)
+			}
+		}
+		if len(brokenChunks) == 0 && len(chunks) > 0 {
+			brokenChunks = []*entity.FileChunk{chunks[len(chunks)-1]}
+		}
+	}
+	if len(brokenChunks) == 0 {
+		return nil
+	}
+
+	// the blob of a chunk may be shared by the other files, so it's left to be replaced by the re-upload
+	chunkIDs := make([]uint64, len(brokenChunks))
+	for i, chunk := range brokenChunks {
+		if chunk.BlobID == 0 {
+			if err := uc.storageRepo.DeleteFile(ctx, chunk.FilePath); err != nil {
+				return err
+			}
+		}
+		chunkIDs[i] = chunk.ID
+	}
+
+	return uc.fileRepo.UpdateFileAndChunkStatus(ctx, file.ID, chunkIDs, entity.FileStatusFailed)
+}
+
+// findOrphanedPaths returns the files in the storage which no chunk refers to. When no file refers to the directory
+// of the files, the directory is returned instead of the files in it. The object area is left to the garbage collector,
+// which tells the blobs being stored from the orphaned ones.
+func (uc *fileFsckUseCase) findOrphanedPaths(
+	ctx context.Context,
+	referencedPaths map[string]struct{},
+	activeDirPaths map[string]struct{},
+	knownDirPaths map[string]struct{},
+) ([]string, e.CustomError) {
+	storedPaths, err := uc.storageRepo.ListFiles(ctx, uc.config.BaseStorageDir)
+	if err != nil {
+		return nil, err
+	}
+
+	orphanedPaths := []string{}
+	orphanedDirPaths := make(map[string]struct{})
+	for _, path := range storedPaths {
+		if _, ok := referencedPaths[path]; ok {
+			continue
+		}
+
+		// the chunks are stored in the directories of the files under the base directory, and a file right under the
+		// base directory is its own
+		relPath, relErr := filepath.Rel(uc.config.BaseStorageDir, path)
+		if relErr != nil {
+			return nil, e.NewFileStorageError(relErr, fmt.Sprintf("%s is out of the storage", path))
+		}
+		if strings.HasPrefix(relPath, storage.ObjectsDirName+string(filepath.Separator)) {
+			continue
+		}
+		dirPath := path
+		if relDirPath := filepath.Dir(relPath); relDirPath != "." {
+			dirPath = filepath.Join(uc.config.BaseStorageDir, relDirPath)
+		}
+		if _, ok := activeDirPaths[dirPath]; ok {
+			continue
+		}
+		if _, ok := knownDirPaths[dirPath]; ok || dirPath == path {
+			orphanedPaths = append(orphanedPaths, path)
+			continue
+		}
+		if _, ok := orphanedDirPaths[dirPath]; !ok {
+			orphanedDirPaths[dirPath] = struct{}{}
+			orphanedPaths = append(orphanedPaths, dirPath)
+		}
+	}
+
+	return orphanedPaths, nil
+}
+
+// isUploadingStatus reports whether the file can be being uploaded by a client
+func isUploadingStatus(status entity.FileStatus) bool {
+	return status == entity.FileStatusInitialized || status == entity.FileStatusInProgress ||
+		status == entity.FileStatusFailed || status == entity.FileStatusVerifying
+}
+
+func newFsckChunk(file *entity.File, chunk *entity.FileChunk) entity.FsckChunk {
+	return entity.FsckChunk{
+		FileID:      file.ID,
+		FileName:    file.Name,
+		ChunkNumber: chunk.ChunkNumber,
+		Status:      chunk.Status,
+		FilePath:    chunk.FilePath,
+	}
+}
@@ -0,0 +1,330 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/mock"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFileFsckUseCase_Execute(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	config := &entity.Config{BaseStorageDir: "/test/uploads", UploadTimeoutSecond: 5 * time.Second}
+	staleTime := time.Now().Add(-time.Hour)
+
+	// chunk 1 of the uploaded file is lost from the storage
+	uploadedFile := &entity.File{ID: 1, Name: "uploaded.txt", TotalChunks: 2, Status: entity.FileStatusUploaded, UpdatedAt: staleTime}
+	uploadedChunks := []*entity.FileChunk{
+		{ID: 10, ParentID: 1, ChunkNumber: 0, FilePath: "/test/uploads/uploaded.txt/0", Status: entity.FileStatusUploaded},
+		{ID: 11, ParentID: 1, ChunkNumber: 1, FilePath: "/test/uploads/uploaded.txt/1", Status: entity.FileStatusUploaded},
+	}
+	// the server crashed while chunk 1 of the stuck file was being uploaded
+	stuckFile := &entity.File{ID: 2, Name: "stuck.txt", TotalChunks: 3, Status: entity.FileStatusInProgress, UpdatedAt: staleTime}
+	stuckChunks := []*entity.FileChunk{
+		{ID: 20, ParentID: 2, ChunkNumber: 0, FilePath: "/test/uploads/stuck.txt/0", Status: entity.FileStatusUploaded},
+		{ID: 21, ParentID: 2, ChunkNumber: 1, FilePath: "/test/uploads/stuck.txt/1", Status: entity.FileStatusInProgress},
+		{ID: 22, ParentID: 2, ChunkNumber: 2, FilePath: "/test/uploads/stuck.txt/2", Status: entity.FileStatusInitialized},
+	}
+	// the active file is being uploaded, so its chunk being written is not interrupted
+	activeFile := &entity.File{ID: 3, Name: "active.txt", TotalChunks: 1, Status: entity.FileStatusInProgress, UpdatedAt: time.Now()}
+	activeChunks := []*entity.FileChunk{
+		{ID: 30, ParentID: 3, ChunkNumber: 0, FilePath: "/test/uploads/active.txt/0", Status: entity.FileStatusInProgress},
+	}
+	// the server crashed while verifying the file, whose chunks are all uploaded
+	verifyingFile := &entity.File{ID: 4, Name: "verifying.txt", TotalChunks: 2, Status: entity.FileStatusVerifying, UpdatedAt: staleTime}
+	verifyingChunks := []*entity.FileChunk{
+		{ID: 40, ParentID: 4, ChunkNumber: 0, FilePath: "/test/uploads/verifying.txt/0", Status: entity.FileStatusUploaded},
+		{ID: 41, ParentID: 4, ChunkNumber: 1, FilePath: "/test/uploads/verifying.txt/1", Status: entity.FileStatusUploaded},
+	}
+	// the blob of chunk 1 of the file stored in blobs is lost, and the blob of another file is being stored
+	blobFile := &entity.File{ID: 5, Name: "blob.txt", TotalChunks: 2, Status: entity.FileStatusUploaded, UpdatedAt: staleTime}
+	blobChunks := []*entity.FileChunk{
+		{ID: 50, ParentID: 5, ChunkNumber: 0, FilePath: "/test/uploads/.objects/0b/0b1d", BlobID: 1, Status: entity.FileStatusUploaded},
+		{ID: 51, ParentID: 5, ChunkNumber: 1, FilePath: "/test/uploads/.objects/1b/1b1d", BlobID: 2, Status: entity.FileStatusUploaded},
+	}
+	storedPaths := []string{
+		"/test/uploads/uploaded.txt/0",
+		"/test/uploads/stuck.txt/0",
+		"/test/uploads/stuck.txt/5",
+		"/test/uploads/active.txt/.0.tmp-123",
+		"/test/uploads/deleted.txt/0",
+		"/test/uploads/deleted.txt/1",
+		"/test/uploads/stray",
+	}
+
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+	storageError := e.NewFileStorageError(errors.New("storage error"), "")
+
+	// expectCheck expects the files above except the verifying file to be checked
+	expectCheck := func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+		mockFileRepo.EXPECT().GetFiles(ctx).Return([]*entity.File{uploadedFile, stuckFile, activeFile}, nil)
+		mockFileRepo.EXPECT().GetChunksByFileID(ctx, uploadedFile.ID).Return(uploadedChunks, nil)
+		mockStorageRepo.EXPECT().FileExists(ctx, uploadedChunks[0].FilePath).Return(true, nil)
+		mockStorageRepo.EXPECT().FileExists(ctx, uploadedChunks[1].FilePath).Return(false, nil)
+		mockFileRepo.EXPECT().GetChunksByFileID(ctx, stuckFile.ID).Return(stuckChunks, nil)
+		mockStorageRepo.EXPECT().FileExists(ctx, stuckChunks[0].FilePath).Return(true, nil)
+		mockFileRepo.EXPECT().GetChunksByFileID(ctx, activeFile.ID).Return(activeChunks, nil)
+	}
+
+	tests := []struct {
+		name           string
+		repair         bool
+		ctx            func() context.Context
+		setupMocks     func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository)
+		expectedReport *entity.FsckReport
+		expectedErr    e.CustomError
+	}{
+		{
+			name: "Success - Reports Inconsistencies Without Repairing",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				expectCheck(mockFileRepo, mockStorageRepo)
+				mockStorageRepo.EXPECT().ListFiles(ctx, config.BaseStorageDir).Return(storedPaths, nil)
+			},
+			expectedReport: &entity.FsckReport{
+				MissingChunks: []entity.FsckChunk{
+					{FileID: 1, FileName: "uploaded.txt", ChunkNumber: 1, Status: entity.FileStatusUploaded, FilePath: "/test/uploads/uploaded.txt/1"},
+				},
+				InterruptedChunks: []entity.FsckChunk{
+					{FileID: 2, FileName: "stuck.txt", ChunkNumber: 1, Status: entity.FileStatusInProgress, FilePath: "/test/uploads/stuck.txt/1"},
+				},
+				StuckFiles: []entity.FsckFile{
+					{FileID: 2, Name: "stuck.txt", Status: entity.FileStatusInProgress, UpdatedAt: staleTime},
+				},
+				OrphanedPaths: []string{"/test/uploads/stuck.txt/5", "/test/uploads/deleted.txt", "/test/uploads/stray"},
+			},
+		},
+		{
+			name:   "Success - Repairs Inconsistencies",
+			repair: true,
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				expectCheck(mockFileRepo, mockStorageRepo)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, uploadedChunks[1].FilePath).Return(nil)
+				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, uploadedFile.ID, []uint64{11}, entity.FileStatusFailed).Return(nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, stuckChunks[1].FilePath).Return(nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, stuckChunks[2].FilePath).Return(nil)
+				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, stuckFile.ID, []uint64{21, 22}, entity.FileStatusFailed).Return(nil)
+				mockStorageRepo.EXPECT().ListFiles(ctx, config.BaseStorageDir).Return(storedPaths, nil)
+				mockStorageRepo.EXPECT().DeleteDirectory(ctx, "/test/uploads/stuck.txt/5").Return(nil)
+				mockStorageRepo.EXPECT().DeleteDirectory(ctx, "/test/uploads/deleted.txt").Return(nil)
+				mockStorageRepo.EXPECT().DeleteDirectory(ctx, "/test/uploads/stray").Return(nil)
+			},
+			expectedReport: &entity.FsckReport{
+				IsRepaired: true,
+				MissingChunks: []entity.FsckChunk{
+					{FileID: 1, FileName: "uploaded.txt", ChunkNumber: 1, Status: entity.FileStatusUploaded, FilePath: "/test/uploads/uploaded.txt/1"},
+				},
+				InterruptedChunks: []entity.FsckChunk{
+					{FileID: 2, FileName: "stuck.txt", ChunkNumber: 1, Status: entity.FileStatusInProgress, FilePath: "/test/uploads/stuck.txt/1"},
+				},
+				StuckFiles: []entity.FsckFile{
+					{FileID: 2, Name: "stuck.txt", Status: entity.FileStatusInProgress, UpdatedAt: staleTime},
+				},
+				OrphanedPaths: []string{"/test/uploads/stuck.txt/5", "/test/uploads/deleted.txt", "/test/uploads/stray"},
+			},
+		},
+		{
+			name:   "Success - Repairs Stuck Verification By Re-uploading Last Chunk",
+			repair: true,
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFiles(ctx).Return([]*entity.File{verifyingFile}, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, verifyingFile.ID).Return(verifyingChunks, nil)
+				mockStorageRepo.EXPECT().FileExists(ctx, verifyingChunks[0].FilePath).Return(true, nil)
+				mockStorageRepo.EXPECT().FileExists(ctx, verifyingChunks[1].FilePath).Return(true, nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, verifyingChunks[1].FilePath).Return(nil)
+				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, verifyingFile.ID, []uint64{41}, entity.FileStatusFailed).Return(nil)
+				mockStorageRepo.EXPECT().ListFiles(ctx, config.BaseStorageDir).Return([]string{verifyingChunks[0].FilePath, verifyingChunks[1].FilePath}, nil)
+			},
+			expectedReport: &entity.FsckReport{
+				IsRepaired:        true,
+				MissingChunks:     []entity.FsckChunk{},
+				InterruptedChunks: []entity.FsckChunk{},
+				StuckFiles: []entity.FsckFile{
+					{FileID: 4, Name: "verifying.txt", Status: entity.FileStatusVerifying, UpdatedAt: staleTime},
+				},
+				OrphanedPaths: []string{},
+			},
+		},
+		{
+			name:   "Success - Repairs Missing Blob Left To Re-upload",
+			repair: true,
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFiles(ctx).Return([]*entity.File{blobFile}, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, blobFile.ID).Return(blobChunks, nil)
+				mockStorageRepo.EXPECT().FileExists(ctx, blobChunks[0].FilePath).Return(true, nil)
+				mockStorageRepo.EXPECT().FileExists(ctx, blobChunks[1].FilePath).Return(false, nil)
+				// the blob may be shared by the other files, so only the chunk is marked for the re-upload
+				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, blobFile.ID, []uint64{51}, entity.FileStatusFailed).Return(nil)
+				// the unreferenced blob is left to the garbage collector
+				mockStorageRepo.EXPECT().ListFiles(ctx, config.BaseStorageDir).Return([]string{blobChunks[0].FilePath, "/test/uploads/.objects/de/dead"}, nil)
+			},
+			expectedReport: &entity.FsckReport{
+				IsRepaired: true,
+				MissingChunks: []entity.FsckChunk{
+					{FileID: 5, FileName: "blob.txt", ChunkNumber: 1, Status: entity.FileStatusUploaded, FilePath: "/test/uploads/.objects/1b/1b1d"},
+				},
+				InterruptedChunks: []entity.FsckChunk{},
+				StuckFiles:        []entity.FsckFile{},
+				OrphanedPaths:     []string{},
+			},
+		},
+		{
+			name: "Success - Consistent",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFiles(ctx).Return([]*entity.File{}, nil)
+				mockStorageRepo.EXPECT().ListFiles(ctx, config.BaseStorageDir).Return([]string{}, nil)
+			},
+			expectedReport: &entity.FsckReport{
+				MissingChunks:     []entity.FsckChunk{},
+				InterruptedChunks: []entity.FsckChunk{},
+				StuckFiles:        []entity.FsckFile{},
+				OrphanedPaths:     []string{},
+			},
+		},
+		{
+			name: "Error - GetFiles DB Error",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFiles(ctx).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name: "Error - GetChunksByFileID DB Error",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFiles(ctx).Return([]*entity.File{uploadedFile}, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, uploadedFile.ID).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name: "Error - FileExists Storage Error",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFiles(ctx).Return([]*entity.File{uploadedFile}, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, uploadedFile.ID).Return(uploadedChunks, nil)
+				mockStorageRepo.EXPECT().FileExists(ctx, uploadedChunks[0].FilePath).Return(false, storageError)
+			},
+			expectedErr: storageError,
+		},
+		{
+			name:   "Error - UpdateFileAndChunkStatus DB Error",
+			repair: true,
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFiles(ctx).Return([]*entity.File{uploadedFile}, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, uploadedFile.ID).Return(uploadedChunks, nil)
+				mockStorageRepo.EXPECT().FileExists(ctx, uploadedChunks[0].FilePath).Return(true, nil)
+				mockStorageRepo.EXPECT().FileExists(ctx, uploadedChunks[1].FilePath).Return(false, nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, uploadedChunks[1].FilePath).Return(nil)
+				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, uploadedFile.ID, []uint64{11}, entity.FileStatusFailed).Return(dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name: "Error - ListFiles Storage Error",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFiles(ctx).Return([]*entity.File{}, nil)
+				mockStorageRepo.EXPECT().ListFiles(ctx, config.BaseStorageDir).Return(nil, storageError)
+			},
+			expectedErr: storageError,
+		},
+		{
+			name:   "Error - DeleteDirectory Storage Error",
+			repair: true,
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFiles(ctx).Return([]*entity.File{}, nil)
+				mockStorageRepo.EXPECT().ListFiles(ctx, config.BaseStorageDir).Return([]string{"/test/uploads/stray"}, nil)
+				mockStorageRepo.EXPECT().DeleteDirectory(ctx, "/test/uploads/stray").Return(storageError)
+			},
+			expectedErr: storageError,
+		},
+		{
+			name: "Error - Context Canceled",
+			ctx: func() context.Context {
+				canceledCtx, cancel := context.WithCancel(ctx)
+				cancel()
+				return canceledCtx
+			},
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFiles(gomock.Any()).Return([]*entity.File{uploadedFile}, nil)
+			},
+			expectedErr: e.NewContextError(context.Canceled, "fsck canceled"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+			mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+			uc := usecase.NewFileFsckUseCase(config, mockFileRepo, mockStorageRepo)
+
+			tc.setupMocks(mockFileRepo, mockStorageRepo)
+			execCtx := ctx
+			if tc.ctx != nil {
+				execCtx = tc.ctx()
+			}
+			report, err := uc.Execute(execCtx, tc.repair)
+
+			if tc.expectedErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+				assert.Nil(t, report)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.False(t, report.CheckedAt.IsZero())
+			report.CheckedAt = time.Time{}
+			assert.Equal(t, tc.expectedReport, report)
+		})
+	}
+}
+
+func TestFileFsckUseCase_Execute_AlreadyRunning(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileFsckUseCase(&entity.Config{BaseStorageDir: "/test/uploads"}, mockFileRepo, mockStorageRepo)
+
+	// the first check blocks until the second one is rejected
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockFileRepo.EXPECT().GetFiles(gomock.Any()).DoAndReturn(
+		func(_ context.Context) ([]*entity.File, e.CustomError) {
+			close(started)
+			<-release
+			return []*entity.File{}, nil
+		},
+	)
+	mockStorageRepo.EXPECT().ListFiles(gomock.Any(), "/test/uploads").Return([]string{}, nil)
+
+	done := make(chan e.CustomError)
+	go func() {
+		_, err := uc.Execute(context.Background(), false)
+		done <- err
+	}()
+
+	<-started
+	_, err := uc.Execute(context.Background(), true)
+	require.Error(t, err)
+	assert.Equal(t, "CONFLICT", err.ErrorCode())
+	assert.Contains(t, err.Error(), "fsck is already running")
+
+	close(release)
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/interface/repository/database"
+	"github.com/tomoya.tokunaga/server/internal/interface/repository/storage"
+)
+
+type fileGCUseCase struct {
+	config      *entity.Config
+	blobRepo    database.BlobRepository
+	storageRepo storage.FileStorageRepository
+
+	gcMutex sync.Mutex // allows only one garbage collection at a time
+}
+
+func NewFileGCUseCase(config *entity.Config, blobRepo database.BlobRepository, storageRepo storage.FileStorageRepository) FileGCUseCase {
+	return &fileGCUseCase{
+		config:      config,
+		blobRepo:    blobRepo,
+		storageRepo: storageRepo,
+	}
+}
+
+// Execute collects the garbage of the blob storage by mark and sweep. The reference counts of the blobs are recounted
+// from the chunks first, so that the drift left by a crash is corrected, and the blobs which no chunk refers to are
+// deleted along with their files. The files in the object area which no blob refers to are deleted as well once
+// they're older than the grace period, which leaves the files of the chunks being stored as blobs
+func (uc *fileGCUseCase) Execute(ctx context.Context) (*entity.BlobGCRun, e.CustomError) {
+	if !uc.gcMutex.TryLock() {
+		return nil, e.NewConflictError(fmt.Errorf("garbage collection is already running"), "")
+	}
+	defer uc.gcMutex.Unlock()
+
+	run := &entity.BlobGCRun{StartedAt: time.Now()}
+
+	// the files are listed before the blobs, so that a file which becomes a blob after the listing is still found
+	// among the blobs. The file of a blob is moved into place right before the blob is recorded, so it isn't stale
+	objectsDirPath := filepath.Join(uc.config.BaseStorageDir, storage.ObjectsDirName)
+	staleFilePaths, err := uc.storageRepo.ListFilesModifiedBefore(ctx, objectsDirPath, run.StartedAt.Add(-uc.config.BlobGCGracePeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	// mark
+	recountedBlobs, err := uc.blobRepo.RecountBlobRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	run.RecountedBlobs = recountedBlobs
+
+	// sweep the blobs, whose records are deleted before their files so that no chunk refers to a deleted file
+	deletedBlobs, err := uc.blobRepo.DeleteUnreferencedBlobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, blob := range deletedBlobs {
+		if err := uc.storageRepo.DeleteFile(ctx, blob.FilePath); err != nil {
+			return nil, err
+		}
+		run.DeletedBlobs++
+	}
+
+	// sweep the files no blob refers to, which are left by the crashes and the failed stores
+	blobPaths, err := uc.blobRepo.GetBlobPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	referencedPaths := make(map[string]struct{}, len(blobPaths))
+	for _, blobPath := range blobPaths {
+		referencedPaths[blobPath] = struct{}{}
+	}
+	for _, filePath := range staleFilePaths {
+		if _, ok := referencedPaths[filePath]; ok {
+			continue
+		}
+		if err := uc.storageRepo.DeleteFile(ctx, filePath); err != nil {
+			return nil, err
+		}
+		run.DeletedStrays++
+	}
+
+	run.FinishedAt = time.Now()
+
+	return run, nil
+}
@@ -0,0 +1,185 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/mock"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFileGCUseCase_Execute(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	config := &entity.Config{BaseStorageDir: "/test/uploads", BlobGCGracePeriod: time.Hour}
+	objectsDirPath := "/test/uploads/.objects"
+
+	// the blob of the deleted file is left unreferenced by a crash before its file was deleted
+	unreferencedBlob := &entity.Blob{ID: 1, FilePath: "/test/uploads/.objects/0b/0b1d"}
+	blobPaths := []string{"/test/uploads/.objects/1b/1b1d", "/test/uploads/.objects/2b/2b1d"}
+	// the stray file is left by a store which failed before the blob was recorded
+	staleFilePaths := []string{blobPaths[0], "/test/uploads/.objects/de/dead"}
+
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+	storageError := e.NewFileStorageError(errors.New("storage error"), "")
+
+	// expectListing expects the files in the object area older than the grace period to be listed
+	expectListing := func(mockStorageRepo *mock.MockFileStorageRepository, filePaths []string, err e.CustomError) {
+		mockStorageRepo.EXPECT().ListFilesModifiedBefore(ctx, objectsDirPath, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, before time.Time) ([]string, e.CustomError) {
+				assert.WithinDuration(t, time.Now().Add(-config.BlobGCGracePeriod), before, time.Minute)
+				return filePaths, err
+			})
+	}
+
+	tests := []struct {
+		name        string
+		setupMocks  func(mockBlobRepo *mock.MockBlobRepository, mockStorageRepo *mock.MockFileStorageRepository)
+		expectedRun *entity.BlobGCRun
+		expectedErr e.CustomError
+	}{
+		{
+			name: "Success - Deletes Unreferenced Blobs And Stray Files",
+			setupMocks: func(mockBlobRepo *mock.MockBlobRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				expectListing(mockStorageRepo, staleFilePaths, nil)
+				mockBlobRepo.EXPECT().RecountBlobRefs(ctx).Return(int64(2), nil)
+				mockBlobRepo.EXPECT().DeleteUnreferencedBlobs(ctx).Return([]*entity.Blob{unreferencedBlob}, nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, unreferencedBlob.FilePath).Return(nil)
+				mockBlobRepo.EXPECT().GetBlobPaths(ctx).Return(blobPaths, nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, staleFilePaths[1]).Return(nil)
+			},
+			expectedRun: &entity.BlobGCRun{RecountedBlobs: 2, DeletedBlobs: 1, DeletedStrays: 1},
+		},
+		{
+			name: "Success - Nothing To Collect",
+			setupMocks: func(mockBlobRepo *mock.MockBlobRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				expectListing(mockStorageRepo, []string{}, nil)
+				mockBlobRepo.EXPECT().RecountBlobRefs(ctx).Return(int64(0), nil)
+				mockBlobRepo.EXPECT().DeleteUnreferencedBlobs(ctx).Return(nil, nil)
+				mockBlobRepo.EXPECT().GetBlobPaths(ctx).Return(blobPaths, nil)
+			},
+			expectedRun: &entity.BlobGCRun{},
+		},
+		{
+			name: "Error - ListFilesModifiedBefore Storage Error",
+			setupMocks: func(mockBlobRepo *mock.MockBlobRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				expectListing(mockStorageRepo, nil, storageError)
+			},
+			expectedErr: storageError,
+		},
+		{
+			name: "Error - RecountBlobRefs DB Error",
+			setupMocks: func(mockBlobRepo *mock.MockBlobRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				expectListing(mockStorageRepo, staleFilePaths, nil)
+				mockBlobRepo.EXPECT().RecountBlobRefs(ctx).Return(int64(0), dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name: "Error - DeleteUnreferencedBlobs DB Error",
+			setupMocks: func(mockBlobRepo *mock.MockBlobRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				expectListing(mockStorageRepo, staleFilePaths, nil)
+				mockBlobRepo.EXPECT().RecountBlobRefs(ctx).Return(int64(0), nil)
+				mockBlobRepo.EXPECT().DeleteUnreferencedBlobs(ctx).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name: "Error - DeleteFile Storage Error",
+			setupMocks: func(mockBlobRepo *mock.MockBlobRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				expectListing(mockStorageRepo, staleFilePaths, nil)
+				mockBlobRepo.EXPECT().RecountBlobRefs(ctx).Return(int64(0), nil)
+				mockBlobRepo.EXPECT().DeleteUnreferencedBlobs(ctx).Return([]*entity.Blob{unreferencedBlob}, nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, unreferencedBlob.FilePath).Return(storageError)
+			},
+			expectedErr: storageError,
+		},
+		{
+			name: "Error - GetBlobPaths DB Error",
+			setupMocks: func(mockBlobRepo *mock.MockBlobRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				expectListing(mockStorageRepo, staleFilePaths, nil)
+				mockBlobRepo.EXPECT().RecountBlobRefs(ctx).Return(int64(0), nil)
+				mockBlobRepo.EXPECT().DeleteUnreferencedBlobs(ctx).Return(nil, nil)
+				mockBlobRepo.EXPECT().GetBlobPaths(ctx).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockBlobRepo := mock.NewMockBlobRepository(mockCtrl)
+			mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+			uc := usecase.NewFileGCUseCase(config, mockBlobRepo, mockStorageRepo)
+
+			tc.setupMocks(mockBlobRepo, mockStorageRepo)
+			run, err := uc.Execute(ctx)
+
+			if tc.expectedErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+				assert.Nil(t, run)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.False(t, run.StartedAt.IsZero())
+			assert.False(t, run.FinishedAt.Before(run.StartedAt))
+			run.StartedAt = time.Time{}
+			run.FinishedAt = time.Time{}
+			assert.Equal(t, tc.expectedRun, run)
+		})
+	}
+}
+
+func TestFileGCUseCase_Execute_AlreadyRunning(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockBlobRepo := mock.NewMockBlobRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileGCUseCase(&entity.Config{BaseStorageDir: "/test/uploads"}, mockBlobRepo, mockStorageRepo)
+
+	// the first collection blocks until the second one is rejected
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockStorageRepo.EXPECT().ListFilesModifiedBefore(gomock.Any(), "/test/uploads/.objects", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, _ time.Time) ([]string, e.CustomError) {
+			close(started)
+			<-release
+			return []string{}, nil
+		},
+	)
+	mockBlobRepo.EXPECT().RecountBlobRefs(gomock.Any()).Return(int64(0), nil)
+	mockBlobRepo.EXPECT().DeleteUnreferencedBlobs(gomock.Any()).Return(nil, nil)
+	mockBlobRepo.EXPECT().GetBlobPaths(gomock.Any()).Return([]string{}, nil)
+
+	done := make(chan e.CustomError)
+	go func() {
+		_, err := uc.Execute(context.Background())
+		done <- err
+	}()
+
+	<-started
+	_, err := uc.Execute(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, "CONFLICT", err.ErrorCode())
+	assert.Contains(t, err.Error(), "garbage collection is already running")
+
+	close(release)
+	assert.NoError(t, <-done)
+}
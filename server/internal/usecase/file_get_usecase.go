@@ -2,19 +2,29 @@ package usecase
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
 
 	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
 	"github.com/tomoya.tokunaga/server/internal/interface/repository/database"
+	"github.com/tomoya.tokunaga/server/internal/interface/repository/storage"
+	"github.com/tomoya.tokunaga/server/internal/util/merkle"
 )
 
 type fileGetUseCase struct {
-	fileRepo database.FileRepository
+	fileRepo    database.FileRepository
+	storageRepo storage.FileStorageRepository
 }
 
-func NewFileGetUseCase(fileRepo database.FileRepository) FileGetUseCase {
+func NewFileGetUseCase(fileRepo database.FileRepository, storageRepo storage.FileStorageRepository) FileGetUseCase {
 	return &fileGetUseCase{
-		fileRepo: fileRepo,
+		fileRepo:    fileRepo,
+		storageRepo: storageRepo,
 	}
 }
 
@@ -28,6 +38,17 @@ func (uc *fileGetUseCase) Execute(ctx context.Context) ([]string, e.CustomError)
 	return files, nil
 }
 
+// ExecuteGetUploadedFiles returns the files whose chunks are all uploaded, ordered by name
+func (uc *fileGetUseCase) ExecuteGetUploadedFiles(ctx context.Context) ([]*entity.File, e.CustomError) {
+	files, err := uc.fileRepo.GetFilesByStatus(ctx, entity.FileStatusUploaded)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	return files, nil
+}
+
 // ExecuteGetStats returns the stats of a file
 func (uc *fileGetUseCase) ExecuteGetStats(ctx context.Context, fileName string) (*entity.File, e.CustomError) {
 	file, err := uc.fileRepo.GetFileByName(ctx, fileName)
@@ -39,13 +60,232 @@ func (uc *fileGetUseCase) ExecuteGetStats(ctx context.Context, fileName string)
 	}
 
 	fileStats := &entity.File{
-		ID:        file.ID,
-		Name:      file.Name,
-		Size:      file.Size,
-		Checksum:  file.Checksum,
-		Status:    file.Status,
-		UpdatedAt: file.UpdatedAt,
+		ID:                file.ID,
+		Name:              file.Name,
+		Size:              file.Size,
+		Checksum:          file.Checksum,
+		ChecksumAlgorithm: file.ChecksumAlgorithm,
+		MerkleRoot:        file.MerkleRoot,
+		Status:            file.Status,
+		UpdatedAt:         file.UpdatedAt,
 	}
 
 	return fileStats, nil
 }
+
+// ExecuteGetContent returns an uploaded file together with its chunks ordered by chunk number, so that the
+// caller can prepare the response (e.g. headers) before the content is streamed by ExecuteStreamContent
+func (uc *fileGetUseCase) ExecuteGetContent(ctx context.Context, fileName string) (*entity.File, e.CustomError) {
+	file, err := uc.fileRepo.GetFileByName(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, e.NewNotFoundError(fmt.Errorf("%s not found", fileName), "")
+	}
+	if file.Status != entity.FileStatusUploaded {
+		// only the files whose chunks are all uploaded can be read
+		return nil, e.NewNotFoundError(fmt.Errorf("%s is in %s status and cannot be read", fileName, file.Status), "")
+	}
+
+	chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
+	if err != nil {
+		return nil, err
+	}
+	if uint(len(chunks)) != file.TotalChunks {
+		return nil, e.NewDatabaseError(
+			fmt.Errorf("expected %d chunks, but found %d", file.TotalChunks, len(chunks)),
+			fmt.Sprintf("chunk records of %s are inconsistent", fileName),
+		)
+	}
+
+	file.FileChunks = make([]entity.FileChunk, len(chunks))
+	for i, chunk := range chunks {
+		file.FileChunks[i] = *chunk
+	}
+
+	return file, nil
+}
+
+// ExecuteStreamContent writes length bytes of the file content starting at offset to the writer. Since the chunks are
+// ordered by their offsets, the chunks covering the range are located without reading the others
+func (uc *fileGetUseCase) ExecuteStreamContent(ctx context.Context, file *entity.File, writer io.Writer, offset uint64, length uint64) e.CustomError {
+	if length == 0 {
+		return nil
+	}
+	end := offset + length // exclusive
+	if end < offset || end > file.Size {
+		return e.NewInvalidInputError(
+			fmt.Errorf("range %d-%d exceeds the file size %d", offset, end-1, file.Size),
+			fmt.Sprintf("invalid range for %s", file.Name),
+		)
+	}
+	if file.ChunkSize == 0 {
+		return e.NewDatabaseError(errors.New("chunk size is 0"), fmt.Sprintf("chunk records of %s are inconsistent", file.Name))
+	}
+
+	first := sort.Search(len(file.FileChunks), func(i int) bool {
+		chunkStart, chunkSize := file.ChunkRange(&file.FileChunks[i])
+		return chunkStart+chunkSize > offset
+	})
+	for _, chunk := range file.FileChunks[first:] {
+		chunkStart, chunkSize := file.ChunkRange(&chunk)
+		if chunkStart >= end {
+			break
+		}
+
+		// position of the range inside the chunk
+		readFrom := max(offset, chunkStart) - chunkStart
+		readTo := min(end, chunkStart+chunkSize) - chunkStart
+
+		if err := uc.storageRepo.ReadChunk(ctx, writer, chunk.FilePath, file.StorageCodec, readFrom, readTo-readFrom); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExecuteGetManifest returns the list of the chunks of a file with their positions in the file, so that the chunks
+// can be fetched one by one. The hash of an uploaded chunk without the recorded checksum is calculated from the
+// stored content
+func (uc *fileGetUseCase) ExecuteGetManifest(ctx context.Context, fileName string) (*entity.FileManifest, e.CustomError) {
+	file, err := uc.fileRepo.GetFileByName(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, e.NewNotFoundError(fmt.Errorf("%s not found", fileName), "")
+	}
+
+	chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
+	if err != nil {
+		return nil, err
+	}
+	if uint(len(chunks)) != file.TotalChunks {
+		return nil, e.NewDatabaseError(
+			fmt.Errorf("expected %d chunks, but found %d", file.TotalChunks, len(chunks)),
+			fmt.Sprintf("chunk records of %s are inconsistent", fileName),
+		)
+	}
+
+	manifest := &entity.FileManifest{
+		FileID:            file.ID,
+		Name:              file.Name,
+		Size:              file.Size,
+		Checksum:          file.Checksum,
+		ChecksumAlgorithm: file.ChecksumAlgorithm,
+		MerkleRoot:        file.MerkleRoot,
+		ChunkSize:         file.ChunkSize,
+		Status:            file.Status,
+		TotalChunks:       file.TotalChunks,
+		Chunks:            make([]entity.FileManifestChunk, len(chunks)),
+	}
+	for i, chunk := range chunks {
+		offset, size := file.ChunkRange(chunk)
+		manifestChunk := entity.FileManifestChunk{
+			ChunkNumber: chunk.ChunkNumber,
+			Offset:      offset,
+			Size:        size,
+			Status:      chunk.Status,
+		}
+
+		// only the uploaded chunks have the complete content to be hashed. the checksum recorded at the upload is used
+		// when it's there
+		manifestChunk.Checksum = chunk.Checksum
+		if chunk.Status == entity.FileStatusUploaded && chunk.Checksum == "" {
+			hash := sha256.New()
+			if err := uc.storageRepo.ReadChunk(ctx, hash, chunk.FilePath, file.StorageCodec, 0, size); err != nil {
+				return nil, err
+			}
+			manifestChunk.Checksum = hex.EncodeToString(hash.Sum(nil))
+		}
+
+		manifest.Chunks[i] = manifestChunk
+	}
+
+	return manifest, nil
+}
+
+// ExecuteGetChunk returns an uploaded chunk of an uploaded file whose size is set to the size of its content
+func (uc *fileGetUseCase) ExecuteGetChunk(ctx context.Context, fileID uint64, chunkNumber uint64) (*entity.File, *entity.FileChunk, e.CustomError) {
+	file, chunk, err := uc.fileRepo.GetFileAndChunk(ctx, fileID, chunkNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	if file.Status != entity.FileStatusUploaded {
+		// the chunks of a file being uploaded, deleted or found damaged aren't served, as well as its content
+		return nil, nil, e.NewNotFoundError(fmt.Errorf("%s is in %s status and cannot be read", file.Name, file.Status), "")
+	}
+	if chunk.Status != entity.FileStatusUploaded {
+		return nil, nil, e.NewNotFoundError(
+			fmt.Errorf("chunk %d of %s is in %s status and cannot be read", chunkNumber, file.Name, chunk.Status), "",
+		)
+	}
+
+	_, chunk.Size = file.ChunkRange(chunk)
+
+	return file, chunk, nil
+}
+
+// ExecuteGetChunkProof returns the inclusion proof of the chunk in the Merkle tree of the file, which lets the client
+// verify the chunk against the Merkle root of the file. It returns nil when the file has no Merkle root, i.e. the file
+// was uploaded before the Merkle root was introduced
+func (uc *fileGetUseCase) ExecuteGetChunkProof(ctx context.Context, file *entity.File, chunk *entity.FileChunk) (*entity.MerkleProof, e.CustomError) {
+	if file.MerkleRoot == "" {
+		return nil, nil
+	}
+
+	chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
+	if err != nil {
+		return nil, err
+	}
+	if uint(len(chunks)) != file.TotalChunks {
+		return nil, e.NewDatabaseError(
+			fmt.Errorf("expected %d chunks, but found %d", file.TotalChunks, len(chunks)),
+			fmt.Sprintf("chunk records of %s are inconsistent", file.Name),
+		)
+	}
+
+	chunkDigests := make([][]byte, len(chunks))
+	for i, c := range chunks {
+		digest, decodeErr := hex.DecodeString(c.Checksum)
+		if decodeErr != nil || len(digest) != sha256.Size {
+			return nil, e.NewDatabaseError(
+				fmt.Errorf("chunk %d has invalid checksum %q", c.ChunkNumber, c.Checksum),
+				fmt.Sprintf("chunk records of %s are inconsistent", file.Name),
+			)
+		}
+		chunkDigests[i] = digest
+	}
+
+	// the chunks can be re-uploaded after the file is completed (e.g. repaired by fsck), so the proof is served only
+	// when the chunks still make up the recorded root
+	if root := hex.EncodeToString(merkle.Root(chunkDigests)); root != file.MerkleRoot {
+		return nil, e.NewDatabaseError(
+			fmt.Errorf("merkle root %s is recorded, but the chunks have %s", file.MerkleRoot, root),
+			fmt.Sprintf("chunk records of %s are inconsistent", file.Name),
+		)
+	}
+
+	proof, proofErr := merkle.Proof(chunkDigests, int(chunk.ChunkNumber))
+	if proofErr != nil {
+		return nil, e.NewDatabaseError(proofErr, fmt.Sprintf("chunk records of %s are inconsistent", file.Name))
+	}
+	hashes := make([]string, len(proof))
+	for i, hash := range proof {
+		hashes[i] = hex.EncodeToString(hash)
+	}
+
+	return &entity.MerkleProof{
+		Root:      file.MerkleRoot,
+		LeafIndex: chunk.ChunkNumber,
+		TreeSize:  uint64(len(chunks)),
+		Hashes:    hashes,
+	}, nil
+}
+
+// ExecuteStreamChunk writes the content of the chunk of the file to the writer
+func (uc *fileGetUseCase) ExecuteStreamChunk(ctx context.Context, file *entity.File, chunk *entity.FileChunk, writer io.Writer) e.CustomError {
+	return uc.storageRepo.ReadChunk(ctx, writer, chunk.FilePath, file.StorageCodec, 0, chunk.Size)
+}
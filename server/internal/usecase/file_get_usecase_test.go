@@ -1,8 +1,13 @@
 package usecase_test
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
 	"testing"
 	"time"
 
@@ -11,6 +16,7 @@ import (
 	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
 	"github.com/tomoya.tokunaga/server/internal/mock"
 	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"github.com/tomoya.tokunaga/server/internal/util/merkle"
 	"go.uber.org/mock/gomock"
 )
 
@@ -21,7 +27,8 @@ func TestFileGetUseCase_Execute(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
-	uc := usecase.NewFileGetUseCase(mockFileRepo)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileGetUseCase(mockFileRepo, mockStorageRepo)
 
 	ctx := context.Background()
 	genericDBError := e.NewDatabaseError(errors.New("db error"), "Failed to get file names")
@@ -68,6 +75,63 @@ func TestFileGetUseCase_Execute(t *testing.T) {
 	}
 }
 
+func TestFileGetUseCase_ExecuteGetUploadedFiles(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileGetUseCase(mockFileRepo, mockStorageRepo)
+
+	ctx := context.Background()
+	genericDBError := e.NewDatabaseError(errors.New("db error"), "Failed to get files by status")
+
+	tests := []struct {
+		name          string
+		setupMocks    func()
+		expectedNames []string
+		expectedErr   e.CustomError
+	}{
+		{
+			name: "Success - Ordered by name",
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusUploaded).Return([]*entity.File{
+					{ID: 1, Name: "b.txt"}, {ID: 2, Name: "a.txt"}, {ID: 3, Name: "B.txt"},
+				}, nil)
+			},
+			expectedNames: []string{"B.txt", "a.txt", "b.txt"},
+		},
+		{
+			name: "Database Error",
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusUploaded).Return(nil, genericDBError)
+			},
+			expectedErr: genericDBError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			files, err := uc.ExecuteGetUploadedFiles(ctx)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				names := make([]string, len(files))
+				for i, file := range files {
+					names[i] = file.Name
+				}
+				assert.Equal(t, tc.expectedNames, names)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+			}
+		})
+	}
+}
+
 func TestFileGetUseCase_ExecuteGetStats(t *testing.T) {
 	t.Parallel()
 
@@ -75,7 +139,8 @@ func TestFileGetUseCase_ExecuteGetStats(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
-	uc := usecase.NewFileGetUseCase(mockFileRepo)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileGetUseCase(mockFileRepo, mockStorageRepo)
 
 	ctx := context.Background()
 	testFileName := "test.txt"
@@ -152,3 +217,591 @@ func TestFileGetUseCase_ExecuteGetStats(t *testing.T) {
 		})
 	}
 }
+
+func TestFileGetUseCase_ExecuteGetContent(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileGetUseCase(mockFileRepo, mockStorageRepo)
+
+	ctx := context.Background()
+	genericDBError := e.NewDatabaseError(errors.New("db error"), "Failed to get file chunks")
+	uploadedFile := &entity.File{
+		ID:          1,
+		Name:        "uploaded.txt",
+		Size:        2048,
+		ChunkSize:   1024,
+		TotalChunks: 2,
+		Status:      entity.FileStatusUploaded,
+	}
+	inProgressFile := &entity.File{
+		ID:          2,
+		Name:        "in_progress.txt",
+		Size:        2048,
+		ChunkSize:   1024,
+		TotalChunks: 2,
+		Status:      entity.FileStatusInProgress,
+	}
+	chunks := []*entity.FileChunk{
+		{ID: 10, ParentID: uploadedFile.ID, ChunkNumber: 0, FilePath: "/test/uploaded.txt/0", Status: entity.FileStatusUploaded},
+		{ID: 11, ParentID: uploadedFile.ID, ChunkNumber: 1, FilePath: "/test/uploaded.txt/1", Status: entity.FileStatusUploaded},
+	}
+
+	tests := []struct {
+		name           string
+		fileName       string
+		setupMocks     func()
+		expectedChunks []entity.FileChunk
+		expectedErr    e.CustomError
+	}{
+		{
+			name:     "Success",
+			fileName: uploadedFile.Name,
+			setupMocks: func() {
+				file := *uploadedFile
+				mockFileRepo.EXPECT().GetFileByName(ctx, uploadedFile.Name).Return(&file, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, uploadedFile.ID).Return(chunks, nil)
+			},
+			expectedChunks: []entity.FileChunk{*chunks[0], *chunks[1]},
+			expectedErr:    nil,
+		},
+		{
+			name:     "Error - File Not Found",
+			fileName: "notfound.txt",
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetFileByName(ctx, "notfound.txt").Return(nil, nil)
+			},
+			expectedErr: e.NewNotFoundError(fmt.Errorf("notfound.txt not found"), ""),
+		},
+		{
+			name:     "Error - File Not Uploaded Yet",
+			fileName: inProgressFile.Name,
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetFileByName(ctx, inProgressFile.Name).Return(inProgressFile, nil)
+			},
+			expectedErr: e.NewNotFoundError(fmt.Errorf("%s is in %s status and cannot be read", inProgressFile.Name, inProgressFile.Status), ""),
+		},
+		{
+			name:     "Error - Chunk Records Missing",
+			fileName: uploadedFile.Name,
+			setupMocks: func() {
+				file := *uploadedFile
+				mockFileRepo.EXPECT().GetFileByName(ctx, uploadedFile.Name).Return(&file, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, uploadedFile.ID).Return(chunks[:1], nil)
+			},
+			expectedErr: e.NewDatabaseError(fmt.Errorf("expected 2 chunks, but found 1"), ""),
+		},
+		{
+			name:     "Error - Database Error",
+			fileName: uploadedFile.Name,
+			setupMocks: func() {
+				file := *uploadedFile
+				mockFileRepo.EXPECT().GetFileByName(ctx, uploadedFile.Name).Return(&file, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, uploadedFile.ID).Return(nil, genericDBError)
+			},
+			expectedErr: genericDBError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			file, err := uc.ExecuteGetContent(ctx, tc.fileName)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedChunks, file.FileChunks)
+			} else {
+				assert.Nil(t, file)
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestFileGetUseCase_ExecuteStreamContent(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileGetUseCase(mockFileRepo, mockStorageRepo)
+
+	ctx := context.Background()
+	storageError := e.NewFileStorageError(errors.New("storage error"), "")
+	// "hello world" stored in chunks of 4 bytes: "hell", "o wo", "rld"
+	chunkContents := []string{"hell", "o wo", "rld"}
+	file := &entity.File{
+		ID:           1,
+		Name:         "uploaded.txt",
+		Size:         11,
+		ChunkSize:    4,
+		StorageCodec: entity.StorageCodecZstd, // the chunks are read in the codec of the file
+		TotalChunks:  3,
+		FileChunks: []entity.FileChunk{
+			{ID: 10, ChunkNumber: 0, FilePath: "/test/uploaded.txt/0"},
+			{ID: 11, ChunkNumber: 1, FilePath: "/test/uploaded.txt/1"},
+			{ID: 12, ChunkNumber: 2, FilePath: "/test/uploaded.txt/2"},
+		},
+	}
+	readChunk := func(chunkNumber int) func(context.Context, io.Writer, string, entity.StorageCodec, uint64, uint64) e.CustomError {
+		return func(_ context.Context, w io.Writer, _ string, _ entity.StorageCodec, offset uint64, length uint64) e.CustomError {
+			_, _ = w.Write([]byte(chunkContents[chunkNumber][offset : offset+length]))
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name            string
+		offset          uint64
+		length          uint64
+		setupMocks      func()
+		expectedContent string
+		expectedErr     e.CustomError
+	}{
+		{
+			name:   "Success - Whole file is written in chunk order",
+			offset: 0,
+			length: 11,
+			setupMocks: func() {
+				gomock.InOrder(
+					mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), file.FileChunks[0].FilePath, file.StorageCodec, uint64(0), uint64(4)).DoAndReturn(readChunk(0)),
+					mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), file.FileChunks[1].FilePath, file.StorageCodec, uint64(0), uint64(4)).DoAndReturn(readChunk(1)),
+					mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), file.FileChunks[2].FilePath, file.StorageCodec, uint64(0), uint64(3)).DoAndReturn(readChunk(2)),
+				)
+			},
+			expectedContent: "hello world",
+			expectedErr:     nil,
+		},
+		{
+			name:   "Success - Range inside a single chunk",
+			offset: 5,
+			length: 2,
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), file.FileChunks[1].FilePath, file.StorageCodec, uint64(1), uint64(2)).DoAndReturn(readChunk(1))
+			},
+			expectedContent: " w",
+			expectedErr:     nil,
+		},
+		{
+			name:   "Success - Range across chunk boundaries",
+			offset: 3,
+			length: 7,
+			setupMocks: func() {
+				gomock.InOrder(
+					mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), file.FileChunks[0].FilePath, file.StorageCodec, uint64(3), uint64(1)).DoAndReturn(readChunk(0)),
+					mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), file.FileChunks[1].FilePath, file.StorageCodec, uint64(0), uint64(4)).DoAndReturn(readChunk(1)),
+					mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), file.FileChunks[2].FilePath, file.StorageCodec, uint64(0), uint64(2)).DoAndReturn(readChunk(2)),
+				)
+			},
+			expectedContent: "lo worl",
+			expectedErr:     nil,
+		},
+		{
+			name:            "Success - Empty range reads nothing",
+			offset:          4,
+			length:          0,
+			setupMocks:      func() {},
+			expectedContent: "",
+			expectedErr:     nil,
+		},
+		{
+			name:            "Error - Range beyond the file size",
+			offset:          8,
+			length:          4,
+			setupMocks:      func() {},
+			expectedContent: "",
+			expectedErr:     e.NewInvalidInputError(errors.New("range 8-11 exceeds the file size 11"), ""),
+		},
+		{
+			name:   "Error - Storage Error stops streaming",
+			offset: 0,
+			length: 11,
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), file.FileChunks[0].FilePath, file.StorageCodec, uint64(0), uint64(4)).Return(storageError)
+			},
+			expectedContent: "",
+			expectedErr:     storageError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			var buf bytes.Buffer
+			err := uc.ExecuteStreamContent(ctx, file, &buf, tc.offset, tc.length)
+
+			assert.Equal(t, tc.expectedContent, buf.String())
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+			}
+		})
+	}
+}
+
+func TestFileGetUseCase_ExecuteStreamContent_VariableChunks(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileGetUseCase(mockFileRepo, mockStorageRepo)
+
+	ctx := context.Background()
+	// "hello world" stored in content-defined chunks: "he", "llo w", "orld"
+	file := &entity.File{
+		ID:             1,
+		Name:           "uploaded.txt",
+		Size:           11,
+		ChunkSize:      8,
+		VariableChunks: true,
+		StorageCodec:   entity.StorageCodecNone,
+		TotalChunks:    3,
+		FileChunks: []entity.FileChunk{
+			{ID: 10, ChunkNumber: 0, Offset: 0, Size: 2, FilePath: "/test/uploaded.txt/0"},
+			{ID: 11, ChunkNumber: 1, Offset: 2, Size: 5, FilePath: "/test/uploaded.txt/1"},
+			{ID: 12, ChunkNumber: 2, Offset: 7, Size: 4, FilePath: "/test/uploaded.txt/2"},
+		},
+	}
+
+	// the range "lo wor" starts in the second chunk, which is located by the recorded offsets
+	gomock.InOrder(
+		mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), file.FileChunks[1].FilePath, file.StorageCodec, uint64(1), uint64(4)).Return(nil),
+		mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), file.FileChunks[2].FilePath, file.StorageCodec, uint64(0), uint64(2)).Return(nil),
+	)
+
+	var buf bytes.Buffer
+	assert.NoError(t, uc.ExecuteStreamContent(ctx, file, &buf, 3, 6))
+}
+
+func TestFileGetUseCase_ExecuteGetManifest(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileGetUseCase(mockFileRepo, mockStorageRepo)
+
+	ctx := context.Background()
+	genericStorageError := e.NewFileStorageError(errors.New("storage error"), "failed to open file")
+	file := &entity.File{
+		ID:          1,
+		Name:        "test.txt",
+		Size:        10,
+		Checksum:    "checksum",
+		ChunkSize:   6,
+		TotalChunks: 2,
+		Status:      entity.FileStatusInProgress,
+	}
+	chunks := []*entity.FileChunk{
+		{ID: 10, ParentID: file.ID, ChunkNumber: 0, FilePath: "/test/test.txt/0", Status: entity.FileStatusUploaded},
+		{ID: 11, ParentID: file.ID, ChunkNumber: 1, FilePath: "/test/test.txt/1", Status: entity.FileStatusInProgress},
+	}
+
+	tests := []struct {
+		name             string
+		fileName         string
+		setupMocks       func()
+		expectedManifest *entity.FileManifest
+		expectedErr      e.CustomError
+	}{
+		{
+			name:     "Success - Only uploaded chunks are hashed",
+			fileName: file.Name,
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetFileByName(ctx, file.Name).Return(file, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, file.ID).Return(chunks, nil)
+				mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), "/test/test.txt/0", gomock.Any(), uint64(0), uint64(6)).
+					DoAndReturn(func(_ context.Context, w io.Writer, _ string, _ entity.StorageCodec, _ uint64, _ uint64) e.CustomError {
+						_, _ = w.Write([]byte("hello "))
+						return nil
+					})
+			},
+			expectedManifest: &entity.FileManifest{
+				FileID:      file.ID,
+				Name:        file.Name,
+				Size:        file.Size,
+				Checksum:    file.Checksum,
+				ChunkSize:   file.ChunkSize,
+				Status:      file.Status,
+				TotalChunks: file.TotalChunks,
+				Chunks: []entity.FileManifestChunk{
+					{
+						ChunkNumber: 0,
+						Offset:      0,
+						Size:        6,
+						Checksum:    "5e3235a8346e5a4585f8c58562f5052b8fe26a3bb122e1e96c76784964dfc461",
+						Status:      entity.FileStatusUploaded,
+					},
+					{ChunkNumber: 1, Offset: 6, Size: 4, Status: entity.FileStatusInProgress},
+				},
+			},
+		},
+		{
+			name:     "Success - Recorded checksums are used",
+			fileName: file.Name,
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetFileByName(ctx, file.Name).Return(file, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, file.ID).Return([]*entity.FileChunk{
+					{ID: 10, ParentID: file.ID, ChunkNumber: 0, Checksum: "hash0", FilePath: "/test/test.txt/0", Status: entity.FileStatusUploaded},
+					{ID: 11, ParentID: file.ID, ChunkNumber: 1, Checksum: "hash1", FilePath: "/test/test.txt/1", Status: entity.FileStatusUploaded},
+				}, nil)
+			},
+			expectedManifest: &entity.FileManifest{
+				FileID:      file.ID,
+				Name:        file.Name,
+				Size:        file.Size,
+				Checksum:    file.Checksum,
+				ChunkSize:   file.ChunkSize,
+				Status:      file.Status,
+				TotalChunks: file.TotalChunks,
+				Chunks: []entity.FileManifestChunk{
+					{ChunkNumber: 0, Offset: 0, Size: 6, Checksum: "hash0", Status: entity.FileStatusUploaded},
+					{ChunkNumber: 1, Offset: 6, Size: 4, Checksum: "hash1", Status: entity.FileStatusUploaded},
+				},
+			},
+		},
+		{
+			name:     "Error - File Not Found",
+			fileName: "notfound.txt",
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetFileByName(ctx, "notfound.txt").Return(nil, nil)
+			},
+			expectedErr: e.NewNotFoundError(fmt.Errorf("notfound.txt not found"), ""),
+		},
+		{
+			name:     "Error - Chunk Records Missing",
+			fileName: file.Name,
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetFileByName(ctx, file.Name).Return(file, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, file.ID).Return(chunks[:1], nil)
+			},
+			expectedErr: e.NewDatabaseError(fmt.Errorf("expected 2 chunks, but found 1"), ""),
+		},
+		{
+			name:     "Error - Storage Error",
+			fileName: file.Name,
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetFileByName(ctx, file.Name).Return(file, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, file.ID).Return(chunks, nil)
+				mockStorageRepo.EXPECT().ReadChunk(ctx, gomock.Any(), "/test/test.txt/0", gomock.Any(), uint64(0), uint64(6)).Return(genericStorageError)
+			},
+			expectedErr: genericStorageError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			manifest, err := uc.ExecuteGetManifest(ctx, tc.fileName)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedManifest, manifest)
+			} else {
+				assert.Nil(t, manifest)
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestFileGetUseCase_ExecuteGetChunk(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileGetUseCase(mockFileRepo, mockStorageRepo)
+
+	ctx := context.Background()
+	notFoundError := e.NewNotFoundError(errors.New("record not found"), "chunk number 5 for file ID 1 not found")
+	file := &entity.File{ID: 1, Name: "test.txt", Size: 10, ChunkSize: 6, TotalChunks: 2, Status: entity.FileStatusUploaded}
+
+	tests := []struct {
+		name          string
+		chunkNumber   uint64
+		setupMocks    func()
+		expectedChunk *entity.FileChunk
+		expectedErr   e.CustomError
+	}{
+		{
+			name:        "Success - Last chunk has the rest of the file",
+			chunkNumber: 1,
+			setupMocks: func() {
+				chunk := &entity.FileChunk{ID: 11, ParentID: file.ID, ChunkNumber: 1, FilePath: "/test/test.txt/1", Status: entity.FileStatusUploaded}
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, file.ID, uint64(1)).Return(file, chunk, nil)
+			},
+			expectedChunk: &entity.FileChunk{ID: 11, ParentID: file.ID, ChunkNumber: 1, Size: 4, FilePath: "/test/test.txt/1", Status: entity.FileStatusUploaded},
+		},
+		{
+			name:        "Error - Chunk Not Uploaded Yet",
+			chunkNumber: 0,
+			setupMocks: func() {
+				chunk := &entity.FileChunk{ID: 10, ParentID: file.ID, ChunkNumber: 0, FilePath: "/test/test.txt/0", Status: entity.FileStatusInProgress}
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, file.ID, uint64(0)).Return(file, chunk, nil)
+			},
+			expectedErr: e.NewNotFoundError(fmt.Errorf("chunk 0 of test.txt is in IN_PROGRESS status and cannot be read"), ""),
+		},
+		{
+			name:        "Error - File Not Uploaded",
+			chunkNumber: 0,
+			setupMocks: func() {
+				inProgressFile := &entity.File{ID: 1, Name: "test.txt", Size: 10, ChunkSize: 6, TotalChunks: 2, Status: entity.FileStatusInProgress}
+				chunk := &entity.FileChunk{ID: 10, ParentID: file.ID, ChunkNumber: 0, FilePath: "/test/test.txt/0", Status: entity.FileStatusUploaded}
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, file.ID, uint64(0)).Return(inProgressFile, chunk, nil)
+			},
+			expectedErr: e.NewNotFoundError(fmt.Errorf("test.txt is in IN_PROGRESS status and cannot be read"), ""),
+		},
+		{
+			name:        "Error - Chunk Not Found",
+			chunkNumber: 5,
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, file.ID, uint64(5)).Return(nil, nil, notFoundError)
+			},
+			expectedErr: notFoundError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			gotFile, chunk, err := uc.ExecuteGetChunk(ctx, file.ID, tc.chunkNumber)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, file, gotFile)
+				assert.Equal(t, tc.expectedChunk, chunk)
+			} else {
+				assert.Nil(t, chunk)
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestFileGetUseCase_ExecuteGetChunkProof(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileGetUseCase(mockFileRepo, mockStorageRepo)
+
+	ctx := context.Background()
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+
+	// "hello world" split into "hello " and "world"
+	helloDigest := sha256.Sum256([]byte("hello "))
+	worldDigest := sha256.Sum256([]byte("world"))
+	chunks := []*entity.FileChunk{
+		{ID: 10, ParentID: 1, ChunkNumber: 0, Checksum: hex.EncodeToString(helloDigest[:]), Status: entity.FileStatusUploaded},
+		{ID: 11, ParentID: 1, ChunkNumber: 1, Checksum: hex.EncodeToString(worldDigest[:]), Status: entity.FileStatusUploaded},
+	}
+	merkleRoot := "1611ac016713113b29ef4acfa37566046c257ee45139b112939f43a4c20ca8a2"
+	file := &entity.File{ID: 1, Name: "test.txt", Size: 11, ChunkSize: 6, TotalChunks: 2, MerkleRoot: merkleRoot, Status: entity.FileStatusUploaded}
+
+	tests := []struct {
+		name          string
+		file          *entity.File
+		chunk         *entity.FileChunk
+		setupMocks    func()
+		expectedProof *entity.MerkleProof
+		expectedErr   e.CustomError
+	}{
+		{
+			name:  "Success",
+			file:  file,
+			chunk: chunks[1],
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, file.ID).Return(chunks, nil)
+			},
+			expectedProof: &entity.MerkleProof{
+				Root:      merkleRoot,
+				LeafIndex: 1,
+				TreeSize:  2,
+				Hashes:    []string{"0e5aa1d9486fc844f74907edb32f9a119333d0780cd414d6cda1190994c8031e"},
+			},
+		},
+		{
+			name:       "Success - File Without Merkle Root",
+			file:       &entity.File{ID: 1, Name: "test.txt", TotalChunks: 2, Status: entity.FileStatusUploaded},
+			chunk:      chunks[1],
+			setupMocks: func() {},
+		},
+		{
+			name:  "Error - GetChunksByFileID DB Error",
+			file:  file,
+			chunk: chunks[1],
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, file.ID).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name:  "Error - Chunk Without Checksum",
+			file:  file,
+			chunk: chunks[1],
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, file.ID).Return([]*entity.FileChunk{{ChunkNumber: 0}, chunks[1]}, nil)
+			},
+			expectedErr: e.NewDatabaseError(errors.New(""), "chunk records of test.txt are inconsistent"),
+		},
+		{
+			name:  "Error - Chunks Changed After Completion",
+			file:  file,
+			chunk: chunks[1],
+			setupMocks: func() {
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, file.ID).Return([]*entity.FileChunk{chunks[1], chunks[0]}, nil)
+			},
+			expectedErr: e.NewDatabaseError(errors.New(""), "chunk records of test.txt are inconsistent"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			proof, err := uc.ExecuteGetChunkProof(ctx, tc.file, tc.chunk)
+
+			if tc.expectedErr != nil {
+				assert.Nil(t, proof)
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedProof, proof)
+			if proof != nil {
+				// the proof verifies the chunk against the root
+				root, _ := hex.DecodeString(proof.Root)
+				sibling, _ := hex.DecodeString(proof.Hashes[0])
+				assert.True(t, merkle.Verify(root, worldDigest[:], proof.LeafIndex, proof.TreeSize, [][]byte{sibling}))
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/interface/repository/database"
+	"github.com/tomoya.tokunaga/server/internal/interface/repository/storage"
+	"github.com/tomoya.tokunaga/server/internal/util/concurrency"
+)
+
+type fileScrubUseCase struct {
+	config      *entity.Config
+	fileRepo    database.FileRepository
+	storageRepo storage.FileStorageRepository
+
+	scrubMutex   sync.Mutex // allows only one scrub at a time
+	lastRunMutex sync.RWMutex
+	lastRun      *entity.ScrubRun
+}
+
+func NewFileScrubUseCase(config *entity.Config, fileRepo database.FileRepository, storageRepo storage.FileStorageRepository) FileScrubUseCase {
+	return &fileScrubUseCase{
+		config:      config,
+		fileRepo:    fileRepo,
+		storageRepo: storageRepo,
+	}
+}
+
+// Execute re-verifies the stored chunks of all the uploaded files, and marks the files and the chunks whose content is
+// missing or doesn't match the recorded checksum as DAMAGED
+func (uc *fileScrubUseCase) Execute(ctx context.Context) (*entity.ScrubRun, e.CustomError) {
+	if !uc.scrubMutex.TryLock() {
+		return nil, e.NewConflictError(fmt.Errorf("scrub is already running"), "")
+	}
+	defer uc.scrubMutex.Unlock()
+
+	run := &entity.ScrubRun{StartedAt: time.Now()}
+
+	files, err := uc.fileRepo.GetFilesByStatus(ctx, entity.FileStatusUploaded)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return nil, e.NewContextError(ctx.Err(), "scrub canceled")
+		}
+
+		chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
+		if err != nil {
+			return nil, err
+		}
+		damagedChunkIDs, err := uc.scrubChunks(ctx, file, chunks)
+		if err != nil {
+			return nil, err
+		}
+		run.ScannedFiles++
+		run.ScannedChunks += uint64(len(chunks))
+
+		if len(damagedChunkIDs) > 0 {
+			if err := uc.fileRepo.UpdateFileAndChunkStatus(ctx, file.ID, damagedChunkIDs, entity.FileStatusDamaged); err != nil {
+				return nil, err
+			}
+			run.DamagedFiles++
+		}
+	}
+
+	run.FinishedAt = time.Now()
+
+	uc.lastRunMutex.Lock()
+	uc.lastRun = run
+	uc.lastRunMutex.Unlock()
+
+	return run, nil
+}
+
+// ExecuteGetReport returns the result of the last scrub and all the files marked as DAMAGED so far
+func (uc *fileScrubUseCase) ExecuteGetReport(ctx context.Context) (*entity.ScrubReport, e.CustomError) {
+	files, err := uc.fileRepo.GetFilesByStatus(ctx, entity.FileStatusDamaged)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.ScrubReport{
+		DamagedFiles: make([]entity.ScrubDamagedFile, len(files)),
+	}
+	for i, file := range files {
+		chunks, err := uc.fileRepo.GetChunksByStatus(ctx, file.ID, []entity.FileStatus{entity.FileStatusDamaged})
+		if err != nil {
+			return nil, err
+		}
+
+		chunkNumbers := make([]uint64, len(chunks))
+		for j, chunk := range chunks {
+			chunkNumbers[j] = chunk.ChunkNumber
+		}
+		slices.Sort(chunkNumbers)
+
+		report.DamagedFiles[i] = entity.ScrubDamagedFile{
+			FileID:       file.ID,
+			Name:         file.Name,
+			ChunkNumbers: chunkNumbers,
+		}
+	}
+
+	uc.lastRunMutex.RLock()
+	report.LastRun = uc.lastRun
+	uc.lastRunMutex.RUnlock()
+
+	return report, nil
+}
+
+// scrubChunks verifies the chunks of the file in parallel and returns the IDs of the damaged ones
+func (uc *fileScrubUseCase) scrubChunks(ctx context.Context, file *entity.File, chunks []*entity.FileChunk) ([]uint64, e.CustomError) {
+	var mutex sync.Mutex
+	damagedChunkIDs := []uint64{}
+
+	// the workers outlive the cancellation so that the submission never blocks, and the tasks stop by themselves
+	wp := concurrency.NewWorkerPool(uc.config.WorkerPoolSize)
+	wp.Start(context.WithoutCancel(ctx))
+
+	for _, chunk := range chunks {
+		if ctx.Err() != nil {
+			break
+		}
+		wp.Submit(func() error {
+			isDamaged, err := uc.isChunkDamaged(ctx, file, chunk)
+			if err != nil {
+				return err.ErrorObject()
+			}
+			if isDamaged {
+				mutex.Lock()
+				damagedChunkIDs = append(damagedChunkIDs, chunk.ID)
+				mutex.Unlock()
+			}
+			return nil
+		})
+	}
+	wp.Wait()
+
+	if ctx.Err() != nil {
+		return nil, e.NewContextError(ctx.Err(), "scrub canceled")
+	}
+	if err, ok := <-wp.Errors(); ok {
+		return nil, e.NewFileStorageError(err, fmt.Sprintf("failed to scrub %s", file.Name))
+	}
+
+	return damagedChunkIDs, nil
+}
+
+// isChunkDamaged reports whether the stored chunk is missing, shorter than its size in the file, or has different
+// content from the recorded checksum. Chunks uploaded before their checksums were recorded are only checked for size.
+func (uc *fileScrubUseCase) isChunkDamaged(ctx context.Context, file *entity.File, chunk *entity.FileChunk) (bool, e.CustomError) {
+	exists, err := uc.storageRepo.FileExists(ctx, chunk.FilePath)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return true, nil
+	}
+
+	hash := sha256.New()
+	_, size := file.ChunkRange(chunk)
+	if err := uc.storageRepo.ReadChunk(ctx, hash, chunk.FilePath, file.StorageCodec, 0, size); err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// the chunk which can't be read back is as good as lost
+		return true, nil
+	}
+
+	return chunk.Checksum != "" && hex.EncodeToString(hash.Sum(nil)) != chunk.Checksum, nil
+}
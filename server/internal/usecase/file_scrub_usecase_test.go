@@ -0,0 +1,274 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tomoya.tokunaga/server/internal/domain/entity"
+	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
+	"github.com/tomoya.tokunaga/server/internal/mock"
+	"github.com/tomoya.tokunaga/server/internal/usecase"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFileScrubUseCase_Execute(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	config := &entity.Config{WorkerPoolSize: 2}
+
+	// "hello world" split into "hello " and "world"
+	healthyFile := &entity.File{ID: 1, Name: "healthy.txt", Size: 11, ChunkSize: 6, TotalChunks: 2, Status: entity.FileStatusUploaded}
+	healthyChunks := []*entity.FileChunk{
+		{ID: 10, ParentID: 1, ChunkNumber: 0, FilePath: "/test/uploads/healthy.txt/0", Status: entity.FileStatusUploaded, Checksum: "5e3235a8346e5a4585f8c58562f5052b8fe26a3bb122e1e96c76784964dfc461"},
+		{ID: 11, ParentID: 1, ChunkNumber: 1, FilePath: "/test/uploads/healthy.txt/1", Status: entity.FileStatusUploaded, Checksum: "486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7"},
+	}
+	// chunk 0 is missing, chunk 1 is modified, and chunk 2 is truncated
+	damagedFile := &entity.File{ID: 2, Name: "damaged.txt", Size: 15, ChunkSize: 6, TotalChunks: 3, Status: entity.FileStatusUploaded}
+	damagedChunks := []*entity.FileChunk{
+		{ID: 20, ParentID: 2, ChunkNumber: 0, FilePath: "/test/uploads/damaged.txt/0", Status: entity.FileStatusUploaded},
+		{ID: 21, ParentID: 2, ChunkNumber: 1, FilePath: "/test/uploads/damaged.txt/1", Status: entity.FileStatusUploaded, Checksum: "486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7"},
+		{ID: 22, ParentID: 2, ChunkNumber: 2, FilePath: "/test/uploads/damaged.txt/2", Status: entity.FileStatusUploaded},
+	}
+
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+	storageError := e.NewFileStorageError(errors.New("storage error"), "")
+
+	// expectRead expects the chunk to be read with the given content
+	expectRead := func(mockStorageRepo *mock.MockFileStorageRepository, chunk *entity.FileChunk, size uint64, content string) {
+		mockStorageRepo.EXPECT().FileExists(gomock.Any(), chunk.FilePath).Return(true, nil)
+		mockStorageRepo.EXPECT().ReadChunk(gomock.Any(), gomock.Any(), chunk.FilePath, gomock.Any(), uint64(0), size).
+			DoAndReturn(func(_ context.Context, w io.Writer, _ string, _ entity.StorageCodec, _ uint64, _ uint64) e.CustomError {
+				_, _ = w.Write([]byte(content))
+				return nil
+			})
+	}
+
+	tests := []struct {
+		name        string
+		ctx         func() context.Context
+		setupMocks  func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository)
+		expectedRun *entity.ScrubRun
+		expectedErr e.CustomError
+	}{
+		{
+			name: "Success - Marks Missing, Modified and Unreadable Chunks",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusUploaded).Return([]*entity.File{healthyFile, damagedFile}, nil)
+
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, healthyFile.ID).Return(healthyChunks, nil)
+				expectRead(mockStorageRepo, healthyChunks[0], 6, "hello ")
+				expectRead(mockStorageRepo, healthyChunks[1], 5, "world")
+
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, damagedFile.ID).Return(damagedChunks, nil)
+				mockStorageRepo.EXPECT().FileExists(gomock.Any(), damagedChunks[0].FilePath).Return(false, nil)
+				expectRead(mockStorageRepo, damagedChunks[1], 6, "w0rld ")
+				mockStorageRepo.EXPECT().FileExists(gomock.Any(), damagedChunks[2].FilePath).Return(true, nil)
+				mockStorageRepo.EXPECT().ReadChunk(gomock.Any(), gomock.Any(), damagedChunks[2].FilePath, gomock.Any(), uint64(0), uint64(3)).Return(storageError)
+				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, damagedFile.ID, gomock.Any(), entity.FileStatusDamaged).
+					DoAndReturn(func(_ context.Context, _ uint64, chunkIDs []uint64, _ entity.FileStatus) e.CustomError {
+						assert.ElementsMatch(t, []uint64{20, 21, 22}, chunkIDs)
+						return nil
+					})
+			},
+			expectedRun: &entity.ScrubRun{ScannedFiles: 2, ScannedChunks: 5, DamagedFiles: 1},
+		},
+		{
+			name: "Success - No Uploaded Files",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusUploaded).Return([]*entity.File{}, nil)
+			},
+			expectedRun: &entity.ScrubRun{},
+		},
+		{
+			name: "Error - GetFilesByStatus DB Error",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusUploaded).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name: "Error - GetChunksByFileID DB Error",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusUploaded).Return([]*entity.File{healthyFile}, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, healthyFile.ID).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name: "Error - FileExists Storage Error",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusUploaded).Return([]*entity.File{healthyFile}, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, healthyFile.ID).Return(healthyChunks[:1], nil)
+				mockStorageRepo.EXPECT().FileExists(gomock.Any(), healthyChunks[0].FilePath).Return(false, storageError)
+			},
+			expectedErr: e.NewFileStorageError(storageError.ErrorObject(), "failed to scrub healthy.txt"),
+		},
+		{
+			name: "Error - UpdateFileAndChunkStatus DB Error",
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusUploaded).Return([]*entity.File{damagedFile}, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, damagedFile.ID).Return(damagedChunks[:1], nil)
+				mockStorageRepo.EXPECT().FileExists(gomock.Any(), damagedChunks[0].FilePath).Return(false, nil)
+				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, damagedFile.ID, []uint64{20}, entity.FileStatusDamaged).Return(dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name: "Error - Context Canceled",
+			ctx: func() context.Context {
+				canceledCtx, cancel := context.WithCancel(ctx)
+				cancel()
+				return canceledCtx
+			},
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFilesByStatus(gomock.Any(), entity.FileStatusUploaded).Return([]*entity.File{healthyFile}, nil)
+			},
+			expectedErr: e.NewContextError(context.Canceled, "scrub canceled"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+			mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+			uc := usecase.NewFileScrubUseCase(config, mockFileRepo, mockStorageRepo)
+
+			tc.setupMocks(mockFileRepo, mockStorageRepo)
+			execCtx := ctx
+			if tc.ctx != nil {
+				execCtx = tc.ctx()
+			}
+			run, err := uc.Execute(execCtx)
+
+			if tc.expectedErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+				assert.Nil(t, run)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedRun.ScannedFiles, run.ScannedFiles)
+			assert.Equal(t, tc.expectedRun.ScannedChunks, run.ScannedChunks)
+			assert.Equal(t, tc.expectedRun.DamagedFiles, run.DamagedFiles)
+			assert.False(t, run.FinishedAt.Before(run.StartedAt))
+		})
+	}
+}
+
+func TestFileScrubUseCase_Execute_AlreadyRunning(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileScrubUseCase(&entity.Config{WorkerPoolSize: 1}, mockFileRepo, mockStorageRepo)
+
+	// the first scrub blocks until the second one is rejected
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockFileRepo.EXPECT().GetFilesByStatus(gomock.Any(), entity.FileStatusUploaded).DoAndReturn(
+		func(_ context.Context, _ entity.FileStatus) ([]*entity.File, e.CustomError) {
+			close(started)
+			<-release
+			return []*entity.File{}, nil
+		},
+	)
+
+	done := make(chan e.CustomError)
+	go func() {
+		_, err := uc.Execute(context.Background())
+		done <- err
+	}()
+
+	<-started
+	_, err := uc.Execute(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, "CONFLICT", err.ErrorCode())
+	assert.Contains(t, err.Error(), "scrub is already running")
+
+	close(release)
+	assert.NoError(t, <-done)
+}
+
+func TestFileScrubUseCase_ExecuteGetReport(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	damagedFile := &entity.File{ID: 2, Name: "damaged.txt", Status: entity.FileStatusDamaged}
+	damagedChunks := []*entity.FileChunk{
+		{ID: 22, ParentID: 2, ChunkNumber: 2, Status: entity.FileStatusDamaged},
+		{ID: 20, ParentID: 2, ChunkNumber: 0, Status: entity.FileStatusDamaged},
+	}
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+
+	t.Run("Success - Before And After Scrub", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+		mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+		uc := usecase.NewFileScrubUseCase(&entity.Config{WorkerPoolSize: 1}, mockFileRepo, mockStorageRepo)
+
+		mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusDamaged).Return([]*entity.File{damagedFile}, nil).Times(2)
+		mockFileRepo.EXPECT().GetChunksByStatus(ctx, damagedFile.ID, []entity.FileStatus{entity.FileStatusDamaged}).Return(damagedChunks, nil).Times(2)
+		mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusUploaded).Return([]*entity.File{}, nil)
+
+		expectedDamagedFiles := []entity.ScrubDamagedFile{{FileID: 2, Name: "damaged.txt", ChunkNumbers: []uint64{0, 2}}}
+
+		report, err := uc.ExecuteGetReport(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, report.LastRun)
+		assert.Equal(t, expectedDamagedFiles, report.DamagedFiles)
+
+		run, err := uc.Execute(ctx)
+		require.NoError(t, err)
+
+		report, err = uc.ExecuteGetReport(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, run, report.LastRun)
+		assert.Equal(t, expectedDamagedFiles, report.DamagedFiles)
+	})
+
+	t.Run("Error - GetFilesByStatus DB Error", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+		mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+		uc := usecase.NewFileScrubUseCase(&entity.Config{WorkerPoolSize: 1}, mockFileRepo, mockStorageRepo)
+
+		mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusDamaged).Return(nil, dbError)
+
+		report, err := uc.ExecuteGetReport(ctx)
+		assert.Nil(t, report)
+		assert.Equal(t, dbError, err)
+	})
+
+	t.Run("Error - GetChunksByStatus DB Error", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+		mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+		uc := usecase.NewFileScrubUseCase(&entity.Config{WorkerPoolSize: 1}, mockFileRepo, mockStorageRepo)
+
+		mockFileRepo.EXPECT().GetFilesByStatus(ctx, entity.FileStatusDamaged).Return([]*entity.File{damagedFile}, nil)
+		mockFileRepo.EXPECT().GetChunksByStatus(ctx, damagedFile.ID, []entity.FileStatus{entity.FileStatusDamaged}).Return(nil, dbError)
+
+		report, err := uc.ExecuteGetReport(ctx)
+		assert.Nil(t, report)
+		assert.Equal(t, dbError, err)
+	})
+}
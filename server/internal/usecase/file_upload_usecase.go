@@ -1,52 +1,196 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
 	"github.com/tomoya.tokunaga/server/internal/interface/repository/database"
 	"github.com/tomoya.tokunaga/server/internal/interface/repository/storage"
+	"github.com/tomoya.tokunaga/server/internal/util/checksum"
+	"github.com/tomoya.tokunaga/server/internal/util/merkle"
 )
 
 type FileUploadUseCaseExecuteInitInput struct {
-	FileName    string
-	Checksum    string
-	TotalSize   uint64
-	TotalChunks uint
-	ChunkSize   uint64
-	IsReUpload  bool
+	FileName          string
+	Checksum          string
+	ChecksumAlgorithm string
+	TotalSize         uint64
+	TotalChunks       uint
+	ChunkSize         uint64
+	ChunkSizes        []uint64            // sizes of the chunks in order when they vary (e.g. content-defined chunks), each up to ChunkSize
+	ChunkChecksums    []string            // SHA-256 of the chunks in order, by which the chunks of the base file are reused
+	BaseFileID        uint64              // file bound to the name whose chunks of the same content are reused, which is none when 0
+	Owner             string              // who starts the upload, which is the only one who can resume its session
+	IsReUpload        bool                // resumes the session of the same content started by the owner if any
+	OpenEnded         bool                // the total size and the checksum are unknown until the upload is committed
+	StorageCodec      entity.StorageCodec // compression the chunks are stored in, which is the default one when empty
+}
+
+type FileUploadUseCaseExecuteCommitInput struct {
+	SessionToken string
+	TotalSize    uint64
+	Checksum     string // checksum of the whole content declared by the client, which is optional
+	Limits       entity.UploadLimits
 }
 
 type FileUploadUseCaseExecuteInput struct {
-	FileID      uint64
-	ChunkNumber uint64
-	Reader      io.Reader
+	SessionToken string
+	ChunkNumber  uint64
+	Offset       uint64 // byte offset in the chunk the content starts at, which is up to the size of the content received so far
+	Reader       io.Reader
+	Checksum     string // SHA-256 of the whole chunk content declared by the client, which is optional
+}
+
+type FileUploadUseCaseExecuteAppendInput struct {
+	SessionToken string
+	Offset       uint64 // byte offset in the file the content starts at, which needs to be the offset of the upload
+	Reader       io.Reader
+	ChecksumHash hash.Hash // hash of the declared checksum of the content, which is optional
+	Checksum     []byte
+}
+
+type FileUploadUseCaseExecutePutInput struct {
+	FileName          string
+	Reader            io.Reader
+	Checksum          string // checksum of the whole content declared by the client, which is optional
+	ChecksumAlgorithm string
+	MaxSize           uint64              // the content beyond it is rejected, which is no limit when zero
+	StorageCodec      entity.StorageCodec // compression the chunk is stored in, which is the default one when empty
+}
+
+type FileUploadUseCaseExecuteUploadPartInput struct {
+	FileName   string // name of the file which the upload is created for
+	UploadID   string
+	PartNumber uint64
+	Reader     io.Reader
+	Checksum   string // SHA-256 of the part content declared by the client, which is optional
+}
+
+type FileUploadUseCaseExecuteCompleteMultipartInput struct {
+	FileName string // name of the file which the upload is created for
+	UploadID string
+	Parts    []entity.MultipartPart // numbers and optionally checksums of the parts making up the file in order
+	Limits   entity.UploadLimits
+	Owner    string
 }
 
 type fileUploadUseCase struct {
 	fileRepo       database.FileRepository
+	sessionRepo    database.UploadSessionRepository
 	storageRepo    storage.FileStorageRepository
 	baseStorageDir string
+	sessionTTL     time.Duration
+	storageCodec   entity.StorageCodec // compression of the chunks of the files which don't choose theirs
+
+	appendMutex       sync.Mutex
+	appendingSessions map[string]struct{} // tokens of the sessions being appended, which allows one request per session
+
+	writeMutex    sync.Mutex
+	sessionWrites map[string]*sessionWrites // requests writing the content of the sessions by their tokens
 }
 
 func NewFileUploadUseCase(
 	fileRepo database.FileRepository,
+	sessionRepo database.UploadSessionRepository,
 	storageRepo storage.FileStorageRepository,
 	baseStorageDir string,
+	sessionTTL time.Duration,
+	storageCodec entity.StorageCodec,
 ) FileUploadUseCase {
 	return &fileUploadUseCase{
-		fileRepo:       fileRepo,
-		storageRepo:    storageRepo,
-		baseStorageDir: baseStorageDir,
+		fileRepo:          fileRepo,
+		sessionRepo:       sessionRepo,
+		storageRepo:       storageRepo,
+		baseStorageDir:    baseStorageDir,
+		sessionTTL:        sessionTTL,
+		storageCodec:      storageCodec,
+		appendingSessions: make(map[string]struct{}),
+		sessionWrites:     make(map[string]*sessionWrites),
+	}
+}
+
+// sessionWrites tracks the requests writing the content of an upload session, so that an abort can reject the new
+// ones and cancel and wait for the running ones before the content is deleted
+type sessionWrites struct {
+	aborted chan struct{} // closed when the upload is aborted
+	writers int
+	wg      sync.WaitGroup
+}
+
+func newSessionWrites() *sessionWrites {
+	return &sessionWrites{aborted: make(chan struct{})}
+}
+
+func (w *sessionWrites) isAborted() bool {
+	select {
+	case <-w.aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+// abortableReader fails to read once the upload is aborted, so that the content being written stops at the next read
+type abortableReader struct {
+	reader  io.Reader
+	aborted <-chan struct{}
+}
+
+func (r *abortableReader) Read(p []byte) (int, error) {
+	select {
+	case <-r.aborted:
+		return 0, errors.New("upload is aborted")
+	default:
+		return r.reader.Read(p)
 	}
 }
 
-// ExecuteInit initializes a file upload and returns an upload ID
-func (uc *fileUploadUseCase) ExecuteInit(ctx context.Context, input FileUploadUseCaseExecuteInitInput) (*entity.File, []*entity.FileChunk, e.CustomError) {
+// ExecuteInit starts an upload session of a file, or resumes the one of the same content started by the same owner
+// when it's a re-upload. The chunks of a resumed session which are not uploaded yet are returned, so that only they
+// are sent again.
+func (uc *fileUploadUseCase) ExecuteInit(ctx context.Context, input FileUploadUseCaseExecuteInitInput) (*entity.UploadSession, []*entity.FileChunk, e.CustomError) {
+	// the checksum is verified with the declared algorithm once all chunks are uploaded
+	if _, err := checksum.New(checksum.Algorithm(input.ChecksumAlgorithm)); err != nil {
+		return nil, nil, e.NewInvalidInputError(err, "invalid checksum algorithm")
+	}
+	storageCodec, err := uc.chooseStorageCodec(input.StorageCodec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if isReservedName(input.FileName) {
+		return nil, nil, e.NewInvalidInputError(fmt.Errorf("%s is reserved", input.FileName), "invalid file name")
+	}
+
+	// the chunks of an open-ended upload are created as they're uploaded, and its layout is validated on the commit.
+	// It can't be resumed by a new initialization, since it has no checksum to tell the same content
+	if input.OpenEnded {
+		if input.ChunkSize == 0 {
+			return nil, nil, e.NewInvalidInputError(errors.New("chunk size must be greater than 0"), "invalid chunk layout")
+		}
+		file := entity.NewFile(input.FileName, 0, "", input.ChecksumAlgorithm, 0, input.ChunkSize)
+		file.StorageCodec = storageCodec
+		session, err := uc.createSession(ctx, file, input.Owner, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		return session, nil, nil
+	}
+
 	// check if the total size can fit in the available storage space
 	availableSpace := uc.storageRepo.GetAvailableSpace(ctx, uc.baseStorageDir)
 	if availableSpace < input.TotalSize {
@@ -56,66 +200,200 @@ func (uc *fileUploadUseCase) ExecuteInit(ctx context.Context, input FileUploadUs
 		)
 	}
 
-	// Directory path for the file chunks
-	fileDirPath := filepath.Join(uc.baseStorageDir, input.FileName)
+	if input.IsReUpload {
+		session, err := uc.findResumableSession(ctx, input)
+		if err != nil {
+			return nil, nil, err
+		}
+		if session != nil {
+			missingChunks, err := uc.resumeSession(ctx, session)
+			if err != nil {
+				return nil, nil, err
+			}
+			return session, missingChunks, nil
+		}
+	}
 
-	// Check if the file already exists
-	existingFile, err := uc.fileRepo.GetFileByName(ctx, input.FileName)
-	if err != nil {
+	if err := validateChunkLayout(input); err != nil {
 		return nil, nil, err
 	}
-	if existingFile == nil {
-		// Create a new file object and insert it to "files" and corresponding child records"file_chunks" tables
-		file := entity.NewFile(input.FileName, input.TotalSize, input.Checksum, input.TotalChunks, input.ChunkSize)
 
-		fileRecord, err := uc.fileRepo.CreateFileWithChunks(ctx, file, uc.baseStorageDir)
-		if err != nil {
+	file := entity.NewFile(input.FileName, input.TotalSize, input.Checksum, input.ChecksumAlgorithm, input.TotalChunks, input.ChunkSize)
+	file.StorageCodec = storageCodec
+	if input.ChunkSizes != nil {
+		file.SetChunkSizes(input.ChunkSizes)
+	}
+	isDelta := input.BaseFileID != 0 && input.ChunkChecksums != nil
+	if isDelta {
+		if err := uc.reuseBaseChunks(ctx, file, input.BaseFileID, input.ChunkChecksums); err != nil {
 			return nil, nil, err
 		}
+	}
+	session, err := uc.createSession(ctx, file, input.Owner, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isDelta {
+		return session, nil, nil
+	}
 
-		// Create a directory for the file chunks
-		if err := uc.storageRepo.CreateDirectory(ctx, fileDirPath); err != nil {
+	// only the chunks which reuse no blob are uploaded. A file all of whose chunks are reused is complete already
+	missingChunks := make([]*entity.FileChunk, 0, len(file.FileChunks))
+	for i := range file.FileChunks {
+		if file.FileChunks[i].Status != entity.FileStatusUploaded {
+			missingChunks = append(missingChunks, &file.FileChunks[i])
+		}
+	}
+	if len(missingChunks) == 0 {
+		session.File.Name = session.TargetName
+		if err := uc.verifyFile(ctx, session, session.File); err != nil {
 			return nil, nil, err
 		}
+	}
+
+	return session, missingChunks, nil
+}
 
-		return fileRecord, nil, nil
+// reuseBaseChunks refers the chunks of the new file to the blobs of the chunks of the base file with the same content,
+// so that only the other chunks are uploaded. The chunks are matched by their checksums and sizes wherever they are in
+// the files, so the content shifted by an insertion is reused as well when the chunks are cut by the content. Only the
+// file bound to the same name in the same codec is the base, otherwise every chunk is uploaded. The file gets its
+// chunks, and is in progress when any of them is reused
+func (uc *fileUploadUseCase) reuseBaseChunks(ctx context.Context, file *entity.File, baseFileID uint64, chunkChecksums []string) e.CustomError {
+	if file.FileChunks == nil {
+		file.FileChunks = make([]entity.FileChunk, file.TotalChunks)
+		for i := range file.FileChunks {
+			chunk := &file.FileChunks[i]
+			chunk.ChunkNumber = uint64(i)
+			chunk.Status = entity.FileStatusInitialized
+			_, chunk.Size = file.ChunkRange(chunk)
+		}
 	}
 
-	// Assume two files are same if they have the same checksum and size
-	isSameFile := existingFile.Checksum == input.Checksum && existingFile.Size == input.TotalSize
-	if !isSameFile {
-		return nil, nil, e.NewInvalidInputError(err, fmt.Sprintf("%s with different content (including orphaned data) already exists", input.FileName))
+	baseFile, err := uc.fileRepo.GetFileByID(ctx, baseFileID)
+	if err != nil {
+		return err
 	}
-	if existingFile.Status == entity.FileStatusInitialized {
-		// Due to the atomicity of the transaction, if the status of "files" record is "INITIALIZED", that of "file_chunks" records is also
-		// "INITIALIZED", so there's no need to check the inconsistency of file status and file chunk status. However, the file storage could
-		// fail to be created even though the "files" record is created, so the following makes sure the directory is certainly there in the file storage
-		if err := uc.storageRepo.CreateDirectory(ctx, fileDirPath); err != nil {
-			return nil, nil, err
+	if baseFile == nil || baseFile.Name != file.Name || baseFile.Status != entity.FileStatusUploaded || baseFile.StorageCodec != file.StorageCodec {
+		return nil
+	}
+	baseChunks, err := uc.fileRepo.GetChunksByFileID(ctx, baseFile.ID)
+	if err != nil {
+		return err
+	}
+
+	// the chunks stored before the blobs existed have no blob to be shared
+	blobChunks := make(map[string]*entity.FileChunk, len(baseChunks))
+	for _, chunk := range baseChunks {
+		if chunk.Status == entity.FileStatusUploaded && chunk.BlobID != 0 && chunk.Checksum != "" {
+			blobChunks[chunk.Checksum] = chunk
 		}
-		return existingFile, nil, nil
 	}
-	if !input.IsReUpload {
-		return nil, nil, e.NewInvalidInputError(err, fmt.Sprintf("%s with same content(including orphaned data) already exists", input.FileName))
+	for i := range file.FileChunks {
+		chunk := &file.FileChunks[i]
+		baseChunk, found := blobChunks[chunkChecksums[i]]
+		if !found || baseChunk.Size != chunk.Size {
+			continue
+		}
+		chunk.Status = entity.FileStatusUploaded
+		chunk.Checksum = baseChunk.Checksum
+		chunk.BlobID = baseChunk.BlobID
+		chunk.FilePath = baseChunk.FilePath
+		file.Status = entity.FileStatusInProgress
+	}
+
+	return nil
+}
+
+// findResumableSession returns the latest unexpired session of the file name started by the owner, whose file has the
+// same content and is still being uploaded. It returns nil when there's none. A file without the checksum, whose
+// checksum is calculated once it's uploaded, is never same as another
+func (uc *fileUploadUseCase) findResumableSession(ctx context.Context, input FileUploadUseCaseExecuteInitInput) (*entity.UploadSession, e.CustomError) {
+	if input.Checksum == "" {
+		return nil, nil
+	}
+	sessions, err := uc.sessionRepo.GetSessionsByTargetName(ctx, input.FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := len(sessions) - 1; i >= 0; i-- {
+		session := sessions[i]
+		if session.Owner != input.Owner || session.Multipart || session.IsExpired(now) || session.File == nil {
+			continue
+		}
+		// a corrupt file can't tell which chunks are broken, so it's uploaded again in a new session
+		file := session.File
+		if file.Status != entity.FileStatusInitialized && file.Status != entity.FileStatusInProgress && file.Status != entity.FileStatusFailed {
+			continue
+		}
+		if file.Checksum != input.Checksum || file.ChecksumAlgorithm != input.ChecksumAlgorithm || file.Size != input.TotalSize {
+			continue
+		}
+		// the same content split into other chunks (e.g. by other chunking parameters) is uploaded in a new session
+		sameLayout, err := uc.hasChunkLayout(ctx, file, input.ChunkSizes)
+		if err != nil {
+			return nil, err
+		}
+		if sameLayout {
+			return session, nil
+		}
 	}
-	if existingFile.Status == entity.FileStatusUploaded {
-		// only accepts re-uploading for files which aren't completed yet
-		return nil, nil, e.NewInvalidInputError(err, fmt.Sprintf("existing %s is in %s status and cannot be re-uploaded", input.FileName, existingFile.Status))
+
+	return nil, nil
+}
+
+// hasChunkLayout reports whether the chunks of the file have the sizes, where nil sizes mean the fixed chunk size. The
+// sizes of variable chunks are compared with the recorded ones
+func (uc *fileUploadUseCase) hasChunkLayout(ctx context.Context, file *entity.File, chunkSizes []uint64) (bool, e.CustomError) {
+	if !file.VariableChunks {
+		return chunkSizes == nil, nil
+	}
+	if chunkSizes == nil || uint(len(chunkSizes)) != file.TotalChunks {
+		return false, nil
+	}
+
+	chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
+	if err != nil {
+		return false, err
+	}
+	if len(chunks) != len(chunkSizes) {
+		return false, nil
 	}
+	for i, chunk := range chunks {
+		if chunk.Size != chunkSizes[i] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
 
+// resumeSession makes the chunks of the session which are not uploaded yet ready to be uploaded again, extends the
+// session, and returns the chunks
+func (uc *fileUploadUseCase) resumeSession(ctx context.Context, session *entity.UploadSession) ([]*entity.FileChunk, e.CustomError) {
 	// remove corresponding file chunks whose status is not "UPLOADED"
-	invalidChunks, err := uc.fileRepo.GetChunksByStatus(ctx, existingFile.ID, []entity.FileStatus{
+	invalidChunks, err := uc.fileRepo.GetChunksByStatus(ctx, session.FileID, []entity.FileStatus{
 		entity.FileStatusInitialized,
 		entity.FileStatusInProgress,
 		entity.FileStatusFailed,
 	})
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	// the content received before the upload of a chunk was cut off is kept in its partial chunk, so that the client
+	// can send only the rest of it. A chunk which was uploaded keeps its blob until it's uploaded again
 	for _, chunk := range invalidChunks {
-		if err := uc.storageRepo.DeleteFile(ctx, chunk.FilePath); err != nil {
-			return nil, nil, err
+		stagingPath := uc.stagingChunkPath(session.Token, chunk.ChunkNumber)
+		if err := uc.storageRepo.DeleteFile(ctx, stagingPath); err != nil {
+			return nil, err
+		}
+		partialSize, err := uc.storageRepo.GetPartialChunkSize(ctx, stagingPath)
+		if err != nil {
+			return nil, err
 		}
+		chunk.PartialSize = partialSize
 	}
 
 	// set the status of invalid chunks to "INITIALIZED"
@@ -126,30 +404,54 @@ func (uc *fileUploadUseCase) ExecuteInit(ctx context.Context, input FileUploadUs
 		}
 	}
 	if len(chunkIDsToUpdate) > 0 {
-		if err := uc.fileRepo.UpdateFileAndChunkStatus(ctx, existingFile.ID, chunkIDsToUpdate, entity.FileStatusInitialized); err != nil {
-			return nil, nil, err
+		if err := uc.fileRepo.UpdateFileAndChunkStatus(ctx, session.FileID, chunkIDsToUpdate, entity.FileStatusInitialized); err != nil {
+			return nil, err
 		}
 	}
 
-	// Preserving the space for the file to be uploaded
-	uc.storageRepo.UpdateAvailableSpace(int64(input.TotalSize))
+	if err := uc.extendSession(ctx, session); err != nil {
+		return nil, err
+	}
 
-	return existingFile, invalidChunks, nil
+	return invalidChunks, nil
 }
 
-// Execute uploads a chunk of a file
+// Execute uploads a chunk of a file. A chunk of an open-ended upload can be of any size up to the chunk size, and can
+// be sent again until the upload is committed, since the content read from a stream can't be resumed by a new
+// initialization
 func (uc *fileUploadUseCase) Execute(ctx context.Context, input FileUploadUseCaseExecuteInput) e.CustomError {
-	// validate the upload ID, chunk ID, and file and chunk status
-	file, chunk, err := uc.fileRepo.GetFileAndChunk(ctx, input.FileID, input.ChunkNumber)
+	// the request is registered before the session is read, so that an abort either rejects it or waits for it
+	aborted, endWrite, err := uc.beginWrite(input.SessionToken)
+	if err != nil {
+		return err
+	}
+	defer endWrite()
+
+	// validate the session, chunk number, and file and chunk status
+	session, err := uc.getSession(ctx, input.SessionToken)
+	if err != nil {
+		return err
+	}
+	stagingPath := uc.stagingChunkPath(session.Token, input.ChunkNumber)
+	if session.OpenEnded {
+		if err := uc.fileRepo.CreateChunkIfNotExists(ctx, session.FileID, input.ChunkNumber, stagingPath); err != nil {
+			return err
+		}
+	}
+	file, chunk, err := uc.fileRepo.GetFileAndChunk(ctx, session.FileID, input.ChunkNumber)
 	if err != nil {
 		return err
 	}
 	if file == nil || chunk == nil {
-		return e.NewInvalidInputError(err, fmt.Sprintf("data not found for (file ID, chunk ID) = (%d, %d)", input.FileID, input.ChunkNumber))
+		return e.NewInvalidInputError(err, fmt.Sprintf("data not found for (file ID, chunk ID) = (%d, %d)", session.FileID, input.ChunkNumber))
 	}
-	if (file.Status != entity.FileStatusInitialized && file.Status != entity.FileStatusInProgress) || chunk.Status != entity.FileStatusInitialized {
+	file.Name = session.TargetName
+	if !isChunkUploadable(session, file, chunk) {
 		return e.NewInvalidInputError(fmt.Errorf("file upload needs to be initialized"), "")
 	}
+	if err := uc.extendSession(ctx, session); err != nil {
+		return err
+	}
 
 	// Update file and chunk status to processing
 	if file.Status == entity.FileStatusInitialized {
@@ -166,25 +468,68 @@ func (uc *fileUploadUseCase) Execute(ctx context.Context, input FileUploadUseCas
 		}
 	}
 
-	// Write the chunk to storage
-	if err := uc.storageRepo.WriteChunk(ctx, input.Reader, chunk.FilePath); err != nil {
+	// Write the content to the partial chunk from the offset, so that the content received before the request is cut
+	// off is kept and the upload of the chunk can continue from it. The reader is limited to one byte more than the
+	// rest of the chunk, so that an oversized chunk is detected without writing all of it
+	_, expectedSize := file.ChunkRange(chunk)
+	if session.OpenEnded {
+		expectedSize = file.ChunkSize
+	}
+	if input.Offset > 0 {
+		partialSize, err := uc.storageRepo.GetPartialChunkSize(ctx, stagingPath)
+		if err != nil {
+			return err
+		}
+		if input.Offset > partialSize || input.Offset > expectedSize {
+			return e.NewConflictError(
+				fmt.Errorf("%d bytes of chunk %d of %s are received, but got the content from %d", partialSize, chunk.ChunkNumber, file.Name, input.Offset),
+				"invalid offset",
+			)
+		}
+	}
+	reader := &abortableReader{reader: input.Reader, aborted: aborted}
+	partialSize, err := uc.storageRepo.AppendPartialChunk(ctx, io.LimitReader(reader, int64(expectedSize-input.Offset)+1), stagingPath, input.Offset)
+	if err != nil {
+		return err
+	}
+	if partialSize > expectedSize {
+		// only the content of this request is discarded
+		if _, err := uc.storageRepo.AppendPartialChunk(ctx, strings.NewReader(""), stagingPath, input.Offset); err != nil {
+			return err
+		}
+	}
+	if partialSize != expectedSize && (!session.OpenEnded || partialSize == 0 || partialSize > expectedSize) {
+		// the content of an undersized chunk is kept, so that the rest of it can be sent from its size
+		return e.NewInvalidInputError(
+			fmt.Errorf("chunk %d of %s needs to be %d bytes, but got %s", chunk.ChunkNumber, file.Name, expectedSize, describeChunkSize(partialSize, expectedSize)),
+			"invalid chunk size",
+		)
+	}
+
+	// the checksum covers the whole chunk, so the chunk corrupted in transit is rejected even if it's received by
+	// multiple requests
+	size, writtenChecksum, err := uc.storageRepo.CommitPartialChunk(ctx, stagingPath, file.StorageCodec, input.Checksum)
+	if err != nil {
 		return err
 	}
 
 	// Update chunk status to completed
-	if err = uc.fileRepo.UpdateChunksStatus(ctx, []uint64{chunk.ID}, entity.FileStatusUploaded); err != nil {
+	chunk.Size = size
+	chunk.Checksum = writtenChecksum
+	if err = uc.storeChunk(ctx, chunk, stagingPath, file.StorageCodec); err != nil {
 		return err
 	}
+	if session.OpenEnded {
+		// the file is verified on the commit, once its size is known
+		return nil
+	}
 
 	uploadedChunks, totalChunks, err := uc.fileRepo.CountChunksByStatus(ctx, file.ID, entity.FileStatusUploaded)
 	if err != nil {
 		return err
 	}
 	if uploadedChunks == totalChunks {
-		// Update file status to completed
-		if err := uc.fileRepo.UpdateFileStatus(ctx, file.ID, entity.FileStatusUploaded); err != nil {
-			return err
-		}
+		return uc.verifyFile(ctx, session, file)
 	}
 
 	// // Increment uploaded chunks counter
@@ -205,9 +550,1126 @@ func (uc *fileUploadUseCase) Execute(ctx context.Context, input FileUploadUseCas
 }
 
 // ExecuteFailRecovery handles the failure of a chunk upload
-func (uc *fileUploadUseCase) ExecuteFailRecovery(ctx context.Context, fileID uint64, chunkID uint64) e.CustomError {
-	if err := uc.fileRepo.UpdateFileAndChunkStatus(ctx, fileID, []uint64{chunkID}, entity.FileStatusFailed); err != nil {
+func (uc *fileUploadUseCase) ExecuteFailRecovery(ctx context.Context, sessionToken string, chunkNumber uint64) e.CustomError {
+	session, err := uc.getSession(ctx, sessionToken)
+	if err != nil {
+		return err
+	}
+	_, chunk, err := uc.fileRepo.GetFileAndChunk(ctx, session.FileID, chunkNumber)
+	if err != nil {
+		return err
+	}
+	if chunk == nil {
+		return e.NewNotFoundError(fmt.Errorf("chunk %d of %s not found", chunkNumber, session.TargetName), "")
+	}
+	if err := uc.fileRepo.UpdateChunksStatus(ctx, []uint64{chunk.ID}, entity.FileStatusFailed); err != nil {
+		return err
+	}
+
+	// the file is marked as failed only while it's being uploaded, so that the result of the verification
+	// (e.g. CORRUPT) is not overwritten
+	if _, err := uc.fileRepo.CompareAndUpdateFileStatus(ctx, session.FileID, []entity.FileStatus{
+		entity.FileStatusInitialized,
+		entity.FileStatusInProgress,
+	}, entity.FileStatusFailed); err != nil {
 		return err
 	}
 	return nil
 }
+
+// ExecuteGetOffset returns the upload session with its file and the number of the bytes of the file stored so far by
+// appending. The session is kept after it's committed until it expires, so the offset of a completed upload is the
+// file size.
+func (uc *fileUploadUseCase) ExecuteGetOffset(ctx context.Context, sessionToken string) (*entity.UploadSession, uint64, e.CustomError) {
+	session, err := uc.getSession(ctx, sessionToken)
+	if err != nil {
+		return nil, 0, err
+	}
+	file := session.File
+	if file.Status == entity.FileStatusUploaded {
+		return session, file.Size, nil
+	}
+
+	chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset, _, err := uc.appendOffset(ctx, session, chunks)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return session, offset, nil
+}
+
+// ExecuteAppend appends the content to the file at the offset of the upload, and returns the new offset. Unlike the
+// chunk uploads, the content can start and end in the middle of a chunk, so the content of the chunk received so far
+// is kept in its partial chunk until the chunk is filled. The received content is kept even if the request is cut
+// off, except when the checksum of the content is declared, where the whole content is discarded unless it matches.
+func (uc *fileUploadUseCase) ExecuteAppend(ctx context.Context, input FileUploadUseCaseExecuteAppendInput) (uint64, e.CustomError) {
+	// the offset is ambiguous when the file is appended by multiple requests at a time
+	if !uc.lockAppend(input.SessionToken) {
+		return 0, e.NewConflictError(errors.New("upload is being appended by another request"), "")
+	}
+	defer uc.unlockAppend(input.SessionToken)
+	aborted, endWrite, err := uc.beginWrite(input.SessionToken)
+	if err != nil {
+		return 0, err
+	}
+	defer endWrite()
+
+	session, err := uc.getSession(ctx, input.SessionToken)
+	if err != nil {
+		return 0, err
+	}
+	file := session.File
+	if session.OpenEnded {
+		return 0, e.NewInvalidInputError(fmt.Errorf("upload of %s is open-ended and cannot be appended", file.Name), "")
+	}
+	if file.Status != entity.FileStatusInitialized && file.Status != entity.FileStatusInProgress {
+		return 0, e.NewInvalidInputError(fmt.Errorf("%s is in %s status and cannot be appended", file.Name, file.Status), "")
+	}
+	if err := uc.extendSession(ctx, session); err != nil {
+		return 0, err
+	}
+
+	chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
+	if err != nil {
+		return 0, err
+	}
+	startOffset, nextChunkIndex, err := uc.appendOffset(ctx, session, chunks)
+	if err != nil {
+		return 0, err
+	}
+	if input.Offset != startOffset {
+		return 0, e.NewConflictError(fmt.Errorf("offset of %s is %d, but got %d", file.Name, startOffset, input.Offset), "invalid offset")
+	}
+	if file.Status == entity.FileStatusInitialized {
+		if err := uc.fileRepo.UpdateFileStatus(ctx, file.ID, entity.FileStatusInProgress); err != nil {
+			return 0, err
+		}
+	}
+
+	// the content beyond the file size is detected after the file is filled
+	var reader io.Reader = io.LimitReader(&abortableReader{reader: input.Reader, aborted: aborted}, int64(file.Size-startOffset))
+	if input.ChecksumHash != nil {
+		reader = io.TeeReader(reader, input.ChecksumHash)
+	}
+
+	// the content is staged in the partial chunks, and the filled ones are committed after all the content is received
+	offset := startOffset
+	touchedChunks := []*entity.FileChunk{}
+	filledChunks := []*entity.FileChunk{}
+	var readErr e.CustomError
+	for _, chunk := range chunks[nextChunkIndex:] {
+		chunkOffset, chunkSize := file.ChunkRange(chunk)
+		partialOffset := offset - chunkOffset
+		touchedChunks = append(touchedChunks, chunk)
+		partialSize, err := uc.storageRepo.AppendPartialChunk(ctx, io.LimitReader(reader, int64(chunkSize-partialOffset)), uc.stagingChunkPath(session.Token, chunk.ChunkNumber), partialOffset)
+		offset = chunkOffset + partialSize
+		if err != nil {
+			readErr = err
+			break
+		}
+		if partialSize < chunkSize {
+			break
+		}
+		filledChunks = append(filledChunks, chunk)
+	}
+
+	// the received content is kept even if the client disconnects
+	ctx = context.WithoutCancel(ctx)
+
+	if readErr == nil && offset == file.Size {
+		n, err := io.ReadFull(input.Reader, make([]byte, 1))
+		if n > 0 {
+			if err := uc.discardAppend(ctx, session, touchedChunks, startOffset); err != nil {
+				return 0, err
+			}
+			return 0, e.NewInvalidInputError(fmt.Errorf("content exceeds the size of %s, %d bytes", file.Name, file.Size), "")
+		}
+		// the reader may fail at the end of the content, e.g. when the checksum trailing the content doesn't match, so
+		// the file isn't verified until the end is read
+		if err != nil && !errors.Is(err, io.EOF) {
+			readErr = e.NewInvalidInputError(err, fmt.Sprintf("failed to read the end of the content appended to %s", file.Name))
+		}
+	}
+	if input.ChecksumHash != nil {
+		if readErr != nil {
+			if err := uc.discardAppend(ctx, session, touchedChunks, startOffset); err != nil {
+				return 0, err
+			}
+			return 0, readErr
+		}
+		if actualChecksum := input.ChecksumHash.Sum(nil); !bytes.Equal(actualChecksum, input.Checksum) {
+			if err := uc.discardAppend(ctx, session, touchedChunks, startOffset); err != nil {
+				return 0, err
+			}
+			return 0, e.NewChecksumMismatchError(
+				fmt.Errorf("expected %x, but got %x", input.Checksum, actualChecksum),
+				fmt.Sprintf("content appended to %s is corrupted", file.Name),
+			)
+		}
+	}
+
+	for _, chunk := range filledChunks {
+		stagingPath := uc.stagingChunkPath(session.Token, chunk.ChunkNumber)
+		size, writtenChecksum, err := uc.storageRepo.CommitPartialChunk(ctx, stagingPath, file.StorageCodec, "")
+		if err != nil {
+			return 0, err
+		}
+		chunk.Size = size
+		chunk.Checksum = writtenChecksum
+		if err := uc.storeChunk(ctx, chunk, stagingPath, file.StorageCodec); err != nil {
+			return 0, err
+		}
+	}
+	if readErr != nil {
+		return offset, readErr
+	}
+
+	if offset == file.Size && len(filledChunks) > 0 {
+		if err := uc.verifyFile(ctx, session, file); err != nil {
+			return 0, err
+		}
+	}
+
+	return offset, nil
+}
+
+// ExecuteCommit completes an open-ended upload with its total size and optionally its checksum, and returns the
+// uploaded file. The uploaded chunks need to make up the total size, where every chunk except the last one has the
+// chunk size, and the file is verified and bound to the name as the other uploads are once all of their chunks are
+// uploaded
+func (uc *fileUploadUseCase) ExecuteCommit(ctx context.Context, input FileUploadUseCaseExecuteCommitInput) (*entity.File, e.CustomError) {
+	session, err := uc.getSession(ctx, input.SessionToken)
+	if err != nil {
+		return nil, err
+	}
+	file := session.File
+	if !session.OpenEnded {
+		return nil, e.NewInvalidInputError(fmt.Errorf("upload of %s is committed once all of its chunks are uploaded", session.TargetName), "")
+	}
+	if session.IsCommitted() {
+		return nil, e.NewConflictError(fmt.Errorf("upload of %s is already committed", session.TargetName), "")
+	}
+	if file.Status != entity.FileStatusInProgress && file.Status != entity.FileStatusFailed {
+		return nil, e.NewInvalidInputError(fmt.Errorf("upload of %s is in %s status and cannot be committed", session.TargetName, file.Status), "")
+	}
+
+	chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateOpenEndedChunks(session.TargetName, file.ChunkSize, chunks, input.TotalSize); err != nil {
+		return nil, err
+	}
+	if err := input.Limits.Validate(input.TotalSize, file.ChunkSize, uint64(len(chunks))); err != nil {
+		return nil, e.NewInvalidInputError(err, "invalid upload layout")
+	}
+
+	if err := uc.fileRepo.UpdateFileLayout(ctx, file.ID, input.TotalSize, uint(len(chunks)), input.Checksum); err != nil {
+		return nil, err
+	}
+	file.Name = session.TargetName
+	file.Size = input.TotalSize
+	file.TotalChunks = uint(len(chunks))
+	file.Checksum = input.Checksum
+	if err := uc.verifyFile(ctx, session, file); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// ExecuteAbort aborts an upload session, and deletes its file along with the content uploaded so far. The file bound
+// to the name of the session is left as it is. The chunks sent after the abort are rejected, and the ones being written
+// are canceled and waited for before the content is deleted, so that no chunk is left behind. A committed session
+// can't be aborted, since its file is the one bound to the name
+func (uc *fileUploadUseCase) ExecuteAbort(ctx context.Context, sessionToken string) e.CustomError {
+	session, err := uc.getSession(ctx, sessionToken)
+	if err != nil {
+		return err
+	}
+	if err := checkAbortable(session); err != nil {
+		return err
+	}
+
+	endAbort := uc.abortWrites(sessionToken)
+	defer endAbort()
+
+	// a chunk written before the abort may have completed the upload, so the session is read again
+	session, err = uc.getSession(ctx, sessionToken)
+	if err != nil {
+		return err
+	}
+	if err := checkAbortable(session); err != nil {
+		return err
+	}
+
+	return uc.discardSession(ctx, session)
+}
+
+// ExecuteSweepSessions deletes the expired upload sessions, and returns the number of the uploads discarded along with
+// them. The file of a committed session is kept, and so is the file being verified, whose session is committed or
+// swept later.
+func (uc *fileUploadUseCase) ExecuteSweepSessions(ctx context.Context) (int, e.CustomError) {
+	sessions, err := uc.sessionRepo.GetExpiredSessions(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	discarded := 0
+	for _, session := range sessions {
+		switch {
+		case session.IsCommitted():
+			if err := uc.sessionRepo.DeleteSession(ctx, session.ID); err != nil {
+				return discarded, err
+			}
+		case session.File == nil || session.File.Status == entity.FileStatusVerifying:
+			continue
+		default:
+			if err := uc.discardSession(ctx, session); err != nil {
+				return discarded, err
+			}
+			discarded++
+		}
+	}
+
+	return discarded, nil
+}
+
+// ExecutePut stores the content as a file of a single chunk, and returns the uploaded file. Unlike the uploads in
+// sessions, the file is created in the UPLOADED status along with its chunk and bound to the name in a single
+// transaction once the content is stored, so that a small file is uploaded by a single request
+func (uc *fileUploadUseCase) ExecutePut(ctx context.Context, input FileUploadUseCaseExecutePutInput) (*entity.File, e.CustomError) {
+	hash, hashErr := checksum.New(checksum.Algorithm(input.ChecksumAlgorithm))
+	if hashErr != nil {
+		return nil, e.NewInvalidInputError(hashErr, "invalid checksum algorithm")
+	}
+	if isReservedName(input.FileName) {
+		return nil, e.NewInvalidInputError(fmt.Errorf("%s is reserved", input.FileName), "invalid file name")
+	}
+	storageCodec, err := uc.chooseStorageCodec(input.StorageCodec)
+	if err != nil {
+		return nil, err
+	}
+
+	// the chunk is written right into the object area, where it becomes a blob unless the blob of the same content
+	// exists
+	chunkPath, err := uc.newBlobPath()
+	if err != nil {
+		return nil, err
+	}
+
+	// the reader is limited to one byte more than the maximum size, so that oversized content is detected without
+	// writing all of it
+	reader := input.Reader
+	if input.MaxSize > 0 {
+		reader = io.LimitReader(reader, int64(input.MaxSize)+1)
+	}
+	size, chunkChecksum, err := uc.storageRepo.WriteChunk(ctx, io.TeeReader(reader, hash), chunkPath, storageCodec, "")
+	actualChecksum := hex.EncodeToString(hash.Sum(nil))
+
+	// the stored content is either bound to the name or deleted even if the client disconnects from here
+	ctx = context.WithoutCancel(ctx)
+	if err == nil {
+		err = validatePutContent(input, size, actualChecksum)
+	}
+	if err != nil {
+		_ = uc.storageRepo.DeleteFile(ctx, chunkPath)
+		return nil, err
+	}
+
+	chunkDigest, _ := hex.DecodeString(chunkChecksum)
+	file := entity.NewFile(input.FileName, size, actualChecksum, input.ChecksumAlgorithm, 1, size)
+	file.StorageCodec = storageCodec
+	file.Status = entity.FileStatusUploaded
+	file.MerkleRoot = hex.EncodeToString(merkle.Root([][]byte{chunkDigest}))
+	chunk := entity.NewFileChunk(0, entity.FileStatusUploaded, 0, size, chunkPath)
+	chunk.Checksum = chunkChecksum
+	file.FileChunks = []entity.FileChunk{*chunk}
+
+	previousFile, releasedBlobs, err := uc.fileRepo.ReplaceFile(ctx, file)
+	if err != nil {
+		_ = uc.storageRepo.DeleteFile(ctx, chunkPath)
+		return nil, err
+	}
+	if blobPath := file.FileChunks[0].FilePath; blobPath != chunkPath {
+		if err := uc.storageRepo.MoveFile(ctx, chunkPath, blobPath); err != nil {
+			return nil, err
+		}
+	}
+	uc.deleteReplacedFile(ctx, previousFile, releasedBlobs)
+
+	return file, nil
+}
+
+// ExecuteCreateMultipart starts a multipart upload of a file, whose parts can be uploaded in any order. The upload is
+// an upload session whose unnamed file records the parts as its chunks, which are staged until the upload is completed,
+// so the upload survives a restart of the server and is swept along with its parts once it expires
+func (uc *fileUploadUseCase) ExecuteCreateMultipart(ctx context.Context, fileName string) (*entity.MultipartUpload, e.CustomError) {
+	if isReservedName(fileName) {
+		return nil, e.NewInvalidInputError(fmt.Errorf("%s is reserved", fileName), "invalid file name")
+	}
+
+	token, err := newSessionToken()
+	if err != nil {
+		return nil, err
+	}
+	session := entity.NewUploadSession(token, fileName, "", time.Now().Add(uc.sessionTTL))
+	session.Multipart = true
+	// the layout of the file is unknown until the upload is completed, and the file never becomes the uploaded one
+	file := entity.NewFile(fileName, 0, "", string(checksum.DefaultAlgorithm), 0, 0)
+	file.StorageCodec = uc.storageCodec
+	dirPath := uc.multipartDirPath(token)
+
+	session, err = uc.sessionRepo.CreateSession(ctx, session, file, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.storageRepo.CreateDirectory(ctx, dirPath); err != nil {
+		return nil, err
+	}
+
+	return newMultipartUpload(session, nil), nil
+}
+
+// ExecuteUploadPart stores a part of a multipart upload, replacing the part with the same number if any
+func (uc *fileUploadUseCase) ExecuteUploadPart(ctx context.Context, input FileUploadUseCaseExecuteUploadPartInput) (*entity.MultipartPart, e.CustomError) {
+	session, err := uc.getMultipartSession(ctx, input.UploadID, input.FileName)
+	if err != nil {
+		return nil, err
+	}
+
+	// every part is written to a new path, so the part being replaced stays intact for a completion which has read it
+	partPath, err := uc.newPartPath(input.UploadID, input.PartNumber)
+	if err != nil {
+		return nil, err
+	}
+	size, writtenChecksum, err := uc.storageRepo.WriteChunk(ctx, input.Reader, partPath, uc.storageCodec, input.Checksum)
+	if err != nil {
+		return nil, err
+	}
+	part := &entity.MultipartPart{
+		PartNumber: input.PartNumber,
+		Size:       size,
+		Checksum:   writtenChecksum,
+		FilePath:   partPath,
+	}
+
+	// the part is recorded even if the client disconnects, otherwise its content would be left behind
+	ctx = context.WithoutCancel(ctx)
+	replacedPart, err := uc.sessionRepo.SavePart(ctx, session, part)
+	if err != nil {
+		// the upload is completed or aborted while the part is written
+		_ = uc.storageRepo.DeleteFile(ctx, partPath)
+		return nil, err
+	}
+	if replacedPart != nil {
+		if err := uc.storageRepo.DeleteFile(ctx, replacedPart.FilePath); err != nil {
+			return nil, err
+		}
+	}
+	if err := uc.extendSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return part, nil
+}
+
+// ExecuteCompleteMultipart assembles the given parts of a multipart upload into a file, and returns the uploaded file.
+// Each part becomes a chunk of the file, so every part except the last one needs to have the same size, which is the
+// chunk size of the file. The parts which are not given are discarded. The file is uploaded in an upload session, so
+// the file with the name is replaced only once the assembled file is committed
+func (uc *fileUploadUseCase) ExecuteCompleteMultipart(ctx context.Context, input FileUploadUseCaseExecuteCompleteMultipartInput) (*entity.File, e.CustomError) {
+	upload, err := uc.getMultipartSession(ctx, input.UploadID, input.FileName)
+	if err != nil {
+		return nil, err
+	}
+	// the upload is claimed by moving its file to IN_PROGRESS, so that it's completed only once and none of its parts
+	// is replaced from here
+	isClaimed, err := uc.fileRepo.CompareAndUpdateFileStatus(ctx, upload.FileID, []entity.FileStatus{entity.FileStatusInitialized}, entity.FileStatusInProgress)
+	if err != nil {
+		return nil, err
+	}
+	if !isClaimed {
+		return nil, e.NewNotFoundError(fmt.Errorf("multipart upload %s of %s not found", input.UploadID, input.FileName), "")
+	}
+
+	partChunks, err := uc.fileRepo.GetChunksByFileID(ctx, upload.FileID)
+	var parts []*entity.MultipartPart
+	if err == nil {
+		parts, err = validateParts(newMultipartUpload(upload, partChunks), input.Parts, input.Limits)
+	}
+	if err != nil {
+		// the upload is released, so that it can be completed with the right parts
+		if releaseErr := uc.fileRepo.UpdateFileStatus(ctx, upload.FileID, entity.FileStatusInitialized); releaseErr != nil {
+			return nil, releaseErr
+		}
+		return nil, err
+	}
+
+	// the parts are moved into the file even if the client disconnects, and the upload is discarded with the rest of
+	// them at the end. The upload is extended, so that it isn't swept while its parts are moved
+	ctx = context.WithoutCancel(ctx)
+	defer func() {
+		_ = uc.discardSession(ctx, upload)
+	}()
+	if err := uc.extendSession(ctx, upload); err != nil {
+		return nil, err
+	}
+
+	var totalSize uint64
+	for _, part := range parts {
+		totalSize += part.Size
+	}
+	// the parts are moved into the chunks as they're stored, so the file has the codec the parts are written in
+	file := entity.NewFile(upload.TargetName, totalSize, "", string(checksum.DefaultAlgorithm), uint(len(parts)), parts[0].Size)
+	file.StorageCodec = upload.File.StorageCodec
+	session, err := uc.createSession(ctx, file, input.Owner, false)
+	if err != nil {
+		return nil, err
+	}
+	file = session.File
+	err = uc.assembleParts(ctx, file, parts)
+	if err == nil {
+		err = uc.verifyFile(ctx, session, file)
+	}
+	if err != nil {
+		// the parts are gone, so the file can't be completed by another request
+		if discardErr := uc.discardSession(ctx, session); discardErr != nil {
+			return nil, discardErr
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// ExecuteAbortMultipart aborts a multipart upload and deletes its parts
+func (uc *fileUploadUseCase) ExecuteAbortMultipart(ctx context.Context, uploadID string) e.CustomError {
+	upload, err := uc.getMultipartSession(ctx, uploadID, "")
+	if err != nil {
+		return err
+	}
+	// the upload being completed can't be aborted, and the one being aborted is aborted only once
+	isClaimed, err := uc.fileRepo.CompareAndUpdateFileStatus(ctx, upload.FileID, []entity.FileStatus{entity.FileStatusInitialized}, entity.FileStatusFailed)
+	if err != nil {
+		return err
+	}
+	if !isClaimed {
+		return e.NewNotFoundError(fmt.Errorf("multipart upload %s not found", uploadID), "")
+	}
+
+	return uc.discardSession(ctx, upload)
+}
+
+// assembleParts stores the parts as the chunks of the file, and moves the file to IN_PROGRESS
+func (uc *fileUploadUseCase) assembleParts(ctx context.Context, file *entity.File, parts []*entity.MultipartPart) e.CustomError {
+	chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
+	if err != nil {
+		return err
+	}
+	if len(chunks) != len(parts) {
+		return e.NewDatabaseError(
+			fmt.Errorf("expected %d chunks, but found %d", len(parts), len(chunks)),
+			fmt.Sprintf("chunk records of %s are inconsistent", file.Name),
+		)
+	}
+	if err := uc.fileRepo.UpdateFileStatus(ctx, file.ID, entity.FileStatusInProgress); err != nil {
+		return err
+	}
+
+	for i, chunk := range chunks {
+		chunk.Size = parts[i].Size
+		chunk.Checksum = parts[i].Checksum
+		if err := uc.storeChunk(ctx, chunk, parts[i].FilePath, file.StorageCodec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getMultipartSession returns the upload session of the multipart upload with its file. The upload needs to be created
+// for the file name unless the name is empty
+func (uc *fileUploadUseCase) getMultipartSession(ctx context.Context, uploadID string, fileName string) (*entity.UploadSession, e.CustomError) {
+	session, err := uc.sessionRepo.GetSessionByToken(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || !session.Multipart || session.File == nil || session.IsExpired(time.Now()) ||
+		(fileName != "" && session.TargetName != fileName) {
+		if fileName == "" {
+			return nil, e.NewNotFoundError(fmt.Errorf("multipart upload %s not found", uploadID), "")
+		}
+		return nil, e.NewNotFoundError(fmt.Errorf("multipart upload %s of %s not found", uploadID, fileName), "")
+	}
+
+	return session, nil
+}
+
+// multipartDirPath returns the path of the directory which keeps the parts of a multipart upload
+func (uc *fileUploadUseCase) multipartDirPath(uploadID string) string {
+	return filepath.Join(uc.baseStorageDir, storage.MultipartDirName, uploadID)
+}
+
+// newPartPath returns a new path in the directory of the multipart upload which a part is written to. The name is
+// random after the part number, so that a part never overwrites the one it replaces
+func (uc *fileUploadUseCase) newPartPath(uploadID string, partNumber uint64) (string, e.CustomError) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", e.NewFileStorageError(err, "failed to generate part name")
+	}
+
+	return filepath.Join(uc.multipartDirPath(uploadID), fmt.Sprintf("%d-%s", partNumber, hex.EncodeToString(suffix))), nil
+}
+
+// newMultipartUpload returns the multipart upload of the session whose parts are recorded as the chunks
+func newMultipartUpload(session *entity.UploadSession, chunks []*entity.FileChunk) *entity.MultipartUpload {
+	upload := &entity.MultipartUpload{
+		ID:        session.Token,
+		FileName:  session.TargetName,
+		Parts:     make(map[uint64]*entity.MultipartPart, len(chunks)),
+		CreatedAt: session.CreatedAt,
+	}
+	for _, chunk := range chunks {
+		upload.Parts[chunk.ChunkNumber] = &entity.MultipartPart{
+			PartNumber: chunk.ChunkNumber,
+			Size:       chunk.Size,
+			Checksum:   chunk.Checksum,
+			FilePath:   chunk.FilePath,
+		}
+	}
+
+	return upload
+}
+
+// validateParts returns the uploaded parts of the upload for the given ones, after validating that they make up a
+// file within the limits
+func validateParts(upload *entity.MultipartUpload, requestedParts []entity.MultipartPart, limits entity.UploadLimits) ([]*entity.MultipartPart, e.CustomError) {
+	if len(requestedParts) == 0 {
+		return nil, e.NewInvalidInputError(errors.New("no part is given"), "invalid part")
+	}
+
+	parts := make([]*entity.MultipartPart, len(requestedParts))
+	var totalSize uint64
+	for i, requestedPart := range requestedParts {
+		if i > 0 && requestedPart.PartNumber <= requestedParts[i-1].PartNumber {
+			return nil, e.NewInvalidInputError(errors.New("parts need to be in ascending order of their numbers"), "invalid part order")
+		}
+		part, ok := upload.Parts[requestedPart.PartNumber]
+		if !ok {
+			return nil, e.NewInvalidInputError(fmt.Errorf("part %d of %s is not uploaded", requestedPart.PartNumber, upload.FileName), "invalid part")
+		}
+		if requestedPart.Checksum != "" && !strings.EqualFold(requestedPart.Checksum, part.Checksum) {
+			return nil, e.NewInvalidInputError(
+				fmt.Errorf("part %d of %s has checksum %s, but got %s", part.PartNumber, upload.FileName, part.Checksum, requestedPart.Checksum),
+				"invalid part",
+			)
+		}
+		parts[i] = part
+		totalSize += part.Size
+	}
+
+	chunkSize := parts[0].Size
+	for i, part := range parts {
+		if part.Size == 0 || part.Size > chunkSize || (part.Size < chunkSize && i < len(parts)-1) {
+			return nil, e.NewInvalidInputError(
+				fmt.Errorf("part %d of %s is %d bytes, but every part except the last one needs to be %d bytes", part.PartNumber, upload.FileName, part.Size, chunkSize),
+				"invalid part",
+			)
+		}
+	}
+	if err := limits.Validate(totalSize, chunkSize, uint64(len(parts))); err != nil {
+		return nil, e.NewInvalidInputError(err, "invalid upload layout")
+	}
+
+	return parts, nil
+}
+
+// appendOffset returns the number of the bytes of the file stored so far by appending and the index of the chunk to be
+// appended next. The chunks are filled in order, so it's the end of the leading UPLOADED chunks and the partial chunk
+// after them.
+func (uc *fileUploadUseCase) appendOffset(ctx context.Context, session *entity.UploadSession, chunks []*entity.FileChunk) (uint64, int, e.CustomError) {
+	file := session.File
+	for i, chunk := range chunks {
+		if chunk.Status == entity.FileStatusUploaded {
+			continue
+		}
+		chunkOffset, _ := file.ChunkRange(chunk)
+		partialSize, err := uc.storageRepo.GetPartialChunkSize(ctx, uc.stagingChunkPath(session.Token, chunk.ChunkNumber))
+		if err != nil {
+			return 0, 0, err
+		}
+		return chunkOffset + partialSize, i, nil
+	}
+
+	return file.Size, len(chunks), nil
+}
+
+// discardAppend restores the partial chunks touched by an append, so that the offset of the file goes back to the
+// offset the append started at
+func (uc *fileUploadUseCase) discardAppend(ctx context.Context, session *entity.UploadSession, touchedChunks []*entity.FileChunk, startOffset uint64) e.CustomError {
+	for i, chunk := range touchedChunks {
+		stagingPath := uc.stagingChunkPath(session.Token, chunk.ChunkNumber)
+		if i == 0 {
+			// the first chunk may have had the content before the append
+			chunkOffset, _ := session.File.ChunkRange(chunk)
+			if _, err := uc.storageRepo.AppendPartialChunk(ctx, strings.NewReader(""), stagingPath, startOffset-chunkOffset); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := uc.storageRepo.DeleteFile(ctx, storage.PartialChunkPath(stagingPath)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// beginWrite registers a request writing the content of the upload session, and returns the channel which is closed
+// when the upload is aborted, along with the function to call once the request finishes. The request is rejected while
+// the upload is being aborted
+func (uc *fileUploadUseCase) beginWrite(sessionToken string) (<-chan struct{}, func(), e.CustomError) {
+	uc.writeMutex.Lock()
+	defer uc.writeMutex.Unlock()
+	writes, ok := uc.sessionWrites[sessionToken]
+	if !ok {
+		writes = newSessionWrites()
+		uc.sessionWrites[sessionToken] = writes
+	}
+	if writes.isAborted() {
+		return nil, nil, e.NewConflictError(errors.New("upload is being aborted"), "")
+	}
+	writes.writers++
+	writes.wg.Add(1)
+
+	return writes.aborted, func() {
+		uc.writeMutex.Lock()
+		defer uc.writeMutex.Unlock()
+		writes.writers--
+		writes.wg.Done()
+		if writes.writers == 0 && !writes.isAborted() {
+			delete(uc.sessionWrites, sessionToken)
+		}
+	}, nil
+}
+
+// abortWrites rejects the requests writing the content of the upload session from now on, and cancels the running
+// ones and waits for them to finish. The function returned lifts the rejection, which is called once the session is
+// deleted, since the requests for a deleted session are rejected anyway
+func (uc *fileUploadUseCase) abortWrites(sessionToken string) func() {
+	uc.writeMutex.Lock()
+	writes, ok := uc.sessionWrites[sessionToken]
+	if !ok {
+		writes = newSessionWrites()
+		uc.sessionWrites[sessionToken] = writes
+	}
+	if !writes.isAborted() {
+		close(writes.aborted)
+	}
+	uc.writeMutex.Unlock()
+
+	writes.wg.Wait()
+
+	return func() {
+		uc.writeMutex.Lock()
+		defer uc.writeMutex.Unlock()
+		if uc.sessionWrites[sessionToken] == writes {
+			delete(uc.sessionWrites, sessionToken)
+		}
+	}
+}
+
+func (uc *fileUploadUseCase) lockAppend(sessionToken string) bool {
+	uc.appendMutex.Lock()
+	defer uc.appendMutex.Unlock()
+	if _, ok := uc.appendingSessions[sessionToken]; ok {
+		return false
+	}
+	uc.appendingSessions[sessionToken] = struct{}{}
+	return true
+}
+
+func (uc *fileUploadUseCase) unlockAppend(sessionToken string) {
+	uc.appendMutex.Lock()
+	defer uc.appendMutex.Unlock()
+	delete(uc.appendingSessions, sessionToken)
+}
+
+// createSession creates an upload session of the file to be bound to its name, and the directory its chunks are
+// stored in. The chunks are stored under the token of the session, so the uploads of the same name don't collide
+func (uc *fileUploadUseCase) createSession(ctx context.Context, file *entity.File, owner string, openEnded bool) (*entity.UploadSession, e.CustomError) {
+	token, err := newSessionToken()
+	if err != nil {
+		return nil, err
+	}
+	session := entity.NewUploadSession(token, file.Name, owner, time.Now().Add(uc.sessionTTL))
+	session.OpenEnded = openEnded
+	dirPath := uc.sessionDirPath(session.Token)
+
+	session, err = uc.sessionRepo.CreateSession(ctx, session, file, dirPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.storageRepo.CreateDirectory(ctx, dirPath); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// newSessionToken returns a new random token of an upload session, which is also the ID of a multipart upload
+func newSessionToken() (string, e.CustomError) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", e.NewFileStorageError(err, "failed to generate session token")
+	}
+
+	return hex.EncodeToString(token), nil
+}
+
+// chooseStorageCodec returns the codec the chunks of a new file are stored in, which is the default one when the file
+// doesn't choose its own
+func (uc *fileUploadUseCase) chooseStorageCodec(codec entity.StorageCodec) (entity.StorageCodec, e.CustomError) {
+	if codec == "" {
+		return uc.storageCodec, nil
+	}
+	if !codec.IsValid() {
+		return "", e.NewInvalidInputError(fmt.Errorf("unsupported storage codec %q", codec), "invalid storage codec")
+	}
+
+	return codec, nil
+}
+
+// sessionDirPath returns the path of the directory which keeps the chunks of an upload session until they're stored
+func (uc *fileUploadUseCase) sessionDirPath(sessionToken string) string {
+	return filepath.Join(uc.baseStorageDir, storage.UploadsDirName, sessionToken)
+}
+
+// stagingChunkPath returns the path which the content of a chunk of an upload session is written to before it's
+// stored. The chunk is moved to its blob once it's complete, so the path isn't the one of the uploaded chunk
+func (uc *fileUploadUseCase) stagingChunkPath(sessionToken string, chunkNumber uint64) string {
+	return filepath.Join(uc.sessionDirPath(sessionToken), strconv.FormatUint(chunkNumber, 10))
+}
+
+// newBlobPath returns a new random path in the object area, which the content of a chunk is moved to before it's
+// stored as a blob. The path of a blob isn't derived from its content, since the file of a released blob is deleted
+// after the transaction releasing it, and the new blob of the same content stored in between would lose its file to
+// the deletion if they had the same path. The blobs table maps the content to the path instead
+func (uc *fileUploadUseCase) newBlobPath() (string, e.CustomError) {
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return "", e.NewFileStorageError(err, "failed to generate blob name")
+	}
+	blobName := hex.EncodeToString(name)
+
+	return filepath.Join(uc.baseStorageDir, storage.ObjectsDirName, blobName[:2], blobName), nil
+}
+
+// storeChunk stores the complete content of the chunk written at the path as the blob of its content in the codec,
+// and marks the chunk as UPLOADED. When the blob of the same content exists, the chunk shares it, and the content
+// replaces the one of the blob, which heals the blob if it's damaged. The blob the chunk referred to before is deleted
+// when it's left unreferenced
+func (uc *fileUploadUseCase) storeChunk(ctx context.Context, chunk *entity.FileChunk, filePath string, codec entity.StorageCodec) e.CustomError {
+	blobPath, err := uc.newBlobPath()
+	if err != nil {
+		return err
+	}
+	if err := uc.storageRepo.MoveFile(ctx, filePath, blobPath); err != nil {
+		return err
+	}
+
+	chunk.FilePath = blobPath
+	releasedBlobs, err := uc.fileRepo.CompleteChunk(ctx, chunk, codec)
+	if err != nil {
+		_ = uc.storageRepo.DeleteFile(ctx, blobPath)
+		return err
+	}
+	if chunk.FilePath != blobPath {
+		if err := uc.storageRepo.MoveFile(ctx, blobPath, chunk.FilePath); err != nil {
+			return err
+		}
+	}
+
+	return deleteBlobFiles(ctx, uc.storageRepo, releasedBlobs)
+}
+
+// getSession returns the upload session of the token with its file. The token is a credential of the upload, so it's
+// not included in the error. A multipart upload isn't uploaded by chunks, so its session isn't returned
+func (uc *fileUploadUseCase) getSession(ctx context.Context, sessionToken string) (*entity.UploadSession, e.CustomError) {
+	session, err := uc.sessionRepo.GetSessionByToken(ctx, sessionToken)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.Multipart || session.File == nil || session.IsExpired(time.Now()) {
+		return nil, e.NewNotFoundError(errors.New("upload session not found or expired"), "")
+	}
+
+	return session, nil
+}
+
+// extendSession extends the expiry of the session by the TTL from now, since the session is in use
+func (uc *fileUploadUseCase) extendSession(ctx context.Context, session *entity.UploadSession) e.CustomError {
+	expiresAt := time.Now().Add(uc.sessionTTL)
+	if err := uc.sessionRepo.ExtendSession(ctx, session.ID, expiresAt); err != nil {
+		return err
+	}
+	session.ExpiresAt = expiresAt
+
+	return nil
+}
+
+// commitSession binds the verified file of the session to the target name, and deletes the content of the file which
+// was bound to the name. The directory of the session is deleted unless a chunk is still stored in it, since all of
+// its content is stored in the blobs
+func (uc *fileUploadUseCase) commitSession(ctx context.Context, session *entity.UploadSession, chunks []*entity.FileChunk) e.CustomError {
+	previousFile, releasedBlobs, err := uc.sessionRepo.CommitSession(ctx, session)
+	if err != nil {
+		return err
+	}
+	if len(chunksStoredAlone(chunks)) == 0 {
+		_ = uc.storageRepo.DeleteDirectory(ctx, uc.sessionDirPath(session.Token))
+	}
+	uc.deleteReplacedFile(ctx, previousFile, releasedBlobs)
+
+	return nil
+}
+
+// deleteReplacedFile deletes the content of the file which was bound to the name of a new file, if any, which is the
+// blobs left unreferenced by the file and the chunks stored on their own. The new file is already readable by then, so
+// the content is deleted on a best-effort basis and the leftovers are found by fsck and the garbage collector
+func (uc *fileUploadUseCase) deleteReplacedFile(ctx context.Context, previousFile *entity.File, releasedBlobs []*entity.Blob) {
+	_ = deleteBlobFiles(ctx, uc.storageRepo, releasedBlobs)
+	if previousFile == nil {
+		return
+	}
+
+	chunks := make([]*entity.FileChunk, len(previousFile.FileChunks))
+	for i := range previousFile.FileChunks {
+		chunks[i] = &previousFile.FileChunks[i]
+	}
+	_ = deleteChunkDirectories(ctx, uc.storageRepo, chunksStoredAlone(chunks))
+}
+
+// discardSession deletes the file of the session along with its content, which also deletes the session. The chunks
+// stored on their own and the parts of a multipart upload are in the directory of the session, and the blobs are
+// deleted unless they're shared
+func (uc *fileUploadUseCase) discardSession(ctx context.Context, session *entity.UploadSession) e.CustomError {
+	releasedBlobs, err := uc.fileRepo.DeleteFileByID(ctx, session.FileID)
+	if err != nil {
+		return err
+	}
+	dirPath := uc.sessionDirPath(session.Token)
+	if session.Multipart {
+		dirPath = uc.multipartDirPath(session.Token)
+	}
+	if err := uc.storageRepo.DeleteDirectory(ctx, dirPath); err != nil {
+		return err
+	}
+
+	return deleteBlobFiles(ctx, uc.storageRepo, releasedBlobs)
+}
+
+// verifyFile recalculates the checksum of the file from the stored chunks, and marks the file as UPLOADED only when
+// it matches the checksum declared at the initialization. A file without the checksum is marked as UPLOADED with the
+// recalculated one, and the session of the file is committed. Since the last chunks can be uploaded concurrently, only
+// the request which moves the file to VERIFYING does the verification.
+func (uc *fileUploadUseCase) verifyFile(ctx context.Context, session *entity.UploadSession, file *entity.File) e.CustomError {
+	isVerifying, err := uc.fileRepo.CompareAndUpdateFileStatus(ctx, file.ID, []entity.FileStatus{
+		entity.FileStatusInProgress,
+		entity.FileStatusFailed,
+	}, entity.FileStatusVerifying)
+	if err != nil {
+		return err
+	}
+	if !isVerifying {
+		return nil
+	}
+
+	// the verification continues even if the client disconnects, otherwise the file would stay VERIFYING
+	ctx = context.WithoutCancel(ctx)
+
+	chunks, err := uc.fileRepo.GetChunksByFileID(ctx, file.ID)
+	if err != nil {
+		return err
+	}
+	actualChecksum, chunkDigests, err := uc.calculateChecksum(ctx, file, chunks)
+	if err != nil {
+		if updateErr := uc.fileRepo.UpdateFileStatus(ctx, file.ID, entity.FileStatusFailed); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+	if file.Checksum != "" && actualChecksum != file.Checksum {
+		if err := uc.fileRepo.UpdateFileStatus(ctx, file.ID, entity.FileStatusCorrupt); err != nil {
+			return err
+		}
+		return e.NewChecksumMismatchError(
+			fmt.Errorf("%s is declared, but the uploaded content has %s", file.Checksum, actualChecksum),
+			fmt.Sprintf("%s is corrupt", file.Name),
+		)
+	}
+
+	file.Checksum = actualChecksum
+	// the Merkle root lets the clients verify a part of the file without reading the whole file
+	file.MerkleRoot = hex.EncodeToString(merkle.Root(chunkDigests))
+	session.File = file
+	return uc.commitSession(ctx, session, chunks)
+}
+
+// calculateChecksum calculates the checksum of the file with its declared algorithm by reading its chunks in order.
+// It also returns the SHA-256 digests of the chunks, which are the leaves of the Merkle tree of the file
+func (uc *fileUploadUseCase) calculateChecksum(ctx context.Context, file *entity.File, chunks []*entity.FileChunk) (string, [][]byte, e.CustomError) {
+	if uint(len(chunks)) != file.TotalChunks {
+		return "", nil, e.NewDatabaseError(
+			fmt.Errorf("expected %d chunks, but found %d", file.TotalChunks, len(chunks)),
+			fmt.Sprintf("chunk records of %s are inconsistent", file.Name),
+		)
+	}
+
+	hash, hashErr := checksum.New(checksum.Algorithm(file.ChecksumAlgorithm))
+	if hashErr != nil {
+		return "", nil, e.NewDatabaseError(hashErr, fmt.Sprintf("checksum algorithm of %s is unknown", file.Name))
+	}
+	chunkDigests := make([][]byte, len(chunks))
+	for i, chunk := range chunks {
+		_, size := file.ChunkRange(chunk)
+		chunkHash := sha256.New()
+		if err := uc.storageRepo.ReadChunk(ctx, io.MultiWriter(hash, chunkHash), chunk.FilePath, file.StorageCodec, 0, size); err != nil {
+			return "", nil, err
+		}
+		chunkDigests[i] = chunkHash.Sum(nil)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), chunkDigests, nil
+}
+
+// isReservedName reports whether the name is the one of the directories which the storage keeps under the base
+// directory, which no file can have
+func isReservedName(fileName string) bool {
+	return fileName == storage.MultipartDirName || fileName == storage.UploadsDirName || fileName == storage.ObjectsDirName
+}
+
+// checkAbortable returns an error when the upload of the session can't be aborted, which is when its file is bound to
+// the name or being verified to be
+func checkAbortable(session *entity.UploadSession) e.CustomError {
+	if session.IsCommitted() {
+		return e.NewConflictError(fmt.Errorf("upload of %s is already committed", session.TargetName), "")
+	}
+	if session.File.Status == entity.FileStatusVerifying {
+		return e.NewConflictError(fmt.Errorf("upload of %s is being committed", session.TargetName), "")
+	}
+
+	return nil
+}
+
+// isChunkUploadable reports whether the content of the chunk can be uploaded. A failed chunk of an open-ended upload
+// can be sent again, while the one of the other uploads is made ready by a re-upload initialization
+func isChunkUploadable(session *entity.UploadSession, file *entity.File, chunk *entity.FileChunk) bool {
+	if session.OpenEnded {
+		return (file.Status == entity.FileStatusInitialized || file.Status == entity.FileStatusInProgress || file.Status == entity.FileStatusFailed) &&
+			(chunk.Status == entity.FileStatusInitialized || chunk.Status == entity.FileStatusFailed)
+	}
+	return (file.Status == entity.FileStatusInitialized || file.Status == entity.FileStatusInProgress) && chunk.Status == entity.FileStatusInitialized
+}
+
+// validateChunkLayout checks that the chunks of the upload cover its total size. Every chunk except the last one has the
+// declared chunk size unless the sizes of the chunks are given, so the number of chunks needs to be the one that covers
+// the total size. Otherwise, the sizes of the chunks can't be validated on their upload
+func validateChunkLayout(input FileUploadUseCaseExecuteInitInput) e.CustomError {
+	if input.ChunkChecksums != nil && uint(len(input.ChunkChecksums)) != input.TotalChunks {
+		return e.NewInvalidInputError(
+			fmt.Errorf("%d chunk checksums are given for %d chunks", len(input.ChunkChecksums), input.TotalChunks),
+			"invalid chunk layout",
+		)
+	}
+	if input.ChunkSizes != nil {
+		if uint(len(input.ChunkSizes)) != input.TotalChunks {
+			return e.NewInvalidInputError(
+				fmt.Errorf("%d chunk sizes are given for %d chunks", len(input.ChunkSizes), input.TotalChunks),
+				"invalid chunk layout",
+			)
+		}
+		// the limits don't matter here, only the sizes of the chunks
+		if err := (entity.UploadLimits{}).ValidateChunkSizes(input.TotalSize, input.ChunkSize, input.ChunkSizes); err != nil {
+			return e.NewInvalidInputError(err, "invalid chunk layout")
+		}
+		return nil
+	}
+
+	if input.ChunkSize == 0 || uint64(input.TotalChunks) != (input.TotalSize+input.ChunkSize-1)/input.ChunkSize {
+		return e.NewInvalidInputError(
+			fmt.Errorf("%d chunks of %d bytes don't make up %d bytes", input.TotalChunks, input.ChunkSize, input.TotalSize),
+			"invalid chunk layout",
+		)
+	}
+
+	return nil
+}
+
+// validateOpenEndedChunks validates that the chunks of an open-ended upload are all uploaded and make up the total
+// size, where every chunk except the last one has the chunk size. The chunks are ordered by their numbers
+func validateOpenEndedChunks(fileName string, chunkSize uint64, chunks []*entity.FileChunk, totalSize uint64) e.CustomError {
+	if len(chunks) == 0 {
+		return e.NewInvalidInputError(fmt.Errorf("no chunk of %s is uploaded", fileName), "invalid chunk layout")
+	}
+
+	var uploadedSize uint64
+	for i, chunk := range chunks {
+		if chunk.ChunkNumber != uint64(i) {
+			return e.NewInvalidInputError(fmt.Errorf("chunk %d of %s is not uploaded", i, fileName), "invalid chunk layout")
+		}
+		if chunk.Status != entity.FileStatusUploaded {
+			return e.NewInvalidInputError(fmt.Errorf("chunk %d of %s is in %s status", i, fileName, chunk.Status), "invalid chunk layout")
+		}
+		if chunk.Size < chunkSize && i < len(chunks)-1 {
+			return e.NewInvalidInputError(
+				fmt.Errorf("chunk %d of %s is %d bytes, but every chunk except the last one needs to be %d bytes", i, fileName, chunk.Size, chunkSize),
+				"invalid chunk layout",
+			)
+		}
+		uploadedSize += chunk.Size
+	}
+	if uploadedSize != totalSize {
+		return e.NewInvalidInputError(
+			fmt.Errorf("%d bytes of %s are uploaded, but the total size is %d bytes", uploadedSize, fileName, totalSize),
+			"invalid chunk layout",
+		)
+	}
+
+	return nil
+}
+
+// validatePutContent validates the size and the checksum of the content stored by a single request
+func validatePutContent(input FileUploadUseCaseExecutePutInput, size uint64, actualChecksum string) e.CustomError {
+	if size == 0 {
+		return e.NewInvalidInputError(errors.New("empty files are not supported"), "invalid content")
+	}
+	if input.MaxSize > 0 && size > input.MaxSize {
+		return e.NewInvalidInputError(
+			fmt.Errorf("content of %s exceeds %d bytes, which needs to be uploaded in chunks", input.FileName, input.MaxSize),
+			"invalid content",
+		)
+	}
+	if input.Checksum != "" && input.Checksum != actualChecksum {
+		return e.NewChecksumMismatchError(
+			fmt.Errorf("%s is declared, but the uploaded content has %s", input.Checksum, actualChecksum),
+			fmt.Sprintf("%s is corrupt", input.FileName),
+		)
+	}
+
+	return nil
+}
+
+// describeChunkSize describes the size of a received chunk. Since an oversized chunk is read only up to one byte more
+// than the expected size, its actual size is unknown
+func describeChunkSize(size uint64, expectedSize uint64) string {
+	if size > expectedSize {
+		return "more"
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
@@ -3,14 +3,19 @@ package usecase_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
 	"github.com/tomoya.tokunaga/server/internal/mock"
@@ -25,113 +30,202 @@ func TestFileUploadUseCase_ExecuteInit(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
 	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
 
 	baseStorageDir := "/test/uploads"
-	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockStorageRepo, baseStorageDir)
+	sessionTTL := time.Hour
+	defaultStorageCodec := entity.StorageCodecZstd
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, sessionTTL, defaultStorageCodec)
 
 	ctx := context.Background()
 	testFileName := "new_file.dat"
 	testFileID := uint64(1)
+	testOwner := "alice@host"
 	testTotalSize := uint64(2048)
 	testChecksum := "checksum123"
+	testChecksumAlgorithm := "sha256"
 	testTotalChunks := uint(2)
 	testChunkSize := uint64(1024)
-	fileDirPath := filepath.Join(baseStorageDir, testFileName)
 	now := time.Now()
 
 	// Available storage space
 	availableSpace := uint64(10 * 1024 * 1024) // 10MB
 
 	newFileInput := usecase.FileUploadUseCaseExecuteInitInput{
-		FileName:    testFileName,
-		Checksum:    testChecksum,
-		TotalSize:   testTotalSize,
-		TotalChunks: testTotalChunks,
-		ChunkSize:   testChunkSize,
-		IsReUpload:  false,
+		FileName:          testFileName,
+		Checksum:          testChecksum,
+		ChecksumAlgorithm: testChecksumAlgorithm,
+		TotalSize:         testTotalSize,
+		TotalChunks:       testTotalChunks,
+		ChunkSize:         testChunkSize,
+		Owner:             testOwner,
+		IsReUpload:        false,
 	}
+	reUploadInput := newFileInput
+	reUploadInput.IsReUpload = true
+	// the chunks of variable sizes are up to the chunk size
+	variableChunkSizes := []uint64{1000, 1024, 24}
+	variableChunksInput := newFileInput
+	variableChunksInput.TotalChunks = uint(len(variableChunkSizes))
+	variableChunksInput.ChunkSizes = variableChunkSizes
+	variableChunksReUploadInput := variableChunksInput
+	variableChunksReUploadInput.IsReUpload = true
 
 	// Input with size larger than available space
 	largeFileInput := usecase.FileUploadUseCaseExecuteInitInput{
-		FileName:    "large_file.dat",
-		Checksum:    testChecksum,
-		TotalSize:   availableSpace + 1, // Larger than available space
-		TotalChunks: testTotalChunks,
-		ChunkSize:   testChunkSize,
-		IsReUpload:  false,
-	}
-
-	newFileEntity := &entity.File{
-		ID:             testFileID,
-		Name:           testFileName,
-		Size:           testTotalSize,
-		Checksum:       testChecksum,
-		ChunkSize:      testChunkSize,
-		Status:         entity.FileStatusInitialized,
-		TotalChunks:    testTotalChunks,
-		UploadedChunks: 0,
-		CreatedAt:      now,
-		UpdatedAt:      now,
-	}
-
-	existingFileInitialized := &entity.File{
-		ID:        testFileID + 1,
-		Name:      "existing_init.dat",
-		Size:      testTotalSize,
-		Checksum:  testChecksum,
-		Status:    entity.FileStatusInitialized,
-		UpdatedAt: now.Add(-time.Hour),
-	}
-
-	existingFileInProgress := &entity.File{
-		ID:        testFileID + 2,
-		Name:      "existing_inprogress.dat",
-		Size:      testTotalSize,
-		Checksum:  testChecksum,
-		Status:    entity.FileStatusInProgress,
-		UpdatedAt: now.Add(-time.Hour),
-	}
-
-	existingFileUploaded := &entity.File{
-		ID:        testFileID + 3,
-		Name:      "existing_uploaded.dat",
-		Size:      testTotalSize,
-		Checksum:  testChecksum,
-		Status:    entity.FileStatusUploaded,
-		UpdatedAt: now.Add(-time.Hour),
-	}
-
-	existingFileDiffChecksum := &entity.File{
-		ID:        testFileID + 4,
-		Name:      "existing_diff.dat",
-		Size:      testTotalSize,
-		Checksum:  "different_checksum",
-		Status:    entity.FileStatusUploaded,
-		UpdatedAt: now.Add(-time.Hour),
+		FileName:          "large_file.dat",
+		Checksum:          testChecksum,
+		ChecksumAlgorithm: testChecksumAlgorithm,
+		TotalSize:         availableSpace + 1, // Larger than available space
+		TotalChunks:       testTotalChunks,
+		ChunkSize:         testChunkSize,
+		IsReUpload:        false,
 	}
 
+	newSession := &entity.UploadSession{
+		ID:         testFileID,
+		Token:      "new-token",
+		FileID:     testFileID,
+		TargetName: testFileName,
+		Owner:      testOwner,
+		ExpiresAt:  now.Add(sessionTTL),
+		File: &entity.File{
+			ID:                testFileID,
+			Name:              testFileName,
+			Size:              testTotalSize,
+			Checksum:          testChecksum,
+			ChecksumAlgorithm: testChecksumAlgorithm,
+			ChunkSize:         testChunkSize,
+			Status:            entity.FileStatusInitialized,
+			TotalChunks:       testTotalChunks,
+		},
+	}
+
+	// sessionOf returns an upload session of the file name started by the owner, whose file has the status
+	sessionOf := func(id uint64, owner string, expiresAt time.Time, status entity.FileStatus, checksum string) *entity.UploadSession {
+		return &entity.UploadSession{
+			ID:         id,
+			Token:      fmt.Sprintf("token-%d", id),
+			FileID:     id,
+			TargetName: testFileName,
+			Owner:      owner,
+			ExpiresAt:  expiresAt,
+			File: &entity.File{
+				ID:                id,
+				Name:              testFileName,
+				Size:              testTotalSize,
+				Checksum:          checksum,
+				ChecksumAlgorithm: testChecksumAlgorithm,
+				Status:            status,
+			},
+		}
+	}
+	resumableSession := sessionOf(2, testOwner, now.Add(time.Minute), entity.FileStatusInProgress, testChecksum)
+	// variableChunksSession returns a session whose file is split into the chunks of the sizes
+	variableChunksSession := func(id uint64, chunkSizes []uint64) (*entity.UploadSession, []*entity.FileChunk) {
+		session := sessionOf(id, testOwner, now.Add(time.Minute), entity.FileStatusInProgress, testChecksum)
+		session.File.VariableChunks = true
+		session.File.TotalChunks = uint(len(chunkSizes))
+		chunks := make([]*entity.FileChunk, len(chunkSizes))
+		var offset uint64
+		for i, size := range chunkSizes {
+			chunks[i] = &entity.FileChunk{ID: id*10 + uint64(i), ParentID: id, ChunkNumber: uint64(i), Offset: offset, Size: size, Status: entity.FileStatusUploaded}
+			offset += size
+		}
+		return session, chunks
+	}
+	sameLayoutSession, sameLayoutChunks := variableChunksSession(8, variableChunkSizes)
+	otherLayoutSession, otherLayoutChunks := variableChunksSession(9, []uint64{1024, 1000, 24})
+	// the sessions started later which can't be resumed
+	unresumableSessions := []*entity.UploadSession{
+		sessionOf(3, "bob@host", now.Add(time.Minute), entity.FileStatusInProgress, testChecksum),
+		sessionOf(4, testOwner, now.Add(-time.Minute), entity.FileStatusInProgress, testChecksum),
+		sessionOf(5, testOwner, now.Add(time.Minute), entity.FileStatusUploaded, testChecksum),
+		sessionOf(6, testOwner, now.Add(time.Minute), entity.FileStatusCorrupt, testChecksum),
+		sessionOf(7, testOwner, now.Add(time.Minute), entity.FileStatusInProgress, "different_checksum"),
+	}
+
+	// the chunk which was uploaded before it failed keeps its blob until it's uploaded again, so the content is staged
+	// in the directory of the session rather than at its path
+	stagingPaths := []string{
+		filepath.Join(baseStorageDir, ".uploads", resumableSession.Token, "0"),
+		filepath.Join(baseStorageDir, ".uploads", resumableSession.Token, "1"),
+	}
 	invalidChunks := []*entity.FileChunk{
-		{ID: 10, ParentID: existingFileInProgress.ID, ChunkNumber: 1, FilePath: filepath.Join(baseStorageDir, existingFileInProgress.Name, "chunk_1"), Status: entity.FileStatusInitialized},
-		{ID: 11, ParentID: existingFileInProgress.ID, ChunkNumber: 2, FilePath: filepath.Join(baseStorageDir, existingFileInProgress.Name, "chunk_2"), Status: entity.FileStatusFailed},
+		{ID: 10, ParentID: resumableSession.FileID, ChunkNumber: 0, FilePath: stagingPaths[0], Status: entity.FileStatusInitialized},
+		{ID: 11, ParentID: resumableSession.FileID, ChunkNumber: 1, FilePath: filepath.Join(baseStorageDir, ".objects", "5e", "5eb1"), BlobID: 5, Status: entity.FileStatusFailed},
 	}
 	chunkIDsToUpdate := []uint64{invalidChunks[1].ID} // Only the one with status FAILED needs update
 
 	dbError := e.NewDatabaseError(errors.New("db error"), "")
 	storageError := e.NewFileStorageError(errors.New("storage error"), "")
-	invalidInputErrorDiffContent := e.NewInvalidInputError(nil, fmt.Sprintf("%s with different content (including orphaned data) already exists", existingFileDiffChecksum.Name))
-	invalidInputErrorExists := e.NewInvalidInputError(nil, fmt.Sprintf("%s with same content(including orphaned data) already exists", existingFileUploaded.Name))
-	invalidInputErrorBadStatus := e.NewInvalidInputError(nil, fmt.Sprintf("existing %s is in %s status and cannot be re-uploaded", existingFileUploaded.Name, existingFileUploaded.Status))
+	unsupportedAlgorithmError := e.NewInvalidInputError(
+		fmt.Errorf("unsupported checksum algorithm \"md5\" (supported: [crc32c sha256 sha512 xxhash64])"),
+		"invalid checksum algorithm",
+	)
+	invalidChunkLayoutError := e.NewInvalidInputError(
+		fmt.Errorf("%d chunks of %d bytes don't make up %d bytes", testTotalChunks+1, testChunkSize, testTotalSize),
+		"invalid chunk layout",
+	)
 	insufficientSpaceError := e.NewFileStorageError(
 		fmt.Errorf("not enough space"),
 		fmt.Sprintf("File size of %s is %d bytes, but available space is %d bytes", largeFileInput.FileName, largeFileInput.TotalSize, availableSpace),
 	)
 
+	// expectNewSession expects a new session of the input to be created along with the directory of its chunks
+	expectNewSession := func(input usecase.FileUploadUseCaseExecuteInitInput, createErr e.CustomError, createDirErr e.CustomError) {
+		var sessionDirPath string
+		mockSessionRepo.EXPECT().CreateSession(ctx, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, session *entity.UploadSession, file *entity.File, dirPath string) (*entity.UploadSession, e.CustomError) {
+				assert.Len(t, session.Token, 64)
+				assert.Equal(t, input.FileName, session.TargetName)
+				assert.Equal(t, input.Owner, session.Owner)
+				assert.Equal(t, input.OpenEnded, session.OpenEnded)
+				assert.WithinDuration(t, time.Now().Add(sessionTTL), session.ExpiresAt, time.Minute)
+				assert.Equal(t, input.FileName, file.Name)
+				assert.Equal(t, input.TotalSize, file.Size)
+				assert.Equal(t, input.Checksum, file.Checksum)
+				assert.Equal(t, input.ChecksumAlgorithm, file.ChecksumAlgorithm)
+				assert.Equal(t, input.TotalChunks, file.TotalChunks)
+				assert.Equal(t, input.ChunkSize, file.ChunkSize)
+				assert.Equal(t, input.ChunkSizes != nil, file.VariableChunks)
+				if input.ChunkSizes != nil {
+					// the chunks are created with their offsets and sizes
+					assert.Equal(t, []entity.FileChunk{
+						{ChunkNumber: 0, Offset: 0, Size: 1000},
+						{ChunkNumber: 1, Offset: 1000, Size: 1024},
+						{ChunkNumber: 2, Offset: 2024, Size: 24},
+					}, file.FileChunks)
+				}
+				expectedStorageCodec := input.StorageCodec
+				if expectedStorageCodec == "" {
+					expectedStorageCodec = defaultStorageCodec
+				}
+				assert.Equal(t, expectedStorageCodec, file.StorageCodec)
+				// the chunks are stored under the token, so that the uploads of the same name don't collide
+				assert.Equal(t, filepath.Join(baseStorageDir, ".uploads", session.Token), dirPath)
+				sessionDirPath = dirPath
+				if createErr != nil {
+					return nil, createErr
+				}
+				return newSession, nil
+			},
+		)
+		if createErr != nil {
+			return
+		}
+		mockStorageRepo.EXPECT().CreateDirectory(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, dirPath string) e.CustomError {
+			assert.Equal(t, sessionDirPath, dirPath)
+			return createDirErr
+		})
+	}
+
 	tests := []struct {
 		name                  string
 		input                 usecase.FileUploadUseCaseExecuteInitInput
 		setupMocks            func()
-		expectedFile          *entity.File
+		expectedSession       *entity.UploadSession
 		expectedInvalidChunks []*entity.FileChunk
 		expectedErr           e.CustomError
 	}{
@@ -140,185 +234,348 @@ func TestFileUploadUseCase_ExecuteInit(t *testing.T) {
 			input: newFileInput,
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
-				mockFileRepo.EXPECT().GetFileByName(ctx, newFileInput.FileName).Return(nil, nil)
-				mockFileRepo.EXPECT().CreateFileWithChunks(ctx, gomock.Any(), baseStorageDir).DoAndReturn(
-					func(ctx context.Context, file *entity.File, baseDir string) (*entity.File, e.CustomError) {
-						assert.Equal(t, newFileInput.FileName, file.Name)
-						assert.Equal(t, newFileInput.TotalSize, file.Size)
-						assert.Equal(t, newFileInput.Checksum, file.Checksum)
-						assert.Equal(t, newFileInput.TotalChunks, file.TotalChunks)
-						assert.Equal(t, newFileInput.ChunkSize, file.ChunkSize)
-						return newFileEntity, nil
-					},
-				)
-				mockStorageRepo.EXPECT().CreateDirectory(ctx, fileDirPath).Return(nil)
+				expectNewSession(newFileInput, nil, nil)
 			},
-			expectedFile:          newFileEntity,
+			expectedSession:       newSession,
 			expectedInvalidChunks: nil,
 			expectedErr:           nil,
 		},
 		{
-			name:  "Error - Insufficient Storage Space",
-			input: largeFileInput,
+			name: "Success - Open-Ended File",
+			input: usecase.FileUploadUseCaseExecuteInitInput{
+				FileName: testFileName, ChecksumAlgorithm: testChecksumAlgorithm, ChunkSize: testChunkSize, Owner: testOwner, OpenEnded: true,
+			},
+			setupMocks: func() {
+				// the size is unknown, so neither the space nor the chunk layout is checked
+				expectNewSession(usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: testFileName, ChecksumAlgorithm: testChecksumAlgorithm, ChunkSize: testChunkSize, Owner: testOwner, OpenEnded: true,
+				}, nil, nil)
+			},
+			expectedSession:       newSession,
+			expectedInvalidChunks: nil,
+			expectedErr:           nil,
+		},
+		{
+			name: "Success - New File with Storage Codec",
+			input: usecase.FileUploadUseCaseExecuteInitInput{
+				FileName: testFileName, Checksum: testChecksum, ChecksumAlgorithm: testChecksumAlgorithm, TotalSize: testTotalSize, TotalChunks: testTotalChunks, ChunkSize: testChunkSize, StorageCodec: entity.StorageCodecGzip,
+			},
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
+				expectNewSession(usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: testFileName, Checksum: testChecksum, ChecksumAlgorithm: testChecksumAlgorithm, TotalSize: testTotalSize, TotalChunks: testTotalChunks, ChunkSize: testChunkSize, StorageCodec: entity.StorageCodecGzip,
+				}, nil, nil)
 			},
-			expectedFile:          nil,
+			expectedSession:       newSession,
 			expectedInvalidChunks: nil,
-			expectedErr:           insufficientSpaceError,
+			expectedErr:           nil,
 		},
 		{
-			name:  "Error - New File - CreateFileWithChunks DB Error",
-			input: newFileInput,
+			name: "Error - Unsupported Storage Codec",
+			input: usecase.FileUploadUseCaseExecuteInitInput{
+				FileName: testFileName, Checksum: testChecksum, ChecksumAlgorithm: testChecksumAlgorithm, TotalSize: testTotalSize, TotalChunks: testTotalChunks, ChunkSize: testChunkSize, StorageCodec: "brotli",
+			},
+			setupMocks:            func() {},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           e.NewInvalidInputError(fmt.Errorf("unsupported storage codec \"brotli\""), "invalid storage codec"),
+		},
+		{
+			name: "Error - Open-Ended File without Chunk Size",
+			input: usecase.FileUploadUseCaseExecuteInitInput{
+				FileName: testFileName, ChecksumAlgorithm: testChecksumAlgorithm, Owner: testOwner, OpenEnded: true,
+			},
+			setupMocks:            func() {},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           e.NewInvalidInputError(errors.New("chunk size must be greater than 0"), "invalid chunk layout"),
+		},
+		{
+			name: "Error - New File (Inconsistent Chunk Layout)",
+			input: usecase.FileUploadUseCaseExecuteInitInput{
+				FileName: testFileName, Checksum: testChecksum, ChecksumAlgorithm: testChecksumAlgorithm, TotalSize: testTotalSize, TotalChunks: testTotalChunks + 1, ChunkSize: testChunkSize,
+			},
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
-				mockFileRepo.EXPECT().GetFileByName(ctx, newFileInput.FileName).Return(nil, nil)
-				mockFileRepo.EXPECT().CreateFileWithChunks(ctx, gomock.Any(), baseStorageDir).Return(nil, dbError)
 			},
-			expectedFile:          nil,
+			expectedSession:       nil,
 			expectedInvalidChunks: nil,
-			expectedErr:           dbError,
+			expectedErr:           invalidChunkLayoutError,
 		},
 		{
-			name:  "Error - New File - CreateDirectory Storage Error",
-			input: newFileInput,
+			name:  "Success - New File with Variable Chunk Sizes",
+			input: variableChunksInput,
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
-				mockFileRepo.EXPECT().GetFileByName(ctx, newFileInput.FileName).Return(nil, nil)
-				mockFileRepo.EXPECT().CreateFileWithChunks(ctx, gomock.Any(), baseStorageDir).Return(newFileEntity, nil)
-				mockStorageRepo.EXPECT().CreateDirectory(ctx, fileDirPath).Return(storageError)
+				expectNewSession(variableChunksInput, nil, nil)
 			},
-			expectedFile:          nil,
+			expectedSession:       newSession,
 			expectedInvalidChunks: nil,
-			expectedErr:           storageError,
+			expectedErr:           nil,
 		},
 		{
-			name: "Success - Existing File (INITIALIZED status)",
+			name: "Error - New File (Variable Chunk Sizes Short Of Total Size)",
 			input: usecase.FileUploadUseCaseExecuteInitInput{
-				FileName: existingFileInitialized.Name, Checksum: existingFileInitialized.Checksum, TotalSize: existingFileInitialized.Size, // Match existing
+				FileName: testFileName, Checksum: testChecksum, ChecksumAlgorithm: testChecksumAlgorithm, TotalSize: testTotalSize, TotalChunks: 2, ChunkSize: testChunkSize, ChunkSizes: []uint64{1024, 1000},
 			},
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
-				mockFileRepo.EXPECT().GetFileByName(ctx, existingFileInitialized.Name).Return(existingFileInitialized, nil)
-				// Directory creation check is still performed
-				mockStorageRepo.EXPECT().CreateDirectory(ctx, filepath.Join(baseStorageDir, existingFileInitialized.Name)).Return(nil)
 			},
-			expectedFile:          existingFileInitialized,
+			expectedSession:       nil,
 			expectedInvalidChunks: nil,
-			expectedErr:           nil,
+			expectedErr:           e.NewInvalidInputError(fmt.Errorf("2 chunks make up 2024 bytes, but the file has 2048 bytes"), "invalid chunk layout"),
 		},
 		{
-			name: "Error - Existing File (Different Content)",
+			name: "Error - New File (Variable Chunk Sizes Mismatch Chunk Count)",
 			input: usecase.FileUploadUseCaseExecuteInitInput{
-				FileName: existingFileDiffChecksum.Name, Checksum: "new_checksum", TotalSize: existingFileDiffChecksum.Size, // Different checksum
+				FileName: testFileName, Checksum: testChecksum, ChecksumAlgorithm: testChecksumAlgorithm, TotalSize: testTotalSize, TotalChunks: 3, ChunkSize: testChunkSize, ChunkSizes: []uint64{1024, 1024},
 			},
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
-				mockFileRepo.EXPECT().GetFileByName(ctx, existingFileDiffChecksum.Name).Return(existingFileDiffChecksum, nil)
 			},
-			expectedFile:          nil,
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           e.NewInvalidInputError(fmt.Errorf("2 chunk sizes are given for 3 chunks"), "invalid chunk layout"),
+		},
+		{
+			name: "Error - Unsupported Checksum Algorithm",
+			input: usecase.FileUploadUseCaseExecuteInitInput{
+				FileName: testFileName, Checksum: testChecksum, ChecksumAlgorithm: "md5", TotalSize: testTotalSize, TotalChunks: testTotalChunks, ChunkSize: testChunkSize,
+			},
+			setupMocks:            func() {},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           unsupportedAlgorithmError,
+		},
+		{
+			name: "Error - Reserved File Name (Multipart)",
+			input: usecase.FileUploadUseCaseExecuteInitInput{
+				FileName: ".multipart", Checksum: testChecksum, ChecksumAlgorithm: testChecksumAlgorithm, TotalSize: testTotalSize, TotalChunks: testTotalChunks, ChunkSize: testChunkSize,
+			},
+			setupMocks:            func() {},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           e.NewInvalidInputError(fmt.Errorf(".multipart is reserved"), "invalid file name"),
+		},
+		{
+			name: "Error - Reserved File Name (Uploads)",
+			input: usecase.FileUploadUseCaseExecuteInitInput{
+				FileName: ".uploads", Checksum: testChecksum, ChecksumAlgorithm: testChecksumAlgorithm, TotalSize: testTotalSize, TotalChunks: testTotalChunks, ChunkSize: testChunkSize,
+			},
+			setupMocks:            func() {},
+			expectedSession:       nil,
 			expectedInvalidChunks: nil,
-			expectedErr:           invalidInputErrorDiffContent,
+			expectedErr:           e.NewInvalidInputError(fmt.Errorf(".uploads is reserved"), "invalid file name"),
 		},
 		{
-			name: "Error - Existing File (UPLOADED status, IsReUpload=false)",
+			name: "Error - Reserved File Name (Objects)",
 			input: usecase.FileUploadUseCaseExecuteInitInput{
-				FileName: existingFileUploaded.Name, Checksum: existingFileUploaded.Checksum, TotalSize: existingFileUploaded.Size, IsReUpload: false, // Match existing, no re-upload
+				FileName: ".objects", Checksum: testChecksum, ChecksumAlgorithm: testChecksumAlgorithm, TotalSize: testTotalSize, TotalChunks: testTotalChunks, ChunkSize: testChunkSize,
 			},
+			setupMocks:            func() {},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           e.NewInvalidInputError(fmt.Errorf(".objects is reserved"), "invalid file name"),
+		},
+		{
+			name:  "Error - Insufficient Storage Space",
+			input: largeFileInput,
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
+			},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           insufficientSpaceError,
+		},
+		{
+			name:  "Error - New File - CreateSession DB Error",
+			input: newFileInput,
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
+				expectNewSession(newFileInput, dbError, nil)
+			},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           dbError,
+		},
+		{
+			name:  "Error - New File - CreateDirectory Storage Error",
+			input: newFileInput,
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
+				expectNewSession(newFileInput, nil, storageError)
+			},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           storageError,
+		},
+		{
+			name:  "Success - ReUpload Without Resumable Session (New Session)",
+			input: reUploadInput,
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
-				mockFileRepo.EXPECT().GetFileByName(ctx, existingFileUploaded.Name).Return(existingFileUploaded, nil)
+				mockSessionRepo.EXPECT().GetSessionsByTargetName(ctx, testFileName).Return(unresumableSessions, nil)
+				expectNewSession(reUploadInput, nil, nil)
 			},
-			expectedFile:          nil,
+			expectedSession:       newSession,
 			expectedInvalidChunks: nil,
-			expectedErr:           invalidInputErrorExists,
+			expectedErr:           nil,
 		},
 		{
-			name: "Success - Existing File (IN_PROGRESS status, IsReUpload=true)",
+			name: "Success - ReUpload Without Checksum (New Session)",
 			input: usecase.FileUploadUseCaseExecuteInitInput{
-				FileName: existingFileInProgress.Name, Checksum: existingFileInProgress.Checksum, TotalSize: existingFileInProgress.Size, IsReUpload: true, // Match existing, re-upload
+				FileName: testFileName, ChecksumAlgorithm: testChecksumAlgorithm, TotalSize: testTotalSize, TotalChunks: testTotalChunks, ChunkSize: testChunkSize, Owner: testOwner, IsReUpload: true,
+			},
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
+				expectNewSession(usecase.FileUploadUseCaseExecuteInitInput{
+					FileName: testFileName, ChecksumAlgorithm: testChecksumAlgorithm, TotalSize: testTotalSize, TotalChunks: testTotalChunks, ChunkSize: testChunkSize, Owner: testOwner,
+				}, nil, nil)
 			},
+			expectedSession:       newSession,
+			expectedInvalidChunks: nil,
+			expectedErr:           nil,
+		},
+		{
+			name:  "Success - ReUpload Resumes Session Of Owner",
+			input: reUploadInput,
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
-				mockFileRepo.EXPECT().GetFileByName(ctx, existingFileInProgress.Name).Return(existingFileInProgress, nil)
-				mockFileRepo.EXPECT().GetChunksByStatus(ctx, existingFileInProgress.ID, []entity.FileStatus{entity.FileStatusInitialized, entity.FileStatusInProgress, entity.FileStatusFailed}).Return(invalidChunks, nil)
+				mockSessionRepo.EXPECT().GetSessionsByTargetName(ctx, testFileName).Return(append([]*entity.UploadSession{resumableSession}, unresumableSessions...), nil)
+				mockFileRepo.EXPECT().GetChunksByStatus(ctx, resumableSession.FileID, []entity.FileStatus{entity.FileStatusInitialized, entity.FileStatusInProgress, entity.FileStatusFailed}).Return(invalidChunks, nil)
 				// Expect deletion for both invalid chunks
-				mockStorageRepo.EXPECT().DeleteFile(ctx, invalidChunks[0].FilePath).Return(nil)
-				mockStorageRepo.EXPECT().DeleteFile(ctx, invalidChunks[1].FilePath).Return(nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, stagingPaths[0]).Return(nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, stagingPaths[1]).Return(nil)
+				// Expect the content received so far to be looked up for both invalid chunks
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, stagingPaths[0]).Return(uint64(512), nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, stagingPaths[1]).Return(uint64(0), nil)
 				// Expect status update only for the chunk that wasn't INITIALIZED, using UpdateFileAndChunkStatus
-				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, existingFileInProgress.ID, chunkIDsToUpdate, entity.FileStatusInitialized).Return(nil)
-				// Expect UpdateAvailableSpace to be called
-				mockStorageRepo.EXPECT().UpdateAvailableSpace(int64(existingFileInProgress.Size))
+				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, resumableSession.FileID, chunkIDsToUpdate, entity.FileStatusInitialized).Return(nil)
+				mockSessionRepo.EXPECT().ExtendSession(ctx, resumableSession.ID, gomock.Any()).Return(nil)
 			},
-			expectedFile:          existingFileInProgress,
+			expectedSession:       resumableSession,
 			expectedInvalidChunks: invalidChunks,
 			expectedErr:           nil,
 		},
 		{
-			name: "Error - Existing File (UPLOADED status, IsReUpload=true)",
-			input: usecase.FileUploadUseCaseExecuteInitInput{
-				FileName: existingFileUploaded.Name, Checksum: existingFileUploaded.Checksum, TotalSize: existingFileUploaded.Size, IsReUpload: true, // Match existing, re-upload
+			name:  "Success - ReUpload With Variable Chunk Sizes Resumes Session Of Same Layout",
+			input: variableChunksReUploadInput,
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
+				mockSessionRepo.EXPECT().GetSessionsByTargetName(ctx, testFileName).Return([]*entity.UploadSession{sameLayoutSession}, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, sameLayoutSession.FileID).Return(sameLayoutChunks, nil)
+				mockFileRepo.EXPECT().GetChunksByStatus(ctx, sameLayoutSession.FileID, gomock.Any()).Return([]*entity.FileChunk{}, nil)
+				mockSessionRepo.EXPECT().ExtendSession(ctx, sameLayoutSession.ID, gomock.Any()).Return(nil)
 			},
+			expectedSession:       sameLayoutSession,
+			expectedInvalidChunks: []*entity.FileChunk{},
+			expectedErr:           nil,
+		},
+		{
+			name:  "Success - ReUpload With Variable Chunk Sizes Skips Sessions Of Other Layouts (New Session)",
+			input: variableChunksReUploadInput,
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
-				mockFileRepo.EXPECT().GetFileByName(ctx, existingFileUploaded.Name).Return(existingFileUploaded, nil)
+				// the session of the fixed chunk size is skipped without reading its chunks
+				mockSessionRepo.EXPECT().GetSessionsByTargetName(ctx, testFileName).Return([]*entity.UploadSession{resumableSession, otherLayoutSession}, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, otherLayoutSession.FileID).Return(otherLayoutChunks, nil)
+				expectNewSession(variableChunksReUploadInput, nil, nil)
 			},
-			expectedFile:          nil,
+			expectedSession:       newSession,
 			expectedInvalidChunks: nil,
-			expectedErr:           invalidInputErrorBadStatus,
+			expectedErr:           nil,
 		},
 		{
-			name: "Error - ReUpload - GetChunksByStatus DB Error",
-			input: usecase.FileUploadUseCaseExecuteInitInput{
-				FileName:   existingFileInProgress.Name,
-				TotalSize:  existingFileInProgress.Size,
-				Checksum:   existingFileInProgress.Checksum,
-				IsReUpload: true,
+			name:  "Error - ReUpload With Variable Chunk Sizes - GetChunksByFileID DB Error",
+			input: variableChunksReUploadInput,
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
+				mockSessionRepo.EXPECT().GetSessionsByTargetName(ctx, testFileName).Return([]*entity.UploadSession{sameLayoutSession}, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, sameLayoutSession.FileID).Return(nil, dbError)
 			},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           dbError,
+		},
+		{
+			name:  "Error - ReUpload - GetSessionsByTargetName DB Error",
+			input: reUploadInput,
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
-				mockFileRepo.EXPECT().GetFileByName(ctx, existingFileInProgress.Name).Return(existingFileInProgress, nil)
-				mockFileRepo.EXPECT().GetChunksByStatus(ctx, existingFileInProgress.ID, gomock.Any()).Return(nil, dbError)
+				mockSessionRepo.EXPECT().GetSessionsByTargetName(ctx, testFileName).Return(nil, dbError)
 			},
-			expectedFile:          nil,
+			expectedSession:       nil,
 			expectedInvalidChunks: nil,
 			expectedErr:           dbError,
 		},
 		{
-			name: "Error - ReUpload - DeleteFile Storage Error",
-			input: usecase.FileUploadUseCaseExecuteInitInput{
-				FileName:   existingFileInProgress.Name,
-				TotalSize:  existingFileInProgress.Size,
-				Checksum:   existingFileInProgress.Checksum,
-				IsReUpload: true,
+			name:  "Error - ReUpload - GetChunksByStatus DB Error",
+			input: reUploadInput,
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
+				mockSessionRepo.EXPECT().GetSessionsByTargetName(ctx, testFileName).Return([]*entity.UploadSession{resumableSession}, nil)
+				mockFileRepo.EXPECT().GetChunksByStatus(ctx, resumableSession.FileID, gomock.Any()).Return(nil, dbError)
 			},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           dbError,
+		},
+		{
+			name:  "Error - ReUpload - DeleteFile Storage Error",
+			input: reUploadInput,
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
-				mockFileRepo.EXPECT().GetFileByName(ctx, existingFileInProgress.Name).Return(existingFileInProgress, nil)
-				mockFileRepo.EXPECT().GetChunksByStatus(ctx, existingFileInProgress.ID, gomock.Any()).Return(invalidChunks, nil)
-				mockStorageRepo.EXPECT().DeleteFile(ctx, invalidChunks[0].FilePath).Return(storageError)
+				mockSessionRepo.EXPECT().GetSessionsByTargetName(ctx, testFileName).Return([]*entity.UploadSession{resumableSession}, nil)
+				mockFileRepo.EXPECT().GetChunksByStatus(ctx, resumableSession.FileID, gomock.Any()).Return(invalidChunks, nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, stagingPaths[0]).Return(storageError)
 				// No need to mock the second DeleteFile or UpdateChunksStatus as it errors out
 			},
-			expectedFile:          nil,
+			expectedSession:       nil,
 			expectedInvalidChunks: nil,
 			expectedErr:           storageError,
 		},
 		{
-			name: "Error - ReUpload - UpdateChunksStatus DB Error",
-			input: usecase.FileUploadUseCaseExecuteInitInput{
-				FileName:   existingFileInProgress.Name,
-				TotalSize:  existingFileInProgress.Size,
-				Checksum:   existingFileInProgress.Checksum,
-				IsReUpload: true,
+			name:  "Error - ReUpload - GetPartialChunkSize Storage Error",
+			input: reUploadInput,
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
+				mockSessionRepo.EXPECT().GetSessionsByTargetName(ctx, testFileName).Return([]*entity.UploadSession{resumableSession}, nil)
+				mockFileRepo.EXPECT().GetChunksByStatus(ctx, resumableSession.FileID, gomock.Any()).Return(invalidChunks, nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, stagingPaths[0]).Return(nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, stagingPaths[0]).Return(uint64(0), storageError)
+			},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           storageError,
+		},
+		{
+			name:  "Error - ReUpload - UpdateFileAndChunkStatus DB Error",
+			input: reUploadInput,
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
+				mockSessionRepo.EXPECT().GetSessionsByTargetName(ctx, testFileName).Return([]*entity.UploadSession{resumableSession}, nil)
+				mockFileRepo.EXPECT().GetChunksByStatus(ctx, resumableSession.FileID, gomock.Any()).Return(invalidChunks, nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, gomock.Any()).Return(nil).Times(len(invalidChunks))
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, gomock.Any()).Return(uint64(0), nil).Times(len(invalidChunks))
+				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, resumableSession.FileID, chunkIDsToUpdate, entity.FileStatusInitialized).Return(dbError)
 			},
+			expectedSession:       nil,
+			expectedInvalidChunks: nil,
+			expectedErr:           dbError,
+		},
+		{
+			name:  "Error - ReUpload - ExtendSession DB Error",
+			input: reUploadInput,
 			setupMocks: func() {
 				mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(availableSpace)
-				mockFileRepo.EXPECT().GetFileByName(ctx, existingFileInProgress.Name).Return(existingFileInProgress, nil)
-				mockFileRepo.EXPECT().GetChunksByStatus(ctx, existingFileInProgress.ID, gomock.Any()).Return(invalidChunks, nil)
+				mockSessionRepo.EXPECT().GetSessionsByTargetName(ctx, testFileName).Return([]*entity.UploadSession{resumableSession}, nil)
+				mockFileRepo.EXPECT().GetChunksByStatus(ctx, resumableSession.FileID, gomock.Any()).Return(invalidChunks, nil)
 				mockStorageRepo.EXPECT().DeleteFile(ctx, gomock.Any()).Return(nil).Times(len(invalidChunks))
-				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, existingFileInProgress.ID, chunkIDsToUpdate, entity.FileStatusInitialized).Return(dbError)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, gomock.Any()).Return(uint64(0), nil).Times(len(invalidChunks))
+				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, resumableSession.FileID, chunkIDsToUpdate, entity.FileStatusInitialized).Return(nil)
+				mockSessionRepo.EXPECT().ExtendSession(ctx, resumableSession.ID, gomock.Any()).Return(dbError)
 			},
-			expectedFile:          nil,
+			expectedSession:       nil,
 			expectedInvalidChunks: nil,
 			expectedErr:           dbError,
 		},
@@ -327,9 +584,9 @@ func TestFileUploadUseCase_ExecuteInit(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
-			file, invalid, err := uc.ExecuteInit(ctx, tc.input)
+			session, invalid, err := uc.ExecuteInit(ctx, tc.input)
 
-			assert.Equal(t, tc.expectedFile, file)
+			assert.Equal(t, tc.expectedSession, session)
 			assert.Equal(t, tc.expectedInvalidChunks, invalid)
 
 			if tc.expectedErr == nil {
@@ -343,6 +600,224 @@ func TestFileUploadUseCase_ExecuteInit(t *testing.T) {
 	}
 }
 
+func TestFileUploadUseCase_ExecuteInit_ReuseBaseChunks(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	baseStorageDir := "/test/uploads"
+	testFileName := "changed_file.dat"
+	testFileID := uint64(1)
+	baseFileID := uint64(7)
+	codec := entity.StorageCodecZstd
+	blobPaths := []string{"/test/uploads/.objects/70/70b1", "/test/uploads/.objects/71/71b1"}
+	helloChecksum := fmt.Sprintf("%x", sha256.Sum256([]byte("hello")))
+
+	// the base file is the previous version of the file bound to the name, whose chunks are stored in the blobs
+	baseFile := &entity.File{ID: baseFileID, Name: testFileName, Status: entity.FileStatusUploaded, StorageCodec: codec}
+	baseChunks := []*entity.FileChunk{
+		{ID: 70, ParentID: baseFileID, ChunkNumber: 0, Size: 1024, Checksum: "c0", BlobID: 70, FilePath: blobPaths[0], Status: entity.FileStatusUploaded},
+		{ID: 71, ParentID: baseFileID, ChunkNumber: 1, Size: 1024, Checksum: "c1", BlobID: 71, FilePath: blobPaths[1], Status: entity.FileStatusUploaded},
+	}
+
+	// the new version has 3 chunks of 1024, 1024 and 512 bytes
+	deltaInput := usecase.FileUploadUseCaseExecuteInitInput{
+		FileName:          testFileName,
+		Checksum:          "checksum123",
+		ChecksumAlgorithm: "sha256",
+		TotalSize:         2560,
+		TotalChunks:       3,
+		ChunkSize:         1024,
+		ChunkChecksums:    []string{"c1", "cx", "c0"},
+		BaseFileID:        baseFileID,
+		StorageCodec:      codec,
+	}
+	newSession := &entity.UploadSession{
+		ID:         testFileID,
+		Token:      "delta-token",
+		FileID:     testFileID,
+		TargetName: testFileName,
+		File:       &entity.File{ID: testFileID, Size: 2560, ChunkSize: 1024, TotalChunks: 3, Status: entity.FileStatusInProgress},
+	}
+
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+
+	// expectNewSession expects a new session to be created with the file of the status and the chunks
+	expectNewSession := func(mockSessionRepo *mock.MockUploadSessionRepository, mockStorageRepo *mock.MockFileStorageRepository, session *entity.UploadSession, status entity.FileStatus, chunks []entity.FileChunk) {
+		mockSessionRepo.EXPECT().CreateSession(ctx, gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ *entity.UploadSession, file *entity.File, _ string) (*entity.UploadSession, e.CustomError) {
+				assert.Equal(t, status, file.Status)
+				assert.Equal(t, chunks, file.FileChunks)
+				return session, nil
+			},
+		)
+		mockStorageRepo.EXPECT().CreateDirectory(ctx, gomock.Any()).Return(nil)
+	}
+	// initialChunks returns the chunks of the new version none of which is reused
+	initialChunks := func() []entity.FileChunk {
+		return []entity.FileChunk{
+			{ChunkNumber: 0, Size: 1024, Status: entity.FileStatusInitialized},
+			{ChunkNumber: 1, Size: 1024, Status: entity.FileStatusInitialized},
+			{ChunkNumber: 2, Size: 512, Status: entity.FileStatusInitialized},
+		}
+	}
+
+	tests := []struct {
+		name                  string
+		input                 usecase.FileUploadUseCaseExecuteInitInput
+		setupMocks            func(mockFileRepo *mock.MockFileRepository, mockSessionRepo *mock.MockUploadSessionRepository, mockStorageRepo *mock.MockFileStorageRepository)
+		expectedSession       *entity.UploadSession
+		expectedMissingChunks []*entity.FileChunk
+		expectedErr           e.CustomError
+	}{
+		{
+			name:  "Success - Reuses Chunks Of Same Content",
+			input: deltaInput,
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockSessionRepo *mock.MockUploadSessionRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFileByID(ctx, baseFileID).Return(baseFile, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, baseFileID).Return(baseChunks, nil)
+				// the first chunk has the content of the second chunk of the base file, while the last one has the
+				// checksum of the first chunk but not its size
+				chunks := initialChunks()
+				chunks[0] = entity.FileChunk{ChunkNumber: 0, Size: 1024, Checksum: "c1", BlobID: 71, FilePath: blobPaths[1], Status: entity.FileStatusUploaded}
+				expectNewSession(mockSessionRepo, mockStorageRepo, newSession, entity.FileStatusInProgress, chunks)
+			},
+			expectedSession: newSession,
+			expectedMissingChunks: []*entity.FileChunk{
+				{ChunkNumber: 1, Size: 1024, Status: entity.FileStatusInitialized},
+				{ChunkNumber: 2, Size: 512, Status: entity.FileStatusInitialized},
+			},
+		},
+		{
+			name:  "Success - Base File Bound To Another Name",
+			input: deltaInput,
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockSessionRepo *mock.MockUploadSessionRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				otherFile := *baseFile
+				otherFile.Name = "other_file.dat"
+				mockFileRepo.EXPECT().GetFileByID(ctx, baseFileID).Return(&otherFile, nil)
+				// every chunk is uploaded
+				expectNewSession(mockSessionRepo, mockStorageRepo, newSession, entity.FileStatusInitialized, initialChunks())
+			},
+			expectedSession: newSession,
+			expectedMissingChunks: []*entity.FileChunk{
+				{ChunkNumber: 0, Size: 1024, Status: entity.FileStatusInitialized},
+				{ChunkNumber: 1, Size: 1024, Status: entity.FileStatusInitialized},
+				{ChunkNumber: 2, Size: 512, Status: entity.FileStatusInitialized},
+			},
+		},
+		{
+			name:  "Success - Base File In Another Codec",
+			input: deltaInput,
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockSessionRepo *mock.MockUploadSessionRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				gzipFile := *baseFile
+				gzipFile.StorageCodec = entity.StorageCodecGzip
+				mockFileRepo.EXPECT().GetFileByID(ctx, baseFileID).Return(&gzipFile, nil)
+				expectNewSession(mockSessionRepo, mockStorageRepo, newSession, entity.FileStatusInitialized, initialChunks())
+			},
+			expectedSession: newSession,
+			expectedMissingChunks: []*entity.FileChunk{
+				{ChunkNumber: 0, Size: 1024, Status: entity.FileStatusInitialized},
+				{ChunkNumber: 1, Size: 1024, Status: entity.FileStatusInitialized},
+				{ChunkNumber: 2, Size: 512, Status: entity.FileStatusInitialized},
+			},
+		},
+		{
+			name: "Success - All Chunks Reused Completes File",
+			input: usecase.FileUploadUseCaseExecuteInitInput{
+				FileName: testFileName, Checksum: helloChecksum, ChecksumAlgorithm: "sha256", TotalSize: 5, TotalChunks: 1, ChunkSize: 5,
+				ChunkChecksums: []string{helloChecksum}, BaseFileID: baseFileID, StorageCodec: codec,
+			},
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockSessionRepo *mock.MockUploadSessionRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				reusedChunk := entity.FileChunk{ChunkNumber: 0, Size: 5, Checksum: helloChecksum, BlobID: 70, FilePath: blobPaths[0], Status: entity.FileStatusUploaded}
+				mockFileRepo.EXPECT().GetFileByID(ctx, baseFileID).Return(baseFile, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, baseFileID).Return([]*entity.FileChunk{&reusedChunk}, nil)
+				session := &entity.UploadSession{
+					ID: testFileID, Token: "delta-token", FileID: testFileID, TargetName: testFileName,
+					File: &entity.File{ID: testFileID, Size: 5, Checksum: helloChecksum, ChecksumAlgorithm: "sha256", ChunkSize: 5, TotalChunks: 1, StorageCodec: codec, Status: entity.FileStatusInProgress},
+				}
+				expectNewSession(mockSessionRepo, mockStorageRepo, session, entity.FileStatusInProgress, []entity.FileChunk{reusedChunk})
+
+				// nothing is uploaded, so the file is verified and bound to the name right away
+				mockFileRepo.EXPECT().CompareAndUpdateFileStatus(ctx, testFileID, []entity.FileStatus{entity.FileStatusInProgress, entity.FileStatusFailed}, entity.FileStatusVerifying).Return(true, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(gomock.Any(), testFileID).Return([]*entity.FileChunk{&reusedChunk}, nil)
+				mockStorageRepo.EXPECT().ReadChunk(gomock.Any(), gomock.Any(), blobPaths[0], codec, uint64(0), uint64(5)).
+					DoAndReturn(func(_ context.Context, w io.Writer, _ string, _ entity.StorageCodec, _ uint64, _ uint64) e.CustomError {
+						_, _ = w.Write([]byte("hello"))
+						return nil
+					})
+				mockSessionRepo.EXPECT().CommitSession(gomock.Any(), session).
+					DoAndReturn(func(_ context.Context, session *entity.UploadSession) (*entity.File, []*entity.Blob, e.CustomError) {
+						assert.Equal(t, testFileName, session.File.Name)
+						return nil, nil, nil
+					})
+				mockStorageRepo.EXPECT().DeleteDirectory(gomock.Any(), filepath.Join(baseStorageDir, ".uploads", "delta-token")).Return(nil)
+			},
+			expectedSession: &entity.UploadSession{
+				ID: testFileID, Token: "delta-token", FileID: testFileID, TargetName: testFileName,
+				File: &entity.File{
+					ID: testFileID, Name: testFileName, Size: 5, Checksum: helloChecksum, ChecksumAlgorithm: "sha256", ChunkSize: 5, TotalChunks: 1, StorageCodec: codec, Status: entity.FileStatusInProgress,
+					MerkleRoot: "07636ca803346b2298b02d2c35146d6f18fb848e06b873d3367a51fa4c89b8a1",
+				},
+			},
+			expectedMissingChunks: []*entity.FileChunk{},
+		},
+		{
+			name: "Error - Chunk Checksums Mismatch Chunk Count",
+			input: func() usecase.FileUploadUseCaseExecuteInitInput {
+				input := deltaInput
+				input.ChunkChecksums = []string{"c1", "cx"}
+				return input
+			}(),
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockSessionRepo *mock.MockUploadSessionRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("2 chunk checksums are given for 3 chunks"), "invalid chunk layout"),
+		},
+		{
+			name:  "Error - GetFileByID DB Error",
+			input: deltaInput,
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockSessionRepo *mock.MockUploadSessionRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFileByID(ctx, baseFileID).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name:  "Error - GetChunksByFileID DB Error",
+			input: deltaInput,
+			setupMocks: func(mockFileRepo *mock.MockFileRepository, mockSessionRepo *mock.MockUploadSessionRepository, mockStorageRepo *mock.MockFileStorageRepository) {
+				mockFileRepo.EXPECT().GetFileByID(ctx, baseFileID).Return(baseFile, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, baseFileID).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+			mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
+			mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+			uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, time.Hour, entity.StorageCodecNone)
+
+			mockStorageRepo.EXPECT().GetAvailableSpace(ctx, baseStorageDir).Return(uint64(10 * 1024 * 1024))
+			tc.setupMocks(mockFileRepo, mockSessionRepo, mockStorageRepo)
+			session, missingChunks, err := uc.ExecuteInit(ctx, tc.input)
+
+			if tc.expectedErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+				assert.Nil(t, session)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedSession, session)
+			assert.Equal(t, tc.expectedMissingChunks, missingChunks)
+		})
+	}
+}
+
 func TestFileUploadUseCase_Execute(t *testing.T) {
 	t.Parallel()
 
@@ -350,35 +825,197 @@ func TestFileUploadUseCase_Execute(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
 	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
 
 	baseStorageDir := "/test/uploads"
-	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockStorageRepo, baseStorageDir)
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, time.Hour, entity.StorageCodecNone)
 
 	ctx := context.Background()
 	testFileID := uint64(50)
 	testChunkNumber := uint64(1)
 	testTotalChunks := uint(2)
 	testChunkID := uint64(100)
-	testChunkPath := filepath.Join(baseStorageDir, "uploading_file.dat", fmt.Sprintf("chunk_%d", testChunkNumber))
+	testSessionToken := "execute-token"
+	testChunkPath := filepath.Join(baseStorageDir, ".uploads", testSessionToken, fmt.Sprintf("%d", testChunkNumber))
 	testReader := bytes.NewReader([]byte("chunk data"))
+	testChunkChecksum := "1aa3cca0ad6e4cb8c5ee5b12e3abb3e0fb4e29bcf2ed1d4fd87ef4a8e1fb7fd3"
+	testChunkSize := uint64(5) // the last chunk of "hello world" split by 6 bytes
 
-	fileInitialized := &entity.File{ID: testFileID, Status: entity.FileStatusInitialized, TotalChunks: testTotalChunks}
-	fileInProgress := &entity.File{ID: testFileID, Status: entity.FileStatusInProgress, TotalChunks: testTotalChunks}
+	// "hello world" split into "hello " and "world"
+	fileInitialized := &entity.File{ID: testFileID, Name: "uploading_file.dat", Size: 11, ChunkSize: 6, StorageCodec: entity.StorageCodecGzip, Status: entity.FileStatusInitialized, TotalChunks: testTotalChunks}
+	fileInProgress := &entity.File{
+		ID:                testFileID,
+		Name:              "uploading_file.dat",
+		Size:              11,
+		ChunkSize:         6,
+		Checksum:          "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9",
+		ChecksumAlgorithm: "sha256",
+		Status:            entity.FileStatusInProgress,
+		TotalChunks:       testTotalChunks,
+	}
+	fileInProgressCRC32C := &entity.File{
+		ID:                testFileID,
+		Name:              "uploading_file.dat",
+		Size:              11,
+		ChunkSize:         6,
+		Checksum:          "c99465aa",
+		ChecksumAlgorithm: "crc32c",
+		Status:            entity.FileStatusInProgress,
+		TotalChunks:       testTotalChunks,
+	}
 	fileUploaded := &entity.File{ID: testFileID, Status: entity.FileStatusUploaded, TotalChunks: testTotalChunks}
 
+	testSession := &entity.UploadSession{
+		ID:         5,
+		Token:      testSessionToken,
+		FileID:     testFileID,
+		TargetName: "uploading_file.dat",
+		ExpiresAt:  time.Now().Add(time.Hour),
+		File:       &entity.File{ID: testFileID},
+	}
+	expiredSession := &entity.UploadSession{ID: 5, Token: testSessionToken, FileID: testFileID, ExpiresAt: time.Now().Add(-time.Minute), File: &entity.File{ID: testFileID}}
+	previousFile := &entity.File{
+		ID:   testFileID - 1,
+		Name: "uploading_file.dat",
+		Size: 20,
+		FileChunks: []entity.FileChunk{
+			{ID: 1, ParentID: testFileID - 1, ChunkNumber: 0, FilePath: "/test/uploads/.uploads/previous-token/0"},
+			{ID: 2, ParentID: testFileID - 1, ChunkNumber: 1, FilePath: "/test/uploads/.uploads/previous-token/1"},
+		},
+	}
+
+	previousBlobFile := &entity.File{
+		ID:   testFileID - 1,
+		Name: "uploading_file.dat",
+		Size: 20,
+		FileChunks: []entity.FileChunk{
+			{ID: 1, ParentID: testFileID - 1, ChunkNumber: 0, FilePath: "/test/uploads/.objects/3a/3a0d", BlobID: 3},
+			{ID: 2, ParentID: testFileID - 1, ChunkNumber: 1, FilePath: "/test/uploads/.objects/7e/7e57", BlobID: 7},
+		},
+	}
+
 	chunkInitialized := &entity.FileChunk{ID: testChunkID, ParentID: testFileID, ChunkNumber: testChunkNumber, FilePath: testChunkPath, Status: entity.FileStatusInitialized}
+	// "hello world" split into variable chunks "hello wo" and "rld", whose sizes are recorded
+	fileVariableChunks := &entity.File{ID: testFileID, Name: "uploading_file.dat", Size: 11, ChunkSize: 8, VariableChunks: true, Status: entity.FileStatusInProgress, TotalChunks: testTotalChunks}
+	variableChunkInitialized := &entity.FileChunk{ID: testChunkID, ParentID: testFileID, ChunkNumber: testChunkNumber, Offset: 8, Size: 3, FilePath: testChunkPath, Status: entity.FileStatusInitialized}
 	chunkUploaded := &entity.FileChunk{ID: testChunkID, ParentID: testFileID, ChunkNumber: testChunkNumber, FilePath: testChunkPath, Status: entity.FileStatusUploaded}
+	existingBlob := &entity.Blob{ID: 7, FilePath: "/test/uploads/.objects/7e/7e57"}
+	releasedBlob := &entity.Blob{ID: 3, FilePath: "/test/uploads/.objects/3a/3a0d"}
 
 	dbError := e.NewDatabaseError(errors.New("db error"), "")
 	storageError := e.NewFileStorageError(errors.New("storage error"), "")
 	invalidInputNilError := e.NewInvalidInputError(nil, fmt.Sprintf("data not found for (file ID, chunk ID) = (%d, %d)", testFileID, testChunkNumber))
 	invalidInputStatusError := e.NewInvalidInputError(fmt.Errorf("file upload needs to be initialized"), "")
+	sessionNotFoundError := e.NewNotFoundError(errors.New("upload session not found or expired"), "")
+	checksumMismatchError := e.NewChecksumMismatchError(fmt.Errorf("%s is declared", fileInProgress.Checksum), "uploading_file.dat is corrupt")
+	undersizedChunkError := e.NewInvalidInputError(fmt.Errorf("chunk 1 of uploading_file.dat needs to be 5 bytes, but got 4 bytes"), "invalid chunk size")
+	oversizedChunkError := e.NewInvalidInputError(fmt.Errorf("chunk 1 of uploading_file.dat needs to be 5 bytes, but got more"), "invalid chunk size")
+	chunkChecksumMismatchError := e.NewChecksumMismatchError(fmt.Errorf("expected %s, but got abc", testChunkChecksum), "")
+	invalidOffsetError := e.NewConflictError(fmt.Errorf("2 bytes of chunk 1 of uploading_file.dat are received, but got the content from 3"), "invalid offset")
+
+	storedChunks := []*entity.FileChunk{
+		{ID: testChunkID - 1, ParentID: testFileID, ChunkNumber: 0, FilePath: "/test/uploads/.objects/0b/0b1d", BlobID: 1, Status: entity.FileStatusUploaded},
+		{ID: testChunkID, ParentID: testFileID, ChunkNumber: 1, FilePath: "/test/uploads/.objects/1b/1b1d", BlobID: 2, Status: entity.FileStatusUploaded},
+	}
+	// expectStore expects the committed chunk to be moved to a new blob path and completed in the codec. When the
+	// blob of the same content exists, the chunk shares it and the content is moved to its path. The blobs the chunk
+	// referred to before are released
+	expectStore := func(codec entity.StorageCodec, sharedBlob *entity.Blob, releasedBlobs []*entity.Blob, completeErr e.CustomError) {
+		var blobPath string
+		mockStorageRepo.EXPECT().MoveFile(ctx, testChunkPath, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, dstPath string) e.CustomError {
+				assert.Equal(t, filepath.Join(baseStorageDir, ".objects"), filepath.Dir(filepath.Dir(dstPath)))
+				assert.True(t, strings.HasPrefix(filepath.Base(dstPath), filepath.Base(filepath.Dir(dstPath))))
+				blobPath = dstPath
+				return nil
+			})
+		mockFileRepo.EXPECT().CompleteChunk(ctx, gomock.Any(), codec).
+			DoAndReturn(func(_ context.Context, chunk *entity.FileChunk, _ entity.StorageCodec) ([]*entity.Blob, e.CustomError) {
+				assert.Equal(t, testChunkID, chunk.ID)
+				assert.Equal(t, testChunkSize, chunk.Size)
+				assert.Equal(t, testChunkChecksum, chunk.Checksum)
+				assert.Equal(t, blobPath, chunk.FilePath)
+				if completeErr != nil {
+					return nil, completeErr
+				}
+				if sharedBlob != nil {
+					chunk.FilePath = sharedBlob.FilePath
+				}
+				return releasedBlobs, nil
+			})
+		if completeErr != nil {
+			// the content moved to the new blob path is deleted since no blob refers to it
+			mockStorageRepo.EXPECT().DeleteFile(ctx, gomock.Any()).
+				DoAndReturn(func(_ context.Context, filePath string) e.CustomError {
+					assert.Equal(t, blobPath, filePath)
+					return nil
+				})
+			return
+		}
+		if sharedBlob != nil {
+			mockStorageRepo.EXPECT().MoveFile(ctx, gomock.Any(), sharedBlob.FilePath).
+				DoAndReturn(func(_ context.Context, srcPath string, _ string) e.CustomError {
+					assert.Equal(t, blobPath, srcPath)
+					return nil
+				})
+		}
+		for _, blob := range releasedBlobs {
+			mockStorageRepo.EXPECT().DeleteFile(ctx, blob.FilePath).Return(nil)
+		}
+	}
+	// expectVerification expects the stored chunks to be read with the given content. The verification runs on a
+	// context detached from the request, so the context is not matched
+	expectVerification := func(contents ...string) {
+		mockFileRepo.EXPECT().CompareAndUpdateFileStatus(ctx, testFileID, []entity.FileStatus{entity.FileStatusInProgress, entity.FileStatusFailed}, entity.FileStatusVerifying).Return(true, nil)
+		mockFileRepo.EXPECT().GetChunksByFileID(gomock.Any(), testFileID).Return(storedChunks, nil)
+		for i, content := range contents {
+			mockStorageRepo.EXPECT().ReadChunk(gomock.Any(), gomock.Any(), storedChunks[i].FilePath, gomock.Any(), uint64(0), uint64(len(content))).
+				DoAndReturn(func(_ context.Context, w io.Writer, _ string, _ entity.StorageCodec, _ uint64, _ uint64) e.CustomError {
+					_, _ = w.Write([]byte(content))
+					return nil
+				})
+		}
+	}
+	// expectCompletion expects the session to be committed with the Merkle root of "hello " and "world", replacing the
+	// given file bound to the name and releasing its blobs
+	expectCompletion := func(replacedFile *entity.File, releasedBlobs []*entity.Blob, err e.CustomError) {
+		mockSessionRepo.EXPECT().CommitSession(gomock.Any(), testSession).
+			DoAndReturn(func(_ context.Context, session *entity.UploadSession) (*entity.File, []*entity.Blob, e.CustomError) {
+				assert.Equal(t, testFileID, session.File.ID)
+				assert.Equal(t, "uploading_file.dat", session.File.Name)
+				assert.Equal(t, "1611ac016713113b29ef4acfa37566046c257ee45139b112939f43a4c20ca8a2", session.File.MerkleRoot)
+				if err != nil {
+					return nil, nil, err
+				}
+				return replacedFile, releasedBlobs, nil
+			})
+		if err == nil {
+			// all the chunks are stored in the blobs, so the staging directory of the session is deleted
+			mockStorageRepo.EXPECT().DeleteDirectory(gomock.Any(), "/test/uploads/.uploads/execute-token").Return(nil)
+		}
+	}
+	// expectSession expects the session to be looked up, and extended when it's used for the upload
+	expectSession := func(isExtended bool) {
+		mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(testSession, nil)
+		if isExtended {
+			mockSessionRepo.EXPECT().ExtendSession(ctx, testSession.ID, gomock.Any()).Return(nil)
+		}
+	}
 
 	input := usecase.FileUploadUseCaseExecuteInput{
-		FileID:      testFileID,
-		ChunkNumber: testChunkNumber,
-		Reader:      testReader,
+		SessionToken: testSessionToken,
+		ChunkNumber:  testChunkNumber,
+		Reader:       testReader,
+		Checksum:     testChunkChecksum,
+	}
+	// continues "wo" of "world" received before
+	continueInput := usecase.FileUploadUseCaseExecuteInput{
+		SessionToken: testSessionToken,
+		ChunkNumber:  testChunkNumber,
+		Offset:       3,
+		Reader:       bytes.NewReader([]byte("ld")),
+		Checksum:     testChunkChecksum,
 	}
 
 	tests := []struct {
@@ -391,10 +1028,12 @@ func TestFileUploadUseCase_Execute(t *testing.T) {
 			name:  "Success - First Chunk (File INITIALIZED)",
 			input: input,
 			setupMocks: func() {
+				expectSession(true)
 				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInitialized, chunkInitialized, nil)
 				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, testFileID, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
-				mockStorageRepo.EXPECT().WriteChunk(ctx, input.Reader, testChunkPath).Return(nil)
-				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusUploaded).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, entity.StorageCodecGzip, testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(entity.StorageCodecGzip, nil, nil, nil)
 				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(1), int64(testTotalChunks), nil)
 			},
 			expectedErr: nil,
@@ -403,65 +1042,257 @@ func TestFileUploadUseCase_Execute(t *testing.T) {
 			name:  "Success - Subsequent Chunk (File IN_PROGRESS)",
 			input: input,
 			setupMocks: func() {
+				expectSession(true)
 				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
 				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
-				mockStorageRepo.EXPECT().WriteChunk(ctx, input.Reader, testChunkPath).Return(nil)
-				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusUploaded).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
 				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(1), int64(testTotalChunks), nil)
 			},
 			expectedErr: nil,
 		},
 		{
-			name:  "Success - Last Chunk",
+			name:  "Success - Chunk Sharing Existing Blob",
 			input: input,
 			setupMocks: func() {
+				expectSession(true)
 				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
 				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
-				mockStorageRepo.EXPECT().WriteChunk(ctx, input.Reader, testChunkPath).Return(nil)
-				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusUploaded).Return(nil)
-				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(testTotalChunks), int64(testTotalChunks), nil)
-				mockFileRepo.EXPECT().UpdateFileStatus(ctx, testFileID, entity.FileStatusUploaded).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				// the content replaces the one of the existing blob
+				expectStore(fileInProgress.StorageCodec, existingBlob, nil, nil)
+				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(1), int64(testTotalChunks), nil)
 			},
 			expectedErr: nil,
 		},
 		{
-			name:  "Error - GetFileAndChunk Not Found (nil, nil)",
+			name:  "Success - Chunk Releasing Previous Blob",
 			input: input,
 			setupMocks: func() {
-				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(nil, nil, nil)
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, []*entity.Blob{releasedBlob}, nil)
+				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(1), int64(testTotalChunks), nil)
 			},
-			expectedErr: invalidInputNilError,
+			expectedErr: nil,
 		},
 		{
-			name:  "Error - GetFileAndChunk DB Error",
+			name:  "Success - Last Chunk",
 			input: input,
 			setupMocks: func() {
-				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(nil, nil, dbError)
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
+				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(testTotalChunks), int64(testTotalChunks), nil)
+				expectVerification("hello ", "world")
+				expectCompletion(nil, nil, nil)
 			},
-			expectedErr: dbError,
+			expectedErr: nil,
 		},
 		{
-			name:  "Error - Invalid File Status (UPLOADED)",
+			name:  "Success - Last Chunk (CRC32C)",
 			input: input,
 			setupMocks: func() {
-				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileUploaded, chunkInitialized, nil)
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgressCRC32C, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
+				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(testTotalChunks), int64(testTotalChunks), nil)
+				expectVerification("hello ", "world")
+				expectCompletion(nil, nil, nil)
 			},
-			expectedErr: invalidInputStatusError,
+			expectedErr: nil,
 		},
 		{
-			name:  "Error - Invalid Chunk Status (UPLOADED)",
+			name:  "Success - Last Chunk Verified By Another Request",
 			input: input,
 			setupMocks: func() {
-				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkUploaded, nil)
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
+				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(testTotalChunks), int64(testTotalChunks), nil)
+				mockFileRepo.EXPECT().CompareAndUpdateFileStatus(ctx, testFileID, []entity.FileStatus{entity.FileStatusInProgress, entity.FileStatusFailed}, entity.FileStatusVerifying).Return(false, nil)
 			},
-			expectedErr: invalidInputStatusError,
+			expectedErr: nil,
 		},
 		{
-			name:  "Error - UpdateFileAndChunkStatus DB Error (First Chunk)",
+			name:  "Error - Checksum Mismatch (Last Chunk)",
 			input: input,
 			setupMocks: func() {
-				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInitialized, chunkInitialized, nil)
-				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, testFileID, []uint64{testChunkID}, entity.FileStatusInProgress).Return(dbError)
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
+				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(testTotalChunks), int64(testTotalChunks), nil)
+				expectVerification("hello ", "there")
+				mockFileRepo.EXPECT().UpdateFileStatus(gomock.Any(), testFileID, entity.FileStatusCorrupt).Return(nil)
+			},
+			expectedErr: checksumMismatchError,
+		},
+		{
+			name:  "Error - Storage Error During Verification (Last Chunk)",
+			input: input,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
+				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(testTotalChunks), int64(testTotalChunks), nil)
+				mockFileRepo.EXPECT().CompareAndUpdateFileStatus(ctx, testFileID, []entity.FileStatus{entity.FileStatusInProgress, entity.FileStatusFailed}, entity.FileStatusVerifying).Return(true, nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(gomock.Any(), testFileID).Return(storedChunks, nil)
+				mockStorageRepo.EXPECT().ReadChunk(gomock.Any(), gomock.Any(), storedChunks[0].FilePath, gomock.Any(), uint64(0), uint64(6)).Return(storageError)
+				mockFileRepo.EXPECT().UpdateFileStatus(gomock.Any(), testFileID, entity.FileStatusFailed).Return(nil)
+			},
+			expectedErr: storageError,
+		},
+		{
+			name:  "Success - Last Chunk Replacing File Bound To Name",
+			input: input,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
+				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(testTotalChunks), int64(testTotalChunks), nil)
+				expectVerification("hello ", "world")
+				expectCompletion(previousFile, nil, nil)
+				// the content of the replaced file is deleted once the name is bound to the new one
+				mockStorageRepo.EXPECT().DeleteDirectory(gomock.Any(), "/test/uploads/.uploads/previous-token").Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:  "Success - Last Chunk Replacing File Stored In Blobs",
+			input: input,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
+				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(testTotalChunks), int64(testTotalChunks), nil)
+				expectVerification("hello ", "world")
+				// only the blob no other chunk refers to is released
+				expectCompletion(previousBlobFile, []*entity.Blob{releasedBlob}, nil)
+				mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), releasedBlob.FilePath).Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:  "Success - Last Chunk Replacing File Bound To Name (Content Deletion Failed)",
+			input: input,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
+				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(testTotalChunks), int64(testTotalChunks), nil)
+				expectVerification("hello ", "world")
+				expectCompletion(previousFile, nil, nil)
+				// the new file is already bound to the name, so the leftovers are left to fsck
+				mockStorageRepo.EXPECT().DeleteDirectory(gomock.Any(), "/test/uploads/.uploads/previous-token").Return(storageError)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:  "Error - Session Not Found",
+			input: input,
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(nil, nil)
+			},
+			expectedErr: sessionNotFoundError,
+		},
+		{
+			name:  "Error - Session Expired",
+			input: input,
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(expiredSession, nil)
+			},
+			expectedErr: sessionNotFoundError,
+		},
+		{
+			name:  "Error - GetSessionByToken DB Error",
+			input: input,
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name:  "Error - ExtendSession DB Error",
+			input: input,
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(testSession, nil)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockSessionRepo.EXPECT().ExtendSession(ctx, testSession.ID, gomock.Any()).Return(dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name:  "Error - GetFileAndChunk Not Found (nil, nil)",
+			input: input,
+			setupMocks: func() {
+				expectSession(false)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(nil, nil, nil)
+			},
+			expectedErr: invalidInputNilError,
+		},
+		{
+			name:  "Error - GetFileAndChunk DB Error",
+			input: input,
+			setupMocks: func() {
+				expectSession(false)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(nil, nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name:  "Error - Invalid File Status (UPLOADED)",
+			input: input,
+			setupMocks: func() {
+				expectSession(false)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileUploaded, chunkInitialized, nil)
+			},
+			expectedErr: invalidInputStatusError,
+		},
+		{
+			name:  "Error - Invalid Chunk Status (UPLOADED)",
+			input: input,
+			setupMocks: func() {
+				expectSession(false)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkUploaded, nil)
+			},
+			expectedErr: invalidInputStatusError,
+		},
+		{
+			name:  "Error - UpdateFileAndChunkStatus DB Error (First Chunk)",
+			input: input,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInitialized, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, testFileID, []uint64{testChunkID}, entity.FileStatusInProgress).Return(dbError)
 			},
 			expectedErr: dbError,
 		},
@@ -469,18 +1300,104 @@ func TestFileUploadUseCase_Execute(t *testing.T) {
 			name:  "Error - UpdateChunksStatus DB Error (Subsequent Chunk, to IN_PROGRESS)",
 			input: input,
 			setupMocks: func() {
+				expectSession(true)
 				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
 				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(dbError)
 			},
 			expectedErr: dbError,
 		},
 		{
-			name:  "Error - WriteChunk Storage Error",
+			name:  "Success - Continue From Received Content",
+			input: continueInput,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, testChunkPath).Return(uint64(3), nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(3)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
+				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(1), int64(testTotalChunks), nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:  "Error - Offset Beyond Received Content",
+			input: continueInput,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, testChunkPath).Return(uint64(2), nil)
+			},
+			expectedErr: invalidOffsetError,
+		},
+		{
+			name:  "Error - CommitPartialChunk Checksum Mismatch",
+			input: input,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(uint64(0), "", chunkChecksumMismatchError)
+			},
+			expectedErr: chunkChecksumMismatchError,
+		},
+		{
+			name:  "Error - AppendPartialChunk Storage Error (Received Content Kept)",
+			input: input,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(uint64(3), storageError)
+			},
+			expectedErr: storageError,
+		},
+		{
+			name:  "Error - Undersized Chunk (Received Content Kept)",
 			input: input,
 			setupMocks: func() {
+				expectSession(true)
 				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
 				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
-				mockStorageRepo.EXPECT().WriteChunk(ctx, input.Reader, testChunkPath).Return(storageError)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize-1, nil)
+			},
+			expectedErr: undersizedChunkError,
+		},
+		{
+			name:  "Error - Undersized Variable Chunk (Checked By Recorded Size)",
+			input: input,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileVariableChunks, variableChunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(uint64(2), nil)
+			},
+			expectedErr: e.NewInvalidInputError(fmt.Errorf("chunk 1 of uploading_file.dat needs to be 3 bytes, but got 2 bytes"), "invalid chunk size"),
+		},
+		{
+			name:  "Error - Oversized Chunk",
+			input: input,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize+1, nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(uint64(0), nil)
+			},
+			expectedErr: oversizedChunkError,
+		},
+		{
+			name:  "Error - AppendPartialChunk Storage Error (Discarding Oversized Chunk)",
+			input: input,
+			setupMocks: func() {
+				expectSession(true)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize+1, nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(uint64(0), storageError)
 			},
 			expectedErr: storageError,
 		},
@@ -488,10 +1405,12 @@ func TestFileUploadUseCase_Execute(t *testing.T) {
 			name:  "Error - UpdateChunksStatus DB Error (to UPLOADED)",
 			input: input,
 			setupMocks: func() {
+				expectSession(true)
 				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
 				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
-				mockStorageRepo.EXPECT().WriteChunk(ctx, input.Reader, testChunkPath).Return(nil)
-				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusUploaded).Return(dbError)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, dbError)
 			},
 			expectedErr: dbError,
 		},
@@ -499,24 +1418,29 @@ func TestFileUploadUseCase_Execute(t *testing.T) {
 			name:  "Error - CountChunksByStatus DB Error",
 			input: input,
 			setupMocks: func() {
+				expectSession(true)
 				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
 				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
-				mockStorageRepo.EXPECT().WriteChunk(ctx, input.Reader, testChunkPath).Return(nil)
-				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusUploaded).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
 				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(0), int64(0), dbError)
 			},
 			expectedErr: dbError,
 		},
 		{
-			name:  "Error - UpdateFileStatus DB Error (Last Chunk)",
+			name:  "Error - CommitSession DB Error (Last Chunk)",
 			input: input,
 			setupMocks: func() {
+				expectSession(true)
 				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(fileInProgress, chunkInitialized, nil)
 				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
-				mockStorageRepo.EXPECT().WriteChunk(ctx, input.Reader, testChunkPath).Return(nil)
-				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusUploaded).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(testChunkSize, nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(testChunkSize, testChunkChecksum, nil)
+				expectStore(fileInProgress.StorageCodec, nil, nil, nil)
 				mockFileRepo.EXPECT().CountChunksByStatus(ctx, testFileID, entity.FileStatusUploaded).Return(int64(testTotalChunks), int64(testTotalChunks), nil)
-				mockFileRepo.EXPECT().UpdateFileStatus(ctx, testFileID, entity.FileStatusUploaded).Return(dbError)
+				expectVerification("hello ", "world")
+				expectCompletion(nil, nil, dbError)
 			},
 			expectedErr: dbError,
 		},
@@ -550,38 +1474,1657 @@ func TestFileUploadUseCase_ExecuteFailRecovery(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
-	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl) // Not used but needed for constructor
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
 
 	baseStorageDir := "/test/uploads"
-	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockStorageRepo, baseStorageDir)
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, time.Hour, entity.StorageCodecNone)
 
 	ctx := context.Background()
 	testFileID := uint64(70)
+	testChunkNumber := uint64(1)
 	testChunkID := uint64(170)
+	testSessionToken := "recovery-token"
+	testSession := &entity.UploadSession{
+		ID:         7,
+		Token:      testSessionToken,
+		FileID:     testFileID,
+		TargetName: "failing.dat",
+		ExpiresAt:  time.Now().Add(time.Hour),
+		File:       &entity.File{ID: testFileID},
+	}
+	testFile := &entity.File{ID: testFileID, Name: "failing.dat", Status: entity.FileStatusInProgress}
+	testChunk := &entity.FileChunk{ID: testChunkID, ParentID: testFileID, ChunkNumber: testChunkNumber, Status: entity.FileStatusInProgress}
 	dbError := e.NewDatabaseError(errors.New("db error"), "")
 
 	tests := []struct {
 		name        string
-		fileID      uint64
-		chunkID     uint64
 		setupMocks  func()
 		expectedErr e.CustomError
 	}{
 		{
-			name:    "Success",
-			fileID:  testFileID,
-			chunkID: testChunkID,
+			name: "Success",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(testSession, nil)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(testFile, testChunk, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusFailed).Return(nil)
+				mockFileRepo.EXPECT().CompareAndUpdateFileStatus(ctx, testFileID, []entity.FileStatus{entity.FileStatusInitialized, entity.FileStatusInProgress}, entity.FileStatusFailed).Return(true, nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Success - File Already Verified",
 			setupMocks: func() {
-				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, testFileID, []uint64{testChunkID}, entity.FileStatusFailed).Return(nil)
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(testSession, nil)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(testFile, testChunk, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusFailed).Return(nil)
+				mockFileRepo.EXPECT().CompareAndUpdateFileStatus(ctx, testFileID, []entity.FileStatus{entity.FileStatusInitialized, entity.FileStatusInProgress}, entity.FileStatusFailed).Return(false, nil)
 			},
 			expectedErr: nil,
 		},
 		{
-			name:    "Error - DB Error",
-			fileID:  testFileID,
-			chunkID: testChunkID,
+			name: "Error - Session Not Found",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(nil, nil)
+			},
+			expectedErr: e.NewNotFoundError(errors.New("upload session not found or expired"), ""),
+		},
+		{
+			name: "Error - Chunk Not Found",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(testSession, nil)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(nil, nil, nil)
+			},
+			expectedErr: e.NewNotFoundError(fmt.Errorf("chunk %d of failing.dat not found", testChunkNumber), ""),
+		},
+		{
+			name: "Error - DB Error",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(testSession, nil)
+				mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(testFile, testChunk, nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusFailed).Return(dbError)
+			},
+			expectedErr: dbError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			err := uc.ExecuteFailRecovery(ctx, testSessionToken, testChunkNumber)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestFileUploadUseCase_ExecuteGetOffset(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+
+	baseStorageDir := "/test/uploads"
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, time.Hour, entity.StorageCodecNone)
+
+	ctx := context.Background()
+	testFileID := uint64(80)
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+	storageError := e.NewFileStorageError(errors.New("storage error"), "")
+
+	// "hello world" split into "hello " and "world"
+	fileInProgress := &entity.File{ID: testFileID, Name: "appending.dat", Size: 11, ChunkSize: 6, Status: entity.FileStatusInProgress, TotalChunks: 2}
+	fileUploaded := &entity.File{ID: testFileID, Name: "appending.dat", Size: 11, ChunkSize: 6, Status: entity.FileStatusUploaded, TotalChunks: 2}
+	chunks := []*entity.FileChunk{
+		{ID: 1, ParentID: testFileID, ChunkNumber: 0, FilePath: "/test/uploads/.uploads/offset-token/0", Status: entity.FileStatusUploaded},
+		{ID: 2, ParentID: testFileID, ChunkNumber: 1, FilePath: "/test/uploads/.uploads/offset-token/1", Status: entity.FileStatusInitialized},
+	}
+	testSessionToken := "offset-token"
+	sessionOf := func(file *entity.File) *entity.UploadSession {
+		return &entity.UploadSession{ID: 8, Token: testSessionToken, FileID: testFileID, TargetName: "appending.dat", ExpiresAt: time.Now().Add(time.Hour), File: file}
+	}
+
+	tests := []struct {
+		name           string
+		setupMocks     func()
+		expectedOffset uint64
+		expectedErr    e.CustomError
+	}{
+		{
+			name: "Success - Partial chunk after uploaded chunk",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(fileInProgress), nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(chunks, nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, chunks[1].FilePath).Return(uint64(2), nil)
+			},
+			expectedOffset: 8,
+		},
+		{
+			name: "Success - Uploaded file",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(fileUploaded), nil)
+			},
+			expectedOffset: 11,
+		},
+		{
+			name: "Error - Session Not Found",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(nil, nil)
+			},
+			expectedErr: e.NewNotFoundError(errors.New("upload session not found or expired"), ""),
+		},
+		{
+			name: "Error - GetSessionByToken DB Error",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name: "Error - GetPartialChunkSize Storage Error",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(fileInProgress), nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(chunks, nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, chunks[1].FilePath).Return(uint64(0), storageError)
+			},
+			expectedErr: storageError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			session, offset, err := uc.ExecuteGetOffset(ctx, testSessionToken)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, testFileID, session.File.ID)
+				assert.Equal(t, tc.expectedOffset, offset)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestFileUploadUseCase_ExecuteAppend(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+
+	baseStorageDir := "/test/uploads"
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, time.Hour, entity.StorageCodecNone)
+
+	ctx := context.Background()
+	testFileID := uint64(90)
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+	storageError := e.NewFileStorageError(errors.New("storage error"), "")
+
+	// "hello world" split into "hello " and "world". The checksum is calculated once it's uploaded
+	fileInitialized := &entity.File{ID: testFileID, Name: "appending.dat", Size: 11, ChunkSize: 6, ChecksumAlgorithm: "sha256", Status: entity.FileStatusInitialized, TotalChunks: 2}
+	fileInProgress := &entity.File{ID: testFileID, Name: "appending.dat", Size: 11, ChunkSize: 6, ChecksumAlgorithm: "sha256", Status: entity.FileStatusInProgress, TotalChunks: 2}
+	fileUploaded := &entity.File{ID: testFileID, Name: "appending.dat", Size: 11, ChunkSize: 6, Status: entity.FileStatusUploaded, TotalChunks: 2}
+	newChunks := func() []*entity.FileChunk {
+		return []*entity.FileChunk{
+			{ID: 1, ParentID: testFileID, ChunkNumber: 0, FilePath: "/test/uploads/.uploads/append-token/0", Status: entity.FileStatusInitialized},
+			{ID: 2, ParentID: testFileID, ChunkNumber: 1, FilePath: "/test/uploads/.uploads/append-token/1", Status: entity.FileStatusInitialized},
+		}
+	}
+	chunkPaths := []string{"/test/uploads/.uploads/append-token/0", "/test/uploads/.uploads/append-token/1"}
+	testSessionToken := "append-token"
+	sessionOf := func(file *entity.File) *entity.UploadSession {
+		return &entity.UploadSession{ID: 9, Token: testSessionToken, FileID: testFileID, TargetName: "appending.dat", ExpiresAt: time.Now().Add(time.Hour), File: file}
+	}
+	// expectSession expects the session of the file to be looked up, and extended when the file can be appended
+	expectSession := func(file *entity.File, isExtended bool) {
+		mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(file), nil)
+		if isExtended {
+			mockSessionRepo.EXPECT().ExtendSession(ctx, uint64(9), gomock.Any()).Return(nil)
+		}
+	}
+	helloChecksum := "5e3235a8346e5a4585f8c58562f5052b8fe26a3bb122e1e96c76784964dfc461"
+	worldChecksum := "486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7"
+
+	// expectAppend expects the content up to the end of the chunk to be appended to the partial chunk
+	expectAppend := func(chunkNumber int, partialOffset uint64) {
+		mockStorageRepo.EXPECT().AppendPartialChunk(gomock.Any(), gomock.Any(), chunkPaths[chunkNumber], partialOffset).
+			DoAndReturn(func(_ context.Context, r io.Reader, _ string, offset uint64) (uint64, e.CustomError) {
+				n, _ := io.Copy(io.Discard, r)
+				return offset + uint64(n), nil
+			})
+	}
+	// expectCommit expects the filled partial chunk to be committed, and stored as the blob of the chunk
+	expectCommit := func(chunkNumber int, size uint64, checksum string) {
+		mockStorageRepo.EXPECT().CommitPartialChunk(gomock.Any(), chunkPaths[chunkNumber], gomock.Any(), "").Return(size, checksum, nil)
+		var blobPath string
+		mockStorageRepo.EXPECT().MoveFile(gomock.Any(), chunkPaths[chunkNumber], gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, dstPath string) e.CustomError {
+				assert.True(t, strings.HasPrefix(dstPath, "/test/uploads/.objects/"))
+				blobPath = dstPath
+				return nil
+			})
+		mockFileRepo.EXPECT().CompleteChunk(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, chunk *entity.FileChunk, _ entity.StorageCodec) ([]*entity.Blob, e.CustomError) {
+				assert.Equal(t, uint64(chunkNumber), chunk.ChunkNumber)
+				assert.Equal(t, size, chunk.Size)
+				assert.Equal(t, checksum, chunk.Checksum)
+				assert.Equal(t, blobPath, chunk.FilePath)
+				return nil, nil
+			})
+	}
+	// expectVerification expects the file to be verified and completed with the calculated checksum of "hello world"
+	expectVerification := func() {
+		mockFileRepo.EXPECT().CompareAndUpdateFileStatus(gomock.Any(), testFileID, []entity.FileStatus{entity.FileStatusInProgress, entity.FileStatusFailed}, entity.FileStatusVerifying).Return(true, nil)
+		mockFileRepo.EXPECT().GetChunksByFileID(gomock.Any(), testFileID).Return(newChunks(), nil)
+		for i, content := range []string{"hello ", "world"} {
+			mockStorageRepo.EXPECT().ReadChunk(gomock.Any(), gomock.Any(), chunkPaths[i], gomock.Any(), uint64(0), uint64(len(content))).
+				DoAndReturn(func(_ context.Context, w io.Writer, _ string, _ entity.StorageCodec, _ uint64, _ uint64) e.CustomError {
+					_, _ = w.Write([]byte(content))
+					return nil
+				})
+		}
+		mockSessionRepo.EXPECT().CommitSession(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, session *entity.UploadSession) (*entity.File, []*entity.Blob, e.CustomError) {
+				assert.Equal(t, testSessionToken, session.Token)
+				assert.Equal(t, "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", session.File.Checksum)
+				assert.Equal(t, "1611ac016713113b29ef4acfa37566046c257ee45139b112939f43a4c20ca8a2", session.File.MerkleRoot)
+				return nil, nil, nil
+			})
+	}
+	sha256Of := func(content string) []byte {
+		sum := sha256.Sum256([]byte(content))
+		return sum[:]
+	}
+
+	tests := []struct {
+		name           string
+		input          usecase.FileUploadUseCaseExecuteAppendInput
+		setupMocks     func()
+		expectedOffset uint64
+		expectedErr    e.CustomError
+	}{
+		{
+			name:  "Success - First append in the middle of a chunk",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{SessionToken: testSessionToken, Offset: 0, Reader: strings.NewReader("hel")},
+			setupMocks: func() {
+				expectSession(fileInitialized, true)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(newChunks(), nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, chunkPaths[0]).Return(uint64(0), nil)
+				mockFileRepo.EXPECT().UpdateFileStatus(ctx, testFileID, entity.FileStatusInProgress).Return(nil)
+				expectAppend(0, 0)
+			},
+			expectedOffset: 3,
+		},
+		{
+			name:  "Success - Append across chunks completes the file",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{SessionToken: testSessionToken, Offset: 3, Reader: strings.NewReader("lo world")},
+			setupMocks: func() {
+				expectSession(fileInProgress, true)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(newChunks(), nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, chunkPaths[0]).Return(uint64(3), nil)
+				expectAppend(0, 3)
+				expectAppend(1, 0)
+				expectCommit(0, 6, helloChecksum)
+				expectCommit(1, 5, worldChecksum)
+				expectVerification()
+			},
+			expectedOffset: 11,
+		},
+		{
+			name: "Success - Checksum matches",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{
+				SessionToken: testSessionToken, Offset: 3, Reader: strings.NewReader("lo wo"), ChecksumHash: sha256.New(), Checksum: sha256Of("lo wo"),
+			},
+			setupMocks: func() {
+				expectSession(fileInProgress, true)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(newChunks(), nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, chunkPaths[0]).Return(uint64(3), nil)
+				expectAppend(0, 3)
+				expectAppend(1, 0)
+				expectCommit(0, 6, helloChecksum)
+			},
+			expectedOffset: 8,
+		},
+		{
+			name: "Error - Checksum mismatch discards the content",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{
+				SessionToken: testSessionToken, Offset: 3, Reader: strings.NewReader("lo wo"), ChecksumHash: sha256.New(), Checksum: sha256Of("lo w0"),
+			},
+			setupMocks: func() {
+				expectSession(fileInProgress, true)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(newChunks(), nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, chunkPaths[0]).Return(uint64(3), nil)
+				expectAppend(0, 3)
+				expectAppend(1, 0)
+				// the partial chunks go back to the offset the append started at
+				mockStorageRepo.EXPECT().AppendPartialChunk(gomock.Any(), gomock.Any(), chunkPaths[0], uint64(3)).Return(uint64(3), nil)
+				mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), chunkPaths[1]+".partial").Return(nil)
+			},
+			expectedErr: e.NewChecksumMismatchError(fmt.Errorf("expected %x, but got %x", sha256Of("lo w0"), sha256Of("lo wo")), "content appended to appending.dat is corrupted"),
+		},
+		{
+			name:  "Error - Content exceeds the file size",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{SessionToken: testSessionToken, Offset: 3, Reader: strings.NewReader("lo world!")},
+			setupMocks: func() {
+				expectSession(fileInProgress, true)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(newChunks(), nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, chunkPaths[0]).Return(uint64(3), nil)
+				expectAppend(0, 3)
+				expectAppend(1, 0)
+				mockStorageRepo.EXPECT().AppendPartialChunk(gomock.Any(), gomock.Any(), chunkPaths[0], uint64(3)).Return(uint64(3), nil)
+				mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), chunkPaths[1]+".partial").Return(nil)
+			},
+			expectedErr: e.NewInvalidInputError(fmt.Errorf("content exceeds the size of appending.dat, 11 bytes"), ""),
+		},
+		{
+			name: "Error - Reader failing at the end leaves the file unverified",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{
+				SessionToken: testSessionToken, Offset: 3, Reader: io.MultiReader(strings.NewReader("lo world"), iotest.ErrReader(errors.New("trailer mismatch"))),
+			},
+			setupMocks: func() {
+				expectSession(fileInProgress, true)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(newChunks(), nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, chunkPaths[0]).Return(uint64(3), nil)
+				expectAppend(0, 3)
+				expectAppend(1, 0)
+				expectCommit(0, 6, helloChecksum)
+				expectCommit(1, 5, worldChecksum)
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("trailer mismatch"), "failed to read the end of the content appended to appending.dat"),
+		},
+		{
+			name:  "Error - Request cut off keeps the received content",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{SessionToken: testSessionToken, Offset: 3, Reader: strings.NewReader("lo wo")},
+			setupMocks: func() {
+				expectSession(fileInProgress, true)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(newChunks(), nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, chunkPaths[0]).Return(uint64(3), nil)
+				expectAppend(0, 3)
+				mockStorageRepo.EXPECT().AppendPartialChunk(gomock.Any(), gomock.Any(), chunkPaths[1], uint64(0)).Return(uint64(1), storageError)
+				expectCommit(0, 6, helloChecksum)
+			},
+			expectedErr: storageError,
+		},
+		{
+			name:  "Error - Offset mismatch",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{SessionToken: testSessionToken, Offset: 0, Reader: strings.NewReader("hel")},
+			setupMocks: func() {
+				expectSession(fileInProgress, true)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(newChunks(), nil)
+				mockStorageRepo.EXPECT().GetPartialChunkSize(ctx, chunkPaths[0]).Return(uint64(3), nil)
+			},
+			expectedErr: e.NewConflictError(fmt.Errorf("offset of appending.dat is 3, but got 0"), "invalid offset"),
+		},
+		{
+			name:  "Error - File already uploaded",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{SessionToken: testSessionToken, Offset: 11, Reader: strings.NewReader("")},
+			setupMocks: func() {
+				expectSession(fileUploaded, false)
+			},
+			expectedErr: e.NewInvalidInputError(fmt.Errorf("appending.dat is in UPLOADED status and cannot be appended"), ""),
+		},
+		{
+			name:  "Error - Open-ended upload",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{SessionToken: testSessionToken, Offset: 0, Reader: strings.NewReader("hel")},
+			setupMocks: func() {
+				session := sessionOf(fileInProgress)
+				session.OpenEnded = true
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(session, nil)
+			},
+			expectedErr: e.NewInvalidInputError(fmt.Errorf("upload of appending.dat is open-ended and cannot be appended"), ""),
+		},
+		{
+			name:  "Error - Session Not Found",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{SessionToken: testSessionToken, Offset: 0, Reader: strings.NewReader("hel")},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(nil, nil)
+			},
+			expectedErr: e.NewNotFoundError(errors.New("upload session not found or expired"), ""),
+		},
+		{
+			name:  "Error - GetSessionByToken DB Error",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{SessionToken: testSessionToken, Offset: 0, Reader: strings.NewReader("hel")},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name:  "Error - ExtendSession DB Error",
+			input: usecase.FileUploadUseCaseExecuteAppendInput{SessionToken: testSessionToken, Offset: 0, Reader: strings.NewReader("hel")},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(fileInProgress), nil)
+				mockSessionRepo.EXPECT().ExtendSession(ctx, uint64(9), gomock.Any()).Return(dbError)
+			},
+			expectedErr: dbError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			offset, err := uc.ExecuteAppend(ctx, tc.input)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedOffset, offset)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestFileUploadUseCase_ExecuteMultipart(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+
+	baseStorageDir := "/test/uploads"
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, time.Hour, entity.StorageCodecNone)
+
+	ctx := context.Background()
+	testFileID := uint64(95)
+	helloChecksum := "5e3235a8346e5a4585f8c58562f5052b8fe26a3bb122e1e96c76784964dfc461"
+	worldChecksum := "486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7"
+	chunkPaths := []string{"/test/uploads/.uploads/multi-token/0", "/test/uploads/.uploads/multi-token/1"}
+
+	// the multipart uploads are kept in the upload sessions, and their parts in the chunks of their files
+	sessions := make(map[string]*entity.UploadSession)
+	fileStatuses := make(map[uint64]entity.FileStatus)
+	savedParts := make(map[uint64]map[uint64]*entity.MultipartPart)
+	mockSessionRepo.EXPECT().GetSessionByToken(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, token string) (*entity.UploadSession, e.CustomError) {
+			return sessions[token], nil
+		}).AnyTimes()
+	mockSessionRepo.EXPECT().ExtendSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockSessionRepo.EXPECT().SavePart(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, session *entity.UploadSession, part *entity.MultipartPart) (*entity.MultipartPart, e.CustomError) {
+			if fileStatuses[session.FileID] != entity.FileStatusInitialized {
+				return nil, e.NewNotFoundError(errors.New("record not found"), "multipart upload is being completed or aborted")
+			}
+			replacedPart := savedParts[session.FileID][part.PartNumber]
+			savedParts[session.FileID][part.PartNumber] = part
+			return replacedPart, nil
+		}).AnyTimes()
+	createUpload := func(fileID uint64) *entity.MultipartUpload {
+		mockSessionRepo.EXPECT().CreateSession(ctx, gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, session *entity.UploadSession, file *entity.File, dirPath string) (*entity.UploadSession, e.CustomError) {
+				assert.True(t, session.Multipart)
+				assert.Equal(t, "multi.dat", session.TargetName)
+				assert.Equal(t, uint(0), file.TotalChunks)
+				assert.Equal(t, filepath.Join(baseStorageDir, ".multipart", session.Token), dirPath)
+				file.ID = fileID
+				session.ID = fileID
+				session.FileID = fileID
+				session.File = file
+				sessions[session.Token] = session
+				fileStatuses[fileID] = entity.FileStatusInitialized
+				savedParts[fileID] = make(map[uint64]*entity.MultipartPart)
+				return session, nil
+			})
+		mockStorageRepo.EXPECT().CreateDirectory(ctx, gomock.Any()).Return(nil)
+		mockFileRepo.EXPECT().CompareAndUpdateFileStatus(gomock.Any(), fileID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint64, currentStatuses []entity.FileStatus, status entity.FileStatus) (bool, e.CustomError) {
+				if !slices.Contains(currentStatuses, fileStatuses[fileID]) {
+					return false, nil
+				}
+				fileStatuses[fileID] = status
+				return true, nil
+			}).AnyTimes()
+		mockFileRepo.EXPECT().UpdateFileStatus(gomock.Any(), fileID, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uint64, status entity.FileStatus) e.CustomError {
+				fileStatuses[fileID] = status
+				return nil
+			}).AnyTimes()
+		mockFileRepo.EXPECT().GetChunksByFileID(gomock.Any(), fileID).
+			DoAndReturn(func(_ context.Context, _ uint64) ([]*entity.FileChunk, e.CustomError) {
+				chunks := []*entity.FileChunk{}
+				for _, part := range savedParts[fileID] {
+					chunks = append(chunks, &entity.FileChunk{
+						ParentID: fileID, ChunkNumber: part.PartNumber, Size: part.Size, Checksum: part.Checksum, FilePath: part.FilePath, Status: entity.FileStatusUploaded,
+					})
+				}
+				return chunks, nil
+			}).AnyTimes()
+		mockFileRepo.EXPECT().DeleteFileByID(gomock.Any(), fileID).
+			DoAndReturn(func(_ context.Context, _ uint64) ([]*entity.Blob, e.CustomError) {
+				for token, session := range sessions {
+					if session.FileID == fileID {
+						delete(sessions, token)
+					}
+				}
+				return nil, nil
+			})
+
+		upload, err := uc.ExecuteCreateMultipart(ctx, "multi.dat")
+		require.NoError(t, err)
+		return upload
+	}
+
+	// Error - The names of the directories of the parts, the uploads and the blobs can't be used
+	for _, reservedName := range []string{".multipart", ".uploads", ".objects"} {
+		_, err := uc.ExecuteCreateMultipart(ctx, reservedName)
+		assert.Error(t, err)
+		assert.Equal(t, "INVALID_INPUT", err.ErrorCode())
+	}
+
+	// Success - Create a multipart upload
+	upload := createUpload(90)
+	assert.Equal(t, "multi.dat", upload.FileName)
+	assert.Len(t, upload.ID, 64)
+	partsDir := filepath.Join(baseStorageDir, ".multipart", upload.ID)
+
+	// Error - Part of an unknown upload
+	_, err := uc.ExecuteUploadPart(ctx, usecase.FileUploadUseCaseExecuteUploadPartInput{FileName: "multi.dat", UploadID: "unknown", PartNumber: 1, Reader: strings.NewReader("hello ")})
+	assert.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.ErrorCode())
+
+	// Error - Part of an upload for another file
+	_, err = uc.ExecuteUploadPart(ctx, usecase.FileUploadUseCaseExecuteUploadPartInput{FileName: "other.dat", UploadID: upload.ID, PartNumber: 1, Reader: strings.NewReader("hello ")})
+	assert.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.ErrorCode())
+
+	// Error - Part of an upload session, which isn't a multipart upload
+	sessions["chunked"] = &entity.UploadSession{ID: 1, Token: "chunked", FileID: 1, TargetName: "multi.dat", ExpiresAt: time.Now().Add(time.Hour), File: &entity.File{ID: 1}}
+	_, err = uc.ExecuteUploadPart(ctx, usecase.FileUploadUseCaseExecuteUploadPartInput{FileName: "multi.dat", UploadID: "chunked", PartNumber: 1, Reader: strings.NewReader("hello ")})
+	assert.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.ErrorCode())
+
+	// Error - A multipart upload isn't uploaded by chunks
+	_, _, err = uc.ExecuteGetOffset(ctx, upload.ID)
+	assert.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.ErrorCode())
+
+	// Success - Upload parts in any order, where part 2 is replaced and part 3 is uploaded by mistake
+	partPaths := make(map[uint64]string)
+	for _, part := range []struct {
+		number   uint64
+		content  string
+		checksum string
+	}{
+		{2, "wrong", "checksum2"},
+		{2, "world", worldChecksum},
+		{1, "hello ", helloChecksum},
+		{3, "oversize", "checksum3"},
+	} {
+		var partPath string
+		mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecNone, part.checksum).
+			DoAndReturn(func(_ context.Context, r io.Reader, filePath string, _ entity.StorageCodec, _ string) (uint64, string, e.CustomError) {
+				assert.Equal(t, partsDir, filepath.Dir(filePath))
+				assert.True(t, strings.HasPrefix(filepath.Base(filePath), fmt.Sprintf("%d-", part.number)))
+				partPath = filePath
+				n, _ := io.Copy(io.Discard, r)
+				return uint64(n), part.checksum, nil
+			})
+		if replacedPath, ok := partPaths[part.number]; ok {
+			// the replaced part is deleted once the new one is recorded
+			mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), replacedPath).Return(nil)
+		}
+		uploadedPart, err := uc.ExecuteUploadPart(ctx, usecase.FileUploadUseCaseExecuteUploadPartInput{
+			FileName: "multi.dat", UploadID: upload.ID, PartNumber: part.number, Reader: strings.NewReader(part.content), Checksum: part.checksum,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, &entity.MultipartPart{PartNumber: part.number, Size: uint64(len(part.content)), Checksum: part.checksum, FilePath: partPath}, uploadedPart)
+		assert.Equal(t, uploadedPart, savedParts[90][part.number])
+		partPaths[part.number] = partPath
+	}
+
+	// Error - Invalid parts leave the upload as it is
+	for _, tc := range []struct {
+		name  string
+		input usecase.FileUploadUseCaseExecuteCompleteMultipartInput
+	}{
+		{
+			name:  "No part",
+			input: usecase.FileUploadUseCaseExecuteCompleteMultipartInput{FileName: "multi.dat", UploadID: upload.ID},
+		},
+		{
+			name:  "Parts out of order",
+			input: usecase.FileUploadUseCaseExecuteCompleteMultipartInput{FileName: "multi.dat", UploadID: upload.ID, Parts: []entity.MultipartPart{{PartNumber: 2}, {PartNumber: 1}}},
+		},
+		{
+			name:  "Part not uploaded",
+			input: usecase.FileUploadUseCaseExecuteCompleteMultipartInput{FileName: "multi.dat", UploadID: upload.ID, Parts: []entity.MultipartPart{{PartNumber: 1}, {PartNumber: 4}}},
+		},
+		{
+			name:  "Checksum mismatch",
+			input: usecase.FileUploadUseCaseExecuteCompleteMultipartInput{FileName: "multi.dat", UploadID: upload.ID, Parts: []entity.MultipartPart{{PartNumber: 1, Checksum: worldChecksum}}},
+		},
+		{
+			name:  "Last part larger than the others",
+			input: usecase.FileUploadUseCaseExecuteCompleteMultipartInput{FileName: "multi.dat", UploadID: upload.ID, Parts: []entity.MultipartPart{{PartNumber: 1}, {PartNumber: 3}}},
+		},
+		{
+			name: "Exceeding the limits",
+			input: usecase.FileUploadUseCaseExecuteCompleteMultipartInput{
+				FileName: "multi.dat", UploadID: upload.ID, Parts: []entity.MultipartPart{{PartNumber: 1}, {PartNumber: 2}}, Limits: entity.UploadLimits{MaxChunkCount: 1},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := uc.ExecuteCompleteMultipart(ctx, tc.input)
+			assert.Error(t, err)
+			assert.Equal(t, "INVALID_INPUT", err.ErrorCode())
+			assert.Equal(t, entity.FileStatusInitialized, fileStatuses[90])
+		})
+	}
+
+	// Error - The upload being completed by another request can't be completed
+	fileStatuses[90] = entity.FileStatusInProgress
+	_, err = uc.ExecuteCompleteMultipart(ctx, usecase.FileUploadUseCaseExecuteCompleteMultipartInput{FileName: "multi.dat", UploadID: upload.ID, Parts: []entity.MultipartPart{{PartNumber: 1}}})
+	assert.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.ErrorCode())
+	fileStatuses[90] = entity.FileStatusInitialized
+
+	// Success - Complete the upload with parts 1 and 2, which become the chunks of "hello world"
+	newChunks := func() []*entity.FileChunk {
+		return []*entity.FileChunk{
+			{ID: 1, ParentID: testFileID, ChunkNumber: 0, FilePath: chunkPaths[0], Status: entity.FileStatusInitialized},
+			{ID: 2, ParentID: testFileID, ChunkNumber: 1, FilePath: chunkPaths[1], Status: entity.FileStatusInitialized},
+		}
+	}
+	// the file is uploaded in a session of the owner, which replaces the file with the name on its commit
+	var sessionDirPath string
+	mockSessionRepo.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, session *entity.UploadSession, file *entity.File, dirPath string) (*entity.UploadSession, e.CustomError) {
+			assert.False(t, session.Multipart)
+			assert.Equal(t, "multi.dat", session.TargetName)
+			assert.Equal(t, "alice", session.Owner)
+			expectedFile := entity.NewFile("multi.dat", 11, "", "sha256", 2, 6)
+			expectedFile.StorageCodec = entity.StorageCodecNone
+			assert.Equal(t, expectedFile, file)
+			assert.Equal(t, filepath.Join(baseStorageDir, ".uploads", session.Token), dirPath)
+			sessionDirPath = dirPath
+			file.ID = testFileID
+			session.ID = 10
+			session.FileID = testFileID
+			session.File = file
+			return session, nil
+		})
+	mockStorageRepo.EXPECT().CreateDirectory(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, dirPath string) e.CustomError {
+			assert.Equal(t, sessionDirPath, dirPath)
+			return nil
+		})
+	mockFileRepo.EXPECT().GetChunksByFileID(gomock.Any(), testFileID).Return(newChunks(), nil)
+	mockFileRepo.EXPECT().UpdateFileStatus(gomock.Any(), testFileID, entity.FileStatusInProgress).Return(nil)
+	// the parts are moved to the blobs of the chunks as they are
+	blobPaths := make([]string, 2)
+	for i, checksum := range []string{helloChecksum, worldChecksum} {
+		mockStorageRepo.EXPECT().MoveFile(gomock.Any(), partPaths[uint64(i+1)], gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, dstPath string) e.CustomError {
+				assert.True(t, strings.HasPrefix(dstPath, "/test/uploads/.objects/"))
+				blobPaths[i] = dstPath
+				return nil
+			})
+		mockFileRepo.EXPECT().CompleteChunk(gomock.Any(), gomock.Any(), entity.StorageCodecNone).
+			DoAndReturn(func(_ context.Context, chunk *entity.FileChunk, _ entity.StorageCodec) ([]*entity.Blob, e.CustomError) {
+				assert.Equal(t, uint64(i), chunk.ChunkNumber)
+				assert.Equal(t, checksum, chunk.Checksum)
+				assert.Equal(t, blobPaths[i], chunk.FilePath)
+				chunk.BlobID = uint64(i + 1)
+				return nil, nil
+			})
+	}
+	mockFileRepo.EXPECT().CompareAndUpdateFileStatus(gomock.Any(), testFileID, []entity.FileStatus{entity.FileStatusInProgress, entity.FileStatusFailed}, entity.FileStatusVerifying).Return(true, nil)
+	mockFileRepo.EXPECT().GetChunksByFileID(gomock.Any(), testFileID).
+		DoAndReturn(func(_ context.Context, _ uint64) ([]*entity.FileChunk, e.CustomError) {
+			chunks := newChunks()
+			for i, chunk := range chunks {
+				chunk.FilePath = blobPaths[i]
+				chunk.BlobID = uint64(i + 1)
+				chunk.Status = entity.FileStatusUploaded
+			}
+			return chunks, nil
+		})
+	for i, content := range []string{"hello ", "world"} {
+		mockStorageRepo.EXPECT().ReadChunk(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), uint64(0), uint64(len(content))).
+			DoAndReturn(func(_ context.Context, w io.Writer, filePath string, _ entity.StorageCodec, _ uint64, _ uint64) e.CustomError {
+				assert.Equal(t, blobPaths[i], filePath)
+				_, _ = w.Write([]byte(content))
+				return nil
+			})
+	}
+	mockSessionRepo.EXPECT().CommitSession(gomock.Any(), gomock.Any()).Return(nil, nil, nil)
+	// all the chunks are stored in the blobs, so the directory of the session is deleted
+	mockStorageRepo.EXPECT().DeleteDirectory(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, dirPath string) e.CustomError {
+			assert.Equal(t, sessionDirPath, dirPath)
+			return nil
+		})
+	// the multipart upload is discarded, and the unused part 3 is deleted with the directory
+	mockStorageRepo.EXPECT().DeleteDirectory(gomock.Any(), partsDir).Return(nil)
+
+	file, err := uc.ExecuteCompleteMultipart(ctx, usecase.FileUploadUseCaseExecuteCompleteMultipartInput{
+		FileName: "multi.dat",
+		UploadID: upload.ID,
+		Parts:    []entity.MultipartPart{{PartNumber: 1, Checksum: helloChecksum}, {PartNumber: 2}},
+		Owner:    "alice",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9", file.Checksum)
+
+	// Error - The upload can't be completed twice
+	_, err = uc.ExecuteCompleteMultipart(ctx, usecase.FileUploadUseCaseExecuteCompleteMultipartInput{FileName: "multi.dat", UploadID: upload.ID, Parts: []entity.MultipartPart{{PartNumber: 1}}})
+	assert.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.ErrorCode())
+
+	// Error - A part written while the upload is being completed is deleted, and the upload can't be aborted then
+	upload = createUpload(91)
+	partsDir = filepath.Join(baseStorageDir, ".multipart", upload.ID)
+	fileStatuses[91] = entity.FileStatusInProgress
+	var partPath string
+	mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecNone, "").
+		DoAndReturn(func(_ context.Context, r io.Reader, filePath string, _ entity.StorageCodec, _ string) (uint64, string, e.CustomError) {
+			partPath = filePath
+			n, _ := io.Copy(io.Discard, r)
+			return uint64(n), helloChecksum, nil
+		})
+	mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, filePath string) e.CustomError {
+			assert.Equal(t, partPath, filePath)
+			return nil
+		})
+	_, err = uc.ExecuteUploadPart(ctx, usecase.FileUploadUseCaseExecuteUploadPartInput{FileName: "multi.dat", UploadID: upload.ID, PartNumber: 1, Reader: strings.NewReader("hello ")})
+	assert.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.ErrorCode())
+	assert.Empty(t, savedParts[91])
+	err = uc.ExecuteAbortMultipart(ctx, upload.ID)
+	assert.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.ErrorCode())
+
+	// Success - Abort an upload, which can be done only once
+	fileStatuses[91] = entity.FileStatusInitialized
+	mockStorageRepo.EXPECT().DeleteDirectory(ctx, partsDir).Return(nil)
+	assert.NoError(t, uc.ExecuteAbortMultipart(ctx, upload.ID))
+	err = uc.ExecuteAbortMultipart(ctx, upload.ID)
+	assert.Error(t, err)
+	assert.Equal(t, "NOT_FOUND", err.ErrorCode())
+}
+
+func TestFileUploadUseCase_ExecuteAbort(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+
+	baseStorageDir := "/test/uploads"
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, time.Hour, entity.StorageCodecNone)
+
+	ctx := context.Background()
+	testFileID := uint64(110)
+	testSessionToken := "abort-token"
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+	storageError := e.NewFileStorageError(errors.New("storage error"), "")
+
+	sessionOf := func(status entity.FileStatus) *entity.UploadSession {
+		return &entity.UploadSession{
+			ID:         11,
+			Token:      testSessionToken,
+			FileID:     testFileID,
+			TargetName: "aborting.dat",
+			ExpiresAt:  time.Now().Add(time.Hour),
+			File:       &entity.File{ID: testFileID, Name: "aborting.dat", Status: status},
+		}
+	}
+	// the blob of the uploaded chunk no other chunk refers to
+	releasedBlob := &entity.Blob{ID: 1, FilePath: "/test/uploads/.objects/5e/5eb1"}
+
+	tests := []struct {
+		name        string
+		setupMocks  func()
+		expectedErr e.CustomError
+	}{
+		{
+			name: "Success - Upload in progress is discarded",
+			setupMocks: func() {
+				// the session is read again once the chunks being written are waited for
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(entity.FileStatusInProgress), nil).Times(2)
+				mockFileRepo.EXPECT().DeleteFileByID(ctx, testFileID).Return([]*entity.Blob{releasedBlob}, nil)
+				// the directory of the session is deleted once along with the partial chunks
+				mockStorageRepo.EXPECT().DeleteDirectory(ctx, "/test/uploads/.uploads/abort-token").Return(nil)
+				mockStorageRepo.EXPECT().DeleteFile(ctx, releasedBlob.FilePath).Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Success - Corrupt upload is discarded",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(entity.FileStatusCorrupt), nil).Times(2)
+				mockFileRepo.EXPECT().DeleteFileByID(ctx, testFileID).Return(nil, nil)
+				mockStorageRepo.EXPECT().DeleteDirectory(ctx, "/test/uploads/.uploads/abort-token").Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Error - Session Not Found",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(nil, nil)
+			},
+			expectedErr: e.NewNotFoundError(errors.New("upload session not found or expired"), ""),
+		},
+		{
+			name: "Error - Upload already committed",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(entity.FileStatusUploaded), nil)
+			},
+			expectedErr: e.NewConflictError(errors.New("upload of aborting.dat is already committed"), ""),
+		},
+		{
+			name: "Error - Upload being committed",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(entity.FileStatusVerifying), nil)
+			},
+			expectedErr: e.NewConflictError(errors.New("upload of aborting.dat is being committed"), ""),
+		},
+		{
+			name: "Error - Upload committed by the last chunk being written",
+			setupMocks: func() {
+				gomock.InOrder(
+					mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(entity.FileStatusInProgress), nil),
+					mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(entity.FileStatusUploaded), nil),
+				)
+			},
+			expectedErr: e.NewConflictError(errors.New("upload of aborting.dat is already committed"), ""),
+		},
+		{
+			name: "Error - DeleteDirectory Storage Error",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(entity.FileStatusInProgress), nil).Times(2)
+				mockFileRepo.EXPECT().DeleteFileByID(ctx, testFileID).Return(nil, nil)
+				mockStorageRepo.EXPECT().DeleteDirectory(ctx, "/test/uploads/.uploads/abort-token").Return(storageError)
+			},
+			expectedErr: storageError,
+		},
+		{
+			name: "Error - DeleteFileByID DB Error",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(entity.FileStatusInProgress), nil).Times(2)
+				mockFileRepo.EXPECT().DeleteFileByID(ctx, testFileID).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			err := uc.ExecuteAbort(ctx, testSessionToken)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestFileUploadUseCase_ExecuteAbort_CancelsChunkWrites(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, "/test/uploads", time.Hour, entity.StorageCodecNone)
+
+	ctx := context.Background()
+	testSessionToken := "abort-token"
+	testChunkPath := "/test/uploads/.uploads/abort-token/0"
+	session := &entity.UploadSession{
+		ID:         11,
+		Token:      testSessionToken,
+		FileID:     110,
+		TargetName: "aborting.dat",
+		ExpiresAt:  time.Now().Add(time.Hour),
+		File:       &entity.File{ID: 110, Name: "aborting.dat", Status: entity.FileStatusInProgress},
+	}
+	// the chunk is large enough not to be filled before the abort
+	file := &entity.File{ID: 110, Size: 1 << 40, ChunkSize: 1 << 40, Status: entity.FileStatusInProgress, TotalChunks: 1}
+	chunk := &entity.FileChunk{ID: 1100, ParentID: 110, ChunkNumber: 0, FilePath: testChunkPath, Status: entity.FileStatusInitialized}
+
+	mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(session, nil).AnyTimes()
+	mockSessionRepo.EXPECT().ExtendSession(ctx, session.ID, gomock.Any()).Return(nil)
+	mockFileRepo.EXPECT().GetFileAndChunk(ctx, session.FileID, chunk.ChunkNumber).Return(file, chunk, nil)
+	mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{chunk.ID}, entity.FileStatusInProgress).Return(nil)
+
+	// the chunk being written keeps reading until the upload is aborted, and a chunk sent meanwhile is rejected
+	started := make(chan struct{})
+	var rejectedErr e.CustomError
+	mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).DoAndReturn(
+		func(_ context.Context, reader io.Reader, _ string, _ uint64) (uint64, e.CustomError) {
+			close(started)
+			written, err := io.Copy(io.Discard, reader)
+			assert.Error(t, err)
+			rejectedErr = uc.Execute(ctx, usecase.FileUploadUseCaseExecuteInput{SessionToken: testSessionToken, ChunkNumber: 0, Reader: strings.NewReader("late")})
+			return uint64(written), e.NewFileStorageError(err, "failed to read from reader")
+		},
+	)
+	// the content is deleted only after the chunk stops being written
+	mockFileRepo.EXPECT().DeleteFileByID(ctx, session.FileID).Return(nil, nil)
+	mockStorageRepo.EXPECT().DeleteDirectory(ctx, "/test/uploads/.uploads/abort-token").Return(nil)
+
+	done := make(chan e.CustomError)
+	go func() {
+		// the body never ends, like the one of a client still sending the chunk
+		done <- uc.Execute(ctx, usecase.FileUploadUseCaseExecuteInput{SessionToken: testSessionToken, ChunkNumber: 0, Reader: endlessReader{}})
+	}()
+
+	<-started
+	require.NoError(t, uc.ExecuteAbort(ctx, testSessionToken))
+
+	writeErr := <-done
+	require.Error(t, writeErr)
+	assert.Equal(t, "FILE_STORAGE", writeErr.ErrorCode())
+	require.Error(t, rejectedErr)
+	assert.Equal(t, "CONFLICT", rejectedErr.ErrorCode())
+	assert.Contains(t, rejectedErr.Error(), "upload is being aborted")
+}
+
+// endlessReader reads zeros forever
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestFileUploadUseCase_ExecuteSweepSessions(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+
+	baseStorageDir := "/test/uploads"
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, time.Hour, entity.StorageCodecNone)
+
+	ctx := context.Background()
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+
+	expiredAt := time.Now().Add(-time.Minute)
+	committedSession := &entity.UploadSession{ID: 1, Token: "committed", FileID: 1, ExpiresAt: expiredAt, File: &entity.File{ID: 1, Status: entity.FileStatusUploaded}}
+	verifyingSession := &entity.UploadSession{ID: 2, Token: "verifying", FileID: 2, ExpiresAt: expiredAt, File: &entity.File{ID: 2, Status: entity.FileStatusVerifying}}
+	abandonedSession := &entity.UploadSession{ID: 3, Token: "abandoned", FileID: 3, ExpiresAt: expiredAt, File: &entity.File{ID: 3, Status: entity.FileStatusFailed}}
+	multipartSession := &entity.UploadSession{ID: 4, Token: "multipart", FileID: 4, Multipart: true, ExpiresAt: expiredAt, File: &entity.File{ID: 4, Status: entity.FileStatusInitialized}}
+
+	tests := []struct {
+		name              string
+		setupMocks        func()
+		expectedDiscarded int
+		expectedErr       e.CustomError
+	}{
+		{
+			name: "Success - Expired sessions are swept",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetExpiredSessions(ctx, gomock.Any()).Return([]*entity.UploadSession{committedSession, verifyingSession, abandonedSession, multipartSession}, nil)
+				// only the session is deleted for the committed file, and the file being verified is left as it is
+				mockSessionRepo.EXPECT().DeleteSession(ctx, committedSession.ID).Return(nil)
+				mockFileRepo.EXPECT().DeleteFileByID(ctx, abandonedSession.FileID).Return(nil, nil)
+				mockStorageRepo.EXPECT().DeleteDirectory(ctx, "/test/uploads/.uploads/abandoned").Return(nil)
+				// the parts of a multipart upload are deleted along with it
+				mockFileRepo.EXPECT().DeleteFileByID(ctx, multipartSession.FileID).Return(nil, nil)
+				mockStorageRepo.EXPECT().DeleteDirectory(ctx, "/test/uploads/.multipart/multipart").Return(nil)
+			},
+			expectedDiscarded: 2,
+		},
+		{
+			name: "Success - No expired session",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetExpiredSessions(ctx, gomock.Any()).Return([]*entity.UploadSession{}, nil)
+			},
+			expectedDiscarded: 0,
+		},
+		{
+			name: "Error - GetExpiredSessions DB Error",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetExpiredSessions(ctx, gomock.Any()).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+		{
+			name: "Error - DeleteFileByID DB Error",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetExpiredSessions(ctx, gomock.Any()).Return([]*entity.UploadSession{abandonedSession}, nil)
+				mockFileRepo.EXPECT().DeleteFileByID(ctx, abandonedSession.FileID).Return(nil, dbError)
+			},
+			expectedErr: dbError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			discarded, err := uc.ExecuteSweepSessions(ctx)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedDiscarded, discarded)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestFileUploadUseCase_ExecutePut(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+
+	baseStorageDir := "/test/uploads"
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, time.Hour, entity.StorageCodecNone)
+
+	ctx := context.Background()
+	testFileName := "small.txt"
+	testContent := "hello world"
+	contentSum := sha256.Sum256([]byte(testContent))
+	testChecksum := fmt.Sprintf("%x", contentSum)
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+	storageError := e.NewFileStorageError(errors.New("storage error"), "")
+	previousFile := &entity.File{
+		ID:   5,
+		Name: testFileName,
+		Size: 20,
+		FileChunks: []entity.FileChunk{
+			{ID: 1, ParentID: 5, ChunkNumber: 0, FilePath: "/test/uploads/.uploads/previous-token/0"},
+		},
+	}
+
+	previousBlobFile := &entity.File{
+		ID:   5,
+		Name: testFileName,
+		Size: 20,
+		FileChunks: []entity.FileChunk{
+			{ID: 1, ParentID: 5, ChunkNumber: 0, FilePath: "/test/uploads/.objects/3a/3a0d", BlobID: 3},
+		},
+	}
+	releasedBlob := &entity.Blob{ID: 3, FilePath: "/test/uploads/.objects/3a/3a0d"}
+
+	// the chunk is written to a new blob path, which is recorded to check that the same path is deleted or bound to the
+	// file
+	var writtenPath string
+	writeChunk := func(_ context.Context, reader io.Reader, filePath string, _ entity.StorageCodec, _ string) (uint64, string, e.CustomError) {
+		writtenPath = filePath
+		content, _ := io.ReadAll(reader)
+		chunkSum := sha256.Sum256(content)
+		return uint64(len(content)), fmt.Sprintf("%x", chunkSum), nil
+	}
+	expectWrittenFileDeleted := func() {
+		mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, filePath string) e.CustomError {
+			assert.Equal(t, writtenPath, filePath)
+			return nil
+		})
+	}
+
+	tests := []struct {
+		name        string
+		input       usecase.FileUploadUseCaseExecutePutInput
+		setupMocks  func()
+		expectedErr e.CustomError
+	}{
+		{
+			name: "Success - New file",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(testContent), Checksum: testChecksum, ChecksumAlgorithm: "sha256", MaxSize: 4096,
+			},
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecNone, "").DoAndReturn(writeChunk)
+				mockFileRepo.EXPECT().ReplaceFile(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, file *entity.File) (*entity.File, []*entity.Blob, e.CustomError) {
+					assert.Equal(t, testFileName, file.Name)
+					assert.Equal(t, uint64(len(testContent)), file.Size)
+					assert.Equal(t, testChecksum, file.Checksum)
+					assert.Equal(t, entity.FileStatusUploaded, file.Status)
+					assert.NotEmpty(t, file.MerkleRoot)
+					assert.Len(t, file.FileChunks, 1)
+					assert.Equal(t, writtenPath, file.FileChunks[0].FilePath)
+					assert.True(t, strings.HasPrefix(writtenPath, "/test/uploads/.objects/"))
+					return nil, nil, nil
+				})
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Success - Previous file is replaced",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(testContent), ChecksumAlgorithm: "sha256", MaxSize: 4096,
+			},
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecNone, "").DoAndReturn(writeChunk)
+				mockFileRepo.EXPECT().ReplaceFile(gomock.Any(), gomock.Any()).Return(previousFile, nil, nil)
+				mockStorageRepo.EXPECT().DeleteDirectory(gomock.Any(), "/test/uploads/.uploads/previous-token").Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Success - Previous file stored in blobs is replaced",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(testContent), ChecksumAlgorithm: "sha256", MaxSize: 4096,
+			},
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecNone, "").DoAndReturn(writeChunk)
+				mockFileRepo.EXPECT().ReplaceFile(gomock.Any(), gomock.Any()).Return(previousBlobFile, []*entity.Blob{releasedBlob}, nil)
+				mockStorageRepo.EXPECT().DeleteFile(gomock.Any(), releasedBlob.FilePath).Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Success - Existing blob of the content is shared",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(testContent), ChecksumAlgorithm: "sha256", MaxSize: 4096,
+			},
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecNone, "").DoAndReturn(writeChunk)
+				mockFileRepo.EXPECT().ReplaceFile(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, file *entity.File) (*entity.File, []*entity.Blob, e.CustomError) {
+					file.FileChunks[0].FilePath = "/test/uploads/.objects/7e/7e57"
+					file.FileChunks[0].BlobID = 7
+					return nil, nil, nil
+				})
+				// the written content replaces the one of the existing blob
+				mockStorageRepo.EXPECT().MoveFile(gomock.Any(), gomock.Any(), "/test/uploads/.objects/7e/7e57").DoAndReturn(func(_ context.Context, srcPath string, _ string) e.CustomError {
+					assert.Equal(t, writtenPath, srcPath)
+					return nil
+				})
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Success - Storage codec",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(testContent), ChecksumAlgorithm: "sha256", MaxSize: 4096, StorageCodec: entity.StorageCodecZstd,
+			},
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecZstd, "").DoAndReturn(writeChunk)
+				mockFileRepo.EXPECT().ReplaceFile(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, file *entity.File) (*entity.File, []*entity.Blob, e.CustomError) {
+					assert.Equal(t, entity.StorageCodecZstd, file.StorageCodec)
+					return nil, nil, nil
+				})
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "Error - Unsupported storage codec",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(testContent), ChecksumAlgorithm: "sha256", MaxSize: 4096, StorageCodec: "lz4",
+			},
+			setupMocks:  func() {},
+			expectedErr: e.NewInvalidInputError(fmt.Errorf("unsupported storage codec \"lz4\""), "invalid storage codec"),
+		},
+		{
+			name: "Error - Checksum mismatch",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(testContent), Checksum: strings.Repeat("0", 64), ChecksumAlgorithm: "sha256", MaxSize: 4096,
+			},
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecNone, "").DoAndReturn(writeChunk)
+				expectWrittenFileDeleted()
+			},
+			expectedErr: e.NewChecksumMismatchError(fmt.Errorf("%s is declared, but the uploaded content has %s", strings.Repeat("0", 64), testChecksum), "small.txt is corrupt"),
+		},
+		{
+			name: "Error - Content exceeds max size",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(testContent), ChecksumAlgorithm: "sha256", MaxSize: 5,
+			},
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecNone, "").DoAndReturn(writeChunk)
+				expectWrittenFileDeleted()
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("content of small.txt exceeds 5 bytes"), ""),
+		},
+		{
+			name: "Error - Empty content",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(""), ChecksumAlgorithm: "sha256", MaxSize: 4096,
+			},
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecNone, "").DoAndReturn(writeChunk)
+				expectWrittenFileDeleted()
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("empty files are not supported"), ""),
+		},
+		{
+			name: "Error - Reserved file name",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: ".uploads", Reader: strings.NewReader(testContent), ChecksumAlgorithm: "sha256",
+			},
+			setupMocks:  func() {},
+			expectedErr: e.NewInvalidInputError(errors.New(".uploads is reserved"), ""),
+		},
+		{
+			name: "Error - Invalid checksum algorithm",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(testContent), ChecksumAlgorithm: "md4",
+			},
+			setupMocks:  func() {},
+			expectedErr: e.NewInvalidInputError(errors.New(`unsupported checksum algorithm "md4"`), ""),
+		},
+		{
+			name: "Error - WriteChunk Storage Error",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(testContent), ChecksumAlgorithm: "sha256", MaxSize: 4096,
+			},
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecNone, "").DoAndReturn(func(_ context.Context, _ io.Reader, filePath string, _ entity.StorageCodec, _ string) (uint64, string, e.CustomError) {
+					writtenPath = filePath
+					return 0, "", storageError
+				})
+				expectWrittenFileDeleted()
+			},
+			expectedErr: storageError,
+		},
+		{
+			name: "Error - ReplaceFile DB Error",
+			input: usecase.FileUploadUseCaseExecutePutInput{
+				FileName: testFileName, Reader: strings.NewReader(testContent), ChecksumAlgorithm: "sha256", MaxSize: 4096,
+			},
+			setupMocks: func() {
+				mockStorageRepo.EXPECT().WriteChunk(ctx, gomock.Any(), gomock.Any(), entity.StorageCodecNone, "").DoAndReturn(writeChunk)
+				mockFileRepo.EXPECT().ReplaceFile(gomock.Any(), gomock.Any()).Return(nil, nil, dbError)
+				expectWrittenFileDeleted()
+			},
+			expectedErr: dbError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			file, err := uc.ExecutePut(ctx, tc.input)
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, testFileName, file.Name)
+				assert.Equal(t, testChecksum, file.Checksum)
+			} else {
+				assert.Error(t, err)
+				assert.Nil(t, file)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestFileUploadUseCase_ExecuteOpenEndedChunk(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+
+	baseStorageDir := "/test/uploads"
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, time.Hour, entity.StorageCodecNone)
+
+	ctx := context.Background()
+	testFileID := uint64(60)
+	testChunkID := uint64(600)
+	testChunkNumber := uint64(2)
+	testSessionToken := "stream-token"
+	testChunkPath := "/test/uploads/.uploads/stream-token/2"
+	testChunkChecksum := "486ea46224d1bb4fb680f34f7c9ad96a8f24ec88be73ea8e5a6c65260e9cb8a7"
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+
+	// the file of an open-ended upload has no size until it's committed
+	fileOf := func(status entity.FileStatus) *entity.File {
+		return &entity.File{ID: testFileID, ChunkSize: 6, ChecksumAlgorithm: "sha256", Status: status}
+	}
+	chunkOf := func(status entity.FileStatus) *entity.FileChunk {
+		return &entity.FileChunk{ID: testChunkID, ParentID: testFileID, ChunkNumber: testChunkNumber, FilePath: testChunkPath, Status: status}
+	}
+	testSession := &entity.UploadSession{
+		ID:         6,
+		Token:      testSessionToken,
+		FileID:     testFileID,
+		TargetName: "stream.tar",
+		OpenEnded:  true,
+		ExpiresAt:  time.Now().Add(time.Hour),
+		File:       fileOf(entity.FileStatusInProgress),
+	}
+	// expectChunk expects the session to be looked up and extended, and the chunk record to be created on demand
+	expectChunk := func(file *entity.File, chunk *entity.FileChunk) {
+		mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(testSession, nil)
+		mockFileRepo.EXPECT().CreateChunkIfNotExists(ctx, testFileID, testChunkNumber, testChunkPath).Return(nil)
+		mockFileRepo.EXPECT().GetFileAndChunk(ctx, testFileID, testChunkNumber).Return(file, chunk, nil)
+	}
+	// expectStore expects the committed chunk to be stored as a new blob
+	expectStore := func() {
+		mockStorageRepo.EXPECT().MoveFile(ctx, testChunkPath, gomock.Any()).Return(nil)
+		mockFileRepo.EXPECT().CompleteChunk(ctx, gomock.Any(), gomock.Any()).Return(nil, nil)
+	}
+
+	tests := []struct {
+		name        string
+		content     string
+		setupMocks  func()
+		expectedErr e.CustomError
+	}{
+		{
+			name:    "Success - Chunk shorter than chunk size (not verified until commit)",
+			content: "world",
+			setupMocks: func() {
+				expectChunk(fileOf(entity.FileStatusInProgress), chunkOf(entity.FileStatusInitialized))
+				mockSessionRepo.EXPECT().ExtendSession(ctx, testSession.ID, gomock.Any()).Return(nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(uint64(5), nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(uint64(5), testChunkChecksum, nil)
+				expectStore()
+			},
+			expectedErr: nil,
+		},
+		{
+			name:    "Success - Failed chunk is sent again",
+			content: "hello ",
+			setupMocks: func() {
+				expectChunk(fileOf(entity.FileStatusFailed), chunkOf(entity.FileStatusFailed))
+				mockSessionRepo.EXPECT().ExtendSession(ctx, testSession.ID, gomock.Any()).Return(nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(uint64(6), nil)
+				mockStorageRepo.EXPECT().CommitPartialChunk(ctx, testChunkPath, gomock.Any(), testChunkChecksum).Return(uint64(6), testChunkChecksum, nil)
+				expectStore()
+			},
+			expectedErr: nil,
+		},
+		{
+			name:    "Error - Chunk larger than chunk size",
+			content: "hello world",
+			setupMocks: func() {
+				expectChunk(fileOf(entity.FileStatusInProgress), chunkOf(entity.FileStatusInitialized))
+				mockSessionRepo.EXPECT().ExtendSession(ctx, testSession.ID, gomock.Any()).Return(nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(uint64(7), nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(uint64(0), nil)
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("chunk 2 of stream.tar needs to be 6 bytes, but got more"), "invalid chunk size"),
+		},
+		{
+			name:    "Error - Empty chunk",
+			content: "",
+			setupMocks: func() {
+				expectChunk(fileOf(entity.FileStatusInProgress), chunkOf(entity.FileStatusInitialized))
+				mockSessionRepo.EXPECT().ExtendSession(ctx, testSession.ID, gomock.Any()).Return(nil)
+				mockFileRepo.EXPECT().UpdateChunksStatus(ctx, []uint64{testChunkID}, entity.FileStatusInProgress).Return(nil)
+				mockStorageRepo.EXPECT().AppendPartialChunk(ctx, gomock.Any(), testChunkPath, uint64(0)).Return(uint64(0), nil)
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("chunk 2 of stream.tar needs to be 6 bytes, but got 0 bytes"), "invalid chunk size"),
+		},
+		{
+			name:    "Error - Upload already verifying",
+			content: "world",
+			setupMocks: func() {
+				expectChunk(fileOf(entity.FileStatusVerifying), chunkOf(entity.FileStatusUploaded))
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("file upload needs to be initialized"), ""),
+		},
+		{
+			name:    "Error - CreateChunkIfNotExists DB Error",
+			content: "world",
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(testSession, nil)
+				mockFileRepo.EXPECT().CreateChunkIfNotExists(ctx, testFileID, testChunkNumber, testChunkPath).Return(dbError)
+			},
+			expectedErr: dbError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+			err := uc.Execute(ctx, usecase.FileUploadUseCaseExecuteInput{
+				SessionToken: testSessionToken,
+				ChunkNumber:  testChunkNumber,
+				Reader:       strings.NewReader(tc.content),
+				Checksum:     testChunkChecksum,
+			})
+
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
+				assert.Contains(t, err.Error(), tc.expectedErr.Error())
+			}
+		})
+	}
+}
+
+func TestFileUploadUseCase_ExecuteCommit(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockFileRepo := mock.NewMockFileRepository(mockCtrl)
+	mockSessionRepo := mock.NewMockUploadSessionRepository(mockCtrl)
+	mockStorageRepo := mock.NewMockFileStorageRepository(mockCtrl)
+
+	baseStorageDir := "/test/uploads"
+	uc := usecase.NewFileUploadUseCase(mockFileRepo, mockSessionRepo, mockStorageRepo, baseStorageDir, time.Hour, entity.StorageCodecNone)
+
+	ctx := context.Background()
+	testFileID := uint64(70)
+	testSessionToken := "commit-token"
+	// "hello world" streamed in chunks of 6 bytes
+	testChecksum := "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	dbError := e.NewDatabaseError(errors.New("db error"), "")
+
+	sessionOf := func(openEnded bool, status entity.FileStatus) *entity.UploadSession {
+		return &entity.UploadSession{
+			ID:         7,
+			Token:      testSessionToken,
+			FileID:     testFileID,
+			TargetName: "stream.tar",
+			OpenEnded:  openEnded,
+			ExpiresAt:  time.Now().Add(time.Hour),
+			File:       &entity.File{ID: testFileID, ChunkSize: 6, ChecksumAlgorithm: "sha256", Status: status},
+		}
+	}
+	chunkOf := func(chunkNumber uint64, size uint64, status entity.FileStatus) *entity.FileChunk {
+		return &entity.FileChunk{
+			ID:          100 + chunkNumber,
+			ParentID:    testFileID,
+			ChunkNumber: chunkNumber,
+			Size:        size,
+			FilePath:    fmt.Sprintf("/test/uploads/.objects/0%d/0%d5e", chunkNumber, chunkNumber),
+			BlobID:      chunkNumber + 1,
+			Status:      status,
+		}
+	}
+	uploadedChunks := []*entity.FileChunk{chunkOf(0, 6, entity.FileStatusUploaded), chunkOf(1, 5, entity.FileStatusUploaded)}
+	// expectVerification expects the chunks to be read as "hello " and "world"
+	expectVerification := func() {
+		mockFileRepo.EXPECT().CompareAndUpdateFileStatus(ctx, testFileID, []entity.FileStatus{entity.FileStatusInProgress, entity.FileStatusFailed}, entity.FileStatusVerifying).Return(true, nil)
+		mockFileRepo.EXPECT().GetChunksByFileID(gomock.Any(), testFileID).Return(uploadedChunks, nil)
+		for i, content := range []string{"hello ", "world"} {
+			mockStorageRepo.EXPECT().ReadChunk(gomock.Any(), gomock.Any(), uploadedChunks[i].FilePath, gomock.Any(), uint64(0), uint64(len(content))).
+				DoAndReturn(func(_ context.Context, w io.Writer, _ string, _ entity.StorageCodec, _ uint64, _ uint64) e.CustomError {
+					_, _ = w.Write([]byte(content))
+					return nil
+				})
+		}
+	}
+
+	tests := []struct {
+		name        string
+		input       usecase.FileUploadUseCaseExecuteCommitInput
+		setupMocks  func()
+		expectedErr e.CustomError
+	}{
+		{
+			name:  "Success - Committed with checksum",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 11, Checksum: testChecksum},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(true, entity.FileStatusInProgress), nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(uploadedChunks, nil)
+				mockFileRepo.EXPECT().UpdateFileLayout(ctx, testFileID, uint64(11), uint(2), testChecksum).Return(nil)
+				expectVerification()
+				mockSessionRepo.EXPECT().CommitSession(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, session *entity.UploadSession) (*entity.File, []*entity.Blob, e.CustomError) {
+						assert.Equal(t, "stream.tar", session.File.Name)
+						assert.Equal(t, testChecksum, session.File.Checksum)
+						assert.Equal(t, "1611ac016713113b29ef4acfa37566046c257ee45139b112939f43a4c20ca8a2", session.File.MerkleRoot)
+						return nil, nil, nil
+					})
+				mockStorageRepo.EXPECT().DeleteDirectory(gomock.Any(), "/test/uploads/.uploads/commit-token").Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:  "Success - Committed without checksum",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 11},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(true, entity.FileStatusFailed), nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(uploadedChunks, nil)
+				mockFileRepo.EXPECT().UpdateFileLayout(ctx, testFileID, uint64(11), uint(2), "").Return(nil)
+				expectVerification()
+				mockSessionRepo.EXPECT().CommitSession(gomock.Any(), gomock.Any()).Return(nil, nil, nil)
+				mockStorageRepo.EXPECT().DeleteDirectory(gomock.Any(), "/test/uploads/.uploads/commit-token").Return(nil)
+			},
+			expectedErr: nil,
+		},
+		{
+			name:  "Error - Checksum mismatch",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 11, Checksum: strings.Repeat("0", 64)},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(true, entity.FileStatusInProgress), nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(uploadedChunks, nil)
+				mockFileRepo.EXPECT().UpdateFileLayout(ctx, testFileID, uint64(11), uint(2), strings.Repeat("0", 64)).Return(nil)
+				expectVerification()
+				mockFileRepo.EXPECT().UpdateFileStatus(gomock.Any(), testFileID, entity.FileStatusCorrupt).Return(nil)
+			},
+			expectedErr: e.NewChecksumMismatchError(fmt.Errorf("%s is declared", strings.Repeat("0", 64)), "stream.tar is corrupt"),
+		},
+		{
+			name:  "Error - Total size doesn't match the chunks",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 12},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(true, entity.FileStatusInProgress), nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(uploadedChunks, nil)
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("11 bytes of stream.tar are uploaded, but the total size is 12 bytes"), "invalid chunk layout"),
+		},
+		{
+			name:  "Error - Chunk missing",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 11},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(true, entity.FileStatusInProgress), nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return([]*entity.FileChunk{uploadedChunks[1]}, nil)
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("chunk 0 of stream.tar is not uploaded"), "invalid chunk layout"),
+		},
+		{
+			name:  "Error - Chunk not uploaded yet",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 11},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(true, entity.FileStatusInProgress), nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return([]*entity.FileChunk{uploadedChunks[0], chunkOf(1, 0, entity.FileStatusFailed)}, nil)
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("chunk 1 of stream.tar is in FAILED status"), "invalid chunk layout"),
+		},
+		{
+			name:  "Error - Short chunk in the middle",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 10},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(true, entity.FileStatusInProgress), nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return([]*entity.FileChunk{chunkOf(0, 5, entity.FileStatusUploaded), chunkOf(1, 5, entity.FileStatusUploaded)}, nil)
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("chunk 0 of stream.tar is 5 bytes, but every chunk except the last one needs to be 6 bytes"), "invalid chunk layout"),
+		},
+		{
+			name:  "Error - Exceeds upload limits",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 11, Limits: entity.UploadLimits{MaxFileSize: 10}},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(true, entity.FileStatusInProgress), nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(uploadedChunks, nil)
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("file size 11 exceeds the maximum 10"), "invalid upload layout"),
+		},
+		{
+			name:  "Error - Upload isn't open-ended",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 11},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(false, entity.FileStatusInProgress), nil)
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("upload of stream.tar is committed once all of its chunks are uploaded"), ""),
+		},
+		{
+			name:  "Error - Upload already committed",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 11},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(true, entity.FileStatusUploaded), nil)
+			},
+			expectedErr: e.NewConflictError(errors.New("upload of stream.tar is already committed"), ""),
+		},
+		{
+			name:  "Error - No chunk uploaded",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 11},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(true, entity.FileStatusInitialized), nil)
+			},
+			expectedErr: e.NewInvalidInputError(errors.New("upload of stream.tar is in INITIALIZED status and cannot be committed"), ""),
+		},
+		{
+			name:  "Error - Session Not Found",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 11},
+			setupMocks: func() {
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(nil, nil)
+			},
+			expectedErr: e.NewNotFoundError(errors.New("upload session not found or expired"), ""),
+		},
+		{
+			name:  "Error - UpdateFileLayout DB Error",
+			input: usecase.FileUploadUseCaseExecuteCommitInput{SessionToken: testSessionToken, TotalSize: 11},
 			setupMocks: func() {
-				mockFileRepo.EXPECT().UpdateFileAndChunkStatus(ctx, testFileID, []uint64{testChunkID}, entity.FileStatusFailed).Return(dbError)
+				mockSessionRepo.EXPECT().GetSessionByToken(ctx, testSessionToken).Return(sessionOf(true, entity.FileStatusInProgress), nil)
+				mockFileRepo.EXPECT().GetChunksByFileID(ctx, testFileID).Return(uploadedChunks, nil)
+				mockFileRepo.EXPECT().UpdateFileLayout(ctx, testFileID, uint64(11), uint(2), "").Return(dbError)
 			},
 			expectedErr: dbError,
 		},
@@ -590,12 +3133,16 @@ func TestFileUploadUseCase_ExecuteFailRecovery(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
-			err := uc.ExecuteFailRecovery(ctx, tc.fileID, tc.chunkID)
+			file, err := uc.ExecuteCommit(ctx, tc.input)
 
 			if tc.expectedErr == nil {
 				assert.NoError(t, err)
+				assert.Equal(t, uint64(11), file.Size)
+				assert.Equal(t, uint(2), file.TotalChunks)
+				assert.Equal(t, testChecksum, file.Checksum)
 			} else {
 				assert.Error(t, err)
+				assert.Nil(t, file)
 				assert.Equal(t, tc.expectedErr.ErrorCode(), err.ErrorCode())
 				assert.Contains(t, err.Error(), tc.expectedErr.Error())
 			}
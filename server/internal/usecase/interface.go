@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"io"
 
 	"github.com/tomoya.tokunaga/server/internal/domain/entity"
 	e "github.com/tomoya.tokunaga/server/internal/domain/entity/error"
@@ -9,15 +10,45 @@ import (
 
 type FileGetUseCase interface {
 	Execute(ctx context.Context) ([]string, e.CustomError)
+	ExecuteGetUploadedFiles(ctx context.Context) ([]*entity.File, e.CustomError)
 	ExecuteGetStats(ctx context.Context, fileName string) (*entity.File, e.CustomError)
+	ExecuteGetContent(ctx context.Context, fileName string) (*entity.File, e.CustomError)
+	ExecuteStreamContent(ctx context.Context, file *entity.File, writer io.Writer, offset uint64, length uint64) e.CustomError
+	ExecuteGetManifest(ctx context.Context, fileName string) (*entity.FileManifest, e.CustomError)
+	ExecuteGetChunk(ctx context.Context, fileID uint64, chunkNumber uint64) (*entity.File, *entity.FileChunk, e.CustomError)
+	ExecuteGetChunkProof(ctx context.Context, file *entity.File, chunk *entity.FileChunk) (*entity.MerkleProof, e.CustomError)
+	ExecuteStreamChunk(ctx context.Context, file *entity.File, chunk *entity.FileChunk, writer io.Writer) e.CustomError
 }
 
 type FileUploadUseCase interface {
-	ExecuteInit(ctx context.Context, input FileUploadUseCaseExecuteInitInput) (*entity.File, []*entity.FileChunk, e.CustomError)
+	ExecuteInit(ctx context.Context, input FileUploadUseCaseExecuteInitInput) (*entity.UploadSession, []*entity.FileChunk, e.CustomError)
 	Execute(ctx context.Context, input FileUploadUseCaseExecuteInput) e.CustomError
-	ExecuteFailRecovery(ctx context.Context, fileID uint64, chunkID uint64) e.CustomError
+	ExecuteFailRecovery(ctx context.Context, sessionToken string, chunkNumber uint64) e.CustomError
+	ExecuteGetOffset(ctx context.Context, sessionToken string) (*entity.UploadSession, uint64, e.CustomError)
+	ExecuteAppend(ctx context.Context, input FileUploadUseCaseExecuteAppendInput) (uint64, e.CustomError)
+	ExecuteCommit(ctx context.Context, input FileUploadUseCaseExecuteCommitInput) (*entity.File, e.CustomError)
+	ExecuteAbort(ctx context.Context, sessionToken string) e.CustomError
+	ExecutePut(ctx context.Context, input FileUploadUseCaseExecutePutInput) (*entity.File, e.CustomError)
+	ExecuteSweepSessions(ctx context.Context) (int, e.CustomError)
+	ExecuteCreateMultipart(ctx context.Context, fileName string) (*entity.MultipartUpload, e.CustomError)
+	ExecuteUploadPart(ctx context.Context, input FileUploadUseCaseExecuteUploadPartInput) (*entity.MultipartPart, e.CustomError)
+	ExecuteCompleteMultipart(ctx context.Context, input FileUploadUseCaseExecuteCompleteMultipartInput) (*entity.File, e.CustomError)
+	ExecuteAbortMultipart(ctx context.Context, uploadID string) e.CustomError
 }
 
 type FileDeleteUseCase interface {
 	Execute(ctx context.Context, fileName string) e.CustomError
 }
+
+type FileScrubUseCase interface {
+	Execute(ctx context.Context) (*entity.ScrubRun, e.CustomError)
+	ExecuteGetReport(ctx context.Context) (*entity.ScrubReport, e.CustomError)
+}
+
+type FileFsckUseCase interface {
+	Execute(ctx context.Context, repair bool) (*entity.FsckReport, e.CustomError)
+}
+
+type FileGCUseCase interface {
+	Execute(ctx context.Context) (*entity.BlobGCRun, e.CustomError)
+}
@@ -0,0 +1,54 @@
+package checksum
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"slices"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Algorithm identifies the hash algorithm of a whole-file checksum
+type Algorithm string
+
+const (
+	SHA256   Algorithm = "sha256"
+	SHA512   Algorithm = "sha512"
+	CRC32C   Algorithm = "crc32c"
+	XXHash64 Algorithm = "xxhash64"
+
+	// DefaultAlgorithm is used when the client doesn't declare any algorithm
+	DefaultAlgorithm = SHA256
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// registry maps the supported algorithms to the constructors of their implementations
+var registry = map[Algorithm]func() hash.Hash{
+	SHA256:   sha256.New,
+	SHA512:   sha512.New,
+	CRC32C:   func() hash.Hash { return crc32.New(crc32cTable) },
+	XXHash64: func() hash.Hash { return xxhash.New() },
+}
+
+// New returns a new hash of the given algorithm
+func New(algorithm Algorithm) (hash.Hash, error) {
+	newHash, ok := registry[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported checksum algorithm %q (supported: %v)", algorithm, Algorithms())
+	}
+	return newHash(), nil
+}
+
+// Algorithms returns the supported algorithms in alphabetical order
+func Algorithms() []Algorithm {
+	algorithms := make([]Algorithm, 0, len(registry))
+	for algorithm := range registry {
+		algorithms = append(algorithms, algorithm)
+	}
+	slices.Sort(algorithms)
+	return algorithms
+}
@@ -0,0 +1,44 @@
+package checksum
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm Algorithm
+		expected  string
+		wantErr   bool
+	}{
+		{name: "SHA-256", algorithm: SHA256, expected: "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"},
+		{name: "SHA-512", algorithm: SHA512, expected: "309ecc489c12d6eb4cc40f50c902f2b4d0ed77ee511a7c7a9bcd3ca86d4cd86f989dd35bc5ff499670da34255b45b0cfd830e81f605dcf7dc5542e93ae9cd76f"},
+		{name: "CRC32C", algorithm: CRC32C, expected: "c99465aa"},
+		{name: "xxHash64", algorithm: XXHash64, expected: "45ab6734b21e6968"},
+		{name: "Unsupported", algorithm: "md5", wantErr: true},
+		{name: "Empty", algorithm: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := New(tt.algorithm)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, h)
+				return
+			}
+
+			require.NoError(t, err)
+			_, _ = h.Write([]byte("hello world"))
+			assert.Equal(t, tt.expected, hex.EncodeToString(h.Sum(nil)))
+		})
+	}
+}
+
+func TestAlgorithms(t *testing.T) {
+	assert.Equal(t, []Algorithm{CRC32C, SHA256, SHA512, XXHash64}, Algorithms())
+}
@@ -0,0 +1,111 @@
+package compression
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Encoding identifies the content coding of a body, named after its HTTP Content-Encoding token
+type Encoding string
+
+const (
+	Identity Encoding = "identity"
+	Gzip     Encoding = "gzip"
+	Zstd     Encoding = "zstd"
+)
+
+// DefaultLevel lets each encoding choose its own default compression level
+const DefaultLevel = 0
+
+// maxZstdWindowSize bounds the memory a zstd stream can make the decoder allocate. Streams produced by the common
+// encoders at their default levels stay far below it
+const maxZstdWindowSize = 64 * 1024 * 1024
+
+// preference lists the encodings chosen for a response in order of preference
+var preference = []Encoding{Zstd, Gzip}
+
+// Parse returns the encoding of the Content-Encoding header value. An empty value is the identity encoding
+func Parse(contentEncoding string) (Encoding, error) {
+	encoding := Encoding(strings.ToLower(strings.TrimSpace(contentEncoding)))
+	switch encoding {
+	case "", Identity:
+		return Identity, nil
+	case Gzip, Zstd:
+		return encoding, nil
+	}
+	return "", fmt.Errorf("unsupported content encoding %q (supported: %v)", contentEncoding, []Encoding{Identity, Gzip, Zstd})
+}
+
+// NewReader returns a reader decoding the content of the encoding read from r
+func NewReader(encoding Encoding, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case Identity:
+		return io.NopCloser(r), nil
+	case Gzip:
+		return gzip.NewReader(r)
+	case Zstd:
+		// a single goroutine decodes the stream, which is read at the pace of the request body anyway
+		decoder, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxWindow(maxZstdWindowSize))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+// NewWriter returns a writer encoding the content written to it in the encoding at the level, which is interpreted
+// as a gzip level (1-9) or a zstd level (1-22). Close needs to be called to flush the content
+func NewWriter(encoding Encoding, w io.Writer, level int) (io.WriteCloser, error) {
+	switch encoding {
+	case Identity:
+		return nopWriteCloser{w}, nil
+	case Gzip:
+		if level == DefaultLevel {
+			level = gzip.DefaultCompression
+		}
+		return gzip.NewWriterLevel(w, level)
+	case Zstd:
+		zstdLevel := zstd.SpeedDefault
+		if level != DefaultLevel {
+			zstdLevel = zstd.EncoderLevelFromZstd(level)
+		}
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstdLevel), zstd.WithEncoderConcurrency(1))
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+// Negotiate chooses the encoding of a response from the Accept-Encoding header value. The identity encoding is chosen
+// unless the client accepts one of the supported encodings with a non-zero quality
+func Negotiate(acceptEncoding string) Encoding {
+	var accepted []Encoding
+	for _, token := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(token, ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if quality, err := strconv.ParseFloat(q, 64); err != nil || quality == 0 {
+				continue
+			}
+		}
+		accepted = append(accepted, Encoding(strings.ToLower(strings.TrimSpace(name))))
+	}
+
+	for _, encoding := range preference {
+		if slices.Contains(accepted, encoding) {
+			return encoding
+		}
+	}
+	return Identity
+}
+
+// nopWriteCloser adds a Close method doing nothing to a writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
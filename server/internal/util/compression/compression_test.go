@@ -0,0 +1,98 @@
+package compression
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name            string
+		contentEncoding string
+		expected        Encoding
+		expectErr       bool
+	}{
+		{name: "Empty", contentEncoding: "", expected: Identity},
+		{name: "Identity", contentEncoding: "identity", expected: Identity},
+		{name: "Gzip", contentEncoding: "gzip", expected: Gzip},
+		{name: "Zstd in upper case", contentEncoding: " ZSTD ", expected: Zstd},
+		{name: "Unsupported", contentEncoding: "br", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, err := Parse(tt.contentEncoding)
+			if tt.expectErr {
+				assert.ErrorContains(t, err, `unsupported content encoding "br"`)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, encoding)
+		})
+	}
+}
+
+func TestNewWriterAndNewReader(t *testing.T) {
+	content := []byte(strings.Repeat("hello world ", 1000))
+
+	for _, encoding := range []Encoding{Identity, Gzip, Zstd} {
+		for _, level := range []int{DefaultLevel, 1, 9} {
+			t.Run(fmt.Sprintf("%s level %d", encoding, level), func(t *testing.T) {
+				var buf bytes.Buffer
+				w, err := NewWriter(encoding, &buf, level)
+				require.NoError(t, err)
+				_, err = w.Write(content)
+				require.NoError(t, err)
+				require.NoError(t, w.Close())
+				if encoding != Identity {
+					assert.Less(t, buf.Len(), len(content))
+				}
+
+				r, err := NewReader(encoding, &buf)
+				require.NoError(t, err)
+				decoded, err := io.ReadAll(r)
+				require.NoError(t, err)
+				require.NoError(t, r.Close())
+				assert.Equal(t, content, decoded)
+			})
+		}
+	}
+}
+
+func TestNewReader_Corrupt(t *testing.T) {
+	for _, encoding := range []Encoding{Gzip, Zstd} {
+		t.Run(string(encoding), func(t *testing.T) {
+			r, err := NewReader(encoding, strings.NewReader("not compressed at all"))
+			if err == nil {
+				_, err = io.ReadAll(r)
+			}
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		expected       Encoding
+	}{
+		{name: "Empty", acceptEncoding: "", expected: Identity},
+		{name: "Gzip only", acceptEncoding: "gzip", expected: Gzip},
+		{name: "Zstd preferred over gzip", acceptEncoding: "gzip, deflate, zstd", expected: Zstd},
+		{name: "Zstd refused", acceptEncoding: "zstd;q=0, gzip;q=0.5", expected: Gzip},
+		{name: "Unsupported only", acceptEncoding: "br, deflate", expected: Identity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Negotiate(tt.acceptEncoding))
+		})
+	}
+}
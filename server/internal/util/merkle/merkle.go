@@ -0,0 +1,105 @@
+// Package merkle builds the Merkle tree of RFC 6962 (Certificate Transparency) over the SHA-256 digests of the chunks
+// of a file, so that a chunk can be verified against the root of the tree without the other chunks.
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+// the prefixes keep a leaf from being interpreted as an internal node and vice versa
+const (
+	leafPrefix = 0x00
+	nodePrefix = 0x01
+)
+
+// LeafHash returns the hash of the leaf for a chunk with the given SHA-256 digest
+func LeafHash(digest []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(digest)
+	return h.Sum(nil)
+}
+
+// nodeHash returns the hash of an internal node with the given children
+func nodeHash(left []byte, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{nodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// Root returns the root hash of the tree whose leaves are the chunks with the given digests in order
+func Root(digests [][]byte) []byte {
+	switch len(digests) {
+	case 0:
+		empty := sha256.Sum256(nil)
+		return empty[:]
+	case 1:
+		return LeafHash(digests[0])
+	}
+
+	k := splitPoint(len(digests))
+	return nodeHash(Root(digests[:k]), Root(digests[k:]))
+}
+
+// Proof returns the inclusion proof of the chunk at the index, which is the list of the sibling hashes from the leaf
+// up to the root
+func Proof(digests [][]byte, index int) ([][]byte, error) {
+	if index < 0 || index >= len(digests) {
+		return nil, fmt.Errorf("index %d is out of the tree of %d leaves", index, len(digests))
+	}
+	return proof(digests, index), nil
+}
+
+func proof(digests [][]byte, index int) [][]byte {
+	if len(digests) <= 1 {
+		return [][]byte{}
+	}
+
+	k := splitPoint(len(digests))
+	if index < k {
+		return append(proof(digests[:k], index), Root(digests[k:]))
+	}
+	return append(proof(digests[k:], index-k), Root(digests[:k]))
+}
+
+// Verify reports whether the chunk with the digest is at the index of the tree of the size with the root
+func Verify(root []byte, digest []byte, index uint64, size uint64, proof [][]byte) bool {
+	if index >= size {
+		return false
+	}
+
+	// see RFC 9162 section 2.1.3.2
+	fn, sn := index, size-1
+	r := LeafHash(digest)
+	for _, p := range proof {
+		if sn == 0 {
+			return false
+		}
+		if fn&1 == 1 || fn == sn {
+			r = nodeHash(p, r)
+			for fn&1 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = nodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	return sn == 0 && bytes.Equal(r, root)
+}
+
+// splitPoint returns the largest power of two smaller than n, which is the number of the leaves in the left subtree
+func splitPoint(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
@@ -0,0 +1,85 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// digestsOf returns the SHA-256 digests of n chunks with distinct contents
+func digestsOf(n int) [][]byte {
+	digests := make([][]byte, n)
+	for i := range digests {
+		digest := sha256.Sum256([]byte(fmt.Sprintf("chunk %d", i)))
+		digests[i] = digest[:]
+	}
+	return digests
+}
+
+func TestRoot(t *testing.T) {
+	digests := digestsOf(3)
+
+	tests := []struct {
+		name     string
+		digests  [][]byte
+		expected []byte
+	}{
+		{
+			name:     "Empty",
+			digests:  [][]byte{},
+			expected: func() []byte { h := sha256.Sum256(nil); return h[:] }(),
+		},
+		{
+			name:     "Single Leaf",
+			digests:  digests[:1],
+			expected: LeafHash(digests[0]),
+		},
+		{
+			name:     "Unbalanced Tree",
+			digests:  digests,
+			expected: nodeHash(nodeHash(LeafHash(digests[0]), LeafHash(digests[1])), LeafHash(digests[2])),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, hex.EncodeToString(tt.expected), hex.EncodeToString(Root(tt.digests)))
+		})
+	}
+}
+
+func TestProofAndVerify(t *testing.T) {
+	for _, size := range []int{1, 2, 3, 4, 5, 7, 8, 13, 64} {
+		t.Run(fmt.Sprintf("%d Leaves", size), func(t *testing.T) {
+			digests := digestsOf(size)
+			root := Root(digests)
+
+			for index := range digests {
+				proof, err := Proof(digests, index)
+				require.NoError(t, err)
+				assert.True(t, Verify(root, digests[index], uint64(index), uint64(size), proof), "leaf %d", index)
+
+				// the proof is bound to the content, the position and the size of the tree
+				assert.False(t, Verify(root, digestsOf(size + 1)[size], uint64(index), uint64(size), proof), "leaf %d with other content", index)
+				if size > 1 {
+					assert.False(t, Verify(root, digests[index], uint64((index+1)%size), uint64(size), proof), "leaf %d at other index", index)
+				}
+				assert.False(t, Verify(root, digests[index], uint64(index), uint64(2*size), proof), "leaf %d in larger tree", index)
+			}
+		})
+	}
+}
+
+func TestProof_InvalidIndex(t *testing.T) {
+	digests := digestsOf(2)
+
+	_, err := Proof(digests, 2)
+	assert.Error(t, err)
+	_, err = Proof(digests, -1)
+	assert.Error(t, err)
+	assert.False(t, Verify(Root(digests), digests[0], 2, 2, [][]byte{}))
+}
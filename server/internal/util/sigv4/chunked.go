@@ -0,0 +1,318 @@
+package sigv4
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// StreamingSignedPayload is the payload hash of an aws-chunked body whose chunks are signed one by one
+	StreamingSignedPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	// StreamingUnsignedPayloadTrailer is the payload hash of an aws-chunked body whose chunks are not signed, and
+	// whose checksum is sent in the trailer instead, which the AWS SDKs send over HTTPS
+	StreamingUnsignedPayloadTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+
+	DecodedContentLengthHeader = "X-Amz-Decoded-Content-Length"
+	TrailerHeader              = "X-Amz-Trailer"
+
+	chunkAlgorithm        = "AWS4-HMAC-SHA256-PAYLOAD"
+	chunkSignatureParam   = "chunk-signature="
+	checksumTrailerPrefix = "x-amz-checksum-"
+	// maxChunkLineSize is the longest line of the chunk headers and the trailers
+	maxChunkLineSize = 4096
+)
+
+// Errors of the aws-chunked bodies, which are found while the body is read
+var (
+	ErrMalformedChunkedBody    = errors.New("malformed aws-chunked body")
+	ErrTrailerChecksumMismatch = errors.New("checksum in the trailer does not match the body")
+)
+
+// crc64NVME is the reversed polynomial of CRC-64/NVME, which crc64.MakeTable takes
+const crc64NVME = 0x9a6c9329ac4bc9b5
+
+// ChunkedReader decodes an aws-chunked body, e.g. "5;chunk-signature=abcd\r\nhello\r\n0;chunk-signature=ef01\r\n\r\n"
+// for a signed one, and "5\r\nhello\r\n0\r\nx-amz-checksum-crc32:NhCmhg==\r\n\r\n" for an unsigned one with the
+// trailer. The signature of each chunk or the checksum in the trailer is verified as the body is read, so the content
+// read so far is not trusted until the reader reaches the end without an error.
+type ChunkedReader struct {
+	reader      *bufio.Reader
+	result      *Result
+	signed      bool
+	decodedSize int64
+
+	remaining         int64     // the size of the content left in the current chunk
+	readSize          int64     // the size of the content read so far
+	chunkHash         hash.Hash // the hash of the current chunk of a signed body
+	chunkSignature    []byte
+	previousSignature []byte
+	trailerName       string
+	trailerChecksum   hash.Hash
+	err               error
+	done              bool
+}
+
+// NewChunkedReader returns the reader of the aws-chunked body of the request, which is signed as the result tells. The
+// decoded body needs to be as large as the X-Amz-Decoded-Content-Length header says, and the checksum is expected
+// in the trailer when the X-Amz-Trailer header names it.
+func (r *Result) NewChunkedReader(req *http.Request) (*ChunkedReader, error) {
+	if r.PayloadHash != StreamingSignedPayload && r.PayloadHash != StreamingUnsignedPayloadTrailer {
+		return nil, fmt.Errorf("%w: payload %s is not supported", ErrMalformedChunkedBody, r.PayloadHash)
+	}
+	decodedSize, err := strconv.ParseInt(req.Header.Get(DecodedContentLengthHeader), 10, 64)
+	if err != nil || decodedSize < 0 {
+		return nil, fmt.Errorf("%w: invalid %s header %q", ErrMalformedChunkedBody, DecodedContentLengthHeader, req.Header.Get(DecodedContentLengthHeader))
+	}
+
+	cr := &ChunkedReader{
+		reader:            bufio.NewReaderSize(req.Body, maxChunkLineSize),
+		result:            r,
+		signed:            r.PayloadHash == StreamingSignedPayload,
+		decodedSize:       decodedSize,
+		chunkHash:         sha256.New(),
+		previousSignature: r.signature,
+	}
+	if trailer := strings.ToLower(strings.TrimSpace(req.Header.Get(TrailerHeader))); trailer != "" {
+		if cr.signed {
+			return nil, fmt.Errorf("%w: trailer of a signed body is not supported", ErrMalformedChunkedBody)
+		}
+		checksumHash, err := newTrailerChecksum(trailer)
+		if err != nil {
+			return nil, err
+		}
+		cr.trailerName = trailer
+		cr.trailerChecksum = checksumHash
+	}
+
+	return cr, nil
+}
+
+// Read reads the decoded content, and fails once the body turns out to be malformed or forged
+func (r *ChunkedReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.done {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if r.remaining == 0 {
+		size, err := r.readChunkHeader()
+		if err != nil {
+			return 0, r.fail(err)
+		}
+		if size == 0 {
+			if err := r.readEnd(); err != nil {
+				return 0, r.fail(err)
+			}
+			r.done = true
+			return 0, io.EOF
+		}
+		if r.readSize+size > r.decodedSize {
+			return 0, r.fail(fmt.Errorf("%w: body exceeds the decoded size %d", ErrMalformedChunkedBody, r.decodedSize))
+		}
+		r.remaining = size
+	}
+
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	r.readSize += int64(n)
+	if r.signed {
+		r.chunkHash.Write(p[:n])
+	}
+	if r.trailerChecksum != nil {
+		r.trailerChecksum.Write(p[:n])
+	}
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return n, r.fail(err)
+	}
+
+	if r.remaining == 0 {
+		if err := r.readChunkEnd(); err != nil {
+			return n, r.fail(err)
+		}
+	}
+	return n, nil
+}
+
+// DecodedSize returns the size of the decoded content
+func (r *ChunkedReader) DecodedSize() int64 {
+	return r.decodedSize
+}
+
+// Err returns the error which the reader failed with, or nil while it hasn't
+func (r *ChunkedReader) Err() error {
+	return r.err
+}
+
+func (r *ChunkedReader) fail(err error) error {
+	r.err = err
+	return err
+}
+
+// readChunkHeader reads the header of the next chunk, e.g. "400;chunk-signature=abcd", and returns the size of the
+// chunk
+func (r *ChunkedReader) readChunkHeader() (int64, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return 0, err
+	}
+
+	sizeHex, extension, _ := strings.Cut(line, ";")
+	size, err := strconv.ParseInt(sizeHex, 16, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("%w: invalid chunk size %q", ErrMalformedChunkedBody, sizeHex)
+	}
+	if r.signed {
+		signature, found := strings.CutPrefix(extension, chunkSignatureParam)
+		decoded, decodeErr := hex.DecodeString(signature)
+		if !found || decodeErr != nil || len(decoded) != sha256.Size {
+			return 0, fmt.Errorf("%w: invalid chunk signature %q", ErrMalformedChunkedBody, extension)
+		}
+		r.chunkSignature = decoded
+		r.chunkHash.Reset()
+	}
+
+	return size, nil
+}
+
+// readChunkEnd reads the line break after the content of a chunk, and verifies the signature of the chunk
+func (r *ChunkedReader) readChunkEnd() error {
+	line, err := r.readLine()
+	if err != nil {
+		return err
+	}
+	if line != "" {
+		return fmt.Errorf("%w: chunk is larger than its size", ErrMalformedChunkedBody)
+	}
+	return r.verifyChunk()
+}
+
+// readEnd reads the trailers after the last chunk and the final line break, and verifies the last chunk, the size of
+// the body and its checksum
+func (r *ChunkedReader) readEnd() error {
+	if err := r.verifyChunk(); err != nil {
+		return err
+	}
+	if r.readSize != r.decodedSize {
+		return fmt.Errorf("%w: expected %d bytes, but got %d", ErrMalformedChunkedBody, r.decodedSize, r.readSize)
+	}
+
+	trailers := make(map[string]string)
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return err
+		}
+		if line == "" {
+			break
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found || r.signed {
+			return fmt.Errorf("%w: unexpected trailer %q", ErrMalformedChunkedBody, line)
+		}
+		trailers[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+
+	if r.trailerChecksum == nil {
+		return nil
+	}
+	value, ok := trailers[r.trailerName]
+	if !ok {
+		return fmt.Errorf("%w: trailer %s is missing", ErrMalformedChunkedBody, r.trailerName)
+	}
+	expected, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return fmt.Errorf("%w: invalid trailer %s %q", ErrMalformedChunkedBody, r.trailerName, value)
+	}
+	if actual := r.trailerChecksum.Sum(nil); !bytes.Equal(actual, expected) {
+		return fmt.Errorf("%w: expected %s %s, but got %s", ErrTrailerChecksumMismatch, r.trailerName, value, base64.StdEncoding.EncodeToString(actual))
+	}
+	return nil
+}
+
+// verifyChunk verifies the signature of the chunk read last, which is calculated over the signature of the previous
+// chunk and the content of the chunk. The signature of the first chunk is chained from the signature of the request
+func (r *ChunkedReader) verifyChunk() error {
+	if !r.signed {
+		return nil
+	}
+	emptyHash := sha256.Sum256(nil)
+	stringToSign := strings.Join([]string{
+		chunkAlgorithm,
+		r.result.time.UTC().Format(TimeFormat),
+		r.result.credentialScope,
+		hex.EncodeToString(r.previousSignature),
+		hex.EncodeToString(emptyHash[:]),
+		hex.EncodeToString(r.chunkHash.Sum(nil)),
+	}, "\n")
+	if !hmac.Equal(hmacSHA256(r.result.signingKey, stringToSign), r.chunkSignature) {
+		return fmt.Errorf("%w: signature of chunk ending at %d bytes", ErrSignatureDoesNotMatch, r.readSize)
+	}
+	r.previousSignature = r.chunkSignature
+	return nil
+}
+
+// readLine reads a line terminated by CRLF without the line break
+func (r *ChunkedReader) readLine() (string, error) {
+	line, err := r.reader.ReadSlice('\n')
+	if err != nil {
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return "", fmt.Errorf("%w: line is too long", ErrMalformedChunkedBody)
+		}
+		if errors.Is(err, io.EOF) {
+			return "", io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	trimmed, found := bytes.CutSuffix(line, []byte("\r\n"))
+	if !found {
+		return "", fmt.Errorf("%w: line is not terminated by CRLF", ErrMalformedChunkedBody)
+	}
+	return string(trimmed), nil
+}
+
+// newTrailerChecksum returns the hash of the checksum named by the trailer, e.g. "x-amz-checksum-crc32"
+func newTrailerChecksum(trailer string) (hash.Hash, error) {
+	algorithm, found := strings.CutPrefix(trailer, checksumTrailerPrefix)
+	if !found {
+		return nil, fmt.Errorf("%w: trailer %q is not supported", ErrMalformedChunkedBody, trailer)
+	}
+	switch algorithm {
+	case "crc32":
+		return crc32.NewIEEE(), nil
+	case "crc32c":
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli)), nil
+	case "crc64nvme":
+		return crc64.New(crc64.MakeTable(crc64NVME)), nil
+	case "sha1":
+		return sha1.New(), nil
+	case "sha256":
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("%w: trailer %q is not supported", ErrMalformedChunkedBody, trailer)
+	}
+}
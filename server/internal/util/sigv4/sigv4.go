@@ -0,0 +1,401 @@
+// Package sigv4 verifies the requests signed with AWS Signature Version 4, which is how the S3 clients (e.g. the AWS
+// SDKs and CLI) authenticate, either by the Authorization header or by the query parameters of a presigned URL, and
+// decodes the aws-chunked bodies which they stream.
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Algorithm = "AWS4-HMAC-SHA256"
+	// UnsignedPayload is the payload hash of a request whose body is not covered by the signature
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// StreamingPayloadPrefix is the prefix of the payload hashes of the aws-chunked encoded bodies
+	StreamingPayloadPrefix = "STREAMING-"
+
+	ContentSHA256Header = "X-Amz-Content-Sha256"
+	DateHeader          = "X-Amz-Date"
+	TimeFormat          = "20060102T150405Z"
+
+	// MaxClockSkew is how far the time of a request can be from the time of the server
+	MaxClockSkew = 15 * time.Minute
+	// MaxPresignExpiry is the longest expiry of a presigned URL
+	MaxPresignExpiry = 7 * 24 * time.Hour
+
+	dateFormat  = "20060102"
+	scopeSuffix = "aws4_request"
+)
+
+// Errors of the verification, which the S3 API reports with the corresponding error codes
+var (
+	ErrMissingAuthentication  = errors.New("request is not signed")
+	ErrMalformedAuthorization = errors.New("malformed authorization")
+	ErrInvalidAccessKeyID     = errors.New("access key ID does not exist")
+	ErrSignatureDoesNotMatch  = errors.New("signature does not match")
+	ErrRequestTimeTooSkewed   = errors.New("request time is too skewed from the server time")
+	ErrRequestExpired         = errors.New("request has expired")
+)
+
+// Verifier verifies the signatures of the requests for a service in a region
+type Verifier struct {
+	credentials map[string]string // access key ID to secret access key
+	region      string
+	service     string
+	now         func() time.Time
+}
+
+// NewVerifier creates a Verifier which accepts the requests signed with one of the credentials
+func NewVerifier(credentials map[string]string, region string, service string) *Verifier {
+	return &Verifier{
+		credentials: credentials,
+		region:      region,
+		service:     service,
+		now:         time.Now,
+	}
+}
+
+// Result is the outcome of a successful verification
+type Result struct {
+	AccessKeyID string
+	// PayloadHash is the hex-encoded SHA-256 of the body the client signed, UnsignedPayload, or a streaming payload
+	// hash. The body itself is not verified by Verify since it's read later
+	PayloadHash string
+
+	// the signature of the request and what it's signed with, which the chunks of a streaming payload are signed
+	// with in turn
+	signature       []byte
+	signingKey      []byte
+	time            time.Time
+	credentialScope string
+}
+
+// authorization represents the signature of a request and what it's calculated over
+type authorization struct {
+	accessKeyID   string
+	date          string // the date of the credential scope
+	region        string
+	service       string
+	signedHeaders []string
+	signature     []byte
+	time          time.Time
+	payloadHash   string
+	presigned     bool
+}
+
+// Verify verifies the signature of the request, given either in the Authorization header or in the query parameters
+func (v *Verifier) Verify(req *http.Request) (*Result, error) {
+	var auth *authorization
+	var err error
+	if header := req.Header.Get("Authorization"); header != "" {
+		auth, err = parseAuthorizationHeader(req, header)
+	} else if req.URL.Query().Has("X-Amz-Signature") {
+		auth, err = parsePresignedQuery(req, v.now())
+	} else {
+		return nil, ErrMissingAuthentication
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if auth.date != auth.time.UTC().Format(dateFormat) {
+		return nil, fmt.Errorf("%w: credential date %s does not match the request time", ErrMalformedAuthorization, auth.date)
+	}
+	if auth.region != v.region {
+		return nil, fmt.Errorf("%w: region %q is wrong, expecting %q", ErrMalformedAuthorization, auth.region, v.region)
+	}
+	if auth.service != v.service {
+		return nil, fmt.Errorf("%w: service %q is wrong, expecting %q", ErrMalformedAuthorization, auth.service, v.service)
+	}
+	if !auth.presigned {
+		// a presigned URL is valid until it expires, which is checked on parsing
+		if skew := v.now().Sub(auth.time); skew > MaxClockSkew || skew < -MaxClockSkew {
+			return nil, ErrRequestTimeTooSkewed
+		}
+	}
+
+	secretAccessKey, ok := v.credentials[auth.accessKeyID]
+	if !ok {
+		return nil, ErrInvalidAccessKeyID
+	}
+	canonical := canonicalRequest(req, auth.signedHeaders, auth.payloadHash, auth.presigned)
+	key := signingKey(secretAccessKey, auth.date, auth.region, auth.service)
+	credentialScope := scope(auth.date, auth.region, auth.service)
+	if !hmac.Equal(hmacSHA256(key, stringToSign(auth.time, credentialScope, canonical)), auth.signature) {
+		return nil, ErrSignatureDoesNotMatch
+	}
+
+	return &Result{
+		AccessKeyID:     auth.accessKeyID,
+		PayloadHash:     auth.payloadHash,
+		signature:       auth.signature,
+		signingKey:      key,
+		time:            auth.time,
+		credentialScope: credentialScope,
+	}, nil
+}
+
+// Sign signs the request with the Authorization header as of the time. The host and the x-amz-* headers are signed
+// in addition to the given ones, and the payload hash is sent in the X-Amz-Content-Sha256 header
+func Sign(req *http.Request, accessKeyID string, secretAccessKey string, region string, service string, payloadHash string, t time.Time, headers ...string) {
+	req.Header.Set(DateHeader, t.UTC().Format(TimeFormat))
+	req.Header.Set(ContentSHA256Header, payloadHash)
+
+	signedHeaders := []string{"host"}
+	for name := range req.Header {
+		if name := strings.ToLower(name); strings.HasPrefix(name, "x-amz-") {
+			signedHeaders = append(signedHeaders, name)
+		}
+	}
+	for _, name := range headers {
+		signedHeaders = append(signedHeaders, strings.ToLower(name))
+	}
+	sort.Strings(signedHeaders)
+
+	date := t.UTC().Format(dateFormat)
+	credentialScope := scope(date, region, service)
+	canonical := canonicalRequest(req, signedHeaders, payloadHash, false)
+	signed := hmacSHA256(signingKey(secretAccessKey, date, region, service), stringToSign(t, credentialScope, canonical))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%x",
+		Algorithm, accessKeyID, credentialScope, strings.Join(signedHeaders, ";"), signed,
+	))
+}
+
+// parseAuthorizationHeader parses an Authorization header, e.g. "AWS4-HMAC-SHA256
+// Credential=AKID/20130524/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-date, Signature=abcd"
+func parseAuthorizationHeader(req *http.Request, header string) (*authorization, error) {
+	algorithm, params, _ := strings.Cut(header, " ")
+	if algorithm != Algorithm {
+		return nil, fmt.Errorf("%w: algorithm %q is not supported", ErrMalformedAuthorization, algorithm)
+	}
+
+	values := make(map[string]string)
+	for _, param := range strings.Split(params, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found {
+			return nil, fmt.Errorf("%w: %q is not a key-value pair", ErrMalformedAuthorization, param)
+		}
+		values[key] = value
+	}
+
+	auth := &authorization{payloadHash: req.Header.Get(ContentSHA256Header)}
+	if auth.payloadHash == "" {
+		return nil, fmt.Errorf("%w: %s header is missing", ErrMalformedAuthorization, ContentSHA256Header)
+	}
+	if err := auth.parseCredential(values["Credential"]); err != nil {
+		return nil, err
+	}
+	if err := auth.parseSignedHeaders(values["SignedHeaders"]); err != nil {
+		return nil, err
+	}
+	if err := auth.parseSignature(values["Signature"]); err != nil {
+		return nil, err
+	}
+
+	if date := req.Header.Get(DateHeader); date != "" {
+		t, err := time.Parse(TimeFormat, date)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid %s header %q", ErrMalformedAuthorization, DateHeader, date)
+		}
+		auth.time = t
+	} else {
+		t, err := http.ParseTime(req.Header.Get("Date"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: neither %s nor Date header is valid", ErrMalformedAuthorization, DateHeader)
+		}
+		auth.time = t
+	}
+
+	return auth, nil
+}
+
+// parsePresignedQuery parses the query parameters of a presigned URL, and checks if the URL has expired
+func parsePresignedQuery(req *http.Request, now time.Time) (*authorization, error) {
+	query := req.URL.Query()
+	if algorithm := query.Get("X-Amz-Algorithm"); algorithm != Algorithm {
+		return nil, fmt.Errorf("%w: algorithm %q is not supported", ErrMalformedAuthorization, algorithm)
+	}
+
+	auth := &authorization{payloadHash: UnsignedPayload, presigned: true}
+	if err := auth.parseCredential(query.Get("X-Amz-Credential")); err != nil {
+		return nil, err
+	}
+	if err := auth.parseSignedHeaders(query.Get("X-Amz-SignedHeaders")); err != nil {
+		return nil, err
+	}
+	if err := auth.parseSignature(query.Get("X-Amz-Signature")); err != nil {
+		return nil, err
+	}
+
+	t, err := time.Parse(TimeFormat, query.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid X-Amz-Date %q", ErrMalformedAuthorization, query.Get("X-Amz-Date"))
+	}
+	auth.time = t
+	expires, err := strconv.ParseUint(query.Get("X-Amz-Expires"), 10, 64)
+	if err != nil || time.Duration(expires)*time.Second > MaxPresignExpiry {
+		return nil, fmt.Errorf("%w: invalid X-Amz-Expires %q", ErrMalformedAuthorization, query.Get("X-Amz-Expires"))
+	}
+	if now.Before(t.Add(-MaxClockSkew)) {
+		return nil, ErrRequestTimeTooSkewed
+	}
+	if now.After(t.Add(time.Duration(expires) * time.Second)) {
+		return nil, ErrRequestExpired
+	}
+
+	return auth, nil
+}
+
+// parseCredential parses a credential, e.g. "AKID/20130524/us-east-1/s3/aws4_request"
+func (a *authorization) parseCredential(credential string) error {
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[0] == "" || parts[4] != scopeSuffix {
+		return fmt.Errorf("%w: invalid credential %q", ErrMalformedAuthorization, credential)
+	}
+	a.accessKeyID, a.date, a.region, a.service = parts[0], parts[1], parts[2], parts[3]
+	return nil
+}
+
+// parseSignedHeaders parses the semicolon-separated names of the signed headers, which need to include the host
+func (a *authorization) parseSignedHeaders(signedHeaders string) error {
+	a.signedHeaders = strings.Split(signedHeaders, ";")
+	for _, name := range a.signedHeaders {
+		if name == "host" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host header needs to be signed", ErrMalformedAuthorization)
+}
+
+// parseSignature parses a hex-encoded signature
+func (a *authorization) parseSignature(signature string) error {
+	decoded, err := hex.DecodeString(signature)
+	if err != nil || len(decoded) != sha256.Size {
+		return fmt.Errorf("%w: invalid signature %q", ErrMalformedAuthorization, signature)
+	}
+	a.signature = decoded
+	return nil
+}
+
+// canonicalRequest returns the canonical form of the request which the signature is calculated over
+func canonicalRequest(req *http.Request, signedHeaders []string, payloadHash string, presigned bool) string {
+	return strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL.Path),
+		canonicalQuery(req.URL.RawQuery, presigned),
+		canonicalHeaders(req, signedHeaders),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+// canonicalURI encodes the path once, as S3 doesn't double-encode it unlike the other services
+func canonicalURI(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return escape(path, false)
+}
+
+// canonicalQuery encodes the query parameters sorted by name, excluding the signature of a presigned URL
+func canonicalQuery(rawQuery string, presigned bool) string {
+	// url.ParseQuery decodes "+" into a space, which the clients encode as "%20", so the keys are split by hand
+	params := []string{}
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(param, "=")
+		key, keyErr := url.PathUnescape(key)
+		value, valueErr := url.PathUnescape(value)
+		if keyErr != nil || valueErr != nil {
+			continue
+		}
+		if presigned && key == "X-Amz-Signature" {
+			continue
+		}
+		params = append(params, escape(key, true)+"="+escape(value, true))
+	}
+	sort.Strings(params)
+
+	return strings.Join(params, "&")
+}
+
+// canonicalHeaders lists the signed headers with their values, whose spaces are trimmed and collapsed
+func canonicalHeaders(req *http.Request, signedHeaders []string) string {
+	var b strings.Builder
+	for _, name := range signedHeaders {
+		var values []string
+		switch name {
+		case "host":
+			host := req.Host
+			if host == "" {
+				host = req.URL.Host
+			}
+			values = []string{host}
+		case "content-length":
+			values = req.Header.Values(name)
+			if len(values) == 0 && req.ContentLength >= 0 {
+				values = []string{strconv.FormatInt(req.ContentLength, 10)}
+			}
+		default:
+			values = req.Header.Values(name)
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		b.WriteString(name + ":" + strings.Join(trimmed, ",") + "\n")
+	}
+	return b.String()
+}
+
+// escape percent-encodes everything except the unreserved characters of RFC 3986, and the slashes unless told to
+func escape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !escapeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// scope returns the credential scope, e.g. "20130524/us-east-1/s3/aws4_request"
+func scope(date string, region string, service string) string {
+	return strings.Join([]string{date, region, service, scopeSuffix}, "/")
+}
+
+// stringToSign returns the string which the signing key signs
+func stringToSign(t time.Time, credentialScope string, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	return strings.Join([]string{Algorithm, t.UTC().Format(TimeFormat), credentialScope, hex.EncodeToString(hash[:])}, "\n")
+}
+
+// signingKey derives the key which signs the strings of the scope from the secret access key
+func signingKey(secretAccessKey string, date string, region string, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, scopeSuffix)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}